@@ -39,3 +39,23 @@ func (hs *HTTPServer) AdminProvisioningReloadNotifications(c *models.ReqContext)
 	}
 	return response.Success("Notifications config reloaded")
 }
+
+func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *models.ReqContext) response.Response {
+	err := hs.ProvisioningService.ProvisionAlerting(c.Req.Context())
+	if err != nil {
+		return response.Error(500, "", err)
+	}
+	return response.Success("Alerting config reloaded")
+}
+
+func (hs *HTTPServer) AdminProvisioningReloadOrgs(c *models.ReqContext) response.Response {
+	err := hs.ProvisioningService.ProvisionOrgs(c.Req.Context())
+	if err != nil {
+		return response.Error(500, "", err)
+	}
+	return response.Success("Organizations config reloaded")
+}
+
+func (hs *HTTPServer) AdminProvisioningDashboardsStatus(c *models.ReqContext) response.Response {
+	return response.JSON(200, hs.ProvisioningService.GetDashboardProvisionersStatus())
+}
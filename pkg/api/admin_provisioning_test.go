@@ -8,6 +8,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 )
@@ -93,6 +94,48 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 			url:          "/api/admin/provisioning/notifications/reload",
 			exit:         true,
 		},
+		{
+			desc:         "should work for alerting with specific scope",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"message":"Alerting config reloaded"}`,
+			permissions: []*accesscontrol.Permission{
+				{
+					Action: ActionProvisioningReload,
+					Scope:  ScopeProvisionersAlerting,
+				},
+			},
+			url: "/api/admin/provisioning/alerting/reload",
+			checkCall: func(mock provisioning.ProvisioningServiceMock) {
+				assert.Len(t, mock.Calls.ProvisionAlerting, 1)
+			},
+		},
+		{
+			desc:         "should fail for alerting with no permission",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/admin/provisioning/alerting/reload",
+			exit:         true,
+		},
+		{
+			desc:         "should work for organizations with specific scope",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"message":"Organizations config reloaded"}`,
+			permissions: []*accesscontrol.Permission{
+				{
+					Action: ActionProvisioningReload,
+					Scope:  ScopeProvisionersOrgs,
+				},
+			},
+			url: "/api/admin/provisioning/orgs/reload",
+			checkCall: func(mock provisioning.ProvisioningServiceMock) {
+				assert.Len(t, mock.Calls.ProvisionOrgs, 1)
+			},
+		},
+		{
+			desc:         "should fail for organizations with no permission",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/admin/provisioning/orgs/reload",
+			exit:         true,
+		},
 		{
 			desc:         "should work for datasources with specific scope",
 			expectedCode: http.StatusOK,
@@ -168,3 +211,78 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_AdminProvisioningDashboardsStatus_AccessControl(t *testing.T) {
+	tests := []reloadProvisioningTestCase{
+		{
+			desc:         "should work with specific scope",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"dashboards","type":"git","orgId":1,"path":"/var/lib/grafana/provisioning/dashboards/dashboards","url":"https://github.com/grafana/dashboards.git","ref":"main","revision":"4b825dc642cb6eb9a060e54bf8d69288fbee4904"}]`,
+			permissions: []*accesscontrol.Permission{
+				{
+					Action: ActionProvisioningRead,
+					Scope:  ScopeProvisionersDashboards,
+				},
+			},
+			url: "/api/admin/provisioning/dashboards/status",
+			checkCall: func(mock provisioning.ProvisioningServiceMock) {
+				assert.Len(t, mock.Calls.GetDashboardProvisionersStatus, 1)
+			},
+		},
+		{
+			desc:         "should fail with reload permission",
+			expectedCode: http.StatusForbidden,
+			permissions: []*accesscontrol.Permission{
+				{
+					Action: ActionProvisioningReload,
+					Scope:  ScopeProvisionersDashboards,
+				},
+			},
+			url:  "/api/admin/provisioning/dashboards/status",
+			exit: true,
+		},
+		{
+			desc:         "should fail with no permission",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/admin/provisioning/dashboards/status",
+			exit:         true,
+		},
+	}
+
+	cfg := setting.NewCfg()
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			sc, hs := setupAccessControlScenarioContext(t, cfg, test.url, test.permissions)
+
+			provisioningMock := provisioning.NewProvisioningServiceMock(context.Background())
+			provisioningMock.GetDashboardProvisionersStatusFunc = func() []*dashboards.ProvisionerStatus {
+				return []*dashboards.ProvisionerStatus{{
+					Name:     "dashboards",
+					Type:     "git",
+					OrgID:    1,
+					Path:     "/var/lib/grafana/provisioning/dashboards/dashboards",
+					URL:      "https://github.com/grafana/dashboards.git",
+					Ref:      "main",
+					Revision: "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+				}}
+			}
+			hs.ProvisioningService = provisioningMock
+
+			sc.resp = httptest.NewRecorder()
+			var err error
+			sc.req, err = http.NewRequest(http.MethodGet, test.url, nil)
+			assert.NoError(t, err)
+
+			sc.exec()
+
+			assert.Equal(t, test.expectedCode, sc.resp.Code)
+			if test.exit {
+				return
+			}
+
+			assert.Equal(t, test.expectedBody, sc.resp.Body.String())
+			test.checkCall(*provisioningMock)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/apikeygen"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 )
 
 // GetAPIKeys returns a list of API keys
@@ -72,16 +73,24 @@ func (hs *HTTPServer) AddAPIKey(c *models.ReqContext, cmd models.AddApiKeyComman
 		}
 	}
 	cmd.OrgId = c.OrgId
-	var err error
-	var serviceAccount *models.User = &models.User{Id: -1}
 	if hs.Cfg.FeatureToggles["service-accounts"] {
 		if cmd.CreateNewServiceAccount {
-			serviceAccount, err = hs.AccessControl.CloneUserToServiceAccount(c.Req.Context(), c.SignedInUser)
+			serviceAccount, err := hs.AccessControl.CloneUserToServiceAccount(c.Req.Context(), c.SignedInUser)
 			if err != nil {
 				return response.Error(500, "Unable to clone user to service account", err)
 			}
 			cmd.ServiceAccountId = serviceAccount.Id
+		} else if cmd.ServiceAccountId != 0 {
+			// the key may only be linked to a service account of the organization
+			if _, err := hs.ServiceAccountsService.GetServiceAccount(c.Req.Context(), c.OrgId, cmd.ServiceAccountId); err != nil {
+				if errors.Is(err, serviceaccounts.ErrServiceAccountNotFound) {
+					return response.Error(400, err.Error(), nil)
+				}
+				return response.Error(500, "Failed to get service account", err)
+			}
 		}
+	} else {
+		cmd.ServiceAccountId = 0
 	}
 
 	newKeyInfo, err := apikeygen.New(cmd.OrgId, cmd.Name)
@@ -50,6 +50,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/searchusers"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
@@ -110,6 +111,7 @@ type HTTPServer struct {
 	EncryptionService         encryption.Service
 	SecretsService            secrets.Service
 	DataSourcesService        *datasources.Service
+	ServiceAccountsService    serviceaccounts.Service
 	cleanUpService            *cleanup.CleanUpService
 	tracingService            *tracing.TracingService
 	internalMetricsSvc        *metrics.InternalMetricsService
@@ -141,7 +143,8 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	internalMetricsSvc *metrics.InternalMetricsService, quotaService *quota.QuotaService,
 	socialService social.Service, oauthTokenService oauthtoken.OAuthTokenService,
 	encryptionService encryption.Service, updateChecker *updatechecker.Service, searchUsersService searchusers.Service,
-	dataSourcesService *datasources.Service, secretsService secrets.Service) (*HTTPServer, error) {
+	dataSourcesService *datasources.Service, secretsService secrets.Service,
+	serviceAccountsService serviceaccounts.Service) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
 
@@ -194,6 +197,7 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		EncryptionService:         encryptionService,
 		SecretsService:            secretsService,
 		DataSourcesService:        dataSourcesService,
+		ServiceAccountsService:    serviceAccountsService,
 		searchUsersService:        searchUsersService,
 	}
 	if hs.Listener != nil {
@@ -8,6 +8,7 @@ import (
 // API related actions
 const (
 	ActionProvisioningReload = "provisioning:reload"
+	ActionProvisioningRead   = "provisioning:read"
 
 	ActionDatasourcesRead   = "datasources:read"
 	ActionDatasourcesQuery  = "datasources:query"
@@ -33,6 +34,8 @@ var (
 	ScopeProvisionersPlugins       = accesscontrol.Scope("provisioners", "plugins")
 	ScopeProvisionersDatasources   = accesscontrol.Scope("provisioners", "datasources")
 	ScopeProvisionersNotifications = accesscontrol.Scope("provisioners", "notifications")
+	ScopeProvisionersAlerting      = accesscontrol.Scope("provisioners", "alerting")
+	ScopeProvisionersOrgs          = accesscontrol.Scope("provisioners", "orgs")
 
 	ScopeDatasourcesAll = accesscontrol.Scope("datasources", "*")
 	ScopeDatasourceID   = accesscontrol.Scope("datasources", "id", accesscontrol.Parameter(":id"))
@@ -53,14 +56,18 @@ func (hs *HTTPServer) declareFixedRoles() error {
 	registrations := []accesscontrol.RoleRegistration{
 		{
 			Role: accesscontrol.RoleDTO{
-				Version:     1,
+				Version:     2,
 				Name:        "fixed:provisioning:admin",
-				Description: "Reload provisioning configurations",
+				Description: "Reload provisioning configurations and read their status",
 				Permissions: []accesscontrol.Permission{
 					{
 						Action: ActionProvisioningReload,
 						Scope:  ScopeProvisionersAll,
 					},
+					{
+						Action: ActionProvisioningRead,
+						Scope:  ScopeProvisionersAll,
+					},
 				},
 			},
 			Grants: []string{accesscontrol.RoleGrafanaAdmin},
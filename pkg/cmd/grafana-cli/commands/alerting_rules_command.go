@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/promrules"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	// the defaults of the scheduler of unified alerting
+	alertingBaseInterval        = 10 * time.Second
+	alertingDefaultInterval     = 60 * time.Second
+	alertingRulesFileMode       = 0640
+	alertingRulesOutputFlag     = "output"
+	alertingRulesOrgIDFlag      = "org-id"
+	alertingRulesDatasourceFlag = "datasource-uid"
+	alertingRulesOverwriteFlag  = "overwrite"
+	alertingDefaultOrgID        = 1
+)
+
+func newAlertRuleStore(sqlStore *sqlstore.SQLStore) store.DBstore {
+	baseInterval := alertingBaseInterval
+	if sqlStore.Cfg != nil && sqlStore.Cfg.AlertingBaseInterval > 0 {
+		baseInterval = sqlStore.Cfg.AlertingBaseInterval * time.Second
+	}
+	return store.DBstore{
+		BaseInterval:    baseInterval,
+		DefaultInterval: alertingDefaultInterval,
+		SQLStore:        sqlStore,
+		Logger:          log.New("grafana-cli.alerting"),
+	}
+}
+
+func alertingOrgID(c utils.CommandLine) int64 {
+	if orgID := c.Int(alertingRulesOrgIDFlag); orgID > 0 {
+		return int64(orgID)
+	}
+	return alertingDefaultOrgID
+}
+
+func exportAlertRulesCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	folderTitle, groupName := c.Args().Get(0), c.Args().Get(1)
+	if folderTitle == "" {
+		return fmt.Errorf("missing folder title")
+	}
+
+	orgID := alertingOrgID(c)
+	folder, err := sqlStore.GetFolderByTitle(orgID, folderTitle)
+	if err != nil {
+		return fmt.Errorf("failed to get folder %q: %w", folderTitle, err)
+	}
+
+	ruleStore := newAlertRuleStore(sqlStore)
+	var rules []*ngmodels.AlertRule
+	if groupName == "" {
+		q := ngmodels.ListNamespaceAlertRulesQuery{OrgID: orgID, NamespaceUID: folder.Uid}
+		if err := ruleStore.GetNamespaceAlertRules(&q); err != nil {
+			return fmt.Errorf("failed to get the alert rules of folder %q: %w", folderTitle, err)
+		}
+		rules = q.Result
+	} else {
+		q := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: orgID, NamespaceUID: folder.Uid, RuleGroup: groupName}
+		if err := ruleStore.GetRuleGroupAlertRules(&q); err != nil {
+			return fmt.Errorf("failed to get the alert rules of rule group %q: %w", groupName, err)
+		}
+		if len(q.Result) == 0 {
+			return fmt.Errorf("rule group %q not found in folder %q", groupName, folderTitle)
+		}
+		rules = q.Result
+	}
+
+	isPrometheus := func(uid string) bool {
+		q := models.GetDataSourceQuery{Uid: uid, OrgId: orgID}
+		return sqlStore.GetDataSource(context.Background(), &q) == nil && q.Result.Type == models.DS_PROMETHEUS
+	}
+	b, err := promrules.Export(rules, isPrometheus).Marshal()
+	if err != nil {
+		return err
+	}
+
+	output := c.String(alertingRulesOutputFlag)
+	if output == "" {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	if err := os.WriteFile(output, b, alertingRulesFileMode); err != nil {
+		return fmt.Errorf("failed to write rule file: %w", err)
+	}
+
+	logger.Infof("%s %d alert rules exported to %s\n", color.GreenString("✔"), len(rules), output)
+	return nil
+}
+
+func importAlertRulesCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	folderTitle, path := c.Args().Get(0), c.Args().Get(1)
+	if folderTitle == "" || path == "" {
+		return fmt.Errorf("missing folder title or rule file")
+	}
+
+	orgID := alertingOrgID(c)
+	folder, err := sqlStore.GetFolderByTitle(orgID, folderTitle)
+	if err != nil {
+		return fmt.Errorf("failed to get folder %q: %w", folderTitle, err)
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path is given by the administrator running the command
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rule file: %w", err)
+	}
+	f, err := promrules.ParseRuleFile(b)
+	if err != nil {
+		return err
+	}
+
+	datasourceUID := c.String(alertingRulesDatasourceFlag)
+	if datasourceUID != "" {
+		q := models.GetDataSourceQuery{Uid: datasourceUID, OrgId: orgID}
+		if err := sqlStore.GetDataSource(context.Background(), &q); err != nil {
+			return fmt.Errorf("failed to get data source %q: %w", datasourceUID, err)
+		}
+		if q.Result.Type != models.DS_PROMETHEUS {
+			return fmt.Errorf("data source %q is not a Prometheus data source", datasourceUID)
+		}
+	}
+
+	ruleStore := newAlertRuleStore(sqlStore)
+	q := ngmodels.ListNamespaceAlertRulesQuery{OrgID: orgID, NamespaceUID: folder.Uid}
+	if err := ruleStore.GetNamespaceAlertRules(&q); err != nil {
+		return fmt.Errorf("failed to get the alert rules of folder %q: %w", folderTitle, err)
+	}
+
+	groups, err := promrules.Import(f, datasourceUID, q.Result, c.Bool(alertingRulesOverwriteFlag))
+	if err != nil {
+		return err
+	}
+
+	cmds := make([]store.UpdateRuleGroupCmd, 0, len(groups))
+	for _, g := range groups {
+		cmds = append(cmds, store.UpdateRuleGroupCmd{
+			OrgID:           orgID,
+			NamespaceUID:    folder.Uid,
+			RuleGroupConfig: g,
+		})
+	}
+	if err := ruleStore.UpdateRuleGroups(cmds); err != nil {
+		return fmt.Errorf("failed to import rule groups: %w", err)
+	}
+
+	logger.Infof("%s %d rule groups imported to folder %q\n", color.GreenString("✔"), len(groups), folderTitle)
+	return nil
+}
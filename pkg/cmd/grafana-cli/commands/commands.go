@@ -135,6 +135,48 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "alerting",
+		Usage: "Exports and imports unified alerting rules",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "export-rules",
+				Usage:  "export-rules <folder> <rule group (optional)>",
+				Action: runDbCommand(exportAlertRulesCommand),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  alertingRulesOrgIDFlag,
+						Usage: "ID of the organization of the folder",
+						Value: alertingDefaultOrgID,
+					},
+					&cli.StringFlag{
+						Name:  alertingRulesOutputFlag,
+						Usage: "Path of the rule file to write, defaults to the standard output",
+					},
+				},
+			},
+			{
+				Name:   "import-rules",
+				Usage:  "import-rules <folder> <rule file>",
+				Action: runDbCommand(importAlertRulesCommand),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  alertingRulesOrgIDFlag,
+						Usage: "ID of the organization of the folder",
+						Value: alertingDefaultOrgID,
+					},
+					&cli.StringFlag{
+						Name:  alertingRulesDatasourceFlag,
+						Usage: "UID of the Prometheus data source that the Prometheus rules of the file query",
+					},
+					&cli.BoolFlag{
+						Name:  alertingRulesOverwriteFlag,
+						Usage: "Replace the rule groups of the folder that have the same name as rule groups of the file",
+					},
+				},
+			},
+		},
+	},
 }
 
 var cueCommands = []*cli.Command{
@@ -78,9 +78,12 @@ func CalculateDiff(options *Options) (*Result, error) {
 		return nil, err
 	}
 
-	baseData := baseVersionQuery.Result.Data
-	newData := newVersionQuery.Result.Data
+	return CalculateJSONDiff(baseVersionQuery.Result.Data, newVersionQuery.Result.Data, options.DiffType)
+}
 
+// CalculateJSONDiff computes the diff of two JSON documents in the format of
+// the diff type, assigning the delta of the diff to the `Delta` field.
+func CalculateJSONDiff(baseData, newData *simplejson.Json, diffType DiffType) (*Result, error) {
 	left, jsonDiff, err := getDiff(baseData, newData)
 	if err != nil {
 		return nil, err
@@ -88,7 +91,7 @@ func CalculateDiff(options *Options) (*Result, error) {
 
 	result := &Result{}
 
-	switch options.DiffType {
+	switch diffType {
 	case DiffDelta:
 
 		deltaOutput, err := deltaFormatter.NewDeltaFormatter().Format(jsonDiff)
@@ -118,7 +121,7 @@ func CalculateDiff(options *Options) (*Result, error) {
 	return result, nil
 }
 
-// getDiff computes the diff of two dashboard versions.
+// getDiff computes the diff of two JSON documents.
 func getDiff(baseData, newData *simplejson.Json) (interface{}, diff.Diff, error) {
 	leftBytes, err := baseData.Encode()
 	if err != nil {
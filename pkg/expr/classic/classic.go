@@ -33,6 +33,8 @@ type ClassicConditionJSON struct {
 	Reducer struct {
 		// Params []interface{} `json:"params"` (Unused)
 		Type string `json:"type"`
+		// Settings is how the reducer handles non-number values, the same way as the reduce expression.
+		Settings *mathexp.ReduceSettings `json:"settings,omitempty"`
 	} `json:"reducer"`
 }
 
@@ -43,10 +45,12 @@ type ConditionEvalJSON struct {
 
 // condition is a single condition within the ConditionsCmd.
 type condition struct {
-	QueryRefID string
-	Reducer    classicReducer
-	Evaluator  evaluator
-	Operator   string
+	QueryRefID    string
+	Reducer       classicReducer
+	ReducerMode   string
+	ReducerMapper mathexp.ReduceMapper
+	Evaluator     evaluator
+	Operator      string
 }
 
 type classicReducer string
@@ -104,7 +108,7 @@ func (ccc *ConditionsCmd) Execute(ctx context.Context, vars mathexp.Vars) (mathe
 				return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
 			}
 
-			reducedNum := c.Reducer.Reduce(series)
+			reducedNum := c.Reducer.ReduceWithMode(series, c.ReducerMode, c.ReducerMapper)
 
 			// TODO handle error / no data signals
 			thisCondNoDataFound := reducedNum.GetFloat64Value() == nil
@@ -208,6 +212,13 @@ func UnmarshalConditionsCmd(rawQuery map[string]interface{}, refID string) (*Con
 		if !cond.Reducer.ValidReduceFunc() {
 			return nil, fmt.Errorf("reducer '%v' in condition %v is not a valid reducer", cond.Reducer, i+1)
 		}
+		if cj.Reducer.Settings != nil {
+			cond.ReducerMode = cj.Reducer.Settings.Mode
+			cond.ReducerMapper, err = cj.Reducer.Settings.Mapper()
+			if err != nil {
+				return nil, fmt.Errorf("reducer '%v' in condition %v: %w", cond.Reducer, i+1, err)
+			}
+		}
 
 		cond.Evaluator, err = newAlertEvaluator(cj.Evaluator)
 		if err != nil {
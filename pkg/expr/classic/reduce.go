@@ -4,6 +4,7 @@ import (
 	"math"
 	"sort"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
@@ -13,12 +14,37 @@ func nilOrNaN(f *float64) bool {
 
 func (cr classicReducer) ValidReduceFunc() bool {
 	switch cr {
-	case "avg", "sum", "min", "max", "count", "last", "median":
+	case "avg", "sum", "min", "max", "count", "last", "first", "median", "stddev":
 		return true
 	case "diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null":
 		return true
 	}
-	return false
+	_, ok, err := mathexp.ParsePercentileReducer(string(cr))
+	return ok && err == nil
+}
+
+// ReduceWithMode reduces the series the same way as the reduce expression with the same mode.
+// In the strict mode, a null or NaN value makes the result NaN, except for the count reducers.
+// In the other modes, the mapper maps the values of the series before they are reduced.
+// Without a mode, the reducers skip null and NaN values.
+func (cr classicReducer) ReduceWithMode(series mathexp.Series, mode string, mapper mathexp.ReduceMapper) mathexp.Number {
+	if mode == mathexp.ReduceModeStrict && cr != "count" && cr != "count_non_null" {
+		for i := 0; i < series.Len(); i++ {
+			if nilOrNaN(series.GetValue(i)) {
+				num := mathexp.NewNumber("", nil)
+				if series.GetLabels() != nil {
+					num.SetLabels(series.GetLabels().Copy())
+				}
+				nan := math.NaN()
+				num.SetValue(&nan)
+				return num
+			}
+		}
+	}
+	if mapper != nil {
+		series = mapper.MapInput(series)
+	}
+	return cr.Reduce(series)
 }
 
 //nolint: gocyclo
@@ -98,6 +124,15 @@ func (cr classicReducer) Reduce(series mathexp.Series) mathexp.Number {
 	case "count":
 		value = float64(ff.Len())
 		allNull = false
+	case "first":
+		for i := 0; i < ff.Len(); i++ {
+			f := ff.GetValue(i)
+			if !nilOrNaN(f) {
+				value = *f
+				allNull = false
+				break
+			}
+		}
 	case "last":
 		for i := ff.Len() - 1; i >= 0; i-- {
 			f := ff.GetValue(i)
@@ -126,6 +161,11 @@ func (cr classicReducer) Reduce(series mathexp.Series) mathexp.Number {
 				value = (values[(length/2)-1] + values[length/2]) / 2
 			}
 		}
+	case "stddev":
+		if values := validValues(ff); values.Len() > 0 {
+			value = *mathexp.Stddev(values)
+			allNull = false
+		}
 	case "diff":
 		allNull, value = calculateDiff(ff, allNull, value, diff)
 	case "diff_abs":
@@ -146,6 +186,13 @@ func (cr classicReducer) Reduce(series mathexp.Series) mathexp.Number {
 		if value > 0 {
 			allNull = false
 		}
+	default:
+		if p, ok, err := mathexp.ParsePercentileReducer(string(cr)); ok && err == nil {
+			if values := validValues(ff); values.Len() > 0 {
+				value = *mathexp.Percentile(values, p)
+				allNull = false
+			}
+		}
 	}
 
 	if allNull {
@@ -156,6 +203,18 @@ func (cr classicReducer) Reduce(series mathexp.Series) mathexp.Number {
 	return num
 }
 
+// validValues returns the values of the field that are not null or NaN.
+func validValues(ff mathexp.Float64Field) *mathexp.Float64Field {
+	values := make([]float64, 0, ff.Len())
+	for i := 0; i < ff.Len(); i++ {
+		if f := ff.GetValue(i); !nilOrNaN(f) {
+			values = append(values, *f)
+		}
+	}
+	field := mathexp.Float64Field(*data.NewField("", nil, values))
+	return &field
+}
+
 func calculateDiff(ff mathexp.Float64Field, allNull bool, value float64, fn func(float64, float64) float64) (bool, float64) {
 	var (
 		first float64
@@ -108,6 +108,30 @@ func TestReducer(t *testing.T) {
 			inputSeries:    valBasedSeries(nil, nil),
 			expectedNumber: valBasedNumber(nil),
 		},
+		{
+			name:           "first with mixed null/real values",
+			reducer:        classicReducer("first"),
+			inputSeries:    valBasedSeries(nil, ptr.Float64(2), ptr.Float64(3)),
+			expectedNumber: valBasedNumber(ptr.Float64(2)),
+		},
+		{
+			name:           "stddev with mixed null/real values",
+			reducer:        classicReducer("stddev"),
+			inputSeries:    valBasedSeries(ptr.Float64(2), nil, ptr.Float64(4)),
+			expectedNumber: valBasedNumber(ptr.Float64(1)),
+		},
+		{
+			name:           "percentile with mixed null/real values",
+			reducer:        classicReducer("percentile(90)"),
+			inputSeries:    valBasedSeries(ptr.Float64(50), nil, ptr.Float64(10), ptr.Float64(30)),
+			expectedNumber: valBasedNumber(ptr.Float64(46)),
+		},
+		{
+			name:           "percentile with no values",
+			reducer:        classicReducer("percentile(90)"),
+			inputSeries:    valBasedSeries(nil, nil),
+			expectedNumber: valBasedNumber(nil),
+		},
 	}
 
 	for _, tt := range tests {
@@ -119,6 +143,73 @@ func TestReducer(t *testing.T) {
 	}
 }
 
+func TestInvalidReducer(t *testing.T) {
+	for _, reducer := range []string{"foo", "percentile(101)", "percentile(foo)"} {
+		require.False(t, classicReducer(reducer).ValidReduceFunc(), reducer)
+	}
+}
+
+func TestReducerWithMode(t *testing.T) {
+	var tests = []struct {
+		name           string
+		reducer        classicReducer
+		mode           string
+		mapper         mathexp.ReduceMapper
+		inputSeries    mathexp.Series
+		expectedNumber mathexp.Number
+	}{
+		{
+			name:           "sum without mode skips null values",
+			reducer:        classicReducer("sum"),
+			inputSeries:    valBasedSeries(ptr.Float64(1), nil, ptr.Float64(3)),
+			expectedNumber: valBasedNumber(ptr.Float64(4)),
+		},
+		{
+			name:           "sum in strict mode with a null value",
+			reducer:        classicReducer("sum"),
+			mode:           mathexp.ReduceModeStrict,
+			inputSeries:    valBasedSeries(ptr.Float64(1), nil, ptr.Float64(3)),
+			expectedNumber: valBasedNumber(ptr.Float64(math.NaN())),
+		},
+		{
+			name:           "count in strict mode with a null value",
+			reducer:        classicReducer("count"),
+			mode:           mathexp.ReduceModeStrict,
+			inputSeries:    valBasedSeries(ptr.Float64(1), nil, ptr.Float64(3)),
+			expectedNumber: valBasedNumber(ptr.Float64(3)),
+		},
+		{
+			name:           "count when dropping non-numbers",
+			reducer:        classicReducer("count"),
+			mode:           mathexp.ReduceModeDropNN,
+			mapper:         mathexp.DropNonNumber{},
+			inputSeries:    valBasedSeries(ptr.Float64(1), nil, ptr.Float64(math.Inf(1))),
+			expectedNumber: valBasedNumber(ptr.Float64(1)),
+		},
+		{
+			name:           "avg when replacing non-numbers",
+			reducer:        classicReducer("avg"),
+			mode:           mathexp.ReduceModeReplaceNN,
+			mapper:         mathexp.ReplaceNonNumberWithValue{Value: 2},
+			inputSeries:    valBasedSeries(ptr.Float64(1), nil, ptr.Float64(3)),
+			expectedNumber: valBasedNumber(ptr.Float64(2)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			num := tt.reducer.ReduceWithMode(tt.inputSeries, tt.mode, tt.mapper)
+			expected, actual := tt.expectedNumber.GetFloat64Value(), num.GetFloat64Value()
+			require.NotNil(t, actual)
+			if math.IsNaN(*expected) {
+				require.True(t, math.IsNaN(*actual))
+				return
+			}
+			require.Equal(t, *expected, *actual)
+		})
+	}
+}
+
 func TestDiffReducer(t *testing.T) {
 	var tests = []struct {
 		name           string
@@ -2,11 +2,14 @@ package expr
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
@@ -71,17 +74,22 @@ func (gm *MathCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.
 type ReduceCommand struct {
 	Reducer     string
 	VarToReduce string
-	refID       string
+	// Mapper maps the values of the series before they are reduced, nil in the strict mode.
+	Mapper mathexp.ReduceMapper
+	refID  string
 }
 
-// NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID, reducer, varToReduce string) *ReduceCommand {
-	// TODO: validate reducer here, before execution
+// NewReduceCommand creates a new ReduceCMD. It returns an error if the reducer is not implemented.
+func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	if err := mathexp.ValidReducer(reducer); err != nil {
+		return nil, err
+	}
 	return &ReduceCommand{
 		Reducer:     reducer,
 		VarToReduce: varToReduce,
+		Mapper:      mapper,
 		refID:       refID,
-	}
+	}, nil
 }
 
 // UnmarshalReduceCommand creates a MathCMD from Grafana's frontend query.
@@ -105,7 +113,26 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 		return nil, fmt.Errorf("expected reducer to be a string, got %T for refId %v", rawReducer, rn.RefID)
 	}
 
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce), nil
+	var mapper mathexp.ReduceMapper
+	if rawSettings, ok := rn.Query["settings"]; ok && rawSettings != nil {
+		settingsJSON, err := json.Marshal(rawSettings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remarshal reduce settings for refId %v: %w", rn.RefID, err)
+		}
+		var settings mathexp.ReduceSettings
+		if err := json.Unmarshal(settingsJSON, &settings); err != nil {
+			return nil, fmt.Errorf("invalid reduce settings for refId %v: %w", rn.RefID, err)
+		}
+		if mapper, err = settings.Mapper(); err != nil {
+			return nil, fmt.Errorf("invalid reduce settings for refId %v: %w", rn.RefID, err)
+		}
+	}
+
+	cmd, err := NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reducer for refId %v: %w", rn.RefID, err)
+	}
+	return cmd, nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -123,7 +150,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathex
 		if !ok {
 			return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
 		}
-		num, err := series.Reduce(gr.refID, gr.Reducer)
+		num, err := series.Reduce(gr.refID, gr.Reducer, gr.Mapper)
 		if err != nil {
 			return newRes, err
 		}
@@ -226,6 +253,188 @@ func (gr *ResampleCommand) Execute(ctx context.Context, vars mathexp.Vars) (math
 	return newRes, nil
 }
 
+// ThresholdCommand is an expression command that checks the values of numbers or series
+// against a threshold. Its results are 1 where the values cross the threshold and 0 otherwise.
+type ThresholdCommand struct {
+	ReferenceVar string
+	Evaluator    ThresholdEvaluator
+	// RecoveryEvaluator is the optional recovery threshold of the items that crossed the
+	// threshold in the previous evaluation, the LoadedDimensions. They keep crossing it until
+	// the recovery threshold is crossed, so that alerts do not flap around the threshold.
+	RecoveryEvaluator *ThresholdEvaluator
+	LoadedDimensions  []data.Labels
+	refID             string
+}
+
+// ThresholdEvaluator checks if a value crosses a threshold.
+type ThresholdEvaluator struct {
+	// Type is one of gt, lt, within_range or outside_range.
+	Type string `json:"type"`
+	// Params holds the threshold, or the two bounds of the range.
+	Params []float64 `json:"params"`
+}
+
+// validate returns an error if the evaluator is not supported.
+func (te ThresholdEvaluator) validate() error {
+	switch te.Type {
+	case "gt", "lt":
+		if len(te.Params) != 1 {
+			return fmt.Errorf("threshold evaluator %s requires one parameter, got %d", te.Type, len(te.Params))
+		}
+	case "within_range", "outside_range":
+		if len(te.Params) != 2 {
+			return fmt.Errorf("threshold evaluator %s requires two parameters, got %d", te.Type, len(te.Params))
+		}
+	default:
+		return fmt.Errorf("threshold evaluator type '%v' not implemented", te.Type)
+	}
+	return nil
+}
+
+// Eval returns true if the value crosses the threshold. Ranges include their bounds,
+// in either order.
+func (te ThresholdEvaluator) Eval(f float64) bool {
+	switch te.Type {
+	case "gt":
+		return f > te.Params[0]
+	case "lt":
+		return f < te.Params[0]
+	case "within_range", "outside_range":
+		lower, upper := te.Params[0], te.Params[1]
+		if lower > upper {
+			lower, upper = upper, lower
+		}
+		within := f >= lower && f <= upper
+		if te.Type == "within_range" {
+			return within
+		}
+		return !within && !math.IsNaN(f)
+	}
+	return false
+}
+
+// NewThresholdCommand creates a new ThresholdCommand. It returns an error if the evaluators are invalid.
+func NewThresholdCommand(refID, referenceVar string, evaluator ThresholdEvaluator, recovery *ThresholdEvaluator, loadedDimensions []data.Labels) (*ThresholdCommand, error) {
+	if err := evaluator.validate(); err != nil {
+		return nil, err
+	}
+	if recovery != nil {
+		if err := recovery.validate(); err != nil {
+			return nil, fmt.Errorf("invalid recovery threshold: %w", err)
+		}
+		// the recovery threshold must be on the other side of the threshold
+		switch {
+		case evaluator.Type == "gt" && recovery.Type == "lt" && recovery.Params[0] <= evaluator.Params[0]:
+		case evaluator.Type == "lt" && recovery.Type == "gt" && recovery.Params[0] >= evaluator.Params[0]:
+		default:
+			return nil, fmt.Errorf("recovery threshold %s %v is not compatible with threshold %s %v: only gt with a lower lt, or lt with a greater gt, are supported", recovery.Type, recovery.Params, evaluator.Type, evaluator.Params)
+		}
+	}
+	return &ThresholdCommand{
+		ReferenceVar:      referenceVar,
+		Evaluator:         evaluator,
+		RecoveryEvaluator: recovery,
+		LoadedDimensions:  loadedDimensions,
+		refID:             refID,
+	}, nil
+}
+
+// ThresholdConditionJSON is the JSON model of the threshold of a ThresholdCommand.
+type ThresholdConditionJSON struct {
+	Evaluator       ThresholdEvaluator  `json:"evaluator"`
+	UnloadEvaluator *ThresholdEvaluator `json:"unloadEvaluator,omitempty"`
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to check the threshold of for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	var model struct {
+		Conditions       []ThresholdConditionJSON `json:"conditions"`
+		LoadedDimensions []data.Labels            `json:"loadedDimensions"`
+	}
+	modelJSON, err := json.Marshal(rn.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal threshold command for refId %v: %w", rn.RefID, err)
+	}
+	if err := json.Unmarshal(modelJSON, &model); err != nil {
+		return nil, fmt.Errorf("invalid threshold command for refId %v: %w", rn.RefID, err)
+	}
+	if len(model.Conditions) != 1 {
+		return nil, fmt.Errorf("threshold command for refId %v requires exactly one condition, got %d", rn.RefID, len(model.Conditions))
+	}
+
+	cond := model.Conditions[0]
+	cmd, err := NewThresholdCommand(rn.RefID, referenceVar, cond.Evaluator, cond.UnloadEvaluator, model.LoadedDimensions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold command for refId %v: %w", rn.RefID, err)
+	}
+	return cmd, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (tc *ThresholdCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	loaded := make(map[string]struct{}, len(tc.LoadedDimensions))
+	for _, l := range tc.LoadedDimensions {
+		loaded[l.String()] = struct{}{}
+	}
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		eval := tc.Evaluator.Eval
+		if _, ok := loaded[val.GetLabels().String()]; ok && tc.RecoveryEvaluator != nil {
+			recovery := tc.RecoveryEvaluator.Eval
+			eval = func(f float64) bool {
+				return !math.IsNaN(f) && !recovery(f)
+			}
+		}
+		check := func(f *float64) *float64 {
+			if f == nil {
+				return nil
+			}
+			r := 0.0
+			if eval(*f) {
+				r = 1
+			}
+			return &r
+		}
+
+		switch v := val.(type) {
+		case mathexp.Number:
+			n := mathexp.NewNumber(tc.refID, v.GetLabels())
+			n.SetValue(check(v.GetFloat64Value()))
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.Series:
+			s := mathexp.NewSeries(tc.refID, v.GetLabels(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				if err := s.SetPoint(i, t, check(f)); err != nil {
+					return newRes, err
+				}
+			}
+			newRes.Values = append(newRes.Values, s)
+		default:
+			return newRes, fmt.Errorf("can only check the threshold of numbers or series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -240,6 +449,8 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeThreshold is the CMDType for checking values against a threshold.
+	TypeThreshold
 )
 
 func (gt CommandType) String() string {
@@ -252,6 +463,8 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
 	default:
 		return "unknown"
 	}
@@ -268,6 +481,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,212 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalThresholdCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       map[string]interface{}
+		expected    *ThresholdCommand
+		errContains string
+	}{
+		{
+			name: "threshold",
+			query: map[string]interface{}{
+				"type":       "threshold",
+				"expression": "$B",
+				"conditions": []interface{}{
+					map[string]interface{}{"evaluator": map[string]interface{}{"type": "gt", "params": []interface{}{80}}},
+				},
+			},
+			expected: &ThresholdCommand{
+				ReferenceVar: "B",
+				Evaluator:    ThresholdEvaluator{Type: "gt", Params: []float64{80}},
+				refID:        "A",
+			},
+		},
+		{
+			name: "threshold with recovery and loaded dimensions",
+			query: map[string]interface{}{
+				"type":       "threshold",
+				"expression": "B",
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"evaluator":       map[string]interface{}{"type": "gt", "params": []interface{}{80}},
+						"unloadEvaluator": map[string]interface{}{"type": "lt", "params": []interface{}{70}},
+					},
+				},
+				"loadedDimensions": []interface{}{map[string]interface{}{"host": "a"}},
+			},
+			expected: &ThresholdCommand{
+				ReferenceVar:      "B",
+				Evaluator:         ThresholdEvaluator{Type: "gt", Params: []float64{80}},
+				RecoveryEvaluator: &ThresholdEvaluator{Type: "lt", Params: []float64{70}},
+				LoadedDimensions:  []data.Labels{{"host": "a"}},
+				refID:             "A",
+			},
+		},
+		{
+			name: "recovery on the wrong side of the threshold",
+			query: map[string]interface{}{
+				"type":       "threshold",
+				"expression": "B",
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"evaluator":       map[string]interface{}{"type": "gt", "params": []interface{}{80}},
+						"unloadEvaluator": map[string]interface{}{"type": "lt", "params": []interface{}{90}},
+					},
+				},
+			},
+			errContains: "not compatible",
+		},
+		{
+			name: "range requires two parameters",
+			query: map[string]interface{}{
+				"type":       "threshold",
+				"expression": "B",
+				"conditions": []interface{}{
+					map[string]interface{}{"evaluator": map[string]interface{}{"type": "within_range", "params": []interface{}{1}}},
+				},
+			},
+			errContains: "requires two parameters",
+		},
+		{
+			name: "unknown evaluator",
+			query: map[string]interface{}{
+				"type":       "threshold",
+				"expression": "B",
+				"conditions": []interface{}{
+					map[string]interface{}{"evaluator": map[string]interface{}{"type": "eq", "params": []interface{}{1}}},
+				},
+			},
+			errContains: "not implemented",
+		},
+		{
+			name: "missing condition",
+			query: map[string]interface{}{
+				"type":       "threshold",
+				"expression": "B",
+			},
+			errContains: "exactly one condition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "A", Query: tt.query})
+			if tt.errContains != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, cmd)
+		})
+	}
+}
+
+func TestThresholdCommandExecute(t *testing.T) {
+	number := func(labels data.Labels, f *float64) mathexp.Number {
+		n := mathexp.NewNumber("B", labels)
+		n.SetValue(f)
+		return n
+	}
+	fp := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name      string
+		evaluator ThresholdEvaluator
+		recovery  *ThresholdEvaluator
+		loaded    []data.Labels
+		input     []*float64
+		expected  []*float64
+	}{
+		{
+			name:      "gt",
+			evaluator: ThresholdEvaluator{Type: "gt", Params: []float64{80}},
+			input:     []*float64{fp(79), fp(80), fp(81), nil},
+			expected:  []*float64{fp(0), fp(0), fp(1), nil},
+		},
+		{
+			name:      "lt",
+			evaluator: ThresholdEvaluator{Type: "lt", Params: []float64{10}},
+			input:     []*float64{fp(9), fp(10), fp(11)},
+			expected:  []*float64{fp(1), fp(0), fp(0)},
+		},
+		{
+			name:      "within_range includes the bounds",
+			evaluator: ThresholdEvaluator{Type: "within_range", Params: []float64{10, 5}},
+			input:     []*float64{fp(4), fp(5), fp(7), fp(10), fp(11)},
+			expected:  []*float64{fp(0), fp(1), fp(1), fp(1), fp(0)},
+		},
+		{
+			name:      "outside_range",
+			evaluator: ThresholdEvaluator{Type: "outside_range", Params: []float64{5, 10}},
+			input:     []*float64{fp(4), fp(5), fp(10), fp(11)},
+			expected:  []*float64{fp(1), fp(0), fp(0), fp(1)},
+		},
+		{
+			name:      "recovery threshold is not used for dimensions that are not loaded",
+			evaluator: ThresholdEvaluator{Type: "gt", Params: []float64{80}},
+			recovery:  &ThresholdEvaluator{Type: "lt", Params: []float64{70}},
+			input:     []*float64{fp(75)},
+			expected:  []*float64{fp(0)},
+		},
+		{
+			name:      "loaded dimensions keep firing until they recover",
+			evaluator: ThresholdEvaluator{Type: "gt", Params: []float64{80}},
+			recovery:  &ThresholdEvaluator{Type: "lt", Params: []float64{70}},
+			loaded:    []data.Labels{{"host": "a"}},
+			input:     []*float64{fp(75), fp(70), fp(69)},
+			expected:  []*float64{fp(1), fp(1), fp(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := NewThresholdCommand("A", "B", tt.evaluator, tt.recovery, tt.loaded)
+			require.NoError(t, err)
+
+			vars := mathexp.Vars{"B": mathexp.Results{}}
+			for _, f := range tt.input {
+				vars["B"] = mathexp.Results{Values: append(vars["B"].Values, number(data.Labels{"host": "a"}, f))}
+			}
+			res, err := cmd.Execute(context.Background(), vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, len(tt.expected))
+			for i, v := range res.Values {
+				require.Equal(t, tt.expected[i], v.(mathexp.Number).GetFloat64Value(), "value %d", i)
+				require.Equal(t, data.Labels{"host": "a"}, v.GetLabels())
+			}
+		})
+	}
+
+	t.Run("series", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("A", "B", ThresholdEvaluator{Type: "gt", Params: []float64{1}}, nil, nil)
+		require.NoError(t, err)
+
+		s := mathexp.NewSeries("B", data.Labels{"host": "a"}, 3)
+		for i, f := range []*float64{fp(0), nil, fp(2)} {
+			require.NoError(t, s.SetPoint(i, time.Unix(int64(i), 0), f))
+		}
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{"B": mathexp.Results{Values: mathexp.Values{s}}})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+
+		out := res.Values[0].(mathexp.Series)
+		require.Equal(t, "A", out.Frame.Fields[1].Name)
+		for i, expected := range []*float64{fp(0), nil, fp(1)} {
+			ts, f := out.GetPoint(i)
+			require.Equal(t, time.Unix(int64(i), 0), ts)
+			require.Equal(t, expected, f)
+		}
+	})
+}
@@ -144,6 +144,7 @@ func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 			TimeRange:     query.TimeRange,
 			QueryType:     query.QueryType,
 			DatasourceUID: query.DatasourceUID,
+			Results:       query.Results,
 		}
 
 		isExpr, err := rn.IsExpressionQuery()
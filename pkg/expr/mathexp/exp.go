@@ -5,6 +5,7 @@ import (
 	"math"
 	"reflect"
 	"runtime"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
@@ -242,6 +243,133 @@ func union(aResults, bResults Results) []*Union {
 	return unions
 }
 
+// vectorMatchingUnion creates Union objects by matching the labels of the Series or Numbers
+// of both sides of a binary operation with PromQL-style on/ignoring and group_left/group_right
+// modifiers. Items without a match are dropped and described in the returned warnings.
+// It returns an error if the matching is ambiguous or if nothing matches at all.
+func vectorMatchingUnion(aResults, bResults Results, vm *parse.VectorMatching) ([]*Union, []string, error) {
+	unions := []*Union{}
+	if len(aResults.Values) == 0 || len(bResults.Values) == 0 {
+		return unions, nil, nil
+	}
+	for _, v := range append(append(Values{}, aResults.Values...), bResults.Values...) {
+		if v.Type() == parse.TypeScalar {
+			return nil, nil, fmt.Errorf("%s can not be used with a scalar", vm)
+		}
+	}
+
+	signature := func(v Value) string {
+		return matchingLabels(v.GetLabels(), vm).String()
+	}
+
+	// the "one" side must have a single item for each signature, and so must
+	// the other side for one-to-one matching
+	manyResults, oneResults, manySide, oneSide := aResults, bResults, "left", "right"
+	if vm.Card == parse.CardOneToMany {
+		manyResults, oneResults, manySide, oneSide = bResults, aResults, "right", "left"
+	}
+	oneBySignature, err := uniqueBySignature(oneResults, oneSide, vm, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	if vm.Card == parse.CardOneToOne {
+		if _, err := uniqueBySignature(manyResults, manySide, vm, signature); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	matched := make(map[string]struct{}, len(oneBySignature))
+	var unmatched []string
+	for _, many := range manyResults.Values {
+		sig := signature(many)
+		one, ok := oneBySignature[sig]
+		if !ok {
+			unmatched = append(unmatched, fmt.Sprintf("%s side {%s}", manySide, many.GetLabels()))
+			continue
+		}
+		matched[sig] = struct{}{}
+
+		u := &Union{Labels: resultLabels(many.GetLabels(), one.GetLabels(), vm), A: many, B: one}
+		if vm.Card == parse.CardOneToMany {
+			u.A, u.B = one, many
+		}
+		unions = append(unions, u)
+	}
+	for _, one := range oneResults.Values {
+		if _, ok := matched[signature(one)]; !ok {
+			unmatched = append(unmatched, fmt.Sprintf("%s side {%s}", oneSide, one.GetLabels()))
+		}
+	}
+
+	if len(unions) == 0 {
+		return nil, nil, fmt.Errorf("no series matched with %s: %s", vm, strings.Join(unmatched, ", "))
+	}
+	var warnings []string
+	if len(unmatched) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d series without a match with %s were dropped: %s", len(unmatched), vm, strings.Join(unmatched, ", ")))
+	}
+	return unions, warnings, nil
+}
+
+// uniqueBySignature maps the items of the results by signature, and returns an error
+// if several items have the same signature.
+func uniqueBySignature(results Results, side string, vm *parse.VectorMatching, signature func(Value) string) (map[string]Value, error) {
+	bySignature := make(map[string]Value, len(results.Values))
+	for _, v := range results.Values {
+		sig := signature(v)
+		if other, ok := bySignature[sig]; ok {
+			hint := ""
+			if vm.Card == parse.CardOneToOne {
+				hint = ", use group_left or group_right for many-to-one matching"
+			}
+			return nil, fmt.Errorf("found duplicate series for the match group {%s} on the %s side of %s: {%s} and {%s}%s", sig, side, vm, other.GetLabels(), v.GetLabels(), hint)
+		}
+		bySignature[sig] = v
+	}
+	return bySignature, nil
+}
+
+// matchingLabels returns the labels the items are matched on.
+func matchingLabels(labels data.Labels, vm *parse.VectorMatching) data.Labels {
+	result := data.Labels{}
+	if vm.On {
+		for _, name := range vm.MatchingLabels {
+			if value, ok := labels[name]; ok {
+				result[name] = value
+			}
+		}
+		return result
+	}
+	for name, value := range labels {
+		result[name] = value
+	}
+	for _, name := range vm.MatchingLabels {
+		delete(result, name)
+	}
+	return result
+}
+
+// resultLabels returns the labels of the result of a matched operation. For one-to-one
+// matching they are the matching labels, otherwise the labels of the "many" side with
+// the included labels of the "one" side.
+func resultLabels(many, one data.Labels, vm *parse.VectorMatching) data.Labels {
+	if vm.Card == parse.CardOneToOne {
+		return matchingLabels(many, vm)
+	}
+	result := many.Copy()
+	if result == nil {
+		result = data.Labels{}
+	}
+	for _, name := range vm.Include {
+		if value, ok := one[name]; ok {
+			result[name] = value
+		} else {
+			delete(result, name)
+		}
+	}
+	return result
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -252,7 +380,16 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	var warnings []string
+	if node.VectorMatching != nil {
+		unions, warnings, err = vectorMatchingUnion(ar, br, node.VectorMatching)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = union(ar, br)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -321,9 +458,20 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 		}
 		res.Values = append(res.Values, value)
 	}
+	addWarnings(res, warnings)
 	return res, nil
 }
 
+// addWarnings adds the warnings as notices to the frames of the results.
+func addWarnings(res Results, warnings []string) {
+	for _, v := range res.Values {
+		frame := v.AsDataFrame()
+		for _, w := range warnings {
+			frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: w})
+		}
+	}
+}
+
 // binaryOp performs a binary operations (e.g. A+B or A>B) on two
 // float values
 // nolint:gocyclo
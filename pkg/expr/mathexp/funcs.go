@@ -1,7 +1,12 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -17,6 +22,76 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             log,
 	},
+	"round": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             round,
+	},
+	"ceil": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             ceil,
+	},
+	"floor": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             floor,
+	},
+	"exp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             exp,
+	},
+	"sqrt": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             sqrt,
+	},
+	"pow": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             pow,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"increase": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      increase,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"timeshift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      timeShift,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumSum,
+	},
 	"nan": {
 		Return: parse.TypeScalar,
 		F:      nan,
@@ -33,28 +108,178 @@ var builtins = map[string]parse.Func{
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
 func abs(e *State, varSet Results) (Results, error) {
-	newRes := Results{}
-	for _, res := range varSet.Values {
-		newVal, err := perFloat(e, res, math.Abs)
-		if err != nil {
-			return newRes, err
-		}
-		newRes.Values = append(newRes.Values, newVal)
-	}
-	return newRes, nil
+	return perFloatResults(e, varSet, math.Abs)
 }
 
 // log returns the natural logarithm value for each result in NumberSet, SeriesSet, or Scalar
 func log(e *State, varSet Results) (Results, error) {
-	newRes := Results{}
-	for _, res := range varSet.Values {
-		newVal, err := perFloat(e, res, math.Log)
-		if err != nil {
-			return newRes, err
+	return perFloatResults(e, varSet, math.Log)
+}
+
+// round returns the nearest integer, rounding half away from zero, for each result in NumberSet, SeriesSet, or Scalar
+func round(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Round)
+}
+
+// ceil returns the least integer greater than or equal to each result in NumberSet, SeriesSet, or Scalar
+func ceil(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Ceil)
+}
+
+// floor returns the greatest integer less than or equal to each result in NumberSet, SeriesSet, or Scalar
+func floor(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Floor)
+}
+
+// exp returns e raised to the power of each result in NumberSet, SeriesSet, or Scalar
+func exp(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Exp)
+}
+
+// sqrt returns the square root of each result in NumberSet, SeriesSet, or Scalar
+func sqrt(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Sqrt)
+}
+
+// pow returns each result in NumberSet, SeriesSet, or Scalar raised to the power of the scalar
+func pow(e *State, varSet Results, powerRes Results) (Results, error) {
+	power, err := scalarArg("pow", powerRes)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(x float64) float64 {
+		return math.Pow(x, power)
+	})
+}
+
+// clampMin returns each result in NumberSet, SeriesSet, or Scalar with a lower bound of the scalar
+func clampMin(e *State, varSet Results, minRes Results) (Results, error) {
+	lower, err := scalarArg("clamp_min", minRes)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(x float64) float64 {
+		return math.Max(x, lower)
+	})
+}
+
+// clampMax returns each result in NumberSet, SeriesSet, or Scalar with an upper bound of the scalar
+func clampMax(e *State, varSet Results, maxRes Results) (Results, error) {
+	upper, err := scalarArg("clamp_max", maxRes)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(x float64) float64 {
+		return math.Min(x, upper)
+	})
+}
+
+// rate returns the per-second rate of increase between consecutive points of each series.
+// A decrease of the value is considered a counter reset. Points at the same time as the
+// previous point are skipped, as the rate between them is undefined.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) (Series, error) {
+		s = advancingInTime(e.RefID, sortedByTime(e.RefID, s))
+		return pointPairs(e.RefID, s, func(prevT time.Time, prev float64, t time.Time, cur float64) float64 {
+			return counterIncrease(prev, cur) / t.Sub(prevT).Seconds()
+		})
+	})
+}
+
+// increase returns the increase between consecutive points of each series.
+// A decrease of the value is considered a counter reset.
+func increase(e *State, varSet Results) (Results, error) {
+	return perPointPair(e, "increase", varSet, func(_ time.Time, prev float64, _ time.Time, cur float64) float64 {
+		return counterIncrease(prev, cur)
+	})
+}
+
+// delta returns the difference between consecutive points of each series.
+func delta(e *State, varSet Results) (Results, error) {
+	return perPointPair(e, "delta", varSet, func(_ time.Time, prev float64, _ time.Time, cur float64) float64 {
+		return cur - prev
+	})
+}
+
+// timeShift moves the points of each series by the duration, which can be negative.
+func timeShift(e *State, varSet Results, rawDuration string) (Results, error) {
+	negative := strings.HasPrefix(rawDuration, "-")
+	d, err := gtime.ParseDuration(strings.TrimPrefix(rawDuration, "-"))
+	if err != nil {
+		return Results{}, fmt.Errorf("timeshift: invalid duration %q: %w", rawDuration, err)
+	}
+	if negative {
+		d = -d
+	}
+	return perSeries(e, "timeshift", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if err := newSeries.SetPoint(i, t.Add(d), f); err != nil {
+				return newSeries, err
+			}
 		}
-		newRes.Values = append(newRes.Values, newVal)
+		return newSeries, nil
+	})
+}
+
+// movingAvg returns the average of the last n points of each series at every point.
+// Null and NaN values are ignored, and the first points are averaged over the available points.
+func movingAvg(e *State, varSet Results, windowRes Results) (Results, error) {
+	window, err := scalarArg("moving_avg", windowRes)
+	if err != nil {
+		return Results{}, err
 	}
-	return newRes, nil
+	if window < 1 || window != math.Trunc(window) {
+		return Results{}, fmt.Errorf("moving_avg: window must be a positive integer, got %v", window)
+	}
+	n := int(window)
+	return perSeries(e, "moving_avg", varSet, func(s Series) (Series, error) {
+		s = sortedByTime(e.RefID, s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			sum, count := 0.0, 0
+			for j := i - n + 1; j <= i; j++ {
+				if j < 0 {
+					continue
+				}
+				if f := s.GetValue(j); f != nil && !math.IsNaN(*f) {
+					sum += *f
+					count++
+				}
+			}
+			var avg *float64
+			if count > 0 {
+				v := sum / float64(count)
+				avg = &v
+			}
+			if err := newSeries.SetPoint(i, s.GetTime(i), avg); err != nil {
+				return newSeries, err
+			}
+		}
+		return newSeries, nil
+	})
+}
+
+// cumSum returns the running sum of each series. Null and NaN values are skipped and are kept in the result.
+func cumSum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) (Series, error) {
+		s = sortedByTime(e.RefID, s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		sum := 0.0
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f != nil && !math.IsNaN(*f) {
+				sum += *f
+				v := sum
+				f = &v
+			}
+			if err := newSeries.SetPoint(i, t, f); err != nil {
+				return newSeries, err
+			}
+		}
+		return newSeries, nil
+	})
 }
 
 // nan returns a scalar nan value
@@ -113,3 +338,108 @@ func perFloat(e *State, val Value, floatF func(x float64) float64) (Value, error
 
 	return newVal, nil
 }
+
+func perFloatResults(e *State, varSet Results, floatF func(x float64) float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, floatF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// scalarArg returns the value of a scalar argument of the function name.
+func scalarArg(name string, res Results) (float64, error) {
+	if len(res.Values) != 1 || res.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("%s: expected a scalar argument", name)
+	}
+	f := res.Values[0].(Scalar).GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s: scalar argument can not be null", name)
+	}
+	return *f, nil
+}
+
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) (Series, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		s, ok := res.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("%s: expected a series but got %s", name, res.Type())
+		}
+		newSeries, err := seriesF(s)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// perPointPair applies pairF to each pair of consecutive points of each series, sorted by time.
+// The result has one point less than the series, at the time of the later point of each pair.
+// The result is null if any of the values is null.
+func perPointPair(e *State, name string, varSet Results, pairF func(prevT time.Time, prev float64, t time.Time, cur float64) float64) (Results, error) {
+	return perSeries(e, name, varSet, func(s Series) (Series, error) {
+		return pointPairs(e.RefID, sortedByTime(e.RefID, s), pairF)
+	})
+}
+
+// pointPairs applies pairF to each pair of consecutive points of the series.
+func pointPairs(refID string, s Series, pairF func(prevT time.Time, prev float64, t time.Time, cur float64) float64) (Series, error) {
+	size := s.Len() - 1
+	if size < 0 {
+		size = 0
+	}
+	newSeries := NewSeries(refID, s.GetLabels(), size)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prev := s.GetPoint(i - 1)
+		t, cur := s.GetPoint(i)
+		var f *float64
+		if prev != nil && cur != nil {
+			v := pairF(prevT, *prev, t, *cur)
+			f = &v
+		}
+		if err := newSeries.SetPoint(i-1, t, f); err != nil {
+			return newSeries, err
+		}
+	}
+	return newSeries, nil
+}
+
+// counterIncrease returns the increase of a counter from prev to cur.
+// If the counter decreased it was reset, and it increased from zero to cur.
+func counterIncrease(prev, cur float64) float64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// sortedByTime returns a copy of the series sorted from oldest to newest.
+func sortedByTime(refID string, s Series) Series {
+	newSeries := NewSeries(refID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		_ = newSeries.SetPoint(i, t, f)
+	}
+	newSeries.SortByTime(false)
+	return newSeries
+}
+
+// advancingInTime returns a copy of the series sorted by time without the points at the same
+// time as the previous point.
+func advancingInTime(refID string, s Series) Series {
+	newSeries := NewSeries(refID, s.GetLabels(), 0)
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if i > 0 && !t.After(newSeries.GetTime(newSeries.Len()-1)) {
+			continue
+		}
+		_ = newSeries.AppendPoint(newSeries.Len(), t, f)
+	}
+	return newSeries
+}
@@ -74,6 +74,403 @@ func TestFunc(t *testing.T) {
 			vars:     Vars{},
 			newErrIs: assert.Error,
 		},
+		{
+			name: "round on number",
+			expr: "round($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(2.5)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name:      "ceil on scalar",
+			expr:      "ceil(1.2)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(2))}},
+		},
+		{
+			name: "floor on series",
+			expr: "floor($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(5, 0), float64Pointer(1.8),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(-1.2),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(-2),
+					}),
+				},
+			},
+		},
+		{
+			name:      "exp on scalar",
+			expr:      "exp(0)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(1))}},
+		},
+		{
+			name: "sqrt on number",
+			expr: "sqrt($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(9)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name: "pow on number",
+			expr: "pow($A, 3)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(2)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(8))}},
+		},
+		{
+			name: "pow with a number exponent - should error",
+			expr: "pow(2, $A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(2)),
+					},
+				},
+			},
+			newErrIs: assert.Error,
+		},
+		{
+			name: "clamp_min and clamp_max on series",
+			expr: "clamp_max(clamp_min($A, 0), 10)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(5, 0), float64Pointer(-5),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(5),
+						}, tp{
+							time.Unix(15, 0), float64Pointer(15),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(5),
+					}, tp{
+						time.Unix(15, 0), float64Pointer(10),
+					}),
+				},
+			},
+		},
+		{
+			name: "rate on series with a counter reset",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(10, 0), float64Pointer(30),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(0, 0), float64Pointer(10),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(10, 0), float64Pointer(2),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(1),
+					}),
+				},
+			},
+		},
+		{
+			name: "rate on series with points at the same time",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(0),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(20),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(30),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(10, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(2),
+					}),
+				},
+			},
+		},
+		{
+			name: "increase on series with a counter reset",
+			expr: "increase($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(30),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(30, 0), nil,
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(10, 0), float64Pointer(20),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(10),
+					}, tp{
+						time.Unix(30, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name: "delta on series",
+			expr: "delta($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(30),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(10),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(10, 0), float64Pointer(20),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(-20),
+					}),
+				},
+			},
+		},
+		{
+			name: "rate on number - should error",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(2)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{},
+		},
+		{
+			name: "timeshift on series",
+			expr: `timeshift($A, "-1m")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(60, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(120, 0), float64Pointer(2),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(60, 0), float64Pointer(2),
+					}),
+				},
+			},
+		},
+		{
+			name: "timeshift with an invalid duration - should error",
+			expr: `timeshift($A, "1 day")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(60, 0), float64Pointer(1),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{},
+		},
+		{
+			name: "moving_avg on series",
+			expr: "moving_avg($A, 2)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(2),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(4),
+						}, tp{
+							time.Unix(20, 0), nil,
+						}, tp{
+							time.Unix(30, 0), nil,
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(2),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(3),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(4),
+					}, tp{
+						time.Unix(30, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name: "moving_avg with an invalid window - should error",
+			expr: "moving_avg($A, 0.5)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(2),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{},
+		},
+		{
+			name: "cumsum on series",
+			expr: "cumsum($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), nil,
+						}, tp{
+							time.Unix(20, 0), float64Pointer(2),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), nil,
+					}, tp{
+						time.Unix(20, 0), float64Pointer(3),
+					}),
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -113,6 +113,15 @@ var lexTests = []lexTest{
 		{itemVar, 0, "$A"},
 		tEOF,
 	}},
+	{"func with underscore and arguments", "clamp_min($A, 0)", []item{
+		{itemFunc, 0, "clamp_min"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemComma, 0, ","},
+		{itemNumber, 0, "0"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
 	// errors
 	{"unclosed quote", "\"", []item{
 		{itemError, 0, "unterminated string"},
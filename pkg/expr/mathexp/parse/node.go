@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -298,6 +299,8 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// VectorMatching holds the label matching modifiers of the operation, if any.
+	VectorMatching *VectorMatching
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,16 +309,82 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.VectorMatching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.VectorMatching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.VectorMatching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.VectorMatching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
+// VectorMatchCardinality is the cardinality of the matching between the two sides of a binary operation.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne matches each item of a side with at most one item of the other side.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne matches many items of the left side with one item of the right side (group_left).
+	CardManyToOne
+	// CardOneToMany matches one item of the left side with many items of the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching holds the PromQL-style modifiers of a binary operation that
+// describe how the labelled items of its sides are matched.
+type VectorMatching struct {
+	Card VectorMatchCardinality
+	// On is true if the items are matched on MatchingLabels only, false if
+	// they are matched on all their labels except MatchingLabels.
+	On             bool
+	MatchingLabels []string
+	// Include are the labels of the "one" side that are added to the result for group_left and group_right.
+	Include []string
+}
+
+// String returns the modifiers as they are written in an expression.
+func (vm *VectorMatching) String() string {
+	keyword := "ignoring"
+	if vm.On {
+		keyword = "on"
+	}
+	s := fmt.Sprintf("%s(%s)", keyword, strings.Join(vm.MatchingLabels, ", "))
+	switch vm.Card {
+	case CardManyToOne:
+		s += " group_left"
+	case CardOneToMany:
+		s += " group_right"
+	default:
+		return s
+	}
+	if len(vm.Include) > 0 {
+		s += fmt.Sprintf("(%s)", strings.Join(vm.Include, ", "))
+	}
+	return s
+}
+
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	if b.VectorMatching == nil {
+		return nil
+	}
+	if b.Args[0].Return() == TypeScalar || b.Args[1].Return() == TypeScalar {
+		return fmt.Errorf("parse: %s can not be used with a scalar in %s", b.VectorMatching, b)
+	}
+	if b.VectorMatching.On {
+		for _, include := range b.VectorMatching.Include {
+			for _, label := range b.VectorMatching.MatchingLabels {
+				if include == label {
+					return fmt.Errorf("parse: label %q must not occur in on and group_left or group_right at once", label)
+				}
+			}
+		}
+	}
 	return nil
 }
 
@@ -165,18 +165,80 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [matching] A}
+A -> C {"&&" [matching] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [matching] P}
+P -> M {( "+" | "-" ) [matching] M}
+M -> E {( "*" | "/" ) [matching] F}
+E -> F {( "**" ) [matching] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+matching -> ("on" | "ignoring") labels [("group_left" | "group_right") [labels]]
+labels -> "(" [name {"," name}] ")"
 */
 
+// binary parses the optional vector matching modifiers that follow the operator
+// and the right hand side of a binary operation.
+func (t *Tree) binary(operator item, lhs Node, rhs func() Node) Node {
+	vm := t.vectorMatching()
+	n := newBinary(operator, lhs, rhs())
+	n.VectorMatching = vm
+	return n
+}
+
+// vectorMatching parses the on/ignoring and group_left/group_right modifiers, if any.
+func (t *Tree) vectorMatching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	vm := &VectorMatching{
+		On:             token.val == "on",
+		MatchingLabels: t.labelList(token.val),
+	}
+
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return vm
+	}
+	t.next()
+	vm.Card = CardManyToOne
+	if token.val == "group_right" {
+		vm.Card = CardOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		vm.Include = t.labelList(token.val)
+	}
+	return vm
+}
+
+// labelList parses a parenthesized list of label names.
+func (t *Tree) labelList(context string) []string {
+	t.expect(itemLeftParen, context)
+	labels := []string{}
+	for {
+		token := t.next()
+		switch token.typ {
+		case itemRightParen:
+			return labels
+		case itemFunc:
+			labels = append(labels, token.val)
+		default:
+			t.unexpected(token, context)
+		}
+		switch token = t.next(); token.typ {
+		case itemComma:
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // expr:
 
 // O is A {"||" A} in the grammar.
@@ -185,7 +247,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +260,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +273,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +286,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +299,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,7 +312,7 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
@@ -329,6 +391,8 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemComma:
+			// the next argument follows
 		case itemRightParen:
 			return
 		}
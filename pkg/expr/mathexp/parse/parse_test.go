@@ -0,0 +1,82 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVectorMatching(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		expected *VectorMatching
+		str      string
+		err      string
+	}{
+		{
+			name:  "without modifiers",
+			input: "$A + $B",
+			str:   "$A + $B",
+		},
+		{
+			name:     "on",
+			input:    "$A + on(host, dc) $B",
+			expected: &VectorMatching{On: true, MatchingLabels: []string{"host", "dc"}},
+			str:      "$A + on(host, dc) $B",
+		},
+		{
+			name:     "ignoring with empty labels",
+			input:    "$A > ignoring() $B",
+			expected: &VectorMatching{MatchingLabels: []string{}},
+			str:      "$A > ignoring() $B",
+		},
+		{
+			name:     "group_left",
+			input:    "$A / ignoring(cpu) group_left $B",
+			expected: &VectorMatching{Card: CardManyToOne, MatchingLabels: []string{"cpu"}},
+			str:      "$A / ignoring(cpu) group_left $B",
+		},
+		{
+			name:     "group_right with included labels",
+			input:    "$A * on(host) group_right(dc, rack_id) $B",
+			expected: &VectorMatching{Card: CardOneToMany, On: true, MatchingLabels: []string{"host"}, Include: []string{"dc", "rack_id"}},
+			str:      "$A * on(host) group_right(dc, rack_id) $B",
+		},
+		{
+			name:  "group_left without on or ignoring",
+			input: "$A + group_left $B",
+			err:   "non existent function group_left",
+		},
+		{
+			name:  "unclosed labels",
+			input: "$A + on(host $B",
+			err:   "unexpected",
+		},
+		{
+			name:  "modifiers with a scalar",
+			input: "$A + on(host) 1",
+			err:   "can not be used with a scalar",
+		},
+		{
+			name:  "label both in on and group_left",
+			input: "$A + on(host) group_left(host) $B",
+			err:   `label "host" must not occur in on and group_left or group_right at once`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := Parse(tt.input)
+			if tt.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			node, ok := tree.Root.(*BinaryNode)
+			require.True(t, ok)
+			require.Equal(t, tt.expected, node.VectorMatching)
+			require.Equal(t, tt.str, node.String())
+		})
+	}
+}
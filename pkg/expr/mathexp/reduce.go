@@ -3,10 +3,89 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
+const (
+	// ReduceModeStrict is the reduction mode where null and NaN values make the result NaN.
+	ReduceModeStrict = "strict"
+	// ReduceModeDropNN is the reduction mode where non-number values are removed before the reduction.
+	ReduceModeDropNN = "dropNN"
+	// ReduceModeReplaceNN is the reduction mode where non-number values are replaced with a value before the reduction.
+	ReduceModeReplaceNN = "replaceNN"
+)
+
+// ReduceSettings is the model of how a reduction handles the non-number values of a series.
+type ReduceSettings struct {
+	Mode             string   `json:"mode"`
+	ReplaceWithValue *float64 `json:"replaceWithValue,omitempty"`
+}
+
+// Mapper returns the ReduceMapper of the mode of the settings. It returns nil for the strict mode.
+func (s ReduceSettings) Mapper() (ReduceMapper, error) {
+	switch s.Mode {
+	case "", ReduceModeStrict:
+		return nil, nil
+	case ReduceModeDropNN:
+		return DropNonNumber{}, nil
+	case ReduceModeReplaceNN:
+		if s.ReplaceWithValue == nil {
+			return nil, fmt.Errorf("reduce mode %s requires a value to replace non-number values with", s.Mode)
+		}
+		return ReplaceNonNumberWithValue{Value: *s.ReplaceWithValue}, nil
+	default:
+		return nil, fmt.Errorf("reduce mode %q not implemented", s.Mode)
+	}
+}
+
+// ReduceMapper maps the values of a series before it is reduced.
+type ReduceMapper interface {
+	MapInput(s Series) Series
+}
+
+// DropNonNumber is a ReduceMapper that removes the null, NaN and infinite values of a series.
+type DropNonNumber struct{}
+
+// MapInput returns a copy of the series without its non-number values.
+func (DropNonNumber) MapInput(s Series) Series {
+	newSeries := NewSeries(s.GetName(), s.GetLabels(), 0)
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if isNonNumber(f) {
+			continue
+		}
+		_ = newSeries.AppendPoint(i, t, f)
+	}
+	return newSeries
+}
+
+// ReplaceNonNumberWithValue is a ReduceMapper that replaces the null, NaN and infinite values of a series with Value.
+type ReplaceNonNumberWithValue struct {
+	Value float64
+}
+
+// MapInput returns a copy of the series with its non-number values replaced.
+func (r ReplaceNonNumberWithValue) MapInput(s Series) Series {
+	newSeries := NewSeries(s.GetName(), s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if isNonNumber(f) {
+			v := r.Value
+			f = &v
+		}
+		_ = newSeries.SetPoint(i, t, f)
+	}
+	return newSeries
+}
+
+func isNonNumber(f *float64) bool {
+	return f == nil || math.IsNaN(*f) || math.IsInf(*f, 0)
+}
+
 func Sum(fv *Float64Field) *float64 {
 	var sum float64
 	for i := 0; i < fv.Len(); i++ {
@@ -69,13 +148,135 @@ func Count(fv *Float64Field) *float64 {
 	return &f
 }
 
-// Reduce turns the Series into a Number based on the given reduction function
-func (s Series) Reduce(refID, rFunc string) (Number, error) {
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+func Last(fv *Float64Field) *float64 {
+	values := validValues(fv)
+	if len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	return &values[len(values)-1]
+}
+
+func First(fv *Float64Field) *float64 {
+	values := validValues(fv)
+	if len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	return &values[0]
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *Float64Field) *float64 {
+	values := validValues(fv)
+	if len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	f := values[len(values)-1] - values[0]
+	return &f
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(fv, 50)
+}
+
+// Percentile returns the p-th percentile of the values, interpolating linearly between the closest ranks.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	values := validValues(fv)
+	if len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+	return &f
+}
+
+// Stddev returns the population standard deviation of the values.
+func Stddev(fv *Float64Field) *float64 {
+	values := validValues(fv)
+	if len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	f := math.Sqrt(variance / float64(len(values)))
+	return &f
+}
+
+// validValues returns the values of the field, or nil if any of them is null or NaN.
+func validValues(fv *Float64Field) []float64 {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil
+		}
+		values = append(values, *v)
+	}
+	return values
+}
+
+// ParsePercentileReducer returns the percentile of a reducer of the form percentile(N).
+// The boolean is false if the reducer is not a percentile reducer.
+func ParsePercentileReducer(rFunc string) (float64, bool, error) {
+	if !strings.HasPrefix(rFunc, "percentile(") || !strings.HasSuffix(rFunc, ")") {
+		return 0, false, nil
+	}
+	raw := strings.TrimSuffix(strings.TrimPrefix(rFunc, "percentile("), ")")
+	p, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || p < 0 || p > 100 {
+		return 0, true, fmt.Errorf("invalid percentile %q, it must be a number between 0 and 100", raw)
+	}
+	return p, true, nil
+}
+
+// ValidReducer returns an error if the reduction function is not implemented.
+func ValidReducer(rFunc string) error {
+	switch rFunc {
+	case "sum", "mean", "min", "max", "count", "count_non_null", "last", "first", "median", "stddev", "diff":
+		return nil
+	}
+	if _, ok, err := ParsePercentileReducer(rFunc); ok {
+		return err
+	}
+	return fmt.Errorf("reduction %v not implemented", rFunc)
+}
+
+// Reduce turns the Series into a Number based on the given reduction function.
+// If mapper is not nil, the values of the series are mapped before the reduction.
+func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
 	}
 	number := NewNumber(refID, l)
+	if mapper != nil {
+		s = mapper.MapInput(s)
+	}
 	var f *float64
 	fVec := s.Frame.Fields[seriesTypeValIdx]
 	floatField := Float64Field(*fVec)
@@ -90,8 +291,27 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 		f = Max(&floatField)
 	case "count":
 		f = Count(&floatField)
+	case "count_non_null":
+		f = CountNonNull(&floatField)
+	case "last":
+		f = Last(&floatField)
+	case "first":
+		f = First(&floatField)
+	case "median":
+		f = Median(&floatField)
+	case "stddev":
+		f = Stddev(&floatField)
+	case "diff":
+		f = Diff(&floatField)
 	default:
-		return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		p, ok, err := ParsePercentileReducer(rFunc)
+		if err != nil {
+			return number, err
+		}
+		if !ok {
+			return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		}
+		f = Percentile(&floatField, p)
 	}
 	number.SetValue(f)
 
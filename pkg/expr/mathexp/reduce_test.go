@@ -30,10 +30,25 @@ var seriesEmpty = Vars{
 	},
 }
 
+var seriesToPercentile = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("temp", nil, tp{
+				time.Unix(5, 0), float64Pointer(50),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(10),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(30),
+			}),
+		},
+	},
+}
+
 func TestSeriesReduce(t *testing.T) {
 	var tests = []struct {
 		name        string
 		red         string
+		mapper      ReduceMapper
 		vars        Vars
 		varToReduce string
 		errIs       require.ErrorAssertionFunc
@@ -214,6 +229,146 @@ func TestSeriesReduce(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "last series",
+			red:         "last",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name:        "first series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(2)),
+				},
+			},
+		},
+		{
+			name:        "last series with a nil value",
+			red:         "last",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, NaN),
+				},
+			},
+		},
+		{
+			name:        "diff series",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(-1)),
+				},
+			},
+		},
+		{
+			name:        "median series",
+			red:         "median",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(1.5)),
+				},
+			},
+		},
+		{
+			name:        "percentile series",
+			red:         "percentile(90)",
+			varToReduce: "A",
+			vars:        seriesToPercentile,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(46)),
+				},
+			},
+		},
+		{
+			name:        "percentile out of range will error",
+			red:         "percentile(101)",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
+		{
+			name:        "stddev series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(0.5)),
+				},
+			},
+		},
+		{
+			name:        "count_non_null series with a nil value",
+			red:         "count_non_null",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name:        "sum series with a nil value when dropping non-numbers",
+			red:         "sum",
+			mapper:      DropNonNumber{},
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(2)),
+				},
+			},
+		},
+		{
+			name:        "mean series with a nil value when replacing non-numbers",
+			red:         "mean",
+			mapper:      ReplaceNonNumberWithValue{Value: 4},
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(3)),
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -221,7 +376,7 @@ func TestSeriesReduce(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red)
+				ns, err := series.Value().(*Series).Reduce("", tt.red, tt.mapper)
 				tt.errIs(t, err)
 				if err != nil {
 					return
@@ -238,3 +393,55 @@ func TestSeriesReduce(t *testing.T) {
 		})
 	}
 }
+
+func TestReduceSettingsMapper(t *testing.T) {
+	replaceWith := 5.0
+	testCases := []struct {
+		name     string
+		settings ReduceSettings
+		expected ReduceMapper
+		err      string
+	}{
+		{name: "default mode is strict", settings: ReduceSettings{}},
+		{name: "strict", settings: ReduceSettings{Mode: ReduceModeStrict}},
+		{name: "dropNN", settings: ReduceSettings{Mode: ReduceModeDropNN}, expected: DropNonNumber{}},
+		{name: "replaceNN", settings: ReduceSettings{Mode: ReduceModeReplaceNN, ReplaceWithValue: &replaceWith}, expected: ReplaceNonNumberWithValue{Value: 5}},
+		{name: "replaceNN without value", settings: ReduceSettings{Mode: ReduceModeReplaceNN}, err: "reduce mode replaceNN requires a value to replace non-number values with"},
+		{name: "unknown mode", settings: ReduceSettings{Mode: "foo"}, err: `reduce mode "foo" not implemented`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mapper, err := tc.settings.Mapper()
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, mapper)
+		})
+	}
+}
+
+func TestReduceMappers(t *testing.T) {
+	s := makeSeries("temp", data.Labels{"host": "a"}, tp{
+		time.Unix(5, 0), float64Pointer(2),
+	}, tp{
+		time.Unix(10, 0), nil,
+	}, tp{
+		time.Unix(15, 0), float64Pointer(math.Inf(1)),
+	}, tp{
+		time.Unix(20, 0), NaN,
+	})
+
+	dropped := DropNonNumber{}.MapInput(s)
+	require.Equal(t, 1, dropped.Len())
+	require.Equal(t, data.Labels{"host": "a"}, dropped.GetLabels())
+	require.Equal(t, float64Pointer(2), dropped.GetValue(0))
+
+	replaced := ReplaceNonNumberWithValue{Value: -1}.MapInput(s)
+	require.Equal(t, 4, replaced.Len())
+	for i, expected := range []float64{2, -1, -1, -1} {
+		require.Equal(t, expected, *replaced.GetValue(i))
+	}
+	require.Nil(t, s.GetValue(1), "the input series should not change")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_union(t *testing.T) {
@@ -242,3 +243,111 @@ func Test_union(t *testing.T) {
 		})
 	}
 }
+
+func TestVectorMatching(t *testing.T) {
+	cpu := Results{
+		Values: Values{
+			makeNumber("", data.Labels{"host": "a", "cpu": "0"}, float64Pointer(10)),
+			makeNumber("", data.Labels{"host": "a", "cpu": "1"}, float64Pointer(20)),
+			makeNumber("", data.Labels{"host": "b", "cpu": "0"}, float64Pointer(30)),
+		},
+	}
+	cores := Results{
+		Values: Values{
+			makeNumber("", data.Labels{"host": "a", "dc": "x"}, float64Pointer(2)),
+			makeNumber("", data.Labels{"host": "b", "dc": "y"}, float64Pointer(3)),
+		},
+	}
+	up := Results{
+		Values: Values{
+			makeNumber("", data.Labels{"host": "a", "job": "node"}, float64Pointer(1)),
+			makeNumber("", data.Labels{"host": "c", "job": "node"}, float64Pointer(1)),
+		},
+	}
+
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		err      string
+		results  Results
+		warnings []string
+	}{
+		{
+			name: "one-to-one matching on a label",
+			expr: "$A + on(host) $B",
+			vars: Vars{"A": cores, "B": up},
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(3)),
+			}},
+			warnings: []string{"2 series without a match with on(host) were dropped: left side {dc=y, host=b}, right side {host=c, job=node}"},
+		},
+		{
+			name: "one-to-one matching ignoring a label",
+			expr: "$A * ignoring(dc, job) $B",
+			vars: Vars{"A": cores, "B": up},
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(2)),
+			}},
+			warnings: []string{"2 series without a match with ignoring(dc, job) were dropped: left side {dc=y, host=b}, right side {host=c, job=node}"},
+		},
+		{
+			name: "many-to-one matching with group_left",
+			expr: "$A / on(host) group_left(dc) $B",
+			vars: Vars{"A": cpu, "B": cores},
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a", "cpu": "0", "dc": "x"}, float64Pointer(5)),
+				makeNumber("", data.Labels{"host": "a", "cpu": "1", "dc": "x"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"host": "b", "cpu": "0", "dc": "y"}, float64Pointer(10)),
+			}},
+		},
+		{
+			name: "one-to-many matching with group_right",
+			expr: "$B - on(host) group_right $A",
+			vars: Vars{"A": cpu, "B": cores},
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a", "cpu": "0"}, float64Pointer(-8)),
+				makeNumber("", data.Labels{"host": "a", "cpu": "1"}, float64Pointer(-18)),
+				makeNumber("", data.Labels{"host": "b", "cpu": "0"}, float64Pointer(-27)),
+			}},
+		},
+		{
+			name: "many-to-many matching is an error",
+			expr: "$A / on(host) $B",
+			vars: Vars{"A": cpu, "B": cores},
+			err:  "found duplicate series for the match group {host=a} on the left side of on(host): {cpu=0, host=a} and {cpu=1, host=a}, use group_left or group_right for many-to-one matching",
+		},
+		{
+			name: "no match is an error",
+			expr: "$A + on(host) $B",
+			vars: Vars{"A": cores, "B": Results{Values: Values{up.Values[1]}}},
+			err:  "no series matched with on(host): left side {dc=x, host=a}, left side {dc=y, host=b}, right side {host=c, job=node}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, res.Values, len(tt.results.Values))
+			for i, expected := range tt.results.Values {
+				actual := res.Values[i].(Number)
+				require.Equal(t, expected.GetLabels(), actual.GetLabels())
+				require.Equal(t, expected.(Number).GetFloat64Value(), actual.GetFloat64Value())
+
+				var warnings []string
+				if actual.Frame.Meta != nil {
+					for _, n := range actual.Frame.Meta.Notices {
+						warnings = append(warnings, n.Text)
+					}
+				}
+				require.Equal(t, tt.warnings, warnings)
+			}
+		})
+	}
+}
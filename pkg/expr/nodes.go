@@ -31,6 +31,7 @@ type rawNode struct {
 	QueryType     string
 	TimeRange     TimeRange
 	DatasourceUID string
+	Results       *mathexp.Results
 }
 
 func (rn *rawNode) GetDatasourceUID() (string, error) {
@@ -158,6 +159,8 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	case TypeThreshold:
+		node.Command, err = UnmarshalThresholdCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in '%v' not implemented", commandType, rn.RefID)
 	}
@@ -186,6 +189,8 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+	// results are the results of the query provided by the caller, if any.
+	results *mathexp.Results
 }
 
 // NodeType returns the data pipeline node type.
@@ -211,6 +216,7 @@ func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, req *Reques
 		maxDP:      defaultMaxDP,
 		timeRange:  rn.TimeRange,
 		request:    *req,
+		results:    rn.Results,
 	}
 
 	rawDsID, ok := rn.Query["datasourceId"]
@@ -251,6 +257,10 @@ func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, req *Reques
 // other nodes they must have already been executed and their results must
 // already by in vars.
 func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (mathexp.Results, error) {
+	if dn.results != nil {
+		return *dn.results, nil
+	}
+
 	pc := backend.PluginContext{
 		OrgID: dn.orgID,
 		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/stretchr/testify/require"
@@ -80,6 +81,37 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestServiceProvidedResults(t *testing.T) {
+	s := Service{}
+
+	a := mathexp.NewNumber("", data.Labels{"instance": "a"})
+	a.SetValue(fp(2))
+
+	queries := []Query{
+		{
+			RefID:         "A",
+			DatasourceUID: "provided",
+			JSON:          json.RawMessage(`{ "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			Results:       &mathexp.Results{Values: mathexp.Values{a}},
+		},
+		{
+			RefID: "B",
+			JSON:  json.RawMessage(`{ "datasource": "__expr__", "datasourceId": -100, "type": "math", "expression": "$A * 2" }`),
+		},
+	}
+
+	pl, err := s.BuildPipeline(&Request{Queries: queries})
+	require.NoError(t, err)
+
+	res, err := s.ExecutePipeline(context.Background(), pl)
+	require.NoError(t, err)
+
+	require.Len(t, res.Responses["B"].Frames, 1)
+	field := res.Responses["B"].Frames[0].Fields[0]
+	require.Equal(t, data.Labels{"instance": "a"}, field.Labels)
+	require.Equal(t, fp(4), field.At(0))
+}
+
 func fp(f float64) *float64 {
 	return &f
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/prometheus/client_golang/prometheus"
@@ -77,6 +78,9 @@ type Query struct {
 	Interval      time.Duration
 	QueryType     string
 	MaxDataPoints int64
+	// Results are the results of the query when they are provided by the caller.
+	// If set, the query is not sent to its data source.
+	Results *mathexp.Results
 }
 
 // TimeRange is a time.Time based TimeRange.
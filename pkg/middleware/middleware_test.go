@@ -161,6 +161,50 @@ func TestMiddlewareContext(t *testing.T) {
 		assert.Equal(t, models.ROLE_EDITOR, sc.context.OrgRole)
 	})
 
+	middlewareScenario(t, "Valid API key of a service account", func(t *testing.T, sc *scenarioContext) {
+		const orgID int64 = 12
+		const serviceAccountID int64 = 42
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			query.Result = &models.ApiKey{Id: 3, OrgId: orgID, Role: models.ROLE_EDITOR, Key: keyhash, ServiceAccountId: serviceAccountID}
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{UserId: serviceAccountID, OrgId: orgID, OrgRole: models.ROLE_VIEWER, Login: "sa-12-agent", IsServiceAccount: true}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		require.Equal(t, 200, sc.resp.Code)
+
+		assert.True(t, sc.context.IsSignedIn)
+		assert.Equal(t, serviceAccountID, sc.context.UserId)
+		assert.Equal(t, int64(3), sc.context.ApiKeyId)
+		assert.Equal(t, models.ROLE_VIEWER, sc.context.OrgRole)
+	})
+
+	middlewareScenario(t, "Valid API key of a disabled service account", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			query.Result = &models.ApiKey{Id: 3, OrgId: 12, Role: models.ROLE_EDITOR, Key: keyhash, ServiceAccountId: 42}
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{UserId: 42, OrgId: 12, OrgRole: models.ROLE_VIEWER, IsServiceAccount: true, IsDisabled: true}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, "Service account disabled", sc.respJson["message"])
+	})
+
 	middlewareScenario(t, "Valid API key, but does not match DB hash", func(t *testing.T, sc *scenarioContext) {
 		const keyhash = "Something_not_matching"
 
@@ -1,6 +1,9 @@
 package models
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 var ErrInvalidEmailCode = errors.New("invalid or expired email code")
 var ErrSmtpNotEnabled = errors.New("SMTP not configured, check your grafana.ini config file's [smtp] section")
@@ -22,6 +25,19 @@ type SendEmailCommand struct {
 	ReplyTo       []string
 	EmbeddedFiles []string
 	AttachedFiles []*SendEmailAttachFile
+	// Relay, when set, sends the email through this SMTP server instead of the one of the [smtp] settings.
+	Relay *SmtpRelay
+}
+
+// SmtpRelay is an SMTP server, and the sender, used to send an email instead of the [smtp] settings
+type SmtpRelay struct {
+	Host           string
+	User           string
+	Password       string
+	FromAddress    string
+	FromName       string
+	StartTLSPolicy string
+	SkipVerify     bool
 }
 
 // SendEmailCommandSync is the command for sending emails synchronously
@@ -39,6 +55,26 @@ type SendWebhookSync struct {
 	ContentType string
 }
 
+// WebhookResponse is the response of the webhooks sent with a context returned by WithWebhookResponse.
+type WebhookResponse struct {
+	StatusCode int
+}
+
+type webhookResponseKey struct{}
+
+// WithWebhookResponse returns a context in which the response of the webhooks sent with it is recorded in resp.
+func WithWebhookResponse(ctx context.Context, resp *WebhookResponse) context.Context {
+	return context.WithValue(ctx, webhookResponseKey{}, resp)
+}
+
+// SetWebhookResponseStatusCode records the status code of the response of a webhook sent with the context,
+// if the context records it.
+func SetWebhookResponseStatusCode(ctx context.Context, statusCode int) {
+	if resp, ok := ctx.Value(webhookResponseKey{}).(*WebhookResponse); ok {
+		resp.StatusCode = statusCode
+	}
+}
+
 type SendResetPasswordEmailCommand struct {
 	User *User
 }
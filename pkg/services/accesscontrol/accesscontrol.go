@@ -31,6 +31,36 @@ type AccessControl interface {
 	DeclareFixedRoles(...RoleRegistration) error
 }
 
+// Store is the storage of custom roles and of their assignments to built-in roles, users and teams.
+// Global roles and assignments belong to the organization GlobalOrgID and apply to every organization.
+type Store interface {
+	// GetRoles returns the global roles and the roles of the organization, without their permissions.
+	GetRoles(ctx context.Context, orgID int64) ([]*RoleDTO, error)
+	// GetRole returns a global role or a role of the organization, with its permissions.
+	GetRole(ctx context.Context, orgID int64, uid string) (*RoleDTO, error)
+	CreateRole(ctx context.Context, cmd CreateRoleCommand) (*RoleDTO, error)
+	UpdateRole(ctx context.Context, cmd UpdateRoleCommand) (*RoleDTO, error)
+	DeleteRole(ctx context.Context, cmd DeleteRoleCommand) error
+
+	// GetBuiltInRoleAssignments returns the roles assigned to built-in roles in the organization, and globally.
+	GetBuiltInRoleAssignments(ctx context.Context, orgID int64) (map[string][]*RoleDTO, error)
+	AddBuiltInRoleAssignment(ctx context.Context, orgID int64, builtInRole, roleUID string) error
+	RemoveBuiltInRoleAssignment(ctx context.Context, orgID int64, builtInRole, roleUID string) error
+
+	// GetUserAssignedRoles returns the roles assigned to the user in the organization.
+	GetUserAssignedRoles(ctx context.Context, orgID, userID int64) ([]*RoleDTO, error)
+	AddUserRole(ctx context.Context, orgID, userID int64, roleUID string) error
+	RemoveUserRole(ctx context.Context, orgID, userID int64, roleUID string) error
+
+	// GetTeamAssignedRoles returns the roles assigned to the team.
+	GetTeamAssignedRoles(ctx context.Context, orgID, teamID int64) ([]*RoleDTO, error)
+	AddTeamRole(ctx context.Context, orgID, teamID int64, roleUID string) error
+	RemoveTeamRole(ctx context.Context, orgID, teamID int64, roleUID string) error
+
+	// GetUserRoles returns the custom roles of a user, with their permissions.
+	GetUserRoles(ctx context.Context, query GetUserRolesQuery) ([]*RoleDTO, error)
+}
+
 func HasAccess(ac AccessControl, c *models.ReqContext) func(fallback func(*models.ReqContext) bool, evaluator Evaluator) bool {
 	return func(fallback func(*models.ReqContext) bool, evaluator Evaluator) bool {
 		if ac.IsDisabled() {
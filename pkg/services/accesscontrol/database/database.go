@@ -0,0 +1,474 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	roleTable        = "role"
+	permissionTable  = "permission"
+	builtinRoleTable = "builtin_role"
+	userRoleTable    = "user_role"
+	teamRoleTable    = "team_role"
+)
+
+// AccessControlStore is the SQL storage of custom roles and of their assignments.
+type AccessControlStore struct {
+	sql *sqlstore.SQLStore
+}
+
+func ProvideService(sqlStore *sqlstore.SQLStore) *AccessControlStore {
+	return &AccessControlStore{
+		sql: sqlStore,
+	}
+}
+
+func (s *AccessControlStore) GetRoles(ctx context.Context, orgID int64) ([]*accesscontrol.RoleDTO, error) {
+	result := make([]*accesscontrol.RoleDTO, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		roles := make([]accesscontrol.Role, 0)
+		err := sess.Table(roleTable).
+			Where("org_id = ? OR org_id = ?", orgID, accesscontrol.GlobalOrgID).
+			Asc("name").
+			Find(&roles)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			result = append(result, roleDTO(role, nil))
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *AccessControlStore) GetRole(ctx context.Context, orgID int64, uid string) (*accesscontrol.RoleDTO, error) {
+	var result *accesscontrol.RoleDTO
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getRoleByUID(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+		permissions, err := getPermissions(sess, role.ID)
+		if err != nil {
+			return err
+		}
+		result = roleDTO(*role, permissions)
+		return nil
+	})
+	return result, err
+}
+
+func (s *AccessControlStore) CreateRole(ctx context.Context, cmd accesscontrol.CreateRoleCommand) (*accesscontrol.RoleDTO, error) {
+	if err := accesscontrol.ValidateCustomRole(cmd.UID, cmd.Name, cmd.Permissions); err != nil {
+		return nil, err
+	}
+
+	orgID := cmd.OrgID
+	if cmd.Global {
+		orgID = accesscontrol.GlobalOrgID
+	}
+	uid := cmd.UID
+	if uid == "" {
+		uid = util.GenerateShortUID()
+	}
+
+	var result *accesscontrol.RoleDTO
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Table(roleTable).
+			Where("uid = ? OR (org_id = ? AND name = ?)", uid, orgID, cmd.Name).
+			Exist()
+		if err != nil {
+			return err
+		}
+		if exists {
+			return accesscontrol.ErrRoleAlreadyExists
+		}
+
+		now := time.Now()
+		role := accesscontrol.Role{
+			OrgID:       orgID,
+			Version:     cmd.Version,
+			UID:         uid,
+			Name:        cmd.Name,
+			DisplayName: cmd.DisplayName,
+			Description: cmd.Description,
+			Created:     now,
+			Updated:     now,
+		}
+		if _, err := sess.Table(roleTable).Insert(&role); err != nil {
+			return err
+		}
+
+		permissions, err := insertPermissions(sess, role.ID, cmd.Permissions, now)
+		if err != nil {
+			return err
+		}
+		result = roleDTO(role, permissions)
+		return nil
+	})
+	return result, err
+}
+
+func (s *AccessControlStore) UpdateRole(ctx context.Context, cmd accesscontrol.UpdateRoleCommand) (*accesscontrol.RoleDTO, error) {
+	if err := accesscontrol.ValidateCustomRole(cmd.UID, cmd.Name, cmd.Permissions); err != nil {
+		return nil, err
+	}
+
+	var result *accesscontrol.RoleDTO
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getRoleByUID(sess, cmd.OrgID, cmd.UID)
+		if err != nil {
+			return err
+		}
+		if cmd.Version <= role.Version {
+			return accesscontrol.ErrRoleVersionTooLow
+		}
+
+		exists, err := sess.Table(roleTable).
+			Where("org_id = ? AND name = ? AND id <> ?", role.OrgID, cmd.Name, role.ID).
+			Exist()
+		if err != nil {
+			return err
+		}
+		if exists {
+			return accesscontrol.ErrRoleAlreadyExists
+		}
+
+		now := time.Now()
+		role.Version = cmd.Version
+		role.Name = cmd.Name
+		role.DisplayName = cmd.DisplayName
+		role.Description = cmd.Description
+		role.Updated = now
+		_, err = sess.Table(roleTable).
+			ID(role.ID).
+			Cols("version", "name", "display_name", "description", "updated").
+			Update(role)
+		if err != nil {
+			return err
+		}
+
+		// the permissions of the role are replaced
+		if _, err := sess.Exec("DELETE FROM permission WHERE role_id = ?", role.ID); err != nil {
+			return err
+		}
+		permissions, err := insertPermissions(sess, role.ID, cmd.Permissions, now)
+		if err != nil {
+			return err
+		}
+		result = roleDTO(*role, permissions)
+		return nil
+	})
+	return result, err
+}
+
+func (s *AccessControlStore) DeleteRole(ctx context.Context, cmd accesscontrol.DeleteRoleCommand) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getRoleByUID(sess, cmd.OrgID, cmd.UID)
+		if err != nil {
+			return err
+		}
+
+		if !cmd.Force {
+			for _, table := range []string{builtinRoleTable, userRoleTable, teamRoleTable} {
+				assigned, err := sess.Table(table).Where("role_id = ?", role.ID).Exist()
+				if err != nil {
+					return err
+				}
+				if assigned {
+					return accesscontrol.ErrRoleAssigned
+				}
+			}
+		}
+
+		deletes := []string{
+			"DELETE FROM builtin_role WHERE role_id = ?",
+			"DELETE FROM user_role WHERE role_id = ?",
+			"DELETE FROM team_role WHERE role_id = ?",
+			"DELETE FROM permission WHERE role_id = ?",
+			"DELETE FROM role WHERE id = ?",
+		}
+		for _, sql := range deletes {
+			if _, err := sess.Exec(sql, role.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *AccessControlStore) GetBuiltInRoleAssignments(ctx context.Context, orgID int64) (map[string][]*accesscontrol.RoleDTO, error) {
+	result := make(map[string][]*accesscontrol.RoleDTO)
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		type assignment struct {
+			accesscontrol.Role `xorm:"extends"`
+			BuiltinRole        string `xorm:"builtin_role"`
+		}
+
+		assignments := make([]assignment, 0)
+		err := sess.SQL(`SELECT role.*, builtin_role.role AS builtin_role FROM builtin_role
+			INNER JOIN role ON role.id = builtin_role.role_id
+			WHERE builtin_role.org_id = ? OR builtin_role.org_id = ?
+			ORDER BY role.name`, orgID, accesscontrol.GlobalOrgID).
+			Find(&assignments)
+		if err != nil {
+			return err
+		}
+		for _, a := range assignments {
+			result[a.BuiltinRole] = append(result[a.BuiltinRole], roleDTO(a.Role, nil))
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *AccessControlStore) AddBuiltInRoleAssignment(ctx context.Context, orgID int64, builtInRole, roleUID string) error {
+	if err := accesscontrol.ValidateBuiltInRoles([]string{builtInRole}); err != nil {
+		return err
+	}
+
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getRoleByUID(sess, orgID, roleUID)
+		if err != nil {
+			return err
+		}
+		if orgID == accesscontrol.GlobalOrgID && !role.Global() {
+			return accesscontrol.ErrRoleNotGlobal
+		}
+
+		exists, err := sess.Table(builtinRoleTable).
+			Where("org_id = ? AND role = ? AND role_id = ?", orgID, builtInRole, role.ID).
+			Exist()
+		if err != nil || exists {
+			return err
+		}
+
+		now := time.Now()
+		_, err = sess.Table(builtinRoleTable).Insert(&accesscontrol.BuiltinRole{
+			RoleID:  role.ID,
+			OrgID:   orgID,
+			Role:    builtInRole,
+			Created: now,
+			Updated: now,
+		})
+		return err
+	})
+}
+
+func (s *AccessControlStore) RemoveBuiltInRoleAssignment(ctx context.Context, orgID int64, builtInRole, roleUID string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getRoleByUID(sess, orgID, roleUID)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM builtin_role WHERE org_id = ? AND role = ? AND role_id = ?", orgID, builtInRole, role.ID)
+		return err
+	})
+}
+
+func (s *AccessControlStore) GetUserAssignedRoles(ctx context.Context, orgID, userID int64) ([]*accesscontrol.RoleDTO, error) {
+	return s.getAssignedRoles(ctx, `SELECT role.* FROM user_role
+		INNER JOIN role ON role.id = user_role.role_id
+		WHERE user_role.org_id = ? AND user_role.user_id = ?
+		ORDER BY role.name`, orgID, userID)
+}
+
+func (s *AccessControlStore) AddUserRole(ctx context.Context, orgID, userID int64, roleUID string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getRoleByUID(sess, orgID, roleUID)
+		if err != nil {
+			return err
+		}
+
+		exists, err := sess.Table(userRoleTable).
+			Where("org_id = ? AND user_id = ? AND role_id = ?", orgID, userID, role.ID).
+			Exist()
+		if err != nil || exists {
+			return err
+		}
+
+		_, err = sess.Table(userRoleTable).Insert(&accesscontrol.UserRole{
+			OrgID:   orgID,
+			RoleID:  role.ID,
+			UserID:  userID,
+			Created: time.Now(),
+		})
+		return err
+	})
+}
+
+func (s *AccessControlStore) RemoveUserRole(ctx context.Context, orgID, userID int64, roleUID string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getRoleByUID(sess, orgID, roleUID)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM user_role WHERE org_id = ? AND user_id = ? AND role_id = ?", orgID, userID, role.ID)
+		return err
+	})
+}
+
+func (s *AccessControlStore) GetTeamAssignedRoles(ctx context.Context, orgID, teamID int64) ([]*accesscontrol.RoleDTO, error) {
+	return s.getAssignedRoles(ctx, `SELECT role.* FROM team_role
+		INNER JOIN role ON role.id = team_role.role_id
+		WHERE team_role.org_id = ? AND team_role.team_id = ?
+		ORDER BY role.name`, orgID, teamID)
+}
+
+func (s *AccessControlStore) AddTeamRole(ctx context.Context, orgID, teamID int64, roleUID string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getRoleByUID(sess, orgID, roleUID)
+		if err != nil {
+			return err
+		}
+
+		exists, err := sess.Table(teamRoleTable).
+			Where("org_id = ? AND team_id = ? AND role_id = ?", orgID, teamID, role.ID).
+			Exist()
+		if err != nil || exists {
+			return err
+		}
+
+		_, err = sess.Table(teamRoleTable).Insert(&accesscontrol.TeamRole{
+			OrgID:   orgID,
+			RoleID:  role.ID,
+			TeamID:  teamID,
+			Created: time.Now(),
+		})
+		return err
+	})
+}
+
+func (s *AccessControlStore) RemoveTeamRole(ctx context.Context, orgID, teamID int64, roleUID string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getRoleByUID(sess, orgID, roleUID)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM team_role WHERE org_id = ? AND team_id = ? AND role_id = ?", orgID, teamID, role.ID)
+		return err
+	})
+}
+
+func (s *AccessControlStore) GetUserRoles(ctx context.Context, query accesscontrol.GetUserRolesQuery) ([]*accesscontrol.RoleDTO, error) {
+	result := make([]*accesscontrol.RoleDTO, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		assignments := []string{
+			"SELECT role_id FROM user_role WHERE org_id = ? AND user_id = ?",
+			`SELECT team_role.role_id FROM team_role
+				INNER JOIN team_member ON team_member.team_id = team_role.team_id
+				WHERE team_role.org_id = ? AND team_member.user_id = ?`,
+		}
+		params := []interface{}{query.OrgID, query.UserID, query.OrgID, query.UserID}
+		if len(query.BuiltInRoles) > 0 {
+			assignments = append(assignments, "SELECT role_id FROM builtin_role WHERE (org_id = ? OR org_id = ?) AND role IN (?"+
+				strings.Repeat(", ?", len(query.BuiltInRoles)-1)+")")
+			params = append(params, query.OrgID, accesscontrol.GlobalOrgID)
+			for _, builtInRole := range query.BuiltInRoles {
+				params = append(params, builtInRole)
+			}
+		}
+
+		roles := make([]accesscontrol.Role, 0)
+		err := sess.SQL("SELECT role.* FROM role WHERE role.id IN ("+strings.Join(assignments, " UNION ")+") ORDER BY role.name", params...).
+			Find(&roles)
+		if err != nil || len(roles) == 0 {
+			return err
+		}
+
+		roleIDs := make([]int64, 0, len(roles))
+		for _, role := range roles {
+			roleIDs = append(roleIDs, role.ID)
+		}
+		permissions, err := getPermissions(sess, roleIDs...)
+		if err != nil {
+			return err
+		}
+
+		permissionsByRole := make(map[int64][]accesscontrol.Permission)
+		for _, p := range permissions {
+			permissionsByRole[p.RoleID] = append(permissionsByRole[p.RoleID], p)
+		}
+		for _, role := range roles {
+			result = append(result, roleDTO(role, permissionsByRole[role.ID]))
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *AccessControlStore) getAssignedRoles(ctx context.Context, sql string, params ...interface{}) ([]*accesscontrol.RoleDTO, error) {
+	result := make([]*accesscontrol.RoleDTO, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		roles := make([]accesscontrol.Role, 0)
+		if err := sess.SQL(sql, params...).Find(&roles); err != nil {
+			return err
+		}
+		for _, role := range roles {
+			result = append(result, roleDTO(role, nil))
+		}
+		return nil
+	})
+	return result, err
+}
+
+// getRoleByUID returns the role with the UID, if it is global or belongs to the organization.
+func getRoleByUID(sess *sqlstore.DBSession, orgID int64, uid string) (*accesscontrol.Role, error) {
+	var role accesscontrol.Role
+	exists, err := sess.Table(roleTable).
+		Where("uid = ? AND (org_id = ? OR org_id = ?)", uid, orgID, accesscontrol.GlobalOrgID).
+		Get(&role)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, accesscontrol.ErrRoleNotFound
+	}
+	return &role, nil
+}
+
+func getPermissions(sess *sqlstore.DBSession, roleIDs ...int64) ([]accesscontrol.Permission, error) {
+	permissions := make([]accesscontrol.Permission, 0)
+	err := sess.Table(permissionTable).In("role_id", roleIDs).Asc("id").Find(&permissions)
+	return permissions, err
+}
+
+func insertPermissions(sess *sqlstore.DBSession, roleID int64, permissions []accesscontrol.Permission, now time.Time) ([]accesscontrol.Permission, error) {
+	inserted := make([]accesscontrol.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		permission := accesscontrol.Permission{
+			RoleID:  roleID,
+			Action:  p.Action,
+			Scope:   p.Scope,
+			Created: now,
+			Updated: now,
+		}
+		if _, err := sess.Table(permissionTable).Insert(&permission); err != nil {
+			return nil, err
+		}
+		inserted = append(inserted, permission)
+	}
+	return inserted, nil
+}
+
+func roleDTO(role accesscontrol.Role, permissions []accesscontrol.Permission) *accesscontrol.RoleDTO {
+	return &accesscontrol.RoleDTO{
+		ID:          role.ID,
+		OrgID:       role.OrgID,
+		Version:     role.Version,
+		UID:         role.UID,
+		Name:        role.Name,
+		DisplayName: role.DisplayName,
+		Description: role.Description,
+		Permissions: permissions,
+		Updated:     role.Updated,
+		Created:     role.Created,
+	}
+}
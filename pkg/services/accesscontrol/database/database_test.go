@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestEnv(t testing.TB) (*AccessControlStore, *sqlstore.SQLStore) {
+	t.Helper()
+
+	sqlStore := sqlstore.InitTestDB(t)
+	return ProvideService(sqlStore), sqlStore
+}
+
+func createRole(t *testing.T, store *AccessControlStore, orgID int64, name string, permissions ...accesscontrol.Permission) *accesscontrol.RoleDTO {
+	t.Helper()
+
+	role, err := store.CreateRole(context.Background(), accesscontrol.CreateRoleCommand{
+		OrgID:       orgID,
+		Global:      orgID == accesscontrol.GlobalOrgID,
+		Name:        name,
+		Permissions: permissions,
+	})
+	require.NoError(t, err)
+	return role
+}
+
+func TestAccessControlStore_Roles(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create, update and delete a role", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+
+		created, err := store.CreateRole(ctx, accesscontrol.CreateRoleCommand{
+			OrgID:       1,
+			UID:         "dashboards-editor",
+			Version:     1,
+			Name:        "custom:dashboards:editor",
+			Description: "Edit the dashboards of the Production folder",
+			Permissions: []accesscontrol.Permission{
+				{Action: "dashboards:write", Scope: "folders:uid:production"},
+				{Action: "dashboards:read", Scope: "folders:uid:production"},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "dashboards-editor", created.UID)
+		assert.Equal(t, int64(1), created.OrgID)
+		assert.Len(t, created.Permissions, 2)
+
+		role, err := store.GetRole(ctx, 1, "dashboards-editor")
+		require.NoError(t, err)
+		assert.Equal(t, "custom:dashboards:editor", role.Name)
+		assert.Equal(t, "Edit the dashboards of the Production folder", role.Description)
+		require.Len(t, role.Permissions, 2)
+		assert.Equal(t, "dashboards:write", role.Permissions[0].Action)
+		assert.Equal(t, "folders:uid:production", role.Permissions[0].Scope)
+
+		_, err = store.GetRole(ctx, 2, "dashboards-editor")
+		require.ErrorIs(t, err, accesscontrol.ErrRoleNotFound)
+
+		_, err = store.UpdateRole(ctx, accesscontrol.UpdateRoleCommand{
+			OrgID:   1,
+			UID:     "dashboards-editor",
+			Version: 1,
+			Name:    "custom:dashboards:editor",
+		})
+		require.ErrorIs(t, err, accesscontrol.ErrRoleVersionTooLow)
+
+		updated, err := store.UpdateRole(ctx, accesscontrol.UpdateRoleCommand{
+			OrgID:   1,
+			UID:     "dashboards-editor",
+			Version: 2,
+			Name:    "custom:dashboards:editor",
+			Permissions: []accesscontrol.Permission{
+				{Action: "dashboards:read", Scope: "folders:uid:production"},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated.Version)
+
+		role, err = store.GetRole(ctx, 1, "dashboards-editor")
+		require.NoError(t, err)
+		require.Len(t, role.Permissions, 1)
+		assert.Equal(t, "dashboards:read", role.Permissions[0].Action)
+		assert.Empty(t, role.Description)
+
+		require.NoError(t, store.DeleteRole(ctx, accesscontrol.DeleteRoleCommand{OrgID: 1, UID: "dashboards-editor"}))
+		_, err = store.GetRole(ctx, 1, "dashboards-editor")
+		require.ErrorIs(t, err, accesscontrol.ErrRoleNotFound)
+	})
+
+	t.Run("should list the global roles and the roles of the organization", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+		createRole(t, store, accesscontrol.GlobalOrgID, "custom:global")
+		createRole(t, store, 1, "custom:org1")
+		createRole(t, store, 2, "custom:org2")
+
+		roles, err := store.GetRoles(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, roles, 2)
+		assert.Equal(t, "custom:global", roles[0].Name)
+		assert.True(t, roles[0].Global())
+		assert.Equal(t, "custom:org1", roles[1].Name)
+	})
+
+	t.Run("should validate the roles", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+		createRole(t, store, 1, "custom:editor")
+
+		testCases := []struct {
+			desc string
+			cmd  accesscontrol.CreateRoleCommand
+			err  error
+		}{
+			{
+				desc: "name is missing",
+				cmd:  accesscontrol.CreateRoleCommand{OrgID: 1},
+				err:  accesscontrol.ErrRoleNameMissing,
+			},
+			{
+				desc: "name has the fixed roles prefix",
+				cmd:  accesscontrol.CreateRoleCommand{OrgID: 1, Name: "fixed:datasources:admin"},
+				err:  accesscontrol.ErrFixedRolePrefix,
+			},
+			{
+				desc: "name is used in the organization",
+				cmd:  accesscontrol.CreateRoleCommand{OrgID: 1, Name: "custom:editor"},
+				err:  accesscontrol.ErrRoleAlreadyExists,
+			},
+			{
+				desc: "uid is not valid",
+				cmd:  accesscontrol.CreateRoleCommand{OrgID: 1, Name: "custom:viewer", UID: "not valid"},
+				err:  accesscontrol.ErrRoleUIDInvalid,
+			},
+			{
+				desc: "scope is not valid",
+				cmd: accesscontrol.CreateRoleCommand{OrgID: 1, Name: "custom:viewer", Permissions: []accesscontrol.Permission{
+					{Action: "dashboards:read", Scope: "folders:*:production"},
+				}},
+				err: accesscontrol.ErrInvalidPermission,
+			},
+		}
+		for _, tc := range testCases {
+			t.Run("should fail when the "+tc.desc, func(t *testing.T) {
+				_, err := store.CreateRole(ctx, tc.cmd)
+				require.ErrorIs(t, err, tc.err)
+			})
+		}
+
+		t.Run("should allow the same name in another organization", func(t *testing.T) {
+			createRole(t, store, 2, "custom:editor")
+		})
+	})
+}
+
+func TestAccessControlStore_Assignments(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*AccessControlStore, *models.User, *models.Team) {
+		store, sqlStore := setupTestEnv(t)
+
+		user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "editor", OrgId: 1})
+		require.NoError(t, err)
+		_, err = sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "other", OrgId: 1})
+		require.NoError(t, err)
+		team, err := sqlStore.CreateTeam("SRE", "", 1)
+		require.NoError(t, err)
+		require.NoError(t, sqlStore.AddTeamMember(user.Id, 1, team.Id, false, 0))
+		return store, user, &team
+	}
+
+	t.Run("should resolve the roles of a user", func(t *testing.T) {
+		store, user, team := setup(t)
+		userRole := createRole(t, store, 1, "custom:user", accesscontrol.Permission{Action: "dashboards:write", Scope: "folders:uid:production"})
+		teamRole := createRole(t, store, 1, "custom:team", accesscontrol.Permission{Action: "dashboards:read", Scope: "dashboards:*"})
+		globalRole := createRole(t, store, accesscontrol.GlobalOrgID, "custom:global", accesscontrol.Permission{Action: "annotations:read"})
+		viewerRole := createRole(t, store, 1, "custom:viewer", accesscontrol.Permission{Action: "folders:read"})
+		createRole(t, store, 1, "custom:unassigned", accesscontrol.Permission{Action: "datasources:write"})
+
+		require.NoError(t, store.AddUserRole(ctx, 1, user.Id, userRole.UID))
+		require.NoError(t, store.AddTeamRole(ctx, 1, team.Id, teamRole.UID))
+		require.NoError(t, store.AddBuiltInRoleAssignment(ctx, accesscontrol.GlobalOrgID, "Editor", globalRole.UID))
+		require.NoError(t, store.AddBuiltInRoleAssignment(ctx, 1, "Viewer", viewerRole.UID))
+		// assigning a role twice is not an error
+		require.NoError(t, store.AddUserRole(ctx, 1, user.Id, userRole.UID))
+
+		roles, err := store.GetUserRoles(ctx, accesscontrol.GetUserRolesQuery{
+			OrgID:        1,
+			UserID:       user.Id,
+			BuiltInRoles: []string{"Editor", "Viewer"},
+		})
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(roles))
+		for _, role := range roles {
+			names = append(names, role.Name)
+			require.Len(t, role.Permissions, 1)
+		}
+		assert.Equal(t, []string{"custom:global", "custom:team", "custom:user", "custom:viewer"}, names)
+
+		roles, err = store.GetUserRoles(ctx, accesscontrol.GetUserRolesQuery{OrgID: 2, UserID: user.Id, BuiltInRoles: []string{"Editor"}})
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, "custom:global", roles[0].Name)
+
+		assigned, err := store.GetUserAssignedRoles(ctx, 1, user.Id)
+		require.NoError(t, err)
+		require.Len(t, assigned, 1)
+		assert.Equal(t, userRole.UID, assigned[0].UID)
+
+		assigned, err = store.GetTeamAssignedRoles(ctx, 1, team.Id)
+		require.NoError(t, err)
+		require.Len(t, assigned, 1)
+		assert.Equal(t, teamRole.UID, assigned[0].UID)
+
+		assignments, err := store.GetBuiltInRoleAssignments(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, assignments["Editor"], 1)
+		assert.Equal(t, globalRole.UID, assignments["Editor"][0].UID)
+		require.Len(t, assignments["Viewer"], 1)
+		assert.Equal(t, viewerRole.UID, assignments["Viewer"][0].UID)
+
+		require.NoError(t, store.RemoveUserRole(ctx, 1, user.Id, userRole.UID))
+		require.NoError(t, store.RemoveTeamRole(ctx, 1, team.Id, teamRole.UID))
+		require.NoError(t, store.RemoveBuiltInRoleAssignment(ctx, 1, "Viewer", viewerRole.UID))
+		roles, err = store.GetUserRoles(ctx, accesscontrol.GetUserRolesQuery{OrgID: 1, UserID: user.Id, BuiltInRoles: []string{"Editor", "Viewer"}})
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, "custom:global", roles[0].Name)
+	})
+
+	t.Run("should not assign roles of other organizations", func(t *testing.T) {
+		store, user, team := setup(t)
+		role := createRole(t, store, 2, "custom:org2")
+
+		require.ErrorIs(t, store.AddUserRole(ctx, 1, user.Id, role.UID), accesscontrol.ErrRoleNotFound)
+		require.ErrorIs(t, store.AddTeamRole(ctx, 1, team.Id, role.UID), accesscontrol.ErrRoleNotFound)
+		require.ErrorIs(t, store.AddBuiltInRoleAssignment(ctx, 1, "Viewer", role.UID), accesscontrol.ErrRoleNotFound)
+		require.ErrorIs(t, store.AddBuiltInRoleAssignment(ctx, accesscontrol.GlobalOrgID, "Viewer", role.UID), accesscontrol.ErrRoleNotFound)
+		require.ErrorIs(t, store.AddBuiltInRoleAssignment(ctx, 2, "Owner", role.UID), accesscontrol.ErrInvalidBuiltinRole)
+	})
+
+	t.Run("should only delete assigned roles when forced", func(t *testing.T) {
+		store, user, _ := setup(t)
+		role := createRole(t, store, 1, "custom:user")
+		require.NoError(t, store.AddUserRole(ctx, 1, user.Id, role.UID))
+
+		err := store.DeleteRole(ctx, accesscontrol.DeleteRoleCommand{OrgID: 1, UID: role.UID})
+		require.ErrorIs(t, err, accesscontrol.ErrRoleAssigned)
+
+		require.NoError(t, store.DeleteRole(ctx, accesscontrol.DeleteRoleCommand{OrgID: 1, UID: role.UID, Force: true}))
+		assigned, err := store.GetUserAssignedRoles(ctx, 1, user.Id)
+		require.NoError(t, err)
+		require.Empty(t, assigned)
+	})
+}
@@ -5,4 +5,13 @@ import "errors"
 var (
 	ErrFixedRolePrefixMissing = errors.New("fixed role should be prefixed with '" + FixedRolePrefix + "'")
 	ErrInvalidBuiltinRole     = errors.New("built-in role is not valid")
+	ErrFixedRolePrefix        = errors.New("custom role can't be prefixed with '" + FixedRolePrefix + "'")
+	ErrRoleNameMissing        = errors.New("role name is missing")
+	ErrRoleUIDInvalid         = errors.New("role uid is not valid")
+	ErrRoleNotFound           = errors.New("role not found")
+	ErrRoleAlreadyExists      = errors.New("a role with the same name or uid already exists")
+	ErrRoleVersionTooLow      = errors.New("role version must be greater than the version of the stored role")
+	ErrRoleAssigned           = errors.New("role is assigned, force the deletion to delete its assignments too")
+	ErrRoleNotGlobal          = errors.New("only global roles can be assigned globally")
+	ErrInvalidPermission      = errors.New("permission is not valid")
 )
@@ -143,6 +143,79 @@ func (p Permission) OSSPermission() Permission {
 	}
 }
 
+// BuiltinRole is the assignment of a role to a built-in role ("Viewer", "Editor", "Admin" or "Grafana Admin").
+// Assignments of the global organization apply to every organization.
+type BuiltinRole struct {
+	ID     int64  `json:"-" xorm:"pk autoincr 'id'"`
+	RoleID int64  `json:"-" xorm:"role_id"`
+	OrgID  int64  `json:"-" xorm:"org_id"`
+	Role   string `json:"role"`
+
+	Updated time.Time `json:"updated"`
+	Created time.Time `json:"created"`
+}
+
+// UserRole is the assignment of a role to a user in an organization.
+type UserRole struct {
+	ID     int64 `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID  int64 `json:"-" xorm:"org_id"`
+	RoleID int64 `json:"-" xorm:"role_id"`
+	UserID int64 `json:"userId" xorm:"user_id"`
+
+	Created time.Time `json:"created"`
+}
+
+// TeamRole is the assignment of a role to a team.
+type TeamRole struct {
+	ID     int64 `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID  int64 `json:"-" xorm:"org_id"`
+	RoleID int64 `json:"-" xorm:"role_id"`
+	TeamID int64 `json:"teamId" xorm:"team_id"`
+
+	Created time.Time `json:"created"`
+}
+
+// CreateRoleCommand creates a custom role, in the organization OrgID or globally.
+type CreateRoleCommand struct {
+	UID         string       `json:"uid"`
+	Global      bool         `json:"global"`
+	Version     int64        `json:"version"`
+	Name        string       `json:"name"`
+	DisplayName string       `json:"displayName"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+
+	OrgID int64 `json:"-"`
+}
+
+// UpdateRoleCommand replaces a custom role and its permissions. Version must be greater than the version of the role.
+type UpdateRoleCommand struct {
+	Version     int64        `json:"version"`
+	Name        string       `json:"name"`
+	DisplayName string       `json:"displayName"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+
+	UID   string `json:"-"`
+	OrgID int64  `json:"-"`
+}
+
+// DeleteRoleCommand deletes a custom role. Roles that are assigned are only deleted with Force,
+// which deletes their assignments too.
+type DeleteRoleCommand struct {
+	UID   string
+	OrgID int64
+	Force bool
+}
+
+// GetUserRolesQuery selects the custom roles of a user in an organization: the roles assigned
+// to the user, to the teams of the user and to the built-in roles of the user.
+type GetUserRolesQuery struct {
+	OrgID        int64
+	UserID       int64
+	BuiltInRoles []string
+}
+
 // ScopeParams holds the parameters used to fill in scope templates
 type ScopeParams struct {
 	OrgID     int64
@@ -205,9 +278,33 @@ const (
 	// Users scope
 	ScopeUsersAll = "users:*"
 
+	// Teams scope
+	ScopeTeamsAll = "teams:*"
+
 	// Settings scope
 	ScopeSettingsAll = "settings:*"
 
+	// Roles actions
+	ActionRolesList          = "roles:list"
+	ActionRolesRead          = "roles:read"
+	ActionRolesWrite         = "roles:write"
+	ActionRolesDelete        = "roles:delete"
+	ActionBuiltinRolesList   = "roles.builtin:list"
+	ActionBuiltinRolesAdd    = "roles.builtin:add"
+	ActionBuiltinRolesRemove = "roles.builtin:remove"
+	ActionUsersRolesList     = "users.roles:list"
+	ActionUsersRolesAdd      = "users.roles:add"
+	ActionUsersRolesRemove   = "users.roles:remove"
+	ActionTeamsRolesList     = "teams.roles:list"
+	ActionTeamsRolesAdd      = "teams.roles:add"
+	ActionTeamsRolesRemove   = "teams.roles:remove"
+
+	// Roles scopes
+	ScopeRolesAll = "roles:*"
+	// ScopePermissionsDelegate restricts the management of roles to the roles granting permissions
+	// the signed in user has, so that users can't escalate their privileges.
+	ScopePermissionsDelegate = "permissions:delegate"
+
 	// Licensing related actions
 	ActionLicensingRead        = "licensing:read"
 	ActionLicensingUpdate      = "licensing:update"
@@ -0,0 +1,419 @@
+package ossaccesscontrol
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/go-macaron/binding"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmiddleware "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (ac *OSSAccessControlService) registerAPIEndpoints() {
+	if ac.RouteRegister == nil || ac.store == nil {
+		return
+	}
+
+	authorize := acmiddleware.Middleware(ac)
+	ac.RouteRegister.Group("/api/access-control", func(r routing.RouteRegister) {
+		r.Get("/roles", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionRolesList, accesscontrol.ScopeRolesAll)), routing.Wrap(ac.getRolesHandler))
+		r.Get("/roles/:uid", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionRolesRead, accesscontrol.ScopeRolesAll)), routing.Wrap(ac.getRoleHandler))
+		r.Post("/roles", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionRolesWrite, accesscontrol.ScopePermissionsDelegate)), binding.Bind(accesscontrol.CreateRoleCommand{}), routing.Wrap(ac.createRoleHandler))
+		r.Put("/roles/:uid", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionRolesWrite, accesscontrol.ScopePermissionsDelegate)), binding.Bind(accesscontrol.UpdateRoleCommand{}), routing.Wrap(ac.updateRoleHandler))
+		r.Delete("/roles/:uid", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionRolesDelete, accesscontrol.ScopePermissionsDelegate)), routing.Wrap(ac.deleteRoleHandler))
+
+		r.Get("/builtin-roles", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionBuiltinRolesList, accesscontrol.ScopeRolesAll)), routing.Wrap(ac.getBuiltInRolesHandler))
+		r.Post("/builtin-roles", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionBuiltinRolesAdd, accesscontrol.ScopePermissionsDelegate)), binding.Bind(addBuiltInRoleCommand{}), routing.Wrap(ac.addBuiltInRoleHandler))
+		r.Delete("/builtin-roles/:builtinRole/roles/:uid", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionBuiltinRolesRemove, accesscontrol.ScopePermissionsDelegate)), routing.Wrap(ac.removeBuiltInRoleHandler))
+
+		userIDScope := accesscontrol.Scope("users", "id", accesscontrol.Parameter(":userId"))
+		r.Get("/users/:userId/roles", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionUsersRolesList, userIDScope)), routing.Wrap(ac.getUserRolesHandler))
+		r.Post("/users/:userId/roles", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionUsersRolesAdd, accesscontrol.ScopePermissionsDelegate)), binding.Bind(addRoleAssignmentCommand{}), routing.Wrap(ac.addUserRoleHandler))
+		r.Delete("/users/:userId/roles/:uid", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionUsersRolesRemove, accesscontrol.ScopePermissionsDelegate)), routing.Wrap(ac.removeUserRoleHandler))
+
+		teamIDScope := accesscontrol.Scope("teams", "id", accesscontrol.Parameter(":teamId"))
+		r.Get("/teams/:teamId/roles", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionTeamsRolesList, teamIDScope)), routing.Wrap(ac.getTeamRolesHandler))
+		r.Post("/teams/:teamId/roles", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionTeamsRolesAdd, accesscontrol.ScopePermissionsDelegate)), binding.Bind(addRoleAssignmentCommand{}), routing.Wrap(ac.addTeamRoleHandler))
+		r.Delete("/teams/:teamId/roles/:uid", authorize(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(accesscontrol.ActionTeamsRolesRemove, accesscontrol.ScopePermissionsDelegate)), routing.Wrap(ac.removeTeamRoleHandler))
+	}, middleware.ReqSignedIn)
+}
+
+type addBuiltInRoleCommand struct {
+	RoleUID     string `json:"roleUid"`
+	BuiltinRole string `json:"builtinRole"`
+	Global      bool   `json:"global"`
+}
+
+type addRoleAssignmentCommand struct {
+	RoleUID string `json:"roleUid"`
+}
+
+// getRolesHandler handles GET /api/access-control/roles.
+func (ac *OSSAccessControlService) getRolesHandler(c *models.ReqContext) response.Response {
+	roles, err := ac.store.GetRoles(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return toRoleError(err, "Failed to get roles")
+	}
+
+	// fixed roles are listed without their permissions, like the custom roles
+	for _, fixed := range accesscontrol.FixedRoles {
+		role := fixed
+		role.Permissions = nil
+		roles = append(roles, &role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return response.JSON(http.StatusOK, roles)
+}
+
+// getRoleHandler handles GET /api/access-control/roles/:uid.
+func (ac *OSSAccessControlService) getRoleHandler(c *models.ReqContext) response.Response {
+	role, err := ac.store.GetRole(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toRoleError(err, "Failed to get role")
+	}
+
+	return response.JSON(http.StatusOK, role)
+}
+
+// createRoleHandler handles POST /api/access-control/roles.
+func (ac *OSSAccessControlService) createRoleHandler(c *models.ReqContext, cmd accesscontrol.CreateRoleCommand) response.Response {
+	if cmd.Global && !c.IsGrafanaAdmin {
+		return response.Error(http.StatusForbidden, "Only Grafana Admins can create global roles", nil)
+	}
+	if resp := ac.checkDelegation(c, cmd.Permissions); resp != nil {
+		return resp
+	}
+
+	cmd.OrgID = c.OrgId
+	role, err := ac.store.CreateRole(c.Req.Context(), cmd)
+	if err != nil {
+		return toRoleError(err, "Failed to create role")
+	}
+	ac.invalidatePermissionsCache()
+
+	return response.JSON(http.StatusOK, role)
+}
+
+// updateRoleHandler handles PUT /api/access-control/roles/:uid.
+func (ac *OSSAccessControlService) updateRoleHandler(c *models.ReqContext, cmd accesscontrol.UpdateRoleCommand) response.Response {
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.OrgId
+
+	existing, resp := ac.getDelegableRole(c, cmd.UID)
+	if resp != nil {
+		return resp
+	}
+	if existing.Global() && !c.IsGrafanaAdmin {
+		return response.Error(http.StatusForbidden, "Only Grafana Admins can update global roles", nil)
+	}
+	// the role can't be changed to grant permissions the user doesn't have
+	if resp := ac.checkDelegation(c, cmd.Permissions); resp != nil {
+		return resp
+	}
+
+	role, err := ac.store.UpdateRole(c.Req.Context(), cmd)
+	if err != nil {
+		return toRoleError(err, "Failed to update role")
+	}
+	ac.invalidatePermissionsCache()
+
+	return response.JSON(http.StatusOK, role)
+}
+
+// deleteRoleHandler handles DELETE /api/access-control/roles/:uid.
+func (ac *OSSAccessControlService) deleteRoleHandler(c *models.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	existing, resp := ac.getDelegableRole(c, uid)
+	if resp != nil {
+		return resp
+	}
+	if existing.Global() && !c.IsGrafanaAdmin {
+		return response.Error(http.StatusForbidden, "Only Grafana Admins can delete global roles", nil)
+	}
+
+	err := ac.store.DeleteRole(c.Req.Context(), accesscontrol.DeleteRoleCommand{
+		UID:   uid,
+		OrgID: c.OrgId,
+		Force: c.QueryBool("force"),
+	})
+	if err != nil {
+		return toRoleError(err, "Failed to delete role")
+	}
+	ac.invalidatePermissionsCache()
+
+	return response.Success("Role deleted")
+}
+
+// getBuiltInRolesHandler handles GET /api/access-control/builtin-roles.
+func (ac *OSSAccessControlService) getBuiltInRolesHandler(c *models.ReqContext) response.Response {
+	assignments, err := ac.store.GetBuiltInRoleAssignments(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return toRoleError(err, "Failed to get built-in role assignments")
+	}
+
+	for builtin, names := range accesscontrol.FixedRoleGrants {
+		for _, name := range names {
+			role, ok := accesscontrol.FixedRoles[name]
+			if !ok {
+				continue
+			}
+			role.Permissions = nil
+			assignments[builtin] = append(assignments[builtin], &role)
+		}
+	}
+
+	return response.JSON(http.StatusOK, assignments)
+}
+
+// addBuiltInRoleHandler handles POST /api/access-control/builtin-roles.
+func (ac *OSSAccessControlService) addBuiltInRoleHandler(c *models.ReqContext, cmd addBuiltInRoleCommand) response.Response {
+	if err := accesscontrol.ValidateBuiltInRoles([]string{cmd.BuiltinRole}); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	if cmd.Global && !c.IsGrafanaAdmin {
+		return response.Error(http.StatusForbidden, "Only Grafana Admins can assign roles globally", nil)
+	}
+	if _, resp := ac.getDelegableRole(c, cmd.RoleUID); resp != nil {
+		return resp
+	}
+
+	if err := ac.store.AddBuiltInRoleAssignment(c.Req.Context(), assignmentOrgID(c, cmd.Global), cmd.BuiltinRole, cmd.RoleUID); err != nil {
+		return toRoleError(err, "Failed to add built-in role grant")
+	}
+	ac.invalidatePermissionsCache()
+
+	return response.Success("Built-in role grant added")
+}
+
+// removeBuiltInRoleHandler handles DELETE /api/access-control/builtin-roles/:builtinRole/roles/:uid.
+func (ac *OSSAccessControlService) removeBuiltInRoleHandler(c *models.ReqContext) response.Response {
+	global := c.QueryBool("global")
+	if global && !c.IsGrafanaAdmin {
+		return response.Error(http.StatusForbidden, "Only Grafana Admins can remove global role grants", nil)
+	}
+	uid := web.Params(c.Req)[":uid"]
+	if _, resp := ac.getDelegableRole(c, uid); resp != nil {
+		return resp
+	}
+
+	if err := ac.store.RemoveBuiltInRoleAssignment(c.Req.Context(), assignmentOrgID(c, global), web.Params(c.Req)[":builtinRole"], uid); err != nil {
+		return toRoleError(err, "Failed to remove built-in role grant")
+	}
+	ac.invalidatePermissionsCache()
+
+	return response.Success("Built-in role grant removed")
+}
+
+// getUserRolesHandler handles GET /api/access-control/users/:userId/roles.
+func (ac *OSSAccessControlService) getUserRolesHandler(c *models.ReqContext) response.Response {
+	userID, resp := ac.getOrgUserID(c)
+	if resp != nil {
+		return resp
+	}
+
+	roles, err := ac.store.GetUserAssignedRoles(c.Req.Context(), c.OrgId, userID)
+	if err != nil {
+		return toRoleError(err, "Failed to get user roles")
+	}
+
+	return response.JSON(http.StatusOK, roles)
+}
+
+// addUserRoleHandler handles POST /api/access-control/users/:userId/roles.
+func (ac *OSSAccessControlService) addUserRoleHandler(c *models.ReqContext, cmd addRoleAssignmentCommand) response.Response {
+	userID, resp := ac.getOrgUserID(c)
+	if resp != nil {
+		return resp
+	}
+	if _, resp := ac.getDelegableRole(c, cmd.RoleUID); resp != nil {
+		return resp
+	}
+
+	if err := ac.store.AddUserRole(c.Req.Context(), c.OrgId, userID, cmd.RoleUID); err != nil {
+		return toRoleError(err, "Failed to add user role")
+	}
+	ac.invalidatePermissionsCache()
+
+	return response.Success("Role added to the user")
+}
+
+// removeUserRoleHandler handles DELETE /api/access-control/users/:userId/roles/:uid.
+func (ac *OSSAccessControlService) removeUserRoleHandler(c *models.ReqContext) response.Response {
+	userID, resp := ac.getOrgUserID(c)
+	if resp != nil {
+		return resp
+	}
+	uid := web.Params(c.Req)[":uid"]
+	if _, resp := ac.getDelegableRole(c, uid); resp != nil {
+		return resp
+	}
+
+	if err := ac.store.RemoveUserRole(c.Req.Context(), c.OrgId, userID, uid); err != nil {
+		return toRoleError(err, "Failed to remove user role")
+	}
+	ac.invalidatePermissionsCache()
+
+	return response.Success("Role removed from the user")
+}
+
+// getTeamRolesHandler handles GET /api/access-control/teams/:teamId/roles.
+func (ac *OSSAccessControlService) getTeamRolesHandler(c *models.ReqContext) response.Response {
+	teamID, resp := ac.getOrgTeamID(c)
+	if resp != nil {
+		return resp
+	}
+
+	roles, err := ac.store.GetTeamAssignedRoles(c.Req.Context(), c.OrgId, teamID)
+	if err != nil {
+		return toRoleError(err, "Failed to get team roles")
+	}
+
+	return response.JSON(http.StatusOK, roles)
+}
+
+// addTeamRoleHandler handles POST /api/access-control/teams/:teamId/roles.
+func (ac *OSSAccessControlService) addTeamRoleHandler(c *models.ReqContext, cmd addRoleAssignmentCommand) response.Response {
+	teamID, resp := ac.getOrgTeamID(c)
+	if resp != nil {
+		return resp
+	}
+	if _, resp := ac.getDelegableRole(c, cmd.RoleUID); resp != nil {
+		return resp
+	}
+
+	if err := ac.store.AddTeamRole(c.Req.Context(), c.OrgId, teamID, cmd.RoleUID); err != nil {
+		return toRoleError(err, "Failed to add team role")
+	}
+	ac.invalidatePermissionsCache()
+
+	return response.Success("Role added to the team")
+}
+
+// removeTeamRoleHandler handles DELETE /api/access-control/teams/:teamId/roles/:uid.
+func (ac *OSSAccessControlService) removeTeamRoleHandler(c *models.ReqContext) response.Response {
+	teamID, resp := ac.getOrgTeamID(c)
+	if resp != nil {
+		return resp
+	}
+	uid := web.Params(c.Req)[":uid"]
+	if _, resp := ac.getDelegableRole(c, uid); resp != nil {
+		return resp
+	}
+
+	if err := ac.store.RemoveTeamRole(c.Req.Context(), c.OrgId, teamID, uid); err != nil {
+		return toRoleError(err, "Failed to remove team role")
+	}
+	ac.invalidatePermissionsCache()
+
+	return response.Success("Role removed from the team")
+}
+
+// getDelegableRole returns the custom role uid when the signed in user has all of its permissions,
+// and so may manage it without escalating its own privileges.
+func (ac *OSSAccessControlService) getDelegableRole(c *models.ReqContext, uid string) (*accesscontrol.RoleDTO, response.Response) {
+	role, err := ac.store.GetRole(c.Req.Context(), c.OrgId, uid)
+	if err != nil {
+		return nil, toRoleError(err, "Failed to get role")
+	}
+	if resp := ac.checkDelegation(c, role.Permissions); resp != nil {
+		return nil, resp
+	}
+	return role, nil
+}
+
+// checkDelegation returns an error response when the signed in user doesn't have all the permissions.
+func (ac *OSSAccessControlService) checkDelegation(c *models.ReqContext, permissions []accesscontrol.Permission) response.Response {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	evaluators := make([]accesscontrol.Evaluator, 0, len(permissions))
+	for _, p := range permissions {
+		if p.Scope == "" {
+			evaluators = append(evaluators, accesscontrol.EvalPermission(p.Action))
+			continue
+		}
+		evaluators = append(evaluators, accesscontrol.EvalPermission(p.Action, p.Scope))
+	}
+
+	hasAccess, err := ac.Evaluate(c.Req.Context(), c.SignedInUser, accesscontrol.EvalAll(evaluators...))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !hasAccess {
+		return response.Error(http.StatusForbidden, "Roles can only grant permissions you have", nil)
+	}
+	return nil
+}
+
+// getOrgUserID returns the :userId parameter when the user is a member of the organization of the signed in user.
+func (ac *OSSAccessControlService) getOrgUserID(c *models.ReqContext) (int64, response.Response) {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":userId"], 10, 64)
+	if err != nil {
+		return 0, response.Error(http.StatusBadRequest, "userId is invalid", err)
+	}
+
+	query := models.GetSignedInUserQuery{UserId: userID, OrgId: c.OrgId}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return 0, response.Error(http.StatusNotFound, "User not found", nil)
+		}
+		return 0, response.Error(http.StatusInternalServerError, "Failed to get user", err)
+	}
+	if query.Result.OrgId != c.OrgId {
+		return 0, response.Error(http.StatusNotFound, "User not found", nil)
+	}
+	return userID, nil
+}
+
+// getOrgTeamID returns the :teamId parameter when the team belongs to the organization of the signed in user.
+func (ac *OSSAccessControlService) getOrgTeamID(c *models.ReqContext) (int64, response.Response) {
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return 0, response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	query := models.GetTeamByIdQuery{OrgId: c.OrgId, Id: teamID, SignedInUser: c.SignedInUser}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrTeamNotFound) {
+			return 0, response.Error(http.StatusNotFound, "Team not found", nil)
+		}
+		return 0, response.Error(http.StatusInternalServerError, "Failed to get team", err)
+	}
+	return teamID, nil
+}
+
+func assignmentOrgID(c *models.ReqContext, global bool) int64 {
+	if global {
+		return accesscontrol.GlobalOrgID
+	}
+	return c.OrgId
+}
+
+func toRoleError(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, accesscontrol.ErrRoleNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, accesscontrol.ErrRoleNameMissing),
+		errors.Is(err, accesscontrol.ErrFixedRolePrefix),
+		errors.Is(err, accesscontrol.ErrRoleUIDInvalid),
+		errors.Is(err, accesscontrol.ErrInvalidPermission),
+		errors.Is(err, accesscontrol.ErrRoleAlreadyExists),
+		errors.Is(err, accesscontrol.ErrRoleVersionTooLow),
+		errors.Is(err, accesscontrol.ErrRoleAssigned),
+		errors.Is(err, accesscontrol.ErrRoleNotGlobal):
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,209 @@
+package ossaccesscontrol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	grafanaAdmin = &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN, IsGrafanaAdmin: true}
+	orgAdmin     = &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+
+	// granted to Grafana Admins by the fixed:users:admin:read role
+	usersRead = accesscontrol.Permission{Action: accesscontrol.ActionUsersRead, Scope: accesscontrol.ScopeGlobalUsersAll}
+)
+
+func newRequestContext(user *models.SignedInUser, target string, params map[string]string) *models.ReqContext {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	return &models.ReqContext{
+		Context:      &web.Context{Req: web.SetURLParams(req, params)},
+		SignedInUser: user,
+	}
+}
+
+func TestOSSAccessControlService_RolesAPI(t *testing.T) {
+	t.Run("should create, update and delete a role", func(t *testing.T) {
+		ac, _, sqlStore := setupCustomRolesTestEnv(t)
+		_, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "admin", OrgId: 1})
+		require.NoError(t, err)
+
+		resp := ac.createRoleHandler(newRequestContext(grafanaAdmin, "/", nil), accesscontrol.CreateRoleCommand{
+			UID:         "users-reader",
+			Version:     1,
+			Name:        "custom:users:reader",
+			Permissions: []accesscontrol.Permission{usersRead},
+		})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		params := map[string]string{":uid": "users-reader"}
+		resp = ac.getRoleHandler(newRequestContext(grafanaAdmin, "/", params))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var role accesscontrol.RoleDTO
+		require.NoError(t, json.Unmarshal(resp.Body(), &role))
+		assert.Equal(t, "custom:users:reader", role.Name)
+		assert.Equal(t, int64(1), role.Version)
+
+		update := accesscontrol.UpdateRoleCommand{Version: 1, Name: "custom:users:reader", DisplayName: "Users reader", Permissions: []accesscontrol.Permission{usersRead}}
+		resp = ac.updateRoleHandler(newRequestContext(grafanaAdmin, "/", params), update)
+		assert.Equal(t, http.StatusBadRequest, resp.Status(), "the version must be incremented")
+
+		update.Version = 2
+		resp = ac.updateRoleHandler(newRequestContext(grafanaAdmin, "/", params), update)
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.NoError(t, json.Unmarshal(resp.Body(), &role))
+		assert.Equal(t, "Users reader", role.DisplayName)
+
+		resp = ac.addUserRoleHandler(newRequestContext(grafanaAdmin, "/", map[string]string{":userId": "1"}), addRoleAssignmentCommand{RoleUID: "users-reader"})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		resp = ac.deleteRoleHandler(newRequestContext(grafanaAdmin, "/", params))
+		assert.Equal(t, http.StatusBadRequest, resp.Status(), "assigned roles are only deleted when forced")
+		resp = ac.deleteRoleHandler(newRequestContext(grafanaAdmin, "/?force=true", params))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		resp = ac.getRoleHandler(newRequestContext(grafanaAdmin, "/", params))
+		assert.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("should list the fixed roles with the custom roles", func(t *testing.T) {
+		ac, _, _ := setupCustomRolesTestEnv(t)
+		resp := ac.createRoleHandler(newRequestContext(grafanaAdmin, "/", nil), accesscontrol.CreateRoleCommand{Name: "custom:users:reader"})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		resp = ac.getRolesHandler(newRequestContext(grafanaAdmin, "/", nil))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var roles []accesscontrol.RoleDTO
+		require.NoError(t, json.Unmarshal(resp.Body(), &roles))
+		require.Len(t, roles, len(accesscontrol.FixedRoles)+1)
+		for _, role := range roles {
+			assert.Empty(t, role.Permissions)
+		}
+	})
+
+	t.Run("should refuse to delegate permissions the user doesn't have", func(t *testing.T) {
+		ac, _, sqlStore := setupCustomRolesTestEnv(t)
+		_, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "admin", OrgId: 1})
+		require.NoError(t, err)
+
+		cmd := accesscontrol.CreateRoleCommand{UID: "users-reader", Name: "custom:users:reader", Permissions: []accesscontrol.Permission{usersRead}}
+		resp := ac.createRoleHandler(newRequestContext(orgAdmin, "/", nil), cmd)
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+
+		resp = ac.createRoleHandler(newRequestContext(grafanaAdmin, "/", nil), cmd)
+		require.Equal(t, http.StatusOK, resp.Status())
+		params := map[string]string{":uid": "users-reader"}
+		resp = ac.addUserRoleHandler(newRequestContext(orgAdmin, "/", map[string]string{":userId": "1"}), addRoleAssignmentCommand{RoleUID: "users-reader"})
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+		resp = ac.deleteRoleHandler(newRequestContext(orgAdmin, "/", params))
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+	})
+
+	t.Run("should only let Grafana Admins manage global roles", func(t *testing.T) {
+		ac, _, _ := setupCustomRolesTestEnv(t)
+
+		cmd := accesscontrol.CreateRoleCommand{UID: "global-role", Name: "custom:global", Global: true}
+		resp := ac.createRoleHandler(newRequestContext(orgAdmin, "/", nil), cmd)
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+		resp = ac.createRoleHandler(newRequestContext(grafanaAdmin, "/", nil), cmd)
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		assignment := addBuiltInRoleCommand{RoleUID: "global-role", BuiltinRole: string(models.ROLE_EDITOR), Global: true}
+		resp = ac.addBuiltInRoleHandler(newRequestContext(orgAdmin, "/", nil), assignment)
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+		resp = ac.addBuiltInRoleHandler(newRequestContext(grafanaAdmin, "/", nil), assignment)
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		resp = ac.getBuiltInRolesHandler(newRequestContext(grafanaAdmin, "/", nil))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var assignments map[string][]accesscontrol.RoleDTO
+		require.NoError(t, json.Unmarshal(resp.Body(), &assignments))
+		assert.Len(t, assignments[string(models.ROLE_EDITOR)], len(accesscontrol.FixedRoleGrants[string(models.ROLE_EDITOR)])+1)
+
+		params := map[string]string{":builtinRole": string(models.ROLE_EDITOR), ":uid": "global-role"}
+		resp = ac.removeBuiltInRoleHandler(newRequestContext(orgAdmin, "/?global=true", params))
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+		resp = ac.removeBuiltInRoleHandler(newRequestContext(grafanaAdmin, "/?global=true", params))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		resp = ac.addBuiltInRoleHandler(newRequestContext(grafanaAdmin, "/", nil), addBuiltInRoleCommand{RoleUID: "global-role", BuiltinRole: "Owner"})
+		assert.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+}
+
+func TestOSSAccessControlService_RoleAssignmentsAPI(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should assign roles to the users and the teams of the organization", func(t *testing.T) {
+		ac, _, sqlStore := setupCustomRolesTestEnv(t)
+		user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "editor", OrgId: 1})
+		require.NoError(t, err)
+		team, err := sqlStore.CreateTeam("SRE", "", 1)
+		require.NoError(t, err)
+
+		resp := ac.createRoleHandler(newRequestContext(grafanaAdmin, "/", nil), accesscontrol.CreateRoleCommand{UID: "users-reader", Name: "custom:users:reader", Permissions: []accesscontrol.Permission{usersRead}})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		userParams := map[string]string{":userId": strconv.FormatInt(user.Id, 10), ":uid": "users-reader"}
+		resp = ac.addUserRoleHandler(newRequestContext(grafanaAdmin, "/", userParams), addRoleAssignmentCommand{RoleUID: "users-reader"})
+		require.Equal(t, http.StatusOK, resp.Status())
+		teamParams := map[string]string{":teamId": strconv.FormatInt(team.Id, 10), ":uid": "users-reader"}
+		resp = ac.addTeamRoleHandler(newRequestContext(grafanaAdmin, "/", teamParams), addRoleAssignmentCommand{RoleUID: "users-reader"})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var roles []accesscontrol.RoleDTO
+		resp = ac.getUserRolesHandler(newRequestContext(grafanaAdmin, "/", userParams))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.NoError(t, json.Unmarshal(resp.Body(), &roles))
+		require.Len(t, roles, 1)
+		resp = ac.getTeamRolesHandler(newRequestContext(grafanaAdmin, "/", teamParams))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.NoError(t, json.Unmarshal(resp.Body(), &roles))
+		require.Len(t, roles, 1)
+
+		signedInUser := &models.SignedInUser{UserId: user.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		hasAccess, err := ac.Evaluate(ctx, signedInUser, accesscontrol.EvalPermission(accesscontrol.ActionUsersRead, "global:users:id:1"))
+		require.NoError(t, err)
+		assert.True(t, hasAccess)
+
+		resp = ac.removeUserRoleHandler(newRequestContext(grafanaAdmin, "/", userParams))
+		require.Equal(t, http.StatusOK, resp.Status())
+		resp = ac.removeTeamRoleHandler(newRequestContext(grafanaAdmin, "/", teamParams))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		hasAccess, err = ac.Evaluate(ctx, signedInUser, accesscontrol.EvalPermission(accesscontrol.ActionUsersRead, "global:users:id:1"))
+		require.NoError(t, err)
+		assert.False(t, hasAccess, "removing an assignment should invalidate the cached permissions")
+	})
+
+	t.Run("should not assign roles to users and teams of other organizations", func(t *testing.T) {
+		ac, _, sqlStore := setupCustomRolesTestEnv(t)
+		// the admin is created first so that the ids of the users differ from the previous tests,
+		// the signed in users being cached
+		_, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "admin", OrgId: 1})
+		require.NoError(t, err)
+		org, err := sqlStore.CreateOrgWithMember("Other", 0)
+		require.NoError(t, err)
+		user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "other", OrgId: org.Id})
+		require.NoError(t, err)
+		team, err := sqlStore.CreateTeam("SRE", "", org.Id)
+		require.NoError(t, err)
+		resp := ac.createRoleHandler(newRequestContext(grafanaAdmin, "/", nil), accesscontrol.CreateRoleCommand{UID: "users-reader", Name: "custom:users:reader"})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		resp = ac.addUserRoleHandler(newRequestContext(grafanaAdmin, "/", map[string]string{":userId": fmt.Sprint(user.Id)}), addRoleAssignmentCommand{RoleUID: "users-reader"})
+		assert.Equal(t, http.StatusNotFound, resp.Status())
+		resp = ac.addTeamRoleHandler(newRequestContext(grafanaAdmin, "/", map[string]string{":teamId": fmt.Sprint(team.Id)}), addRoleAssignmentCommand{RoleUID: "users-reader"})
+		assert.Equal(t, http.StatusNotFound, resp.Status())
+	})
+}
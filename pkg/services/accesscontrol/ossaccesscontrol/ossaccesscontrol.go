@@ -3,34 +3,57 @@ package ossaccesscontrol
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-func ProvideService(cfg *setting.Cfg, usageStats usagestats.Service) *OSSAccessControlService {
+// permissionsCacheTTL is how long the permissions of the custom roles of a user are cached. Changes made
+// through the API of this instance are applied immediately, other changes within the TTL.
+const permissionsCacheTTL = time.Minute
+
+func ProvideService(cfg *setting.Cfg, usageStats usagestats.Service, store accesscontrol.Store, routeRegister routing.RouteRegister,
+	serviceAccountsStore serviceaccounts.Store) *OSSAccessControlService {
 	s := &OSSAccessControlService{
-		Cfg:           cfg,
-		UsageStats:    usageStats,
-		Log:           log.New("accesscontrol"),
-		scopeResolver: accesscontrol.NewScopeResolver(),
+		Cfg:                  cfg,
+		UsageStats:           usageStats,
+		RouteRegister:        routeRegister,
+		Log:                  log.New("accesscontrol"),
+		scopeResolver:        accesscontrol.NewScopeResolver(),
+		store:                store,
+		serviceAccountsStore: serviceAccountsStore,
+		permissionsCache:     localcache.New(permissionsCacheTTL, 2*permissionsCacheTTL),
 	}
 	s.registerUsageMetrics()
+	if !s.IsDisabled() {
+		s.registerAPIEndpoints()
+	}
 	return s
 }
 
 // OSSAccessControlService is the service implementing role based access control.
 type OSSAccessControlService struct {
-	Cfg           *setting.Cfg
-	UsageStats    usagestats.Service
-	Log           log.Logger
-	registrations accesscontrol.RegistrationList
-	scopeResolver accesscontrol.ScopeResolver
+	Cfg                  *setting.Cfg
+	UsageStats           usagestats.Service
+	RouteRegister        routing.RouteRegister
+	Log                  log.Logger
+	registrations        accesscontrol.RegistrationList
+	scopeResolver        accesscontrol.ScopeResolver
+	store                accesscontrol.Store
+	serviceAccountsStore serviceaccounts.Store
+	permissionsCache     *localcache.CacheService
 }
 
 func (ac *OSSAccessControlService) IsDisabled() bool {
@@ -72,22 +95,81 @@ func (ac *OSSAccessControlService) Evaluate(ctx context.Context, user *models.Si
 	return evaluator.Evaluate(accesscontrol.GroupScopesByAction(permissions))
 }
 
-// GetUserRoles returns user permissions based on built-in roles
+// GetUserRoles returns the fixed roles granted to the built-in roles of the user, and the custom roles
+// assigned to the user, to its teams and to its built-in roles
 func (ac *OSSAccessControlService) GetUserRoles(ctx context.Context, user *models.SignedInUser) ([]*accesscontrol.RoleDTO, error) {
-	return nil, errors.New("unsupported function") //OSS users will continue to use builtin roles via GetUserPermissions
+	builtinRoles := ac.GetUserBuiltInRoles(user)
+	roles := make([]*accesscontrol.RoleDTO, 0)
+	for _, name := range fixedRoleNames(builtinRoles) {
+		role := accesscontrol.FixedRoles[name]
+		roles = append(roles, &role)
+	}
+
+	if ac.store == nil {
+		return roles, nil
+	}
+	customRoles, err := ac.store.GetUserRoles(ctx, accesscontrol.GetUserRolesQuery{
+		OrgID:        user.OrgId,
+		UserID:       user.UserId,
+		BuiltInRoles: builtinRoles,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(roles, customRoles...), nil
 }
 
-// CloneUserToServiceAccount creates a service account with permissions based on a user
+// CloneUserToServiceAccount creates a service account with the organization role and the custom roles
+// assigned to the user
 func (ac *OSSAccessControlService) CloneUserToServiceAccount(ctx context.Context, user *models.SignedInUser) (*models.User, error) {
-	return nil, errors.New("clone user not implemented yet in service accounts") //Please switch on Enterprise to test this
+	if ac.serviceAccountsStore == nil {
+		return nil, errors.New("service accounts are not available")
+	}
+
+	serviceAccount, err := ac.serviceAccountsStore.CreateServiceAccount(ctx, user.OrgId, serviceaccounts.CreateServiceAccountForm{
+		Name: fmt.Sprintf("%s-%s", user.Login, util.GenerateShortUID()),
+		Role: user.OrgRole,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ac.store != nil {
+		roles, err := ac.store.GetUserAssignedRoles(ctx, user.OrgId, user.UserId)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			if err := ac.store.AddUserRole(ctx, user.OrgId, serviceAccount.Id, role.UID); err != nil {
+				return nil, err
+			}
+		}
+		ac.invalidatePermissionsCache()
+	}
+
+	return &models.User{
+		Id:               serviceAccount.Id,
+		OrgId:            serviceAccount.OrgId,
+		Login:            serviceAccount.Login,
+		Name:             serviceAccount.Name,
+		IsServiceAccount: true,
+	}, nil
 }
 
-// Link creates a service account with permissions based on a user
-func (ac *OSSAccessControlService) LinkAPIKeyToServiceAccount(context.Context, *models.ApiKey, *models.User) error {
-	return errors.New("link SA not implemented yet in service accounts") //Please switch on Enterprise to test this
+// LinkAPIKeyToServiceAccount makes the API key authenticate requests as the service account
+func (ac *OSSAccessControlService) LinkAPIKeyToServiceAccount(ctx context.Context, apiKey *models.ApiKey, serviceAccount *models.User) error {
+	if ac.serviceAccountsStore == nil {
+		return errors.New("service accounts are not available")
+	}
+
+	if err := ac.serviceAccountsStore.LinkAPIKeyToServiceAccount(ctx, apiKey.OrgId, apiKey.Id, serviceAccount.Id); err != nil {
+		return err
+	}
+	apiKey.ServiceAccountId = serviceAccount.Id
+	return nil
 }
 
-// GetUserPermissions returns user permissions based on built-in roles
+// GetUserPermissions returns user permissions based on built-in roles and on the custom roles of the user
 func (ac *OSSAccessControlService) GetUserPermissions(ctx context.Context, user *models.SignedInUser) ([]*accesscontrol.Permission, error) {
 	timer := prometheus.NewTimer(metrics.MAccessPermissionsSummary)
 	defer timer.ObserveDuration()
@@ -113,9 +195,79 @@ func (ac *OSSAccessControlService) GetUserPermissions(ctx context.Context, user
 		}
 	}
 
+	customPermissions, err := ac.getCustomRolesPermissions(ctx, user, builtinRoles)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range customPermissions {
+		permission, err := ac.scopeResolver.ResolveKeyword(user, p)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, nil
+}
+
+// getCustomRolesPermissions returns the permissions of the custom roles of the user, from the cache when possible.
+func (ac *OSSAccessControlService) getCustomRolesPermissions(ctx context.Context, user *models.SignedInUser, builtinRoles []string) ([]accesscontrol.Permission, error) {
+	if ac.store == nil {
+		return nil, nil
+	}
+
+	key := fmt.Sprintf("custom-roles-permissions-%d-%d-%s", user.OrgId, user.UserId, strings.Join(builtinRoles, ","))
+	if ac.permissionsCache != nil {
+		if cached, ok := ac.permissionsCache.Get(key); ok {
+			return cached.([]accesscontrol.Permission), nil
+		}
+	}
+
+	roles, err := ac.store.GetUserRoles(ctx, accesscontrol.GetUserRolesQuery{
+		OrgID:        user.OrgId,
+		UserID:       user.UserId,
+		BuiltInRoles: builtinRoles,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]accesscontrol.Permission, 0)
+	for _, role := range roles {
+		for _, p := range role.Permissions {
+			permissions = append(permissions, p.OSSPermission())
+		}
+	}
+
+	if ac.permissionsCache != nil {
+		ac.permissionsCache.Set(key, permissions, permissionsCacheTTL)
+	}
 	return permissions, nil
 }
 
+// invalidatePermissionsCache drops the cached permissions after a change of the custom roles or of their assignments.
+func (ac *OSSAccessControlService) invalidatePermissionsCache() {
+	if ac.permissionsCache != nil {
+		ac.permissionsCache.Flush()
+	}
+}
+
+// fixedRoleNames returns the names of the fixed roles granted to the built-in roles.
+func fixedRoleNames(builtinRoles []string) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, builtin := range builtinRoles {
+		for _, name := range accesscontrol.FixedRoleGrants[builtin] {
+			if _, exists := accesscontrol.FixedRoles[name]; !exists || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func (ac *OSSAccessControlService) GetUserBuiltInRoles(user *models.SignedInUser) []string {
 	roles := []string{string(user.OrgRole)}
 	for _, role := range user.OrgRole.Children() {
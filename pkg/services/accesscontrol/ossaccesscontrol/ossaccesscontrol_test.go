@@ -3,12 +3,17 @@ package ossaccesscontrol
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	serviceaccountsdatabase "github.com/grafana/grafana/pkg/services/serviceaccounts/database"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,7 +24,7 @@ func setupTestEnv(t testing.TB) *OSSAccessControlService {
 
 	cfg := setting.NewCfg()
 	cfg.FeatureToggles = map[string]bool{"accesscontrol": true}
-	ac := ProvideService(cfg, &usagestats.UsageStatsMock{T: t})
+	ac := ProvideService(cfg, &usagestats.UsageStatsMock{T: t}, nil, routing.NewRouteRegister(), nil)
 	return ac
 }
 
@@ -145,7 +150,7 @@ func TestUsageMetrics(t *testing.T) {
 				cfg.FeatureToggles = map[string]bool{"accesscontrol": true}
 			}
 
-			s := ProvideService(cfg, &usagestats.UsageStatsMock{T: t})
+			s := ProvideService(cfg, &usagestats.UsageStatsMock{T: t}, nil, routing.NewRouteRegister(), nil)
 			report, err := s.UsageStats.GetUsageReport(context.Background())
 			assert.Nil(t, err)
 
@@ -581,3 +586,137 @@ func TestOSSAccessControlService_GetUserPermissions(t *testing.T) {
 		})
 	}
 }
+
+func setupCustomRolesTestEnv(t *testing.T) (*OSSAccessControlService, *database.AccessControlStore, *sqlstore.SQLStore) {
+	t.Helper()
+
+	sqlStore := sqlstore.InitTestDB(t)
+	store := database.ProvideService(sqlStore)
+	cfg := setting.NewCfg()
+	cfg.FeatureToggles = map[string]bool{"accesscontrol": true}
+	return ProvideService(cfg, &usagestats.UsageStatsMock{T: t}, store, routing.NewRouteRegister(), serviceaccountsdatabase.ProvideServiceAccountsStore(sqlStore)), store, sqlStore
+}
+
+func TestOSSAccessControlService_CustomRoles(t *testing.T) {
+	ctx := context.Background()
+	dashboardsWrite := accesscontrol.Permission{Action: "dashboards:write", Scope: "folders:uid:production"}
+
+	t.Run("should grant the permissions of the custom roles of the user", func(t *testing.T) {
+		ac, store, sqlStore := setupCustomRolesTestEnv(t)
+		user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "editor", OrgId: 1})
+		require.NoError(t, err)
+		team, err := sqlStore.CreateTeam("SRE", "", 1)
+		require.NoError(t, err)
+		require.NoError(t, sqlStore.AddTeamMember(user.Id, 1, team.Id, false, 0))
+
+		userRole, err := store.CreateRole(ctx, accesscontrol.CreateRoleCommand{OrgID: 1, Name: "custom:dashboards:production", Permissions: []accesscontrol.Permission{dashboardsWrite}})
+		require.NoError(t, err)
+		teamRole, err := store.CreateRole(ctx, accesscontrol.CreateRoleCommand{OrgID: 1, Name: "custom:users:self", Permissions: []accesscontrol.Permission{{Action: "users:write", Scope: "users:self"}}})
+		require.NoError(t, err)
+		require.NoError(t, store.AddUserRole(ctx, 1, user.Id, userRole.UID))
+		require.NoError(t, store.AddTeamRole(ctx, 1, team.Id, teamRole.UID))
+
+		signedInUser := &models.SignedInUser{UserId: user.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		permissions, err := ac.GetUserPermissions(ctx, signedInUser)
+		require.NoError(t, err)
+		rawPermissions := extractRawPermissionsHelper(permissions)
+		assert.Contains(t, rawPermissions, &dashboardsWrite)
+		assert.Contains(t, rawPermissions, &accesscontrol.Permission{Action: "users:write", Scope: fmt.Sprintf("users:id:%d", user.Id)})
+
+		roles, err := ac.GetUserRoles(ctx, signedInUser)
+		require.NoError(t, err)
+		require.Len(t, roles, 2)
+
+		hasAccess, err := ac.Evaluate(ctx, signedInUser, accesscontrol.EvalPermission("dashboards:write", "folders:uid:production"))
+		require.NoError(t, err)
+		assert.True(t, hasAccess)
+		hasAccess, err = ac.Evaluate(ctx, signedInUser, accesscontrol.EvalPermission("dashboards:write", "folders:uid:staging"))
+		require.NoError(t, err)
+		assert.False(t, hasAccess)
+	})
+
+	t.Run("should grant the permissions of the custom roles of the built-in roles", func(t *testing.T) {
+		ac, store, _ := setupCustomRolesTestEnv(t)
+		role, err := store.CreateRole(ctx, accesscontrol.CreateRoleCommand{Global: true, Name: "custom:dashboards:production", Permissions: []accesscontrol.Permission{dashboardsWrite}})
+		require.NoError(t, err)
+		require.NoError(t, store.AddBuiltInRoleAssignment(ctx, accesscontrol.GlobalOrgID, string(models.ROLE_EDITOR), role.UID))
+
+		permissions, err := ac.GetUserPermissions(ctx, &models.SignedInUser{UserId: 2, OrgId: 3, OrgRole: models.ROLE_EDITOR})
+		require.NoError(t, err)
+		assert.Contains(t, extractRawPermissionsHelper(permissions), &dashboardsWrite)
+
+		permissions, err = ac.GetUserPermissions(ctx, &models.SignedInUser{UserId: 2, OrgId: 3, OrgRole: models.ROLE_VIEWER})
+		require.NoError(t, err)
+		assert.NotContains(t, extractRawPermissionsHelper(permissions), &dashboardsWrite)
+
+		roles, err := ac.GetUserRoles(ctx, &models.SignedInUser{UserId: 2, OrgId: 3, OrgRole: models.ROLE_EDITOR})
+		require.NoError(t, err)
+		names := make([]string, 0, len(roles))
+		for _, r := range roles {
+			names = append(names, r.Name)
+		}
+		assert.ElementsMatch(t, []string{"fixed:datasources:editor:read", "custom:dashboards:production"}, names)
+	})
+
+	t.Run("should cache the permissions until the cache is invalidated", func(t *testing.T) {
+		ac, store, _ := setupCustomRolesTestEnv(t)
+		role, err := store.CreateRole(ctx, accesscontrol.CreateRoleCommand{OrgID: 1, Name: "custom:dashboards:production", Permissions: []accesscontrol.Permission{dashboardsWrite}})
+		require.NoError(t, err)
+		require.NoError(t, store.AddUserRole(ctx, 1, 2, role.UID))
+
+		signedInUser := &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		permissions, err := ac.GetUserPermissions(ctx, signedInUser)
+		require.NoError(t, err)
+		assert.Contains(t, extractRawPermissionsHelper(permissions), &dashboardsWrite)
+
+		require.NoError(t, store.RemoveUserRole(ctx, 1, 2, role.UID))
+		permissions, err = ac.GetUserPermissions(ctx, signedInUser)
+		require.NoError(t, err)
+		assert.Contains(t, extractRawPermissionsHelper(permissions), &dashboardsWrite)
+
+		ac.invalidatePermissionsCache()
+		permissions, err = ac.GetUserPermissions(ctx, signedInUser)
+		require.NoError(t, err)
+		assert.NotContains(t, extractRawPermissionsHelper(permissions), &dashboardsWrite)
+	})
+}
+
+func TestOSSAccessControlService_ServiceAccounts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should clone a user to a service account with its roles", func(t *testing.T) {
+		ac, store, sqlStore := setupCustomRolesTestEnv(t)
+		user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "editor", OrgId: 1})
+		require.NoError(t, err)
+		role, err := store.CreateRole(ctx, accesscontrol.CreateRoleCommand{OrgID: 1, Name: "custom:users:reader", Permissions: []accesscontrol.Permission{{Action: "users:read", Scope: "global:users:*"}}})
+		require.NoError(t, err)
+		require.NoError(t, store.AddUserRole(ctx, 1, user.Id, role.UID))
+
+		serviceAccount, err := ac.CloneUserToServiceAccount(ctx, &models.SignedInUser{UserId: user.Id, OrgId: 1, Login: "editor", OrgRole: models.ROLE_EDITOR})
+		require.NoError(t, err)
+		assert.True(t, serviceAccount.IsServiceAccount)
+		assert.True(t, strings.HasPrefix(serviceAccount.Name, "editor-"))
+
+		query := models.GetSignedInUserQuery{UserId: serviceAccount.Id, OrgId: 1}
+		require.NoError(t, sqlstore.GetSignedInUser(ctx, &query))
+		assert.True(t, query.Result.IsServiceAccount)
+		assert.Equal(t, models.ROLE_EDITOR, query.Result.OrgRole)
+
+		roles, err := store.GetUserAssignedRoles(ctx, 1, serviceAccount.Id)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, role.UID, roles[0].UID)
+
+		apiKey := &models.AddApiKeyCommand{Name: "key", Role: models.ROLE_EDITOR, OrgId: 1, Key: "key"}
+		require.NoError(t, sqlstore.AddAPIKey(ctx, apiKey))
+		require.NoError(t, ac.LinkAPIKeyToServiceAccount(ctx, apiKey.Result, serviceAccount))
+		assert.Equal(t, serviceAccount.Id, apiKey.Result.ServiceAccountId)
+	})
+
+	t.Run("should fail without service accounts store", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		ac := ProvideService(cfg, &usagestats.UsageStatsMock{T: t}, nil, routing.NewRouteRegister(), nil)
+		_, err := ac.CloneUserToServiceAccount(ctx, &models.SignedInUser{UserId: 1, OrgId: 1})
+		assert.Error(t, err)
+	})
+}
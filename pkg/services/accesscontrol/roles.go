@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 type RoleRegistry interface {
@@ -170,6 +171,72 @@ var (
 			},
 		}),
 	}
+
+	permissionsAdminReadRole = RoleDTO{
+		Name:    permissionsAdminRead,
+		Version: 1,
+		Permissions: []Permission{
+			{
+				Action: ActionRolesList,
+				Scope:  ScopeRolesAll,
+			},
+			{
+				Action: ActionRolesRead,
+				Scope:  ScopeRolesAll,
+			},
+			{
+				Action: ActionBuiltinRolesList,
+				Scope:  ScopeRolesAll,
+			},
+			{
+				Action: ActionUsersRolesList,
+				Scope:  ScopeUsersAll,
+			},
+			{
+				Action: ActionTeamsRolesList,
+				Scope:  ScopeTeamsAll,
+			},
+		},
+	}
+
+	permissionsAdminEditRole = RoleDTO{
+		Name:    permissionsAdminEdit,
+		Version: 1,
+		Permissions: ConcatPermissions(permissionsAdminReadRole.Permissions, []Permission{
+			{
+				Action: ActionRolesWrite,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionRolesDelete,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionBuiltinRolesAdd,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionBuiltinRolesRemove,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionUsersRolesAdd,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionUsersRolesRemove,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionTeamsRolesAdd,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionTeamsRolesRemove,
+				Scope:  ScopePermissionsDelegate,
+			},
+		}),
+	}
 )
 
 // Role names definitions
@@ -188,6 +255,9 @@ const (
 
 	ldapAdminEdit = "fixed:ldap:admin:edit"
 	ldapAdminRead = "fixed:ldap:admin:read"
+
+	permissionsAdminEdit = "fixed:permissions:admin:edit"
+	permissionsAdminRead = "fixed:permissions:admin:read"
 )
 
 var (
@@ -207,6 +277,8 @@ var (
 		ldapAdminRead:         ldapAdminReadRole,
 		serverAdminRead:       serverAdminReadRole,
 		settingsAdminRead:     settingsAdminReadRole,
+		permissionsAdminEdit:  permissionsAdminEditRole,
+		permissionsAdminRead:  permissionsAdminReadRole,
 	}
 
 	// FixedRoleGrants specifies which built-in roles are assigned
@@ -215,6 +287,8 @@ var (
 		RoleGrafanaAdmin: {
 			ldapAdminEdit,
 			ldapAdminRead,
+			permissionsAdminEdit,
+			permissionsAdminRead,
 			serverAdminRead,
 			settingsAdminRead,
 			usersAdminEdit,
@@ -253,6 +327,28 @@ func ValidateFixedRole(role RoleDTO) error {
 	return nil
 }
 
+// ValidateCustomRole errors when a custom role can't be stored
+func ValidateCustomRole(uid, name string, permissions []Permission) error {
+	if name == "" {
+		return ErrRoleNameMissing
+	}
+	if strings.HasPrefix(name, FixedRolePrefix) {
+		return ErrFixedRolePrefix
+	}
+	if uid != "" && (!util.IsValidShortUID(uid) || util.IsShortUIDTooLong(uid)) {
+		return ErrRoleUIDInvalid
+	}
+	for _, p := range permissions {
+		if p.Action == "" {
+			return fmt.Errorf("%w: action is missing", ErrInvalidPermission)
+		}
+		if p.Scope != "" && !ValidateScope(p.Scope) {
+			return fmt.Errorf("%w: scope '%s' should not contain meta-characters like * or ?, except in the last position", ErrInvalidPermission, p.Scope)
+		}
+	}
+	return nil
+}
+
 // ValidateBuiltInRoles errors when a built-in role does not match expected pattern
 func ValidateBuiltInRoles(builtInRoles []string) error {
 	for _, br := range builtInRoles {
@@ -15,25 +15,34 @@ import (
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
-	shortURLService shorturls.Service) *CleanUpService {
+	shortURLService shorturls.Service, alertNG *ngalert.AlertNG) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:               cfg,
 		ServerLockService: serverLockService,
 		ShortURLService:   shortURLService,
 		log:               log.New("cleanup"),
 	}
+	// the store of the unified alerting is only set up if it is enabled
+	if alertNG != nil && !alertNG.IsDisabled() && alertNG.Store != nil {
+		s.AlertStateHistoryStore = alertNG.Store
+		s.AlertConfigHistoryStore = alertNG.Store
+	}
 	return s
 }
 
 type CleanUpService struct {
-	log               log.Logger
-	Cfg               *setting.Cfg
-	ServerLockService *serverlock.ServerLockService
-	ShortURLService   shorturls.Service
+	log                     log.Logger
+	Cfg                     *setting.Cfg
+	ServerLockService       *serverlock.ServerLockService
+	ShortURLService         shorturls.Service
+	AlertStateHistoryStore  ngstore.StateHistoryStore
+	AlertConfigHistoryStore ngstore.AlertmanagerConfigHistoryStore
 }
 
 func (srv *CleanUpService) Run(ctx context.Context) error {
@@ -52,6 +61,8 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites()
 			srv.deleteStaleShortURLs()
+			srv.deleteOldAlertStateHistory(ctxWithTimeout)
+			srv.deleteOldAlertmanagerConfigurations(ctxWithTimeout)
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func(context.Context) {
 					srv.deleteOldLoginAttempts()
@@ -171,6 +182,34 @@ func (srv *CleanUpService) expireOldUserInvites() {
 	}
 }
 
+func (srv *CleanUpService) deleteOldAlertStateHistory(ctx context.Context) {
+	maxAge := srv.Cfg.UnifiedAlerting.StateHistory.MaxAge
+	if maxAge <= 0 || srv.AlertStateHistoryStore == nil {
+		return
+	}
+
+	affected, err := srv.AlertStateHistoryStore.DeleteAlertStateHistoryOlderThan(ctx, time.Now().Add(-maxAge))
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		srv.log.Error("Problem deleting old alert state history", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted old alert state history", "rows affected", affected)
+	}
+}
+
+func (srv *CleanUpService) deleteOldAlertmanagerConfigurations(ctx context.Context) {
+	maxVersions := srv.Cfg.UnifiedAlerting.ConfigHistory.MaxVersions
+	if maxVersions <= 0 || srv.AlertConfigHistoryStore == nil {
+		return
+	}
+
+	affected, err := srv.AlertConfigHistoryStore.DeleteOldAlertmanagerConfigurations(ctx, maxVersions)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		srv.log.Error("Problem deleting old alertmanager configurations", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted old alertmanager configurations", "rows affected", affected)
+	}
+}
+
 func (srv *CleanUpService) deleteStaleShortURLs() {
 	cmd := models.DeleteShortUrlCommand{
 		OlderThan: time.Now().Add(-time.Hour * 24 * 7),
@@ -1,9 +1,12 @@
 package cleanup
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
 )
@@ -35,3 +38,81 @@ func TestCleanUpTmpFiles(t *testing.T) {
 		require.False(t, service.shouldCleanupTempFile(weekAgo, now))
 	})
 }
+
+type fakeAlertStateHistoryStore struct {
+	ngstore.StateHistoryStore
+	olderThan time.Time
+}
+
+func (f *fakeAlertStateHistoryStore) DeleteAlertStateHistoryOlderThan(_ context.Context, olderThan time.Time) (int64, error) {
+	f.olderThan = olderThan
+	return 1, nil
+}
+
+func TestDeleteOldAlertStateHistory(t *testing.T) {
+	cfg := setting.NewCfg()
+	historyStore := &fakeAlertStateHistoryStore{}
+	service := CleanUpService{
+		Cfg:                    cfg,
+		AlertStateHistoryStore: historyStore,
+		log:                    log.New("cleanup"),
+	}
+
+	t.Run("Should delete the transitions older than the max age", func(t *testing.T) {
+		cfg.UnifiedAlerting.StateHistory.MaxAge = 24 * time.Hour
+		service.deleteOldAlertStateHistory(context.Background())
+		require.WithinDuration(t, time.Now().Add(-24*time.Hour), historyStore.olderThan, time.Minute)
+	})
+
+	t.Run("If max age is 0, transitions should never be deleted", func(t *testing.T) {
+		historyStore.olderThan = time.Time{}
+		cfg.UnifiedAlerting.StateHistory.MaxAge = 0
+		service.deleteOldAlertStateHistory(context.Background())
+		require.True(t, historyStore.olderThan.IsZero())
+	})
+
+	t.Run("If unified alerting is disabled, transitions should not be deleted", func(t *testing.T) {
+		cfg.UnifiedAlerting.StateHistory.MaxAge = 24 * time.Hour
+		disabled := CleanUpService{Cfg: cfg, log: log.New("cleanup")}
+		require.NotPanics(t, func() { disabled.deleteOldAlertStateHistory(context.Background()) })
+	})
+}
+
+type fakeAlertConfigHistoryStore struct {
+	ngstore.AlertmanagerConfigHistoryStore
+	keep int
+}
+
+func (f *fakeAlertConfigHistoryStore) DeleteOldAlertmanagerConfigurations(_ context.Context, keep int) (int64, error) {
+	f.keep = keep
+	return 1, nil
+}
+
+func TestDeleteOldAlertmanagerConfigurations(t *testing.T) {
+	cfg := setting.NewCfg()
+	historyStore := &fakeAlertConfigHistoryStore{}
+	service := CleanUpService{
+		Cfg:                     cfg,
+		AlertConfigHistoryStore: historyStore,
+		log:                     log.New("cleanup"),
+	}
+
+	t.Run("Should keep the configured number of configurations", func(t *testing.T) {
+		cfg.UnifiedAlerting.ConfigHistory.MaxVersions = 10
+		service.deleteOldAlertmanagerConfigurations(context.Background())
+		require.Equal(t, 10, historyStore.keep)
+	})
+
+	t.Run("If max versions is 0, configurations should never be deleted", func(t *testing.T) {
+		historyStore.keep = 0
+		cfg.UnifiedAlerting.ConfigHistory.MaxVersions = 0
+		service.deleteOldAlertmanagerConfigurations(context.Background())
+		require.Equal(t, 0, historyStore.keep)
+	})
+
+	t.Run("If unified alerting is disabled, configurations should not be deleted", func(t *testing.T) {
+		cfg.UnifiedAlerting.ConfigHistory.MaxVersions = 10
+		disabled := CleanUpService{Cfg: cfg, log: log.New("cleanup")}
+		require.NotPanics(t, func() { disabled.deleteOldAlertmanagerConfigurations(context.Background()) })
+	})
+}
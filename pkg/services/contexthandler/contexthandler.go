@@ -121,6 +121,9 @@ func (h *ContextHandler) Middleware(mContext *web.Context) {
 	}
 
 	reqContext.Logger = log.New("context", "userId", reqContext.UserId, "orgId", reqContext.OrgId, "uname", reqContext.Login)
+	if reqContext.ApiKeyId != 0 {
+		reqContext.Logger = reqContext.Logger.New("apiKeyId", reqContext.ApiKeyId)
+	}
 
 	span.LogFields(
 		ol.String("uname", reqContext.Login),
@@ -243,8 +246,20 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 		return true
 	}
 
+	if !query.Result.IsServiceAccount {
+		reqContext.JsonApiErr(401, InvalidAPIKey, nil)
+		return true
+	}
+	if query.Result.IsDisabled {
+		reqContext.JsonApiErr(401, "Service account disabled", nil)
+		return true
+	}
+
+	// the signed in user is cached, the id of the API key identifies the token used in the logs
+	user := *query.Result
+	user.ApiKeyId = apikey.Id
 	reqContext.IsSignedIn = true
-	reqContext.SignedInUser = query.Result
+	reqContext.SignedInUser = &user
 	return true
 }
 
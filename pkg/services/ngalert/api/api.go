@@ -10,6 +10,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
@@ -31,9 +33,10 @@ type Scheduler interface {
 
 type Alertmanager interface {
 	// Configuration
-	SaveAndApplyConfig(config *apimodels.PostableUserConfig) error
+	SaveAndApplyConfig(config *apimodels.PostableUserConfig, userID int64) error
 	SaveAndApplyDefaultConfig() error
 	GetStatus() apimodels.GettableStatus
+	GetReceiversStatus() apimodels.GettableReceiversStatus
 
 	// Silences
 	CreateSilence(ps *apimodels.PostableSilence) (string, error)
@@ -45,8 +48,14 @@ type Alertmanager interface {
 	GetAlerts(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.GettableAlerts, error)
 	GetAlertGroups(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.AlertGroups, error)
 
+	// Acknowledgements
+	AcknowledgeAlert(fingerprint, acknowledgedBy, comment string) (apimodels.GettableAcknowledgement, error)
+	DeleteAcknowledgement(fingerprint string) error
+	ListAcknowledgements() apimodels.GettableAcknowledgements
+
 	// Testing
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigParams) (*notifier.TestReceiversResult, error)
+	TestTemplates(ctx context.Context, c apimodels.TestTemplatesConfigParams) (*notifier.TestTemplatesResults, error)
 }
 
 // API handlers.
@@ -58,8 +67,13 @@ type API struct {
 	QuotaService         *quota.QuotaService
 	Schedule             schedule.ScheduleService
 	RuleStore            store.RuleStore
+	RuleVersionStore     store.RuleVersionStore
+	StateHistoryStore    store.StateHistoryStore
 	InstanceStore        store.InstanceStore
 	AlertingStore        store.AlertingStore
+	ConfigHistoryStore   store.AlertmanagerConfigHistoryStore
+	SilenceStore         store.SilenceStore
+	ProvisioningStore    store.ProvisioningStore
 	AdminConfigStore     store.AdminConfigurationStore
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
@@ -74,29 +88,55 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		DataProxy: api.DataProxy,
 	}
 
+	amSrv := AlertmanagerSrv{store: api.AlertingStore, historyStore: api.ConfigHistoryStore, silenceStore: api.SilenceStore, provenanceStore: api.ProvisioningStore, mam: api.MultiOrgAlertmanager, secrets: api.SecretsService, log: logger}
 	// Register endpoints for proxying to Alertmanager-compatible backends.
 	api.RegisterAlertmanagerApiEndpoints(NewForkedAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		AlertmanagerSrv{store: api.AlertingStore, mam: api.MultiOrgAlertmanager, secrets: api.SecretsService, log: logger},
+		amSrv,
 	), m)
+	api.RegisterAlertmanagerConfigHistoryApiEndpoints(amSrv, m)
+	api.RegisterAlertmanagerAcknowledgementApiEndpoints(amSrv, m)
+	api.RegisterAlertmanagerReceiversApiEndpoints(amSrv, m)
+	api.RegisterAlertmanagerSilencesApiEndpoints(amSrv, m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkedProm(
 		api.DatasourceCache,
 		NewLotexProm(proxy, logger),
 		PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore},
 	), m)
+	rulerSrv := RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, provenanceStore: api.ProvisioningStore, log: logger}
 	// Register endpoints for proxying to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkedRuler(
 		api.DatasourceCache,
 		NewLotexRuler(proxy, logger),
-		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, log: logger},
+		rulerSrv,
 	), m)
+	api.RegisterRuleExportApiEndpoints(RuleExportSrv{
+		store:           api.RuleStore,
+		DatasourceCache: api.DatasourceCache,
+		ruler:           rulerSrv,
+		log:             logger,
+	}, m)
+	api.RegisterRuleHistoryApiEndpoints(RuleHistorySrv{
+		DatasourceCache: api.DatasourceCache,
+		store:           api.RuleStore,
+		versionStore:    api.RuleVersionStore,
+		provenanceStore: api.ProvisioningStore,
+		manager:         api.StateManager,
+		log:             logger,
+	}, m)
+	api.RegisterStateHistoryApiEndpoints(StateHistorySrv{
+		store:        api.RuleStore,
+		historyStore: api.StateHistoryStore,
+		log:          logger,
+	}, m)
 	api.RegisterTestingApiEndpoints(TestingApiSrv{
 		AlertingProxy:   proxy,
 		Cfg:             api.Cfg,
 		DataService:     api.DataService,
 		DatasourceCache: api.DatasourceCache,
+		Backtesting:     backtesting.NewEngine(eval.Evaluator{Cfg: api.Cfg, Log: logger}, api.DataService, api.Cfg.UnifiedAlerting.Backtesting.MaxEvaluations, logger),
 		log:             logger,
 	}, m)
 	api.RegisterConfigurationApiEndpoints(AdminSrv{
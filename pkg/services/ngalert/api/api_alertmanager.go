@@ -28,10 +28,13 @@ const (
 )
 
 type AlertmanagerSrv struct {
-	mam     *notifier.MultiOrgAlertmanager
-	secrets secrets.Service
-	store   store.AlertingStore
-	log     log.Logger
+	mam             *notifier.MultiOrgAlertmanager
+	secrets         secrets.Service
+	store           store.AlertingStore
+	historyStore    store.AlertmanagerConfigHistoryStore
+	silenceStore    store.SilenceStore
+	provenanceStore store.ProvisioningStore
+	log             log.Logger
 }
 
 type UnknownReceiverError struct {
@@ -124,6 +127,19 @@ func (srv AlertmanagerSrv) RouteGetAMStatus(c *models.ReqContext) response.Respo
 	return response.JSON(http.StatusOK, am.GetStatus())
 }
 
+func (srv AlertmanagerSrv) RouteGetReceivers(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	return response.JSON(http.StatusOK, am.GetReceiversStatus())
+}
+
 func (srv AlertmanagerSrv) RouteCreateSilence(c *models.ReqContext, postableSilence apimodels.PostableSilence) response.Response {
 	if !c.HasUserRole(models.ROLE_EDITOR) {
 		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
@@ -200,9 +216,26 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 		return ErrResp(http.StatusInternalServerError, err, "failed to get latest configuration")
 	}
 
-	cfg, err := notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+	result, errResp := srv.toGettableUserConfig(query.Result.AlertmanagerConfiguration)
+	if errResp != nil {
+		return errResp
+	}
+
+	provenances, err := srv.getAlertmanagerProvenances(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenances")
+	}
+	provenances.setProvenances(&result)
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// toGettableUserConfig converts a stored Alertmanager configuration to its API representation,
+// in which the secure settings of the receivers are replaced by the names of the fields that are set.
+func (srv AlertmanagerSrv) toGettableUserConfig(rawConfig string) (apimodels.GettableUserConfig, response.Response) {
+	cfg, err := notifier.Load([]byte(rawConfig))
 	if err != nil {
-		return ErrResp(http.StatusInternalServerError, err, "failed to unmarshal alertmanager configuration")
+		return apimodels.GettableUserConfig{}, ErrResp(http.StatusInternalServerError, err, "failed to unmarshal alertmanager configuration")
 	}
 
 	result := apimodels.GettableUserConfig{
@@ -218,7 +251,7 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 			for k := range pr.SecureSettings {
 				decryptedValue, err := srv.getDecryptedSecret(pr, k)
 				if err != nil {
-					return ErrResp(http.StatusInternalServerError, err, "failed to decrypt stored secure setting: %s", k)
+					return apimodels.GettableUserConfig{}, ErrResp(http.StatusInternalServerError, err, "failed to decrypt stored secure setting: %s", k)
 				}
 				if decryptedValue == "" {
 					continue
@@ -244,7 +277,7 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 		result.AlertmanagerConfig.Receivers = append(result.AlertmanagerConfig.Receivers, &gettableApiReceiver)
 	}
 
-	return response.JSON(http.StatusOK, result)
+	return result, nil
 }
 
 func (srv AlertmanagerSrv) RouteGetAMAlertGroups(c *models.ReqContext) response.Response {
@@ -355,6 +388,13 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 
+	if err := srv.checkProvisionedResources(c.Req.Context(), c.OrgId, &body, false); err != nil {
+		if errors.Is(err, ngmodels.ErrProvisioned) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
 	if err := body.ProcessConfig(srv.secrets.Encrypt); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to post process Alertmanager configuration")
 	}
@@ -364,7 +404,7 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 		return errResp
 	}
 
-	if err := am.SaveAndApplyConfig(&body); err != nil {
+	if err := am.SaveAndApplyConfig(&body, c.SignedInUser.UserId); err != nil {
 		srv.log.Error("unable to save and apply alertmanager configuration", "err", err)
 		return ErrResp(http.StatusBadRequest, err, "failed to save and apply Alertmanager configuration")
 	}
@@ -441,6 +481,46 @@ func contextWithTimeoutFromRequest(ctx context.Context, r *http.Request, default
 	return ctx, cancelFunc, nil
 }
 
+func (srv AlertmanagerSrv) RoutePostTestTemplates(c *models.ReqContext, body apimodels.TestTemplatesConfigParams) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := am.TestTemplates(c.Req.Context(), body)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoTemplates) || errors.Is(err, notifier.ErrInvalidTemplateFileName) || errors.Is(err, notifier.ErrInvalidTestAlert) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to test templates")
+	}
+
+	return response.JSON(http.StatusOK, newTestTemplatesResults(result))
+}
+
+func newTestTemplatesResults(r *notifier.TestTemplatesResults) apimodels.TestTemplatesResults {
+	v := apimodels.TestTemplatesResults{
+		Results: make([]apimodels.TestTemplatesResult, 0, len(r.Results)),
+		Errors:  make([]apimodels.TestTemplatesErrorResult, 0, len(r.Errors)),
+	}
+	for _, next := range r.Results {
+		v.Results = append(v.Results, apimodels.TestTemplatesResult{Name: next.Name, Text: next.Text})
+	}
+	for _, next := range r.Errors {
+		v.Errors = append(v.Errors, apimodels.TestTemplatesErrorResult{
+			Name:    next.Name,
+			Kind:    string(next.Kind),
+			Line:    next.Line,
+			Message: next.Error.Error(),
+		})
+	}
+	return v
+}
+
 func newTestReceiversResult(r *notifier.TestReceiversResult) apimodels.TestReceiversResult {
 	v := apimodels.TestReceiversResult{
 		Alert: apimodels.TestReceiversConfigAlertParams{
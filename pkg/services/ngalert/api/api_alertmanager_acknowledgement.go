@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (srv AlertmanagerSrv) RouteGetAcknowledgements(c *models.ReqContext) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, am.ListAcknowledgements())
+}
+
+func (srv AlertmanagerSrv) RoutePostAcknowledgement(c *models.ReqContext, body apimodels.PostableAcknowledgement) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	ack, err := am.AcknowledgeAlert(web.Params(c.Req)[":Fingerprint"], c.SignedInUser.Login, body.Comment)
+	if err != nil {
+		return acknowledgementErrResp(err)
+	}
+	return response.JSON(http.StatusCreated, ack)
+}
+
+func (srv AlertmanagerSrv) RouteDeleteAcknowledgement(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := am.DeleteAcknowledgement(web.Params(c.Req)[":Fingerprint"]); err != nil {
+		return acknowledgementErrResp(err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "acknowledgement deleted"})
+}
+
+func acknowledgementErrResp(err error) response.Response {
+	switch {
+	case errors.Is(err, notifier.ErrAlertNotFound), errors.Is(err, notifier.ErrAcknowledgementNotFound):
+		return ErrResp(http.StatusNotFound, err, "")
+	case errors.Is(err, notifier.ErrInvalidAlertFingerprint), errors.Is(err, notifier.ErrAlertNotFiring):
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "")
+}
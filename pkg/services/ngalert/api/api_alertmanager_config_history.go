@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (srv AlertmanagerSrv) RouteGetAlertingConfigHistory(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	q := ngmodels.ListAlertmanagerConfigurationHistoryQuery{
+		OrgID: c.OrgId,
+		Limit: c.QueryInt("limit"),
+	}
+	if err := srv.historyStore.GetAlertmanagerConfigurationHistory(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the configuration history")
+	}
+
+	result := make(apimodels.GettableHistoricUserConfigs, 0, len(q.Result))
+	for _, cfg := range q.Result {
+		result = append(result, toGettableHistoricUserConfig(cfg))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteGetAlertingConfigVersion(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	id, err := strconv.ParseInt(web.Params(c.Req)[":ConfigID"], 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid configuration ID")
+	}
+	historic, errResp := srv.getHistoricConfig(c, id)
+	if errResp != nil {
+		return errResp
+	}
+
+	cfg, errResp := srv.toGettableUserConfig(historic.AlertmanagerConfiguration)
+	if errResp != nil {
+		return errResp
+	}
+
+	result := toGettableHistoricUserConfig(historic)
+	result.TemplateFiles = cfg.TemplateFiles
+	result.AlertmanagerConfig = &cfg.AlertmanagerConfig
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteGetAlertingConfigDiff(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	baseID := c.QueryInt64("base")
+	newID := c.QueryInt64("new")
+	if baseID <= 0 || newID <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("the base and new query parameters must be valid configuration IDs"), "")
+	}
+
+	diffType := dashdiffs.DiffDelta
+	if t := c.Query("diffType"); t != "" {
+		diffType = dashdiffs.ParseDiffType(t)
+	}
+
+	baseJSON, errResp := srv.historicConfigJSON(c, baseID)
+	if errResp != nil {
+		return errResp
+	}
+	newJSON, errResp := srv.historicConfigJSON(c, newID)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := dashdiffs.CalculateJSONDiff(baseJSON, newJSON, diffType)
+	if err != nil {
+		if errors.Is(err, dashdiffs.ErrNilDiff) {
+			return ErrResp(http.StatusBadRequest, errors.New("the configurations do not differ"), "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to compute diff")
+	}
+
+	if diffType == dashdiffs.DiffDelta {
+		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
+func (srv AlertmanagerSrv) RoutePostRollbackAlertingConfig(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	id, err := strconv.ParseInt(web.Params(c.Req)[":ConfigID"], 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid configuration ID")
+	}
+	historic, errResp := srv.getHistoricConfig(c, id)
+	if errResp != nil {
+		return errResp
+	}
+
+	// the secure settings of the stored configuration are already encrypted
+	cfg, err := notifier.Load([]byte(historic.AlertmanagerConfiguration))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to unmarshal alertmanager configuration")
+	}
+
+	if err := srv.checkProvisionedResources(c.Req.Context(), c.OrgId, cfg, true); err != nil {
+		if errors.Is(err, ngmodels.ErrProvisioned) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := am.SaveAndApplyConfig(cfg, c.SignedInUser.UserId); err != nil {
+		srv.log.Error("unable to roll back the alertmanager configuration", "id", id, "err", err)
+		return ErrResp(http.StatusBadRequest, err, "failed to save and apply Alertmanager configuration")
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": fmt.Sprintf("configuration rolled back to version %d", id)})
+}
+
+// getHistoricConfig returns a saved Alertmanager configuration of the organization of the request.
+func (srv AlertmanagerSrv) getHistoricConfig(c *models.ReqContext, id int64) (*ngmodels.AlertConfigurationWithCreator, response.Response) {
+	q := ngmodels.GetAlertmanagerConfigurationQuery{OrgID: c.OrgId, ID: id}
+	if err := srv.historyStore.GetAlertmanagerConfiguration(c.Req.Context(), &q); err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return nil, ErrResp(http.StatusNotFound, err, "configuration %d", id)
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get configuration")
+	}
+	return q.Result, nil
+}
+
+// historicConfigJSON returns a saved Alertmanager configuration to compute diffs with.
+// The secure settings are left out, so the diff only shows which of them are set.
+func (srv AlertmanagerSrv) historicConfigJSON(c *models.ReqContext, id int64) (*simplejson.Json, response.Response) {
+	historic, errResp := srv.getHistoricConfig(c, id)
+	if errResp != nil {
+		return nil, errResp
+	}
+	cfg, errResp := srv.toGettableUserConfig(historic.AlertmanagerConfiguration)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	// the configuration is marshalled as a value so that it is encoded like the responses of the configuration API
+	j, err := json.Marshal(map[string]interface{}{
+		"template_files":      cfg.TemplateFiles,
+		"alertmanager_config": cfg.AlertmanagerConfig,
+	})
+	if err != nil {
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to encode configuration")
+	}
+	data, err := simplejson.NewJson(j)
+	if err != nil {
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to encode configuration")
+	}
+	return data, nil
+}
+
+func toGettableHistoricUserConfig(cfg *ngmodels.AlertConfigurationWithCreator) apimodels.GettableHistoricUserConfig {
+	return apimodels.GettableHistoricUserConfig{
+		ID:        cfg.ID,
+		CreatedAt: time.Unix(cfg.CreatedAt, 0).UTC(),
+		CreatedBy: cfg.CreatedByLogin,
+		Default:   cfg.Default,
+	}
+}
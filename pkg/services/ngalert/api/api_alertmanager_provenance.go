@@ -0,0 +1,212 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// alertmanagerProvenances are the provenances of the provisioned resources of the Alertmanager configuration
+// of an organization.
+type alertmanagerProvenances struct {
+	contactPoints map[string]ngmodels.Provenance
+	templates     map[string]ngmodels.Provenance
+	muteTimings   map[string]ngmodels.Provenance
+	policies      ngmodels.Provenance
+}
+
+func (srv AlertmanagerSrv) getAlertmanagerProvenances(ctx context.Context, orgID int64) (alertmanagerProvenances, error) {
+	var (
+		result alertmanagerProvenances
+		err    error
+	)
+	if result.contactPoints, err = srv.provenanceStore.GetProvenances(ctx, orgID, ngmodels.ResourceTypeContactPoint); err != nil {
+		return result, err
+	}
+	if result.templates, err = srv.provenanceStore.GetProvenances(ctx, orgID, ngmodels.ResourceTypeTemplate); err != nil {
+		return result, err
+	}
+	if result.muteTimings, err = srv.provenanceStore.GetProvenances(ctx, orgID, ngmodels.ResourceTypeMuteTiming); err != nil {
+		return result, err
+	}
+	policies, err := srv.provenanceStore.GetProvenances(ctx, orgID, ngmodels.ResourceTypeNotificationPolicy)
+	if err != nil {
+		return result, err
+	}
+	result.policies = policies[ngmodels.NotificationPolicyKey]
+	return result, nil
+}
+
+// setProvenances sets the provenances of the provisioned resources of the configuration.
+func (p alertmanagerProvenances) setProvenances(cfg *apimodels.GettableUserConfig) {
+	for _, recv := range cfg.AlertmanagerConfig.Receivers {
+		for _, gr := range recv.GrafanaManagedReceivers {
+			gr.Provenance = p.contactPoints[recv.Name]
+		}
+	}
+	cfg.AlertmanagerConfig.RouteProvenance = p.policies
+	for name, provenance := range p.templates {
+		if _, ok := cfg.TemplateFiles[name]; ok {
+			if cfg.TemplateFileProvenances == nil {
+				cfg.TemplateFileProvenances = make(map[string]ngmodels.Provenance)
+			}
+			cfg.TemplateFileProvenances[name] = provenance
+		}
+	}
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		if provenance, ok := p.muteTimings[mt.Name]; ok {
+			if cfg.AlertmanagerConfig.MuteTimeProvenances == nil {
+				cfg.AlertmanagerConfig.MuteTimeProvenances = make(map[string]ngmodels.Provenance)
+			}
+			cfg.AlertmanagerConfig.MuteTimeProvenances[mt.Name] = provenance
+		}
+	}
+}
+
+// checkProvisionedResources returns an error wrapping ngmodels.ErrProvisioned if the configuration changes or
+// removes a provisioned resource of the current configuration of the organization. encrypted tells whether the
+// secure settings of the receivers of the configuration are encrypted, as they are in the saved configurations,
+// or not, as they are in the requests.
+func (srv AlertmanagerSrv) checkProvisionedResources(ctx context.Context, orgID int64, cfg *apimodels.PostableUserConfig, encrypted bool) error {
+	provenances, err := srv.getAlertmanagerProvenances(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get provenances: %w", err)
+	}
+	if len(provenances.contactPoints) == 0 && len(provenances.templates) == 0 && len(provenances.muteTimings) == 0 && provenances.policies == ngmodels.ProvenanceNone {
+		return nil
+	}
+
+	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+	if err := srv.store.GetLatestAlertmanagerConfiguration(&query); err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return nil
+		}
+		return fmt.Errorf("failed to get latest configuration: %w", err)
+	}
+	current, err := notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return fmt.Errorf("failed to load latest configuration: %w", err)
+	}
+
+	for name := range provenances.contactPoints {
+		currentReceiver, err := srv.receiverToJSON(findReceiver(current, name), true)
+		if err != nil {
+			return err
+		}
+		newReceiver, err := srv.receiverToJSON(findReceiver(cfg, name), encrypted)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(currentReceiver, newReceiver) {
+			return fmt.Errorf("%w: contact point %s", ngmodels.ErrProvisioned, name)
+		}
+	}
+
+	for name := range provenances.templates {
+		currentTemplate, currentOk := current.TemplateFiles[name]
+		newTemplate, newOk := cfg.TemplateFiles[name]
+		if currentOk != newOk || currentTemplate != newTemplate {
+			return fmt.Errorf("%w: template %s", ngmodels.ErrProvisioned, name)
+		}
+	}
+
+	for name := range provenances.muteTimings {
+		equal, err := jsonEqual(findMuteTimeInterval(current, name), findMuteTimeInterval(cfg, name))
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return fmt.Errorf("%w: mute timing %s", ngmodels.ErrProvisioned, name)
+		}
+	}
+
+	if provenances.policies != ngmodels.ProvenanceNone {
+		equal, err := jsonEqual(current.AlertmanagerConfig.Route, cfg.AlertmanagerConfig.Route)
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return fmt.Errorf("%w: notification policies", ngmodels.ErrProvisioned)
+		}
+	}
+	return nil
+}
+
+// receiverToJSON returns the JSON representation of the Grafana receivers of a contact point, with their
+// secure settings decrypted if they are encrypted.
+func (srv AlertmanagerSrv) receiverToJSON(recv *apimodels.PostableApiReceiver, encrypted bool) ([]byte, error) {
+	if recv == nil {
+		return nil, nil
+	}
+
+	type receiver struct {
+		UID                   string            `json:"uid"`
+		Name                  string            `json:"name"`
+		Type                  string            `json:"type"`
+		DisableResolveMessage bool              `json:"disableResolveMessage"`
+		Settings              interface{}       `json:"settings"`
+		SecureSettings        map[string]string `json:"secureSettings"`
+	}
+	receivers := make([]receiver, 0, len(recv.GrafanaManagedReceivers))
+	for _, gr := range recv.GrafanaManagedReceivers {
+		r := receiver{
+			UID:                   gr.UID,
+			Name:                  gr.Name,
+			Type:                  gr.Type,
+			DisableResolveMessage: gr.DisableResolveMessage,
+			SecureSettings:        make(map[string]string, len(gr.SecureSettings)),
+		}
+		if gr.Settings != nil {
+			r.Settings = gr.Settings.Interface()
+		}
+		for k, v := range gr.SecureSettings {
+			if encrypted {
+				decrypted, err := srv.getDecryptedSecret(gr, k)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decrypt stored secure setting: %s: %w", k, err)
+				}
+				v = decrypted
+			}
+			r.SecureSettings[k] = v
+		}
+		receivers = append(receivers, r)
+	}
+	return json.Marshal(receivers)
+}
+
+func findReceiver(cfg *apimodels.PostableUserConfig, name string) *apimodels.PostableApiReceiver {
+	for _, recv := range cfg.AlertmanagerConfig.Receivers {
+		if recv.Name == name {
+			return recv
+		}
+	}
+	return nil
+}
+
+func findMuteTimeInterval(cfg *apimodels.PostableUserConfig, name string) interface{} {
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		if mt.Name == name {
+			return mt
+		}
+	}
+	return nil
+}
+
+func jsonEqual(a, b interface{}) (bool, error) {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aJSON, bJSON), nil
+}
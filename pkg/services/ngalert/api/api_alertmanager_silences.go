@@ -0,0 +1,423 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (srv AlertmanagerSrv) RouteGetSilenceTemplates(c *models.ReqContext) response.Response {
+	q := ngmodels.ListSilenceTemplatesQuery{OrgID: c.OrgId}
+	if err := srv.silenceStore.ListSilenceTemplates(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get silence templates")
+	}
+
+	result := make(apimodels.GettableSilenceTemplates, 0, len(q.Result))
+	for _, t := range q.Result {
+		result = append(result, toGettableSilenceTemplate(t))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteGetSilenceTemplate(c *models.ReqContext) response.Response {
+	template, errResp := srv.getSilenceTemplate(c, web.Params(c.Req)[":TemplateUID"])
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, toGettableSilenceTemplate(template))
+}
+
+func (srv AlertmanagerSrv) RoutePostSilenceTemplate(c *models.ReqContext, body apimodels.PostableSilenceTemplate) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	template := &ngmodels.SilenceTemplate{OrgID: c.OrgId, CreatedBy: c.SignedInUser.Login}
+	if errResp := srv.saveSilenceTemplate(c, template, body); errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusCreated, toGettableSilenceTemplate(template))
+}
+
+func (srv AlertmanagerSrv) RoutePutSilenceTemplate(c *models.ReqContext, body apimodels.PostableSilenceTemplate) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	template, errResp := srv.getSilenceTemplate(c, web.Params(c.Req)[":TemplateUID"])
+	if errResp != nil {
+		return errResp
+	}
+	if errResp := srv.saveSilenceTemplate(c, template, body); errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, toGettableSilenceTemplate(template))
+}
+
+func (srv AlertmanagerSrv) RouteDeleteSilenceTemplate(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	cmd := ngmodels.DeleteSilenceTemplateCommand{OrgID: c.OrgId, UID: web.Params(c.Req)[":TemplateUID"]}
+	if err := srv.silenceStore.DeleteSilenceTemplate(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, ngmodels.ErrSilenceTemplateNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete silence template")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "silence template deleted"})
+}
+
+func (srv AlertmanagerSrv) RoutePostSilenceFromTemplate(c *models.ReqContext, body apimodels.PostableSilenceFromTemplate) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	template, errResp := srv.getSilenceTemplate(c, web.Params(c.Req)[":TemplateUID"])
+	if errResp != nil {
+		return errResp
+	}
+
+	startsAt := body.StartsAt
+	if startsAt.IsZero() {
+		startsAt = timeNow()
+	}
+	endsAt := body.EndsAt
+	if endsAt.IsZero() {
+		if template.Duration <= 0 {
+			return ErrResp(http.StatusBadRequest, errors.New("the end of the silence is required as the template has no duration"), "")
+		}
+		endsAt = startsAt.Add(template.Duration)
+	}
+	comment := body.Comment
+	if comment == "" {
+		comment = template.Comment
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	silenceID, err := am.CreateSilence(notifier.NewPostableSilence(template.Matchers, comment, c.SignedInUser.Login, startsAt, endsAt))
+	if err != nil {
+		if errors.Is(err, notifier.ErrCreateSilenceBadPayload) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to create silence")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "silence created", "id": silenceID})
+}
+
+func (srv AlertmanagerSrv) RouteGetRecurringSilences(c *models.ReqContext) response.Response {
+	q := ngmodels.ListRecurringSilencesQuery{OrgID: c.OrgId}
+	if err := srv.silenceStore.ListRecurringSilences(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get recurring silences")
+	}
+
+	result := make(apimodels.GettableRecurringSilences, 0, len(q.Result))
+	for _, rs := range q.Result {
+		result = append(result, toGettableRecurringSilence(rs))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteGetRecurringSilence(c *models.ReqContext) response.Response {
+	rs, errResp := srv.getRecurringSilence(c, web.Params(c.Req)[":RecurringSilenceUID"])
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, toGettableRecurringSilence(rs))
+}
+
+func (srv AlertmanagerSrv) RoutePostRecurringSilence(c *models.ReqContext, body apimodels.PostableRecurringSilence) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	rs := &ngmodels.RecurringSilence{OrgID: c.OrgId, CreatedBy: c.SignedInUser.Login}
+	if errResp := srv.saveRecurringSilence(c, rs, body); errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusCreated, toGettableRecurringSilence(rs))
+}
+
+func (srv AlertmanagerSrv) RoutePutRecurringSilence(c *models.ReqContext, body apimodels.PostableRecurringSilence) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	rs, errResp := srv.getRecurringSilence(c, web.Params(c.Req)[":RecurringSilenceUID"])
+	if errResp != nil {
+		return errResp
+	}
+	previous := *rs
+	if errResp := srv.saveRecurringSilence(c, rs, body); errResp != nil {
+		return errResp
+	}
+	if !rs.SameOccurrences(&previous) {
+		// the current or next occurrence is materialized again with the new matchers and schedule
+		if err := srv.silenceStore.ResetRecurringSilenceOccurrence(c.Req.Context(), rs.ID); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to reset the occurrence of the recurring silence")
+		}
+		srv.expireRecurringSilence(c.OrgId, &previous)
+		rs.LastStartsAt, rs.SilenceID = 0, ""
+	}
+	return response.JSON(http.StatusOK, toGettableRecurringSilence(rs))
+}
+
+func (srv AlertmanagerSrv) RouteDeleteRecurringSilence(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	rs, errResp := srv.getRecurringSilence(c, web.Params(c.Req)[":RecurringSilenceUID"])
+	if errResp != nil {
+		return errResp
+	}
+	cmd := ngmodels.DeleteRecurringSilenceCommand{OrgID: c.OrgId, UID: rs.UID}
+	if err := srv.silenceStore.DeleteRecurringSilence(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete recurring silence")
+	}
+	srv.expireRecurringSilence(c.OrgId, rs)
+	return response.JSON(http.StatusOK, util.DynMap{"message": "recurring silence deleted"})
+}
+
+func (srv AlertmanagerSrv) getSilenceTemplate(c *models.ReqContext, uid string) (*ngmodels.SilenceTemplate, response.Response) {
+	q := ngmodels.GetSilenceTemplateQuery{OrgID: c.OrgId, UID: uid}
+	if err := srv.silenceStore.GetSilenceTemplate(c.Req.Context(), &q); err != nil {
+		if errors.Is(err, ngmodels.ErrSilenceTemplateNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get silence template")
+	}
+	return q.Result, nil
+}
+
+func (srv AlertmanagerSrv) saveSilenceTemplate(c *models.ReqContext, template *ngmodels.SilenceTemplate, body apimodels.PostableSilenceTemplate) response.Response {
+	if strings.TrimSpace(body.Name) == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("the name of the silence template is required"), "")
+	}
+	matchers, err := silenceMatchersFromAPI(body.Matchers)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if body.Duration < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("the duration must not be negative"), "")
+	}
+
+	template.Name = body.Name
+	template.Matchers = matchers
+	template.Comment = body.Comment
+	template.Duration = time.Duration(body.Duration)
+	if err := srv.silenceStore.SaveSilenceTemplate(c.Req.Context(), template); err != nil {
+		switch {
+		case errors.Is(err, ngmodels.ErrSilenceTemplateNameExists):
+			return ErrResp(http.StatusConflict, err, "")
+		case errors.Is(err, ngmodels.ErrSilenceTemplateNotFound):
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to save silence template")
+	}
+	return nil
+}
+
+func (srv AlertmanagerSrv) getRecurringSilence(c *models.ReqContext, uid string) (*ngmodels.RecurringSilence, response.Response) {
+	q := ngmodels.GetRecurringSilenceQuery{OrgID: c.OrgId, UID: uid}
+	if err := srv.silenceStore.GetRecurringSilence(c.Req.Context(), &q); err != nil {
+		if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get recurring silence")
+	}
+	return q.Result, nil
+}
+
+// expireRecurringSilence expires the silence the last occurrence of the recurring silence was materialized into,
+// if it's still pending or active.
+func (srv AlertmanagerSrv) expireRecurringSilence(orgID int64, rs *ngmodels.RecurringSilence) {
+	if rs.SilenceID == "" {
+		return
+	}
+	am, err := srv.mam.AlertmanagerFor(orgID)
+	if err != nil {
+		srv.log.Warn("failed to expire the silence of a recurring silence", "uid", rs.UID, "silence", rs.SilenceID, "err", err)
+		return
+	}
+	silence, err := am.GetSilence(rs.SilenceID)
+	if err != nil {
+		if !errors.Is(err, notifier.ErrSilenceNotFound) {
+			srv.log.Warn("failed to expire the silence of a recurring silence", "uid", rs.UID, "silence", rs.SilenceID, "err", err)
+		}
+		return
+	}
+	if silence.Status != nil && silence.Status.State != nil && *silence.Status.State == amv2.SilenceStatusStateExpired {
+		return
+	}
+	if err := am.DeleteSilence(rs.SilenceID); err != nil {
+		srv.log.Warn("failed to expire the silence of a recurring silence", "uid", rs.UID, "silence", rs.SilenceID, "err", err)
+	}
+}
+
+func (srv AlertmanagerSrv) saveRecurringSilence(c *models.ReqContext, rs *ngmodels.RecurringSilence, body apimodels.PostableRecurringSilence) response.Response {
+	comment := body.Comment
+	var matchers []ngmodels.SilenceMatcher
+	if body.TemplateUID != "" {
+		template, errResp := srv.getSilenceTemplate(c, body.TemplateUID)
+		if errResp != nil {
+			return errResp
+		}
+		matchers = template.Matchers
+		if comment == "" {
+			comment = template.Comment
+		}
+	} else {
+		var err error
+		if matchers, err = silenceMatchersFromAPI(body.Matchers); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+	windows, err := weeklyWindowsFromAPI(body.WeeklyWindows)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	rs.Matchers = matchers
+	rs.Comment = comment
+	rs.Cron = body.Cron
+	rs.Duration = time.Duration(body.Duration)
+	rs.WeeklyWindows = windows
+	rs.Timezone = body.Timezone
+	if err := rs.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid recurring silence")
+	}
+
+	if err := srv.silenceStore.SaveRecurringSilence(c.Req.Context(), rs); err != nil {
+		if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to save recurring silence")
+	}
+	return nil
+}
+
+func silenceMatchersFromAPI(matchers amv2.Matchers) ([]ngmodels.SilenceMatcher, error) {
+	if len(matchers) == 0 {
+		return nil, errors.New("at least one matcher is required")
+	}
+	result := make([]ngmodels.SilenceMatcher, 0, len(matchers))
+	for _, m := range matchers {
+		if m == nil || m.Name == nil || m.Value == nil || m.IsRegex == nil {
+			return nil, errors.New("matchers must have a name, a value and isRegex")
+		}
+		sm := ngmodels.SilenceMatcher{Name: *m.Name, Value: *m.Value, IsRegex: *m.IsRegex, IsEqual: true}
+		if m.IsEqual != nil {
+			sm.IsEqual = *m.IsEqual
+		}
+
+		matchType := labels.MatchEqual
+		switch {
+		case sm.IsRegex && sm.IsEqual:
+			matchType = labels.MatchRegexp
+		case sm.IsRegex:
+			matchType = labels.MatchNotRegexp
+		case !sm.IsEqual:
+			matchType = labels.MatchNotEqual
+		}
+		if _, err := labels.NewMatcher(matchType, sm.Name, sm.Value); err != nil {
+			return nil, fmt.Errorf("invalid matcher %s: %w", sm.Name, err)
+		}
+		result = append(result, sm)
+	}
+	return result, nil
+}
+
+func silenceMatchersToAPI(matchers []ngmodels.SilenceMatcher) amv2.Matchers {
+	result := make(amv2.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		m := m
+		result = append(result, &amv2.Matcher{Name: &m.Name, Value: &m.Value, IsRegex: &m.IsRegex, IsEqual: &m.IsEqual})
+	}
+	return result
+}
+
+func weeklyWindowsFromAPI(windows []apimodels.WeeklyWindow) ([]ngmodels.WeeklyWindow, error) {
+	result := make([]ngmodels.WeeklyWindow, 0, len(windows))
+	for _, w := range windows {
+		days := make([]time.Weekday, 0, len(w.Weekdays))
+		for _, name := range w.Weekdays {
+			day, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("invalid weekday %q", name)
+			}
+			days = append(days, day)
+		}
+		result = append(result, ngmodels.WeeklyWindow{Weekdays: days, StartTime: w.StartTime, EndTime: w.EndTime})
+	}
+	return result, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+func toGettableSilenceTemplate(t *ngmodels.SilenceTemplate) apimodels.GettableSilenceTemplate {
+	return apimodels.GettableSilenceTemplate{
+		UID:       t.UID,
+		Name:      t.Name,
+		Matchers:  silenceMatchersToAPI(t.Matchers),
+		Comment:   t.Comment,
+		Duration:  model.Duration(t.Duration),
+		CreatedBy: t.CreatedBy,
+		Updated:   t.Updated,
+	}
+}
+
+func toGettableRecurringSilence(rs *ngmodels.RecurringSilence) apimodels.GettableRecurringSilence {
+	result := apimodels.GettableRecurringSilence{
+		UID:       rs.UID,
+		Matchers:  silenceMatchersToAPI(rs.Matchers),
+		Comment:   rs.Comment,
+		Cron:      rs.Cron,
+		Duration:  model.Duration(rs.Duration),
+		Timezone:  rs.Timezone,
+		CreatedBy: rs.CreatedBy,
+		Updated:   rs.Updated,
+	}
+	for _, w := range rs.WeeklyWindows {
+		days := make([]string, 0, len(w.Weekdays))
+		for _, d := range w.Weekdays {
+			days = append(days, strings.ToLower(d.String()))
+		}
+		result.WeeklyWindows = append(result.WeeklyWindows, apimodels.WeeklyWindow{Weekdays: days, StartTime: w.StartTime, EndTime: w.EndTime})
+	}
+	// the schedule is validated before the recurring silence is saved
+	if start, end, err := rs.NextOccurrence(timeNow()); err == nil {
+		result.NextStartsAt, result.NextEndsAt = start, end
+	}
+	return result
+}
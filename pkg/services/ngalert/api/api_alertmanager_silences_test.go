@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSilenceMatchersFromAPI(t *testing.T) {
+	name, value, isRegex, isEqual := "team", "db", false, false
+	matchers, err := silenceMatchersFromAPI(amv2.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex}})
+	require.NoError(t, err)
+	require.Equal(t, []ngmodels.SilenceMatcher{{Name: "team", Value: "db", IsEqual: true}}, matchers)
+
+	matchers, err = silenceMatchersFromAPI(amv2.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex, IsEqual: &isEqual}})
+	require.NoError(t, err)
+	require.Equal(t, []ngmodels.SilenceMatcher{{Name: "team", Value: "db"}}, matchers)
+	require.Equal(t, amv2.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex, IsEqual: &isEqual}}, silenceMatchersToAPI(matchers))
+
+	_, err = silenceMatchersFromAPI(nil)
+	require.Error(t, err)
+
+	_, err = silenceMatchersFromAPI(amv2.Matchers{{Name: &name, IsRegex: &isRegex}})
+	require.Error(t, err)
+
+	isRegex, value = true, "db("
+	_, err = silenceMatchersFromAPI(amv2.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex}})
+	require.Error(t, err)
+}
+
+func TestWeeklyWindowsFromAPI(t *testing.T) {
+	windows, err := weeklyWindowsFromAPI([]apimodels.WeeklyWindow{{Weekdays: []string{"saturday", "Sunday"}, StartTime: "22:00", EndTime: "02:00"}})
+	require.NoError(t, err)
+	require.Equal(t, []ngmodels.WeeklyWindow{{Weekdays: []time.Weekday{time.Saturday, time.Sunday}, StartTime: "22:00", EndTime: "02:00"}}, windows)
+
+	_, err = weeklyWindowsFromAPI([]apimodels.WeeklyWindow{{Weekdays: []string{"sun"}, StartTime: "22:00", EndTime: "02:00"}})
+	require.Error(t, err)
+}
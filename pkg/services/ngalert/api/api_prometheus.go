@@ -164,6 +164,9 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		if rule.IsRecordingRule() {
+			newRule.Type = apiv1.RuleTypeRecording
+		}
 
 		for _, alertState := range srv.manager.GetStatesForRuleUID(c.OrgId, rule.UID) {
 			activeAt := alertState.StartsAt
@@ -207,6 +210,10 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 			alertingRule.Alerts = append(alertingRule.Alerts, alert)
 		}
 
+		if rule.IsPaused {
+			newRule.Health = "paused"
+		}
+
 		alertingRule.Rule = newRule
 		newGroup.Rules = append(newGroup.Rules, alertingRule)
 		newGroup.Interval = float64(rule.IntervalSeconds)
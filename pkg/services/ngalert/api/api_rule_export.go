@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/promrules"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maxRuleFileSize is the maximum size of the rule files that can be imported.
+const maxRuleFileSize = 10 << 20
+
+type RuleExportSrv struct {
+	store           store.RuleStore
+	DatasourceCache datasources.CacheService
+	// ruler saves the imported rule groups, as if they were posted to the ruler API.
+	ruler RulerSrv
+	log   log.Logger
+}
+
+func (srv RuleExportSrv) RouteGetNamespaceRulesExport(c *models.ReqContext) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), web.Params(c.Req)[":Namespace"], c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+
+	return srv.exportRules(c, q.Result)
+}
+
+func (srv RuleExportSrv) RouteGetRuleGroupExport(c *models.ReqContext) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), web.Params(c.Req)[":Namespace"], c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    web.Params(c.Req)[":Groupname"],
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if len(q.Result) == 0 {
+		return ErrResp(http.StatusNotFound, ngmodels.ErrRuleGroupNamespaceNotFound, "")
+	}
+
+	return srv.exportRules(c, q.Result)
+}
+
+func (srv RuleExportSrv) exportRules(c *models.ReqContext, rules []*ngmodels.AlertRule) response.Response {
+	isPrometheus := func(uid string) bool {
+		ds, err := srv.DatasourceCache.GetDatasourceByUID(uid, c.SignedInUser, c.SkipCache)
+		return err == nil && ds.Type == models.DS_PROMETHEUS
+	}
+
+	b, err := promrules.Export(rules, isPrometheus).Marshal()
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal rule file")
+	}
+	return response.Respond(http.StatusOK, b).SetHeader("Content-Type", "application/yaml")
+}
+
+func (srv RuleExportSrv) RoutePostRulesImport(c *models.ReqContext) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), web.Params(c.Req)[":Namespace"], c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(c.Req.Body, maxRuleFileSize+1))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read rule file")
+	}
+	if len(b) > maxRuleFileSize {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("rule file is larger than %d bytes", maxRuleFileSize), "")
+	}
+
+	f, err := promrules.ParseRuleFile(b)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	datasourceUID := c.Query("datasource_uid")
+	if datasourceUID != "" {
+		ds, err := srv.DatasourceCache.GetDatasourceByUID(datasourceUID, c.SignedInUser, c.SkipCache)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "failed to get data source %q", datasourceUID)
+		}
+		if ds.Type != models.DS_PROMETHEUS {
+			return ErrResp(http.StatusBadRequest, errors.New("the data source of imported Prometheus rules must be a Prometheus data source"), "")
+		}
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+
+	groups, err := promrules.Import(f, datasourceUID, q.Result, c.QueryBool("overwrite"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	// the rule groups are all validated before any of them is saved, and they are saved in a single transaction
+	if resp := srv.ruler.updateRuleGroups(c, namespace, groups); resp.Status() != http.StatusAccepted {
+		srv.log.Warn("failed to import rule groups", "namespace", namespace.Title, "status", resp.Status())
+		return resp
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": fmt.Sprintf("%d rule groups imported successfully", len(groups))})
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type RuleHistorySrv struct {
+	DatasourceCache datasources.CacheService
+	store           store.RuleStore
+	versionStore    store.RuleVersionStore
+	provenanceStore store.ProvisioningStore
+	manager         *state.Manager
+	log             log.Logger
+}
+
+func (srv RuleHistorySrv) RouteGetRuleVersions(c *models.ReqContext) response.Response {
+	_, namespace, errResp := srv.getRuleAndNamespace(c, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	q := ngmodels.ListAlertRuleVersionsQuery{
+		OrgID:   c.SignedInUser.OrgId,
+		RuleUID: web.Params(c.Req)[":RuleUID"],
+	}
+	if err := srv.versionStore.GetAlertRuleVersions(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule versions")
+	}
+
+	result := make(apimodels.RuleVersionsResponse, 0, len(q.Result))
+	for _, v := range q.Result {
+		result = append(result, toGettableRuleVersion(v, namespace.Id))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv RuleHistorySrv) RouteGetRuleVersionsDiff(c *models.ReqContext) response.Response {
+	_, namespace, errResp := srv.getRuleAndNamespace(c, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	baseVersion := c.QueryInt64("base")
+	newVersion := c.QueryInt64("new")
+	if baseVersion <= 0 || newVersion <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("the base and new query parameters must be valid rule versions"), "")
+	}
+
+	diffType := dashdiffs.DiffDelta
+	if t := c.Query("diffType"); t != "" {
+		diffType = dashdiffs.ParseDiffType(t)
+	}
+
+	baseJSON, errResp := srv.ruleVersionJSON(c, baseVersion, namespace.Id)
+	if errResp != nil {
+		return errResp
+	}
+	newJSON, errResp := srv.ruleVersionJSON(c, newVersion, namespace.Id)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := dashdiffs.CalculateJSONDiff(baseJSON, newJSON, diffType)
+	if err != nil {
+		if errors.Is(err, dashdiffs.ErrNilDiff) {
+			return ErrResp(http.StatusBadRequest, errors.New("the versions do not differ"), "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to compute diff")
+	}
+
+	if diffType == dashdiffs.DiffDelta {
+		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
+func (srv RuleHistorySrv) RouteRestoreRuleVersion(c *models.ReqContext) response.Response {
+	rule, _, errResp := srv.getRuleAndNamespace(c, true)
+	if errResp != nil {
+		return errResp
+	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.Req.Context(), c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenances of alert rules")
+	}
+	if provenances[rule.UID] != ngmodels.ProvenanceNone {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("%w: alert rule %s", ngmodels.ErrProvisioned, rule.UID), "")
+	}
+
+	version, err := strconv.ParseInt(web.Params(c.Req)[":Version"], 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid version")
+	}
+	if errResp := srv.validateRuleVersion(c, rule, version); errResp != nil {
+		return errResp
+	}
+
+	cmd := ngmodels.RestoreAlertRuleVersionCommand{
+		OrgID:   c.SignedInUser.OrgId,
+		RuleUID: rule.UID,
+		Version: version,
+		UserID:  c.SignedInUser.UserId,
+	}
+	if err := srv.versionStore.RestoreAlertRuleVersion(&cmd); err != nil {
+		switch {
+		case errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound):
+			return ErrResp(http.StatusNotFound, err, "")
+		case errors.Is(err, ngmodels.ErrAlertRuleFailedValidation), errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation):
+			return ErrResp(http.StatusBadRequest, err, "failed to restore alert rule version")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to restore alert rule version")
+	}
+
+	srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, rule.UID)
+
+	return response.JSON(http.StatusAccepted, util.DynMap{
+		"message": fmt.Sprintf("alert rule version %d restored", version),
+		"version": cmd.Result.Version,
+	})
+}
+
+// getRuleAndNamespace returns the alert rule of the request and its namespace if the user can access it.
+func (srv RuleHistorySrv) getRuleAndNamespace(c *models.ReqContext, withCanSave bool) (*ngmodels.AlertRule, *models.Folder, response.Response) {
+	q := ngmodels.GetAlertRuleByUIDQuery{
+		OrgID: c.SignedInUser.OrgId,
+		UID:   web.Params(c.Req)[":RuleUID"],
+	}
+	if err := srv.store.GetAlertRuleByUID(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return nil, nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		return nil, nil, ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+
+	namespaces, err := srv.store.GetNamespaces(c.Req.Context(), c.SignedInUser.OrgId, c.SignedInUser)
+	if err != nil {
+		return nil, nil, ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	namespace, ok := namespaces[q.Result.NamespaceUID]
+	if !ok {
+		// do not reveal the existence of rules in namespaces the user cannot see
+		return nil, nil, ErrResp(http.StatusNotFound, ngmodels.ErrAlertRuleNotFound, "")
+	}
+
+	if withCanSave {
+		if _, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespace.Title, c.SignedInUser.OrgId, c.SignedInUser, true); err != nil {
+			return nil, nil, toNamespaceErrorResponse(err)
+		}
+	}
+
+	return q.Result, namespace, nil
+}
+
+// validateRuleVersion validates the queries and the condition of a version of the alert rule against the
+// current data sources, like the ruler API does, before the version is restored.
+func (srv RuleHistorySrv) validateRuleVersion(c *models.ReqContext, rule *ngmodels.AlertRule, version int64) response.Response {
+	q := ngmodels.GetAlertRuleVersionQuery{OrgID: c.SignedInUser.OrgId, RuleUID: rule.UID, Version: version}
+	if err := srv.versionStore.GetAlertRuleVersion(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule version")
+	}
+
+	cond := ngmodels.Condition{
+		Condition: q.Result.Condition,
+		OrgID:     c.SignedInUser.OrgId,
+		Data:      q.Result.Data,
+	}
+	if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule version %d", version)
+	}
+
+	// the rules of a group are evaluated in order, so the rule can only reference the rules before it
+	groupQuery := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: rule.OrgID, NamespaceUID: rule.NamespaceUID, RuleGroup: rule.RuleGroup}
+	if err := srv.store.GetRuleGroupAlertRules(&groupQuery); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the rule group of the alert rule")
+	}
+	ruleUIDs := make(map[string]struct{})
+	for _, r := range groupQuery.Result {
+		if r.UID == rule.UID {
+			break
+		}
+		ruleUIDs[r.UID] = struct{}{}
+	}
+	if err := validateRuleReferences(q.Result.Data, ruleUIDs); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule version %d", version)
+	}
+	return nil
+}
+
+// ruleVersionJSON returns the content of a rule version to compute diffs with.
+func (srv RuleHistorySrv) ruleVersionJSON(c *models.ReqContext, version int64, namespaceID int64) (*simplejson.Json, response.Response) {
+	q := ngmodels.GetAlertRuleVersionQuery{
+		OrgID:   c.SignedInUser.OrgId,
+		RuleUID: web.Params(c.Req)[":RuleUID"],
+		Version: version,
+	}
+	if err := srv.versionStore.GetAlertRuleVersion(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "version %d", version)
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get alert rule version")
+	}
+
+	// the diff is about the content of the rule and not about the version metadata
+	rule := alertRuleFromVersion(&q.Result.AlertRuleVersion)
+	rule.Version = 0
+	node := toGettableExtendedRuleNode(*rule, namespaceID, ngmodels.ProvenanceNone)
+
+	j, err := simplejson.NewFromAny(node).Encode()
+	if err != nil {
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to encode alert rule version")
+	}
+	data, err := simplejson.NewJson(j)
+	if err != nil {
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to encode alert rule version")
+	}
+	return data, nil
+}
+
+func alertRuleFromVersion(v *ngmodels.AlertRuleVersion) *ngmodels.AlertRule {
+	return &ngmodels.AlertRule{
+		OrgID:           v.RuleOrgID,
+		UID:             v.RuleUID,
+		NamespaceUID:    v.RuleNamespaceUID,
+		RuleGroup:       v.RuleGroup,
+		Version:         v.Version,
+		Title:           v.Title,
+		Condition:       v.Condition,
+		Data:            v.Data,
+		IntervalSeconds: v.IntervalSeconds,
+		NoDataState:     v.NoDataState,
+		ExecErrState:    v.ExecErrState,
+		For:             v.For,
+		Annotations:     v.Annotations,
+		Labels:          v.Labels,
+		Record:          v.Record,
+		IsPaused:        v.IsPaused,
+		ComputedLabels:  v.ComputedLabels,
+	}
+}
+
+func toGettableRuleVersion(v *ngmodels.AlertRuleVersionWithCreator, namespaceID int64) apimodels.GettableRuleVersion {
+	rule := alertRuleFromVersion(&v.AlertRuleVersion)
+	rule.Updated = v.Created
+	return apimodels.GettableRuleVersion{
+		Version:       v.Version,
+		ParentVersion: v.ParentVersion,
+		RestoredFrom:  v.RestoredFrom,
+		Created:       v.Created,
+		CreatedBy:     v.CreatedByLogin,
+		Rule:          toGettableExtendedRuleNode(*rule, namespaceID, ngmodels.ProvenanceNone),
+	}
+}
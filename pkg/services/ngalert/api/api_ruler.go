@@ -23,6 +23,7 @@ import (
 
 type RulerSrv struct {
 	store           store.RuleStore
+	provenanceStore store.ProvisioningStore
 	DatasourceCache datasources.CacheService
 	QuotaService    *quota.QuotaService
 	manager         *state.Manager
@@ -36,6 +37,17 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		return toNamespaceErrorResponse(err)
 	}
 
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+	if errResp := srv.checkRulesNotProvisioned(c, q.Result); errResp != nil {
+		return errResp
+	}
+
 	uids, err := srv.store.DeleteNamespaceAlertRules(c.SignedInUser.OrgId, namespace.Uid)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete namespace alert rules")
@@ -55,6 +67,18 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		return toNamespaceErrorResponse(err)
 	}
 	ruleGroup := web.Params(c.Req)[":Groupname"]
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if errResp := srv.checkRulesNotProvisioned(c, q.Result); errResp != nil {
+		return errResp
+	}
+
 	uids, err := srv.store.DeleteRuleGroupAlertRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
 
 	if err != nil {
@@ -85,6 +109,10 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.Req.Context(), c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenances of alert rules")
+	}
 
 	result := apimodels.NamespaceConfigResponse{}
 	ruleGroupConfigs := make(map[string]apimodels.GettableRuleGroupConfig)
@@ -96,11 +124,11 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 				Name:     r.RuleGroup,
 				Interval: ruleGroupInterval,
 				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, namespace.Id),
+					toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]),
 				},
 			}
 		} else {
-			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id))
+			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]))
 			ruleGroupConfigs[r.RuleGroup] = ruleGroupConfig
 		}
 	}
@@ -128,12 +156,16 @@ func (srv RulerSrv) RouteGetRulegGroupConfig(c *models.ReqContext) response.Resp
 	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.Req.Context(), c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenances of alert rules")
+	}
 
 	var ruleGroupInterval model.Duration
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(q.Result))
 	for _, r := range q.Result {
 		ruleGroupInterval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
-		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id))
+		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]))
 	}
 
 	result := apimodels.RuleGroupConfigResponse{
@@ -176,6 +208,10 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 	if err := srv.store.GetOrgAlertRules(&q); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.Req.Context(), c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenances of alert rules")
+	}
 
 	configs := make(map[string]map[string]apimodels.GettableRuleGroupConfig)
 	for _, r := range q.Result {
@@ -193,7 +229,7 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 				Name:     r.RuleGroup,
 				Interval: ruleGroupInterval,
 				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, folder.Id),
+					toGettableExtendedRuleNode(*r, folder.Id, provenances[r.UID]),
 				},
 			}
 		} else {
@@ -204,11 +240,11 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 					Name:     r.RuleGroup,
 					Interval: ruleGroupInterval,
 					Rules: []apimodels.GettableExtendedRuleNode{
-						toGettableExtendedRuleNode(*r, folder.Id),
+						toGettableExtendedRuleNode(*r, folder.Id, provenances[r.UID]),
 					},
 				}
 			} else {
-				ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, folder.Id))
+				ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, folder.Id, provenances[r.UID]))
 				configs[namespace][r.RuleGroup] = ruleGroupConfig
 			}
 		}
@@ -230,31 +266,39 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		return toNamespaceErrorResponse(err)
 	}
 
-	//TODO: Should this belong in alerting-api?
-	if ruleGroupConfig.Name == "" {
-		return ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
-	}
+	return srv.updateRuleGroup(c, namespace, ruleGroupConfig)
+}
 
+// updateRuleGroup validates the rules of the rule group and replaces the rule group of the namespace with them.
+func (srv RulerSrv) updateRuleGroup(c *models.ReqContext, namespace *models.Folder, ruleGroupConfig apimodels.PostableRuleGroupConfig) response.Response {
+	return srv.updateRuleGroups(c, namespace, []apimodels.PostableRuleGroupConfig{ruleGroupConfig})
+}
+
+// updateRuleGroups validates the rules of all the rule groups first, and then replaces the rule groups of the
+// namespace with them in a single transaction, so that either all the rule groups are updated or none of them is.
+func (srv RulerSrv) updateRuleGroups(c *models.ReqContext, namespace *models.Folder, ruleGroupConfigs []apimodels.PostableRuleGroupConfig) response.Response {
+	ruleGroups := make(map[string]struct{}, len(ruleGroupConfigs))
 	alertRuleUIDs := make(map[string]struct{})
-	for _, r := range ruleGroupConfig.Rules {
-		cond := ngmodels.Condition{
-			Condition: r.GrafanaManagedAlert.Condition,
-			OrgID:     c.SignedInUser.OrgId,
-			Data:      r.GrafanaManagedAlert.Data,
+	numOfNewRules := 0
+	for _, ruleGroupConfig := range ruleGroupConfigs {
+		if _, ok := ruleGroups[ruleGroupConfig.Name]; ok {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("conflicting rule group name %q found", ruleGroupConfig.Name), "")
 		}
-		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
-			return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+		ruleGroups[ruleGroupConfig.Name] = struct{}{}
+
+		groupRuleUIDs, errResp := srv.validateRuleGroup(c, namespace, ruleGroupConfig)
+		if errResp != nil {
+			return errResp
 		}
-		if r.GrafanaManagedAlert.UID != "" {
-			_, ok := alertRuleUIDs[r.GrafanaManagedAlert.UID]
-			if ok {
-				return ErrResp(http.StatusBadRequest, fmt.Errorf("conflicting UID %q found", r.GrafanaManagedAlert.UID), "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+		for uid := range groupRuleUIDs {
+			if _, ok := alertRuleUIDs[uid]; ok {
+				return ErrResp(http.StatusBadRequest, fmt.Errorf("conflicting UID %q found", uid), "failed to validate rule group %q", ruleGroupConfig.Name)
 			}
-			alertRuleUIDs[r.GrafanaManagedAlert.UID] = struct{}{}
+			alertRuleUIDs[uid] = struct{}{}
 		}
+		numOfNewRules += len(ruleGroupConfig.Rules) - len(groupRuleUIDs)
 	}
 
-	numOfNewRules := len(ruleGroupConfig.Rules) - len(alertRuleUIDs)
 	if numOfNewRules > 0 {
 		// quotas are checked in advanced
 		// that is acceptable under the assumption that there will be only one alert rule under the rule group
@@ -269,14 +313,19 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		}
 	}
 
-	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
-		OrgID:           c.SignedInUser.OrgId,
-		NamespaceUID:    namespace.Uid,
-		RuleGroupConfig: ruleGroupConfig,
-	}); err != nil {
+	cmds := make([]store.UpdateRuleGroupCmd, 0, len(ruleGroupConfigs))
+	for _, ruleGroupConfig := range ruleGroupConfigs {
+		cmds = append(cmds, store.UpdateRuleGroupCmd{
+			OrgID:           c.SignedInUser.OrgId,
+			NamespaceUID:    namespace.Uid,
+			RuleGroupConfig: ruleGroupConfig,
+			UserID:          c.SignedInUser.UserId,
+		})
+	}
+	if err := srv.store.UpdateRuleGroups(cmds); err != nil {
 		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
 			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation) {
 			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
 		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
@@ -289,7 +338,75 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
 }
 
-func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
+// validateRuleGroup validates the rules of the rule group, it returns the UIDs of the existing rules of the group.
+func (srv RulerSrv) validateRuleGroup(c *models.ReqContext, namespace *models.Folder, ruleGroupConfig apimodels.PostableRuleGroupConfig) (map[string]struct{}, response.Response) {
+	//TODO: Should this belong in alerting-api?
+	if ruleGroupConfig.Name == "" {
+		return nil, ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
+	}
+
+	alertRuleUIDs := make(map[string]struct{})
+	for _, r := range ruleGroupConfig.Rules {
+		cond := ngmodels.Condition{
+			Condition: r.GrafanaManagedAlert.Condition,
+			OrgID:     c.SignedInUser.OrgId,
+			Data:      r.GrafanaManagedAlert.Data,
+		}
+		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+			return nil, ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+		}
+		// the rules of a group are evaluated in order, so rules can only reference the rules before them
+		if err := validateRuleReferences(r.GrafanaManagedAlert.Data, alertRuleUIDs); err != nil {
+			return nil, ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+		}
+		if r.GrafanaManagedAlert.UID != "" {
+			_, ok := alertRuleUIDs[r.GrafanaManagedAlert.UID]
+			if ok {
+				return nil, ErrResp(http.StatusBadRequest, fmt.Errorf("conflicting UID %q found", r.GrafanaManagedAlert.UID), "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+			}
+			alertRuleUIDs[r.GrafanaManagedAlert.UID] = struct{}{}
+		}
+	}
+
+	// provisioned rules can neither be updated, nor moved to or removed from their group
+	existingRules := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroupConfig.Name,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&existingRules); err != nil {
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	rules := existingRules.Result
+	for uid := range alertRuleUIDs {
+		rules = append(rules, &ngmodels.AlertRule{UID: uid})
+	}
+	if errResp := srv.checkRulesNotProvisioned(c, rules); errResp != nil {
+		return nil, errResp
+	}
+
+	return alertRuleUIDs, nil
+}
+
+// checkRulesNotProvisioned returns an error response if one of the rules is provisioned, as provisioned rules
+// can only be changed by provisioning.
+func (srv RulerSrv) checkRulesNotProvisioned(c *models.ReqContext, rules []*ngmodels.AlertRule) response.Response {
+	if len(rules) == 0 {
+		return nil
+	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.Req.Context(), c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenances of alert rules")
+	}
+	for _, r := range rules {
+		if provenances[r.UID] != ngmodels.ProvenanceNone {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("%w: alert rule %s", ngmodels.ErrProvisioned, r.UID), "")
+		}
+	}
+	return nil
+}
+
+func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provenance ngmodels.Provenance) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
 			ID:              r.ID,
@@ -306,12 +423,16 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			RuleGroup:       r.RuleGroup,
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			IsPaused:        r.IsPaused,
+			ComputedLabels:  r.ComputedLabels,
+			Provenance:      provenance,
 		},
 	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
 		For:         model.Duration(r.For),
 		Annotations: r.Annotations,
 		Labels:      r.Labels,
+		Record:      r.Record,
 	}
 	return gettableExtendedRuleNode
 }
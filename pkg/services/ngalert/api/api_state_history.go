@@ -0,0 +1,128 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const defaultStateHistoryLimit = 100
+
+type StateHistorySrv struct {
+	store        store.RuleStore
+	historyStore store.StateHistoryStore
+	log          log.Logger
+}
+
+func (srv StateHistorySrv) RouteGetStateHistory(c *models.ReqContext) response.Response {
+	labels, err := parseLabelMatchers(c.QueryStrings("labels"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultStateHistoryLimit
+	}
+
+	q := ngmodels.ListAlertStateHistoryQuery{
+		OrgID:  c.SignedInUser.OrgId,
+		Labels: labels,
+		Limit:  limit,
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		q.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		q.To = time.UnixMilli(to)
+	}
+
+	titles, errResp := srv.getVisibleRuleTitles(c)
+	if errResp != nil {
+		return errResp
+	}
+	if ruleUID := c.Query("ruleUID"); ruleUID != "" {
+		if _, ok := titles[ruleUID]; !ok {
+			return ErrResp(http.StatusNotFound, ngmodels.ErrAlertRuleNotFound, "")
+		}
+		q.RuleUIDs = []string{ruleUID}
+	} else {
+		for uid := range titles {
+			q.RuleUIDs = append(q.RuleUIDs, uid)
+		}
+	}
+
+	result := make(apimodels.StateHistoryResponse, 0)
+	if len(q.RuleUIDs) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	if err := srv.historyStore.ListAlertStateHistory(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert state history")
+	}
+
+	for _, entry := range q.Result {
+		result = append(result, apimodels.GettableStateHistory{
+			RuleUID:       entry.RuleUID,
+			RuleTitle:     titles[entry.RuleUID],
+			Labels:        entry.Labels,
+			PreviousState: entry.PreviousState,
+			State:         entry.State,
+			Values:        entry.EvaluationValues,
+			Error:         entry.Error,
+			Time:          entry.Created,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// getVisibleRuleTitles returns the titles of the alert rules in the namespaces the user can see, by rule UID.
+func (srv StateHistorySrv) getVisibleRuleTitles(c *models.ReqContext) (map[string]string, response.Response) {
+	namespaces, err := srv.store.GetNamespaces(c.Req.Context(), c.SignedInUser.OrgId, c.SignedInUser)
+	if err != nil {
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+
+	titles := make(map[string]string)
+	if len(namespaces) == 0 {
+		return titles, nil
+	}
+
+	q := ngmodels.ListAlertRulesQuery{
+		OrgID: c.SignedInUser.OrgId,
+	}
+	for uid := range namespaces {
+		q.NamespaceUIDs = append(q.NamespaceUIDs, uid)
+	}
+	if err := srv.store.GetOrgAlertRules(&q); err != nil {
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+	for _, rule := range q.Result {
+		titles[rule.UID] = rule.Title
+	}
+	return titles, nil
+}
+
+// parseLabelMatchers parses label matchers formatted as name=value.
+func parseLabelMatchers(matchers []string) (map[string]string, error) {
+	labels := make(map[string]string, len(matchers))
+	for _, m := range matchers {
+		parts := strings.SplitN(m, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid label matcher %q: must be formatted as name=value", m)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	return labels, nil
+}
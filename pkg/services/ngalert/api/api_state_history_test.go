@@ -0,0 +1,23 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelMatchers(t *testing.T) {
+	labels, err := parseLabelMatchers([]string{"instance=a", "query=up==1"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"instance": "a", "query": "up==1"}, labels)
+
+	labels, err = parseLabelMatchers(nil)
+	require.NoError(t, err)
+	require.Nil(t, labels)
+
+	_, err = parseLabelMatchers([]string{"instance"})
+	require.Error(t, err)
+
+	_, err = parseLabelMatchers([]string{"=a"})
+	require.Error(t, err)
+}
@@ -6,23 +6,30 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/web"
 )
 
+// defaultBacktestInterval is the evaluation interval of a backtest when the request does not set it.
+const defaultBacktestInterval = time.Minute
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	Cfg             *setting.Cfg
 	DataService     *tsdb.Service
 	DatasourceCache datasources.CacheService
+	Backtesting     *backtesting.Engine
 	log             log.Logger
 }
 
@@ -93,3 +100,71 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+func (srv TestingApiSrv) RouteBacktestConfig(c *models.ReqContext, cmd apimodels.BacktestConfig) response.Response {
+	interval := time.Duration(cmd.Interval)
+	if interval == 0 {
+		interval = defaultBacktestInterval
+	}
+	if interval < 0 || interval%time.Second != 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid interval %s: it must be a positive number of seconds", interval), "")
+	}
+	if cmd.For < 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid for %s: it must not be negative", cmd.For), "")
+	}
+
+	noDataState := ngmodels.NoData
+	if cmd.NoDataState != "" {
+		noDataState = ngmodels.NoDataState(cmd.NoDataState)
+	}
+	execErrState := ngmodels.AlertingErrState
+	if cmd.ExecErrState != "" {
+		execErrState = ngmodels.ExecutionErrorState(cmd.ExecErrState)
+	}
+
+	rule := &ngmodels.AlertRule{
+		OrgID:           c.SignedInUser.OrgId,
+		Title:           "backtest",
+		UID:             "backtest",
+		Condition:       cmd.Condition,
+		Data:            cmd.Data,
+		IntervalSeconds: int64(interval.Seconds()),
+		For:             time.Duration(cmd.For),
+		Labels:          cmd.Labels,
+		NoDataState:     noDataState,
+		ExecErrState:    execErrState,
+	}
+	condition := ngmodels.Condition{Condition: rule.Condition, OrgID: rule.OrgID, Data: rule.Data}
+	if err := validateCondition(condition, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid condition")
+	}
+
+	timelines, err := srv.Backtesting.Test(c.Req.Context(), rule, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidTimeRange) || errors.Is(err, backtesting.ErrTooManyEvaluations) || errors.Is(err, backtesting.ErrRuleReferencesNotSupported) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to backtest the alert rule")
+	}
+
+	result := apimodels.BacktestResult{Instances: make([]apimodels.BacktestInstance, 0, len(timelines))}
+	for _, timeline := range timelines {
+		instance := apimodels.BacktestInstance{
+			Labels:   timeline.Labels,
+			Timeline: make([]apimodels.BacktestStep, 0, len(timeline.Steps)),
+		}
+		for _, step := range timeline.Steps {
+			s := apimodels.BacktestStep{
+				Time:   step.Time,
+				State:  step.State.String(),
+				Values: step.Values,
+			}
+			if step.Error != nil {
+				s.Error = step.Error.Error()
+			}
+			instance.Timeline = append(instance.Timeline, s)
+		}
+		result.Instances = append(result.Instances, instance)
+	}
+	return response.JSON(http.StatusOK, result)
+}
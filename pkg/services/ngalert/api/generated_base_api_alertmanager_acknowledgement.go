@@ -0,0 +1,59 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type AlertmanagerAcknowledgementApiService interface {
+	RouteDeleteAcknowledgement(*models.ReqContext) response.Response
+	RouteGetAcknowledgements(*models.ReqContext) response.Response
+	RoutePostAcknowledgement(*models.ReqContext, apimodels.PostableAcknowledgement) response.Response
+}
+
+func (api *API) RegisterAlertmanagerAcknowledgementApiEndpoints(srv AlertmanagerAcknowledgementApiService, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Delete(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/alerts/{Fingerprint}/acknowledgement"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/grafana/api/v2/alerts/{Fingerprint}/acknowledgement",
+				srv.RouteDeleteAcknowledgement,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/acknowledgements"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/acknowledgements",
+				srv.RouteGetAcknowledgements,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/alerts/{Fingerprint}/acknowledgement"),
+			binding.Bind(apimodels.PostableAcknowledgement{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/alerts/{Fingerprint}/acknowledgement",
+				srv.RoutePostAcknowledgement,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,65 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type AlertmanagerConfigHistoryApiService interface {
+	RouteGetAlertingConfigDiff(*models.ReqContext) response.Response
+	RouteGetAlertingConfigHistory(*models.ReqContext) response.Response
+	RouteGetAlertingConfigVersion(*models.ReqContext) response.Response
+	RoutePostRollbackAlertingConfig(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterAlertmanagerConfigHistoryApiEndpoints(srv AlertmanagerConfigHistoryApiService, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/config/history/diff"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/config/history/diff",
+				srv.RouteGetAlertingConfigDiff,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/config/history"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/config/history",
+				srv.RouteGetAlertingConfigHistory,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/config/history/{ConfigID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/config/history/{ConfigID}",
+				srv.RouteGetAlertingConfigVersion,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/config/history/{ConfigID}/rollback"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/config/history/{ConfigID}/rollback",
+				srv.RoutePostRollbackAlertingConfig,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,143 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type AlertmanagerSilencesApiService interface {
+	RouteDeleteRecurringSilence(*models.ReqContext) response.Response
+	RouteDeleteSilenceTemplate(*models.ReqContext) response.Response
+	RouteGetRecurringSilence(*models.ReqContext) response.Response
+	RouteGetRecurringSilences(*models.ReqContext) response.Response
+	RouteGetSilenceTemplate(*models.ReqContext) response.Response
+	RouteGetSilenceTemplates(*models.ReqContext) response.Response
+	RoutePostRecurringSilence(*models.ReqContext, apimodels.PostableRecurringSilence) response.Response
+	RoutePostSilenceFromTemplate(*models.ReqContext, apimodels.PostableSilenceFromTemplate) response.Response
+	RoutePostSilenceTemplate(*models.ReqContext, apimodels.PostableSilenceTemplate) response.Response
+	RoutePutRecurringSilence(*models.ReqContext, apimodels.PostableRecurringSilence) response.Response
+	RoutePutSilenceTemplate(*models.ReqContext, apimodels.PostableSilenceTemplate) response.Response
+}
+
+func (api *API) RegisterAlertmanagerSilencesApiEndpoints(srv AlertmanagerSilencesApiService, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Delete(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/recurring-silences/{RecurringSilenceUID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/grafana/api/v2/recurring-silences/{RecurringSilenceUID}",
+				srv.RouteDeleteRecurringSilence,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID}",
+				srv.RouteDeleteSilenceTemplate,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/recurring-silences/{RecurringSilenceUID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/recurring-silences/{RecurringSilenceUID}",
+				srv.RouteGetRecurringSilence,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/recurring-silences"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/recurring-silences",
+				srv.RouteGetRecurringSilences,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID}",
+				srv.RouteGetSilenceTemplate,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-templates"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/silence-templates",
+				srv.RouteGetSilenceTemplates,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/recurring-silences"),
+			binding.Bind(apimodels.PostableRecurringSilence{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/recurring-silences",
+				srv.RoutePostRecurringSilence,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID}/silences"),
+			binding.Bind(apimodels.PostableSilenceFromTemplate{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID}/silences",
+				srv.RoutePostSilenceFromTemplate,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-templates"),
+			binding.Bind(apimodels.PostableSilenceTemplate{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silence-templates",
+				srv.RoutePostSilenceTemplate,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/recurring-silences/{RecurringSilenceUID}"),
+			binding.Bind(apimodels.PostableRecurringSilence{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/alertmanager/grafana/api/v2/recurring-silences/{RecurringSilenceUID}",
+				srv.RoutePutRecurringSilence,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID}"),
+			binding.Bind(apimodels.PostableSilenceTemplate{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID}",
+				srv.RoutePutSilenceTemplate,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,55 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type RuleExportApiService interface {
+	RouteGetNamespaceRulesExport(*models.ReqContext) response.Response
+	RouteGetRuleGroupExport(*models.ReqContext) response.Response
+	RoutePostRulesImport(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterRuleExportApiEndpoints(srv RuleExportApiService, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/export/rules/{Namespace}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/export/rules/{Namespace}",
+				srv.RouteGetNamespaceRulesExport,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/export/rules/{Namespace}/{Groupname}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/export/rules/{Namespace}/{Groupname}",
+				srv.RouteGetRuleGroupExport,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/import/rules/{Namespace}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/import/rules/{Namespace}",
+				srv.RoutePostRulesImport,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
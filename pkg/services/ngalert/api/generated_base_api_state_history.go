@@ -0,0 +1,35 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type StateHistoryApiService interface {
+	RouteGetStateHistory(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterStateHistoryApiEndpoints(srv StateHistoryApiService, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/state_history"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/state_history",
+				srv.RouteGetStateHistory,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -20,12 +20,23 @@ import (
 )
 
 type TestingApiService interface {
+	RouteBacktestConfig(*models.ReqContext, apimodels.BacktestConfig) response.Response
 	RouteEvalQueries(*models.ReqContext, apimodels.EvalQueriesPayload) response.Response
 	RouteTestRuleConfig(*models.ReqContext, apimodels.TestRulePayload) response.Response
 }
 
 func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/v1/rule/backtest"),
+			binding.Bind(apimodels.BacktestConfig{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/backtest",
+				srv.RouteBacktestConfig,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/eval"),
 			binding.Bind(apimodels.EvalQueriesPayload{}),
@@ -5,18 +5,22 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"reflect"
 	"sort"
+	tmpltext "text/template"
 	"time"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/pkg/errors"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
 )
@@ -91,6 +95,18 @@ import (
 //       408: Failure
 //       409: AlertManagerNotReady
 
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/templates/test alertmanager RoutePostTestTemplates
+//
+// Render notification templates with test alerts without saving them.
+//
+//     Responses:
+//
+//       200: TestTemplatesResults
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: AlertManagerNotFound
+//       409: AlertManagerNotReady
+
 // swagger:route GET /api/alertmanager/{Recipient}/api/v2/silences alertmanager RouteGetSilences
 //
 // get silences
@@ -173,6 +189,49 @@ type TestReceiverConfigResult struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// swagger:parameters RoutePostTestTemplates
+type TestTemplatesConfigParams struct {
+	// The template files to test, by file name. Every template they define is rendered.
+	// in:body
+	Templates map[string]string `yaml:"templates,omitempty" json:"templates,omitempty"`
+	// The alerts the templates are rendered with, a firing test alert if there are none.
+	// in:body
+	Alerts []*TestTemplatesConfigAlertParams `yaml:"alerts,omitempty" json:"alerts,omitempty"`
+}
+
+type TestTemplatesConfigAlertParams struct {
+	Annotations  model.LabelSet `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Labels       model.LabelSet `yaml:"labels,omitempty" json:"labels,omitempty"`
+	StartsAt     time.Time      `yaml:"startsAt,omitempty" json:"startsAt,omitempty"`
+	EndsAt       time.Time      `yaml:"endsAt,omitempty" json:"endsAt,omitempty"`
+	GeneratorURL string         `yaml:"generatorURL,omitempty" json:"generatorURL,omitempty"`
+}
+
+// swagger:model
+type TestTemplatesResults struct {
+	Results []TestTemplatesResult      `json:"results"`
+	Errors  []TestTemplatesErrorResult `json:"errors"`
+}
+
+// swagger:model
+type TestTemplatesResult struct {
+	// Name is the name of the template.
+	Name string `json:"name"`
+	// Text is the rendered template.
+	Text string `json:"text"`
+}
+
+// swagger:model
+type TestTemplatesErrorResult struct {
+	// Name is the name of the template, or of the template file if it is invalid.
+	Name string `json:"name,omitempty"`
+	// Kind is the kind of error, invalid_template or execution_error.
+	Kind string `json:"kind"`
+	// Line is the line of the error in the template file, if known.
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
 // swagger:parameters RouteCreateSilence
 type CreateSilenceParams struct {
 	// in:body
@@ -341,7 +400,7 @@ type BodyAlertingConfig struct {
 }
 
 // alertmanager routes
-// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMStatus RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig RoutePostTestReceivers
+// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMStatus RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig RoutePostTestReceivers RoutePostTestTemplates
 // ruler routes
 // swagger:parameters RouteGetRulesConfig RoutePostNameRulesConfig RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetRulegGroupConfig RouteDeleteRuleGroupConfig
 // prom routes
@@ -466,6 +525,8 @@ func (c *PostableUserConfig) UnmarshalYAML(value *yaml.Node) error {
 type GettableUserConfig struct {
 	TemplateFiles      map[string]string         `yaml:"template_files" json:"template_files"`
 	AlertmanagerConfig GettableApiAlertingConfig `yaml:"alertmanager_config" json:"alertmanager_config"`
+	// TemplateFileProvenances are the provenances of the provisioned templates by name.
+	TemplateFileProvenances map[string]models.Provenance `yaml:"template_file_provenances,omitempty" json:"template_file_provenances,omitempty"`
 
 	// amSimple stores a map[string]interface of the decoded alertmanager config.
 	// This enables circumventing the underlying alertmanager secret type
@@ -532,6 +593,11 @@ type GettableApiAlertingConfig struct {
 
 	// Override with our superset receiver type
 	Receivers []*GettableApiReceiver `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+
+	// RouteProvenance is set if the notification policies are provisioned.
+	RouteProvenance models.Provenance `yaml:"route_provenance,omitempty" json:"route_provenance,omitempty"`
+	// MuteTimeProvenances are the provenances of the provisioned mute timings by name.
+	MuteTimeProvenances map[string]models.Provenance `yaml:"mute_time_provenances,omitempty" json:"mute_time_provenances,omitempty"`
 }
 
 func (c *GettableApiAlertingConfig) UnmarshalJSON(b []byte) error {
@@ -544,10 +610,12 @@ func (c *GettableApiAlertingConfig) UnmarshalJSON(b []byte) error {
 	// Otherwise, the json decoder will detect this and only use the embedded type.
 	// Additionally, we'll use pointers to slices in order to reference the intended target.
 	type overrides struct {
-		Receivers *[]*GettableApiReceiver `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+		Receivers           *[]*GettableApiReceiver       `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+		RouteProvenance     *models.Provenance            `yaml:"route_provenance,omitempty" json:"route_provenance,omitempty"`
+		MuteTimeProvenances *map[string]models.Provenance `yaml:"mute_time_provenances,omitempty" json:"mute_time_provenances,omitempty"`
 	}
 
-	if err := json.Unmarshal(b, &overrides{Receivers: &c.Receivers}); err != nil {
+	if err := json.Unmarshal(b, &overrides{Receivers: &c.Receivers, RouteProvenance: &c.RouteProvenance, MuteTimeProvenances: &c.MuteTimeProvenances}); err != nil {
 		return err
 	}
 
@@ -587,10 +655,11 @@ func (c *GettableApiAlertingConfig) validate() error {
 
 // Config is the top-level configuration for Alertmanager's config files.
 type Config struct {
-	Global       *config.GlobalConfig  `yaml:"global,omitempty" json:"global,omitempty"`
-	Route        *Route                `yaml:"route,omitempty" json:"route,omitempty"`
-	InhibitRules []*config.InhibitRule `yaml:"inhibit_rules,omitempty" json:"inhibit_rules,omitempty"`
-	Templates    []string              `yaml:"templates" json:"templates"`
+	Global            *config.GlobalConfig      `yaml:"global,omitempty" json:"global,omitempty"`
+	Route             *Route                    `yaml:"route,omitempty" json:"route,omitempty"`
+	InhibitRules      []*config.InhibitRule     `yaml:"inhibit_rules,omitempty" json:"inhibit_rules,omitempty"`
+	MuteTimeIntervals []config.MuteTimeInterval `yaml:"mute_time_intervals,omitempty" json:"mute_time_intervals,omitempty"`
+	Templates         []string                  `yaml:"templates" json:"templates"`
 }
 
 // A Route is a node that contains definitions of how to handle alerts. This is modified
@@ -614,6 +683,65 @@ type Route struct {
 	GroupWait      *model.Duration `yaml:"group_wait,omitempty" json:"group_wait,omitempty"`
 	GroupInterval  *model.Duration `yaml:"group_interval,omitempty" json:"group_interval,omitempty"`
 	RepeatInterval *model.Duration `yaml:"repeat_interval,omitempty" json:"repeat_interval,omitempty"`
+
+	// Escalations are only supported by the Grafana Alertmanager.
+	Escalations []*Escalation `yaml:"escalations,omitempty" json:"escalations,omitempty"`
+	// TemplateMatchers are only supported by the Grafana Alertmanager.
+	TemplateMatchers []*TemplateMatcher `yaml:"template_matchers,omitempty" json:"template_matchers,omitempty"`
+}
+
+// Escalation notifies a receiver of the firing alerts of a route that are not acknowledged
+// after they have been firing for a given duration.
+type Escalation struct {
+	After    model.Duration `yaml:"after" json:"after"`
+	Receiver string         `yaml:"receiver" json:"receiver"`
+}
+
+// TemplateMatcher matches the result of a template, executed with the labels and the annotations of
+// an alert, against a value. For example, the template `{{ .Labels.grafana_folder | toLower }}` with
+// the type `=~` and the value `team-.*` matches the alerts of the folders of all teams.
+type TemplateMatcher struct {
+	Template string `yaml:"template" json:"template"`
+	// Type is one of =, !=, =~ and !~.
+	Type  string `yaml:"type" json:"type"`
+	Value string `yaml:"value" json:"value"`
+}
+
+// TemplateMatcherData is the data the template of a template matcher is executed with.
+type TemplateMatcherData struct {
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// ParseTemplate parses the template of the matcher. The functions of the notification templates are available.
+func (m *TemplateMatcher) ParseTemplate() (*tmpltext.Template, error) {
+	return tmpltext.New(m.LabelName()).Option("missingkey=zero").Funcs(tmpltext.FuncMap(template.DefaultFuncs)).Parse(m.Template)
+}
+
+// LabelName returns the name of the private label that the result of the template is stored in,
+// so that the routing tree can match it like any other label.
+func (m *TemplateMatcher) LabelName() string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(m.Template))
+	return fmt.Sprintf("__template_%x__", h.Sum64())
+}
+
+// Matcher returns the label matcher of the result of the template.
+func (m *TemplateMatcher) Matcher() (*labels.Matcher, error) {
+	var t labels.MatchType
+	switch m.Type {
+	case "=":
+		t = labels.MatchEqual
+	case "!=":
+		t = labels.MatchNotEqual
+	case "=~":
+		t = labels.MatchRegexp
+	case "!~":
+		t = labels.MatchNotRegexp
+	default:
+		return nil, fmt.Errorf("unknown match type %q", m.Type)
+	}
+	return labels.NewMatcher(t, m.LabelName(), m.Value)
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Route. This is a copy of alertmanager's upstream except it removes validation on the label key.
@@ -651,6 +779,27 @@ func (r *Route) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return fmt.Errorf("repeat_interval cannot be zero")
 	}
 
+	for _, e := range r.Escalations {
+		if e == nil || e.Receiver == "" {
+			return fmt.Errorf("escalations must have a receiver")
+		}
+		if time.Duration(e.After) <= 0 {
+			return fmt.Errorf("the after duration of the escalation to receiver %q must be positive", e.Receiver)
+		}
+	}
+
+	for _, m := range r.TemplateMatchers {
+		if m == nil || m.Template == "" {
+			return fmt.Errorf("template matchers must have a template")
+		}
+		if _, err := m.ParseTemplate(); err != nil {
+			return fmt.Errorf("invalid template matcher %q: %w", m.Template, err)
+		}
+		if _, err := m.Matcher(); err != nil {
+			return fmt.Errorf("invalid template matcher %q: %w", m.Template, err)
+		}
+	}
+
 	return nil
 }
 
@@ -752,9 +901,38 @@ func (c *Config) UnmarshalJSON(b []byte) error {
 		}
 	}
 
+	muteTimeIntervals := make(map[string]struct{}, len(c.MuteTimeIntervals))
+	for _, mt := range c.MuteTimeIntervals {
+		if mt.Name == "" {
+			return fmt.Errorf("missing name in mute time interval")
+		}
+		if _, ok := muteTimeIntervals[mt.Name]; ok {
+			return fmt.Errorf("mute time interval %q is not unique", mt.Name)
+		}
+		muteTimeIntervals[mt.Name] = struct{}{}
+	}
+	for _, name := range AllMuteTimeIntervals(c.Route) {
+		if _, ok := muteTimeIntervals[name]; !ok {
+			return fmt.Errorf("undefined mute time interval %q used in route", name)
+		}
+	}
+
 	return nil
 }
 
+// AllMuteTimeIntervals returns the names of the mute time intervals used by a route and its child routes.
+func AllMuteTimeIntervals(route *Route) []string {
+	if route == nil {
+		return nil
+	}
+
+	res := append([]string{}, route.MuteTimeIntervals...)
+	for _, subRoute := range route.Routes {
+		res = append(res, AllMuteTimeIntervals(subRoute)...)
+	}
+	return res
+}
+
 type PostableApiAlertingConfig struct {
 	Config `yaml:",inline"`
 
@@ -825,6 +1003,16 @@ func (c *PostableApiAlertingConfig) validate() error {
 		}
 	}
 
+	escalationReceivers := AllEscalationReceivers(c.Route)
+	if len(escalationReceivers) > 0 && hasAMReceivers {
+		return fmt.Errorf("escalations are only supported with Grafana receivers")
+	}
+	for _, receiver := range escalationReceivers {
+		if _, ok := receivers[receiver]; !ok {
+			return fmt.Errorf("unexpected escalation receiver (%s) is undefined", receiver)
+		}
+	}
+
 	return nil
 }
 
@@ -860,13 +1048,31 @@ func AllReceivers(route *config.Route) (res []string) {
 	return res
 }
 
+// AllEscalationReceivers will recursively walk a routing tree and return a list of all the
+// receiver names referenced by escalations.
+func AllEscalationReceivers(route *Route) (res []string) {
+	if route == nil {
+		return res
+	}
+
+	for _, e := range route.Escalations {
+		res = append(res, e.Receiver)
+	}
+
+	for _, subRoute := range route.Routes {
+		res = append(res, AllEscalationReceivers(subRoute)...)
+	}
+	return res
+}
+
 type GettableGrafanaReceiver struct {
-	UID                   string           `json:"uid"`
-	Name                  string           `json:"name"`
-	Type                  string           `json:"type"`
-	DisableResolveMessage bool             `json:"disableResolveMessage"`
-	Settings              *simplejson.Json `json:"settings"`
-	SecureFields          map[string]bool  `json:"secureFields"`
+	UID                   string            `json:"uid"`
+	Name                  string            `json:"name"`
+	Type                  string            `json:"type"`
+	DisableResolveMessage bool              `json:"disableResolveMessage"`
+	Settings              *simplejson.Json  `json:"settings"`
+	SecureFields          map[string]bool   `json:"secureFields"`
+	Provenance            models.Provenance `json:"provenance,omitempty"`
 }
 
 type PostableGrafanaReceiver struct {
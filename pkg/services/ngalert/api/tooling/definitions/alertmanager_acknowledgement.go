@@ -0,0 +1,70 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/alertmanager/grafana/api/v2/acknowledgements alertmanager_acknowledgement RouteGetAcknowledgements
+//
+// List the acknowledgements of the firing alerts
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableAcknowledgements
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/alerts/{Fingerprint}/acknowledgement alertmanager_acknowledgement RoutePostAcknowledgement
+//
+// Acknowledge a firing alert, which stops the escalations of the alert until it is resolved
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       201: GettableAcknowledgement
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route DELETE /api/alertmanager/grafana/api/v2/alerts/{Fingerprint}/acknowledgement alertmanager_acknowledgement RouteDeleteAcknowledgement
+//
+// Remove the acknowledgement of an alert
+//
+//     Responses:
+//       200: Ack
+//       404: NotFound
+
+// swagger:parameters RoutePostAcknowledgement RouteDeleteAcknowledgement
+type AlertFingerprintParam struct {
+	// The fingerprint of the alert, as in the fingerprint field of the alerts.
+	// in: path
+	Fingerprint string
+}
+
+// swagger:parameters RoutePostAcknowledgement
+type PostableAcknowledgementParams struct {
+	// in: body
+	Body PostableAcknowledgement
+}
+
+// swagger:model
+type PostableAcknowledgement struct {
+	Comment string `json:"comment"`
+}
+
+// swagger:model
+type GettableAcknowledgement struct {
+	Fingerprint string `json:"fingerprint"`
+	// StartsAt is the start of the firing alert that is acknowledged. The acknowledgement
+	// does not apply to the alert after it's resolved, even if it fires again.
+	StartsAt       time.Time `json:"startsAt"`
+	AcknowledgedBy string    `json:"acknowledgedBy"`
+	AcknowledgedAt time.Time `json:"acknowledgedAt"`
+	Comment        string    `json:"comment,omitempty"`
+}
+
+// swagger:model
+type GettableAcknowledgements []GettableAcknowledgement
@@ -0,0 +1,98 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/alertmanager/grafana/config/history alertmanager_config_history RouteGetAlertingConfigHistory
+//
+// List the saved versions of the Alertmanager configuration, newest first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableHistoricUserConfigs
+
+// swagger:route GET /api/alertmanager/grafana/config/history/{ConfigID} alertmanager_config_history RouteGetAlertingConfigVersion
+//
+// Get a saved version of the Alertmanager configuration
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableHistoricUserConfig
+//       404: Failure
+
+// swagger:route GET /api/alertmanager/grafana/config/history/diff alertmanager_config_history RouteGetAlertingConfigDiff
+//
+// Get the difference between two saved versions of the Alertmanager configuration
+//
+//     Produces:
+//     - application/json
+//     - text/html
+//
+//     Responses:
+//       200: AlertingConfigDiffResponse
+//       400: ValidationError
+//       404: Failure
+
+// swagger:route POST /api/alertmanager/grafana/config/history/{ConfigID}/rollback alertmanager_config_history RoutePostRollbackAlertingConfig
+//
+// Roll back the Alertmanager configuration to a saved version, which is saved and applied as the latest version
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: Failure
+
+// swagger:parameters RouteGetAlertingConfigHistory
+type AlertingConfigHistoryParams struct {
+	// The maximum number of versions to return, all of them if not set.
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters RouteGetAlertingConfigVersion RoutePostRollbackAlertingConfig
+type AlertingConfigIDParam struct {
+	// in: path
+	ConfigID int64
+}
+
+// swagger:parameters RouteGetAlertingConfigDiff
+type AlertingConfigDiffParams struct {
+	// The ID of the version to compare from.
+	// in: query
+	Base int64 `json:"base"`
+	// The ID of the version to compare to.
+	// in: query
+	New int64 `json:"new"`
+	// in: query
+	// enum: basic,json,delta
+	// default: delta
+	DiffType string `json:"diffType"`
+}
+
+// swagger:model
+type GettableHistoricUserConfigs []GettableHistoricUserConfig
+
+// AlertingConfigDiffResponse is the delta of the two versions when the diff type is delta
+// and the HTML rendering of the difference otherwise.
+// swagger:model
+type AlertingConfigDiffResponse map[string]interface{}
+
+// GettableHistoricUserConfig is a saved version of the Alertmanager configuration.
+// swagger:model
+type GettableHistoricUserConfig struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	// CreatedBy is the login of the user who saved the configuration, empty if unknown.
+	CreatedBy string `json:"createdBy"`
+	// Default is true if the configuration is the default configuration.
+	Default bool `json:"default"`
+
+	// The configuration is only returned when a single version is requested.
+	TemplateFiles      map[string]string          `json:"template_files,omitempty"`
+	AlertmanagerConfig *GettableApiAlertingConfig `json:"alertmanager_config,omitempty"`
+}
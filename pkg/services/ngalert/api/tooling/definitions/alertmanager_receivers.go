@@ -0,0 +1,52 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/alertmanager/grafana/config/api/v1/receivers alertmanager RouteGetReceivers
+//
+// Get the status of the last notifications sent to the contact points
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableReceiversStatus
+
+// swagger:model
+type GettableReceiversStatus []GettableReceiverStatus
+
+// swagger:model
+type GettableReceiverStatus struct {
+	Name         string                      `json:"name"`
+	Integrations []GettableIntegrationStatus `json:"integrations"`
+}
+
+// swagger:model
+type GettableIntegrationStatus struct {
+	UID          string `json:"uid"`
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Index        int    `json:"index"`
+	SendResolved bool   `json:"sendResolved"`
+	// LastNotifyAttempt is the zero time if no notification was sent to the integration since
+	// Grafana started or the integration was created.
+	LastNotifyAttempt           time.Time `json:"lastNotifyAttempt"`
+	LastNotifyAttemptDuration   string    `json:"lastNotifyAttemptDuration"`
+	LastNotifyAttemptStatusCode int       `json:"lastNotifyAttemptStatusCode,omitempty"`
+	LastNotifyAttemptError      string    `json:"lastNotifyAttemptError,omitempty"`
+	// Deliveries are the last notification attempts of the integration, the most recent first.
+	Deliveries []NotificationDelivery `json:"deliveries"`
+}
+
+// swagger:model
+type NotificationDelivery struct {
+	Timestamp time.Time `json:"timestamp"`
+	Duration  string    `json:"duration"`
+	// StatusCode is the status code of the response of the HTTP request of the notification,
+	// if the integration sends one.
+	StatusCode int    `json:"statusCode,omitempty"`
+	Alerts     int    `json:"alerts"`
+	Error      string `json:"error,omitempty"`
+}
@@ -0,0 +1,228 @@
+package definitions
+
+import (
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/alertmanager/grafana/api/v2/silence-templates alertmanager_silences RouteGetSilenceTemplates
+//
+// List the silence templates
+//
+//     Responses:
+//       200: GettableSilenceTemplates
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/silence-templates alertmanager_silences RoutePostSilenceTemplate
+//
+// Create a silence template
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: GettableSilenceTemplate
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route GET /api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID} alertmanager_silences RouteGetSilenceTemplate
+//
+// Get a silence template
+//
+//     Responses:
+//       200: GettableSilenceTemplate
+//       404: NotFound
+
+// swagger:route PUT /api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID} alertmanager_silences RoutePutSilenceTemplate
+//
+// Update a silence template
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: GettableSilenceTemplate
+//       400: ValidationError
+//       404: NotFound
+//       409: Failure
+
+// swagger:route DELETE /api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID} alertmanager_silences RouteDeleteSilenceTemplate
+//
+// Delete a silence template
+//
+//     Responses:
+//       200: Ack
+//       404: NotFound
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/silence-templates/{TemplateUID}/silences alertmanager_silences RoutePostSilenceFromTemplate
+//
+// Create a silence with the matchers of a silence template
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: SilenceCreatedResponse
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route GET /api/alertmanager/grafana/api/v2/recurring-silences alertmanager_silences RouteGetRecurringSilences
+//
+// List the recurring silences
+//
+//     Responses:
+//       200: GettableRecurringSilences
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/recurring-silences alertmanager_silences RoutePostRecurringSilence
+//
+// Create a recurring silence
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: GettableRecurringSilence
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route GET /api/alertmanager/grafana/api/v2/recurring-silences/{RecurringSilenceUID} alertmanager_silences RouteGetRecurringSilence
+//
+// Get a recurring silence
+//
+//     Responses:
+//       200: GettableRecurringSilence
+//       404: NotFound
+
+// swagger:route PUT /api/alertmanager/grafana/api/v2/recurring-silences/{RecurringSilenceUID} alertmanager_silences RoutePutRecurringSilence
+//
+// Update a recurring silence. The silences it already created are not updated.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRecurringSilence
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route DELETE /api/alertmanager/grafana/api/v2/recurring-silences/{RecurringSilenceUID} alertmanager_silences RouteDeleteRecurringSilence
+//
+// Delete a recurring silence. The silences it already created are not expired.
+//
+//     Responses:
+//       200: Ack
+//       404: NotFound
+
+// swagger:parameters RouteGetSilenceTemplate RoutePutSilenceTemplate RouteDeleteSilenceTemplate RoutePostSilenceFromTemplate
+type SilenceTemplateUIDParam struct {
+	// in: path
+	TemplateUID string
+}
+
+// swagger:parameters RouteGetRecurringSilence RoutePutRecurringSilence RouteDeleteRecurringSilence
+type RecurringSilenceUIDParam struct {
+	// in: path
+	RecurringSilenceUID string
+}
+
+// swagger:parameters RoutePostSilenceTemplate RoutePutSilenceTemplate
+type PostableSilenceTemplateParams struct {
+	// in: body
+	Body PostableSilenceTemplate
+}
+
+// swagger:parameters RoutePostSilenceFromTemplate
+type PostableSilenceFromTemplateParams struct {
+	// in: body
+	Body PostableSilenceFromTemplate
+}
+
+// swagger:parameters RoutePostRecurringSilence RoutePutRecurringSilence
+type PostableRecurringSilenceParams struct {
+	// in: body
+	Body PostableRecurringSilence
+}
+
+// swagger:model
+type PostableSilenceTemplate struct {
+	Name     string        `json:"name"`
+	Matchers amv2.Matchers `json:"matchers"`
+	Comment  string        `json:"comment,omitempty"`
+	// Duration is the default duration of the silences created from the template.
+	Duration model.Duration `json:"duration,omitempty"`
+}
+
+// swagger:model
+type GettableSilenceTemplate struct {
+	UID       string         `json:"uid"`
+	Name      string         `json:"name"`
+	Matchers  amv2.Matchers  `json:"matchers"`
+	Comment   string         `json:"comment,omitempty"`
+	Duration  model.Duration `json:"duration,omitempty"`
+	CreatedBy string         `json:"createdBy"`
+	Updated   time.Time      `json:"updated"`
+}
+
+// swagger:model
+type GettableSilenceTemplates []GettableSilenceTemplate
+
+// swagger:model
+type PostableSilenceFromTemplate struct {
+	// StartsAt is the start of the silence, now if not set.
+	StartsAt time.Time `json:"startsAt,omitempty"`
+	// EndsAt is the end of the silence. If not set, the silence lasts for the duration of the template.
+	EndsAt time.Time `json:"endsAt,omitempty"`
+	// Comment is the comment of the silence, the one of the template if not set.
+	Comment string `json:"comment,omitempty"`
+}
+
+// swagger:model
+type SilenceCreatedResponse struct {
+	Message string `json:"message"`
+	ID      string `json:"id"`
+}
+
+// WeeklyWindow is a time window on some days of the week, from StartTime to EndTime in the HH:MM format.
+// The window ends on the next day if EndTime is not after StartTime.
+type WeeklyWindow struct {
+	// Weekdays are the lowercase names of the days of the week, such as sunday.
+	Weekdays  []string `json:"weekdays"`
+	StartTime string   `json:"startTime"`
+	EndTime   string   `json:"endTime"`
+}
+
+// swagger:model
+type PostableRecurringSilence struct {
+	// Matchers are the matchers of the silences, the ones of the template if a template is set.
+	Matchers amv2.Matchers `json:"matchers,omitempty"`
+	// TemplateUID is the UID of the silence template the matchers and the comment are copied from.
+	TemplateUID string `json:"templateUid,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	// Cron is a standard cron expression of the times the silences start. The silences last for Duration.
+	Cron     string         `json:"cron,omitempty"`
+	Duration model.Duration `json:"duration,omitempty"`
+	// WeeklyWindows are the windows during which the silences are active, if Cron is not set.
+	WeeklyWindows []WeeklyWindow `json:"weeklyWindows,omitempty"`
+	// Timezone is the name of the location of the times of the schedule, UTC if not set.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// swagger:model
+type GettableRecurringSilence struct {
+	UID           string         `json:"uid"`
+	Matchers      amv2.Matchers  `json:"matchers"`
+	Comment       string         `json:"comment,omitempty"`
+	Cron          string         `json:"cron,omitempty"`
+	Duration      model.Duration `json:"duration,omitempty"`
+	WeeklyWindows []WeeklyWindow `json:"weeklyWindows,omitempty"`
+	Timezone      string         `json:"timezone,omitempty"`
+	CreatedBy     string         `json:"createdBy"`
+	Updated       time.Time      `json:"updated"`
+	// NextStartsAt and NextEndsAt are the bounds of the next, or current, silence.
+	NextStartsAt time.Time `json:"nextStartsAt"`
+	NextEndsAt   time.Time `json:"nextEndsAt"`
+}
+
+// swagger:model
+type GettableRecurringSilences []GettableRecurringSilence
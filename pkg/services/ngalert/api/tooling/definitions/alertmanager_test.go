@@ -5,8 +5,10 @@ import (
 	"io/ioutil"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -339,6 +341,251 @@ func Test_ApiAlertingConfig_Marshaling(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			desc: "success graf escalations",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:    "graf",
+								Escalations: []*Escalation{{After: model.Duration(15 * time.Minute), Receiver: "on-call"}},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+					{
+						Receiver: config.Receiver{
+							Name: "on-call",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "failure graf undefined escalation receiver",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:    "graf",
+								Escalations: []*Escalation{{After: model.Duration(15 * time.Minute), Receiver: "unmentioned"}},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+					{
+						Receiver: config.Receiver{
+							Name: "on-call",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "failure graf escalation without delay",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:    "graf",
+								Escalations: []*Escalation{{Receiver: "on-call"}},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+					{
+						Receiver: config.Receiver{
+							Name: "on-call",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "success graf template matchers",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:         "graf",
+								TemplateMatchers: []*TemplateMatcher{{Template: "{{ .Labels.grafana_folder | toLower }}", Type: "=~", Value: "team-.*"}},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "failure graf template matcher with invalid template",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:         "graf",
+								TemplateMatchers: []*TemplateMatcher{{Template: "{{ .Labels.grafana_folder", Type: "=", Value: "team-a"}},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "failure graf template matcher with invalid type",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:         "graf",
+								TemplateMatchers: []*TemplateMatcher{{Template: "{{ .Labels.grafana_folder }}", Type: "==", Value: "team-a"}},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "success graf mute time intervals",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:          "graf",
+								MuteTimeIntervals: []string{"weekends"},
+							},
+						},
+					},
+					MuteTimeIntervals: []config.MuteTimeInterval{
+						{
+							Name: "weekends",
+							TimeIntervals: []timeinterval.TimeInterval{
+								{Weekdays: []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 6, End: 6}}, {InclusiveRange: timeinterval.InclusiveRange{Begin: 0, End: 0}}}},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "failure graf undefined mute time interval",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:          "graf",
+								MuteTimeIntervals: []string{"weekends"},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			encoded, err := json.Marshal(tc.input)
@@ -72,13 +72,13 @@ type NamespaceConfig struct {
 	Body PostableRuleGroupConfig
 }
 
-// swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig
+// swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetNamespaceRulesExport RoutePostRulesImport
 type PathNamespaceConfig struct {
 	// in: path
 	Namespace string
 }
 
-// swagger:parameters RouteGetRulegGroupConfig RouteDeleteRuleGroupConfig
+// swagger:parameters RouteGetRulegGroupConfig RouteDeleteRuleGroupConfig RouteGetRuleGroupExport
 type PathRouleGroupConfig struct {
 	// in: path
 	Namespace string
@@ -197,7 +197,7 @@ func (c *GettableRuleGroupConfig) validate() error {
 type ApiRuleNode struct {
 	Record      string            `yaml:"record,omitempty" json:"record,omitempty"`
 	Alert       string            `yaml:"alert,omitempty" json:"alert,omitempty"`
-	Expr        string            `yaml:"expr" json:"expr"`
+	Expr        string            `yaml:"expr,omitempty" json:"expr"`
 	For         model.Duration    `yaml:"for,omitempty" json:"for,omitempty"`
 	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
 	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
@@ -239,10 +239,8 @@ func (n *PostableExtendedRuleNode) validate() error {
 		return fmt.Errorf("cannot have empty rule")
 	}
 
-	if n.GrafanaManagedAlert != nil {
-		if n.ApiRuleNode != nil && (n.ApiRuleNode.Expr != "" || n.ApiRuleNode.Record != "") {
-			return fmt.Errorf("cannot have both Prometheus style rules and Grafana rules together")
-		}
+	if n.GrafanaManagedAlert != nil && n.ApiRuleNode != nil {
+		return validateGrafanaRuleNode(n.ApiRuleNode)
 	}
 	return nil
 }
@@ -275,9 +273,25 @@ func (n *GettableExtendedRuleNode) validate() error {
 		return fmt.Errorf("cannot have empty rule")
 	}
 
-	if n.GrafanaManagedAlert != nil {
-		if n.ApiRuleNode != nil && (n.ApiRuleNode.Expr != "" || n.ApiRuleNode.Record != "") {
-			return fmt.Errorf("cannot have both Prometheus style rules and Grafana rules together")
+	if n.GrafanaManagedAlert != nil && n.ApiRuleNode != nil {
+		return validateGrafanaRuleNode(n.ApiRuleNode)
+	}
+	return nil
+}
+
+// validateGrafanaRuleNode validates the Prometheus style properties of a Grafana managed rule.
+// Grafana recording rules set the record property but never an expression, as the series
+// to record is the result of the condition of the Grafana managed rule.
+func validateGrafanaRuleNode(n *ApiRuleNode) error {
+	if n.Expr != "" {
+		return fmt.Errorf("cannot have both Prometheus style rules and Grafana rules together")
+	}
+	if n.Record != "" {
+		if !model.IsValidMetricName(model.LabelValue(n.Record)) {
+			return fmt.Errorf("invalid recording rule name: %s", n.Record)
+		}
+		if n.For != 0 {
+			return fmt.Errorf("invalid field 'for' in recording rule")
 		}
 	}
 	return nil
@@ -301,12 +315,14 @@ const (
 
 // swagger:model
 type PostableGrafanaRule struct {
-	Title        string              `json:"title" yaml:"title"`
-	Condition    string              `json:"condition" yaml:"condition"`
-	Data         []models.AlertQuery `json:"data" yaml:"data"`
-	UID          string              `json:"uid" yaml:"uid"`
-	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	Title          string              `json:"title" yaml:"title"`
+	Condition      string              `json:"condition" yaml:"condition"`
+	Data           []models.AlertQuery `json:"data" yaml:"data"`
+	UID            string              `json:"uid" yaml:"uid"`
+	NoDataState    NoDataState         `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState   ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	IsPaused       bool                `json:"is_paused" yaml:"is_paused"`
+	ComputedLabels bool                `json:"computed_labels,omitempty" yaml:"computed_labels,omitempty"`
 }
 
 // swagger:model
@@ -325,4 +341,8 @@ type GettableGrafanaRule struct {
 	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
 	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	IsPaused        bool                `json:"is_paused" yaml:"is_paused"`
+	ComputedLabels  bool                `json:"computed_labels" yaml:"computed_labels"`
+	// Provenance is set if the rule is provisioned, in which case it cannot be changed from the API.
+	Provenance models.Provenance `json:"provenance,omitempty" yaml:"provenance,omitempty"`
 }
@@ -39,13 +39,28 @@ func Test_Rule_Marshaling(t *testing.T) {
 			err: true,
 		},
 		{
-			desc: "failure mixed with record lotex rule",
+			desc: "success grafana recording rule",
+			input: PostableExtendedRuleNode{
+				ApiRuleNode:         &ApiRuleNode{Record: "job:requests:rate5m"},
+				GrafanaManagedAlert: &PostableGrafanaRule{},
+			},
+		},
+		{
+			desc: "failure grafana recording rule with invalid metric name",
 			input: PostableExtendedRuleNode{
 				ApiRuleNode:         &ApiRuleNode{Record: "<string>"},
 				GrafanaManagedAlert: &PostableGrafanaRule{},
 			},
 			err: true,
 		},
+		{
+			desc: "failure grafana recording rule with for",
+			input: PostableExtendedRuleNode{
+				ApiRuleNode:         &ApiRuleNode{Record: "job:requests:rate5m", For: dur},
+				GrafanaManagedAlert: &PostableGrafanaRule{},
+			},
+			err: true,
+		},
 		{
 			desc: "grafana with for, annotation and label properties",
 			input: PostableExtendedRuleNode{
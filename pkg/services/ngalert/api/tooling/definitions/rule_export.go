@@ -0,0 +1,58 @@
+package definitions
+
+// swagger:route GET /api/ruler/grafana/api/v1/export/rules/{Namespace} rule_export RouteGetNamespaceRulesExport
+//
+// Export the rule groups of a namespace as a Prometheus rule file
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: RuleFileExport
+//       403: ForbiddenError
+//       404: NotFound
+
+// swagger:route GET /api/ruler/grafana/api/v1/export/rules/{Namespace}/{Groupname} rule_export RouteGetRuleGroupExport
+//
+// Export a rule group as a Prometheus rule file
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: RuleFileExport
+//       403: ForbiddenError
+//       404: NotFound
+
+// swagger:route POST /api/ruler/grafana/api/v1/import/rules/{Namespace} rule_export RoutePostRulesImport
+//
+// Import the rule groups of a Prometheus rule file into a namespace. Rule groups with the same name are only replaced with overwrite.
+//
+//     Consumes:
+//     - application/yaml
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       403: ForbiddenError
+
+// RuleFileExport is a Prometheus rule file. Rules that can't be translated to PromQL are
+// exported as Grafana managed rules under the grafana_alert key.
+// swagger:response RuleFileExport
+type RuleFileExport struct {
+	// in:body
+	Body string
+}
+
+// swagger:parameters RoutePostRulesImport
+type RulesImportParams struct {
+	// The UID of the Prometheus data source that the imported Prometheus rules query.
+	// Not required if the file only has Grafana managed rules.
+	// in: query
+	DatasourceUID string `json:"datasource_uid"`
+	// Replace the rule groups of the namespace that have the same name as rule groups of the file.
+	// in: query
+	Overwrite bool `json:"overwrite"`
+	// in: body
+	Body string
+}
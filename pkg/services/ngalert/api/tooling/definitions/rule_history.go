@@ -0,0 +1,84 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/ruler/grafana/api/v1/rule/{RuleUID}/versions rule_history RouteGetRuleVersions
+//
+// List the versions of an alert rule, newest first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionsResponse
+//       404: Failure
+
+// swagger:route GET /api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff rule_history RouteGetRuleVersionsDiff
+//
+// Get the difference between two versions of an alert rule
+//
+//     Produces:
+//     - application/json
+//     - text/html
+//
+//     Responses:
+//       200: RuleVersionsDiffResponse
+//       400: ValidationError
+//       404: Failure
+
+// swagger:route POST /api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore rule_history RouteRestoreRuleVersion
+//
+// Restore a version of an alert rule as its new version
+//
+//     Responses:
+//       202: Ack
+//       404: Failure
+
+// swagger:parameters RouteGetRuleVersions RouteGetRuleVersionsDiff RouteRestoreRuleVersion
+type RuleUIDParam struct {
+	// in: path
+	RuleUID string
+}
+
+// swagger:parameters RouteRestoreRuleVersion
+type RuleVersionParam struct {
+	// in: path
+	Version int64
+}
+
+// swagger:parameters RouteGetRuleVersionsDiff
+type RuleVersionsDiffParams struct {
+	// The version to compare from.
+	// in: query
+	Base int64 `json:"base"`
+	// The version to compare to.
+	// in: query
+	New int64 `json:"new"`
+	// in: query
+	// enum: basic,json,delta
+	// default: delta
+	DiffType string `json:"diffType"`
+}
+
+// swagger:model
+type RuleVersionsResponse []GettableRuleVersion
+
+// RuleVersionsDiffResponse is the delta of the two versions when the diff type is delta
+// and the HTML rendering of the difference otherwise.
+// swagger:model
+type RuleVersionsDiffResponse map[string]interface{}
+
+// swagger:model
+type GettableRuleVersion struct {
+	Version int64 `json:"version"`
+	// ParentVersion is the version the version was created from, 0 for the first version.
+	ParentVersion int64 `json:"parentVersion"`
+	// RestoredFrom is the version the version was restored from, 0 if it was not restored.
+	RestoredFrom int64     `json:"restoredFrom"`
+	Created      time.Time `json:"created"`
+	// CreatedBy is the login of the user who created the version, empty if unknown.
+	CreatedBy string                   `json:"createdBy"`
+	Rule      GettableExtendedRuleNode `json:"rule"`
+}
@@ -0,0 +1,51 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/v1/ngalert/state_history state_history RouteGetStateHistory
+//
+// List the state transitions of the alert instances, newest first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryResponse
+//       400: ValidationError
+
+// swagger:parameters RouteGetStateHistory
+type StateHistoryParams struct {
+	// Only return the transitions of the alert rule with this UID.
+	// in: query
+	RuleUID string `json:"ruleUID"`
+	// Only return the transitions of the alert instances with these labels, formatted as name=value.
+	// in: query
+	Labels []string `json:"labels"`
+	// Return the transitions from this time, in epoch milliseconds.
+	// in: query
+	From int64 `json:"from"`
+	// Return the transitions until this time, in epoch milliseconds.
+	// in: query
+	To int64 `json:"to"`
+	// in: query
+	// default: 100
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type StateHistoryResponse []GettableStateHistory
+
+// GettableStateHistory is a transition of an alert instance from one state to another.
+type GettableStateHistory struct {
+	RuleUID       string            `json:"ruleUID"`
+	RuleTitle     string            `json:"ruleTitle"`
+	Labels        map[string]string `json:"labels"`
+	PreviousState string            `json:"previousState"`
+	State         string            `json:"state"`
+	// Values contains the RefID and value of the reduce and math expressions of the evaluation that caused the transition.
+	Values map[string]*float64 `json:"values,omitempty"`
+	Error  string              `json:"error,omitempty"`
+	Time   time.Time           `json:"time"`
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 )
 
@@ -38,6 +39,20 @@ import (
 //     Responses:
 //       200: EvalQueriesResponse
 
+// swagger:route Post /api/v1/rule/backtest testing RouteBacktestConfig
+//
+// Backtest rule: evaluate a rule at every interval of a time range and return the states of its alert instances
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -70,6 +85,47 @@ type EvalQueriesPayload struct {
 	Now  time.Time           `json:"now"`
 }
 
+// swagger:parameters RouteBacktestConfig
+type BacktestConfigRequest struct {
+	// in:body
+	Body BacktestConfig
+}
+
+// swagger:model
+type BacktestConfig struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Interval is the evaluation interval of the rule. It defaults to one minute.
+	Interval model.Duration `json:"interval,omitempty"`
+
+	Condition    string              `json:"condition"`
+	Data         []models.AlertQuery `json:"data"`
+	For          model.Duration      `json:"for,omitempty"`
+	Labels       map[string]string   `json:"labels,omitempty"`
+	NoDataState  NoDataState         `json:"no_data_state,omitempty"`
+	ExecErrState ExecutionErrorState `json:"exec_err_state,omitempty"`
+}
+
+// swagger:model
+type BacktestResult struct {
+	Instances []BacktestInstance `json:"instances"`
+}
+
+// BacktestInstance is the timeline of the states of an alert instance.
+type BacktestInstance struct {
+	Labels   map[string]string `json:"labels"`
+	Timeline []BacktestStep    `json:"timeline"`
+}
+
+// BacktestStep is the state of an alert instance after an evaluation.
+type BacktestStep struct {
+	Time  time.Time `json:"time"`
+	State string    `json:"state"`
+	// Values contains the RefID and value of the reduce and math expressions of the evaluation.
+	Values map[string]*float64 `json:"values,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
 	type plain TestRulePayload
 	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
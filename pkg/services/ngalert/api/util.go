@@ -200,6 +200,14 @@ func validateQueriesAndExpressions(data []ngmodels.AlertQuery, user *models.Sign
 	}
 
 	for _, query := range data {
+		if query.IsRuleReference() {
+			if _, err := query.GetRuleReference(); err != nil {
+				return nil, err
+			}
+			refIDs[query.RefID] = struct{}{}
+			continue
+		}
+
 		datasourceUID, err := query.GetDatasource()
 		if err != nil {
 			return nil, err
@@ -223,6 +231,23 @@ func validateQueriesAndExpressions(data []ngmodels.AlertQuery, user *models.Sign
 	return refIDs, nil
 }
 
+// validateRuleReferences checks that the queries only reference the provided alert rules.
+func validateRuleReferences(data []ngmodels.AlertQuery, ruleUIDs map[string]struct{}) error {
+	for _, query := range data {
+		if !query.IsRuleReference() {
+			continue
+		}
+		ref, err := query.GetRuleReference()
+		if err != nil {
+			return err
+		}
+		if _, ok := ruleUIDs[ref.RuleUID]; !ok {
+			return fmt.Errorf("query %s references alert rule %s which is not evaluated before it in the rule group", query.RefID, ref.RuleUID)
+		}
+	}
+	return nil
+}
+
 func conditionEval(c *models.ReqContext, cmd ngmodels.EvalAlertConditionCommand, datasourceCache datasources.CacheService, dataService *tsdb.Service, cfg *setting.Cfg, log log.Logger) response.Response {
 	evalCond := ngmodels.Condition{
 		Condition: cmd.Condition,
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestToMacaronPath(t *testing.T) {
@@ -25,3 +29,21 @@ func TestToMacaronPath(t *testing.T) {
 		assert.Equal(t, tc.expectedOutputPath, outputPath)
 	}
 }
+
+func TestValidateRuleReferences(t *testing.T) {
+	data := []ngmodels.AlertQuery{
+		{
+			RefID:         "A",
+			DatasourceUID: ngmodels.RuleReferenceDatasourceUID,
+			Model:         json.RawMessage(`{"ruleUid": "previous"}`),
+		},
+		{
+			RefID:         "B",
+			DatasourceUID: "-100",
+			Model:         json.RawMessage(`{"type": "math", "expression": "$A > 0"}`),
+		},
+	}
+
+	require.NoError(t, validateRuleReferences(data, map[string]struct{}{"previous": {}}))
+	require.EqualError(t, validateRuleReferences(data, map[string]struct{}{}), "query A references alert rule previous which is not evaluated before it in the rule group")
+}
@@ -0,0 +1,166 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+var (
+	// ErrInvalidTimeRange is returned when the time range of a backtest is invalid.
+	ErrInvalidTimeRange = errors.New("invalid time range")
+	// ErrTooManyEvaluations is returned when a backtest would evaluate the alert rule more times than the
+	// maximum number of evaluations of the engine.
+	ErrTooManyEvaluations = errors.New("too many evaluations")
+	// ErrRuleReferencesNotSupported is returned when the alert rule references other alert rules.
+	ErrRuleReferencesNotSupported = errors.New("alert rules that reference other alert rules cannot be backtested")
+)
+
+// conditionEvaluator evaluates the condition of an alert rule at a point in time.
+type conditionEvaluator func(condition *models.Condition, now time.Time) (eval.Results, error)
+
+// Engine replays the evaluations of an alert rule over a time range.
+type Engine struct {
+	log            log.Logger
+	evaluate       conditionEvaluator
+	maxEvaluations int
+}
+
+// NewEngine returns a backtesting engine that evaluates alert rules with the evaluator and the data service,
+// at most maxEvaluations times per backtest.
+func NewEngine(evaluator eval.Evaluator, dataService *tsdb.Service, maxEvaluations int, logger log.Logger) *Engine {
+	return &Engine{
+		log:            logger,
+		maxEvaluations: maxEvaluations,
+		evaluate: func(condition *models.Condition, now time.Time) (eval.Results, error) {
+			return evaluator.ConditionEval(condition, now, dataService)
+		},
+	}
+}
+
+// Step is the state of an alert instance after an evaluation.
+type Step struct {
+	Time  time.Time
+	State eval.State
+	// Error is the error of the evaluation, if the state is Error.
+	Error error
+	// Values contains the values of the reduce and math expressions, by RefID. NaN and infinite values are nil.
+	Values map[string]*float64
+}
+
+// Timeline is the states of an alert instance over the time range of a backtest.
+type Timeline struct {
+	Labels map[string]string
+	Steps  []Step
+}
+
+// Test evaluates the alert rule at every interval from the start to the end of the time range, both included.
+// The evaluation results are processed by a state manager of its own, so the timelines show the states the
+// alert instances would have had, including the pending period and the handling of no data and errors.
+// It returns a timeline per alert instance, sorted by labels.
+func (e *Engine) Test(ctx context.Context, rule *models.AlertRule, from, to time.Time) ([]Timeline, error) {
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s: it must be positive", interval)
+	}
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return nil, fmt.Errorf("%w: from %s must be before to %s", ErrInvalidTimeRange, from, to)
+	}
+	if n := int64(to.Sub(from)/interval) + 1; n > int64(e.maxEvaluations) {
+		return nil, fmt.Errorf("%w: the backtest would evaluate the alert rule %d times, it is limited to %d evaluations", ErrTooManyEvaluations, n, e.maxEvaluations)
+	}
+	for _, q := range rule.Data {
+		if q.IsRuleReference() {
+			return nil, ErrRuleReferencesNotSupported
+		}
+	}
+
+	// the states of the backtest are neither persisted nor exposed in the metrics
+	st := state.NewManager(e.log, metrics.NewNGAlert(prometheus.NewRegistry()).GetStateMetrics(), nil, nil, noopInstanceStore{}, nil)
+	defer st.Close()
+	// stale states are found by the time of the evaluation, as in production, instead of the current time
+	clk := clock.NewMock()
+	st.Clock = clk
+
+	timelines := make(map[string]*Timeline)
+	for now := from; !now.After(to); now = now.Add(interval) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		clk.Set(now)
+
+		data, err := st.WithLoadedDimensions(rule)
+		if err != nil {
+			return nil, err
+		}
+		condition := models.Condition{
+			Condition: rule.Condition,
+			OrgID:     rule.OrgID,
+			Data:      data,
+		}
+		results, err := e.evaluate(&condition, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate the alert rule at %s: %w", now, err)
+		}
+
+		for _, s := range st.ProcessEvalResults(ctx, rule, results, "") {
+			timeline, ok := timelines[s.CacheId]
+			if !ok {
+				labels := s.Labels.Copy()
+				delete(labels, models.RuleUIDLabel)
+				delete(labels, models.NamespaceUIDLabel)
+				timeline = &Timeline{Labels: labels}
+				timelines[s.CacheId] = timeline
+			}
+			step := Step{Time: now, State: s.State, Error: s.Error}
+			if len(s.Results) > 0 {
+				last := s.Results[len(s.Results)-1]
+				if len(last.Values) > 0 {
+					step.Values = make(map[string]*float64, len(last.Values))
+					for refID, v := range last.Values {
+						// NaN and infinite values cannot be encoded as JSON
+						if v.Value != nil && !math.IsNaN(*v.Value) && !math.IsInf(*v.Value, 0) {
+							step.Values[refID] = v.Value
+						} else {
+							step.Values[refID] = nil
+						}
+					}
+				}
+			}
+			timeline.Steps = append(timeline.Steps, step)
+		}
+	}
+
+	keys := make([]string, 0, len(timelines))
+	for k := range timelines {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := make([]Timeline, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, *timelines[k])
+	}
+	return result, nil
+}
+
+// noopInstanceStore is the instance store of the state manager of a backtest, which does not persist anything.
+type noopInstanceStore struct{}
+
+func (noopInstanceStore) GetAlertInstance(_ *models.GetAlertInstanceQuery) error     { return nil }
+func (noopInstanceStore) ListAlertInstances(_ *models.ListAlertInstancesQuery) error { return nil }
+func (noopInstanceStore) SaveAlertInstance(_ *models.SaveAlertInstanceCommand) error { return nil }
+func (noopInstanceStore) FetchOrgIds() ([]int64, error)                              { return nil, nil }
+func (noopInstanceStore) DeleteAlertInstance(_ int64, _, _ string) error             { return nil }
@@ -0,0 +1,145 @@
+package backtesting
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEngine_Test(t *testing.T) {
+	from := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	newRule := func() *models.AlertRule {
+		return &models.AlertRule{
+			OrgID:           1,
+			UID:             "backtest",
+			Title:           "backtest",
+			Condition:       "A",
+			Data:            []models.AlertQuery{{RefID: "A", DatasourceUID: "-100", Model: json.RawMessage(`{}`)}},
+			IntervalSeconds: 60,
+			For:             time.Minute,
+			NoDataState:     models.NoData,
+			ExecErrState:    models.AlertingErrState,
+		}
+	}
+
+	// missing is the state of an instance at the minutes it has no result
+	const missing eval.State = -1
+
+	// newEngine returns an engine that evaluates the condition to the states of the instances at each minute
+	newEngine := func(states map[string][]eval.State) *Engine {
+		return &Engine{
+			log:            log.New("backtesting test"),
+			maxEvaluations: 10,
+			evaluate: func(_ *models.Condition, now time.Time) (eval.Results, error) {
+				i := int(now.Sub(from) / time.Minute)
+				var results eval.Results
+				for instance, s := range states {
+					if i >= len(s) || s[i] == missing {
+						continue
+					}
+					results = append(results, eval.Result{Instance: data.Labels{"instance": instance}, State: s[i], EvaluatedAt: now})
+				}
+				return results, nil
+			},
+		}
+	}
+
+	stateTimeline := func(timeline Timeline) []eval.State {
+		states := make([]eval.State, 0, len(timeline.Steps))
+		for _, s := range timeline.Steps {
+			states = append(states, s.State)
+		}
+		return states
+	}
+
+	t.Run("it should apply the pending period and the no data state", func(t *testing.T) {
+		engine := newEngine(map[string][]eval.State{
+			"a": {eval.Normal, eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal, eval.NoData},
+			"b": {eval.Alerting, eval.Normal, eval.Normal, eval.Normal, eval.Normal, eval.Normal},
+		})
+
+		timelines, err := engine.Test(context.Background(), newRule(), from, from.Add(5*time.Minute))
+		require.NoError(t, err)
+		require.Len(t, timelines, 2)
+
+		require.Equal(t, map[string]string{"alertname": "backtest", "instance": "a"}, timelines[0].Labels)
+		require.Equal(t, []eval.State{eval.Normal, eval.Pending, eval.Pending, eval.Alerting, eval.Normal, eval.NoData}, stateTimeline(timelines[0]))
+		require.Equal(t, []eval.State{eval.Pending, eval.Normal, eval.Normal, eval.Normal, eval.Normal, eval.Normal}, stateTimeline(timelines[1]))
+		for i, s := range timelines[0].Steps {
+			require.Equal(t, from.Add(time.Duration(i)*time.Minute), s.Time)
+		}
+	})
+
+	t.Run("it should keep the states of instances that are missing for less than two intervals", func(t *testing.T) {
+		rule := newRule()
+		rule.For = 2 * time.Minute
+		engine := newEngine(map[string][]eval.State{
+			"a": {eval.Alerting, eval.Alerting, missing, eval.Alerting},
+			"b": {eval.Normal, eval.Normal, eval.Normal, eval.Normal},
+		})
+
+		timelines, err := engine.Test(context.Background(), rule, from, from.Add(3*time.Minute))
+		require.NoError(t, err)
+		require.Len(t, timelines, 2)
+		require.Equal(t, []eval.State{eval.Pending, eval.Pending, eval.Alerting}, stateTimeline(timelines[0]))
+		require.Equal(t, from.Add(3*time.Minute), timelines[0].Steps[2].Time)
+	})
+
+	t.Run("it should alert on errors", func(t *testing.T) {
+		engine := newEngine(map[string][]eval.State{"a": {eval.Normal, eval.Error}})
+
+		timelines, err := engine.Test(context.Background(), newRule(), from, from.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, timelines, 1)
+		require.Equal(t, []eval.State{eval.Normal, eval.Alerting}, stateTimeline(timelines[0]))
+	})
+
+	t.Run("it should drop the values that cannot be encoded as JSON", func(t *testing.T) {
+		engine := newEngine(nil)
+		finite, nan, inf := 1.0, math.NaN(), math.Inf(1)
+		engine.evaluate = func(_ *models.Condition, now time.Time) (eval.Results, error) {
+			return eval.Results{{
+				Instance:    data.Labels{"instance": "a"},
+				State:       eval.Normal,
+				EvaluatedAt: now,
+				Values: map[string]eval.NumberValueCapture{
+					"A": {Value: &finite},
+					"B": {Value: &nan},
+					"C": {Value: &inf},
+				},
+			}}, nil
+		}
+
+		timelines, err := engine.Test(context.Background(), newRule(), from, from.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, timelines, 1)
+		require.Equal(t, map[string]*float64{"A": &finite, "B": nil, "C": nil}, timelines[0].Steps[0].Values)
+		_, err = json.Marshal(timelines)
+		require.NoError(t, err)
+	})
+
+	t.Run("it should fail on invalid requests", func(t *testing.T) {
+		engine := newEngine(nil)
+
+		_, err := engine.Test(context.Background(), newRule(), from, from)
+		require.ErrorIs(t, err, ErrInvalidTimeRange)
+
+		_, err = engine.Test(context.Background(), newRule(), from, from.Add(10*time.Minute))
+		require.ErrorIs(t, err, ErrTooManyEvaluations)
+
+		rule := newRule()
+		rule.Data = []models.AlertQuery{{RefID: "A", DatasourceUID: models.RuleReferenceDatasourceUID, Model: json.RawMessage(`{"ruleUid": "rule"}`)}}
+		_, err = engine.Test(context.Background(), rule, from, from.Add(time.Minute))
+		require.ErrorIs(t, err, ErrRuleReferencesNotSupported)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/expr/classic"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 
@@ -24,6 +25,15 @@ import (
 type Evaluator struct {
 	Cfg *setting.Cfg
 	Log log.Logger
+	// RuleReferences resolves the queries that reference other alert rules.
+	// Such queries cannot be evaluated if it is nil.
+	RuleReferences RuleReferenceResolver
+}
+
+// RuleReferenceResolver resolves the queries that reference other alert rules.
+type RuleReferenceResolver interface {
+	// GetRuleReferenceValues returns the latest values of the alert instances of the referenced alert rule.
+	GetRuleReferenceValues(orgID int64, ref models.RuleReference) []NumberValueCapture
 }
 
 // invalidEvalResultFormatError is an error for invalid format of the alert definition evaluation results.
@@ -112,6 +122,7 @@ type AlertExecCtx struct {
 	OrgID              int64
 	ExpressionsEnabled bool
 	Log                log.Logger
+	RuleReferences     RuleReferenceResolver
 
 	Ctx context.Context
 }
@@ -143,7 +154,7 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 			return nil, fmt.Errorf("failed to retrieve maxDatapoints from the model: %w", err)
 		}
 
-		req.Queries = append(req.Queries, expr.Query{
+		query := expr.Query{
 			TimeRange: expr.TimeRange{
 				From: q.RelativeTimeRange.ToTimeRange(now).From,
 				To:   q.RelativeTimeRange.ToTimeRange(now).To,
@@ -154,11 +165,39 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 			RefID:         q.RefID,
 			MaxDataPoints: maxDatapoints,
 			QueryType:     q.QueryType,
-		})
+		}
+
+		if q.IsRuleReference() {
+			query.Results, err = resolveRuleReference(ctx, &q)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		req.Queries = append(req.Queries, query)
 	}
 	return req, nil
 }
 
+// resolveRuleReference returns the latest values of the alert rule referenced by the query.
+func resolveRuleReference(ctx AlertExecCtx, q *models.AlertQuery) (*mathexp.Results, error) {
+	ref, err := q.GetRuleReference()
+	if err != nil {
+		return nil, err
+	}
+	if ctx.RuleReferences == nil {
+		return nil, fmt.Errorf("query %s references alert rule %s: alert rule references can only be evaluated with their rule group", q.RefID, ref.RuleUID)
+	}
+
+	results := &mathexp.Results{Values: mathexp.Values{}}
+	for _, v := range ctx.RuleReferences.GetRuleReferenceValues(ctx.OrgID, ref) {
+		n := mathexp.NewNumber(q.RefID, v.Labels)
+		n.SetValue(v.Value)
+		results.Values = append(results.Values, n)
+	}
+	return results, nil
+}
+
 type NumberValueCapture struct {
 	Var    string // RefID
 	Labels data.Labels
@@ -435,7 +474,7 @@ func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, da
 	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Cfg.UnifiedAlerting.EvaluationTimeout)
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log, RuleReferences: e.RuleReferences}
 
 	execResult := executeCondition(alertExecCtx, condition, now, dataService)
 
@@ -443,12 +482,72 @@ func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, da
 	return evalResults, nil
 }
 
+// ConditionValues executes conditions and returns the values of the condition
+// without evaluating them into alert instance states. It is used by recording rules.
+func (e *Evaluator) ConditionValues(condition *models.Condition, now time.Time, dataService *tsdb.Service) ([]NumberValueCapture, error) {
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Cfg.UnifiedAlerting.EvaluationTimeout)
+	defer cancelFn()
+
+	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log, RuleReferences: e.RuleReferences}
+
+	execResult := executeCondition(alertExecCtx, condition, now, dataService)
+
+	return extractConditionValues(execResult)
+}
+
+// extractConditionValues takes the ExecutionResults and returns the value of each Frame.
+//
+// Frames follow the same rules as in evaluateExecutionResult: each non-empty Frame must be
+// a single Field of type []*float64 and of length 1. Empty Frames are skipped and any other
+// Frame schema results in an error.
+func extractConditionValues(execResults ExecutionResults) ([]NumberValueCapture, error) {
+	if execResults.Error != nil {
+		return nil, execResults.Error
+	}
+
+	values := make([]NumberValueCapture, 0, len(execResults.Results))
+	for _, f := range execResults.Results {
+		rowLen, err := f.RowLen()
+		if err != nil {
+			return nil, &invalidEvalResultFormatError{refID: f.RefID, reason: "unable to get frame row length", err: err}
+		}
+
+		if len(f.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)) > 0 {
+			return nil, &invalidEvalResultFormatError{refID: f.RefID, reason: "looks like time series data, only reduced data can be recorded."}
+		}
+
+		if rowLen == 0 {
+			continue
+		}
+
+		if rowLen > 1 {
+			return nil, &invalidEvalResultFormatError{refID: f.RefID, reason: fmt.Sprintf("unexpected row length: %d instead of 0 or 1", rowLen)}
+		}
+
+		if len(f.Fields) > 1 {
+			return nil, &invalidEvalResultFormatError{refID: f.RefID, reason: fmt.Sprintf("unexpected field length: %d instead of 1", len(f.Fields))}
+		}
+
+		if f.Fields[0].Type() != data.FieldTypeNullableFloat64 {
+			return nil, &invalidEvalResultFormatError{refID: f.RefID, reason: fmt.Sprintf("invalid field type: %s", f.Fields[0].Type())}
+		}
+
+		values = append(values, NumberValueCapture{
+			Var:    f.RefID,
+			Labels: f.Fields[0].Labels.Copy(),
+			Value:  f.Fields[0].At(0).(*float64), // type checked by data.FieldTypeNullableFloat64 above
+		})
+	}
+
+	return values, nil
+}
+
 // QueriesAndExpressionsEval executes queries and expressions and returns the result.
 func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
 	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Cfg.UnifiedAlerting.EvaluationTimeout)
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log, RuleReferences: e.RuleReferences}
 
 	execResult, err := executeQueriesAndExpressions(alertExecCtx, data, now, dataService)
 	if err != nil {
@@ -294,3 +294,87 @@ func TestEvaluateExecutionResult(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractConditionValues(t *testing.T) {
+	cases := []struct {
+		desc         string
+		execResults  ExecutionResults
+		expectValues []NumberValueCapture
+		expectError  string
+	}{
+		{
+			desc: "single instance returns its value",
+			execResults: ExecutionResults{
+				Results: []*data.Frame{
+					data.NewFrame("", data.NewField("", nil, []*float64{ptr.Float64(3)})),
+				},
+			},
+			expectValues: []NumberValueCapture{
+				{Labels: data.Labels{}, Value: ptr.Float64(3)},
+			},
+		},
+		{
+			desc: "multiple instances return their labels and values",
+			execResults: ExecutionResults{
+				Results: []*data.Frame{
+					data.NewFrame("", data.NewField("", data.Labels{"a": "b"}, []*float64{ptr.Float64(1)})),
+					data.NewFrame("", data.NewField("", data.Labels{"a": "c"}, []*float64{nil})),
+				},
+			},
+			expectValues: []NumberValueCapture{
+				{Labels: data.Labels{"a": "b"}, Value: ptr.Float64(1)},
+				{Labels: data.Labels{"a": "c"}},
+			},
+		},
+		{
+			desc: "empty frames are skipped",
+			execResults: ExecutionResults{
+				Results: []*data.Frame{
+					data.NewFrame(""),
+					data.NewFrame("", data.NewField("", data.Labels{"a": "b"}, []*float64{})),
+				},
+			},
+			expectValues: []NumberValueCapture{},
+		},
+		{
+			desc: "an execution error is returned",
+			execResults: ExecutionResults{
+				Error: fmt.Errorf("an execution error"),
+			},
+			expectError: "an execution error",
+		},
+		{
+			desc: "time series data is an error",
+			execResults: ExecutionResults{
+				Results: []*data.Frame{
+					data.NewFrame("",
+						data.NewField("", nil, []time.Time{{}}),
+						data.NewField("", nil, []*float64{ptr.Float64(1)}),
+					),
+				},
+			},
+			expectError: "invalid format of evaluation results for the alert definition : looks like time series data, only reduced data can be recorded.",
+		},
+		{
+			desc: "unsupported field type is an error",
+			execResults: ExecutionResults{
+				Results: []*data.Frame{
+					data.NewFrame("", data.NewField("", nil, []float64{3})),
+				},
+			},
+			expectError: "invalid format of evaluation results for the alert definition : invalid field type: []float64",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			values, err := extractConditionValues(tc.execResults)
+			if tc.expectError != "" {
+				require.EqualError(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectValues, values)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+const (
+	screenshotWidth  = 1000
+	screenshotHeight = 500
+)
+
+var (
+	// ErrNoPanel is returned when the alert rule is not linked to a panel.
+	ErrNoPanel = errors.New("the alert rule is not linked to a panel")
+	// ErrRendererNotAvailable is returned when the image renderer is not installed.
+	ErrRendererNotAvailable = errors.New("the image renderer is not available")
+)
+
+// ImageService captures the images of the panels alert rules are linked to.
+type ImageService interface {
+	// NewImage captures an image of the panel of the alert rule and returns its URL.
+	// It returns ErrNoPanel if the alert rule is not linked to a panel, and an empty URL
+	// if the image could not be stored anywhere the receivers can access it.
+	NewImage(ctx context.Context, rule *ngmodels.AlertRule) (string, error)
+}
+
+// ScreenshotImageService takes screenshots of the panels with the image renderer
+// and uploads them to the external image storage.
+type ScreenshotImageService struct {
+	renderService   rendering.Service
+	uploader        imguploader.ImageUploader
+	timeout         time.Duration
+	concurrentLimit int
+	log             log.Logger
+}
+
+// NewScreenshotImageService returns an image service that uploads the screenshots to the
+// external image storage configured in the `external_image_storage` section.
+func NewScreenshotImageService(renderService rendering.Service, timeout time.Duration, concurrentLimit int, logger log.Logger) (*ScreenshotImageService, error) {
+	uploader, err := imguploader.NewImageUploader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the image uploader: %w", err)
+	}
+	return &ScreenshotImageService{
+		renderService:   renderService,
+		uploader:        uploader,
+		timeout:         timeout,
+		concurrentLimit: concurrentLimit,
+		log:             logger,
+	}, nil
+}
+
+// NewImage takes a screenshot of the panel of the alert rule and uploads it.
+func (s *ScreenshotImageService) NewImage(ctx context.Context, rule *ngmodels.AlertRule) (string, error) {
+	path, err := panelPath(rule)
+	if err != nil {
+		return "", err
+	}
+	if !s.renderService.IsAvailable() {
+		return "", ErrRendererNotAvailable
+	}
+
+	start := time.Now()
+	result, err := s.renderService.Render(ctx, rendering.Opts{
+		Width:           screenshotWidth,
+		Height:          screenshotHeight,
+		Timeout:         s.timeout,
+		OrgID:           rule.OrgID,
+		OrgRole:         models.ROLE_ADMIN,
+		Path:            path,
+		ConcurrentLimit: s.concurrentLimit,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to take a screenshot of the panel: %w", err)
+	}
+	s.log.Debug("took a screenshot of the panel", "ruleUID", rule.UID, "path", path, "file", result.FilePath, "took", time.Since(start))
+
+	url, err := s.uploader.Upload(ctx, result.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload the screenshot of the panel: %w", err)
+	}
+	if url == "" {
+		s.log.Debug("the screenshot of the panel was not uploaded: no external image storage is configured", "ruleUID", rule.UID)
+	}
+	return url, nil
+}
+
+// panelPath returns the path of the page of the panel the alert rule is linked to.
+func panelPath(rule *ngmodels.AlertRule) (string, error) {
+	dashboardUID := rule.Annotations[ngmodels.DashboardUIDAnnotation]
+	panelID, err := strconv.ParseInt(rule.Annotations[ngmodels.PanelIDAnnotation], 10, 64)
+	if dashboardUID == "" || err != nil {
+		return "", ErrNoPanel
+	}
+	return fmt.Sprintf("d-solo/%s?orgId=%d&panelId=%d", dashboardUID, rule.OrgID, panelID), nil
+}
+
+// NoopImageService is the image service used when the screenshots are disabled.
+type NoopImageService struct{}
+
+// NewImage returns an empty URL.
+func (NoopImageService) NewImage(_ context.Context, _ *ngmodels.AlertRule) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,101 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+func TestScreenshotImageService(t *testing.T) {
+	rule := &ngmodels.AlertRule{
+		OrgID: 2,
+		UID:   "rule",
+		Annotations: map[string]string{
+			ngmodels.DashboardUIDAnnotation: "dashboard",
+			ngmodels.PanelIDAnnotation:      "5",
+		},
+	}
+
+	newService := func(renderer *fakeRenderService, uploader *fakeImageUploader) *ScreenshotImageService {
+		return &ScreenshotImageService{renderService: renderer, uploader: uploader, log: log.New("image test")}
+	}
+
+	t.Run("it should take a screenshot of the panel and upload it", func(t *testing.T) {
+		renderer := &fakeRenderService{available: true}
+		uploader := &fakeImageUploader{url: "https://images.example.com/image.png"}
+
+		url, err := newService(renderer, uploader).NewImage(context.Background(), rule)
+		require.NoError(t, err)
+		require.Equal(t, "https://images.example.com/image.png", url)
+		require.Equal(t, "d-solo/dashboard?orgId=2&panelId=5", renderer.opts.Path)
+		require.Equal(t, int64(2), renderer.opts.OrgID)
+		require.Equal(t, models.ROLE_ADMIN, renderer.opts.OrgRole)
+		require.Equal(t, "image.png", uploader.path)
+	})
+
+	t.Run("it should fail if the alert rule is not linked to a panel", func(t *testing.T) {
+		for _, annotations := range []map[string]string{
+			nil,
+			{ngmodels.DashboardUIDAnnotation: "dashboard"},
+			{ngmodels.DashboardUIDAnnotation: "dashboard", ngmodels.PanelIDAnnotation: "panel"},
+			{ngmodels.PanelIDAnnotation: "5"},
+		} {
+			rule := &ngmodels.AlertRule{OrgID: 1, Annotations: annotations}
+			_, err := newService(&fakeRenderService{available: true}, &fakeImageUploader{}).NewImage(context.Background(), rule)
+			require.ErrorIs(t, err, ErrNoPanel)
+		}
+	})
+
+	t.Run("it should fail if the image renderer is not available", func(t *testing.T) {
+		_, err := newService(&fakeRenderService{}, &fakeImageUploader{}).NewImage(context.Background(), rule)
+		require.ErrorIs(t, err, ErrRendererNotAvailable)
+	})
+
+	t.Run("it should fail if the screenshot fails", func(t *testing.T) {
+		renderErr := errors.New("timeout")
+		_, err := newService(&fakeRenderService{available: true, err: renderErr}, &fakeImageUploader{}).NewImage(context.Background(), rule)
+		require.ErrorIs(t, err, renderErr)
+	})
+}
+
+type fakeRenderService struct {
+	available bool
+	err       error
+	opts      rendering.Opts
+}
+
+func (s *fakeRenderService) IsAvailable() bool { return s.available }
+func (s *fakeRenderService) Version() string   { return "" }
+func (s *fakeRenderService) Render(_ context.Context, opts rendering.Opts) (*rendering.RenderResult, error) {
+	s.opts = opts
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &rendering.RenderResult{FilePath: "image.png"}, nil
+}
+func (s *fakeRenderService) RenderCSV(_ context.Context, _ rendering.CSVOpts) (*rendering.RenderCSVResult, error) {
+	return nil, nil
+}
+func (s *fakeRenderService) RenderErrorImage(_ error) (*rendering.RenderResult, error) {
+	return nil, nil
+}
+func (s *fakeRenderService) GetRenderUser(_ string) (*rendering.RenderUser, bool) {
+	return nil, false
+}
+
+type fakeImageUploader struct {
+	url  string
+	path string
+}
+
+func (u *fakeImageUploader) Upload(_ context.Context, path string) (string, error) {
+	u.path = path
+	return u.url, nil
+}
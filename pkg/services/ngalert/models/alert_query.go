@@ -6,12 +6,29 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr"
+	"gopkg.in/yaml.v3"
 )
 
 const defaultMaxDataPoints float64 = 43200 // 12 hours at 1sec interval
 const defaultIntervalMS float64 = 1000
 
+// RuleReferenceDatasourceUID is the fake datasource uid of the queries that reference
+// the latest state or values of another alert rule of the same rule group.
+const RuleReferenceDatasourceUID = "__alert_rule__"
+
+// RuleReference is the model of a query that references another alert rule.
+type RuleReference struct {
+	// RuleUID is the UID of the referenced alert rule. It must be evaluated
+	// before the referencing alert rule in their rule group.
+	RuleUID string `json:"ruleUid"`
+	// Expression is the RefID of the referenced expression of the alert rule.
+	// If empty, the query references the state of the alert instances of the alert rule:
+	// 1 if they are firing and 0 otherwise.
+	Expression string `json:"expression,omitempty"`
+}
+
 // Duration is a type used for marshalling durations.
 type Duration time.Duration
 
@@ -37,6 +54,19 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	}
 }
 
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).Seconds(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var v float64
+	if err := value.Decode(&v); err != nil {
+		return fmt.Errorf("invalid duration %v", value.Value)
+	}
+	*d = Duration(time.Duration(v) * time.Second)
+	return nil
+}
+
 // RelativeTimeRange is the per query start and end time
 // for requests.
 type RelativeTimeRange struct {
@@ -77,6 +107,55 @@ type AlertQuery struct {
 	modelProps map[string]interface{}
 }
 
+// yamlAlertQuery is the YAML representation of an AlertQuery. Unlike in JSON,
+// the model is a mapping rather than raw bytes.
+type yamlAlertQuery struct {
+	RefID             string                 `yaml:"refId"`
+	QueryType         string                 `yaml:"queryType,omitempty"`
+	RelativeTimeRange yamlRelativeTimeRange  `yaml:"relativeTimeRange"`
+	DatasourceUID     string                 `yaml:"datasourceUid"`
+	Model             map[string]interface{} `yaml:"model"`
+}
+
+type yamlRelativeTimeRange struct {
+	From Duration `yaml:"from"`
+	To   Duration `yaml:"to"`
+}
+
+func (aq AlertQuery) MarshalYAML() (interface{}, error) {
+	q := yamlAlertQuery{
+		RefID:             aq.RefID,
+		QueryType:         aq.QueryType,
+		RelativeTimeRange: yamlRelativeTimeRange(aq.RelativeTimeRange),
+		DatasourceUID:     aq.DatasourceUID,
+	}
+	if len(aq.Model) > 0 {
+		if err := json.Unmarshal(aq.Model, &q.Model); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal query model: %w", err)
+		}
+	}
+	return q, nil
+}
+
+func (aq *AlertQuery) UnmarshalYAML(value *yaml.Node) error {
+	var q yamlAlertQuery
+	if err := value.Decode(&q); err != nil {
+		return err
+	}
+	model, err := json.Marshal(q.Model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal query model: %w", err)
+	}
+	*aq = AlertQuery{
+		RefID:             q.RefID,
+		QueryType:         q.QueryType,
+		RelativeTimeRange: RelativeTimeRange(q.RelativeTimeRange),
+		DatasourceUID:     q.DatasourceUID,
+		Model:             model,
+	}
+	return nil
+}
+
 func (aq *AlertQuery) setModelProps() error {
 	aq.modelProps = make(map[string]interface{})
 	err := json.Unmarshal(aq.Model, &aq.modelProps)
@@ -92,6 +171,54 @@ func (aq *AlertQuery) IsExpression() (bool, error) {
 	return aq.DatasourceUID == expr.DatasourceUID, nil
 }
 
+// IsRuleReference returns true if the alert query references another alert rule.
+func (aq *AlertQuery) IsRuleReference() bool {
+	return aq.DatasourceUID == RuleReferenceDatasourceUID
+}
+
+// GetRuleReference returns the reference to another alert rule of the alert query.
+func (aq *AlertQuery) GetRuleReference() (RuleReference, error) {
+	var ref RuleReference
+	if !aq.IsRuleReference() {
+		return ref, fmt.Errorf("query %s does not reference an alert rule", aq.RefID)
+	}
+	if err := json.Unmarshal(aq.Model, &ref); err != nil {
+		return ref, fmt.Errorf("failed to unmarshal alert rule reference of query %s: %w", aq.RefID, err)
+	}
+	if ref.RuleUID == "" {
+		return ref, fmt.Errorf("query %s does not reference any alert rule: ruleUid is missing", aq.RefID)
+	}
+	return ref, nil
+}
+
+// HasRecoveryThreshold returns true if the alert query is a threshold expression with a recovery threshold.
+func (aq *AlertQuery) HasRecoveryThreshold() bool {
+	if aq.DatasourceUID != expr.DatasourceUID {
+		return false
+	}
+	var model struct {
+		Type       string `json:"type"`
+		Conditions []struct {
+			UnloadEvaluator *expr.ThresholdEvaluator `json:"unloadEvaluator"`
+		} `json:"conditions"`
+	}
+	if err := json.Unmarshal(aq.Model, &model); err != nil {
+		return false
+	}
+	return model.Type == expr.TypeThreshold.String() && len(model.Conditions) > 0 && model.Conditions[0].UnloadEvaluator != nil
+}
+
+// SetLoadedDimensions sets the dimensions that crossed the threshold of a threshold expression
+// in the previous evaluation, so that they are checked against its recovery threshold.
+func (aq *AlertQuery) SetLoadedDimensions(dims []data.Labels) error {
+	// the model is parsed again so that copies of the alert query do not share the loaded dimensions
+	if err := aq.setModelProps(); err != nil {
+		return err
+	}
+	aq.modelProps["loadedDimensions"] = dims
+	return nil
+}
+
 // setMaxDatapoints sets the model maxDataPoints if it's missing or invalid
 func (aq *AlertQuery) setMaxDatapoints() error {
 	if aq.modelProps == nil {
@@ -232,7 +359,7 @@ func (aq *AlertQuery) PreSave() error {
 		return err
 	}
 
-	if ok := isExpression || aq.RelativeTimeRange.isValid(); !ok {
+	if ok := isExpression || aq.IsRuleReference() || aq.RelativeTimeRange.isValid(); !ok {
 		return fmt.Errorf("invalid relative time range: %+v", aq.RelativeTimeRange)
 	}
 	return nil
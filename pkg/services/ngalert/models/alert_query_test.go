@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -245,3 +247,96 @@ func TestAlertQueryMarshalling(t *testing.T) {
 		}
 	}
 }
+
+func TestAlertQueryRuleReference(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		alertQuery  AlertQuery
+		expectedRef RuleReference
+		expectedErr string
+	}{
+		{
+			desc: "given a query that references the state of an alert rule",
+			alertQuery: AlertQuery{
+				RefID:         "A",
+				DatasourceUID: RuleReferenceDatasourceUID,
+				Model:         json.RawMessage(`{"ruleUid": "rule-1"}`),
+			},
+			expectedRef: RuleReference{RuleUID: "rule-1"},
+		},
+		{
+			desc: "given a query that references an expression of an alert rule",
+			alertQuery: AlertQuery{
+				RefID:         "A",
+				DatasourceUID: RuleReferenceDatasourceUID,
+				Model:         json.RawMessage(`{"ruleUid": "rule-1", "expression": "B"}`),
+			},
+			expectedRef: RuleReference{RuleUID: "rule-1", Expression: "B"},
+		},
+		{
+			desc: "given a query without rule UID",
+			alertQuery: AlertQuery{
+				RefID:         "A",
+				DatasourceUID: RuleReferenceDatasourceUID,
+				Model:         json.RawMessage(`{"expression": "B"}`),
+			},
+			expectedErr: "query A does not reference any alert rule: ruleUid is missing",
+		},
+		{
+			desc: "given a datasource query",
+			alertQuery: AlertQuery{
+				RefID:         "A",
+				DatasourceUID: "000000001",
+				Model:         json.RawMessage(`{"ruleUid": "rule-1"}`),
+			},
+			expectedErr: "query A does not reference an alert rule",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ref, err := tc.alertQuery.GetRuleReference()
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.alertQuery.IsRuleReference())
+			require.Equal(t, tc.expectedRef, ref)
+		})
+	}
+}
+
+func TestAlertQueryLoadedDimensions(t *testing.T) {
+	threshold := AlertQuery{
+		RefID:         "C",
+		DatasourceUID: expr.DatasourceUID,
+		Model: json.RawMessage(`{
+			"type": "threshold",
+			"expression": "B",
+			"conditions": [{"evaluator": {"type": "gt", "params": [80]}, "unloadEvaluator": {"type": "lt", "params": [70]}}]
+		}`),
+	}
+	require.True(t, threshold.HasRecoveryThreshold())
+
+	withoutRecovery := AlertQuery{
+		RefID:         "C",
+		DatasourceUID: expr.DatasourceUID,
+		Model:         json.RawMessage(`{"type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "gt", "params": [80]}}]}`),
+	}
+	require.False(t, withoutRecovery.HasRecoveryThreshold())
+
+	datasource := AlertQuery{RefID: "B", DatasourceUID: "000000001", Model: threshold.Model}
+	require.False(t, datasource.HasRecoveryThreshold())
+
+	loaded := threshold
+	require.NoError(t, loaded.SetLoadedDimensions([]data.Labels{{"host": "a"}}))
+	model, err := loaded.GetModel()
+	require.NoError(t, err)
+	require.Contains(t, string(model), `"loadedDimensions":[{"host":"a"}]`)
+
+	// the original alert query is not modified
+	model, err = threshold.GetModel()
+	require.NoError(t, err)
+	require.NotContains(t, string(model), "loadedDimensions")
+}
@@ -3,12 +3,15 @@ package models
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 )
 
 var (
 	// ErrAlertRuleNotFound is an error for an unknown alert rule.
 	ErrAlertRuleNotFound = fmt.Errorf("could not find alert rule")
+	// ErrAlertRuleVersionNotFound is an error for an unknown alert rule version.
+	ErrAlertRuleVersionNotFound = errors.New("could not find alert rule version")
 	// ErrAlertRuleFailedGenerateUniqueUID is an error for failure to generate alert rule UID
 	ErrAlertRuleFailedGenerateUniqueUID = errors.New("failed to generate alert rule UID")
 	// ErrCannotEditNamespace is an error returned if the user does not have permissions to edit the namespace
@@ -47,9 +50,20 @@ const (
 	RuleUIDLabel      = "__alert_rule_uid__"
 	NamespaceUIDLabel = "__alert_rule_namespace_uid__"
 
+	// FolderTitleLabel, DashboardUIDLabel and PanelIDLabel are computed by the state manager and attached
+	// to the alerts of the rules that enable them, so that notification policies can route alerts by folder,
+	// dashboard or panel.
+	FolderTitleLabel  = "grafana_folder"
+	DashboardUIDLabel = "grafana_dashboard_uid"
+	PanelIDLabel      = "grafana_panel_id"
+
 	// Annotations are actually a set of labels, so technically this is the label name of an annotation.
 	DashboardUIDAnnotation = "__dashboardUid__"
 	PanelIDAnnotation      = "__panelId__"
+
+	// ImageURLAnnotation is the annotation of the alerts that holds the URL of the image of the panel
+	// the alert rule is linked to. It is set by the scheduler and any value set by users is ignored.
+	ImageURLAnnotation = "__alertImageUrl__"
 )
 
 // AlertRule is the model for alert rules in unified alerting.
@@ -67,13 +81,53 @@ type AlertRule struct {
 	DashboardUID    *string `xorm:"dashboard_uid"`
 	PanelID         *int64  `xorm:"panel_id"`
 	RuleGroup       string
-	NoDataState     NoDataState
-	ExecErrState    ExecutionErrorState
+	// RuleGroupIndex is the position of the rule in its rule group, starting at 1.
+	// The rules of a group are evaluated sequentially in this order.
+	RuleGroupIndex int `xorm:"rule_group_idx"`
+	NoDataState    NoDataState
+	ExecErrState   ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record is the name of the metric the rule writes the result of its condition to.
+	// It is empty for alert rules and set for recording rules.
+	Record string
+	// IsPaused is true if the evaluation of the rule is paused.
+	IsPaused bool
+	// ComputedLabels is true if the computed labels are attached to the alerts of the rule.
+	ComputedLabels bool
+}
+
+// IsRecordingRule returns true if the rule records the result of its condition
+// instead of evaluating it into alert states.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return alertRule.Record != ""
+}
+
+// ComputedLabelNames are the names of the labels computed by the state manager. Rules that enable
+// the computed labels cannot define labels with these names.
+var ComputedLabelNames = []string{FolderTitleLabel, DashboardUIDLabel, PanelIDLabel}
+
+// ComputedLabelSet returns the labels computed from the rule and the title of its folder, none if the rule
+// does not enable them. Labels with empty values, e.g. the dashboard UID of rules that are not linked to
+// a panel, are omitted.
+func (alertRule *AlertRule) ComputedLabelSet(folderTitle string) map[string]string {
+	labels := make(map[string]string, len(ComputedLabelNames))
+	if !alertRule.ComputedLabels {
+		return labels
+	}
+	if folderTitle != "" {
+		labels[FolderTitleLabel] = folderTitle
+	}
+	if alertRule.DashboardUID != nil && *alertRule.DashboardUID != "" {
+		labels[DashboardUIDLabel] = *alertRule.DashboardUID
+	}
+	if alertRule.PanelID != nil {
+		labels[PanelIDLabel] = strconv.FormatInt(*alertRule.PanelID, 10)
+	}
+	return labels
 }
 
 // AlertRuleKey is the alert definition identifier
@@ -91,6 +145,38 @@ func (alertRule *AlertRule) GetKey() AlertRuleKey {
 	return AlertRuleKey{OrgID: alertRule.OrgID, UID: alertRule.UID}
 }
 
+// AlertRuleGroupKey is the identifier of a rule group.
+type AlertRuleGroupKey struct {
+	OrgID        int64
+	NamespaceUID string
+	RuleGroup    string
+}
+
+func (k AlertRuleGroupKey) String() string {
+	return fmt.Sprintf("{orgID: %d, namespaceUID: %s, groupName: %s}", k.OrgID, k.NamespaceUID, k.RuleGroup)
+}
+
+// GetGroupKey returns the identifier of the rule group of the alert rule.
+func (alertRule *AlertRule) GetGroupKey() AlertRuleGroupKey {
+	return AlertRuleGroupKey{OrgID: alertRule.OrgID, NamespaceUID: alertRule.NamespaceUID, RuleGroup: alertRule.RuleGroup}
+}
+
+// GetRuleReferences returns the references of the alert rule queries to other alert rules.
+func (alertRule *AlertRule) GetRuleReferences() ([]RuleReference, error) {
+	var refs []RuleReference
+	for i := range alertRule.Data {
+		if !alertRule.Data[i].IsRuleReference() {
+			continue
+		}
+		ref, err := alertRule.Data[i].GetRuleReference()
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
 // PreSave sets default values and loads the updated model for each alert query.
 func (alertRule *AlertRule) PreSave(timeNow func() time.Time) error {
 	for i, q := range alertRule.Data {
@@ -111,6 +197,7 @@ type AlertRuleVersion struct {
 	RuleUID          string `xorm:"rule_uid"`
 	RuleNamespaceUID string `xorm:"rule_namespace_uid"`
 	RuleGroup        string
+	RuleGroupIndex   int `xorm:"rule_group_idx"`
 	ParentVersion    int64
 	RestoredFrom     int64
 	Version          int64
@@ -124,9 +211,47 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
+	For            time.Duration
+	Annotations    map[string]string
+	Labels         map[string]string
+	Record         string
+	IsPaused       bool
+	ComputedLabels bool
+	// CreatedBy is the ID of the user who created the version.
+	CreatedBy int64
+}
+
+// AlertRuleVersionWithCreator is an alert rule version along with the login of the user who created it.
+type AlertRuleVersionWithCreator struct {
+	AlertRuleVersion `xorm:"extends"`
+	CreatedByLogin   string `xorm:"created_by_login"`
+}
+
+// ListAlertRuleVersionsQuery is the query for listing the versions of an alert rule, newest first.
+type ListAlertRuleVersionsQuery struct {
+	OrgID   int64
+	RuleUID string
+
+	Result []*AlertRuleVersionWithCreator
+}
+
+// RestoreAlertRuleVersionCommand is the command for restoring a version of an alert rule.
+type RestoreAlertRuleVersionCommand struct {
+	OrgID   int64
+	RuleUID string
+	Version int64
+	UserID  int64
+
+	Result *AlertRule
+}
+
+// GetAlertRuleVersionQuery is the query for retrieving a single version of an alert rule.
+type GetAlertRuleVersionQuery struct {
+	OrgID   int64
+	RuleUID string
+	Version int64
+
+	Result *AlertRuleVersionWithCreator
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -11,6 +11,14 @@ type AlertConfiguration struct {
 	CreatedAt                 int64 `xorm:"created"`
 	Default                   bool
 	OrgID                     int64 `xorm:"org_id"`
+	// CreatedBy is the ID of the user who saved the configuration, 0 if unknown.
+	CreatedBy int64 `xorm:"created_by"`
+}
+
+// AlertConfigurationWithCreator is an Alertmanager configuration along with the login of the user who saved it.
+type AlertConfigurationWithCreator struct {
+	AlertConfiguration `xorm:"extends"`
+	CreatedByLogin     string `xorm:"created_by_login"`
 }
 
 // GetLatestAlertmanagerConfigurationQuery is the query to get the latest alertmanager configuration.
@@ -19,10 +27,26 @@ type GetLatestAlertmanagerConfigurationQuery struct {
 	Result *AlertConfiguration
 }
 
+// ListAlertmanagerConfigurationHistoryQuery is the query to list the saved alertmanager configurations, newest first.
+type ListAlertmanagerConfigurationHistoryQuery struct {
+	OrgID int64
+	// Limit is the maximum number of configurations to return, all of them if zero.
+	Limit  int
+	Result []*AlertConfigurationWithCreator
+}
+
+// GetAlertmanagerConfigurationQuery is the query to get a saved alertmanager configuration by ID.
+type GetAlertmanagerConfigurationQuery struct {
+	OrgID  int64
+	ID     int64
+	Result *AlertConfigurationWithCreator
+}
+
 // SaveAlertmanagerConfigurationCmd is the command to save an alertmanager configuration.
 type SaveAlertmanagerConfigurationCmd struct {
 	AlertmanagerConfiguration string
 	ConfigurationVersion      string
 	Default                   bool
 	OrgID                     int64
+	CreatedBy                 int64
 }
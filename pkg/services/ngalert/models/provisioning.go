@@ -0,0 +1,37 @@
+package models
+
+import "errors"
+
+// ErrProvisioned is an error for a change to a provisioned resource, which can only be changed by provisioning.
+var ErrProvisioned = errors.New("provisioned resources cannot be changed from the API")
+
+// Provenance is the origin of a resource. Resources created from the API have no provenance.
+type Provenance string
+
+const (
+	ProvenanceNone Provenance = ""
+	ProvenanceFile Provenance = "file"
+)
+
+// The types of the resources that can be provisioned.
+const (
+	ResourceTypeAlertRule          = "alertRule"
+	ResourceTypeContactPoint       = "contactPoint"
+	ResourceTypeNotificationPolicy = "notificationPolicy"
+	ResourceTypeTemplate           = "template"
+	ResourceTypeMuteTiming         = "muteTiming"
+)
+
+// NotificationPolicyKey is the key of the provenance of the notification policy tree, as an organization
+// has a single tree. The key of the other resources is the UID of the alert rules and the name of the
+// contact points, templates and mute timings.
+const NotificationPolicyKey = "root"
+
+// ProvenanceRecord is the provenance of a provisioned resource.
+type ProvenanceRecord struct {
+	ID         int64  `xorm:"pk autoincr 'id'"`
+	OrgID      int64  `xorm:"org_id"`
+	RecordType string `xorm:"record_type"`
+	RecordKey  string `xorm:"record_key"`
+	Provenance Provenance
+}
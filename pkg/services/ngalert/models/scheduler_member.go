@@ -0,0 +1,9 @@
+package models
+
+// SchedulerMember is a Grafana instance taking part in the sharding of the alert rule evaluation.
+type SchedulerMember struct {
+	ID     int64  `xorm:"pk autoincr 'id'"`
+	NodeID string `xorm:"node_id"`
+	// Heartbeat is the Unix timestamp of the last time the instance announced itself.
+	Heartbeat int64
+}
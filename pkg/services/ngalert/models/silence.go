@@ -0,0 +1,240 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrSilenceTemplateNotFound is an error for an unknown silence template.
+	ErrSilenceTemplateNotFound = errors.New("could not find silence template")
+	// ErrSilenceTemplateNameExists is an error for a silence template with the name of another template of the organization.
+	ErrSilenceTemplateNameExists = errors.New("a silence template with the same name already exists")
+	// ErrRecurringSilenceNotFound is an error for an unknown recurring silence.
+	ErrRecurringSilenceNotFound = errors.New("could not find recurring silence")
+)
+
+// SilenceMatcher is a matcher of the labels of the alerts that a silence mutes.
+type SilenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// SilenceTemplate is a reusable set of matchers and comment to create silences from.
+type SilenceTemplate struct {
+	ID       int64            `xorm:"pk autoincr 'id'"`
+	OrgID    int64            `xorm:"org_id"`
+	UID      string           `xorm:"uid"`
+	Name     string           `xorm:"name"`
+	Matchers []SilenceMatcher `xorm:"matchers"`
+	Comment  string           `xorm:"comment"`
+	// Duration is the default duration of the silences created from the template.
+	Duration  time.Duration `xorm:"duration"`
+	CreatedBy string        `xorm:"created_by"`
+	Updated   time.Time     `xorm:"updated"`
+}
+
+// WeeklyWindow is a time window on some days of the week. The window ends on the next day
+// if its end time is not after its start time.
+type WeeklyWindow struct {
+	Weekdays []time.Weekday `json:"weekdays"`
+	// StartTime and EndTime are the times of day of the window in the HH:MM format.
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+}
+
+// RecurringSilence is a silence that is active on a schedule, either from the times of a cron expression
+// for a duration, or during weekly windows. It is materialized into a silence of the Alertmanager of its
+// organization shortly before each time it becomes active.
+type RecurringSilence struct {
+	ID        int64            `xorm:"pk autoincr 'id'"`
+	OrgID     int64            `xorm:"org_id"`
+	UID       string           `xorm:"uid"`
+	Matchers  []SilenceMatcher `xorm:"matchers"`
+	Comment   string           `xorm:"comment"`
+	CreatedBy string           `xorm:"created_by"`
+	// Cron is a standard cron expression of the times the silence starts, for Duration.
+	Cron     string        `xorm:"cron"`
+	Duration time.Duration `xorm:"duration"`
+	// WeeklyWindows are the windows during which the silence is active, if Cron is empty.
+	WeeklyWindows []WeeklyWindow `xorm:"weekly_windows"`
+	// Timezone is the location of the times of the schedule, UTC if empty.
+	Timezone string    `xorm:"timezone"`
+	Updated  time.Time `xorm:"updated"`
+	// LastStartsAt is the start, in Unix seconds, of the last occurrence that was materialized into a silence.
+	LastStartsAt int64 `xorm:"last_starts_at"`
+	// SilenceID is the ID of the silence the last occurrence was materialized into.
+	SilenceID string `xorm:"silence_id"`
+}
+
+// recurringSchedule is a schedule of the starts of a recurring silence along with the duration of the occurrences.
+type recurringSchedule struct {
+	schedule cron.Schedule
+	duration time.Duration
+}
+
+// Validate returns an error if the schedule of the recurring silence is invalid.
+func (s *RecurringSilence) Validate() error {
+	if len(s.Matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	_, _, err := s.schedules()
+	return err
+}
+
+// SameOccurrences returns true if the occurrences of the recurring silences are materialized into the same
+// silences, that is if they have the same matchers, comment and schedule.
+func (s *RecurringSilence) SameOccurrences(other *RecurringSilence) bool {
+	return reflect.DeepEqual(s.Matchers, other.Matchers) &&
+		s.Comment == other.Comment &&
+		s.Cron == other.Cron &&
+		s.Duration == other.Duration &&
+		reflect.DeepEqual(s.WeeklyWindows, other.WeeklyWindows) &&
+		s.Timezone == other.Timezone
+}
+
+// NextOccurrence returns the start and end of the first occurrence of the recurring silence that ends after t.
+func (s *RecurringSilence) NextOccurrence(t time.Time) (time.Time, time.Time, error) {
+	schedules, loc, err := s.schedules()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	var start, end time.Time
+	for _, sched := range schedules {
+		// the first start after t minus the duration is the one of the first occurrence that ends after t
+		next := sched.schedule.Next(t.Add(-sched.duration).In(loc))
+		if next.IsZero() {
+			continue
+		}
+		if start.IsZero() || next.Before(start) {
+			start, end = next, next.Add(sched.duration)
+		}
+	}
+	if start.IsZero() {
+		return time.Time{}, time.Time{}, errors.New("the schedule has no occurrence")
+	}
+	return start, end, nil
+}
+
+func (s *RecurringSilence) schedules() ([]recurringSchedule, *time.Location, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+
+	if s.Cron != "" {
+		if len(s.WeeklyWindows) > 0 {
+			return nil, nil, errors.New("a recurring silence cannot have both a cron expression and weekly windows")
+		}
+		if s.Duration <= 0 {
+			return nil, nil, errors.New("the duration must be positive")
+		}
+		sched, err := cron.ParseStandard(s.Cron)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid cron expression %q: %w", s.Cron, err)
+		}
+		return []recurringSchedule{{schedule: sched, duration: s.Duration}}, loc, nil
+	}
+
+	if len(s.WeeklyWindows) == 0 {
+		return nil, nil, errors.New("either a cron expression or weekly windows are required")
+	}
+	schedules := make([]recurringSchedule, 0, len(s.WeeklyWindows))
+	for _, w := range s.WeeklyWindows {
+		sched, err := w.schedule()
+		if err != nil {
+			return nil, nil, err
+		}
+		schedules = append(schedules, sched)
+	}
+	return schedules, loc, nil
+}
+
+func (w WeeklyWindow) schedule() (recurringSchedule, error) {
+	if len(w.Weekdays) == 0 {
+		return recurringSchedule{}, errors.New("weekly windows must have at least one weekday")
+	}
+	days := make([]string, 0, len(w.Weekdays))
+	for _, d := range w.Weekdays {
+		if d < time.Sunday || d > time.Saturday {
+			return recurringSchedule{}, fmt.Errorf("invalid weekday %d", d)
+		}
+		days = append(days, strconv.Itoa(int(d)))
+	}
+	start, err := parseTimeOfDay(w.StartTime)
+	if err != nil {
+		return recurringSchedule{}, err
+	}
+	end, err := parseTimeOfDay(w.EndTime)
+	if err != nil {
+		return recurringSchedule{}, err
+	}
+	duration := end - start
+	if duration <= 0 {
+		duration += 24 * time.Hour
+	}
+
+	spec := fmt.Sprintf("%d %d * * %s", int(start.Minutes())%60, int(start.Hours()), strings.Join(days, ","))
+	sched, err := cron.ParseStandard(spec)
+	if err != nil {
+		return recurringSchedule{}, err
+	}
+	return recurringSchedule{schedule: sched, duration: duration}, nil
+}
+
+// parseTimeOfDay parses a time of day in the HH:MM format into the duration since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, it must be in the HH:MM format", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ListSilenceTemplatesQuery is the query to list the silence templates of an organization.
+type ListSilenceTemplatesQuery struct {
+	OrgID  int64
+	Result []*SilenceTemplate
+}
+
+// GetSilenceTemplateQuery is the query to get a silence template by UID.
+type GetSilenceTemplateQuery struct {
+	OrgID  int64
+	UID    string
+	Result *SilenceTemplate
+}
+
+// DeleteSilenceTemplateCommand is the command to delete a silence template by UID.
+type DeleteSilenceTemplateCommand struct {
+	OrgID int64
+	UID   string
+}
+
+// ListRecurringSilencesQuery is the query to list the recurring silences of an organization,
+// or of all the organizations if OrgID is zero.
+type ListRecurringSilencesQuery struct {
+	OrgID  int64
+	Result []*RecurringSilence
+}
+
+// GetRecurringSilenceQuery is the query to get a recurring silence by UID.
+type GetRecurringSilenceQuery struct {
+	OrgID  int64
+	UID    string
+	Result *RecurringSilence
+}
+
+// DeleteRecurringSilenceCommand is the command to delete a recurring silence by UID.
+type DeleteRecurringSilenceCommand struct {
+	OrgID int64
+	UID   string
+}
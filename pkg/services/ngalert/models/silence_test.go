@@ -0,0 +1,167 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurringSilenceNextOccurrence(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	matchers := []SilenceMatcher{{Name: "team", Value: "db", IsEqual: true}}
+	// a Saturday
+	saturday := time.Date(2021, 11, 6, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		silence  RecurringSilence
+		t        time.Time
+		expStart time.Time
+		expEnd   time.Time
+	}{
+		{
+			desc:     "weekly window",
+			silence:  RecurringSilence{Matchers: matchers, WeeklyWindows: []WeeklyWindow{{Weekdays: []time.Weekday{time.Sunday}, StartTime: "02:00", EndTime: "04:00"}}},
+			t:        saturday,
+			expStart: time.Date(2021, 11, 7, 2, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2021, 11, 7, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "active weekly window",
+			silence:  RecurringSilence{Matchers: matchers, WeeklyWindows: []WeeklyWindow{{Weekdays: []time.Weekday{time.Sunday}, StartTime: "02:00", EndTime: "04:00"}}},
+			t:        time.Date(2021, 11, 7, 3, 0, 0, 0, time.UTC),
+			expStart: time.Date(2021, 11, 7, 2, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2021, 11, 7, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "weekly window ending the next day",
+			silence:  RecurringSilence{Matchers: matchers, WeeklyWindows: []WeeklyWindow{{Weekdays: []time.Weekday{time.Saturday}, StartTime: "22:00", EndTime: "01:30"}}},
+			t:        saturday,
+			expStart: time.Date(2021, 11, 6, 22, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2021, 11, 7, 1, 30, 0, 0, time.UTC),
+		},
+		{
+			desc: "first of several weekly windows",
+			silence: RecurringSilence{Matchers: matchers, WeeklyWindows: []WeeklyWindow{
+				{Weekdays: []time.Weekday{time.Monday, time.Tuesday}, StartTime: "02:00", EndTime: "04:00"},
+				{Weekdays: []time.Weekday{time.Sunday}, StartTime: "10:00", EndTime: "11:00"},
+			}},
+			t:        saturday,
+			expStart: time.Date(2021, 11, 7, 10, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2021, 11, 7, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "weekly window in a timezone",
+			silence:  RecurringSilence{Matchers: matchers, Timezone: "Europe/Paris", WeeklyWindows: []WeeklyWindow{{Weekdays: []time.Weekday{time.Sunday}, StartTime: "02:00", EndTime: "04:00"}}},
+			t:        saturday,
+			expStart: time.Date(2021, 11, 7, 2, 0, 0, 0, paris),
+			expEnd:   time.Date(2021, 11, 7, 4, 0, 0, 0, paris),
+		},
+		{
+			desc:     "cron",
+			silence:  RecurringSilence{Matchers: matchers, Cron: "30 */6 * * *", Duration: 15 * time.Minute},
+			t:        saturday,
+			expStart: time.Date(2021, 11, 6, 12, 30, 0, 0, time.UTC),
+			expEnd:   time.Date(2021, 11, 6, 12, 45, 0, 0, time.UTC),
+		},
+		{
+			desc:     "cron occurrence ending at the time",
+			silence:  RecurringSilence{Matchers: matchers, Cron: "0 11 * * *", Duration: time.Hour},
+			t:        saturday,
+			expStart: time.Date(2021, 11, 7, 11, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2021, 11, 7, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.NoError(t, tc.silence.Validate())
+			start, end, err := tc.silence.NextOccurrence(tc.t)
+			require.NoError(t, err)
+			require.True(t, tc.expStart.Equal(start), "expected start %s, got %s", tc.expStart, start)
+			require.True(t, tc.expEnd.Equal(end), "expected end %s, got %s", tc.expEnd, end)
+		})
+	}
+}
+
+func TestRecurringSilenceValidate(t *testing.T) {
+	matchers := []SilenceMatcher{{Name: "team", Value: "db", IsEqual: true}}
+	window := WeeklyWindow{Weekdays: []time.Weekday{time.Sunday}, StartTime: "02:00", EndTime: "04:00"}
+
+	testCases := []struct {
+		desc    string
+		silence RecurringSilence
+		expErr  string
+	}{
+		{
+			desc:    "no matchers",
+			silence: RecurringSilence{WeeklyWindows: []WeeklyWindow{window}},
+			expErr:  "at least one matcher is required",
+		},
+		{
+			desc:    "no schedule",
+			silence: RecurringSilence{Matchers: matchers},
+			expErr:  "either a cron expression or weekly windows are required",
+		},
+		{
+			desc:    "cron and weekly windows",
+			silence: RecurringSilence{Matchers: matchers, Cron: "0 2 * * 0", Duration: time.Hour, WeeklyWindows: []WeeklyWindow{window}},
+			expErr:  "a recurring silence cannot have both a cron expression and weekly windows",
+		},
+		{
+			desc:    "invalid cron",
+			silence: RecurringSilence{Matchers: matchers, Cron: "every sunday", Duration: time.Hour},
+			expErr:  `invalid cron expression "every sunday"`,
+		},
+		{
+			desc:    "cron without duration",
+			silence: RecurringSilence{Matchers: matchers, Cron: "0 2 * * 0"},
+			expErr:  "the duration must be positive",
+		},
+		{
+			desc:    "invalid time of day",
+			silence: RecurringSilence{Matchers: matchers, WeeklyWindows: []WeeklyWindow{{Weekdays: []time.Weekday{time.Sunday}, StartTime: "2am", EndTime: "04:00"}}},
+			expErr:  `invalid time of day "2am"`,
+		},
+		{
+			desc:    "window without weekdays",
+			silence: RecurringSilence{Matchers: matchers, WeeklyWindows: []WeeklyWindow{{StartTime: "02:00", EndTime: "04:00"}}},
+			expErr:  "weekly windows must have at least one weekday",
+		},
+		{
+			desc:    "invalid timezone",
+			silence: RecurringSilence{Matchers: matchers, Timezone: "Mars/Olympus", WeeklyWindows: []WeeklyWindow{window}},
+			expErr:  `invalid timezone "Mars/Olympus"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.silence.Validate()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expErr)
+		})
+	}
+}
+
+func TestRecurringSilenceSameOccurrences(t *testing.T) {
+	rs := RecurringSilence{
+		Matchers:      []SilenceMatcher{{Name: "team", Value: "db", IsEqual: true}},
+		WeeklyWindows: []WeeklyWindow{{Weekdays: []time.Weekday{time.Sunday}, StartTime: "02:00", EndTime: "04:00"}},
+		LastStartsAt:  100,
+		SilenceID:     "silence",
+	}
+
+	other := rs
+	other.LastStartsAt, other.SilenceID = 0, ""
+	require.True(t, rs.SameOccurrences(&other))
+
+	other.Timezone = "Europe/Paris"
+	require.False(t, rs.SameOccurrences(&other))
+
+	other = rs
+	other.Matchers = []SilenceMatcher{{Name: "team", Value: "web", IsEqual: true}}
+	require.False(t, rs.SameOccurrences(&other))
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// AlertStateHistory is a transition of an alert instance from one state to another.
+type AlertStateHistory struct {
+	ID         int64  `xorm:"pk autoincr 'id'"`
+	RuleOrgID  int64  `xorm:"rule_org_id"`
+	RuleUID    string `xorm:"rule_uid"`
+	Labels     map[string]string
+	LabelsHash string
+	// PreviousState and State are the names of the evaluation states, as in eval.State.
+	PreviousState string
+	State         string
+	// EvaluationValues contains the RefID and value of the reduce and math expressions of the evaluation that caused the transition.
+	EvaluationValues map[string]*float64
+	// Error is the error of the evaluation that caused the transition, if any.
+	Error   string
+	Created time.Time
+}
+
+// ListAlertStateHistoryQuery is the query for listing the state transitions of the alert instances of an organization.
+// Transitions are returned from the most recent to the oldest.
+type ListAlertStateHistoryQuery struct {
+	OrgID int64
+	// RuleUIDs restricts the transitions to the given alert rules if not empty.
+	RuleUIDs []string
+	// Labels restricts the transitions to the alert instances that have all the given labels.
+	Labels map[string]string
+	From   time.Time
+	To     time.Time
+	Limit  int
+
+	Result []*AlertStateHistory
+}
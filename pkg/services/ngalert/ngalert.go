@@ -2,7 +2,9 @@ package ngalert
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -13,16 +15,21 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/prometheus/alertmanager/cluster"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -39,7 +46,7 @@ const (
 
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, dataService *tsdb.Service, dataProxy *datasourceproxy.DataSourceProxyService,
-	quotaService *quota.QuotaService, secretsService secrets.Service, m *metrics.NGAlert) (*AlertNG, error) {
+	quotaService *quota.QuotaService, secretsService secrets.Service, renderService rendering.Service, m *metrics.NGAlert) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:             cfg,
 		DataSourceCache: dataSourceCache,
@@ -50,6 +57,7 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		DataProxy:       dataProxy,
 		QuotaService:    quotaService,
 		SecretsService:  secretsService,
+		RenderService:   renderService,
 		Metrics:         m,
 		Log:             log.New("ngalert"),
 	}
@@ -76,13 +84,18 @@ type AlertNG struct {
 	DataProxy       *datasourceproxy.DataSourceProxyService
 	QuotaService    *quota.QuotaService
 	SecretsService  secrets.Service
+	RenderService   rendering.Service
 	Metrics         *metrics.NGAlert
 	Log             log.Logger
 	schedule        schedule.ScheduleService
 	stateManager    *state.Manager
 
+	// Store is the database store of the alert rules and the Alertmanager configurations.
+	Store *store.DBstore
+
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
+	recurringSilences    *notifier.RecurringSilenceScheduler
 }
 
 func (ng *AlertNG) init() error {
@@ -100,6 +113,7 @@ func (ng *AlertNG) init() error {
 		SQLStore:        ng.SQLStore,
 		Logger:          ng.Log,
 	}
+	ng.Store = store
 
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
@@ -113,12 +127,26 @@ func (ng *AlertNG) init() error {
 		return err
 	}
 
+	ng.recurringSilences = notifier.NewRecurringSilenceScheduler(store, ng.MultiOrgAlertmanager, ng.Cfg.UnifiedAlerting.AlertmanagerConfigPollInterval, log.New("ngalert.recurring-silences"))
+
+	membership, err := ng.getShardingMembership(store)
+	if err != nil {
+		return err
+	}
+
+	appUrl, err := url.Parse(ng.Cfg.AppURL)
+	if err != nil {
+		ng.Log.Error("Failed to parse application URL. Continue without it.", "error", err)
+		appUrl = nil
+	}
+	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, ng.getStateHistoryStore(store))
+
 	schedCfg := schedule.SchedulerCfg{
 		C:                       clock.New(),
 		BaseInterval:            baseInterval,
 		Logger:                  ng.Log,
 		MaxAttempts:             ng.Cfg.UnifiedAlerting.MaxAttempts,
-		Evaluator:               eval.Evaluator{Cfg: ng.Cfg, Log: ng.Log},
+		Evaluator:               eval.Evaluator{Cfg: ng.Cfg, Log: ng.Log, RuleReferences: stateManager},
 		InstanceStore:           store,
 		RuleStore:               store,
 		AdminConfigStore:        store,
@@ -128,14 +156,11 @@ func (ng *AlertNG) init() error {
 		AdminConfigPollInterval: ng.Cfg.UnifiedAlerting.AdminConfigPollInterval,
 		DisabledOrgs:            ng.Cfg.UnifiedAlerting.DisabledOrgs,
 		MinRuleInterval:         ng.getRuleMinInterval(),
+		RecordingWriter:         ng.getRecordingWriter(),
+		ImageService:            ng.getImageService(),
+		Membership:              membership,
 	}
 
-	appUrl, err := url.Parse(ng.Cfg.AppURL)
-	if err != nil {
-		ng.Log.Error("Failed to parse application URL. Continue without it.", "error", err)
-		appUrl = nil
-	}
-	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store)
 	scheduler := schedule.NewScheduler(schedCfg, ng.DataService, appUrl, stateManager)
 
 	ng.stateManager = stateManager
@@ -152,7 +177,12 @@ func (ng *AlertNG) init() error {
 		SecretsService:       ng.SecretsService,
 		InstanceStore:        store,
 		RuleStore:            store,
+		RuleVersionStore:     store,
+		StateHistoryStore:    store,
 		AlertingStore:        store,
+		ConfigHistoryStore:   store,
+		SilenceStore:         store,
+		ProvisioningStore:    store,
 		AdminConfigStore:     store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
@@ -177,6 +207,9 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.MultiOrgAlertmanager.Run(subCtx)
 	})
+	children.Go(func() error {
+		return ng.recurringSilences.Run(subCtx)
+	})
 	return children.Wait()
 }
 
@@ -214,3 +247,69 @@ func (ng *AlertNG) getRuleMinInterval() time.Duration {
 
 	return ng.Cfg.UnifiedAlerting.MinInterval
 }
+
+// getRecordingWriter returns the writer for the results of recording rules.
+// If no remote write endpoint is configured the results are kept in memory.
+func (ng *AlertNG) getRecordingWriter() recording.Writer {
+	rrCfg := ng.Cfg.UnifiedAlerting.RecordingRules
+	if rrCfg.URL == "" {
+		return recording.NewMemoryWriter()
+	}
+
+	return recording.NewRemoteWriter(recording.RemoteWriteConfig{
+		URL:      rrCfg.URL,
+		User:     rrCfg.BasicAuthUsername,
+		Password: rrCfg.BasicAuthPassword,
+		Timeout:  rrCfg.Timeout,
+	}, log.New("ngalert.recording"))
+}
+
+// getImageService returns the service that captures the images of the panels of the alert rules that start firing.
+func (ng *AlertNG) getImageService() image.ImageService {
+	ssCfg := ng.Cfg.UnifiedAlerting.Screenshots
+	if !ssCfg.Capture || ng.RenderService == nil {
+		return image.NoopImageService{}
+	}
+
+	imageService, err := image.NewScreenshotImageService(ng.RenderService, ssCfg.CaptureTimeout, ssCfg.MaxConcurrent, log.New("ngalert.image"))
+	if err != nil {
+		ng.Log.Error("Failed to create the image service. Continue without screenshots.", "error", err)
+		return image.NoopImageService{}
+	}
+	return imageService
+}
+
+// getStateHistoryStore returns the store the state transitions are recorded to.
+// It returns nil if the state history is disabled.
+func (ng *AlertNG) getStateHistoryStore(historyStore store.StateHistoryStore) store.StateHistoryStore {
+	if !ng.Cfg.UnifiedAlerting.StateHistory.Enabled {
+		return nil
+	}
+	return historyStore
+}
+
+// getShardingMembership returns how the instances sharing the alert rule evaluation are discovered.
+// It returns nil if sharding is disabled.
+func (ng *AlertNG) getShardingMembership(memberStore store.SchedulerMemberStore) (schedule.Membership, error) {
+	shardingCfg := ng.Cfg.UnifiedAlerting.Sharding
+	if !shardingCfg.Enabled {
+		return nil, nil
+	}
+
+	if shardingCfg.Membership == setting.ShardingMembershipPeers {
+		peer, ok := ng.MultiOrgAlertmanager.Peer().(*cluster.Peer)
+		if !ok {
+			return nil, fmt.Errorf("sharding membership %q requires ha_peers to be configured", shardingCfg.Membership)
+		}
+		return schedule.NewPeerMembership(peer), nil
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		ng.Log.Warn("unable to get the hostname for the alert rule evaluation sharding", "err", err)
+		hostname = "grafana"
+	}
+	nodeID := fmt.Sprintf("%s-%s", hostname, util.GenerateShortUID())
+	ng.Log.Info("alert rule evaluation sharding enabled", "membership", shardingCfg.Membership, "node", nodeID)
+	return schedule.NewDatabaseMembership(memberStore, nodeID, shardingCfg.HeartbeatInterval, shardingCfg.MemberTimeout, clock.New(), log.New("ngalert.sharding")), nil
+}
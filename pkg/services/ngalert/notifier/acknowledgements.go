@@ -0,0 +1,264 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/store"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	ErrAlertNotFound              = errors.New("alert not found")
+	ErrAlertNotFiring             = errors.New("only firing alerts can be acknowledged")
+	ErrAcknowledgementNotFound    = errors.New("acknowledgement not found")
+	ErrInvalidAlertFingerprint    = errors.New("invalid alert fingerprint")
+	ErrAcknowledgementsInternal   = errors.New("unable to acknowledge the alert due to an internal error")
+	errAcknowledgementsBadPayload = errors.New("failed to decode acknowledgements")
+)
+
+// acknowledgement is the acknowledgement of a firing alert. Acknowledgements are replicated to the other
+// members of the cluster, so removed acknowledgements are kept as deleted until they expire.
+type acknowledgement struct {
+	Fingerprint    string    `json:"fingerprint"`
+	StartsAt       time.Time `json:"startsAt"`
+	AcknowledgedBy string    `json:"acknowledgedBy"`
+	AcknowledgedAt time.Time `json:"acknowledgedAt"`
+	Comment        string    `json:"comment,omitempty"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	Deleted        bool      `json:"deleted,omitempty"`
+}
+
+// acknowledgements holds the acknowledgements of the alerts of an Alertmanager. It implements
+// the cluster.State interface, the state is merged by keeping the last update of each alert.
+type acknowledgements struct {
+	mtx       sync.RWMutex
+	entries   map[string]*acknowledgement
+	broadcast func([]byte)
+	retention time.Duration
+	now       func() time.Time
+}
+
+func newAcknowledgements(retention time.Duration) *acknowledgements {
+	return &acknowledgements{
+		entries:   make(map[string]*acknowledgement),
+		broadcast: func([]byte) {},
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// SetBroadcast sets the function that sends the updated acknowledgements to the other members of the cluster.
+func (a *acknowledgements) SetBroadcast(f func([]byte)) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.broadcast = f
+}
+
+// MarshalBinary implements the cluster.State interface.
+func (a *acknowledgements) MarshalBinary() ([]byte, error) {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	entries := make([]*acknowledgement, 0, len(a.entries))
+	for _, e := range a.entries {
+		entries = append(entries, e)
+	}
+	return json.Marshal(entries)
+}
+
+// Merge implements the cluster.State interface.
+func (a *acknowledgements) Merge(b []byte) error {
+	var entries []*acknowledgement
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return fmt.Errorf("%s: %w", errAcknowledgementsBadPayload, err)
+	}
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	for _, e := range entries {
+		a.merge(e)
+	}
+	return nil
+}
+
+func (a *acknowledgements) merge(e *acknowledgement) bool {
+	if existing, ok := a.entries[e.Fingerprint]; ok && !e.UpdatedAt.After(existing.UpdatedAt) {
+		return false
+	}
+	a.entries[e.Fingerprint] = e
+	return true
+}
+
+// loadSnapshot merges the acknowledgements of the snapshot file, if it exists.
+func (a *acknowledgements) loadSnapshot(path string) error {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path is the one of the Alertmanager working directory
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || len(b) == 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return a.Merge(b)
+}
+
+func (a *acknowledgements) set(e *acknowledgement) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if !a.merge(e) {
+		return
+	}
+	b, err := json.Marshal([]*acknowledgement{e})
+	if err != nil {
+		// In theory, this should never happen.
+		return
+	}
+	a.broadcast(b)
+}
+
+// get returns the acknowledgement of the alert with the fingerprint, if it's not deleted.
+func (a *acknowledgements) get(fingerprint string) (acknowledgement, bool) {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	e, ok := a.entries[fingerprint]
+	if !ok || e.Deleted {
+		return acknowledgement{}, false
+	}
+	return *e, true
+}
+
+// isAcknowledged returns true if the alert is acknowledged since it started firing.
+func (a *acknowledgements) isAcknowledged(alert *types.Alert) bool {
+	e, ok := a.get(alert.Fingerprint().String())
+	return ok && e.StartsAt.Equal(alert.StartsAt)
+}
+
+func (a *acknowledgements) list() []acknowledgement {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	res := make([]acknowledgement, 0, len(a.entries))
+	for _, e := range a.entries {
+		if !e.Deleted {
+			res = append(res, *e)
+		}
+	}
+	return res
+}
+
+// gc removes the acknowledgements that have not been updated for the retention period, unless the
+// alert they acknowledge is still firing.
+func (a *acknowledgements) gc(isFiring func(fingerprint string, startsAt time.Time) bool) int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	var n int
+	for fp, e := range a.entries {
+		if a.now().Sub(e.UpdatedAt) < a.retention {
+			continue
+		}
+		if !e.Deleted && isFiring(fp, e.StartsAt) {
+			continue
+		}
+		delete(a.entries, fp)
+		n++
+	}
+	return n
+}
+
+// AcknowledgeAlert acknowledges the firing alert with the given fingerprint.
+func (am *Alertmanager) AcknowledgeAlert(fingerprint, acknowledgedBy, comment string) (apimodels.GettableAcknowledgement, error) {
+	alert, err := am.getAlert(fingerprint)
+	if err != nil {
+		return apimodels.GettableAcknowledgement{}, err
+	}
+	if alert.Resolved() {
+		return apimodels.GettableAcknowledgement{}, ErrAlertNotFiring
+	}
+
+	now := am.acknowledgements.now()
+	e := &acknowledgement{
+		Fingerprint:    alert.Fingerprint().String(),
+		StartsAt:       alert.StartsAt,
+		AcknowledgedBy: acknowledgedBy,
+		AcknowledgedAt: now,
+		Comment:        comment,
+		UpdatedAt:      now,
+	}
+	am.acknowledgements.set(e)
+	return toGettableAcknowledgement(*e), nil
+}
+
+// DeleteAcknowledgement removes the acknowledgement of the alert with the given fingerprint.
+func (am *Alertmanager) DeleteAcknowledgement(fingerprint string) error {
+	fp, err := model.ParseFingerprint(fingerprint)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAlertFingerprint, err)
+	}
+	e, ok := am.acknowledgements.get(fp.String())
+	if !ok {
+		return ErrAcknowledgementNotFound
+	}
+
+	e.Deleted = true
+	e.UpdatedAt = am.acknowledgements.now()
+	am.acknowledgements.set(&e)
+	return nil
+}
+
+// ListAcknowledgements returns the acknowledgements of the alerts that are still firing since they were acknowledged.
+func (am *Alertmanager) ListAcknowledgements() apimodels.GettableAcknowledgements {
+	res := apimodels.GettableAcknowledgements{}
+	for _, e := range am.acknowledgements.list() {
+		if am.isFiring(e.Fingerprint, e.StartsAt) {
+			res = append(res, toGettableAcknowledgement(e))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Fingerprint < res[j].Fingerprint
+	})
+	return res
+}
+
+func (am *Alertmanager) getAlert(fingerprint string) (*types.Alert, error) {
+	fp, err := model.ParseFingerprint(fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAlertFingerprint, err)
+	}
+	alert, err := am.alerts.Get(fp)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrAlertNotFound
+		}
+		am.logger.Error(ErrAcknowledgementsInternal.Error(), "err", err)
+		return nil, fmt.Errorf("%w: %s", ErrAcknowledgementsInternal, err)
+	}
+	return alert, nil
+}
+
+// isFiring returns true if the alert with the fingerprint is firing since startsAt.
+func (am *Alertmanager) isFiring(fingerprint string, startsAt time.Time) bool {
+	fp, err := model.ParseFingerprint(fingerprint)
+	if err != nil {
+		return false
+	}
+	alert, err := am.alerts.Get(fp)
+	return err == nil && !alert.Resolved() && alert.StartsAt.Equal(startsAt)
+}
+
+func toGettableAcknowledgement(e acknowledgement) apimodels.GettableAcknowledgement {
+	return apimodels.GettableAcknowledgement{
+		Fingerprint:    e.Fingerprint,
+		StartsAt:       e.StartsAt,
+		AcknowledgedBy: e.AcknowledgedBy,
+		AcknowledgedAt: e.AcknowledgedAt,
+		Comment:        e.Comment,
+	}
+}
@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcknowledgementsMerge(t *testing.T) {
+	now := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	a, b := newAcknowledgements(time.Hour), newAcknowledgements(time.Hour)
+	a.SetBroadcast(func(msg []byte) {
+		require.NoError(t, b.Merge(msg))
+	})
+
+	a.set(&acknowledgement{Fingerprint: "0000000000000001", StartsAt: now, AcknowledgedBy: "admin", UpdatedAt: now})
+	e, ok := b.get("0000000000000001")
+	require.True(t, ok)
+	require.Equal(t, "admin", e.AcknowledgedBy)
+
+	t.Run("older updates are ignored", func(t *testing.T) {
+		older := &acknowledgement{Fingerprint: "0000000000000001", StartsAt: now, AcknowledgedBy: "editor", UpdatedAt: now.Add(-time.Minute)}
+		a.set(older)
+		e, ok := a.get("0000000000000001")
+		require.True(t, ok)
+		require.Equal(t, "admin", e.AcknowledgedBy)
+	})
+
+	t.Run("deletions are replicated", func(t *testing.T) {
+		a.set(&acknowledgement{Fingerprint: "0000000000000001", StartsAt: now, UpdatedAt: now.Add(time.Minute), Deleted: true})
+		_, ok := b.get("0000000000000001")
+		require.False(t, ok)
+		require.Empty(t, b.list())
+	})
+
+	t.Run("the full state is merged", func(t *testing.T) {
+		a.set(&acknowledgement{Fingerprint: "0000000000000002", StartsAt: now, UpdatedAt: now})
+		state, err := a.MarshalBinary()
+		require.NoError(t, err)
+
+		c := newAcknowledgements(time.Hour)
+		require.NoError(t, c.Merge(state))
+		require.Len(t, c.list(), 1)
+		require.Len(t, c.entries, 2)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		require.Error(t, b.Merge([]byte("{")))
+	})
+}
+
+func TestAcknowledgementsGC(t *testing.T) {
+	now := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	a := newAcknowledgements(time.Hour)
+	a.now = func() time.Time { return now }
+
+	a.set(&acknowledgement{Fingerprint: "recent", UpdatedAt: now.Add(-time.Minute)})
+	a.set(&acknowledgement{Fingerprint: "firing", UpdatedAt: now.Add(-2 * time.Hour)})
+	a.set(&acknowledgement{Fingerprint: "resolved", UpdatedAt: now.Add(-2 * time.Hour)})
+	a.set(&acknowledgement{Fingerprint: "deleted", UpdatedAt: now.Add(-2 * time.Hour), Deleted: true})
+
+	removed := a.gc(func(fingerprint string, _ time.Time) bool {
+		return fingerprint == "firing" || fingerprint == "deleted"
+	})
+	require.Equal(t, 2, removed)
+	require.Len(t, a.entries, 2)
+	require.Contains(t, a.entries, "recent")
+	require.Contains(t, a.entries, "firing")
+}
+
+func TestAlertmanagerAcknowledgements(t *testing.T) {
+	am := setupAMTest(t)
+	now := time.Now()
+
+	firing := &types.Alert{Alert: model.Alert{
+		Labels:   model.LabelSet{"alertname": "firing"},
+		StartsAt: now.Add(-time.Hour),
+		EndsAt:   now.Add(time.Hour),
+	}}
+	resolved := &types.Alert{Alert: model.Alert{
+		Labels:   model.LabelSet{"alertname": "resolved"},
+		StartsAt: now.Add(-time.Hour),
+		EndsAt:   now.Add(-time.Minute),
+	}}
+	require.NoError(t, am.alerts.Put(firing, resolved))
+
+	ack, err := am.AcknowledgeAlert(firing.Fingerprint().String(), "admin", "looking into it")
+	require.NoError(t, err)
+	require.Equal(t, firing.Fingerprint().String(), ack.Fingerprint)
+	require.Equal(t, "admin", ack.AcknowledgedBy)
+	require.Equal(t, "looking into it", ack.Comment)
+	require.True(t, firing.StartsAt.Equal(ack.StartsAt))
+	require.True(t, am.acknowledgements.isAcknowledged(firing))
+
+	acks := am.ListAcknowledgements()
+	require.Len(t, acks, 1)
+	require.Equal(t, ack, acks[0])
+
+	_, err = am.AcknowledgeAlert(resolved.Fingerprint().String(), "admin", "")
+	require.ErrorIs(t, err, ErrAlertNotFiring)
+	_, err = am.AcknowledgeAlert("0000000000000001", "admin", "")
+	require.ErrorIs(t, err, ErrAlertNotFound)
+	_, err = am.AcknowledgeAlert("invalid", "admin", "")
+	require.ErrorIs(t, err, ErrInvalidAlertFingerprint)
+
+	t.Run("acknowledgements do not apply once the alert fires again", func(t *testing.T) {
+		refired := &types.Alert{Alert: firing.Alert}
+		refired.StartsAt = now
+		require.False(t, am.acknowledgements.isAcknowledged(refired))
+	})
+
+	t.Run("acknowledgements can be removed", func(t *testing.T) {
+		require.NoError(t, am.DeleteAcknowledgement(firing.Fingerprint().String()))
+		require.Empty(t, am.ListAcknowledgements())
+		require.False(t, am.acknowledgements.isAcknowledged(firing))
+		require.ErrorIs(t, am.DeleteAcknowledgement(firing.Fingerprint().String()), ErrAcknowledgementNotFound)
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strconv"
 	"sync"
+	tmpltext "text/template"
 	"time"
 	"unicode/utf8"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/prometheus/alertmanager/provider/mem"
 	"github.com/prometheus/alertmanager/silence"
 	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
@@ -44,8 +46,9 @@ import (
 )
 
 const (
-	notificationLogFilename = "notifications"
-	silencesFilename        = "silences"
+	notificationLogFilename  = "notifications"
+	silencesFilename         = "silences"
+	acknowledgementsFilename = "acknowledgements"
 
 	workingDir = "alerting"
 	// How long should we keep silences and notification entries on-disk after they've served their purpose.
@@ -110,6 +113,12 @@ type Alertmanager struct {
 	silencer *silence.Silencer
 	silences *silence.Silences
 
+	acknowledgements *acknowledgements
+	deliveries       *deliveryLog
+	// templateMatchers are the templates of the template matchers of the routing tree by the name of
+	// the label their result is stored in.
+	templateMatchers map[string]*tmpltext.Template
+
 	stageMetrics      *notify.Metrics
 	dispatcherMetrics *dispatch.DispatcherMetrics
 
@@ -136,6 +145,7 @@ func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, k
 		Metrics:           m,
 		orgID:             orgID,
 		decryptFn:         decryptFn,
+		deliveries:        newDeliveryLog(),
 	}
 
 	am.gokitLogger = gokit_log.NewLogfmtLogger(logging.NewWrapper(am.logger))
@@ -149,6 +159,10 @@ func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, k
 	if err != nil {
 		return nil, err
 	}
+	acknowledgementsFilePath, err := am.fileStore.FilepathFor(context.TODO(), acknowledgementsFilename)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize the notification log
 	am.wg.Add(1)
@@ -186,12 +200,26 @@ func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, k
 		am.wg.Done()
 	}()
 
+	// Initialize the acknowledgements of alerts
+	am.acknowledgements = newAcknowledgements(retentionNotificationsAndSilences)
+	if err := am.acknowledgements.loadSnapshot(acknowledgementsFilePath); err != nil {
+		return nil, fmt.Errorf("unable to initialize the acknowledgement component of alerting: %w", err)
+	}
+	c = am.peer.AddState(fmt.Sprintf("acknowledgements:%d", am.orgID), am.acknowledgements, m.Registerer)
+	am.acknowledgements.SetBroadcast(c.Broadcast)
+
 	// Initialize in-memory alerts
 	am.alerts, err = mem.NewAlerts(context.Background(), am.marker, memoryAlertsGCInterval, nil, am.gokitLogger)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize the alert provider component of alerting: %w", err)
 	}
 
+	am.wg.Add(1)
+	go func() {
+		defer am.wg.Done()
+		am.acknowledgementsMaintenance(maintenanceNotificationAndSilences)
+	}()
+
 	return am, nil
 }
 
@@ -257,8 +285,9 @@ func (am *Alertmanager) SaveAndApplyDefaultConfig() error {
 }
 
 // SaveAndApplyConfig saves the configuration the database and applies the configuration to the Alertmanager.
-// It rollbacks the save if we fail to apply the configuration.
-func (am *Alertmanager) SaveAndApplyConfig(cfg *apimodels.PostableUserConfig) error {
+// It rollbacks the save if we fail to apply the configuration. The user ID is recorded as the author of the
+// configuration, 0 if unknown.
+func (am *Alertmanager) SaveAndApplyConfig(cfg *apimodels.PostableUserConfig, userID int64) error {
 	rawConfig, err := json.Marshal(&cfg)
 	if err != nil {
 		return fmt.Errorf("failed to serialize to the Alertmanager configuration: %w", err)
@@ -271,6 +300,7 @@ func (am *Alertmanager) SaveAndApplyConfig(cfg *apimodels.PostableUserConfig) er
 		AlertmanagerConfiguration: string(rawConfig),
 		ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
 		OrgID:                     am.orgID,
+		CreatedBy:                 userID,
 	}
 
 	err = am.Store.SaveAlertmanagerConfigurationWithCallback(cmd, func() error {
@@ -375,6 +405,14 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	if err != nil {
 		return fmt.Errorf("failed to build integration map: %w", err)
 	}
+	am.deliveries.retain(integrationKeys(cfg.AlertmanagerConfig.Receivers))
+
+	amRoute := cfg.AlertmanagerConfig.Route.AsAMRoute()
+	templateMatchers := map[string]*tmpltext.Template{}
+	if err := routeTemplateMatchers(amRoute, cfg.AlertmanagerConfig.Route, templateMatchers); err != nil {
+		return fmt.Errorf("failed to build template matchers: %w", err)
+	}
+
 	// Now, let's put together our notification pipeline
 	routingStage := make(notify.RoutingStage, len(integrationsMap))
 
@@ -388,15 +426,23 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	am.inhibitor = inhibit.NewInhibitor(am.alerts, cfg.AlertmanagerConfig.InhibitRules, am.marker, am.gokitLogger)
 	am.silencer = silence.NewSilencer(am.silences, am.marker, am.gokitLogger)
 
+	am.route = dispatch.NewRoute(amRoute, nil)
+	am.templateMatchers = templateMatchers
+
 	meshStage := notify.NewGossipSettleStage(am.peer)
 	inhibitionStage := notify.NewMuteStage(am.inhibitor)
 	silencingStage := notify.NewMuteStage(am.silencer)
+	muteTimes := make(map[string][]timeinterval.TimeInterval, len(cfg.AlertmanagerConfig.MuteTimeIntervals))
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		muteTimes[mt.Name] = mt.TimeIntervals
+	}
+	timeMuteStage := notify.NewTimeMuteStage(muteTimes)
+	escalationStage := am.createEscalationStage(am.route, cfg.AlertmanagerConfig.Route, integrationsMap)
 	for name := range integrationsMap {
 		stage := am.createReceiverStage(name, integrationsMap[name], am.waitFunc, am.notificationLog)
-		routingStage[name] = notify.MultiStage{meshStage, silencingStage, inhibitionStage, stage}
+		routingStage[name] = notify.MultiStage{meshStage, silencingStage, inhibitionStage, timeMuteStage, notify.FanoutStage{stage, escalationStage}}
 	}
 
-	am.route = dispatch.NewRoute(cfg.AlertmanagerConfig.Route.AsAMRoute(), nil)
 	am.dispatcher = dispatch.NewDispatcher(am.alerts, am.route, routingStage, am.marker, am.timeoutFunc, &nilLimits{}, am.gokitLogger, am.dispatcherMetrics)
 
 	am.wg.Add(1)
@@ -448,6 +494,12 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 		if err != nil {
 			return nil, err
 		}
+		n = &deliveryRecorder{
+			NotificationChannel: n,
+			key:                 integrationKey{receiver: receiver.Name, index: i, uid: r.UID},
+			log:                 am.deliveries,
+			logger:              am.logger,
+		}
 		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
 	}
 	return integrations, nil
@@ -516,6 +568,19 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 		n, err = channels.NewOpsgenieNotifier(cfg, tmpl, am.decryptFn)
 	case "prometheus-alertmanager":
 		n, err = channels.NewAlertmanagerNotifier(cfg, tmpl, am.decryptFn)
+	case "webex":
+		n, err = channels.NewWebexNotifier(cfg, tmpl, am.decryptFn)
+	case "sns":
+		n, err = channels.NewSNSNotifier(cfg, tmpl, am.decryptFn, channels.AWSSettings{
+			AllowedAuthProviders: am.Settings.AWSAllowedAuthProviders,
+			AssumeRoleEnabled:    am.Settings.AWSAssumeRoleEnabled,
+		})
+	case "mattermost":
+		n, err = channels.NewMattermostNotifier(cfg, tmpl, am.decryptFn)
+	case "zulip":
+		n, err = channels.NewZulipNotifier(cfg, tmpl, am.decryptFn)
+	case "smtprelay":
+		n, err = channels.NewSmtpRelayNotifier(cfg, tmpl, am.decryptFn)
 	default:
 		return nil, InvalidReceiverError{
 			Receiver: r,
@@ -535,6 +600,10 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
 func (am *Alertmanager) PutAlerts(postableAlerts apimodels.PostableAlerts) error {
+	am.reloadConfigMtx.RLock()
+	templateMatchers := am.templateMatchers
+	am.reloadConfigMtx.RUnlock()
+
 	now := time.Now()
 	alerts := make([]*types.Alert, 0, len(postableAlerts.PostableAlerts))
 	var validationErr *AlertValidationError
@@ -562,6 +631,7 @@ func (am *Alertmanager) PutAlerts(postableAlerts apimodels.PostableAlerts) error
 			}
 			alert.Alert.Annotations[model.LabelName(k)] = model.LabelValue(v)
 		}
+		setTemplateMatcherLabels(templateMatchers, alert, am.logger)
 
 		// Ensure StartsAt is set.
 		if alert.StartsAt.IsZero() {
@@ -691,6 +761,55 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []notify.I
 	return fs
 }
 
+// createEscalationStage creates the stage that runs the escalations of the routes. The notifications
+// of each escalation are deduplicated separately from the ones of the route and of other escalations.
+func (am *Alertmanager) createEscalationStage(route *dispatch.Route, r *apimodels.Route, integrationsMap map[string][]notify.Integration) notify.Stage {
+	byRoute := make(map[string][]*apimodels.Escalation)
+	routeEscalations(route, r, byRoute)
+
+	s := &escalationStage{
+		escalations:      make(map[string][]escalation, len(byRoute)),
+		acknowledgements: am.acknowledgements,
+	}
+	for key, escalations := range byRoute {
+		for i, e := range escalations {
+			name := fmt.Sprintf("%s/escalation/%d", e.Receiver, i)
+			s.escalations[key] = append(s.escalations[key], escalation{
+				after:    time.Duration(e.After),
+				receiver: e.Receiver,
+				stage:    am.createReceiverStage(name, integrationsMap[e.Receiver], am.waitFunc, am.notificationLog),
+			})
+		}
+	}
+	return s
+}
+
+// acknowledgementsMaintenance garbage collects and persists the acknowledgements at every interval until
+// the Alertmanager is stopped.
+func (am *Alertmanager) acknowledgementsMaintenance(interval time.Duration) {
+	persist := func() {
+		removed := am.acknowledgements.gc(am.isFiring)
+		size, err := am.fileStore.Persist(context.TODO(), acknowledgementsFilename, am.acknowledgements)
+		if err != nil {
+			am.logger.Error("failed to persist the acknowledgements", "err", err)
+			return
+		}
+		am.logger.Debug("acknowledgements maintenance done", "removed", removed, "size", size)
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-am.stopc:
+			persist()
+			return
+		case <-t.C:
+			persist()
+		}
+	}
+}
+
 func (am *Alertmanager) waitFunc() time.Duration {
 	return time.Duration(am.peer.Position()) * am.peerTimeout
 }
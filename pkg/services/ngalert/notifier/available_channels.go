@@ -829,5 +829,328 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "webex",
+			Name:        "Webex Teams",
+			Description: "Sends notifications to a Webex Teams room",
+			Heading:     "Webex Teams settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Bot Token",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Webex bot access token",
+					PropertyName: "bot_token",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Room ID",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "The ID of the room the bot posts to",
+					PropertyName: "room_id",
+					Required:     true,
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "message",
+				},
+				{
+					Label:        "API URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  channels.WebexAPIURL,
+					PropertyName: "api_url",
+				},
+			},
+		},
+		{
+			Type:        "sns",
+			Name:        "AWS SNS",
+			Description: "Publishes notifications to an AWS SNS topic, endpoint or phone number",
+			Heading:     "AWS SNS settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Region",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "us-east-1",
+					PropertyName: "region",
+					Required:     true,
+				},
+				{
+					Label:        "Topic ARN",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "One of Topic ARN, Target ARN or Phone number is required",
+					PropertyName: "topic_arn",
+				},
+				{
+					Label:        "Target ARN",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "A mobile platform endpoint ARN",
+					PropertyName: "target_arn",
+				},
+				{
+					Label:        "Phone number",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "A phone number in E.164 format to send SMS messages to",
+					PropertyName: "phone_number",
+				},
+				{
+					Label:        "Access Key",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Leave empty to use the default AWS credentials chain, if the default auth provider is allowed",
+					PropertyName: "access_key",
+					Secure:       true,
+				},
+				{
+					Label:        "Secret Key",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "secret_key",
+					Secure:       true,
+				},
+				{
+					Label:        "Assume Role ARN",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "assume_role_arn",
+				},
+				{
+					Label:        "Subject",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  `{{ template "default.title" . }}`,
+					PropertyName: "subject",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "message",
+				},
+				{
+					Label:        "API URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Overrides the SNS endpoint with an HTTPS AWS endpoint, e.g. a VPC endpoint",
+					PropertyName: "api_url",
+				},
+			},
+		},
+		{
+			Type:        "mattermost",
+			Name:        "Mattermost",
+			Description: "Sends notifications to Mattermost via incoming webhooks",
+			Heading:     "Mattermost settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Webhook URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Mattermost incoming webhook URL",
+					PropertyName: "url",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Channel",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Overrides the channel of the incoming webhook",
+					PropertyName: "channel",
+				},
+				{
+					Label:        "Username",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Grafana",
+					PropertyName: "username",
+				},
+				{
+					Label:        "Icon URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "icon_url",
+				},
+				{
+					Label:        "Title",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  `{{ template "default.title" . }}`,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Text Body",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "text",
+				},
+			},
+		},
+		{
+			Type:        "zulip",
+			Name:        "Zulip",
+			Description: "Sends notifications to a Zulip stream or to Zulip users",
+			Heading:     "Zulip settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Server URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "https://example.zulipchat.com",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Bot email",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "The email address of the bot sending the messages",
+					PropertyName: "bot_email",
+					Required:     true,
+				},
+				{
+					Label:        "API key",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					Description:  "The API key of the bot",
+					PropertyName: "api_key",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:   "Message type",
+					Element: alerting.ElementTypeSelect,
+					SelectOptions: []alerting.SelectOption{
+						{
+							Value: "stream",
+							Label: "Stream",
+						},
+						{
+							Value: "private",
+							Label: "Private",
+						},
+					},
+					PropertyName: "type",
+				},
+				{
+					Label:        "To",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "The name of the stream, or a comma separated list of email addresses for private messages",
+					PropertyName: "to",
+					Required:     true,
+				},
+				{
+					Label:        "Topic",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  `{{ template "default.title" . }}`,
+					Description:  "The topic of stream messages, truncated to 60 characters",
+					PropertyName: "topic",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "message",
+				},
+			},
+		},
+		{
+			Type:        "smtprelay",
+			Name:        "SMTP relay",
+			Description: "Sends notifications to a group of email addresses through an SMTP relay",
+			Heading:     "SMTP relay settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Host",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "smtp.example.org:587",
+					PropertyName: "host",
+					Required:     true,
+				},
+				{
+					Label:        "User",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "user",
+				},
+				{
+					Label:        "Password",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+				{
+					Label:        "From address",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "alerts@example.org",
+					PropertyName: "from_address",
+					Required:     true,
+				},
+				{
+					Label:        "From name",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Grafana",
+					PropertyName: "from_name",
+				},
+				{
+					Label:   "StartTLS policy",
+					Element: alerting.ElementTypeSelect,
+					SelectOptions: []alerting.SelectOption{
+						{
+							Value: "OpportunisticStartTLS",
+							Label: "Opportunistic",
+						},
+						{
+							Value: "MandatoryStartTLS",
+							Label: "Mandatory",
+						},
+						{
+							Value: "NoStartTLS",
+							Label: "None",
+						},
+					},
+					PropertyName: "start_tls_policy",
+				},
+				{
+					Label:        "Skip TLS verification",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "skip_verify",
+				},
+				{
+					Label:        "Addresses",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  "Email addresses of the group",
+					Description:  "You can enter multiple email addresses using a \";\" separator",
+					PropertyName: "addresses",
+					Required:     true,
+				},
+				{
+					Label:        "Single email",
+					Element:      alerting.ElementTypeCheckbox,
+					Description:  "Send a single email to all recipients",
+					PropertyName: "singleEmail",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "message",
+				},
+			},
+		},
 	}
 }
@@ -96,6 +96,12 @@ func (d DiscordNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	ruleURL := joinUrlPath(d.tmpl.ExternalURL.String(), "/alerting/list", d.log)
 	embed.Set("url", ruleURL)
 
+	if imageURL := getImageURL(as...); imageURL != "" {
+		embed.Set("image", map[string]interface{}{
+			"url": imageURL,
+		})
+	}
+
 	bodyJSON.Set("embeds", []interface{}{embed})
 
 	u := tmpl(d.WebhookURL)
@@ -60,6 +60,16 @@ func NewEmailNotifier(model *NotificationChannelConfig, t *template.Template) (*
 
 // Notify sends the alert notification.
 func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	cmd := en.buildEmailCommand(ctx, as)
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// buildEmailCommand templates the email of the notification of the alerts.
+func (en *EmailNotifier) buildEmailCommand(ctx context.Context, as []*types.Alert) *models.SendEmailCommandSync {
 	var tmplErr error
 	tmpl, data := TmplText(ctx, en.tmpl, as, en.log, &tmplErr)
 
@@ -103,11 +113,7 @@ func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		en.log.Debug("failed to template email message", "err", tmplErr.Error())
 	}
 
-	if err := bus.DispatchCtx(ctx, cmd); err != nil {
-		return false, err
-	}
-
-	return true, nil
+	return cmd
 }
 
 func (en *EmailNotifier) SendResolved() bool {
@@ -0,0 +1,143 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// MattermostNotifier is responsible for sending
+// alert notifications to Mattermost incoming webhooks.
+type MattermostNotifier struct {
+	*Base
+	URL      string
+	Channel  string
+	Username string
+	IconURL  string
+	Title    string
+	Text     string
+	log      log.Logger
+	tmpl     *template.Template
+}
+
+// NewMattermostNotifier is the constructor for the Mattermost notifier.
+func NewMattermostNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*MattermostNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
+	}
+
+	u := fn(context.Background(), model.SecureSettings, "url", model.Settings.Get("url").MustString())
+	if u == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find url property in settings"}
+	}
+
+	return &MattermostNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		URL:      u,
+		Channel:  model.Settings.Get("channel").MustString(),
+		Username: model.Settings.Get("username").MustString("Grafana"),
+		IconURL:  model.Settings.Get("icon_url").MustString(),
+		Title:    model.Settings.Get("title").MustString(`{{ template "default.title" . }}`),
+		Text:     model.Settings.Get("text").MustString(`{{ template "default.message" . }}`),
+		log:      log.New("alerting.notifier.mattermost"),
+		tmpl:     t,
+	}, nil
+}
+
+// mattermostMessage is the payload accepted by Mattermost incoming webhooks.
+// See: https://docs.mattermost.com/developer/message-attachments.html
+type mattermostMessage struct {
+	Channel     string                 `json:"channel,omitempty"`
+	Username    string                 `json:"username,omitempty"`
+	IconURL     string                 `json:"icon_url,omitempty"`
+	Attachments []mattermostAttachment `json:"attachments"`
+}
+
+type mattermostAttachment struct {
+	Fallback   string `json:"fallback"`
+	Color      string `json:"color,omitempty"`
+	Title      string `json:"title,omitempty"`
+	TitleLink  string `json:"title_link,omitempty"`
+	Text       string `json:"text"`
+	ImageURL   string `json:"image_url,omitempty"`
+	Footer     string `json:"footer"`
+	FooterIcon string `json:"footer_icon"`
+}
+
+// Notify sends an alert notification to Mattermost.
+func (mn *MattermostNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	mn.log.Debug("Executing Mattermost notification", "notification", mn.Name)
+
+	msg, u := mn.buildMattermostMessage(ctx, as)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        u,
+		HttpMethod: "POST",
+		HttpHeader: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: string(body),
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		mn.log.Error("Failed to send Mattermost notification", "error", err, "webhook", mn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (mn *MattermostNotifier) buildMattermostMessage(ctx context.Context, as []*types.Alert) (*mattermostMessage, string) {
+	alerts := types.Alerts(as...)
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, mn.tmpl, as, mn.log, &tmplErr)
+
+	title := tmpl(mn.Title)
+	msg := &mattermostMessage{
+		Channel:  tmpl(mn.Channel),
+		Username: tmpl(mn.Username),
+		IconURL:  tmpl(mn.IconURL),
+		Attachments: []mattermostAttachment{
+			{
+				Fallback:   title,
+				Color:      getAlertStatusColor(alerts.Status()),
+				Title:      title,
+				TitleLink:  joinUrlPath(mn.tmpl.ExternalURL.String(), "/alerting/list", mn.log),
+				Text:       tmpl(mn.Text),
+				ImageURL:   getImageURL(as...),
+				Footer:     "Grafana v" + setting.BuildVersion,
+				FooterIcon: FooterIconURL,
+			},
+		},
+	}
+	u := tmpl(mn.URL)
+
+	if tmplErr != nil {
+		mn.log.Debug("failed to template Mattermost message", "err", tmplErr.Error())
+	}
+
+	return msg, u
+}
+
+func (mn *MattermostNotifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
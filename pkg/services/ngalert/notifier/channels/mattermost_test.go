@@ -0,0 +1,158 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMattermostNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       *mattermostMessage
+		expInitError string
+		expMsgError  error
+	}{
+		{
+			name:     "Default template with one alert",
+			settings: `{"url": "http://localhost/hooks/abcd"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__dashboardUid__": "abcd", "__panelId__": "efgh"},
+					},
+				},
+			},
+			expMsg: &mattermostMessage{
+				Username: "Grafana",
+				Attachments: []mattermostAttachment{
+					{
+						Fallback:   "[FIRING:1]  (val1)",
+						Color:      "#D63232",
+						Title:      "[FIRING:1]  (val1)",
+						TitleLink:  "http://localhost/alerting/list",
+						Text:       "**Firing**\n\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n",
+						Footer:     "Grafana v" + setting.BuildVersion,
+						FooterIcon: FooterIconURL,
+					},
+				},
+			},
+			expMsgError: nil,
+		}, {
+			name: "Custom config with multiple alerts and an image",
+			settings: `{
+				"url": "http://localhost/hooks/abcd",
+				"channel": "alerts",
+				"username": "Alerting",
+				"icon_url": "http://localhost/icon.png",
+				"title": "{{ .CommonLabels.alertname }} is {{ .Status }}",
+				"text": "{{ len .Alerts.Firing }} alerts are firing"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__alertImageUrl__": "http://localhost/image.png"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+						Annotations: model.LabelSet{"ann1": "annv2"},
+					},
+				},
+			},
+			expMsg: &mattermostMessage{
+				Channel:  "alerts",
+				Username: "Alerting",
+				IconURL:  "http://localhost/icon.png",
+				Attachments: []mattermostAttachment{
+					{
+						Fallback:   "alert1 is firing",
+						Color:      "#D63232",
+						Title:      "alert1 is firing",
+						TitleLink:  "http://localhost/alerting/list",
+						Text:       "2 alerts are firing",
+						ImageURL:   "http://localhost/image.png",
+						Footer:     "Grafana v" + setting.BuildVersion,
+						FooterIcon: FooterIconURL,
+					},
+				},
+			},
+			expMsgError: nil,
+		}, {
+			name:         "Error in initing",
+			settings:     `{}`,
+			expInitError: `failed to validate receiver "mattermost_testing" of type "mattermost": could not find url property in settings`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &NotificationChannelConfig{
+				Name:     "mattermost_testing",
+				Type:     "mattermost",
+				Settings: settingsJSON,
+			}
+
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			decryptFn := secretsService.GetDecryptedValue
+			pn, err := NewMattermostNotifier(m, tmpl, decryptFn)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var webhook *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+				webhook = cmd
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			if c.expMsgError != nil {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, "http://localhost/hooks/abcd", webhook.Url)
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+
+			require.JSONEq(t, string(expBody), webhook.Body)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"mime/multipart"
+	"path"
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -19,6 +20,9 @@ var (
 	PushoverEndpoint = "https://api.pushover.net/1/messages.json"
 )
 
+// pushoverMaxAttachmentSize is the maximum size of the attachments accepted by Pushover.
+const pushoverMaxAttachmentSize = 2621440
+
 // PushoverNotifier is responsible for sending
 // alert notifications to Pushover
 type PushoverNotifier struct {
@@ -223,6 +227,23 @@ func (pn *PushoverNotifier) genPushoverBody(ctx context.Context, as ...*types.Al
 	if err != nil {
 		return nil, b, err
 	}
+
+	// Add image only if requested and available
+	if imageURL := getImageURL(as...); pn.Upload && imageURL != "" {
+		image, err := downloadImage(ctx, imageURL, pushoverMaxAttachmentSize)
+		if err != nil {
+			pn.log.Warn("failed to download the image, sending the notification without it", "url", imageURL, "err", err)
+		} else {
+			fw, err := w.CreateFormFile("attachment", path.Base(imageURL))
+			if err != nil {
+				return nil, b, err
+			}
+			if _, err := fw.Write(image); err != nil {
+				return nil, b, err
+			}
+		}
+	}
+
 	if err := w.Close(); err != nil {
 		return nil, b, err
 	}
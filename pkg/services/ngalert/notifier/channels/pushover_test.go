@@ -31,6 +31,14 @@ func TestPushoverNotifier(t *testing.T) {
 	require.NoError(t, err)
 	tmpl.ExternalURL = externalURL
 
+	origDownloadImage := downloadImage
+	t.Cleanup(func() {
+		downloadImage = origDownloadImage
+	})
+	downloadImage = func(_ context.Context, imageURL string, _ int64) ([]byte, error) {
+		return []byte("image from " + imageURL), nil
+	}
+
 	cases := []struct {
 		name         string
 		settings     string
@@ -66,6 +74,34 @@ func TestPushoverNotifier(t *testing.T) {
 			},
 			expMsgError: nil,
 		},
+		{
+			name: "Correct config with an image",
+			settings: `{
+				"userKey": "<userKey>",
+				"apiToken": "<apiToken>"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__alertImageUrl__": "https://images.example.com/image.png"},
+					},
+				},
+			},
+			expMsg: map[string]string{
+				"user":       "<userKey>",
+				"token":      "<apiToken>",
+				"priority":   "0",
+				"sound":      "",
+				"title":      "[FIRING:1]  (val1)",
+				"url":        "http://localhost/alerting/list",
+				"url_title":  "Show alert rule",
+				"message":    "**Firing**\n\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\n",
+				"html":       "1",
+				"attachment": "image from https://images.example.com/image.png",
+			},
+			expMsgError: nil,
+		},
 		{
 			name: "Custom config with multiple alerts",
 			settings: `{
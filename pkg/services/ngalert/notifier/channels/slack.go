@@ -142,6 +142,7 @@ type attachment struct {
 	FooterIcon string              `json:"footer_icon"`
 	Color      string              `json:"color,omitempty"`
 	Ts         int64               `json:"ts,omitempty"`
+	ImageURL   string              `json:"image_url,omitempty"`
 }
 
 // Notify sends an alert notification to Slack.
@@ -262,6 +263,7 @@ func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Aler
 				TitleLink:  ruleURL,
 				Text:       tmpl(sn.Text),
 				Fields:     nil, // TODO. Should be a config.
+				ImageURL:   getImageURL(as...),
 			},
 		},
 	}
@@ -0,0 +1,85 @@
+package channels
+
+import (
+	"context"
+	"net"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SmtpRelayNotifier is responsible for sending alert notifications over email
+// to a group of addresses, through an SMTP relay other than the one of the Grafana configuration.
+type SmtpRelayNotifier struct {
+	*EmailNotifier
+	Relay models.SmtpRelay
+}
+
+// NewSmtpRelayNotifier is the constructor for the SMTP relay notifier.
+func NewSmtpRelayNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*SmtpRelayNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
+	}
+
+	host := model.Settings.Get("host").MustString()
+	if host == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find SMTP host in settings"}
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "SMTP host must be of the form host:port", Err: err}
+	}
+
+	fromAddress := model.Settings.Get("from_address").MustString()
+	if !util.IsEmail(fromAddress) {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find a valid from address in settings"}
+	}
+
+	startTLSPolicy := model.Settings.Get("start_tls_policy").MustString()
+	switch startTLSPolicy {
+	case "", "OpportunisticStartTLS", "MandatoryStartTLS", "NoStartTLS":
+	default:
+		return nil, receiverInitError{Cfg: *model, Reason: "StartTLS policy must be one of OpportunisticStartTLS, MandatoryStartTLS or NoStartTLS"}
+	}
+
+	en, err := NewEmailNotifier(model, t)
+	if err != nil {
+		return nil, err
+	}
+	en.log = log.New("alerting.notifier.smtprelay")
+
+	return &SmtpRelayNotifier{
+		EmailNotifier: en,
+		Relay: models.SmtpRelay{
+			Host:           host,
+			User:           model.Settings.Get("user").MustString(),
+			Password:       fn(context.Background(), model.SecureSettings, "password", model.Settings.Get("password").MustString()),
+			FromAddress:    fromAddress,
+			FromName:       model.Settings.Get("from_name").MustString("Grafana"),
+			StartTLSPolicy: startTLSPolicy,
+			SkipVerify:     model.Settings.Get("skip_verify").MustBool(false),
+		},
+	}, nil
+}
+
+// Notify sends the alert notification through the SMTP relay.
+func (sn *SmtpRelayNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	cmd := sn.buildEmailCommand(ctx, as)
+	relay := sn.Relay
+	cmd.Relay = &relay
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		sn.log.Error("Failed to send email through the SMTP relay", "error", err, "host", sn.Relay.Host, "notification", sn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (sn *SmtpRelayNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
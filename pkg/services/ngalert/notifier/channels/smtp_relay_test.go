@@ -0,0 +1,146 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestSmtpRelayNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expTo        []string
+		expSingle    bool
+		expRelay     *models.SmtpRelay
+		expInitError string
+	}{
+		{
+			name: "Group of addresses through a relay",
+			settings: `{
+				"host": "relay.example.org:587",
+				"user": "alerts",
+				"password": "secret",
+				"from_address": "alerts@example.org",
+				"addresses": "someops@example.com;somedev@example.com",
+				"singleEmail": true,
+				"start_tls_policy": "MandatoryStartTLS"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expTo:     []string{"someops@example.com", "somedev@example.com"},
+			expSingle: true,
+			expRelay: &models.SmtpRelay{
+				Host:           "relay.example.org:587",
+				User:           "alerts",
+				Password:       "secret",
+				FromAddress:    "alerts@example.org",
+				FromName:       "Grafana",
+				StartTLSPolicy: "MandatoryStartTLS",
+			},
+		}, {
+			name: "Relay without authentication",
+			settings: `{
+				"host": "relay.example.org:25",
+				"from_address": "alerts@example.org",
+				"from_name": "Alerts",
+				"skip_verify": true,
+				"addresses": "someops@example.com"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expTo: []string{"someops@example.com"},
+			expRelay: &models.SmtpRelay{
+				Host:        "relay.example.org:25",
+				FromAddress: "alerts@example.org",
+				FromName:    "Alerts",
+				SkipVerify:  true,
+			},
+		}, {
+			name:         "Error in initing, no host",
+			settings:     `{"from_address": "alerts@example.org", "addresses": "someops@example.com"}`,
+			expInitError: `failed to validate receiver "smtprelay_testing" of type "smtprelay": could not find SMTP host in settings`,
+		}, {
+			name:         "Error in initing, host without port",
+			settings:     `{"host": "relay.example.org", "from_address": "alerts@example.org", "addresses": "someops@example.com"}`,
+			expInitError: `failed to validate receiver "smtprelay_testing" of type "smtprelay": SMTP host must be of the form host:port: address relay.example.org: missing port in address`,
+		}, {
+			name:         "Error in initing, invalid from address",
+			settings:     `{"host": "relay.example.org:25", "from_address": "alerts", "addresses": "someops@example.com"}`,
+			expInitError: `failed to validate receiver "smtprelay_testing" of type "smtprelay": could not find a valid from address in settings`,
+		}, {
+			name:         "Error in initing, invalid StartTLS policy",
+			settings:     `{"host": "relay.example.org:25", "from_address": "alerts@example.org", "addresses": "someops@example.com", "start_tls_policy": "Always"}`,
+			expInitError: `failed to validate receiver "smtprelay_testing" of type "smtprelay": StartTLS policy must be one of OpportunisticStartTLS, MandatoryStartTLS or NoStartTLS`,
+		}, {
+			name:         "Error in initing, no addresses",
+			settings:     `{"host": "relay.example.org:25", "from_address": "alerts@example.org"}`,
+			expInitError: `failed to validate receiver "smtprelay_testing" of type "smtprelay": could not find addresses in settings`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &NotificationChannelConfig{
+				Name:     "smtprelay_testing",
+				Type:     "smtprelay",
+				Settings: settingsJSON,
+			}
+
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			decryptFn := secretsService.GetDecryptedValue
+			pn, err := NewSmtpRelayNotifier(m, tmpl, decryptFn)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var email *models.SendEmailCommandSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendEmailCommandSync) error {
+				email = cmd
+				return nil
+			})
+
+			ok, err := pn.Notify(context.Background(), c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, c.expTo, email.To)
+			require.Equal(t, c.expSingle, email.SingleEmail)
+			require.Equal(t, "ng_alert_notification", email.Template)
+			require.Equal(t, c.expRelay, email.Relay)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// snsSubjectLengthLimit is the maximum length, in characters, of the subject of an SNS message.
+const snsSubjectLengthLimit = 100
+
+// The AWS authentication providers of the allowed_auth_providers setting that the SNS notifier uses.
+const (
+	awsAuthProviderDefault = "default"
+	awsAuthProviderKeys    = "keys"
+)
+
+// AWSSettings are the settings of the [aws] section that restrict how AWS notifiers authenticate.
+type AWSSettings struct {
+	AllowedAuthProviders []string
+	AssumeRoleEnabled    bool
+}
+
+func (s AWSSettings) isAllowed(authProvider string) bool {
+	for _, p := range s.AllowedAuthProviders {
+		if p == authProvider {
+			return true
+		}
+	}
+	return false
+}
+
+// SNSNotifier is responsible for publishing
+// alert notifications to an AWS SNS topic.
+type SNSNotifier struct {
+	*Base
+	Region        string
+	APIURL        string
+	TopicARN      string
+	TargetARN     string
+	PhoneNumber   string
+	AccessKey     string
+	SecretKey     string
+	AssumeRoleARN string
+	Subject       string
+	Message       string
+	log           log.Logger
+	tmpl          *template.Template
+}
+
+// NewSNSNotifier is the constructor for the AWS SNS notifier.
+// Notifiers without an access key use the default AWS credentials chain of the server, so they are only
+// allowed if the default authentication provider is allowed.
+func NewSNSNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn, awsSettings AWSSettings) (*SNSNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
+	}
+
+	region := model.Settings.Get("region").MustString()
+	if region == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find region in settings"}
+	}
+
+	topicARN := model.Settings.Get("topic_arn").MustString()
+	targetARN := model.Settings.Get("target_arn").MustString()
+	phoneNumber := model.Settings.Get("phone_number").MustString()
+	if topicARN == "" && targetARN == "" && phoneNumber == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "must specify one of topic ARN, target ARN or phone number"}
+	}
+
+	accessKey := fn(context.Background(), model.SecureSettings, "access_key", model.Settings.Get("access_key").MustString())
+	secretKey := fn(context.Background(), model.SecureSettings, "secret_key", model.Settings.Get("secret_key").MustString())
+	if (accessKey == "") != (secretKey == "") {
+		return nil, receiverInitError{Cfg: *model, Reason: "access key and secret key must be specified together"}
+	}
+	if accessKey == "" && !awsSettings.isAllowed(awsAuthProviderDefault) {
+		return nil, receiverInitError{Cfg: *model, Reason: "the default AWS credentials are not allowed, an access key and a secret key must be specified"}
+	}
+	if accessKey != "" && !awsSettings.isAllowed(awsAuthProviderKeys) {
+		return nil, receiverInitError{Cfg: *model, Reason: "access keys are not allowed as AWS credentials"}
+	}
+
+	assumeRoleARN := model.Settings.Get("assume_role_arn").MustString()
+	if assumeRoleARN != "" && !awsSettings.AssumeRoleEnabled {
+		return nil, receiverInitError{Cfg: *model, Reason: "assuming an AWS role is not enabled"}
+	}
+
+	apiURL := model.Settings.Get("api_url").MustString()
+	if apiURL != "" && !isSNSEndpoint(apiURL) {
+		return nil, receiverInitError{Cfg: *model, Reason: "API URL must be an HTTPS AWS endpoint"}
+	}
+
+	return &SNSNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		Region:        region,
+		APIURL:        apiURL,
+		TopicARN:      topicARN,
+		TargetARN:     targetARN,
+		PhoneNumber:   phoneNumber,
+		AccessKey:     accessKey,
+		SecretKey:     secretKey,
+		AssumeRoleARN: assumeRoleARN,
+		Subject:       model.Settings.Get("subject").MustString(`{{ template "default.title" . }}`),
+		Message:       model.Settings.Get("message").MustString(`{{ template "default.message" . }}`),
+		log:           log.New("alerting.notifier.sns"),
+		tmpl:          t,
+	}, nil
+}
+
+// isSNSEndpoint returns whether the URL is an HTTPS URL of an AWS endpoint, such as a regional or a VPC endpoint.
+// Other URLs are rejected, as requests to them are signed with the credentials of the notifier.
+func isSNSEndpoint(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	host := u.Hostname()
+	return strings.HasSuffix(host, ".amazonaws.com") || strings.HasSuffix(host, ".amazonaws.com.cn")
+}
+
+// Notify publishes an alert notification to AWS SNS. Requests are signed
+// with AWS Signature Version 4 by the SDK.
+func (sn *SNSNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	sn.log.Debug("Executing AWS SNS notification", "notification", sn.Name)
+
+	input := sn.buildPublishInput(ctx, as)
+
+	client, err := sn.createClient()
+	if err != nil {
+		return false, fmt.Errorf("failed to create AWS SNS client: %w", err)
+	}
+
+	out, err := client.PublishWithContext(ctx, input)
+	if err != nil {
+		sn.log.Error("Failed to publish AWS SNS notification", "error", err, "notification", sn.Name)
+		return false, err
+	}
+
+	sn.log.Debug("Published AWS SNS notification", "messageId", aws.StringValue(out.MessageId))
+	return true, nil
+}
+
+func (sn *SNSNotifier) buildPublishInput(ctx context.Context, as []*types.Alert) *sns.PublishInput {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, sn.tmpl, as, sn.log, &tmplErr)
+
+	input := &sns.PublishInput{
+		Message: aws.String(tmpl(sn.Message)),
+	}
+
+	switch {
+	case sn.TopicARN != "":
+		input.TopicArn = aws.String(tmpl(sn.TopicARN))
+	case sn.TargetARN != "":
+		input.TargetArn = aws.String(tmpl(sn.TargetARN))
+	default:
+		input.PhoneNumber = aws.String(tmpl(sn.PhoneNumber))
+	}
+
+	// Subjects are only supported by email endpoints, they can't be sent along with SMS messages.
+	if input.PhoneNumber == nil {
+		// SNS rejects subjects that contain line breaks or are longer than the limit.
+		subject := strings.Join(strings.Fields(tmpl(sn.Subject)), " ")
+		// truncate by runes to not split multi-byte characters
+		subject, _ = notify.Truncate(subject, snsSubjectLengthLimit)
+		if subject != "" {
+			input.Subject = aws.String(subject)
+		}
+	}
+
+	if tmplErr != nil {
+		sn.log.Debug("failed to template AWS SNS message", "err", tmplErr.Error())
+	}
+
+	return input
+}
+
+func (sn *SNSNotifier) createClient() (*sns.SNS, error) {
+	cfg := &aws.Config{
+		Region: aws.String(sn.Region),
+	}
+	if sn.APIURL != "" {
+		cfg.Endpoint = aws.String(sn.APIURL)
+	}
+	if sn.AccessKey != "" {
+		cfg.Credentials = credentials.NewStaticCredentials(sn.AccessKey, sn.SecretKey, "")
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if sn.AssumeRoleARN != "" {
+		return sns.New(sess, &aws.Config{Credentials: stscreds.NewCredentials(sess, sn.AssumeRoleARN)}), nil
+	}
+
+	return sns.New(sess), nil
+}
+
+func (sn *SNSNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
@@ -0,0 +1,295 @@
+package channels
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestSNSNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	// The stub answers like the SNS Publish API and records the signed requests it receives.
+	var (
+		authHeader string
+		form       url.Values
+	)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		require.NoError(t, r.ParseForm())
+		form = r.PostForm
+		w.Header().Set("Content-Type", "text/xml")
+		_, err := w.Write([]byte(`<PublishResponse xmlns="http://sns.amazonaws.com/doc/2010-03-31/"><PublishResult><MessageId>abcd</MessageId></PublishResult></PublishResponse>`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(stub.Close)
+
+	cases := []struct {
+		name         string
+		settings     string
+		awsSettings  *AWSSettings
+		alerts       []*types.Alert
+		expForm      url.Values
+		expInitError string
+		expMsgError  error
+	}{
+		{
+			name: "Default template with one alert",
+			settings: `{
+				"region": "us-east-1",
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__dashboardUid__": "abcd", "__panelId__": "efgh"},
+					},
+				},
+			},
+			expForm: url.Values{
+				"Action":   {"Publish"},
+				"Version":  {"2010-03-31"},
+				"TopicArn": {"arn:aws:sns:us-east-1:123456789012:alerts"},
+				"Subject":  {"[FIRING:1] (val1)"},
+				"Message":  {"**Firing**\n\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n"},
+			},
+			expMsgError: nil,
+		}, {
+			name: "Custom template sent to a phone number",
+			settings: `{
+				"region": "us-east-1",
+				"phone_number": "+15555550100",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret",
+				"message": "{{ len .Alerts.Firing }} alerts are firing"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+						Annotations: model.LabelSet{"ann1": "annv2"},
+					},
+				},
+			},
+			expForm: url.Values{
+				"Action":      {"Publish"},
+				"Version":     {"2010-03-31"},
+				"PhoneNumber": {"+15555550100"},
+				"Message":     {"2 alerts are firing"},
+			},
+			expMsgError: nil,
+		}, {
+			name: "Long subject is truncated",
+			settings: `{
+				"region": "us-east-1",
+				"target_arn": "arn:aws:sns:us-east-1:123456789012:endpoint/GCM/app/abcd",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret",
+				"subject": "{{ range .Alerts }}{{ .Labels.lbl1 }} {{ end }}` + strings.Repeat("a", 100) + `",
+				"message": "message"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expForm: url.Values{
+				"Action":    {"Publish"},
+				"Version":   {"2010-03-31"},
+				"TargetArn": {"arn:aws:sns:us-east-1:123456789012:endpoint/GCM/app/abcd"},
+				"Subject":   {"val1 " + strings.Repeat("a", 92) + "..."},
+				"Message":   {"message"},
+			},
+			expMsgError: nil,
+		}, {
+			name: "Subject with multi-byte characters under the limit is not truncated",
+			settings: `{
+				"region": "us-east-1",
+				"target_arn": "arn:aws:sns:us-east-1:123456789012:endpoint/GCM/app/abcd",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret",
+				"subject": "` + strings.Repeat("é", 60) + `",
+				"message": "message"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expForm: url.Values{
+				"Action":    {"Publish"},
+				"Version":   {"2010-03-31"},
+				"TargetArn": {"arn:aws:sns:us-east-1:123456789012:endpoint/GCM/app/abcd"},
+				"Subject":   {strings.Repeat("é", 60)},
+				"Message":   {"message"},
+			},
+			expMsgError: nil,
+		}, {
+			name: "Long multi-byte subject is truncated by characters",
+			settings: `{
+				"region": "us-east-1",
+				"target_arn": "arn:aws:sns:us-east-1:123456789012:endpoint/GCM/app/abcd",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret",
+				"subject": "` + strings.Repeat("é", 120) + `",
+				"message": "message"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expForm: url.Values{
+				"Action":    {"Publish"},
+				"Version":   {"2010-03-31"},
+				"TargetArn": {"arn:aws:sns:us-east-1:123456789012:endpoint/GCM/app/abcd"},
+				"Subject":   {strings.Repeat("é", 97) + "..."},
+				"Message":   {"message"},
+			},
+			expMsgError: nil,
+		}, {
+			name:         "Error in initing, no region",
+			settings:     `{"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts"}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": could not find region in settings`,
+		}, {
+			name:         "Error in initing, no destination",
+			settings:     `{"region": "us-east-1"}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": must specify one of topic ARN, target ARN or phone number`,
+		}, {
+			name: "Error in initing, access key without secret key",
+			settings: `{
+				"region": "us-east-1",
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"access_key": "AKIDEXAMPLE"
+			}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": access key and secret key must be specified together`,
+		}, {
+			name: "Error in initing, default credentials are not allowed",
+			settings: `{
+				"region": "us-east-1",
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts"
+			}`,
+			awsSettings:  &AWSSettings{AllowedAuthProviders: []string{"keys", "credentials"}, AssumeRoleEnabled: true},
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": the default AWS credentials are not allowed, an access key and a secret key must be specified`,
+		}, {
+			name: "Error in initing, access keys are not allowed",
+			settings: `{
+				"region": "us-east-1",
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret"
+			}`,
+			awsSettings:  &AWSSettings{AllowedAuthProviders: []string{"default"}, AssumeRoleEnabled: true},
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": access keys are not allowed as AWS credentials`,
+		}, {
+			name: "Error in initing, assume role is not enabled",
+			settings: `{
+				"region": "us-east-1",
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret",
+				"assume_role_arn": "arn:aws:iam::123456789012:role/alerting"
+			}`,
+			awsSettings:  &AWSSettings{AllowedAuthProviders: []string{"default", "keys", "credentials"}, AssumeRoleEnabled: false},
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": assuming an AWS role is not enabled`,
+		}, {
+			name: "Error in initing, API URL is not an AWS endpoint",
+			settings: `{
+				"region": "us-east-1",
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret",
+				"api_url": "https://sns.example.com"
+			}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": API URL must be an HTTPS AWS endpoint`,
+		}, {
+			name: "Error in initing, API URL is not HTTPS",
+			settings: `{
+				"region": "us-east-1",
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret",
+				"api_url": "http://sns.us-east-1.amazonaws.com"
+			}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": API URL must be an HTTPS AWS endpoint`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			authHeader, form = "", nil
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			awsSettings := AWSSettings{AllowedAuthProviders: []string{"default", "keys", "credentials"}, AssumeRoleEnabled: true}
+			if c.awsSettings != nil {
+				awsSettings = *c.awsSettings
+			}
+
+			m := &NotificationChannelConfig{
+				Name:     "sns_testing",
+				Type:     "sns",
+				Settings: settingsJSON,
+			}
+
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			decryptFn := secretsService.GetDecryptedValue
+			pn, err := NewSNSNotifier(m, tmpl, decryptFn, awsSettings)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			// the stub is not an AWS endpoint, so it can't be set as the API URL in the settings
+			pn.APIURL = stub.URL
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			if c.expMsgError != nil {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.True(t, strings.HasPrefix(authHeader, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"), authHeader)
+			require.Contains(t, authHeader, "/us-east-1/sns/aws4_request")
+			require.Equal(t, c.expForm, form)
+		})
+	}
+}
@@ -14,9 +14,13 @@ import (
 )
 
 var (
-	TelegramAPIURL = "https://api.telegram.org/bot%s/sendMessage"
+	TelegramAPIURL      = "https://api.telegram.org/bot%s/sendMessage"
+	TelegramPhotoAPIURL = "https://api.telegram.org/bot%s/sendPhoto"
 )
 
+// telegramCaptionLengthLimit is the maximum length of the caption of a photo.
+const telegramCaptionLengthLimit = 1024
+
 // TelegramNotifier is responsible for sending
 // alert notifications to Telegram.
 type TelegramNotifier struct {
@@ -96,9 +100,14 @@ func (tn *TelegramNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 		return false, err
 	}
 
+	apiURL := TelegramAPIURL
+	if _, ok := msg["photo"]; ok {
+		apiURL = TelegramPhotoAPIURL
+	}
+
 	tn.log.Info("sending telegram notification", "chat_id", msg["chat_id"])
 	cmd := &models.SendWebhookSync{
-		Url:        fmt.Sprintf(TelegramAPIURL, tn.BotToken),
+		Url:        fmt.Sprintf(apiURL, tn.BotToken),
 		Body:       body.String(),
 		HttpMethod: "POST",
 		HttpHeader: map[string]string{
@@ -127,6 +136,17 @@ func (tn *TelegramNotifier) buildTelegramMessage(ctx context.Context, as []*type
 		tn.log.Debug("failed to template Telegram message", "err", tmplErr.Error())
 	}
 
+	// The image is sent as a photo with the message as caption, unless the message
+	// is too long for a caption, in which case the link to the image is appended.
+	if imageURL := getImageURL(as...); imageURL != "" {
+		if len([]rune(message)) <= telegramCaptionLengthLimit {
+			msg["photo"] = imageURL
+			msg["caption"] = message
+			return msg, nil
+		}
+		message += "\nImage: " + imageURL
+	}
+
 	msg["text"] = message
 
 	return msg, nil
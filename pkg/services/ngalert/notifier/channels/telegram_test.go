@@ -51,6 +51,27 @@ func TestTelegramNotifier(t *testing.T) {
 				"text":       "**Firing**\n\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: a URL\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n",
 			},
 			expMsgError: nil,
+		}, {
+			name: "Default template with an image",
+			settings: `{
+				"bottoken": "abcdefgh0123456789",
+				"chatid": "someid"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__alertImageUrl__": "https://images.example.com/image.png"},
+					},
+				},
+			},
+			expMsg: map[string]string{
+				"chat_id":    "someid",
+				"parse_mode": "html",
+				"photo":      "https://images.example.com/image.png",
+				"caption":    "**Firing**\n\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\n",
+			},
+			expMsgError: nil,
 		}, {
 			name: "Custom template with multiple alerts",
 			settings: `{
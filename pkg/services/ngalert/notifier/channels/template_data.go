@@ -31,6 +31,7 @@ type ExtendedAlert struct {
 	DashboardURL string      `json:"dashboardURL"`
 	PanelURL     string      `json:"panelURL"`
 	ValueString  string      `json:"valueString"`
+	ImageURL     string      `json:"imageURL"`
 }
 
 type ExtendedAlerts []ExtendedAlert
@@ -66,6 +67,7 @@ func extendAlert(alert template.Alert, externalURL string, logger log.Logger) *E
 		EndsAt:       alert.EndsAt,
 		GeneratorURL: alert.GeneratorURL,
 		Fingerprint:  alert.Fingerprint,
+		ImageURL:     alert.Annotations[ngmodels.ImageURLAnnotation],
 	}
 
 	// fill in some grafana-specific urls
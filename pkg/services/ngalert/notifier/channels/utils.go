@@ -12,9 +12,12 @@ import (
 	"path"
 	"time"
 
+	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -116,6 +119,7 @@ var sendHTTPRequest = func(ctx context.Context, url *url.URL, cfg httpCfg, logge
 			logger.Warn("Failed to close response body", "err", err)
 		}
 	}()
+	models.SetWebhookResponseStatusCode(ctx, resp.StatusCode)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -132,6 +136,51 @@ var sendHTTPRequest = func(ctx context.Context, url *url.URL, cfg httpCfg, logge
 	return respBody, nil
 }
 
+// getImageURL returns the URL of the image of the panel of the first firing alert that has one.
+func getImageURL(as ...*types.Alert) string {
+	for _, a := range as {
+		if a.Resolved() {
+			continue
+		}
+		if imageURL := string(a.Annotations[ngmodels.ImageURLAnnotation]); imageURL != "" {
+			return imageURL
+		}
+	}
+	return ""
+}
+
+// downloadImage downloads the image at the URL, up to maxSize bytes.
+// Stubbable by tests.
+var downloadImage = func(ctx context.Context, imageURL string, maxSize int64) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	request.Header.Set("User-Agent", "Grafana")
+	netClient := &http.Client{
+		Timeout: time.Second * 30,
+	}
+	resp, err := netClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to download the image - status code %d", resp.StatusCode)
+	}
+	image, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the image: %w", err)
+	}
+	if int64(len(image)) > maxSize {
+		return nil, fmt.Errorf("the image is larger than %d bytes", maxSize)
+	}
+	return image, nil
+}
+
 func joinUrlPath(base, additionalPath string, logger log.Logger) string {
 	u, err := url.Parse(base)
 	if err != nil {
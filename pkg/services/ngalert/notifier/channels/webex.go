@@ -0,0 +1,119 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// WebexAPIURL is the default endpoint of the Webex messages API.
+var WebexAPIURL = "https://webexapis.com/v1/messages"
+
+// WebexNotifier is responsible for sending
+// alert notifications to Webex Teams rooms.
+type WebexNotifier struct {
+	*Base
+	URL      string
+	BotToken string
+	RoomID   string
+	Message  string
+	log      log.Logger
+	tmpl     *template.Template
+}
+
+// NewWebexNotifier is the constructor for the Webex Teams notifier.
+func NewWebexNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*WebexNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
+	}
+
+	botToken := fn(context.Background(), model.SecureSettings, "bot_token", model.Settings.Get("bot_token").MustString())
+	if botToken == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find Bot Token in settings"}
+	}
+
+	roomID := model.Settings.Get("room_id").MustString()
+	if roomID == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find Room ID in settings"}
+	}
+
+	return &WebexNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		URL:      model.Settings.Get("api_url").MustString(WebexAPIURL),
+		BotToken: botToken,
+		RoomID:   roomID,
+		Message:  model.Settings.Get("message").MustString(`{{ template "default.message" . }}`),
+		log:      log.New("alerting.notifier.webex"),
+		tmpl:     t,
+	}, nil
+}
+
+// webexMessage is the payload of a message created with the Webex messages API.
+// See: https://developer.webex.com/docs/api/v1/messages/create-a-message
+type webexMessage struct {
+	RoomID   string   `json:"roomId"`
+	Markdown string   `json:"markdown"`
+	Files    []string `json:"files,omitempty"`
+}
+
+// Notify sends an alert notification to Webex Teams.
+func (wn *WebexNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	wn.log.Debug("Executing Webex Teams notification", "notification", wn.Name)
+
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, wn.tmpl, as, wn.log, &tmplErr)
+
+	title := tmpl(`{{ template "default.title" . }}`)
+	ruleURL := joinUrlPath(wn.tmpl.ExternalURL.String(), "/alerting/list", wn.log)
+
+	msg := webexMessage{
+		RoomID:   tmpl(wn.RoomID),
+		Markdown: fmt.Sprintf("**[%s](%s)**\n\n%s", title, ruleURL, tmpl(wn.Message)),
+	}
+	if imageURL := getImageURL(as...); imageURL != "" {
+		msg.Files = []string{imageURL}
+	}
+
+	if tmplErr != nil {
+		wn.log.Debug("failed to template Webex Teams message", "err", tmplErr.Error())
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        wn.URL,
+		HttpMethod: "POST",
+		HttpHeader: map[string]string{
+			"Content-Type":  "application/json",
+			"Authorization": fmt.Sprintf("Bearer %s", wn.BotToken),
+		},
+		Body: string(body),
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		wn.log.Error("Failed to send Webex Teams notification", "error", err, "webhook", wn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (wn *WebexNotifier) SendResolved() bool {
+	return !wn.GetDisableResolveMessage()
+}
@@ -0,0 +1,141 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestWebexNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expURL       string
+		expMsg       *webexMessage
+		expInitError string
+		expMsgError  error
+	}{
+		{
+			name:     "Default template with one alert",
+			settings: `{"bot_token": "abcdefgh0123456789", "room_id": "someroom"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__dashboardUid__": "abcd", "__panelId__": "efgh"},
+					},
+				},
+			},
+			expURL: WebexAPIURL,
+			expMsg: &webexMessage{
+				RoomID:   "someroom",
+				Markdown: "**[[FIRING:1]  (val1)](http://localhost/alerting/list)**\n\n**Firing**\n\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n",
+			},
+			expMsgError: nil,
+		}, {
+			name: "Custom template and API URL with an image",
+			settings: `{
+				"bot_token": "abcdefgh0123456789",
+				"room_id": "someroom",
+				"api_url": "http://localhost/webex",
+				"message": "{{ len .Alerts.Firing }} alerts are firing"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__alertImageUrl__": "http://localhost/image.png"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+						Annotations: model.LabelSet{"ann1": "annv2"},
+					},
+				},
+			},
+			expURL: "http://localhost/webex",
+			expMsg: &webexMessage{
+				RoomID:   "someroom",
+				Markdown: "**[[FIRING:2]  ](http://localhost/alerting/list)**\n\n2 alerts are firing",
+				Files:    []string{"http://localhost/image.png"},
+			},
+			expMsgError: nil,
+		}, {
+			name:         "Error in initing, no bot token",
+			settings:     `{"room_id": "someroom"}`,
+			expInitError: `failed to validate receiver "webex_testing" of type "webex": could not find Bot Token in settings`,
+		}, {
+			name:         "Error in initing, no room id",
+			settings:     `{"bot_token": "abcdefgh0123456789"}`,
+			expInitError: `failed to validate receiver "webex_testing" of type "webex": could not find Room ID in settings`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &NotificationChannelConfig{
+				Name:     "webex_testing",
+				Type:     "webex",
+				Settings: settingsJSON,
+			}
+
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			decryptFn := secretsService.GetDecryptedValue
+			pn, err := NewWebexNotifier(m, tmpl, decryptFn)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var webhook *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+				webhook = cmd
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			if c.expMsgError != nil {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, c.expURL, webhook.Url)
+			require.Equal(t, "Bearer abcdefgh0123456789", webhook.HttpHeader["Authorization"])
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+
+			require.JSONEq(t, string(expBody), webhook.Body)
+		})
+	}
+}
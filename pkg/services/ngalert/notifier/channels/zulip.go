@@ -0,0 +1,135 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// zulipTopicLengthLimit is the maximum length, in characters, of the topic of a Zulip stream message.
+const zulipTopicLengthLimit = 60
+
+// ZulipNotifier is responsible for sending
+// alert notifications to Zulip streams or users.
+type ZulipNotifier struct {
+	*Base
+	URL         string
+	BotEmail    string
+	APIKey      string
+	MessageType string
+	To          string
+	Topic       string
+	Message     string
+	log         log.Logger
+	tmpl        *template.Template
+}
+
+// NewZulipNotifier is the constructor for the Zulip notifier.
+func NewZulipNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*ZulipNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
+	}
+
+	serverURL := model.Settings.Get("url").MustString()
+	if serverURL == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find Zulip server URL in settings"}
+	}
+	if _, err := url.Parse(serverURL); err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid Zulip server URL", Err: err}
+	}
+
+	botEmail := model.Settings.Get("bot_email").MustString()
+	if botEmail == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find bot email in settings"}
+	}
+
+	apiKey := fn(context.Background(), model.SecureSettings, "api_key", model.Settings.Get("api_key").MustString())
+	if apiKey == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find API key in settings"}
+	}
+
+	messageType := model.Settings.Get("type").MustString("stream")
+	if messageType != "stream" && messageType != "private" {
+		return nil, receiverInitError{Cfg: *model, Reason: "message type must be either stream or private"}
+	}
+
+	to := model.Settings.Get("to").MustString()
+	if to == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find the stream or the recipients in settings"}
+	}
+
+	return &ZulipNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		URL:         strings.TrimSuffix(serverURL, "/") + "/api/v1/messages",
+		BotEmail:    botEmail,
+		APIKey:      apiKey,
+		MessageType: messageType,
+		To:          to,
+		Topic:       model.Settings.Get("topic").MustString(`{{ template "default.title" . }}`),
+		Message:     model.Settings.Get("message").MustString(`{{ template "default.message" . }}`),
+		log:         log.New("alerting.notifier.zulip"),
+		tmpl:        t,
+	}, nil
+}
+
+// Notify sends an alert notification to Zulip.
+// See: https://zulip.com/api/send-message
+func (zn *ZulipNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	zn.log.Debug("Executing Zulip notification", "notification", zn.Name)
+
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, zn.tmpl, as, zn.log, &tmplErr)
+
+	content := tmpl(zn.Message)
+	if imageURL := getImageURL(as...); imageURL != "" {
+		content += "\n\n[Image](" + imageURL + ")"
+	}
+
+	form := url.Values{}
+	form.Set("type", zn.MessageType)
+	form.Set("to", tmpl(zn.To))
+	form.Set("content", content)
+	if zn.MessageType == "stream" {
+		// Zulip rejects topics that are longer than the limit, truncate by runes to not split multi-byte characters
+		topic, _ := notify.Truncate(strings.Join(strings.Fields(tmpl(zn.Topic)), " "), zulipTopicLengthLimit)
+		form.Set("topic", topic)
+	}
+
+	if tmplErr != nil {
+		zn.log.Debug("failed to template Zulip message", "err", tmplErr.Error())
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         zn.URL,
+		User:        zn.BotEmail,
+		Password:    zn.APIKey,
+		HttpMethod:  "POST",
+		ContentType: "application/x-www-form-urlencoded",
+		Body:        form.Encode(),
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		zn.log.Error("Failed to send Zulip notification", "error", err, "webhook", zn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (zn *ZulipNotifier) SendResolved() bool {
+	return !zn.GetDisableResolveMessage()
+}
@@ -0,0 +1,183 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestZulipNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expURL       string
+		expForm      url.Values
+		expInitError string
+		expMsgError  error
+	}{
+		{
+			name:     "Default template with one alert",
+			settings: `{"url": "https://example.zulipchat.com/", "bot_email": "grafana-bot@example.zulipchat.com", "api_key": "abcdefgh0123456789", "to": "alerts"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__dashboardUid__": "abcd", "__panelId__": "efgh"},
+					},
+				},
+			},
+			expURL: "https://example.zulipchat.com/api/v1/messages",
+			expForm: url.Values{
+				"type":    {"stream"},
+				"to":      {"alerts"},
+				"topic":   {"[FIRING:1] (val1)"},
+				"content": {"**Firing**\n\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n"},
+			},
+			expMsgError: nil,
+		}, {
+			name: "Custom template with an image and a long multi-byte topic",
+			settings: `{
+				"url": "https://example.zulipchat.com",
+				"bot_email": "grafana-bot@example.zulipchat.com",
+				"api_key": "abcdefgh0123456789",
+				"to": "alerts",
+				"topic": "` + strings.Repeat("é", 70) + `",
+				"message": "{{ len .Alerts.Firing }} alerts are firing"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__alertImageUrl__": "http://localhost/image.png"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+						Annotations: model.LabelSet{"ann1": "annv2"},
+					},
+				},
+			},
+			expURL: "https://example.zulipchat.com/api/v1/messages",
+			expForm: url.Values{
+				"type":    {"stream"},
+				"to":      {"alerts"},
+				"topic":   {strings.Repeat("é", 57) + "..."},
+				"content": {"2 alerts are firing\n\n[Image](http://localhost/image.png)"},
+			},
+			expMsgError: nil,
+		}, {
+			name: "Private message",
+			settings: `{
+				"url": "https://example.zulipchat.com",
+				"bot_email": "grafana-bot@example.zulipchat.com",
+				"api_key": "abcdefgh0123456789",
+				"type": "private",
+				"to": "oncall@example.com",
+				"message": "{{ len .Alerts.Firing }} alerts are firing"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expURL: "https://example.zulipchat.com/api/v1/messages",
+			expForm: url.Values{
+				"type":    {"private"},
+				"to":      {"oncall@example.com"},
+				"content": {"1 alerts are firing"},
+			},
+			expMsgError: nil,
+		}, {
+			name:         "Error in initing, no server URL",
+			settings:     `{"bot_email": "grafana-bot@example.zulipchat.com", "api_key": "abcdefgh0123456789", "to": "alerts"}`,
+			expInitError: `failed to validate receiver "zulip_testing" of type "zulip": could not find Zulip server URL in settings`,
+		}, {
+			name:         "Error in initing, no bot email",
+			settings:     `{"url": "https://example.zulipchat.com", "api_key": "abcdefgh0123456789", "to": "alerts"}`,
+			expInitError: `failed to validate receiver "zulip_testing" of type "zulip": could not find bot email in settings`,
+		}, {
+			name:         "Error in initing, no API key",
+			settings:     `{"url": "https://example.zulipchat.com", "bot_email": "grafana-bot@example.zulipchat.com", "to": "alerts"}`,
+			expInitError: `failed to validate receiver "zulip_testing" of type "zulip": could not find API key in settings`,
+		}, {
+			name:         "Error in initing, invalid message type",
+			settings:     `{"url": "https://example.zulipchat.com", "bot_email": "grafana-bot@example.zulipchat.com", "api_key": "abcdefgh0123456789", "type": "topic", "to": "alerts"}`,
+			expInitError: `failed to validate receiver "zulip_testing" of type "zulip": message type must be either stream or private`,
+		}, {
+			name:         "Error in initing, no recipient",
+			settings:     `{"url": "https://example.zulipchat.com", "bot_email": "grafana-bot@example.zulipchat.com", "api_key": "abcdefgh0123456789"}`,
+			expInitError: `failed to validate receiver "zulip_testing" of type "zulip": could not find the stream or the recipients in settings`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &NotificationChannelConfig{
+				Name:     "zulip_testing",
+				Type:     "zulip",
+				Settings: settingsJSON,
+			}
+
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			decryptFn := secretsService.GetDecryptedValue
+			pn, err := NewZulipNotifier(m, tmpl, decryptFn)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var webhook *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+				webhook = cmd
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			if c.expMsgError != nil {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, c.expURL, webhook.Url)
+			require.Equal(t, "grafana-bot@example.zulipchat.com", webhook.User)
+			require.Equal(t, "abcdefgh0123456789", webhook.Password)
+			require.Equal(t, "application/x-www-form-urlencoded", webhook.ContentType)
+
+			form, err := url.ParseQuery(webhook.Body)
+			require.NoError(t, err)
+			require.Equal(t, c.expForm, form)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// deliveryLogSize is the number of notification attempts kept for each integration.
+const deliveryLogSize = 10
+
+// integrationKey identifies an integration of a receiver across configuration changes.
+type integrationKey struct {
+	receiver string
+	index    int
+	uid      string
+}
+
+// delivery is a notification attempt of an integration.
+type delivery struct {
+	timestamp  time.Time
+	duration   time.Duration
+	statusCode int
+	alerts     int
+	err        error
+}
+
+// deliveryLog keeps the last notification attempts of the integrations of an Alertmanager in memory.
+type deliveryLog struct {
+	mtx     sync.RWMutex
+	entries map[integrationKey][]delivery
+}
+
+func newDeliveryLog() *deliveryLog {
+	return &deliveryLog{
+		entries: make(map[integrationKey][]delivery),
+	}
+}
+
+// record adds a notification attempt of an integration, dropping the oldest attempt if there are too many.
+func (l *deliveryLog) record(key integrationKey, d delivery) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	entries := append(l.entries[key], d)
+	if len(entries) > deliveryLogSize {
+		entries = entries[len(entries)-deliveryLogSize:]
+	}
+	l.entries[key] = entries
+}
+
+// get returns the last notification attempts of an integration, the most recent first.
+func (l *deliveryLog) get(key integrationKey) []delivery {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	entries := l.entries[key]
+	res := make([]delivery, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		res = append(res, entries[i])
+	}
+	return res
+}
+
+// retain removes the notification attempts of the integrations that are not in keys.
+func (l *deliveryLog) retain(keys map[integrationKey]struct{}) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for k := range l.entries {
+		if _, ok := keys[k]; !ok {
+			delete(l.entries, k)
+		}
+	}
+}
+
+// integrationKeys returns the keys of the integrations of the receivers in the delivery log.
+func integrationKeys(receivers []*apimodels.PostableApiReceiver) map[integrationKey]struct{} {
+	keys := make(map[integrationKey]struct{})
+	for _, receiver := range receivers {
+		for i, r := range receiver.GrafanaManagedReceivers {
+			keys[integrationKey{receiver: receiver.Name, index: i, uid: r.UID}] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// deliveryRecorder records every notification attempt of an integration in the delivery log.
+type deliveryRecorder struct {
+	NotificationChannel
+	key    integrationKey
+	log    *deliveryLog
+	logger log.Logger
+}
+
+func (r *deliveryRecorder) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	resp := &models.WebhookResponse{}
+	start := time.Now()
+	retry, err := r.NotificationChannel.Notify(models.WithWebhookResponse(ctx, resp), as...)
+	d := delivery{
+		timestamp:  start,
+		duration:   time.Since(start),
+		statusCode: resp.StatusCode,
+		alerts:     len(as),
+		err:        err,
+	}
+	r.log.record(r.key, d)
+	r.logger.Debug("notification attempt", "receiver", r.key.receiver, "integration", r.key.index, "statusCode", d.statusCode, "duration", d.duration, "err", err)
+	return retry, err
+}
+
+// GetReceiversStatus returns the last notification attempts of the integrations of the receivers of the configuration.
+func (am *Alertmanager) GetReceiversStatus() apimodels.GettableReceiversStatus {
+	am.reloadConfigMtx.RLock()
+	defer am.reloadConfigMtx.RUnlock()
+
+	res := apimodels.GettableReceiversStatus{}
+	if !am.ready() {
+		return res
+	}
+	for _, receiver := range am.config.AlertmanagerConfig.Receivers {
+		status := apimodels.GettableReceiverStatus{
+			Name:         receiver.Name,
+			Integrations: make([]apimodels.GettableIntegrationStatus, 0, len(receiver.GrafanaManagedReceivers)),
+		}
+		for i, r := range receiver.GrafanaManagedReceivers {
+			integration := apimodels.GettableIntegrationStatus{
+				UID:          r.UID,
+				Name:         r.Name,
+				Type:         r.Type,
+				Index:        i,
+				SendResolved: !r.DisableResolveMessage,
+				Deliveries:   []apimodels.NotificationDelivery{},
+			}
+			for _, d := range am.deliveries.get(integrationKey{receiver: receiver.Name, index: i, uid: r.UID}) {
+				integration.Deliveries = append(integration.Deliveries, toNotificationDelivery(d))
+			}
+			if len(integration.Deliveries) > 0 {
+				last := integration.Deliveries[0]
+				integration.LastNotifyAttempt = last.Timestamp
+				integration.LastNotifyAttemptDuration = last.Duration
+				integration.LastNotifyAttemptStatusCode = last.StatusCode
+				integration.LastNotifyAttemptError = last.Error
+			}
+			status.Integrations = append(status.Integrations, integration)
+		}
+		res = append(res, status)
+	}
+	return res
+}
+
+func toNotificationDelivery(d delivery) apimodels.NotificationDelivery {
+	res := apimodels.NotificationDelivery{
+		Timestamp:  d.timestamp,
+		Duration:   d.duration.String(),
+		StatusCode: d.statusCode,
+		Alerts:     d.alerts,
+	}
+	if d.err != nil {
+		res.Error = d.err.Error()
+	}
+	return res
+}
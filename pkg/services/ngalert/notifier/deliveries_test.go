@@ -0,0 +1,138 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+type fakeNotificationChannel struct {
+	statusCode int
+	err        error
+}
+
+func (f *fakeNotificationChannel) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	models.SetWebhookResponseStatusCode(ctx, f.statusCode)
+	return f.err != nil, f.err
+}
+
+func (f *fakeNotificationChannel) SendResolved() bool {
+	return true
+}
+
+func TestDeliveryLog(t *testing.T) {
+	l := newDeliveryLog()
+	key := integrationKey{receiver: "receiver", index: 0, uid: "uid"}
+	for i := 0; i < deliveryLogSize+5; i++ {
+		l.record(key, delivery{alerts: i})
+	}
+
+	entries := l.get(key)
+	require.Len(t, entries, deliveryLogSize)
+	require.Equal(t, deliveryLogSize+4, entries[0].alerts)
+	require.Equal(t, 5, entries[deliveryLogSize-1].alerts)
+	require.Empty(t, l.get(integrationKey{receiver: "receiver", index: 1, uid: "uid"}))
+
+	l.retain(map[integrationKey]struct{}{})
+	require.Empty(t, l.get(key))
+}
+
+func TestDeliveryRecorder(t *testing.T) {
+	l := newDeliveryLog()
+	key := integrationKey{receiver: "receiver", index: 1, uid: "uid"}
+	n := &fakeNotificationChannel{statusCode: http.StatusOK}
+	r := &deliveryRecorder{NotificationChannel: n, key: key, log: l, logger: log.New("test")}
+
+	retry, err := r.Notify(context.Background(), &types.Alert{}, &types.Alert{})
+	require.NoError(t, err)
+	require.False(t, retry)
+
+	n.statusCode, n.err = http.StatusBadGateway, errors.New("bad gateway")
+	retry, err = r.Notify(context.Background(), &types.Alert{})
+	require.Error(t, err)
+	require.True(t, retry)
+
+	entries := l.get(key)
+	require.Len(t, entries, 2)
+	require.Equal(t, http.StatusBadGateway, entries[0].statusCode)
+	require.Equal(t, 1, entries[0].alerts)
+	require.EqualError(t, entries[0].err, "bad gateway")
+	require.Equal(t, http.StatusOK, entries[1].statusCode)
+	require.Equal(t, 2, entries[1].alerts)
+	require.NoError(t, entries[1].err)
+}
+
+func TestGetReceiversStatus(t *testing.T) {
+	am := setupAMTest(t)
+	require.Empty(t, am.GetReceiversStatus())
+
+	cfg := &apimodels.PostableUserConfig{
+		AlertmanagerConfig: apimodels.PostableApiAlertingConfig{
+			Config: apimodels.Config{Route: &apimodels.Route{Receiver: "team"}},
+			Receivers: []*apimodels.PostableApiReceiver{{
+				Receiver: config.Receiver{Name: "team"},
+				PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+					GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+						{UID: "webhook", Name: "webhook", Type: "webhook", Settings: simplejson.NewFromAny(map[string]interface{}{"url": "http://localhost"})},
+						{UID: "email", Name: "email", Type: "email", DisableResolveMessage: true, Settings: simplejson.NewFromAny(map[string]interface{}{"addresses": "team@example.com"})},
+					},
+				},
+			}},
+		},
+	}
+	require.NoError(t, am.applyConfig(cfg, nil))
+
+	now := time.Now()
+	am.deliveries.record(integrationKey{receiver: "team", index: 0, uid: "webhook"}, delivery{
+		timestamp:  now,
+		duration:   time.Second,
+		statusCode: http.StatusInternalServerError,
+		alerts:     1,
+		err:        errors.New("internal server error"),
+	})
+	// attempts of integrations that are no longer in the configuration are not returned
+	am.deliveries.record(integrationKey{receiver: "team", index: 1, uid: "slack"}, delivery{timestamp: now})
+
+	require.Equal(t, apimodels.GettableReceiversStatus{{
+		Name: "team",
+		Integrations: []apimodels.GettableIntegrationStatus{
+			{
+				UID:                         "webhook",
+				Name:                        "webhook",
+				Type:                        "webhook",
+				Index:                       0,
+				SendResolved:                true,
+				LastNotifyAttempt:           now,
+				LastNotifyAttemptDuration:   "1s",
+				LastNotifyAttemptStatusCode: http.StatusInternalServerError,
+				LastNotifyAttemptError:      "internal server error",
+				Deliveries: []apimodels.NotificationDelivery{{
+					Timestamp:  now,
+					Duration:   "1s",
+					StatusCode: http.StatusInternalServerError,
+					Alerts:     1,
+					Error:      "internal server error",
+				}},
+			},
+			{
+				UID:          "email",
+				Name:         "email",
+				Type:         "email",
+				Index:        1,
+				SendResolved: false,
+				Deliveries:   []apimodels.NotificationDelivery{},
+			},
+		},
+	}}, am.GetReceiversStatus())
+}
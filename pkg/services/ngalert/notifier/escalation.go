@@ -0,0 +1,106 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// escalation notifies a receiver of the firing alerts that are not acknowledged after they
+// have been firing for a given duration.
+type escalation struct {
+	after    time.Duration
+	receiver string
+	stage    notify.Stage
+}
+
+// escalationStage runs the escalations of the route of the aggregation group of the alerts.
+// It runs along the stage of the receiver of the route, after the silencing and inhibition stages,
+// so that escalations are not sent for muted alerts.
+//
+// Escalations are evaluated every time the aggregation group is flushed, so they are sent up to a
+// group interval after their delay.
+type escalationStage struct {
+	// escalations by route key
+	escalations      map[string][]escalation
+	acknowledgements *acknowledgements
+}
+
+func (s *escalationStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	groupKey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return ctx, nil, fmt.Errorf("group key missing")
+	}
+	escalations := s.escalationsFor(groupKey)
+	if len(escalations) == 0 {
+		return ctx, alerts, nil
+	}
+
+	now, ok := notify.Now(ctx)
+	if !ok {
+		now = time.Now()
+	}
+
+	var errs types.MultiError
+	for _, e := range escalations {
+		var escalated []*types.Alert
+		for _, a := range alerts {
+			// resolved alerts are passed on so that receivers that were notified are notified of the resolution
+			if a.ResolvedAt(now) || (now.Sub(a.StartsAt) >= e.after && !s.acknowledgements.isAcknowledged(a)) {
+				escalated = append(escalated, a)
+			}
+		}
+		if len(escalated) == 0 {
+			continue
+		}
+
+		ctx := notify.WithReceiverName(ctx, e.receiver)
+		if _, _, err := e.stage.Exec(ctx, l, escalated...); err != nil {
+			level.Error(l).Log("msg", "failed to notify the escalation receiver", "receiver", e.receiver, "err", err)
+			errs.Add(err)
+		}
+	}
+
+	if errs.Len() > 0 {
+		return ctx, alerts, &errs
+	}
+	return ctx, alerts, nil
+}
+
+// escalationsFor returns the escalations of the route of the aggregation group with the given key,
+// which is the key of the route followed by the labels of the group.
+func (s *escalationStage) escalationsFor(groupKey string) []escalation {
+	var routeKey string
+	for k := range s.escalations {
+		if strings.HasPrefix(groupKey, k+":") && len(k) > len(routeKey) {
+			routeKey = k
+		}
+	}
+	return s.escalations[routeKey]
+}
+
+// routeEscalations returns the escalations of a routing tree by route key. The route is the Alertmanager
+// route built from the Grafana route, so that they have the same structure.
+func routeEscalations(route *dispatch.Route, r *apimodels.Route, res map[string][]*apimodels.Escalation) {
+	if route == nil || r == nil {
+		return
+	}
+	key := route.Key()
+	if _, ok := res[key]; !ok && len(r.Escalations) > 0 {
+		res[key] = r.Escalations
+	}
+	for i := range route.Routes {
+		if i < len(r.Routes) {
+			routeEscalations(route.Routes[i], r.Routes[i], res)
+		}
+	}
+}
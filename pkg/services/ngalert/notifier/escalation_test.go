@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+type recordingStage struct {
+	mtx      sync.Mutex
+	receiver []string
+	alerts   [][]*types.Alert
+	err      error
+}
+
+func (s *recordingStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	name, _ := notify.ReceiverName(ctx)
+	s.receiver = append(s.receiver, name)
+	s.alerts = append(s.alerts, alerts)
+	return ctx, alerts, s.err
+}
+
+func TestEscalationStage(t *testing.T) {
+	now := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	newAlert := func(name string, firingFor time.Duration, resolved bool) *types.Alert {
+		a := &types.Alert{Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": model.LabelValue(name)},
+			StartsAt: now.Add(-firingFor),
+			EndsAt:   now.Add(time.Hour),
+		}}
+		if resolved {
+			a.EndsAt = now.Add(-time.Second)
+		}
+		return a
+	}
+	recent := newAlert("recent", 5*time.Minute, false)
+	old := newAlert("old", 20*time.Minute, false)
+	acknowledged := newAlert("acknowledged", 20*time.Minute, false)
+	resolved := newAlert("resolved", 20*time.Minute, true)
+
+	acks := newAcknowledgements(time.Hour)
+	acks.set(&acknowledgement{Fingerprint: acknowledged.Fingerprint().String(), StartsAt: acknowledged.StartsAt, UpdatedAt: now})
+
+	team, manager := &recordingStage{}, &recordingStage{}
+	s := &escalationStage{
+		escalations: map[string][]escalation{
+			"{}/{team=\"a\"}": {
+				{after: 15 * time.Minute, receiver: "team-lead", stage: team},
+				{after: time.Hour, receiver: "manager", stage: manager},
+			},
+		},
+		acknowledgements: acks,
+	}
+
+	ctx := notify.WithNow(context.Background(), now)
+	exec := func(groupKey string, alerts ...*types.Alert) error {
+		_, res, err := s.Exec(notify.WithGroupKey(ctx, groupKey), log.NewNopLogger(), alerts...)
+		// the alerts are passed on whatever the escalations
+		require.Equal(t, alerts, res)
+		return err
+	}
+
+	t.Run("firing alerts that are not acknowledged after the delay are escalated", func(t *testing.T) {
+		require.NoError(t, exec(`{}/{team="a"}:{alertname="x"}`, recent, old, acknowledged, resolved))
+		require.Equal(t, []string{"team-lead"}, team.receiver)
+		require.Equal(t, [][]*types.Alert{{old, resolved}}, team.alerts)
+		// resolved alerts alone are passed on for the notification of their resolution
+		require.Equal(t, [][]*types.Alert{{resolved}}, manager.alerts)
+	})
+
+	t.Run("groups of other routes are not escalated", func(t *testing.T) {
+		team.alerts, manager.alerts = nil, nil
+		require.NoError(t, exec(`{}/{team="b"}:{alertname="x"}`, old))
+		require.NoError(t, exec(`{}:{alertname="x"}`, old))
+		require.Empty(t, team.alerts)
+		require.Empty(t, manager.alerts)
+	})
+
+	t.Run("nothing is escalated before the delay", func(t *testing.T) {
+		require.NoError(t, exec(`{}/{team="a"}:{alertname="x"}`, recent))
+		require.Empty(t, team.alerts)
+		require.Empty(t, manager.alerts)
+	})
+
+	t.Run("errors of escalations are returned", func(t *testing.T) {
+		team.err = errors.New("failed")
+		require.Error(t, exec(`{}/{team="a"}:{alertname="x"}`, old))
+	})
+}
+
+func TestRouteEscalations(t *testing.T) {
+	escalation := []*apimodels.Escalation{{After: model.Duration(15 * time.Minute), Receiver: "team-lead"}}
+	r := &apimodels.Route{
+		Receiver: "default",
+		Routes: []*apimodels.Route{
+			{
+				Receiver:       "team",
+				ObjectMatchers: apimodels.ObjectMatchers{{Type: labels.MatchEqual, Name: "team", Value: "a"}},
+				Escalations:    escalation,
+			},
+			{
+				Receiver:       "team",
+				ObjectMatchers: apimodels.ObjectMatchers{{Type: labels.MatchEqual, Name: "team", Value: "b"}},
+			},
+		},
+	}
+	route := dispatch.NewRoute(r.AsAMRoute(), nil)
+
+	res := make(map[string][]*apimodels.Escalation)
+	routeEscalations(route, r, res)
+	require.Equal(t, map[string][]*apimodels.Escalation{`{}/{team="a"}`: escalation}, res)
+
+	// the keys are the ones of the aggregation groups of the routes
+	require.Equal(t, `{}/{team="a"}`, route.Match(model.LabelSet{"team": "a"})[0].Key())
+}
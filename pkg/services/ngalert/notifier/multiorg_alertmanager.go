@@ -297,6 +297,11 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// Peer returns the peer of the Alertmanager peer mesh this instance is part of.
+func (moa *MultiOrgAlertmanager) Peer() ClusterPeer {
+	return moa.peer
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// RecurringSilenceScheduler materializes the occurrences of the recurring silences into silences of the
+// Alertmanagers of their organizations. Each occurrence is materialized shortly before it starts, or as soon
+// as possible if it's already active, and only once across the Grafana instances sharing the database.
+type RecurringSilenceScheduler struct {
+	store    store.SilenceStore
+	moa      *MultiOrgAlertmanager
+	interval time.Duration
+	logger   log.Logger
+}
+
+func NewRecurringSilenceScheduler(store store.SilenceStore, moa *MultiOrgAlertmanager, interval time.Duration, logger log.Logger) *RecurringSilenceScheduler {
+	return &RecurringSilenceScheduler{
+		store:    store,
+		moa:      moa,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run materializes the recurring silences at every interval until the context is done.
+func (s *RecurringSilenceScheduler) Run(ctx context.Context) error {
+	s.logger.Info("starting recurring silence scheduler", "interval", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Materialize(ctx, time.Now()); err != nil {
+			s.logger.Error("failed to materialize the recurring silences", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Materialize creates the silences of the occurrences of the recurring silences that are active at now or
+// start before the next run.
+func (s *RecurringSilenceScheduler) Materialize(ctx context.Context, now time.Time) error {
+	q := models.ListRecurringSilencesQuery{}
+	if err := s.store.ListRecurringSilences(ctx, &q); err != nil {
+		return err
+	}
+
+	for _, rs := range q.Result {
+		if err := s.materialize(ctx, rs, now); err != nil {
+			s.logger.Error("failed to materialize recurring silence", "org", rs.OrgID, "uid", rs.UID, "err", err)
+		}
+	}
+	return nil
+}
+
+func (s *RecurringSilenceScheduler) materialize(ctx context.Context, rs *models.RecurringSilence, now time.Time) error {
+	startsAt, endsAt, err := rs.NextOccurrence(now)
+	if err != nil {
+		return err
+	}
+	if startsAt.After(now.Add(s.interval)) || startsAt.Unix() <= rs.LastStartsAt {
+		return nil
+	}
+
+	am, err := s.moa.AlertmanagerFor(rs.OrgID)
+	if err != nil {
+		// the Alertmanager of the organization is disabled or not synchronized yet
+		s.logger.Debug("skipping recurring silence", "org", rs.OrgID, "uid", rs.UID, "err", err)
+		return nil
+	}
+
+	comment := rs.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Recurring silence %s", rs.UID)
+	}
+	// the silence is created before the occurrence is claimed, so that a failure to create it does not skip the occurrence
+	silenceID, err := am.CreateSilence(NewPostableSilence(rs.Matchers, comment, rs.CreatedBy, startsAt, endsAt))
+	if err != nil {
+		return err
+	}
+
+	claimed, err := s.store.ClaimRecurringSilenceOccurrence(ctx, rs.ID, startsAt.Unix(), silenceID)
+	if err != nil || !claimed {
+		// another Grafana instance materialized the occurrence, or it's materialized again at the next run
+		if err := am.DeleteSilence(silenceID); err != nil {
+			s.logger.Error("failed to expire the silence of an unclaimed occurrence", "org", rs.OrgID, "uid", rs.UID, "silence", silenceID, "err", err)
+		}
+		return err
+	}
+	s.logger.Debug("materialized recurring silence", "org", rs.OrgID, "uid", rs.UID, "silence", silenceID, "startsAt", startsAt, "endsAt", endsAt)
+	return nil
+}
@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeSilenceStore struct {
+	store.SilenceStore
+	recurring []*models.RecurringSilence
+	claimErr  error
+}
+
+func (f *fakeSilenceStore) ListRecurringSilences(_ context.Context, query *models.ListRecurringSilencesQuery) error {
+	query.Result = f.recurring
+	return nil
+}
+
+func (f *fakeSilenceStore) ClaimRecurringSilenceOccurrence(_ context.Context, id int64, startsAt int64, silenceID string) (bool, error) {
+	if f.claimErr != nil {
+		return false, f.claimErr
+	}
+	for _, rs := range f.recurring {
+		if rs.ID == id && rs.LastStartsAt < startsAt {
+			rs.LastStartsAt, rs.SilenceID = startsAt, silenceID
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRecurringSilenceScheduler(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test")
+	require.NoError(t, err)
+	t.Cleanup(cleanOrgDirectories(tmpDir, t))
+
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+	m := metrics.NewNGAlert(prometheus.NewPedanticRegistry())
+	cfg := &setting.Cfg{
+		DataPath: tmpDir,
+		UnifiedAlerting: setting.UnifiedAlertingSettings{
+			AlertmanagerConfigPollInterval: 3 * time.Minute,
+			DefaultConfiguration:           setting.GetAlertmanagerDefaultConfiguration(),
+		},
+	}
+	configStore := &FakeConfigStore{configs: map[int64]*models.AlertConfiguration{}}
+	orgStore := &FakeOrgStore{orgs: []int64{1}}
+	moa, err := NewMultiOrgAlertmanager(cfg, configStore, orgStore, newFakeKVStore(t), secretsService.GetDecryptedValue, m.GetMultiOrgAlertmanagerMetrics(), log.New("testlogger"))
+	require.NoError(t, err)
+	require.NoError(t, moa.LoadAndSyncAlertmanagersForOrgs(context.Background()))
+
+	matchers := []models.SilenceMatcher{{Name: "team", Value: "db", IsEqual: true}}
+	silenceStore := &fakeSilenceStore{recurring: []*models.RecurringSilence{
+		{
+			ID:        1,
+			OrgID:     1,
+			UID:       "sunday",
+			Matchers:  matchers,
+			Comment:   "DB maintenance",
+			CreatedBy: "admin",
+			WeeklyWindows: []models.WeeklyWindow{
+				{Weekdays: []time.Weekday{time.Sunday}, StartTime: "02:00", EndTime: "04:00"},
+			},
+		},
+		{
+			// the organization has no Alertmanager
+			ID:       2,
+			OrgID:    2,
+			UID:      "hourly",
+			Matchers: matchers,
+			Cron:     "0 * * * *",
+			Duration: time.Minute,
+		},
+	}}
+	s := NewRecurringSilenceScheduler(silenceStore, moa, time.Minute, log.New("testlogger"))
+	am, err := moa.AlertmanagerFor(1)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	sunday := now.AddDate(0, 0, 7-int(now.Weekday()))
+	start := time.Date(sunday.Year(), sunday.Month(), sunday.Day(), 2, 0, 0, 0, time.UTC)
+
+	t.Run("occurrences are not materialized before they are about to start", func(t *testing.T) {
+		require.NoError(t, s.Materialize(ctx, start.Add(-2*time.Minute)))
+		silences, err := am.ListSilences(nil)
+		require.NoError(t, err)
+		require.Empty(t, silences)
+	})
+
+	t.Run("occurrences are materialized once before they start", func(t *testing.T) {
+		require.NoError(t, s.Materialize(ctx, start.Add(-30*time.Second)))
+		require.NoError(t, s.Materialize(ctx, start.Add(-10*time.Second)))
+		silences, err := am.ListSilences(nil)
+		require.NoError(t, err)
+		require.Len(t, silences, 1)
+		require.Equal(t, "DB maintenance", *silences[0].Comment)
+		require.Equal(t, "admin", *silences[0].CreatedBy)
+		require.Equal(t, "team", *silences[0].Matchers[0].Name)
+		require.True(t, start.Equal(time.Time(*silences[0].StartsAt)))
+		require.True(t, start.Add(2*time.Hour).Equal(time.Time(*silences[0].EndsAt)))
+		require.Equal(t, start.Unix(), silenceStore.recurring[0].LastStartsAt)
+		require.Equal(t, *silences[0].ID, silenceStore.recurring[0].SilenceID)
+		require.Zero(t, silenceStore.recurring[1].LastStartsAt)
+	})
+
+	t.Run("the silence is expired if the occurrence cannot be claimed", func(t *testing.T) {
+		silenceStore.recurring[0].LastStartsAt, silenceStore.recurring[0].SilenceID = 0, ""
+		silenceStore.claimErr = errors.New("database is locked")
+		defer func() { silenceStore.claimErr = nil }()
+
+		require.NoError(t, s.Materialize(ctx, start.Add(-10*time.Second)))
+		silences, err := am.ListSilences(nil)
+		require.NoError(t, err)
+		require.Len(t, silences, 2)
+		active := 0
+		for _, silence := range silences {
+			if *silence.Status.State != "expired" {
+				active++
+			}
+		}
+		require.Equal(t, 1, active)
+		require.Zero(t, silenceStore.recurring[0].LastStartsAt)
+	})
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/go-openapi/strfmt"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	v2 "github.com/prometheus/alertmanager/api/v2"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/silence"
 )
 
@@ -117,3 +120,22 @@ func (am *Alertmanager) DeleteSilence(silenceID string) error {
 
 	return nil
 }
+
+// NewPostableSilence returns the silence with the given matchers, from startsAt to endsAt.
+func NewPostableSilence(matchers []ngmodels.SilenceMatcher, comment, createdBy string, startsAt, endsAt time.Time) *apimodels.PostableSilence {
+	ms := make(amv2.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		m := m
+		ms = append(ms, &amv2.Matcher{Name: &m.Name, Value: &m.Value, IsRegex: &m.IsRegex, IsEqual: &m.IsEqual})
+	}
+	start, end := strfmt.DateTime(startsAt), strfmt.DateTime(endsAt)
+	return &apimodels.PostableSilence{
+		Silence: amv2.Silence{
+			Matchers:  ms,
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			StartsAt:  &start,
+			EndsAt:    &end,
+		},
+	}
+}
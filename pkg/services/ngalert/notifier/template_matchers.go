@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"strings"
+	tmpltext "text/template"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// routeTemplateMatchers adds the template matchers of a routing tree to the matchers of the Alertmanager
+// routing tree that was created from it, and adds the templates of the matchers to res by the name of
+// the label their result is stored in.
+func routeTemplateMatchers(amRoute *config.Route, r *apimodels.Route, res map[string]*tmpltext.Template) error {
+	if len(r.TemplateMatchers) > 0 {
+		// the matchers can share their backing array with the matchers of the Grafana route
+		matchers := make(config.Matchers, 0, len(amRoute.Matchers)+len(r.TemplateMatchers))
+		matchers = append(matchers, amRoute.Matchers...)
+		for _, m := range r.TemplateMatchers {
+			matcher, err := m.Matcher()
+			if err != nil {
+				return err
+			}
+			tmpl, err := m.ParseTemplate()
+			if err != nil {
+				return err
+			}
+			matchers = append(matchers, matcher)
+			res[m.LabelName()] = tmpl
+		}
+		amRoute.Matchers = matchers
+	}
+
+	// the Alertmanager routing tree is created from the routing tree, so they have the same shape
+	if len(amRoute.Routes) == len(r.Routes) {
+		for i := range amRoute.Routes {
+			if err := routeTemplateMatchers(amRoute.Routes[i], r.Routes[i], res); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setTemplateMatcherLabels executes the templates of the template matchers with the labels and the annotations
+// of the alert, and sets the results as private labels of the alert, so that the routing tree can match them.
+// Empty results are skipped like empty labels.
+func setTemplateMatcherLabels(templates map[string]*tmpltext.Template, alert *types.Alert, logger log.Logger) {
+	if len(templates) == 0 {
+		return
+	}
+
+	data := apimodels.TemplateMatcherData{
+		Labels:      make(map[string]string, len(alert.Labels)),
+		Annotations: make(map[string]string, len(alert.Annotations)),
+	}
+	for k, v := range alert.Labels {
+		data.Labels[string(k)] = string(v)
+	}
+	for k, v := range alert.Annotations {
+		data.Annotations[string(k)] = string(v)
+	}
+
+	for name, tmpl := range templates {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			logger.Debug("failed to execute the template of a template matcher", "label", name, "err", err)
+			continue
+		}
+		if sb.Len() == 0 {
+			continue
+		}
+		alert.Labels[model.LabelName(name)] = model.LabelValue(sb.String())
+	}
+}
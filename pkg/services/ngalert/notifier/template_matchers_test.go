@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"testing"
+	tmpltext "text/template"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestTemplateMatchers(t *testing.T) {
+	folder := &apimodels.TemplateMatcher{Template: `{{ .Labels.grafana_folder | toLower }}`, Type: "=~", Value: "team-.*"}
+	severity := &apimodels.TemplateMatcher{Template: `{{ if eq .Annotations.severity "critical" }}page{{ end }}`, Type: "=", Value: "page"}
+	r := &apimodels.Route{
+		Receiver: "default",
+		Routes: []*apimodels.Route{
+			{
+				Receiver:         "on-call",
+				ObjectMatchers:   apimodels.ObjectMatchers{{Type: labels.MatchEqual, Name: "env", Value: "prod"}},
+				TemplateMatchers: []*apimodels.TemplateMatcher{severity},
+			},
+			{
+				Receiver:         "teams",
+				TemplateMatchers: []*apimodels.TemplateMatcher{folder},
+			},
+		},
+	}
+
+	amRoute := r.AsAMRoute()
+	templates := map[string]*tmpltext.Template{}
+	require.NoError(t, routeTemplateMatchers(amRoute, r, templates))
+	require.Len(t, templates, 2)
+	require.Len(t, r.Routes[0].ObjectMatchers, 1)
+	route := dispatch.NewRoute(amRoute, nil)
+
+	receiver := func(lbs, annotations model.LabelSet) string {
+		alert := &types.Alert{Alert: model.Alert{Labels: lbs, Annotations: annotations}}
+		setTemplateMatcherLabels(templates, alert, log.New("test"))
+		return route.Match(alert.Labels)[0].RouteOpts.Receiver
+	}
+
+	require.Equal(t, "on-call", receiver(model.LabelSet{"env": "prod"}, model.LabelSet{"severity": "critical"}))
+	require.Equal(t, "default", receiver(model.LabelSet{"env": "dev"}, model.LabelSet{"severity": "critical"}))
+	require.Equal(t, "teams", receiver(model.LabelSet{"env": "prod", "grafana_folder": "Team-A"}, model.LabelSet{"severity": "warning"}))
+	require.Equal(t, "default", receiver(model.LabelSet{"grafana_folder": "Infrastructure"}, model.LabelSet{}))
+
+	t.Run("empty results are not set as labels", func(t *testing.T) {
+		alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"env": "prod"}, Annotations: model.LabelSet{}}}
+		setTemplateMatcherLabels(templates, alert, log.New("test"))
+		require.Equal(t, model.LabelSet{"env": "prod"}, alert.Labels)
+	})
+}
@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	tmpltext "text/template"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/logging"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+var (
+	ErrNoTemplates             = errors.New("no templates")
+	ErrInvalidTemplateFileName = errors.New("invalid template file name")
+	ErrInvalidTestAlert        = errors.New("invalid test alert")
+
+	// templateErrorLineRegex matches the file and line number text/template prefixes its errors with.
+	templateErrorLineRegex = regexp.MustCompile(`^template: [^:]+:(\d+)`)
+)
+
+type TemplateErrorKind string
+
+const (
+	InvalidTemplate TemplateErrorKind = "invalid_template"
+	ExecutionError  TemplateErrorKind = "execution_error"
+)
+
+type TestTemplatesResults struct {
+	Results []TestTemplatesResult
+	Errors  []TestTemplatesErrorResult
+}
+
+type TestTemplatesResult struct {
+	Name string
+	Text string
+}
+
+type TestTemplatesErrorResult struct {
+	// Name is the name of the template, or of the template file for invalid templates.
+	Name string
+	Kind TemplateErrorKind
+	// Line is the line of the error in the template file, 0 if unknown.
+	Line  int
+	Error error
+}
+
+// TestTemplates renders every template defined in the template files of the request with the alerts of the
+// request, or a test alert if there are none. The template files are added to the templates of the current
+// configuration, replacing the files with the same names, so they can use the templates defined there.
+// Errors in the templates are part of the results, an error is returned only if the request is invalid.
+func (am *Alertmanager) TestTemplates(ctx context.Context, c apimodels.TestTemplatesConfigParams) (*TestTemplatesResults, error) {
+	if len(c.Templates) == 0 {
+		return nil, ErrNoTemplates
+	}
+	for i, a := range c.Alerts {
+		if a == nil {
+			return nil, fmt.Errorf("%w: alert %d is null", ErrInvalidTestAlert, i)
+		}
+	}
+
+	am.reloadConfigMtx.RLock()
+	templateFiles := map[string]string{
+		"__default__.tmpl": channels.DefaultTemplateString,
+	}
+	if am.config != nil {
+		for name, content := range am.config.TemplateFiles {
+			templateFiles[name] = content
+		}
+	}
+	am.reloadConfigMtx.RUnlock()
+
+	results := &TestTemplatesResults{}
+
+	// the templates of the request are parsed on their own first, to find the templates they define
+	var names []string
+	for file, content := range c.Templates {
+		if file != filepath.Base(filepath.Clean(file)) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTemplateFileName, file)
+		}
+		t, err := tmpltext.New(file).Option("missingkey=zero").Funcs(tmpltext.FuncMap(template.DefaultFuncs)).Parse(content)
+		if err != nil {
+			results.Errors = append(results.Errors, newTestTemplatesErrorResult(file, InvalidTemplate, err))
+			continue
+		}
+		for _, defined := range t.Templates() {
+			if defined.Name() != file {
+				names = append(names, defined.Name())
+			}
+		}
+		templateFiles[file] = content
+	}
+	if len(results.Errors) > 0 {
+		sortTestTemplatesErrorResults(results.Errors)
+		return results, nil
+	}
+
+	dir, err := os.MkdirTemp("", "alertmanager-templates-test")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the template directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			am.logger.Warn("failed to remove the template directory", "dir", dir, "err", err)
+		}
+	}()
+
+	paths, _, err := PersistTemplates(&apimodels.PostableUserConfig{TemplateFiles: templateFiles}, dir)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := am.templateFromPaths(paths...)
+	if err != nil {
+		results.Errors = append(results.Errors, newTestTemplatesErrorResult("", InvalidTemplate, err))
+		return results, nil
+	}
+
+	now := time.Now()
+	alerts := newTestTemplatesAlerts(c, now)
+	ctx = notify.WithGroupKey(ctx, "test-templates")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": alerts[0].Labels["alertname"]})
+	ctx = notify.WithReceiverName(ctx, "TestReceiver")
+	data := channels.ExtendData(notify.GetTemplateData(ctx, tmpl, alerts, gokit_log.NewLogfmtLogger(logging.NewWrapper(am.logger))), am.logger)
+
+	sort.Strings(names)
+	for _, name := range names {
+		text, err := tmpl.ExecuteTextString(fmt.Sprintf(`{{ template %q . }}`, name), data)
+		if err != nil {
+			results.Errors = append(results.Errors, newTestTemplatesErrorResult(name, ExecutionError, err))
+			continue
+		}
+		results.Results = append(results.Results, TestTemplatesResult{Name: name, Text: text})
+	}
+	return results, nil
+}
+
+func newTestTemplatesErrorResult(name string, kind TemplateErrorKind, err error) TestTemplatesErrorResult {
+	result := TestTemplatesErrorResult{Name: name, Kind: kind, Error: err}
+	if m := templateErrorLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		result.Line, _ = strconv.Atoi(m[1])
+	}
+	return result
+}
+
+func sortTestTemplatesErrorResults(errs []TestTemplatesErrorResult) {
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Name < errs[j].Name
+	})
+}
+
+// newTestTemplatesAlerts returns the alerts of the request, or a firing test alert if there are none.
+func newTestTemplatesAlerts(c apimodels.TestTemplatesConfigParams, now time.Time) []*types.Alert {
+	if len(c.Alerts) == 0 {
+		alert := newTestAlert(apimodels.TestReceiversConfigParams{}, now, now)
+		return []*types.Alert{&alert}
+	}
+
+	alerts := make([]*types.Alert, 0, len(c.Alerts))
+	for _, a := range c.Alerts {
+		alert := &types.Alert{
+			Alert: model.Alert{
+				Labels:       model.LabelSet{},
+				Annotations:  model.LabelSet{},
+				StartsAt:     a.StartsAt,
+				EndsAt:       a.EndsAt,
+				GeneratorURL: a.GeneratorURL,
+			},
+			UpdatedAt: now,
+		}
+		for k, v := range a.Labels {
+			alert.Labels[k] = v
+		}
+		for k, v := range a.Annotations {
+			alert.Annotations[k] = v
+		}
+		if alert.StartsAt.IsZero() {
+			alert.StartsAt = now
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestAlertmanager_TestTemplates(t *testing.T) {
+	am := setupAMTest(t)
+	am.Settings.AppURL = "http://localhost:3000/"
+
+	t.Run("it should render the templates with a test alert", func(t *testing.T) {
+		results, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigParams{
+			Templates: map[string]string{
+				"slack.tmpl": `{{ define "slack.title" }}{{ len .Alerts.Firing }} firing: {{ .CommonLabels.alertname }}{{ end }}
+{{ define "slack.text" }}{{ template "__text_alert_list" .Alerts }}{{ end }}`,
+			},
+		})
+		require.NoError(t, err)
+		require.Empty(t, results.Errors)
+		require.Len(t, results.Results, 2)
+		require.Equal(t, TestTemplatesResult{Name: "slack.text", Text: results.Results[0].Text}, results.Results[0])
+		require.Contains(t, results.Results[0].Text, "alertname = TestAlert")
+		require.Equal(t, TestTemplatesResult{Name: "slack.title", Text: "1 firing: TestAlert"}, results.Results[1])
+	})
+
+	t.Run("it should render the templates with the alerts of the request", func(t *testing.T) {
+		results, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigParams{
+			Templates: map[string]string{
+				"a.tmpl": `{{ define "a" }}{{ range .Alerts }}{{ .Labels.host }} {{ end }}{{ end }}`,
+			},
+			Alerts: []*apimodels.TestTemplatesConfigAlertParams{
+				{Labels: model.LabelSet{"alertname": "a", "host": "h1"}},
+				{Labels: model.LabelSet{"alertname": "a", "host": "h2"}},
+			},
+		})
+		require.NoError(t, err)
+		require.Empty(t, results.Errors)
+		require.Equal(t, []TestTemplatesResult{{Name: "a", Text: "h1 h2 "}}, results.Results)
+	})
+
+	t.Run("it should return the line of parse errors", func(t *testing.T) {
+		results, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigParams{
+			Templates: map[string]string{
+				"a.tmpl": "{{ define \"a\" }}\n{{ if }}{{ end }}\n{{ end }}",
+			},
+		})
+		require.NoError(t, err)
+		require.Empty(t, results.Results)
+		require.Len(t, results.Errors, 1)
+		require.Equal(t, "a.tmpl", results.Errors[0].Name)
+		require.Equal(t, InvalidTemplate, results.Errors[0].Kind)
+		require.Equal(t, 2, results.Errors[0].Line)
+	})
+
+	t.Run("it should return the line of execution errors", func(t *testing.T) {
+		results, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigParams{
+			Templates: map[string]string{
+				"a.tmpl": "{{ define \"a\" }}ok{{ end }}\n{{ define \"b\" }}\n{{ template \"missing\" . }}{{ end }}",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []TestTemplatesResult{{Name: "a", Text: "ok"}}, results.Results)
+		require.Len(t, results.Errors, 1)
+		require.Equal(t, "b", results.Errors[0].Name)
+		require.Equal(t, ExecutionError, results.Errors[0].Kind)
+		require.Equal(t, 3, results.Errors[0].Line)
+	})
+
+	t.Run("it should fail without templates", func(t *testing.T) {
+		_, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigParams{})
+		require.ErrorIs(t, err, ErrNoTemplates)
+	})
+
+	t.Run("it should fail with invalid template file names", func(t *testing.T) {
+		_, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigParams{
+			Templates: map[string]string{"../a.tmpl": `{{ define "a" }}{{ end }}`},
+		})
+		require.ErrorIs(t, err, ErrInvalidTemplateFileName)
+	})
+	t.Run("it should fail with null alerts", func(t *testing.T) {
+		_, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigParams{
+			Templates: map[string]string{"a.tmpl": `{{ define "a" }}{{ end }}`},
+			Alerts:    []*apimodels.TestTemplatesConfigAlertParams{nil},
+		})
+		require.ErrorIs(t, err, ErrInvalidTestAlert)
+	})
+}
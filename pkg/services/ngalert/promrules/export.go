@@ -0,0 +1,275 @@
+package promrules
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// IsPrometheusFn returns true if the data source with the given UID is a Prometheus data source.
+type IsPrometheusFn func(datasourceUID string) bool
+
+// reducerFunctions maps the reducers of reduce expressions to the PromQL functions
+// that aggregate a range of samples the same way.
+var reducerFunctions = map[string]string{
+	"mean":  "avg_over_time",
+	"min":   "min_over_time",
+	"max":   "max_over_time",
+	"sum":   "sum_over_time",
+	"count": "count_over_time",
+}
+
+// mathComparisonRegexp matches math expressions comparing a single variable with a number, e.g. $A > 0.8.
+var mathComparisonRegexp = regexp.MustCompile(`^\s*\$\{?([A-Za-z0-9_]+)\}?\s*(>=|<=|==|!=|>|<)\s*(-?[0-9]+(?:\.[0-9]+)?(?:[eE][-+]?[0-9]+)?)\s*$`)
+
+// Export converts alert rules to a rule file with one group per rule group.
+//
+// Rules that run a single Prometheus query, possibly reduced and compared to a threshold
+// by expressions, are translated to native Prometheus rules. The other rules are exported
+// as Grafana managed rules.
+func Export(rules []*ngmodels.AlertRule, isPrometheus IsPrometheusFn) RuleFile {
+	byGroup := make(map[string][]*ngmodels.AlertRule)
+	for _, r := range rules {
+		byGroup[r.RuleGroup] = append(byGroup[r.RuleGroup], r)
+	}
+
+	groupNames := make([]string, 0, len(byGroup))
+	for name := range byGroup {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+
+	f := RuleFile{Groups: make([]apimodels.PostableRuleGroupConfig, 0, len(groupNames))}
+	for _, name := range groupNames {
+		groupRules := byGroup[name]
+		sort.SliceStable(groupRules, func(i, j int) bool {
+			return groupRules[i].RuleGroupIndex < groupRules[j].RuleGroupIndex
+		})
+
+		g := apimodels.PostableRuleGroupConfig{
+			Name:     name,
+			Interval: model.Duration(time.Duration(groupRules[0].IntervalSeconds) * time.Second),
+			Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(groupRules)),
+		}
+		for _, r := range groupRules {
+			g.Rules = append(g.Rules, exportRule(r, isPrometheus))
+		}
+		f.Groups = append(f.Groups, g)
+	}
+	return f
+}
+
+func exportRule(r *ngmodels.AlertRule, isPrometheus IsPrometheusFn) apimodels.PostableExtendedRuleNode {
+	if promQL, ok := toPromQL(r, isPrometheus); ok {
+		n := &apimodels.ApiRuleNode{
+			Expr:        promQL,
+			Labels:      r.Labels,
+			Annotations: r.Annotations,
+		}
+		if r.IsRecordingRule() {
+			n.Record = r.Record
+		} else {
+			n.Alert = r.Title
+			n.For = model.Duration(r.For)
+		}
+		return apimodels.PostableExtendedRuleNode{ApiRuleNode: n}
+	}
+
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			Record:      r.Record,
+			For:         model.Duration(r.For),
+			Labels:      r.Labels,
+			Annotations: r.Annotations,
+		},
+		GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+			Title:          r.Title,
+			Condition:      r.Condition,
+			Data:           r.Data,
+			UID:            r.UID,
+			NoDataState:    apimodels.NoDataState(r.NoDataState),
+			ExecErrState:   apimodels.ExecutionErrorState(r.ExecErrState),
+			IsPaused:       r.IsPaused,
+			ComputedLabels: r.ComputedLabels,
+		},
+	}
+}
+
+// toPromQL returns the PromQL expression equivalent to the condition of the rule.
+// It returns false if the rule can't be represented as a Prometheus rule.
+func toPromQL(r *ngmodels.AlertRule, isPrometheus IsPrometheusFn) (string, bool) {
+	if r.IsPaused {
+		return "", false
+	}
+
+	t := translator{
+		queries:      make(map[string]ngmodels.AlertQuery, len(r.Data)),
+		visited:      make(map[string]struct{}, len(r.Data)),
+		isPrometheus: isPrometheus,
+		// comparisons filter series in PromQL but result in 0 or 1 in Grafana,
+		// so the recorded series are only the same without them.
+		allowComparisons: !r.IsRecordingRule(),
+	}
+	for _, q := range r.Data {
+		t.queries[q.RefID] = q
+	}
+
+	promQL, ok := t.translate(r.Condition)
+	// queries and expressions that the condition does not depend on are lost in translation
+	if !ok || len(t.visited) != len(r.Data) {
+		return "", false
+	}
+	return promQL, true
+}
+
+type translator struct {
+	queries          map[string]ngmodels.AlertQuery
+	visited          map[string]struct{}
+	isPrometheus     IsPrometheusFn
+	allowComparisons bool
+}
+
+// exprModel holds the properties of the models of queries and expressions that can be translated.
+type exprModel struct {
+	Type       string                        `json:"type"`
+	Expression string                        `json:"expression"`
+	Reducer    string                        `json:"reducer"`
+	Settings   *mathexp.ReduceSettings       `json:"settings"`
+	Conditions []expr.ThresholdConditionJSON `json:"conditions"`
+	Expr       string                        `json:"expr"`
+}
+
+func (t *translator) translate(refID string) (string, bool) {
+	q, ok := t.queries[refID]
+	if !ok {
+		return "", false
+	}
+	if _, ok := t.visited[refID]; ok {
+		return "", false
+	}
+	t.visited[refID] = struct{}{}
+
+	var m exprModel
+	if err := json.Unmarshal(q.Model, &m); err != nil {
+		return "", false
+	}
+
+	if q.DatasourceUID != expr.DatasourceUID {
+		if !t.isPrometheus(q.DatasourceUID) || m.Expr == "" {
+			return "", false
+		}
+		if _, err := parser.ParseExpr(m.Expr); err != nil {
+			return "", false
+		}
+		return m.Expr, true
+	}
+
+	switch m.Type {
+	case expr.TypeMath.String():
+		if !t.allowComparisons {
+			return "", false
+		}
+		// the condition of imported rules that fire for any series of their query
+		if m.Expression == alwaysTrueExpression {
+			return t.translate(queryRefID)
+		}
+		match := mathComparisonRegexp.FindStringSubmatch(m.Expression)
+		if match == nil {
+			return "", false
+		}
+		inner, ok := t.translate(match[1])
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%s %s %s", wrap(inner), match[2], match[3]), true
+	case expr.TypeThreshold.String():
+		if !t.allowComparisons || len(m.Conditions) != 1 || m.Conditions[0].UnloadEvaluator != nil {
+			return "", false
+		}
+		inner, ok := t.translate(strings.TrimPrefix(m.Expression, "$"))
+		if !ok {
+			return "", false
+		}
+		return thresholdToPromQL(wrap(inner), m.Conditions[0].Evaluator)
+	case expr.TypeReduce.String():
+		if m.Settings != nil && m.Settings.Mode != "" && m.Settings.Mode != mathexp.ReduceModeStrict {
+			return "", false
+		}
+		reduced, ok := t.queries[strings.TrimPrefix(m.Expression, "$")]
+		// only the reduction of the Prometheus query itself can be translated
+		if !ok || reduced.DatasourceUID == expr.DatasourceUID || reduced.RelativeTimeRange.From <= 0 || reduced.RelativeTimeRange.To != 0 {
+			return "", false
+		}
+		inner, ok := t.translate(reduced.RefID)
+		if !ok {
+			return "", false
+		}
+		if m.Reducer == "last" {
+			return inner, true
+		}
+		fn, ok := reducerFunctions[m.Reducer]
+		if !ok {
+			return "", false
+		}
+		rng := model.Duration(reduced.RelativeTimeRange.From)
+		return fmt.Sprintf("%s(%s[%s:])", fn, wrap(inner), rng), true
+	default:
+		return "", false
+	}
+}
+
+func thresholdToPromQL(promQL string, e expr.ThresholdEvaluator) (string, bool) {
+	switch {
+	case (e.Type == "gt" || e.Type == "lt") && len(e.Params) != 1:
+		return "", false
+	case (e.Type == "within_range" || e.Type == "outside_range") && len(e.Params) != 2:
+		return "", false
+	}
+
+	switch e.Type {
+	case "gt":
+		return fmt.Sprintf("%s > %s", promQL, formatFloat(e.Params[0])), true
+	case "lt":
+		return fmt.Sprintf("%s < %s", promQL, formatFloat(e.Params[0])), true
+	case "within_range", "outside_range":
+		lower, upper := e.Params[0], e.Params[1]
+		if lower > upper {
+			lower, upper = upper, lower
+		}
+		if e.Type == "within_range" {
+			return fmt.Sprintf("%s >= %s <= %s", promQL, formatFloat(lower), formatFloat(upper)), true
+		}
+		return fmt.Sprintf("%s < %s or %s > %s", promQL, formatFloat(lower), promQL, formatFloat(upper)), true
+	default:
+		return "", false
+	}
+}
+
+// wrap puts the PromQL expression between parentheses if it is a binary expression,
+// so that it can be used as the operand of another one.
+func wrap(promQL string) string {
+	e, err := parser.ParseExpr(promQL)
+	if err != nil {
+		return promQL
+	}
+	if _, ok := e.(*parser.BinaryExpr); ok {
+		return "(" + promQL + ")"
+	}
+	return promQL
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
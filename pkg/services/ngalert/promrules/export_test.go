@@ -0,0 +1,324 @@
+package promrules
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	promDatasourceUID  = "prom"
+	otherDatasourceUID = "loki"
+)
+
+func isPrometheusForTests(uid string) bool {
+	return uid == promDatasourceUID
+}
+
+func promQuery(refID, promQL string) ngmodels.AlertQuery {
+	return ngmodels.AlertQuery{
+		RefID:             refID,
+		DatasourceUID:     promDatasourceUID,
+		RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(10 * time.Minute)},
+		Model:             json.RawMessage(`{"expr": "` + promQL + `", "refId": "` + refID + `"}`),
+	}
+}
+
+func expression(refID, model string) ngmodels.AlertQuery {
+	return ngmodels.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         json.RawMessage(model),
+	}
+}
+
+func TestExport(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		condition string
+		record    string
+		isPaused  bool
+		data      []ngmodels.AlertQuery
+		// expExpr is empty if the rule is expected to be exported as a Grafana managed rule.
+		expExpr string
+	}{
+		{
+			desc:      "single Prometheus query",
+			condition: "A",
+			data:      []ngmodels.AlertQuery{promQuery("A", "up == 0")},
+			expExpr:   "up == 0",
+		},
+		{
+			desc:      "threshold greater than",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "rate(http_requests_total[5m])"),
+				expression("B", `{"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [0.8]}}]}`),
+			},
+			expExpr: "rate(http_requests_total[5m]) > 0.8",
+		},
+		{
+			desc:      "threshold of a binary expression",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "errors / requests"),
+				expression("B", `{"type": "threshold", "expression": "$A", "conditions": [{"evaluator": {"type": "lt", "params": [5]}}]}`),
+			},
+			expExpr: "(errors / requests) < 5",
+		},
+		{
+			desc:      "threshold within range",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "temperature"),
+				expression("B", `{"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "within_range", "params": [30, 10]}}]}`),
+			},
+			expExpr: "temperature >= 10 <= 30",
+		},
+		{
+			desc:      "threshold outside range",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "temperature"),
+				expression("B", `{"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "outside_range", "params": [10, 30]}}]}`),
+			},
+			expExpr: "temperature < 10 or temperature > 30",
+		},
+		{
+			desc:      "reduce and math comparison",
+			condition: "C",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "node_load1"),
+				expression("B", `{"type": "reduce", "expression": "A", "reducer": "mean"}`),
+				expression("C", `{"type": "math", "expression": "${B} >= 5"}`),
+			},
+			expExpr: "avg_over_time(node_load1[10m:]) >= 5",
+		},
+		{
+			desc:      "reduce last",
+			condition: "C",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "node_load1"),
+				expression("B", `{"type": "reduce", "expression": "A", "reducer": "last", "settings": {"mode": "strict"}}`),
+				expression("C", `{"type": "math", "expression": "$B != 0"}`),
+			},
+			expExpr: "node_load1 != 0",
+		},
+		{
+			desc:      "condition of an imported rule",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "absent(up)"),
+				expression("B", `{"type": "math", "expression": "$A == $A || $A != $A"}`),
+			},
+			expExpr: "absent(up)",
+		},
+		{
+			desc:      "recording rule",
+			condition: "A",
+			record:    "job:up:sum",
+			data:      []ngmodels.AlertQuery{promQuery("A", "sum by (job) (up)")},
+			expExpr:   "sum by (job) (up)",
+		},
+		{
+			desc:      "recording rule with comparison",
+			condition: "B",
+			record:    "job:up:sum",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "sum by (job) (up)"),
+				expression("B", `{"type": "math", "expression": "$A > 0"}`),
+			},
+		},
+		{
+			desc:      "other data source",
+			condition: "A",
+			data: []ngmodels.AlertQuery{
+				{RefID: "A", DatasourceUID: otherDatasourceUID, Model: json.RawMessage(`{"expr": "count_over_time({job=\"app\"}[5m])"}`)},
+			},
+		},
+		{
+			desc:      "math expression other than a comparison",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "up"),
+				expression("B", `{"type": "math", "expression": "$A * 2 > 1"}`),
+			},
+		},
+		{
+			desc:      "threshold with a recovery threshold",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "up"),
+				expression("B", `{"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [10]}, "unloadEvaluator": {"type": "lt", "params": [5]}}]}`),
+			},
+		},
+		{
+			desc:      "reduce with a non strict mode",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "up"),
+				expression("B", `{"type": "reduce", "expression": "A", "reducer": "mean", "settings": {"mode": "dropNN"}}`),
+			},
+		},
+		{
+			desc:      "classic condition",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "up"),
+				expression("B", `{"type": "classic_conditions", "conditions": []}`),
+			},
+		},
+		{
+			desc:      "unused query",
+			condition: "A",
+			data: []ngmodels.AlertQuery{
+				promQuery("A", "up"),
+				promQuery("B", "down"),
+			},
+		},
+		{
+			desc:      "paused rule",
+			condition: "A",
+			isPaused:  true,
+			data:      []ngmodels.AlertQuery{promQuery("A", "up == 0")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rule := &ngmodels.AlertRule{
+				UID:             "uid",
+				Title:           "rule",
+				Condition:       tc.condition,
+				Data:            tc.data,
+				Record:          tc.record,
+				IsPaused:        tc.isPaused,
+				RuleGroup:       "group",
+				IntervalSeconds: 60,
+				For:             5 * time.Minute,
+				NoDataState:     ngmodels.NoData,
+				ExecErrState:    ngmodels.AlertingErrState,
+				Labels:          map[string]string{"severity": "critical"},
+				Annotations:     map[string]string{"summary": "{{ $labels.instance }} is down"},
+			}
+
+			f := Export([]*ngmodels.AlertRule{rule}, isPrometheusForTests)
+			require.Len(t, f.Groups, 1)
+			require.Len(t, f.Groups[0].Rules, 1)
+			exported := f.Groups[0].Rules[0]
+
+			if tc.expExpr == "" {
+				require.NotNil(t, exported.GrafanaManagedAlert)
+				require.Equal(t, &apimodels.PostableGrafanaRule{
+					Title:        "rule",
+					Condition:    tc.condition,
+					Data:         tc.data,
+					UID:          "uid",
+					NoDataState:  apimodels.NoData,
+					ExecErrState: apimodels.AlertingErrState,
+					IsPaused:     tc.isPaused,
+				}, exported.GrafanaManagedAlert)
+				require.Empty(t, exported.Expr)
+				return
+			}
+
+			require.Nil(t, exported.GrafanaManagedAlert)
+			exp := &apimodels.ApiRuleNode{
+				Expr:        tc.expExpr,
+				Labels:      rule.Labels,
+				Annotations: rule.Annotations,
+			}
+			if tc.record != "" {
+				exp.Record = tc.record
+			} else {
+				exp.Alert = "rule"
+				exp.For = model.Duration(5 * time.Minute)
+			}
+			require.Equal(t, exp, exported.ApiRuleNode)
+		})
+	}
+}
+
+func TestExportGroups(t *testing.T) {
+	rules := []*ngmodels.AlertRule{
+		{Title: "b2", RuleGroup: "b", RuleGroupIndex: 2, IntervalSeconds: 30, Condition: "A", Data: []ngmodels.AlertQuery{promQuery("A", "up")}},
+		{Title: "a1", RuleGroup: "a", RuleGroupIndex: 1, IntervalSeconds: 60, Condition: "A", Data: []ngmodels.AlertQuery{promQuery("A", "up")}},
+		{Title: "b1", RuleGroup: "b", RuleGroupIndex: 1, IntervalSeconds: 30, Condition: "A", Data: []ngmodels.AlertQuery{promQuery("A", "up")}},
+	}
+
+	f := Export(rules, isPrometheusForTests)
+	require.Len(t, f.Groups, 2)
+	require.Equal(t, "a", f.Groups[0].Name)
+	require.Equal(t, model.Duration(time.Minute), f.Groups[0].Interval)
+	require.Equal(t, "b", f.Groups[1].Name)
+	require.Equal(t, model.Duration(30*time.Second), f.Groups[1].Interval)
+	require.Len(t, f.Groups[1].Rules, 2)
+	require.Equal(t, "b1", f.Groups[1].Rules[0].Alert)
+	require.Equal(t, "b2", f.Groups[1].Rules[1].Alert)
+}
+
+func TestRuleFileMarshal(t *testing.T) {
+	rules := []*ngmodels.AlertRule{
+		{
+			Title: "HighLoad", RuleGroup: "node", RuleGroupIndex: 1, IntervalSeconds: 60, For: 5 * time.Minute,
+			Condition: "B",
+			Data: []ngmodels.AlertQuery{
+				promQuery("A", "node_load1"),
+				expression("B", `{"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [4]}}]}`),
+			},
+			Labels: map[string]string{"severity": "warning"},
+		},
+		{
+			UID: "abcd", Title: "LogErrors", RuleGroup: "node", RuleGroupIndex: 2, IntervalSeconds: 60,
+			Condition:    "A",
+			Data:         []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: otherDatasourceUID, RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(5 * time.Minute)}, Model: json.RawMessage(`{"expr": "count_over_time({job=\"app\"}[5m])", "refId": "A"}`)}},
+			NoDataState:  ngmodels.OK,
+			ExecErrState: ngmodels.AlertingErrState,
+		},
+	}
+
+	b, err := Export(rules, isPrometheusForTests).Marshal()
+	require.NoError(t, err)
+	require.Equal(t, `groups:
+  - name: node
+    interval: 1m
+    rules:
+      - alert: HighLoad
+        expr: node_load1 > 4
+        for: 5m
+        labels:
+          severity: warning
+      - grafana_alert:
+          title: LogErrors
+          condition: A
+          data:
+            - refId: A
+              relativeTimeRange:
+                from: 300
+                to: 0
+              datasourceUid: loki
+              model:
+                expr: count_over_time({job="app"}[5m])
+                refId: A
+          uid: abcd
+          no_data_state: OK
+          exec_err_state: Alerting
+          is_paused: false
+`, string(b))
+
+	// the exported file can be parsed again
+	f, err := ParseRuleFile(b)
+	require.NoError(t, err)
+	require.Len(t, f.Groups, 1)
+	require.Len(t, f.Groups[0].Rules, 2)
+	require.Equal(t, "node_load1 > 4", f.Groups[0].Rules[0].Expr)
+	require.Equal(t, rules[1].Data[0].RelativeTimeRange, f.Groups[0].Rules[1].GrafanaManagedAlert.Data[0].RelativeTimeRange)
+	require.JSONEq(t, string(rules[1].Data[0].Model), string(f.Groups[0].Rules[1].GrafanaManagedAlert.Data[0].Model))
+}
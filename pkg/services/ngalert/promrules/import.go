@@ -0,0 +1,223 @@
+package promrules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	queryRefID     = "A"
+	conditionRefID = "B"
+
+	// queryTimeRange is the relative time range of imported queries, the default of the alert rule editor.
+	queryTimeRange = 10 * time.Minute
+	// alwaysTrueExpression is true for any sample, including NaN. Prometheus alerts fire
+	// for all the series returned by their expression, whatever their values.
+	alwaysTrueExpression = "$A == $A || $A != $A"
+)
+
+// ErrRuleGroupExists is returned when a rule group of a rule file already exists in the folder it is imported into.
+var ErrRuleGroupExists = errors.New("rule group already exists")
+
+// Import converts the rule groups of a rule file to Grafana managed rule groups, to import them into the
+// folder with the given alert rules. Prometheus rules are converted to rules that run their expression
+// against the Prometheus data source with the given UID. Grafana managed rules are kept as is.
+//
+// Rule groups that exist in the folder are replaced by the imported ones if overwrite is true, otherwise
+// ErrRuleGroupExists is returned. Titles of alert rules must be unique in a folder, so rules with the same
+// name as another rule of the file or of the folder get a numeric suffix.
+func Import(f RuleFile, datasourceUID string, folderRules []*ngmodels.AlertRule, overwrite bool) ([]apimodels.PostableRuleGroupConfig, error) {
+	imported := make(map[string]struct{}, len(f.Groups))
+	for _, g := range f.Groups {
+		imported[g.Name] = struct{}{}
+	}
+
+	titles := make(map[string]struct{})
+	for _, r := range folderRules {
+		if _, ok := imported[r.RuleGroup]; ok {
+			if !overwrite {
+				return nil, fmt.Errorf("%w: %q", ErrRuleGroupExists, r.RuleGroup)
+			}
+			// the rules of the group are replaced
+			continue
+		}
+		titles[r.Title] = struct{}{}
+	}
+	for _, g := range f.Groups {
+		for _, r := range g.Rules {
+			if r.GrafanaManagedAlert != nil {
+				titles[r.GrafanaManagedAlert.Title] = struct{}{}
+			}
+		}
+	}
+	uniqueTitle := func(name string) string {
+		title := name
+		for n := 2; ; n++ {
+			if _, ok := titles[title]; !ok {
+				break
+			}
+			title = fmt.Sprintf("%s (%d)", name, n)
+		}
+		titles[title] = struct{}{}
+		return title
+	}
+
+	groups := make([]apimodels.PostableRuleGroupConfig, 0, len(f.Groups))
+	for _, g := range f.Groups {
+		group := apimodels.PostableRuleGroupConfig{
+			Name:     g.Name,
+			Interval: g.Interval,
+			Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(g.Rules)),
+		}
+		for _, r := range g.Rules {
+			if r.GrafanaManagedAlert != nil {
+				group.Rules = append(group.Rules, r)
+				continue
+			}
+			if datasourceUID == "" {
+				return nil, fmt.Errorf("a data source is required to import the Prometheus rules of rule group %q", g.Name)
+			}
+			rule, err := importPrometheusRule(*r.ApiRuleNode, datasourceUID, uniqueTitle)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import rule group %q: %w", g.Name, err)
+			}
+			group.Rules = append(group.Rules, rule)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func importPrometheusRule(r apimodels.ApiRuleNode, datasourceUID string, uniqueTitle func(string) string) (apimodels.PostableExtendedRuleNode, error) {
+	name := r.Alert
+	if r.Record != "" {
+		name = r.Record
+	}
+
+	e, err := parser.ParseExpr(r.Expr)
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid expression of rule %q: %w", name, err)
+	}
+
+	promQL, condition := r.Expr, queryRefID
+	var conditionModel map[string]interface{}
+	if r.Alert != "" {
+		promQL, conditionModel = alertCondition(r.Expr, e)
+		condition = conditionRefID
+	}
+
+	query, err := newAlertQuery(queryRefID, datasourceUID, map[string]interface{}{
+		"refId":         queryRefID,
+		"expr":          promQL,
+		"instant":       true,
+		"range":         false,
+		"intervalMs":    1000,
+		"maxDataPoints": 43200,
+	})
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, err
+	}
+	query.RelativeTimeRange = ngmodels.RelativeTimeRange{From: ngmodels.Duration(queryTimeRange)}
+	data := []ngmodels.AlertQuery{query}
+
+	if conditionModel != nil {
+		conditionModel["refId"] = conditionRefID
+		cond, err := newAlertQuery(conditionRefID, expr.DatasourceUID, conditionModel)
+		if err != nil {
+			return apimodels.PostableExtendedRuleNode{}, err
+		}
+		data = append(data, cond)
+	}
+
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			Record:      r.Record,
+			For:         r.For,
+			Labels:      r.Labels,
+			Annotations: r.Annotations,
+		},
+		GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+			Title:     uniqueTitle(name),
+			Condition: condition,
+			Data:      data,
+			// Prometheus does not fire alerts for series that have no data
+			NoDataState:  apimodels.OK,
+			ExecErrState: apimodels.AlertingErrState,
+		},
+	}, nil
+}
+
+// alertCondition returns the PromQL query of an alerting rule expression and the model of the
+// expression that is its condition. Comparisons of the result of a query to a number are turned
+// into expressions, so that they can be edited as thresholds.
+func alertCondition(promQL string, e parser.Expr) (string, map[string]interface{}) {
+	alwaysTrue := map[string]interface{}{
+		"type":       expr.TypeMath.String(),
+		"expression": alwaysTrueExpression,
+	}
+
+	b, ok := e.(*parser.BinaryExpr)
+	if !ok || !b.Op.IsComparisonOperator() || b.ReturnBool {
+		return promQL, alwaysTrue
+	}
+	threshold, ok := unwrap(b.RHS).(*parser.NumberLiteral)
+	if !ok || b.LHS.Type() != parser.ValueTypeVector {
+		return promQL, alwaysTrue
+	}
+
+	lhs := unwrap(b.LHS)
+	pos := lhs.PositionRange()
+	query := promQL[pos.Start:pos.End]
+
+	switch b.Op {
+	case parser.GTR, parser.LSS:
+		evaluator := "gt"
+		if b.Op == parser.LSS {
+			evaluator = "lt"
+		}
+		return query, map[string]interface{}{
+			"type":       expr.TypeThreshold.String(),
+			"expression": queryRefID,
+			"conditions": []expr.ThresholdConditionJSON{
+				{Evaluator: expr.ThresholdEvaluator{Type: evaluator, Params: []float64{threshold.Val}}},
+			},
+		}
+	default:
+		return query, map[string]interface{}{
+			"type":       expr.TypeMath.String(),
+			"expression": fmt.Sprintf("$%s %s %s", queryRefID, b.Op, strconv.FormatFloat(threshold.Val, 'f', -1, 64)),
+		}
+	}
+}
+
+// unwrap returns the expression between parentheses.
+func unwrap(e parser.Expr) parser.Expr {
+	for {
+		p, ok := e.(*parser.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.Expr
+	}
+}
+
+func newAlertQuery(refID, datasourceUID string, model map[string]interface{}) (ngmodels.AlertQuery, error) {
+	b, err := json.Marshal(model)
+	if err != nil {
+		return ngmodels.AlertQuery{}, fmt.Errorf("failed to marshal query model: %w", err)
+	}
+	return ngmodels.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: datasourceUID,
+		Model:         b,
+	}, nil
+}
@@ -0,0 +1,301 @@
+package promrules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestParseRuleFile(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		file   string
+		expErr string
+	}{
+		{
+			desc: "valid Prometheus rule file",
+			file: `
+groups:
+  - name: node
+    interval: 30s
+    rules:
+      - record: job:up:sum
+        expr: sum by (job) (up)
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        labels:
+          severity: critical
+        annotations:
+          summary: "{{ $labels.instance }} is down"
+`,
+		},
+		{
+			desc: "empty file",
+			file: ``,
+		},
+		{
+			desc: "unknown field",
+			file: `
+groups:
+  - name: node
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        keep_firing_for: 5m
+`,
+			expErr: "failed to parse rule file",
+		},
+		{
+			desc: "duplicate group",
+			file: `
+groups:
+  - name: node
+    rules: []
+  - name: node
+    rules: []
+`,
+			expErr: `duplicate rule group "node"`,
+		},
+		{
+			desc: "invalid expression",
+			file: `
+groups:
+  - name: node
+    rules:
+      - alert: InstanceDown
+        expr: up ==
+`,
+			expErr: `invalid rule 1 of rule group "node": invalid expr`,
+		},
+		{
+			desc: "alert and record",
+			file: `
+groups:
+  - name: node
+    rules:
+      - alert: InstanceDown
+        record: instance_down
+        expr: up == 0
+`,
+			expErr: `invalid rule 1 of rule group "node": only one of record and alert must be set`,
+		},
+		{
+			desc: "invalid recording rule name",
+			file: `
+groups:
+  - name: node
+    rules:
+      - record: instance-down
+        expr: up == 0
+`,
+			expErr: `invalid rule 1 of rule group "node": invalid recording rule name: instance-down`,
+		},
+		{
+			desc: "expr and grafana_alert",
+			file: `
+groups:
+  - name: node
+    rules:
+      - expr: up == 0
+        grafana_alert:
+          title: InstanceDown
+          condition: A
+          data: []
+`,
+			expErr: `invalid rule 1 of rule group "node": cannot have both expr and grafana_alert`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := ParseRuleFile([]byte(tc.file))
+			if tc.expErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	f, err := ParseRuleFile([]byte(`
+groups:
+  - name: node
+    interval: 30s
+    rules:
+      - record: job:up:sum
+        expr: sum by (job) (up)
+      - alert: InstanceDown
+        expr: up < 1
+        for: 5m
+        labels:
+          severity: critical
+      - alert: InstanceDown
+        expr: (node_load1 + node_load5) >= 4
+      - alert: Absent
+        expr: absent(up)
+      - grafana_alert:
+          title: Absent
+          condition: A
+          data:
+            - refId: A
+              relativeTimeRange:
+                from: 300
+                to: 0
+              datasourceUid: loki
+              model:
+                expr: count_over_time({job="app"}[5m])
+                refId: A
+          uid: abcd
+          no_data_state: NoData
+          exec_err_state: Alerting
+`))
+	require.NoError(t, err)
+
+	groups, err := Import(f, promDatasourceUID, nil, false)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	g := groups[0]
+	require.Equal(t, "node", g.Name)
+	require.Equal(t, model.Duration(30*time.Second), g.Interval)
+	require.Len(t, g.Rules, 5)
+
+	requireQueries := func(t *testing.T, rule *apimodels.PostableGrafanaRule, expQuery, expCondition string) {
+		t.Helper()
+		require.Equal(t, "A", rule.Data[0].RefID)
+		require.Equal(t, promDatasourceUID, rule.Data[0].DatasourceUID)
+		require.Equal(t, ngmodels.RelativeTimeRange{From: ngmodels.Duration(10 * time.Minute)}, rule.Data[0].RelativeTimeRange)
+		require.JSONEq(t, expQuery, string(rule.Data[0].Model))
+		if expCondition == "" {
+			require.Len(t, rule.Data, 1)
+			require.Equal(t, "A", rule.Condition)
+			return
+		}
+		require.Len(t, rule.Data, 2)
+		require.Equal(t, "B", rule.Condition)
+		require.Equal(t, expr.DatasourceUID, rule.Data[1].DatasourceUID)
+		require.JSONEq(t, expCondition, string(rule.Data[1].Model))
+	}
+
+	t.Run("recording rule", func(t *testing.T) {
+		r := g.Rules[0]
+		require.Equal(t, "job:up:sum", r.Record)
+		require.Equal(t, "job:up:sum", r.GrafanaManagedAlert.Title)
+		requireQueries(t, r.GrafanaManagedAlert,
+			`{"refId": "A", "expr": "sum by (job) (up)", "instant": true, "range": false, "intervalMs": 1000, "maxDataPoints": 43200}`, "")
+	})
+
+	t.Run("comparison is imported as a threshold", func(t *testing.T) {
+		r := g.Rules[1]
+		require.Equal(t, "InstanceDown", r.GrafanaManagedAlert.Title)
+		require.Equal(t, model.Duration(5*time.Minute), r.For)
+		require.Equal(t, map[string]string{"severity": "critical"}, r.Labels)
+		require.Equal(t, apimodels.OK, r.GrafanaManagedAlert.NoDataState)
+		require.Equal(t, apimodels.AlertingErrState, r.GrafanaManagedAlert.ExecErrState)
+		requireQueries(t, r.GrafanaManagedAlert,
+			`{"refId": "A", "expr": "up", "instant": true, "range": false, "intervalMs": 1000, "maxDataPoints": 43200}`,
+			`{"refId": "B", "type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "lt", "params": [1]}}]}`)
+	})
+
+	t.Run("comparison is imported as a math expression", func(t *testing.T) {
+		r := g.Rules[2]
+		require.Equal(t, "InstanceDown (2)", r.GrafanaManagedAlert.Title)
+		requireQueries(t, r.GrafanaManagedAlert,
+			`{"refId": "A", "expr": "node_load1 + node_load5", "instant": true, "range": false, "intervalMs": 1000, "maxDataPoints": 43200}`,
+			`{"refId": "B", "type": "math", "expression": "$A >= 4"}`)
+	})
+
+	t.Run("other expressions fire for any series", func(t *testing.T) {
+		r := g.Rules[3]
+		// the title of the Grafana managed rule of the file is kept
+		require.Equal(t, "Absent (2)", r.GrafanaManagedAlert.Title)
+		requireQueries(t, r.GrafanaManagedAlert,
+			`{"refId": "A", "expr": "absent(up)", "instant": true, "range": false, "intervalMs": 1000, "maxDataPoints": 43200}`,
+			`{"refId": "B", "type": "math", "expression": "$A == $A || $A != $A"}`)
+	})
+
+	t.Run("Grafana managed rules are kept", func(t *testing.T) {
+		r := g.Rules[4]
+		require.Equal(t, f.Groups[0].Rules[4], r)
+		require.Equal(t, "Absent", r.GrafanaManagedAlert.Title)
+		require.Equal(t, "abcd", r.GrafanaManagedAlert.UID)
+	})
+
+	t.Run("data source is required for Prometheus rules", func(t *testing.T) {
+		_, err := Import(f, "", nil, false)
+		require.EqualError(t, err, `a data source is required to import the Prometheus rules of rule group "node"`)
+	})
+
+	t.Run("titles of the rules of the folder are not reused", func(t *testing.T) {
+		folderRules := []*ngmodels.AlertRule{
+			{Title: "job:up:sum", RuleGroup: "other"},
+			{Title: "job:up:sum (2)", RuleGroup: "other"},
+			{Title: "InstanceDown", RuleGroup: "node"},
+		}
+		groups, err := Import(f, promDatasourceUID, folderRules, true)
+		require.NoError(t, err)
+		require.Equal(t, "job:up:sum (3)", groups[0].Rules[0].GrafanaManagedAlert.Title)
+		// the rules of the replaced rule group do not count
+		require.Equal(t, "InstanceDown", groups[0].Rules[1].GrafanaManagedAlert.Title)
+	})
+
+	t.Run("existing rule groups are not replaced without overwrite", func(t *testing.T) {
+		_, err := Import(f, promDatasourceUID, []*ngmodels.AlertRule{{Title: "InstanceDown", RuleGroup: "node"}}, false)
+		require.ErrorIs(t, err, ErrRuleGroupExists)
+	})
+}
+
+func TestImportExportRoundTrip(t *testing.T) {
+	file := `groups:
+  - name: node
+    interval: 1m
+    rules:
+      - record: job:up:sum
+        expr: sum by (job) (up)
+      - alert: InstanceDown
+        expr: up < 1
+        for: 5m
+        labels:
+          severity: critical
+      - alert: HighLoad
+        expr: (node_load1 + node_load5) >= 4
+      - alert: Absent
+        expr: absent(up)
+`
+	f, err := ParseRuleFile([]byte(file))
+	require.NoError(t, err)
+	groups, err := Import(f, promDatasourceUID, nil, false)
+	require.NoError(t, err)
+
+	// convert the imported rule groups to the alert rules they are stored as
+	var rules []*ngmodels.AlertRule
+	for _, g := range groups {
+		for i, r := range g.Rules {
+			rules = append(rules, &ngmodels.AlertRule{
+				Title:           r.GrafanaManagedAlert.Title,
+				Condition:       r.GrafanaManagedAlert.Condition,
+				Data:            r.GrafanaManagedAlert.Data,
+				RuleGroup:       g.Name,
+				RuleGroupIndex:  i + 1,
+				IntervalSeconds: int64(time.Duration(g.Interval).Seconds()),
+				Record:          r.Record,
+				For:             time.Duration(r.For),
+				Labels:          r.Labels,
+				Annotations:     r.Annotations,
+			})
+		}
+	}
+
+	b, err := Export(rules, isPrometheusForTests).Marshal()
+	require.NoError(t, err)
+	require.Equal(t, file, string(b))
+}
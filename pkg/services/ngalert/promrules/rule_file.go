@@ -0,0 +1,104 @@
+// Package promrules converts Grafana managed alert rules from and to Prometheus rule files.
+package promrules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// RuleFile is a Prometheus rule file. Besides Prometheus alerting and recording
+// rules, its groups can hold Grafana managed rules under the grafana_alert key.
+type RuleFile struct {
+	Groups []apimodels.PostableRuleGroupConfig `yaml:"groups"`
+}
+
+// ParseRuleFile parses and validates a rule file.
+func ParseRuleFile(b []byte) (RuleFile, error) {
+	var f RuleFile
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return RuleFile{}, fmt.Errorf("failed to parse rule file: %w", err)
+	}
+	if err := f.validate(); err != nil {
+		return RuleFile{}, err
+	}
+	return f, nil
+}
+
+// Marshal returns the YAML encoding of the rule file.
+func (f RuleFile) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(f); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (f RuleFile) validate() error {
+	groups := make(map[string]struct{}, len(f.Groups))
+	for _, g := range f.Groups {
+		if g.Name == "" {
+			return errors.New("rule group name is empty")
+		}
+		if _, ok := groups[g.Name]; ok {
+			return fmt.Errorf("duplicate rule group %q", g.Name)
+		}
+		groups[g.Name] = struct{}{}
+
+		for i, r := range g.Rules {
+			if err := validateRule(r); err != nil {
+				return fmt.Errorf("invalid rule %d of rule group %q: %w", i+1, g.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateRule(r apimodels.PostableExtendedRuleNode) error {
+	if r.GrafanaManagedAlert != nil {
+		if r.GrafanaManagedAlert.Title == "" {
+			return errors.New("grafana_alert title is empty")
+		}
+		if r.ApiRuleNode != nil && r.ApiRuleNode.Alert != "" {
+			return errors.New("cannot have both alert and grafana_alert")
+		}
+		if r.ApiRuleNode != nil && r.ApiRuleNode.Expr != "" {
+			return errors.New("cannot have both expr and grafana_alert")
+		}
+		return nil
+	}
+
+	if r.ApiRuleNode == nil {
+		return errors.New("rule is empty")
+	}
+	switch {
+	case r.Record != "" && r.Alert != "":
+		return errors.New("only one of record and alert must be set")
+	case r.Record == "" && r.Alert == "":
+		return errors.New("one of record or alert must be set")
+	case r.Record != "" && !model.IsValidMetricName(model.LabelValue(r.Record)):
+		return fmt.Errorf("invalid recording rule name: %s", r.Record)
+	case r.Record != "" && r.For != 0:
+		return errors.New("invalid field 'for' in recording rule")
+	case r.Expr == "":
+		return errors.New("expr is empty")
+	}
+	if _, err := parser.ParseExpr(r.Expr); err != nil {
+		return fmt.Errorf("invalid expr: %w", err)
+	}
+	return nil
+}
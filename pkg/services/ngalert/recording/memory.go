@@ -0,0 +1,44 @@
+package recording
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryWriter keeps the latest sample of every recorded series in memory.
+// It is used when no remote write endpoint is configured.
+type MemoryWriter struct {
+	mtx     sync.RWMutex
+	samples map[int64]map[string]Sample // orgID > series > sample
+}
+
+// NewMemoryWriter returns a new, empty MemoryWriter.
+func NewMemoryWriter() *MemoryWriter {
+	return &MemoryWriter{samples: make(map[int64]map[string]Sample)}
+}
+
+// Write stores the samples, replacing any previous sample of the same series.
+func (w *MemoryWriter) Write(_ context.Context, orgID int64, samples []Sample) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if _, ok := w.samples[orgID]; !ok {
+		w.samples[orgID] = make(map[string]Sample)
+	}
+	for _, s := range samples {
+		w.samples[orgID][s.Labels.String()] = s
+	}
+	return nil
+}
+
+// Samples returns the latest sample of every series recorded for the organization.
+func (w *MemoryWriter) Samples(orgID int64) []Sample {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+
+	samples := make([]Sample, 0, len(w.samples[orgID]))
+	for _, s := range w.samples[orgID] {
+		samples = append(samples, s)
+	}
+	return samples
+}
@@ -0,0 +1,52 @@
+// Package recording writes the results of recording rules to a storage backend.
+package recording
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+// Sample is a single value produced by a recording rule.
+type Sample struct {
+	Labels    data.Labels
+	Value     float64
+	Timestamp time.Time
+}
+
+// Writer writes the samples produced by recording rules.
+type Writer interface {
+	Write(ctx context.Context, orgID int64, samples []Sample) error
+}
+
+// NewSamples creates the samples for the values of a recording rule.
+// Each sample has the labels of its value, overridden by the rule labels, and its
+// metric name is set to the name of the recording rule. Values without data are skipped.
+func NewSamples(metric string, ruleLabels map[string]string, values []eval.NumberValueCapture, ts time.Time) []Sample {
+	samples := make([]Sample, 0, len(values))
+	for _, v := range values {
+		if v.Value == nil {
+			continue
+		}
+
+		lbs := make(data.Labels, len(v.Labels)+len(ruleLabels)+1)
+		for k, val := range v.Labels {
+			lbs[k] = val
+		}
+		for k, val := range ruleLabels {
+			lbs[k] = val
+		}
+		lbs[model.MetricNameLabel] = metric
+
+		samples = append(samples, Sample{
+			Labels:    lbs,
+			Value:     *v.Value,
+			Timestamp: ts,
+		})
+	}
+	return samples
+}
@@ -0,0 +1,107 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+)
+
+// RemoteWriteConfig is the configuration of a Prometheus remote write endpoint.
+type RemoteWriteConfig struct {
+	URL      string
+	User     string
+	Password string
+	Timeout  time.Duration
+}
+
+// RemoteWriter writes samples to a Prometheus remote write endpoint.
+type RemoteWriter struct {
+	cfg    RemoteWriteConfig
+	client *http.Client
+	log    log.Logger
+}
+
+// NewRemoteWriter returns a new RemoteWriter for the given endpoint.
+func NewRemoteWriter(cfg RemoteWriteConfig, logger log.Logger) *RemoteWriter {
+	return &RemoteWriter{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		log:    logger,
+	}
+}
+
+// Write sends the samples to the remote write endpoint in a single request.
+func (w *RemoteWriter) Write(ctx context.Context, orgID int64, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	b, err := remotewrite.TimeSeriesToBytes(toTimeSeries(samples))
+	if err != nil {
+		return fmt.Errorf("failed to encode samples: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.cfg.User != "" {
+		req.SetBasicAuth(w.cfg.User, w.cfg.Password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d from remote write endpoint: %s", resp.StatusCode, string(body))
+	}
+
+	w.log.Debug("samples written to remote write endpoint", "org", orgID, "count", len(samples))
+	return nil
+}
+
+func toTimeSeries(samples []Sample) []prompb.TimeSeries {
+	series := make([]prompb.TimeSeries, 0, len(samples))
+	for _, s := range samples {
+		names := make([]string, 0, len(s.Labels))
+		for name := range s.Labels {
+			names = append(names, name)
+		}
+		// remote write requires labels to be sorted by name
+		sort.Strings(names)
+
+		labels := make([]prompb.Label, 0, len(names))
+		for _, name := range names {
+			labels = append(labels, prompb.Label{Name: name, Value: s.Labels[name]})
+		}
+
+		series = append(series, prompb.TimeSeries{
+			Labels: labels,
+			Samples: []prompb.Sample{
+				{Value: s.Value, Timestamp: s.Timestamp.UnixNano() / int64(time.Millisecond)},
+			},
+		})
+	}
+	return series
+}
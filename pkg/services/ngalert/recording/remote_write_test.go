@@ -0,0 +1,89 @@
+package recording
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+func TestNewSamples(t *testing.T) {
+	ts := time.Unix(1000, 0)
+	values := []eval.NumberValueCapture{
+		{Var: "B", Labels: data.Labels{"instance": "a", "team": "value"}, Value: ptr.Float64(1)},
+		{Var: "B", Labels: data.Labels{"instance": "b"}, Value: nil},
+	}
+
+	samples := NewSamples("job:requests:rate5m", map[string]string{"team": "rule"}, values, ts)
+
+	require.Equal(t, []Sample{
+		{
+			Labels:    data.Labels{"__name__": "job:requests:rate5m", "instance": "a", "team": "rule"},
+			Value:     1,
+			Timestamp: ts,
+		},
+	}, samples)
+}
+
+func TestRemoteWriter(t *testing.T) {
+	t.Run("should send samples to the remote write endpoint", func(t *testing.T) {
+		var received prompb.WriteRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+			user, password, ok := r.BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "user", user)
+			require.Equal(t, "password", password)
+
+			compressed, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			b, err := snappy.Decode(nil, compressed)
+			require.NoError(t, err)
+			require.NoError(t, proto.Unmarshal(b, &received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(server.Close)
+
+		writer := NewRemoteWriter(RemoteWriteConfig{URL: server.URL, User: "user", Password: "password", Timeout: time.Second}, log.New("test"))
+		err := writer.Write(context.Background(), 1, []Sample{
+			{
+				Labels:    data.Labels{"__name__": "test", "b": "2", "a": "1"},
+				Value:     5,
+				Timestamp: time.Unix(10, 0),
+			},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, received.Timeseries, 1)
+		require.Equal(t, []prompb.Label{
+			{Name: "__name__", Value: "test"},
+			{Name: "a", Value: "1"},
+			{Name: "b", Value: "2"},
+		}, received.Timeseries[0].Labels)
+		require.Equal(t, []prompb.Sample{{Value: 5, Timestamp: 10000}}, received.Timeseries[0].Samples)
+	})
+
+	t.Run("should return an error on unexpected status code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("out of order sample"))
+		}))
+		t.Cleanup(server.Close)
+
+		writer := NewRemoteWriter(RemoteWriteConfig{URL: server.URL, Timeout: time.Second}, log.New("test"))
+		err := writer.Write(context.Background(), 1, []Sample{{Labels: data.Labels{"__name__": "test"}, Value: 1, Timestamp: time.Unix(10, 0)}})
+		require.EqualError(t, err, "unexpected status code 400 from remote write endpoint: out of order sample")
+	})
+}
@@ -25,6 +25,7 @@ const (
 
 // stateToPostableAlert converts a state to a model that is accepted by Alertmanager. Annotations and Labels are copied from the state.
 // - if state has at least one result, a new label '__value_string__' is added to the label set
+// - if state has an image, its URL is added to the annotation ngModels.ImageURLAnnotation
 // - the alert's GeneratorURL is constructed to point to the alert edit page
 // - if evaluation state is either NoData or Error, the resulting set of labels is changed:
 //   - original alert name (label: model.AlertNameLabel) is backed up to OriginalAlertName
@@ -37,6 +38,12 @@ func stateToPostableAlert(alertState *state.State, appURL *url.URL) *models.Post
 		nA["__value_string__"] = alertState.Results[0].EvaluationString
 	}
 
+	if alertState.ImageURL != "" {
+		nA[ngModels.ImageURLAnnotation] = alertState.ImageURL
+	} else {
+		delete(nA, ngModels.ImageURLAnnotation)
+	}
+
 	var urlStr string
 	if uid := nL[ngModels.RuleUIDLabel]; len(uid) > 0 && appURL != nil {
 		u := *appURL
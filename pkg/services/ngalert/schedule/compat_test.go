@@ -120,6 +120,21 @@ func Test_stateToPostableAlert(t *testing.T) {
 					result = stateToPostableAlert(alertState, appURL)
 					require.Equal(t, expected, result.Annotations)
 				})
+
+				t.Run("add the image URL if it has an image", func(t *testing.T) {
+					alertState := randomState(tc.state)
+					alertState.Annotations = randomMapOfStrings()
+					alertState.ImageURL = "https://images.example.com/" + util.GenerateShortUID() + ".png"
+
+					result := stateToPostableAlert(alertState, appURL)
+					require.Equal(t, alertState.ImageURL, result.Annotations[ngModels.ImageURLAnnotation])
+
+					// the annotation cannot be set by users
+					alertState.ImageURL = ""
+					alertState.Annotations[ngModels.ImageURLAnnotation] = "http://localhost/private"
+					result = stateToPostableAlert(alertState, appURL)
+					require.NotContains(t, result.Annotations, ngModels.ImageURLAnnotation)
+				})
 			})
 
 			switch tc.state {
@@ -5,15 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	grafanaModels "github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -23,6 +28,10 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// folderTitleRefreshInterval is how often the title of the folder of an alert rule that enables the
+// computed labels is fetched again, in addition to with each new version of the rule.
+const folderTitleRefreshInterval = time.Minute
+
 // ScheduleService handles scheduling
 type ScheduleService interface {
 	Run(context.Context) error
@@ -77,6 +86,18 @@ type schedule struct {
 	multiOrgNotifier *notifier.MultiOrgAlertmanager
 	metrics          *metrics.Scheduler
 
+	// recordingWriter writes the results of recording rules.
+	recordingWriter recording.Writer
+
+	// imageService captures the images of the panels of the alert rules that start firing.
+	imageService image.ImageService
+
+	// membership provides the instances the alert rule evaluation is sharded across.
+	// It is nil if sharding is disabled.
+	membership Membership
+	ring       *ring
+	ownedRules map[models.AlertRuleKey]struct{}
+
 	// Senders help us send alerts to external Alertmanagers.
 	sendersMtx              sync.RWMutex
 	sendersCfgHash          map[int64]string
@@ -104,12 +125,20 @@ type SchedulerCfg struct {
 	AdminConfigPollInterval time.Duration
 	DisabledOrgs            map[int64]struct{}
 	MinRuleInterval         time.Duration
+	RecordingWriter         recording.Writer
+	ImageService            image.ImageService
+	Membership              Membership
 }
 
 // NewScheduler returns a new schedule.
 func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL *url.URL, stateManager *state.Manager) *schedule {
 	ticker := alerting.NewTicker(cfg.C.Now(), time.Second*0, cfg.C, int64(cfg.BaseInterval.Seconds()))
 
+	imageService := cfg.ImageService
+	if imageService == nil {
+		imageService = image.NoopImageService{}
+	}
+
 	sch := schedule{
 		registry:                alertRuleRegistry{alertRuleInfo: make(map[models.AlertRuleKey]alertRuleInfo)},
 		maxAttempts:             cfg.MaxAttempts,
@@ -134,6 +163,9 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL *url.URL,
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
 		disabledOrgs:            cfg.DisabledOrgs,
 		minRuleInterval:         cfg.MinRuleInterval,
+		recordingWriter:         cfg.RecordingWriter,
+		imageService:            imageService,
+		membership:              cfg.Membership,
 	}
 	return &sch
 }
@@ -160,6 +192,16 @@ func (sch *schedule) Run(ctx context.Context) error {
 	var wg sync.WaitGroup
 	wg.Add(2)
 
+	if m, ok := sch.membership.(membershipRunner); ok {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.Run(ctx); err != nil {
+				sch.log.Error("failure while running the alert rule evaluation sharding membership", "err", err)
+			}
+		}()
+	}
+
 	go func() {
 		defer wg.Done()
 		if err := sch.ruleEvaluationLoop(ctx); err != nil {
@@ -331,6 +373,7 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 			}
 			alertRules := sch.fetchAllDetails(disabledOrgs)
 			sch.log.Debug("alert rules fetched", "count", len(alertRules), "disabled_orgs", disabledOrgs)
+			alertRules = sch.ownRules(alertRules)
 
 			// registeredDefinitions is a map used for finding deleted alert rules
 			// initially it is assigned to all known alert rules from the previous cycle
@@ -338,12 +381,8 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 			// so, at the end, the remaining registered alert rules are the deleted ones
 			registeredDefinitions := sch.registry.keyMap()
 
-			type readyToRunItem struct {
-				key      models.AlertRuleKey
-				ruleInfo alertRuleInfo
-			}
-
 			readyToRun := make([]readyToRunItem, 0)
+			pausedRules := make([]models.AlertRuleKey, 0)
 			for _, item := range alertRules {
 				key := item.GetKey()
 				itemVersion := item.Version
@@ -371,25 +410,35 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 					continue
 				}
 
+				// remove the alert rule from the registered alert rules
+				delete(registeredDefinitions, key)
+
+				if item.IsPaused {
+					pausedRules = append(pausedRules, key)
+					continue
+				}
+
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
 				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
-					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo})
+					readyToRun = append(readyToRun, readyToRunItem{rule: item, ruleInfo: ruleInfo})
 				}
-
-				// remove the alert rule from the registered alert rules
-				delete(registeredDefinitions, key)
 			}
 
+			sch.pauseRules(pausedRules, tick)
+
+			// the rule groups are spread across the base interval and
+			// the rules of a group are evaluated sequentially in their order
+			groups := groupReadyToRun(readyToRun)
 			var step int64 = 0
-			if len(readyToRun) > 0 {
-				step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
+			if len(groups) > 0 {
+				step = sch.baseInterval.Nanoseconds() / int64(len(groups))
 			}
 
-			for i := range readyToRun {
-				item := readyToRun[i]
+			for i := range groups {
+				group := groups[i]
 
 				time.AfterFunc(time.Duration(int64(i)*step), func() {
-					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version}
+					sch.evaluateGroup(ctx, tick, group)
 				})
 			}
 
@@ -440,7 +489,25 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		return q.Result, nil
 	}
 
-	evaluate := func(alertRule *models.AlertRule, attempt int64, ctx *evalContext) error {
+	// folderTitle is the title of the folder of the rule, it's only used by the rules that enable the
+	// computed labels. It's fetched with each new version of the rule and refreshed periodically, so that
+	// renaming the folder updates the labels without a new version of the rule.
+	var folderTitle string
+	var folderTitleFetchedAt time.Time
+	updateFolderTitle := func(alertRule *models.AlertRule) {
+		folderTitleFetchedAt = sch.clock.Now()
+		// the scheduler reads the folder as an administrator of the organization of the rule
+		user := &grafanaModels.SignedInUser{OrgId: key.OrgID, OrgRole: grafanaModels.ROLE_ADMIN}
+		folder, err := sch.ruleStore.GetNamespaceByUID(grafanaCtx, alertRule.NamespaceUID, key.OrgID, user)
+		if err != nil {
+			// the evaluation goes on with the previous folder title, if any
+			logger.Error("failed to fetch the folder of the alert rule", "namespace", alertRule.NamespaceUID, "err", err)
+			return
+		}
+		folderTitle = folder.Title
+	}
+
+	record := func(alertRule *models.AlertRule, attempt int64, ctx *evalContext) error {
 		logger := logger.New("version", alertRule.Version, "attempt", attempt, "now", ctx.now)
 		start := sch.clock.Now()
 
@@ -449,6 +516,48 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 			OrgID:     alertRule.OrgID,
 			Data:      alertRule.Data,
 		}
+		values, err := sch.evaluator.ConditionValues(&condition, ctx.now, sch.dataService)
+		dur := sch.clock.Now().Sub(start)
+		evalTotal.Inc()
+		evalDuration.Observe(dur.Seconds())
+		if err != nil {
+			evalTotalFailures.Inc()
+			logger.Error("failed to evaluate recording rule", "duration", dur, "err", err)
+			return err
+		}
+
+		samples := recording.NewSamples(alertRule.Record, alertRule.Labels, values, ctx.now)
+		if err := sch.recordingWriter.Write(grafanaCtx, alertRule.OrgID, samples); err != nil {
+			logger.Error("failed to write recording rule samples", "count", len(samples), "err", err)
+			return err
+		}
+		logger.Debug("recording rule evaluated", "record", alertRule.Record, "samples", len(samples), "duration", dur)
+		return nil
+	}
+
+	evaluate := func(alertRule *models.AlertRule, attempt int64, ctx *evalContext) error {
+		if alertRule.IsPaused {
+			logger.Debug("skipping evaluation of paused alert rule", "version", alertRule.Version)
+			return nil
+		}
+
+		if alertRule.IsRecordingRule() {
+			return record(alertRule, attempt, ctx)
+		}
+
+		logger := logger.New("version", alertRule.Version, "attempt", attempt, "now", ctx.now)
+		start := sch.clock.Now()
+
+		data, err := sch.stateManager.WithLoadedDimensions(alertRule)
+		if err != nil {
+			logger.Error("failed to set the loaded dimensions of threshold expressions", "err", err)
+			return err
+		}
+		condition := models.Condition{
+			Condition: alertRule.Condition,
+			OrgID:     alertRule.OrgID,
+			Data:      data,
+		}
 		results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
 		dur := sch.clock.Now().Sub(start)
 		evalTotal.Inc()
@@ -461,7 +570,8 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		}
 		logger.Debug("alert rule evaluated", "results", results, "duration", dur)
 
-		processedStates := sch.stateManager.ProcessEvalResults(context.Background(), alertRule, results)
+		processedStates := sch.stateManager.ProcessEvalResults(context.Background(), alertRule, results, folderTitle)
+		sch.setImages(grafanaCtx, alertRule, processedStates, logger)
 		sch.saveAlertStates(processedStates)
 		alerts := FromAlertStateToPostableAlerts(processedStates, sch.stateManager, sch.appURL)
 
@@ -470,35 +580,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 			return nil
 		}
 
-		var localNotifierExist, externalNotifierExist bool
-		logger.Debug("sending alerts to notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts)
-		n, err := sch.multiOrgNotifier.AlertmanagerFor(alertRule.OrgID)
-		if err == nil {
-			localNotifierExist = true
-			if err := n.PutAlerts(alerts); err != nil {
-				logger.Error("failed to put alerts in the local notifier", "count", len(alerts.PostableAlerts), "err", err)
-			}
-		} else {
-			if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
-				logger.Debug("local notifier was not found")
-			} else {
-				logger.Error("local notifier is not available", "err", err)
-			}
-		}
-
-		// Send alerts to external Alertmanager(s) if we have a sender for this organization.
-		sch.sendersMtx.RLock()
-		defer sch.sendersMtx.RUnlock()
-		s, ok := sch.senders[alertRule.OrgID]
-		if ok {
-			logger.Debug("sending alerts to external notifier", "count", len(alerts.PostableAlerts))
-			s.SendAlerts(alerts)
-			externalNotifierExist = true
-		}
-
-		if !localNotifierExist && !externalNotifierExist {
-			logger.Error("no external or internal notifier - alerts not delivered!", "count", len(alerts.PostableAlerts))
-		}
+		sch.notify(alertRule.OrgID, alerts, logger)
 		return nil
 	}
 
@@ -520,6 +602,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		select {
 		case ctx := <-evalCh:
 			if evalRunning {
+				if ctx.done != nil {
+					close(ctx.done)
+				}
 				continue
 			}
 
@@ -528,18 +613,26 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				defer func() {
 					evalRunning = false
 					sch.evalApplied(key, ctx.now)
+					if ctx.done != nil {
+						close(ctx.done)
+					}
 				}()
 
 				err := retryIfError(func(attempt int64) error {
 					// fetch latest alert rule version
+					ruleUpdated := false
 					if currentRule == nil || currentRule.Version < ctx.version {
 						newRule, err := updateRule()
 						if err != nil {
 							return err
 						}
 						currentRule = newRule
+						ruleUpdated = true
 						logger.Debug("new alert rule version fetched", "title", newRule.Title, "version", newRule.Version)
 					}
+					if currentRule.ComputedLabels && (ruleUpdated || sch.clock.Now().Sub(folderTitleFetchedAt) >= folderTitleRefreshInterval) {
+						updateFolderTitle(currentRule)
+					}
 					return evaluate(currentRule, attempt, ctx)
 				})
 				if err != nil {
@@ -557,13 +650,66 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 	}
 }
 
+// notify sends the alerts to the local Alertmanager and the external Alertmanager(s) of the organization.
+func (sch *schedule) notify(orgID int64, alerts apimodels.PostableAlerts, logger log.Logger) {
+	var localNotifierExist, externalNotifierExist bool
+	logger.Debug("sending alerts to notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts)
+	n, err := sch.multiOrgNotifier.AlertmanagerFor(orgID)
+	if err == nil {
+		localNotifierExist = true
+		if err := n.PutAlerts(alerts); err != nil {
+			logger.Error("failed to put alerts in the local notifier", "count", len(alerts.PostableAlerts), "err", err)
+		}
+	} else {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			logger.Debug("local notifier was not found")
+		} else {
+			logger.Error("local notifier is not available", "err", err)
+		}
+	}
+
+	// Send alerts to external Alertmanager(s) if we have a sender for this organization.
+	sch.sendersMtx.RLock()
+	defer sch.sendersMtx.RUnlock()
+	s, ok := sch.senders[orgID]
+	if ok {
+		logger.Debug("sending alerts to external notifier", "count", len(alerts.PostableAlerts))
+		s.SendAlerts(alerts)
+		externalNotifierExist = true
+	}
+
+	if !localNotifierExist && !externalNotifierExist {
+		logger.Error("no external or internal notifier - alerts not delivered!", "count", len(alerts.PostableAlerts))
+	}
+}
+
+// pauseRules marks the states of the paused alert rules as paused, saves them and
+// sends the alerts resolved by pausing the alert rules.
+func (sch *schedule) pauseRules(keys []models.AlertRuleKey, now time.Time) {
+	for _, key := range keys {
+		states := sch.stateManager.MarkPaused(key.OrgID, key.UID, now)
+		if len(states) == 0 {
+			continue
+		}
+
+		logger := sch.log.New("uid", key.UID, "org", key.OrgID)
+		logger.Info("alert rule paused", "states", len(states))
+		sch.saveAlertStates(states)
+
+		alerts := FromAlertStateToPostableAlerts(states, sch.stateManager, sch.appURL)
+		if len(alerts.PostableAlerts) > 0 {
+			sch.notify(key.OrgID, alerts, logger)
+		}
+	}
+}
+
 func (sch *schedule) saveAlertStates(states []*state.State) {
 	sch.log.Debug("saving alert states", "count", len(states))
 	for _, s := range states {
 		cmd := models.SaveAlertInstanceCommand{
 			RuleOrgID:         s.OrgID,
 			RuleUID:           s.AlertRuleUID,
-			Labels:            models.InstanceLabels(s.Labels),
+			Labels:            models.InstanceLabels(s.InstanceLabels()),
 			State:             models.InstanceStateType(s.State.String()),
 			LastEvalTime:      s.LastEvaluationTime,
 			CurrentStateSince: s.StartsAt,
@@ -659,6 +805,90 @@ type alertRuleInfo struct {
 type evalContext struct {
 	now     time.Time
 	version int64
+	// done is closed once the evaluation is complete, if not nil.
+	done chan struct{}
+}
+
+type readyToRunItem struct {
+	rule     *models.AlertRule
+	ruleInfo alertRuleInfo
+}
+
+// groupReadyToRun groups the alert rules ready to run by rule group and
+// sorts the alert rules of each group by their position in the group.
+func groupReadyToRun(items []readyToRunItem) [][]readyToRunItem {
+	groups := make([][]readyToRunItem, 0)
+	index := make(map[models.AlertRuleGroupKey]int)
+	for _, item := range items {
+		key := item.rule.GetGroupKey()
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], item)
+	}
+
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].rule.RuleGroupIndex < group[j].rule.RuleGroupIndex
+		})
+	}
+	return groups
+}
+
+// setImages captures an image of the panel of the alert rule if some of its alert instances just started
+// firing and sets it on them, so that their notifications include it. The image is kept until they fire again.
+// The notifications and the next evaluation of the alert rule wait for the capture, so it is abandoned after
+// half of the evaluation interval of the alert rule.
+func (sch *schedule) setImages(ctx context.Context, alertRule *models.AlertRule, states []*state.State, logger log.Logger) {
+	var firing []*state.State
+	for _, s := range states {
+		if s.State == eval.Alerting && s.StartsAt.Equal(s.LastEvaluationTime) {
+			firing = append(firing, s)
+		}
+	}
+	if len(firing) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(alertRule.IntervalSeconds)*time.Second/2)
+	defer cancel()
+	imageURL, err := sch.imageService.NewImage(ctx, alertRule)
+	if err != nil {
+		if !errors.Is(err, image.ErrNoPanel) {
+			logger.Warn("failed to capture an image of the panel of the alert rule", "err", err)
+		}
+		return
+	}
+	sch.stateManager.SetImageURL(firing, imageURL)
+}
+
+// evaluateGroup evaluates the alert rules of a rule group sequentially, so that alert rules
+// can reference the results of the alert rules evaluated before them. The evaluation of the
+// group is abandoned if it does not complete within the interval of the group.
+func (sch *schedule) evaluateGroup(ctx context.Context, tick time.Time, group []readyToRunItem) {
+	interval := time.Duration(group[0].rule.IntervalSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, interval)
+	defer cancel()
+
+	for i, item := range group {
+		evalCtx := &evalContext{now: tick, version: item.ruleInfo.version, done: make(chan struct{})}
+		select {
+		case item.ruleInfo.evalCh <- evalCtx:
+		case <-ctx.Done():
+			sch.log.Warn("rule group evaluation did not complete within its interval, skipping the remaining alert rules", "group", item.rule.GetGroupKey(), "skipped", len(group)-i)
+			return
+		}
+
+		select {
+		case <-evalCtx.done:
+		case <-ctx.Done():
+			sch.log.Warn("rule group evaluation did not complete within its interval, skipping the remaining alert rules", "group", item.rule.GetGroupKey(), "skipped", len(group)-i-1)
+			return
+		}
+	}
 }
 
 // overrideCfg is only used on tests.
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net/url"
@@ -23,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -307,6 +309,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 				s := states[0]
 				t.Logf("State: %v", s)
 				require.Equal(t, rule.UID, s.AlertRuleUID)
+				require.Equal(t, "folder-"+rule.NamespaceUID, s.Labels[models.FolderTitleLabel])
 				require.Len(t, s.Results, 1)
 				var expectedStatus = evalState
 				if evalState == eval.Pending {
@@ -566,12 +569,243 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		})
 	})
 
+	t.Run("when a recording rule is evaluated", func(t *testing.T) {
+		evalChan := make(chan *evalContext)
+		evalAppliedChan := make(chan time.Time)
+
+		sch, ruleStore, instanceStore, _, _ := createSchedule(evalAppliedChan)
+
+		rule := CreateTestAlertRule(t, ruleStore, 10, rand.Int63(), eval.Alerting)
+		rule.Record = "test:recorded"
+		rule.Labels = map[string]string{"team": "alerting"}
+
+		go func() {
+			stop := make(chan struct{})
+			t.Cleanup(func() {
+				close(stop)
+			})
+			_ = sch.ruleRoutine(context.Background(), rule.GetKey(), evalChan, stop)
+		}()
+
+		expectedTime := time.UnixMicro(rand.Int63())
+		evalChan <- &evalContext{
+			now:     expectedTime,
+			version: rule.Version,
+		}
+		waitForTimeChannel(t, evalAppliedChan)
+
+		t.Run("it should write the result to the recording writer", func(t *testing.T) {
+			samples := sch.recordingWriter.(*recording.MemoryWriter).Samples(rule.OrgID)
+			require.Len(t, samples, 1)
+			require.Equal(t, data.Labels{"__name__": "test:recorded", "team": "alerting"}, samples[0].Labels)
+			require.Equal(t, float64(1), samples[0].Value)
+			require.Equal(t, expectedTime, samples[0].Timestamp)
+		})
+
+		t.Run("it should not create alert states", func(t *testing.T) {
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			require.Empty(t, instanceStore.recordedOps)
+		})
+	})
+
+	t.Run("when a paused rule is evaluated", func(t *testing.T) {
+		evalChan := make(chan *evalContext)
+		evalAppliedChan := make(chan time.Time)
+
+		sch, ruleStore, instanceStore, _, _ := createSchedule(evalAppliedChan)
+
+		rule := CreateTestAlertRule(t, ruleStore, 10, rand.Int63(), eval.Alerting)
+		rule.IsPaused = true
+
+		go func() {
+			stop := make(chan struct{})
+			t.Cleanup(func() {
+				close(stop)
+			})
+			_ = sch.ruleRoutine(context.Background(), rule.GetKey(), evalChan, stop)
+		}()
+
+		evalChan <- &evalContext{
+			now:     time.UnixMicro(rand.Int63()),
+			version: rule.Version,
+		}
+		waitForTimeChannel(t, evalAppliedChan)
+
+		t.Run("it should not evaluate the rule", func(t *testing.T) {
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			require.Empty(t, instanceStore.recordedOps)
+		})
+	})
+
+	t.Run("when a rule is paused", func(t *testing.T) {
+		sch, ruleStore, instanceStore, _, _ := createSchedule(make(chan time.Time))
+
+		rule := CreateTestAlertRule(t, ruleStore, 10, rand.Int63(), eval.Alerting)
+		now := time.UnixMicro(rand.Int63())
+		sch.stateManager.Put([]*state.State{{
+			AlertRuleUID: rule.UID,
+			OrgID:        rule.OrgID,
+			CacheId:      "test",
+			State:        eval.Alerting,
+			StartsAt:     now.Add(-time.Minute),
+		}})
+
+		sch.pauseRules([]models.AlertRuleKey{rule.GetKey()}, now)
+
+		t.Run("it should mark its states as paused", func(t *testing.T) {
+			states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			require.Len(t, states, 1)
+			require.True(t, states[0].Paused)
+			require.Equal(t, eval.Normal, states[0].State)
+		})
+
+		t.Run("it should save the paused states", func(t *testing.T) {
+			require.Len(t, instanceStore.recordedOps, 1)
+			cmd, ok := instanceStore.recordedOps[0].(models.SaveAlertInstanceCommand)
+			require.True(t, ok)
+			require.Equal(t, models.InstanceStateNormal, cmd.State)
+			require.Equal(t, now, cmd.LastEvalTime)
+		})
+	})
+
 	t.Run("when there are no alerts to send it should not call notifiers", func(t *testing.T) {
 		// TODO needs some mocking/stubbing for Alertmanager and Sender to make sure it was not called
 		t.Skip()
 	})
 }
 
+func TestGroupReadyToRun(t *testing.T) {
+	newItem := func(uid, group string, index int) readyToRunItem {
+		return readyToRunItem{
+			rule: &models.AlertRule{OrgID: 1, UID: uid, NamespaceUID: "ns", RuleGroup: group, RuleGroupIndex: index},
+		}
+	}
+	items := []readyToRunItem{
+		newItem("a-3", "group-a", 3),
+		newItem("b-1", "group-b", 1),
+		newItem("a-1", "group-a", 1),
+		newItem("a-2", "group-a", 2),
+	}
+
+	groups := groupReadyToRun(items)
+	require.Len(t, groups, 2)
+
+	uids := func(group []readyToRunItem) []string {
+		result := make([]string, 0, len(group))
+		for _, item := range group {
+			result = append(result, item.rule.UID)
+		}
+		return result
+	}
+	require.Equal(t, []string{"a-1", "a-2", "a-3"}, uids(groups[0]))
+	require.Equal(t, []string{"b-1"}, uids(groups[1]))
+}
+
+func TestSchedule_evaluateGroup(t *testing.T) {
+	sch, _ := setupScheduler(t, newFakeRuleStore(t), &FakeInstanceStore{}, newFakeAdminConfigStore(t), nil)
+
+	newGroup := func(size int, interval int64) []readyToRunItem {
+		group := make([]readyToRunItem, 0, size)
+		for i := 1; i <= size; i++ {
+			group = append(group, readyToRunItem{
+				rule:     &models.AlertRule{OrgID: 1, UID: fmt.Sprintf("rule-%d", i), RuleGroup: "group", RuleGroupIndex: i, IntervalSeconds: interval},
+				ruleInfo: alertRuleInfo{evalCh: make(chan *evalContext, 1), stopCh: make(chan struct{})},
+			})
+		}
+		return group
+	}
+
+	t.Run("it should evaluate an alert rule only after the previous one completes", func(t *testing.T) {
+		group := newGroup(2, 10)
+		done := make(chan struct{})
+		go func() {
+			sch.evaluateGroup(context.Background(), time.Now(), group)
+			close(done)
+		}()
+
+		first := <-group[0].ruleInfo.evalCh
+		select {
+		case <-group[1].ruleInfo.evalCh:
+			t.Fatal("the second alert rule was evaluated before the first one completed")
+		case <-time.After(100 * time.Millisecond):
+		}
+		close(first.done)
+
+		second := <-group[1].ruleInfo.evalCh
+		close(second.done)
+		<-done
+	})
+
+	t.Run("it should skip the remaining alert rules when the group interval elapses", func(t *testing.T) {
+		group := newGroup(2, 1)
+		done := make(chan struct{})
+		go func() {
+			sch.evaluateGroup(context.Background(), time.Now(), group)
+			close(done)
+		}()
+
+		<-group[0].ruleInfo.evalCh
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the group evaluation did not time out")
+		}
+		require.Empty(t, group[1].ruleInfo.evalCh)
+	})
+}
+
+func TestSchedule_setImages(t *testing.T) {
+	sch, _ := setupScheduler(t, newFakeRuleStore(t), &FakeInstanceStore{}, newFakeAdminConfigStore(t), nil)
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", IntervalSeconds: 1}
+	now := time.Now()
+
+	newStates := func() []*state.State {
+		return []*state.State{
+			{State: eval.Alerting, StartsAt: now, LastEvaluationTime: now},
+			{State: eval.Alerting, StartsAt: now.Add(-time.Minute), LastEvaluationTime: now, ImageURL: "https://images.example.com/old.png"},
+			{State: eval.Normal, LastEvaluationTime: now},
+		}
+	}
+
+	t.Run("it should capture an image for the alert instances that start firing", func(t *testing.T) {
+		images := &fakeImageService{url: "https://images.example.com/new.png"}
+		sch.imageService = images
+
+		states := newStates()
+		sch.setImages(context.Background(), rule, states, sch.log)
+		require.Equal(t, 1, images.calls)
+		require.Equal(t, "https://images.example.com/new.png", states[0].ImageURL)
+		require.Equal(t, "https://images.example.com/old.png", states[1].ImageURL)
+		require.Empty(t, states[2].ImageURL)
+	})
+
+	t.Run("it should not capture an image if no alert instance starts firing", func(t *testing.T) {
+		images := &fakeImageService{url: "https://images.example.com/new.png"}
+		sch.imageService = images
+
+		sch.setImages(context.Background(), rule, newStates()[1:], sch.log)
+		require.Equal(t, 0, images.calls)
+	})
+
+	t.Run("it should send the notifications without image if the capture fails", func(t *testing.T) {
+		sch.imageService = &fakeImageService{err: errors.New("renderer failed")}
+
+		states := newStates()
+		sch.setImages(context.Background(), rule, states, sch.log)
+		require.Empty(t, states[0].ImageURL)
+	})
+
+	t.Run("it should abandon the capture after half of the evaluation interval", func(t *testing.T) {
+		sch.imageService = &fakeImageService{url: "https://images.example.com/new.png", block: true}
+
+		states := newStates()
+		start := time.Now()
+		sch.setImages(context.Background(), rule, states, sch.log)
+		require.Less(t, time.Since(start), time.Second)
+		require.Empty(t, states[0].ImageURL)
+	})
+}
+
 func setupScheduler(t *testing.T, rs store.RuleStore, is store.InstanceStore, acs store.AdminConfigurationStore, registry *prometheus.Registry) (*schedule, *clock.Mock) {
 	t.Helper()
 
@@ -598,8 +832,9 @@ func setupScheduler(t *testing.T, rs store.RuleStore, is store.InstanceStore, ac
 		Logger:                  logger,
 		Metrics:                 m.GetSchedulerMetrics(),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
+		RecordingWriter:         recording.NewMemoryWriter(),
 	}
-	st := state.NewManager(schedCfg.Logger, m.GetStateMetrics(), nil, rs, is)
+	st := state.NewManager(schedCfg.Logger, m.GetStateMetrics(), nil, rs, is, nil)
 	appUrl := &url.URL{
 		Scheme: "http",
 		Host:   "localhost",
@@ -0,0 +1,270 @@
+package schedule
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/alertmanager/cluster"
+)
+
+// ringTokensPerMember is the number of points each member is assigned on the hash ring.
+// More points give a more even distribution of the alert rules between the members.
+const ringTokensPerMember = 128
+
+// Membership provides the Grafana instances taking part in the sharding of the alert rule evaluation.
+type Membership interface {
+	// Self returns the identifier of this instance.
+	Self() string
+	// Members returns the identifiers of all live instances, this one included.
+	Members() []string
+}
+
+// membershipRunner is implemented by the memberships that need to run in the background
+// for as long as the scheduler is running.
+type membershipRunner interface {
+	Run(ctx context.Context) error
+}
+
+// ring is a consistent hash ring that assigns each alert rule to a single member.
+// When a member joins or leaves only the alert rules of that member move.
+type ring struct {
+	members []string
+	tokens  []uint64
+	owners  map[uint64]string
+}
+
+func newRing(members []string) *ring {
+	r := &ring{
+		members: make([]string, len(members)),
+		tokens:  make([]uint64, 0, len(members)*ringTokensPerMember),
+		owners:  make(map[uint64]string, len(members)*ringTokensPerMember),
+	}
+	copy(r.members, members)
+	sort.Strings(r.members)
+
+	for _, member := range r.members {
+		for i := 0; i < ringTokensPerMember; i++ {
+			token := ringHash(fmt.Sprintf("%s-%d", member, i))
+			if _, ok := r.owners[token]; ok {
+				continue
+			}
+			r.owners[token] = member
+			r.tokens = append(r.tokens, token)
+		}
+	}
+	sort.Slice(r.tokens, func(i, j int) bool { return r.tokens[i] < r.tokens[j] })
+	return r
+}
+
+// owner returns the member responsible for evaluating the alert rules of the rule group.
+func (r *ring) owner(key models.AlertRuleGroupKey) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := ringHash(fmt.Sprintf("%d/%s/%s", key.OrgID, key.NamespaceUID, key.RuleGroup))
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= h })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.owners[r.tokens[i]]
+}
+
+// hasMembers returns true if the ring was built for exactly the provided members.
+func (r *ring) hasMembers(members []string) bool {
+	if len(r.members) != len(members) {
+		return false
+	}
+	sorted := make([]string, len(members))
+	copy(sorted, members)
+	sort.Strings(sorted)
+	for i := range sorted {
+		if sorted[i] != r.members[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func ringHash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	// FNV spreads similar inputs poorly, so finalize it with the splitmix64 mixer.
+	x := binary.BigEndian.Uint64(h.Sum(nil))
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// DatabaseMembership discovers the members through the database. Each instance periodically
+// writes a heartbeat and instances without a recent heartbeat are considered gone.
+type DatabaseMembership struct {
+	store             store.SchedulerMemberStore
+	nodeID            string
+	heartbeatInterval time.Duration
+	memberTimeout     time.Duration
+	clock             clock.Clock
+	log               log.Logger
+
+	mtx     sync.RWMutex
+	members []string
+}
+
+// NewDatabaseMembership returns a new DatabaseMembership for the instance identified by nodeID.
+func NewDatabaseMembership(store store.SchedulerMemberStore, nodeID string, heartbeatInterval, memberTimeout time.Duration, c clock.Clock, logger log.Logger) *DatabaseMembership {
+	return &DatabaseMembership{
+		store:             store,
+		nodeID:            nodeID,
+		heartbeatInterval: heartbeatInterval,
+		memberTimeout:     memberTimeout,
+		clock:             c,
+		log:               logger,
+		members:           []string{nodeID},
+	}
+}
+
+func (m *DatabaseMembership) Self() string {
+	return m.nodeID
+}
+
+func (m *DatabaseMembership) Members() []string {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	members := make([]string, len(m.members))
+	copy(members, m.members)
+	return members
+}
+
+// Run writes the heartbeat of this instance and refreshes the members until the context is cancelled.
+// On exit the instance removes itself so that the remaining instances take over its alert rules right away.
+func (m *DatabaseMembership) Run(ctx context.Context) error {
+	ticker := m.clock.Ticker(m.heartbeatInterval)
+	defer ticker.Stop()
+
+	m.sync(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			m.sync(ctx)
+		case <-ctx.Done():
+			if err := m.store.DeleteSchedulerMember(context.Background(), m.nodeID); err != nil {
+				m.log.Error("unable to leave the alert rule evaluation sharding", "node", m.nodeID, "err", err)
+			}
+			return nil
+		}
+	}
+}
+
+func (m *DatabaseMembership) sync(ctx context.Context) {
+	now := m.clock.Now()
+	if err := m.store.HeartbeatSchedulerMember(ctx, m.nodeID, now); err != nil {
+		m.log.Error("unable to write the alert rule evaluation sharding heartbeat", "node", m.nodeID, "err", err)
+		return
+	}
+
+	members, err := m.store.GetActiveSchedulerMembers(ctx, now.Add(-m.memberTimeout))
+	if err != nil {
+		m.log.Error("unable to fetch the alert rule evaluation sharding members", "err", err)
+		return
+	}
+
+	found := false
+	for _, member := range members {
+		if member == m.nodeID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		members = append(members, m.nodeID)
+	}
+
+	m.mtx.Lock()
+	m.members = members
+	m.mtx.Unlock()
+}
+
+// ClusterPeer is the part of the Alertmanager peer mesh used to discover the members.
+type ClusterPeer interface {
+	Name() string
+	Peers() []cluster.ClusterMember
+}
+
+// PeerMembership discovers the members through the Alertmanager peer mesh.
+type PeerMembership struct {
+	peer ClusterPeer
+}
+
+// NewPeerMembership returns a new PeerMembership using the provided peer.
+func NewPeerMembership(peer ClusterPeer) *PeerMembership {
+	return &PeerMembership{peer: peer}
+}
+
+func (m *PeerMembership) Self() string {
+	return m.peer.Name()
+}
+
+func (m *PeerMembership) Members() []string {
+	peers := m.peer.Peers()
+	members := make([]string, 0, len(peers))
+	for _, p := range peers {
+		members = append(members, p.Name())
+	}
+	if len(members) == 0 {
+		members = append(members, m.peer.Name())
+	}
+	return members
+}
+
+// ownRules returns the alert rules this instance is responsible for evaluating. All alert rules
+// are returned if sharding is disabled. The alert rules are sharded by rule group, as the alert
+// rules of a group are evaluated sequentially. The cached states of the alert rules that moved away from
+// this instance are dropped and the ones of the alert rules that moved to this instance are
+// reloaded from the database, so that the states keep converging through the state manager.
+func (sch *schedule) ownRules(alertRules []*models.AlertRule) []*models.AlertRule {
+	if sch.membership == nil {
+		return alertRules
+	}
+
+	members := sch.membership.Members()
+	if sch.ring == nil || !sch.ring.hasMembers(members) {
+		sch.log.Info("alert rule evaluation sharding members changed, rebalancing alert rules", "members", members)
+		sch.ring = newRing(members)
+	}
+
+	self := sch.membership.Self()
+	owned := make(map[models.AlertRuleKey]struct{}, len(alertRules))
+	result := make([]*models.AlertRule, 0, len(alertRules)/len(members)+1)
+	for _, rule := range alertRules {
+		key := rule.GetKey()
+		_, wasOwned := sch.ownedRules[key]
+		if sch.ring.owner(rule.GetGroupKey()) != self {
+			// on the first run every state is cached as the state manager warms up with all of them
+			if wasOwned || sch.ownedRules == nil {
+				sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+			}
+			continue
+		}
+		if sch.ownedRules != nil && !wasOwned {
+			sch.stateManager.WarmRule(key.OrgID, key.UID)
+		}
+		owned[key] = struct{}{}
+		result = append(result, rule)
+	}
+	sch.ownedRules = owned
+
+	sch.log.Debug("alert rules assigned to this instance", "count", len(result), "total", len(alertRules), "node", self)
+	return result
+}
@@ -0,0 +1,164 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestRing(t *testing.T) {
+	keys := make([]models.AlertRuleGroupKey, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, models.AlertRuleGroupKey{OrgID: int64(i%5 + 1), NamespaceUID: "namespace", RuleGroup: util.GenerateShortUID()})
+	}
+
+	r := newRing([]string{"node-a", "node-b", "node-c"})
+
+	t.Run("it should spread the rule groups across all members", func(t *testing.T) {
+		counts := map[string]int{}
+		for _, key := range keys {
+			counts[r.owner(key)]++
+		}
+		require.Len(t, counts, 3)
+		for member, count := range counts {
+			require.Greaterf(t, count, len(keys)/5, "member %s owns too few rule groups", member)
+			require.Lessf(t, count, len(keys)/2, "member %s owns too many rule groups", member)
+		}
+	})
+
+	t.Run("it should not depend on the order of the members", func(t *testing.T) {
+		other := newRing([]string{"node-c", "node-a", "node-b"})
+		require.True(t, r.hasMembers([]string{"node-c", "node-b", "node-a"}))
+		for _, key := range keys {
+			require.Equal(t, r.owner(key), other.owner(key))
+		}
+	})
+
+	t.Run("it should only move the rule groups of a member that leaves", func(t *testing.T) {
+		smaller := newRing([]string{"node-a", "node-b"})
+		require.False(t, r.hasMembers([]string{"node-a", "node-b"}))
+		for _, key := range keys {
+			before := r.owner(key)
+			if before == "node-c" {
+				require.NotEqual(t, "node-c", smaller.owner(key))
+				continue
+			}
+			require.Equal(t, before, smaller.owner(key))
+		}
+	})
+}
+
+type fakeMembership struct {
+	self    string
+	members []string
+}
+
+func (m *fakeMembership) Self() string      { return m.self }
+func (m *fakeMembership) Members() []string { return m.members }
+
+func TestSchedule_ownRules(t *testing.T) {
+	ruleStore := newFakeRuleStore(t)
+	instanceStore := &FakeInstanceStore{}
+	sch, _ := setupScheduler(t, ruleStore, instanceStore, newFakeAdminConfigStore(t), nil)
+
+	membership := &fakeMembership{self: "node-a", members: []string{"node-a", "node-b"}}
+	sch.membership = membership
+
+	rules := make([]*models.AlertRule, 0, 50)
+	ruleStore.rules[1] = map[string]map[string][]*models.AlertRule{}
+	for i := 0; i < cap(rules); i++ {
+		rule := &models.AlertRule{OrgID: 1, UID: fmt.Sprintf("rule-%d", i), NamespaceUID: "namespace", RuleGroup: fmt.Sprintf("group-%d", i)}
+		ruleStore.rules[1][rule.RuleGroup] = map[string][]*models.AlertRule{}
+		ruleStore.putRule(rule)
+		rules = append(rules, rule)
+		sch.stateManager.Put([]*state.State{{OrgID: rule.OrgID, AlertRuleUID: rule.UID, CacheId: "state"}})
+	}
+
+	owned := sch.ownRules(rules)
+	require.NotEmpty(t, owned)
+	require.Less(t, len(owned), len(rules))
+
+	ownedUIDs := map[string]struct{}{}
+	for _, rule := range owned {
+		ownedUIDs[rule.UID] = struct{}{}
+	}
+
+	t.Run("it should drop the states of the alert rules owned by other members", func(t *testing.T) {
+		for _, rule := range rules {
+			states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			if _, ok := ownedUIDs[rule.UID]; ok {
+				require.Len(t, states, 1)
+				continue
+			}
+			require.Empty(t, states)
+		}
+	})
+
+	t.Run("it should take over and reload the states of the alert rules when a member leaves", func(t *testing.T) {
+		membership.members = []string{"node-a"}
+		require.Len(t, sch.ownRules(rules), len(rules))
+
+		reloaded := map[string]struct{}{}
+		for _, op := range instanceStore.recordedOps {
+			if q, ok := op.(models.ListAlertInstancesQuery); ok {
+				reloaded[q.RuleUID] = struct{}{}
+			}
+		}
+		require.Len(t, reloaded, len(rules)-len(owned))
+		for uid := range ownedUIDs {
+			require.NotContains(t, reloaded, uid)
+		}
+	})
+}
+
+type fakeSchedulerMemberStore struct {
+	heartbeats map[string]time.Time
+}
+
+func (f *fakeSchedulerMemberStore) HeartbeatSchedulerMember(_ context.Context, nodeID string, now time.Time) error {
+	f.heartbeats[nodeID] = now
+	return nil
+}
+
+func (f *fakeSchedulerMemberStore) GetActiveSchedulerMembers(_ context.Context, since time.Time) ([]string, error) {
+	members := []string{}
+	for nodeID, heartbeat := range f.heartbeats {
+		if !heartbeat.Before(since) {
+			members = append(members, nodeID)
+		}
+	}
+	return members, nil
+}
+
+func (f *fakeSchedulerMemberStore) DeleteSchedulerMember(_ context.Context, nodeID string) error {
+	delete(f.heartbeats, nodeID)
+	return nil
+}
+
+func TestDatabaseMembership(t *testing.T) {
+	mockedClock := clock.NewMock()
+	memberStore := &fakeSchedulerMemberStore{heartbeats: map[string]time.Time{
+		"node-b": mockedClock.Now(),
+		"node-c": mockedClock.Now().Add(-2 * time.Minute),
+	}}
+	m := NewDatabaseMembership(memberStore, "node-a", 15*time.Second, time.Minute, mockedClock, log.New("test"))
+	require.Equal(t, []string{"node-a"}, m.Members())
+
+	m.sync(context.Background())
+	require.Contains(t, memberStore.heartbeats, "node-a")
+	require.ElementsMatch(t, []string{"node-a", "node-b"}, m.Members())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, m.Run(ctx))
+	require.NotContains(t, memberStore.heartbeats, "node-a")
+}
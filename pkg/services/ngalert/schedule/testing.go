@@ -162,6 +162,9 @@ func (f *fakeRuleStore) GetNamespaces(_ context.Context, _ int64, _ *models2.Sig
 func (f *fakeRuleStore) GetNamespaceByTitle(_ context.Context, _ string, _ int64, _ *models2.SignedInUser, _ bool) (*models2.Folder, error) {
 	return nil, nil
 }
+func (f *fakeRuleStore) GetNamespaceByUID(_ context.Context, uid string, _ int64, _ *models2.SignedInUser) (*models2.Folder, error) {
+	return &models2.Folder{Uid: uid, Title: "folder-" + uid}, nil
+}
 func (f *fakeRuleStore) GetOrgRuleGroups(q *models.ListOrgRuleGroupsQuery) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -175,6 +178,15 @@ func (f *fakeRuleStore) UpsertAlertRules(q []store.UpsertRule) error {
 	f.recordedOps = append(f.recordedOps, q)
 	return nil
 }
+func (f *fakeRuleStore) UpdateRuleGroups(cmds []store.UpdateRuleGroupCmd) error {
+	for _, cmd := range cmds {
+		if err := f.UpdateRuleGroup(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (f *fakeRuleStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -212,6 +224,8 @@ func (f *fakeRuleStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
 			RuleGroup:       cmd.RuleGroupConfig.Name,
 			NoDataState:     models.NoDataState(r.GrafanaManagedAlert.NoDataState),
 			ExecErrState:    models.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+			IsPaused:        r.GrafanaManagedAlert.IsPaused,
+			ComputedLabels:  r.GrafanaManagedAlert.ComputedLabels,
 			Version:         1,
 		}
 
@@ -219,6 +233,7 @@ func (f *fakeRuleStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
 			new.For = time.Duration(r.ApiRuleNode.For)
 			new.Annotations = r.ApiRuleNode.Annotations
 			new.Labels = r.ApiRuleNode.Labels
+			new.Record = r.ApiRuleNode.Record
 		}
 
 		if new.NoDataState == "" {
@@ -266,6 +281,30 @@ func (f *FakeInstanceStore) SaveAlertInstance(q *models.SaveAlertInstanceCommand
 func (f *FakeInstanceStore) FetchOrgIds() ([]int64, error)                  { return []int64{}, nil }
 func (f *FakeInstanceStore) DeleteAlertInstance(_ int64, _, _ string) error { return nil }
 
+// FakeStateHistoryStore keeps the recorded state transitions in memory.
+type FakeStateHistoryStore struct {
+	mtx     sync.Mutex
+	Entries []*models.AlertStateHistory
+}
+
+func (f *FakeStateHistoryStore) SaveAlertStateHistory(_ context.Context, entries []*models.AlertStateHistory) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.Entries = append(f.Entries, entries...)
+	return nil
+}
+
+func (f *FakeStateHistoryStore) ListAlertStateHistory(_ context.Context, q *models.ListAlertStateHistoryQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	q.Result = append([]*models.AlertStateHistory(nil), f.Entries...)
+	return nil
+}
+
+func (f *FakeStateHistoryStore) DeleteAlertStateHistoryOlderThan(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
 func newFakeAdminConfigStore(t *testing.T) *fakeAdminConfigStore {
 	t.Helper()
 	return &fakeAdminConfigStore{configs: map[int64]*models.AdminConfiguration{}}
@@ -379,3 +418,20 @@ func (am *FakeExternalAlertmanager) Handler() func(w http.ResponseWriter, r *htt
 func (am *FakeExternalAlertmanager) Close() {
 	am.server.Close()
 }
+
+type fakeImageService struct {
+	url   string
+	err   error
+	calls int
+	// block makes NewImage wait for the context to be done
+	block bool
+}
+
+func (f *fakeImageService) NewImage(ctx context.Context, _ *models.AlertRule) (string, error) {
+	f.calls++
+	if f.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return f.url, f.err
+}
@@ -32,20 +32,27 @@ func newCache(logger log.Logger, metrics *metrics.State, externalURL *url.URL) *
 	}
 }
 
-func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *State {
+func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result, folderTitle string) *State {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
 
 	// clone the labels so we don't change eval.Result
 	labels := result.Instance.Copy()
 	attachRuleLabels(labels, alertRule)
-	ruleLabels, annotations := c.expandRuleLabelsAndAnnotations(alertRule, labels, result)
+	computed := alertRule.ComputedLabelSet(folderTitle)
+	ruleLabels, annotations := c.expandRuleLabelsAndAnnotations(alertRule, labels, result, computed)
 
 	// if duplicate labels exist, alertRule label will take precedence
 	lbs := mergeLabels(ruleLabels, result.Instance)
 	attachRuleLabels(lbs, alertRule)
 
-	il := ngModels.InstanceLabels(lbs)
+	// the computed labels are not part of the identity of the state, so that enabling them
+	// or renaming the folder of the rule does not replace the existing states
+	var computedNames []string
+	for k := range computed {
+		computedNames = append(computedNames, k)
+	}
+	il := ngModels.InstanceLabels(withoutLabels(lbs, computedNames))
 	id, err := il.StringKey()
 	if err != nil {
 		c.log.Error("error getting cacheId for entry", "err", err.Error())
@@ -59,8 +66,10 @@ func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *
 	}
 
 	if state, ok := c.states[alertRule.OrgID][alertRule.UID][id]; ok {
-		// Annotations can change over time for the same alert.
+		// Annotations and computed labels can change over time for the same alert.
 		state.Annotations = annotations
+		state.Labels = lbs
+		state.ComputedLabels = computedNames
 		c.states[alertRule.OrgID][alertRule.UID][id] = state
 		return state
 	}
@@ -74,6 +83,7 @@ func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *
 		Labels:             lbs,
 		Annotations:        annotations,
 		EvaluationDuration: result.EvaluationDuration,
+		ComputedLabels:     computedNames,
 	}
 	if result.State == eval.Alerting {
 		newState.StartsAt = result.EvaluatedAt
@@ -88,7 +98,26 @@ func attachRuleLabels(m map[string]string, alertRule *ngModels.AlertRule) {
 	m[prometheusModel.AlertNameLabel] = alertRule.Title
 }
 
-func (c *cache) expandRuleLabelsAndAnnotations(alertRule *ngModels.AlertRule, labels map[string]string, alertInstance eval.Result) (map[string]string, map[string]string) {
+// withoutLabels returns a copy of the labels without the labels with the given names.
+func withoutLabels(lbs data.Labels, names []string) data.Labels {
+	if len(names) == 0 {
+		return lbs
+	}
+	res := lbs.Copy()
+	for _, name := range names {
+		delete(res, name)
+	}
+	return res
+}
+
+// expandRuleLabelsAndAnnotations expands the templates of the labels and annotations of the rule.
+// The computed labels of the rule are added to the expanded labels, overriding labels with the same
+// name, and can be used in the templates.
+func (c *cache) expandRuleLabelsAndAnnotations(alertRule *ngModels.AlertRule, labels map[string]string, alertInstance eval.Result, computed map[string]string) (map[string]string, map[string]string) {
+	for k, v := range computed {
+		labels[k] = v
+	}
+
 	expand := func(original map[string]string) map[string]string {
 		expanded := make(map[string]string, len(original))
 		for k, v := range original {
@@ -103,7 +132,11 @@ func (c *cache) expandRuleLabelsAndAnnotations(alertRule *ngModels.AlertRule, la
 
 		return expanded
 	}
-	return expand(alertRule.Labels), expand(alertRule.Annotations)
+	ruleLabels := expand(alertRule.Labels)
+	for k, v := range computed {
+		ruleLabels[k] = v
+	}
+	return ruleLabels, expand(alertRule.Annotations)
 }
 
 func (c *cache) set(entry *State) {
@@ -118,6 +151,14 @@ func (c *cache) set(entry *State) {
 	c.states[entry.OrgID][entry.AlertRuleUID][entry.CacheId] = entry
 }
 
+func (c *cache) setImageURL(states []*State, imageURL string) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	for _, s := range states {
+		s.ImageURL = imageURL
+	}
+}
+
 func (c *cache) get(orgID int64, alertRuleUID, stateId string) (*State, error) {
 	c.mtxStates.RLock()
 	defer c.mtxStates.RUnlock()
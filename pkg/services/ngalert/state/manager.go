@@ -3,10 +3,13 @@ package state
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/url"
 	"strconv"
 	"time"
 
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -17,6 +20,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+
+	prometheusModel "github.com/prometheus/common/model"
 )
 
 var ResendDelay = 30 * time.Second
@@ -28,20 +33,27 @@ type Manager struct {
 	cache       *cache
 	quit        chan struct{}
 	ResendDelay time.Duration
+	// Clock is the time the states are checked against to find stale states. Replays of evaluations
+	// at past times set it to the time of the evaluation.
+	Clock clock.Clock
 
 	ruleStore     store.RuleStore
 	instanceStore store.InstanceStore
+	// historyStore records the state transitions, it is nil if the state history is disabled.
+	historyStore store.StateHistoryStore
 }
 
-func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL, ruleStore store.RuleStore, instanceStore store.InstanceStore) *Manager {
+func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL, ruleStore store.RuleStore, instanceStore store.InstanceStore, historyStore store.StateHistoryStore) *Manager {
 	manager := &Manager{
 		cache:         newCache(logger, metrics, externalURL),
 		quit:          make(chan struct{}),
 		ResendDelay:   ResendDelay, // TODO: make this configurable
+		Clock:         clock.New(),
 		log:           logger,
 		metrics:       metrics,
 		ruleStore:     ruleStore,
 		instanceStore: instanceStore,
+		historyStore:  historyStore,
 	}
 	go manager.recordMetrics()
 	return manager
@@ -89,25 +101,7 @@ func (st *Manager) Warm() {
 				st.log.Error("rule not found for instance, ignoring", "rule", entry.RuleUID)
 				continue
 			}
-
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &State{
-				AlertRuleUID:       entry.RuleUID,
-				OrgID:              entry.RuleOrgID,
-				CacheId:            cacheId,
-				Labels:             lbs,
-				State:              translateInstanceState(entry.CurrentState),
-				Results:            []Evaluation{},
-				StartsAt:           entry.CurrentStateSince,
-				EndsAt:             entry.CurrentStateEnd,
-				LastEvaluationTime: entry.LastEvalTime,
-				Annotations:        ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+			states = append(states, st.stateFromInstance(entry, ruleForEntry))
 		}
 	}
 
@@ -116,8 +110,55 @@ func (st *Manager) Warm() {
 	}
 }
 
-func (st *Manager) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *State {
-	return st.cache.getOrCreate(alertRule, result)
+// WarmRule replaces the cached states of the alert rule with the ones persisted in the database.
+// It is used when the evaluation of an alert rule moves to this instance from another one.
+func (st *Manager) WarmRule(orgID int64, ruleUID string) {
+	ruleCmd := ngModels.GetAlertRuleByUIDQuery{
+		OrgID: orgID,
+		UID:   ruleUID,
+	}
+	if err := st.ruleStore.GetAlertRuleByUID(&ruleCmd); err != nil {
+		st.log.Error("unable to fetch alert rule", "uid", ruleUID, "msg", err.Error())
+		return
+	}
+
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: orgID,
+		RuleUID:   ruleUID,
+	}
+	if err := st.instanceStore.ListAlertInstances(&cmd); err != nil {
+		st.log.Error("unable to fetch previous state", "uid", ruleUID, "msg", err.Error())
+		return
+	}
+
+	st.RemoveByRuleUID(orgID, ruleUID)
+	for _, entry := range cmd.Result {
+		st.set(st.stateFromInstance(entry, ruleCmd.Result))
+	}
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.ListAlertInstancesQueryResult, alertRule *ngModels.AlertRule) *State {
+	lbs := map[string]string(entry.Labels)
+	cacheId, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("error getting cacheId for entry", "msg", err.Error())
+	}
+	return &State{
+		AlertRuleUID:       entry.RuleUID,
+		OrgID:              entry.RuleOrgID,
+		CacheId:            cacheId,
+		Labels:             lbs,
+		State:              translateInstanceState(entry.CurrentState),
+		Results:            []Evaluation{},
+		StartsAt:           entry.CurrentStateSince,
+		EndsAt:             entry.CurrentStateEnd,
+		LastEvaluationTime: entry.LastEvalTime,
+		Annotations:        alertRule.Annotations,
+	}
+}
+
+func (st *Manager) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result, folderTitle string) *State {
+	return st.cache.getOrCreate(alertRule, result, folderTitle)
 }
 
 func (st *Manager) set(entry *State) {
@@ -138,23 +179,61 @@ func (st *Manager) RemoveByRuleUID(orgID int64, ruleUID string) {
 	st.cache.removeByRuleUID(orgID, ruleUID)
 }
 
-func (st *Manager) ProcessEvalResults(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results) []*State {
+// MarkPaused marks the states of a paused alert rule as paused. Alerting states are resolved and all
+// states become Normal as the alert rule is no longer evaluated. It returns the states that changed,
+// so that they can be saved and the resolved alerts sent to the Alertmanager.
+func (st *Manager) MarkPaused(orgID int64, ruleUID string, now time.Time) []*State {
+	var states []*State
+	var history []*ngModels.AlertStateHistory
+	for _, s := range st.cache.getStatesForRuleUID(orgID, ruleUID) {
+		if s.Paused {
+			continue
+		}
+		s.Paused = true
+		s.Resolved = s.State == eval.Alerting
+		if previous := s.State; previous != eval.Normal {
+			s.State = eval.Normal
+			s.StartsAt = now
+			s.EndsAt = now
+			history = append(history, newStateHistory(s, previous, nil, now))
+		}
+		s.Error = nil
+		s.LastEvaluationTime = now
+		st.set(s)
+		states = append(states, s)
+	}
+	if len(states) > 0 {
+		st.log.Debug("alert rule states marked as paused", "uid", ruleUID, "count", len(states))
+	}
+	st.saveStateHistory(context.Background(), history)
+	return states
+}
+
+// ProcessEvalResults updates the states of the alert rule from its evaluation results. The folder title
+// is the value of the FolderTitleLabel label of the states, the label is not set if it's empty.
+func (st *Manager) ProcessEvalResults(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results, folderTitle string) []*State {
 	st.log.Debug("state manager processing evaluation results", "uid", alertRule.UID, "resultCount", len(results))
 	var states []*State
+	var history []*ngModels.AlertStateHistory
 	processedResults := make(map[string]*State, len(results))
 	for _, result := range results {
-		s := st.setNextState(ctx, alertRule, result)
+		s, oldState := st.setNextState(ctx, alertRule, result, folderTitle)
 		states = append(states, s)
 		processedResults[s.CacheId] = s
+		if oldState != s.State {
+			history = append(history, newStateHistory(s, oldState, &result, result.EvaluatedAt))
+		}
 	}
-	st.staleResultsHandler(alertRule, processedResults)
+	history = append(history, st.staleResultsHandler(alertRule, processedResults)...)
+	st.saveStateHistory(ctx, history)
 	return states
 }
 
-// Set the current state based on evaluation results
-func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
-	currentState := st.getOrCreate(alertRule, result)
+// Set the current state based on evaluation results, the previous state is returned along with the current state.
+func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, folderTitle string) (*State, eval.State) {
+	currentState := st.getOrCreate(alertRule, result, folderTitle)
 
+	currentState.Paused = false
 	currentState.LastEvaluationTime = result.EvaluatedAt
 	currentState.EvaluationDuration = result.EvaluationDuration
 	currentState.Results = append(currentState.Results, Evaluation{
@@ -187,7 +266,7 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	if oldState != currentState.State {
 		go st.createAlertAnnotation(ctx, currentState.State, alertRule, result, oldState)
 	}
-	return currentState
+	return currentState, oldState
 }
 
 func (st *Manager) GetAll(orgID int64) []*State {
@@ -198,6 +277,98 @@ func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*Stat
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID)
 }
 
+// GetRuleReferenceValues returns the latest values of the alert instances of the referenced alert rule,
+// so that alert rules can use them as the input of their expressions.
+// The labels the state manager attaches to the alert instances are removed from the values.
+func (st *Manager) GetRuleReferenceValues(orgID int64, ref ngModels.RuleReference) []eval.NumberValueCapture {
+	states := st.cache.getStatesForRuleUID(orgID, ref.RuleUID)
+	values := make([]eval.NumberValueCapture, 0, len(states))
+	seen := make(map[string]struct{}, len(states))
+	for _, s := range states {
+		var v eval.NumberValueCapture
+		if ref.Expression == "" {
+			value := 0.0
+			if s.State == eval.Alerting {
+				value = 1
+			}
+			labels := s.Labels.Copy()
+			removeRuleLabels(labels, s)
+			v = eval.NumberValueCapture{Var: ref.RuleUID, Labels: labels, Value: &value}
+		} else {
+			if len(s.Results) == 0 {
+				continue
+			}
+			ev, ok := s.Results[len(s.Results)-1].Values[ref.Expression]
+			if !ok {
+				continue
+			}
+			v = eval.NumberValueCapture{Var: ref.Expression, Labels: ev.Labels, Value: ev.Value}
+		}
+
+		// alert instances of the same alert rule can share the values of an expression
+		key := v.Labels.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		values = append(values, v)
+	}
+	return values
+}
+
+// GetLoadedDimensions returns the labels of the firing and pending alert instances of the alert rule,
+// so that threshold expressions can apply their recovery threshold to them. The labels of the alert rule
+// and the labels the state manager attaches to the alert instances are removed.
+func (st *Manager) GetLoadedDimensions(alertRule *ngModels.AlertRule) []data.Labels {
+	states := st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID)
+	dims := make([]data.Labels, 0, len(states))
+	for _, s := range states {
+		if s.State != eval.Alerting && s.State != eval.Pending {
+			continue
+		}
+		labels := s.Labels.Copy()
+		removeRuleLabels(labels, s)
+		for k := range alertRule.Labels {
+			delete(labels, k)
+		}
+		dims = append(dims, labels)
+	}
+	return dims
+}
+
+// WithLoadedDimensions returns the queries of the alert rule where the threshold expressions with a recovery
+// threshold know which alert instances are firing or pending, so that they keep firing until they recover.
+// The queries of the alert rule are not modified.
+func (st *Manager) WithLoadedDimensions(alertRule *ngModels.AlertRule) ([]ngModels.AlertQuery, error) {
+	var queries []ngModels.AlertQuery
+	for i, q := range alertRule.Data {
+		if !q.HasRecoveryThreshold() {
+			continue
+		}
+		if queries == nil {
+			queries = make([]ngModels.AlertQuery, len(alertRule.Data))
+			copy(queries, alertRule.Data)
+		}
+		if err := queries[i].SetLoadedDimensions(st.GetLoadedDimensions(alertRule)); err != nil {
+			return nil, fmt.Errorf("query %s: %w", q.RefID, err)
+		}
+	}
+	if queries == nil {
+		return alertRule.Data, nil
+	}
+	return queries, nil
+}
+
+// removeRuleLabels removes the labels the state manager attaches to the alert instance of the state.
+func removeRuleLabels(labels data.Labels, s *State) {
+	delete(labels, ngModels.RuleUIDLabel)
+	delete(labels, ngModels.NamespaceUIDLabel)
+	delete(labels, prometheusModel.AlertNameLabel)
+	for _, name := range s.ComputedLabels {
+		delete(labels, name)
+	}
+}
+
 func (st *Manager) recordMetrics() {
 	// TODO: parameterize?
 	// Setting to a reasonable default scrape interval for Prometheus.
@@ -216,12 +387,62 @@ func (st *Manager) recordMetrics() {
 	}
 }
 
+// SetImageURL sets the URL of the image of the panel of their alert rule on the states.
+func (st *Manager) SetImageURL(states []*State, imageURL string) {
+	st.cache.setImageURL(states, imageURL)
+}
+
 func (st *Manager) Put(states []*State) {
 	for _, s := range states {
 		st.set(s)
 	}
 }
 
+// saveStateHistory records the state transitions if the state history is enabled.
+func (st *Manager) saveStateHistory(ctx context.Context, history []*ngModels.AlertStateHistory) {
+	if st.historyStore == nil || len(history) == 0 {
+		return
+	}
+	if err := st.historyStore.SaveAlertStateHistory(ctx, history); err != nil {
+		st.log.Error("failed to save alert state history", "count", len(history), "error", err)
+	}
+}
+
+// newStateHistory returns the transition of the state from the previous state. The result is the
+// evaluation that caused the transition, it is nil if the transition was not caused by an evaluation.
+func newStateHistory(s *State, previous eval.State, result *eval.Result, at time.Time) *ngModels.AlertStateHistory {
+	entry := &ngModels.AlertStateHistory{
+		RuleOrgID:     s.OrgID,
+		RuleUID:       s.AlertRuleUID,
+		Labels:        s.Labels,
+		PreviousState: previous.String(),
+		State:         s.State.String(),
+		Created:       at,
+	}
+	labels := ngModels.InstanceLabels(s.InstanceLabels())
+	if _, hash, err := labels.StringAndHash(); err == nil {
+		entry.LabelsHash = hash
+	}
+	if result == nil {
+		return entry
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+	}
+	if len(result.Values) > 0 {
+		entry.EvaluationValues = make(map[string]*float64, len(result.Values))
+		for refID, v := range result.Values {
+			// NaN and infinite values cannot be encoded as JSON
+			if v.Value != nil && !math.IsNaN(*v.Value) && !math.IsInf(*v.Value, 0) {
+				entry.EvaluationValues[refID] = v.Value
+			} else {
+				entry.EvaluationValues[refID] = nil
+			}
+		}
+	}
+	return entry
+}
+
 func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 	switch {
 	case state == ngModels.InstanceStateFiring:
@@ -278,14 +499,22 @@ func (st *Manager) createAlertAnnotation(ctx context.Context, new eval.State, al
 	}
 }
 
-func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map[string]*State) {
+// staleResultsHandler removes the states of the alert rule that were not evaluated recently, and returns
+// the transitions to Normal of the removed states that were not Normal.
+func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map[string]*State) []*ngModels.AlertStateHistory {
+	var history []*ngModels.AlertStateHistory
 	allStates := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
 	for _, s := range allStates {
 		_, ok := states[s.CacheId]
-		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds) {
+		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds, st.Clock.Now()) {
 			st.log.Debug("removing stale state entry", "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
 			st.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
-			ilbs := ngModels.InstanceLabels(s.Labels)
+			if s.State != eval.Normal {
+				resolved := *s
+				resolved.State = eval.Normal
+				history = append(history, newStateHistory(&resolved, s.State, nil, st.Clock.Now()))
+			}
+			ilbs := ngModels.InstanceLabels(s.InstanceLabels())
 			_, labelsHash, err := ilbs.StringAndHash()
 			if err != nil {
 				st.log.Error("unable to get labelsHash", "error", err.Error(), "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID)
@@ -296,8 +525,9 @@ func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map
 			}
 		}
 	}
+	return history
 }
 
-func isItStale(lastEval time.Time, intervalSeconds int64) bool {
-	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(time.Now())
+func isItStale(lastEval time.Time, intervalSeconds int64, now time.Time) bool {
+	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(now)
 }
@@ -2,11 +2,14 @@ package state_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -1228,10 +1231,10 @@ func TestProcessEvalResults(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, &schedule.FakeInstanceStore{})
+		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, &schedule.FakeInstanceStore{}, nil)
 		t.Run(tc.desc, func(t *testing.T) {
 			for _, res := range tc.evalResults {
-				_ = st.ProcessEvalResults(context.Background(), tc.alertRule, res)
+				_ = st.ProcessEvalResults(context.Background(), tc.alertRule, res, "")
 			}
 
 			states := st.GetStatesForRuleUID(tc.alertRule.OrgID, tc.alertRule.UID)
@@ -1328,14 +1331,14 @@ func TestStaleResultsHandler(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore)
+		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, nil)
 		st.Warm()
 		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
 
 		// We have loaded the expected number of entries from the db
 		assert.Equal(t, tc.startingStateCount, len(existingStatesForRule))
 		for _, res := range tc.evalResults {
-			st.ProcessEvalResults(context.Background(), rule, res)
+			st.ProcessEvalResults(context.Background(), rule, res, "")
 			for _, s := range tc.expectedStates {
 				cachedState, err := st.Get(s.OrgID, s.AlertRuleUID, s.CacheId)
 				require.NoError(t, err)
@@ -1348,3 +1351,354 @@ func TestStaleResultsHandler(t *testing.T) {
 		assert.Equal(t, tc.finalStateCount, len(existingStatesForRule))
 	}
 }
+
+func TestMarkPaused(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+	pausedAt := evaluationTime.Add(time.Minute)
+
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 10,
+	}
+
+	st := state.NewManager(log.New("test_mark_paused"), testMetrics.GetStateMetrics(), nil, nil, &schedule.FakeInstanceStore{}, nil)
+	st.ProcessEvalResults(context.Background(), rule, eval.Results{
+		eval.Result{
+			Instance:    data.Labels{"instance": "alerting"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime,
+		},
+		eval.Result{
+			Instance:    data.Labels{"instance": "normal"},
+			State:       eval.Normal,
+			EvaluatedAt: evaluationTime,
+		},
+	}, "")
+
+	states := st.MarkPaused(rule.OrgID, rule.UID, pausedAt)
+	require.Len(t, states, 2)
+	for _, s := range states {
+		require.True(t, s.Paused)
+		require.Equal(t, eval.Normal, s.State)
+		require.Equal(t, pausedAt, s.LastEvaluationTime)
+		require.Equal(t, s.Labels["instance"] == "alerting", s.Resolved)
+	}
+
+	t.Run("it should not mark the states again", func(t *testing.T) {
+		require.Empty(t, st.MarkPaused(rule.OrgID, rule.UID, pausedAt.Add(time.Minute)))
+	})
+
+	t.Run("it should clear the mark when the alert rule is evaluated again", func(t *testing.T) {
+		states := st.ProcessEvalResults(context.Background(), rule, eval.Results{
+			eval.Result{
+				Instance:    data.Labels{"instance": "alerting"},
+				State:       eval.Alerting,
+				EvaluatedAt: pausedAt.Add(time.Minute),
+			},
+		}, "")
+		require.Len(t, states, 1)
+		require.False(t, states[0].Paused)
+		require.Equal(t, eval.Alerting, states[0].State)
+	})
+}
+
+func TestGetRuleReferenceValues(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 10,
+	}
+
+	value := func(v float64) *float64 {
+		return &v
+	}
+
+	st := state.NewManager(log.New("test_rule_references"), testMetrics.GetStateMetrics(), nil, nil, &schedule.FakeInstanceStore{}, nil)
+	st.ProcessEvalResults(context.Background(), rule, eval.Results{
+		eval.Result{
+			Instance:    data.Labels{"instance": "alerting"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime,
+			Values: map[string]eval.NumberValueCapture{
+				"B": {Var: "B", Labels: data.Labels{"instance": "alerting"}, Value: value(42)},
+			},
+		},
+		eval.Result{
+			Instance:    data.Labels{"instance": "normal"},
+			State:       eval.Normal,
+			EvaluatedAt: evaluationTime,
+			Values: map[string]eval.NumberValueCapture{
+				"B": {Var: "B", Labels: data.Labels{"instance": "normal"}, Value: value(1)},
+			},
+		},
+	}, "")
+
+	byInstance := func(values []eval.NumberValueCapture) map[string]float64 {
+		result := make(map[string]float64, len(values))
+		for _, v := range values {
+			require.NotNil(t, v.Value)
+			result[v.Labels["instance"]] = *v.Value
+		}
+		return result
+	}
+
+	t.Run("it should return the state of the alert instances", func(t *testing.T) {
+		values := st.GetRuleReferenceValues(rule.OrgID, models.RuleReference{RuleUID: rule.UID})
+		require.Len(t, values, 2)
+		for _, v := range values {
+			require.Equal(t, data.Labels{"instance": v.Labels["instance"]}, v.Labels)
+		}
+		require.Equal(t, map[string]float64{"alerting": 1, "normal": 0}, byInstance(values))
+	})
+
+	t.Run("it should return the last values of the expression", func(t *testing.T) {
+		values := st.GetRuleReferenceValues(rule.OrgID, models.RuleReference{RuleUID: rule.UID, Expression: "B"})
+		require.Equal(t, map[string]float64{"alerting": 42, "normal": 1}, byInstance(values))
+	})
+
+	t.Run("it should return nothing for unknown alert rules and expressions", func(t *testing.T) {
+		require.Empty(t, st.GetRuleReferenceValues(rule.OrgID, models.RuleReference{RuleUID: "unknown"}))
+		require.Empty(t, st.GetRuleReferenceValues(rule.OrgID, models.RuleReference{RuleUID: rule.UID, Expression: "C"}))
+	})
+}
+
+func TestStateHistory(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 10,
+		ExecErrState:    models.AlertingErrState,
+	}
+
+	value := 42.0
+	historyStore := &schedule.FakeStateHistoryStore{}
+	st := state.NewManager(log.New("test_state_history"), testMetrics.GetStateMetrics(), nil, nil, &schedule.FakeInstanceStore{}, historyStore)
+
+	st.ProcessEvalResults(context.Background(), rule, eval.Results{
+		eval.Result{
+			Instance:    data.Labels{"instance": "a"},
+			State:       eval.Normal,
+			EvaluatedAt: evaluationTime,
+		},
+	}, "")
+	require.Empty(t, historyStore.Entries, "the state did not change")
+
+	st.ProcessEvalResults(context.Background(), rule, eval.Results{
+		eval.Result{
+			Instance:    data.Labels{"instance": "a"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime.Add(10 * time.Second),
+			Values:      map[string]eval.NumberValueCapture{"B": {Var: "B", Value: &value}},
+		},
+	}, "")
+	require.Len(t, historyStore.Entries, 1)
+	entry := historyStore.Entries[0]
+	require.Equal(t, rule.OrgID, entry.RuleOrgID)
+	require.Equal(t, rule.UID, entry.RuleUID)
+	require.Equal(t, map[string]string{"__alert_rule_uid__": rule.UID, "__alert_rule_namespace_uid__": rule.NamespaceUID, "alertname": rule.Title, "instance": "a"}, entry.Labels)
+	require.NotEmpty(t, entry.LabelsHash)
+	require.Equal(t, "Normal", entry.PreviousState)
+	require.Equal(t, "Alerting", entry.State)
+	require.Equal(t, value, *entry.EvaluationValues["B"])
+	require.Equal(t, evaluationTime.Add(10*time.Second), entry.Created)
+
+	t.Run("it should record the error of the evaluation", func(t *testing.T) {
+		st.ProcessEvalResults(context.Background(), rule, eval.Results{
+			eval.Result{
+				Instance:    data.Labels{"instance": "b"},
+				State:       eval.Error,
+				Error:       errors.New("query failed"),
+				EvaluatedAt: evaluationTime.Add(20 * time.Second),
+			},
+		}, "")
+		require.Len(t, historyStore.Entries, 3)
+		require.Equal(t, "b", historyStore.Entries[1].Labels["instance"])
+		require.Equal(t, "Normal", historyStore.Entries[1].PreviousState)
+		require.Equal(t, "Alerting", historyStore.Entries[1].State)
+		require.Equal(t, "query failed", historyStore.Entries[1].Error)
+	})
+
+	t.Run("it should record the removal of stale states", func(t *testing.T) {
+		// the state of the first instance is stale and was removed when the second instance was evaluated
+		require.Equal(t, "a", historyStore.Entries[2].Labels["instance"])
+		require.Equal(t, "Alerting", historyStore.Entries[2].PreviousState)
+		require.Equal(t, "Normal", historyStore.Entries[2].State)
+		require.Empty(t, historyStore.Entries[2].Error)
+	})
+
+	t.Run("it should record the transitions of paused rules", func(t *testing.T) {
+		st.MarkPaused(rule.OrgID, rule.UID, evaluationTime.Add(30*time.Second))
+		require.Len(t, historyStore.Entries, 4)
+		require.Equal(t, "b", historyStore.Entries[3].Labels["instance"])
+		require.Equal(t, "Alerting", historyStore.Entries[3].PreviousState)
+		require.Equal(t, "Normal", historyStore.Entries[3].State)
+	})
+}
+
+func TestGetLoadedDimensions(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		Labels:          map[string]string{"severity": "critical"},
+		IntervalSeconds: 10,
+		For:             time.Minute,
+	}
+
+	st := state.NewManager(log.New("test_loaded_dimensions"), testMetrics.GetStateMetrics(), nil, nil, &schedule.FakeInstanceStore{}, nil)
+	require.Empty(t, st.GetLoadedDimensions(rule))
+
+	st.ProcessEvalResults(context.Background(), rule, eval.Results{
+		eval.Result{Instance: data.Labels{"instance": "pending"}, State: eval.Alerting, EvaluatedAt: evaluationTime},
+		eval.Result{Instance: data.Labels{"instance": "normal"}, State: eval.Normal, EvaluatedAt: evaluationTime},
+	}, "")
+	require.Equal(t, []data.Labels{{"instance": "pending"}}, st.GetLoadedDimensions(rule))
+
+	t.Run("it should only set the loaded dimensions of threshold expressions with a recovery threshold", func(t *testing.T) {
+		queries, err := st.WithLoadedDimensions(rule)
+		require.NoError(t, err)
+		require.Equal(t, rule.Data, queries)
+
+		rule := *rule
+		rule.Data = []models.AlertQuery{
+			{RefID: "A", DatasourceUID: "000000001", Model: json.RawMessage(`{}`)},
+			{RefID: "B", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{
+				"type": "threshold",
+				"expression": "A",
+				"conditions": [{"evaluator": {"type": "gt", "params": [80]}, "unloadEvaluator": {"type": "lt", "params": [70]}}]
+			}`)},
+		}
+		queries, err = st.WithLoadedDimensions(&rule)
+		require.NoError(t, err)
+		require.Len(t, queries, 2)
+		require.Equal(t, rule.Data[0], queries[0])
+
+		model, err := queries[1].GetModel()
+		require.NoError(t, err)
+		require.Contains(t, string(model), `"loadedDimensions":[{"instance":"pending"}]`)
+		model, err = rule.Data[1].GetModel()
+		require.NoError(t, err)
+		require.NotContains(t, string(model), "loadedDimensions")
+	})
+}
+
+func TestProcessEvalResultsComputedLabels(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	dashboardUID := "dashboard"
+	panelID := int64(3)
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 10,
+		DashboardUID:    &dashboardUID,
+		PanelID:         &panelID,
+		ComputedLabels:  true,
+		Labels: map[string]string{
+			"team":           "{{ $labels.grafana_folder }}",
+			"grafana_folder": "overridden",
+			"severity":       `{{ if gt $values.B.Value 10.0 }}critical{{ else }}warning{{ end }}`,
+		},
+	}
+	value := func(v float64) *float64 {
+		return &v
+	}
+
+	st := state.NewManager(log.New("test_computed_labels"), testMetrics.GetStateMetrics(), nil, nil, &schedule.FakeInstanceStore{}, nil)
+	states := st.ProcessEvalResults(context.Background(), rule, eval.Results{
+		eval.Result{
+			Instance:    data.Labels{"instance": "a", models.FolderTitleLabel: "instance"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime,
+			Values: map[string]eval.NumberValueCapture{
+				"B": {Var: "B", Labels: data.Labels{"instance": "a"}, Value: value(42)},
+			},
+		},
+	}, "Team A")
+	require.Len(t, states, 1)
+
+	require.Equal(t, data.Labels{
+		"__alert_rule_uid__":           "test_alert_rule_uid",
+		"__alert_rule_namespace_uid__": "test_namespace_uid",
+		"alertname":                    "test_title",
+		"instance":                     "a",
+		"team":                         "Team A",
+		"severity":                     "critical",
+		models.FolderTitleLabel:        "Team A",
+		models.DashboardUIDLabel:       "dashboard",
+		models.PanelIDLabel:            "3",
+	}, states[0].Labels)
+
+	t.Run("computed labels are not the labels of the instance", func(t *testing.T) {
+		values := st.GetRuleReferenceValues(rule.OrgID, models.RuleReference{RuleUID: rule.UID})
+		require.Len(t, values, 1)
+		for _, name := range models.ComputedLabelNames {
+			require.NotContains(t, values[0].Labels, name)
+		}
+	})
+
+	t.Run("computed labels do not identify the state", func(t *testing.T) {
+		rule := &models.AlertRule{OrgID: 1, Title: "renamed", UID: "renamed", NamespaceUID: "test_namespace_uid", IntervalSeconds: 10, ComputedLabels: true}
+		result := eval.Result{Instance: data.Labels{"instance": "a"}, State: eval.Alerting, EvaluatedAt: evaluationTime}
+		states := st.ProcessEvalResults(context.Background(), rule, eval.Results{result}, "Team A")
+		require.Len(t, states, 1)
+		cacheID := states[0].CacheId
+		require.Equal(t, data.Labels{
+			"__alert_rule_uid__":           "renamed",
+			"__alert_rule_namespace_uid__": "test_namespace_uid",
+			"alertname":                    "renamed",
+			"instance":                     "a",
+		}, states[0].InstanceLabels())
+
+		result.EvaluatedAt = evaluationTime.Add(10 * time.Second)
+		states = st.ProcessEvalResults(context.Background(), rule, eval.Results{result}, "Team B")
+		require.Len(t, states, 1)
+		require.Equal(t, cacheID, states[0].CacheId)
+		require.Equal(t, "Team B", states[0].Labels[models.FolderTitleLabel])
+		require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), 1)
+	})
+
+	t.Run("computed labels are only attached if the rule enables them", func(t *testing.T) {
+		rule := &models.AlertRule{OrgID: 1, Title: "disabled", UID: "disabled", NamespaceUID: "test_namespace_uid", IntervalSeconds: 10, DashboardUID: &dashboardUID, PanelID: &panelID}
+		states := st.ProcessEvalResults(context.Background(), rule, eval.Results{
+			eval.Result{Instance: data.Labels{"instance": "a", models.FolderTitleLabel: "instance"}, State: eval.Normal, EvaluatedAt: evaluationTime},
+		}, "Team A")
+		require.Len(t, states, 1)
+		require.Equal(t, "instance", states[0].Labels[models.FolderTitleLabel])
+		require.NotContains(t, states[0].Labels, models.DashboardUIDLabel)
+		require.NotContains(t, states[0].Labels, models.PanelIDLabel)
+		require.Equal(t, states[0].Labels, states[0].InstanceLabels())
+	})
+
+	t.Run("labels are omitted if there is no folder or panel", func(t *testing.T) {
+		rule := &models.AlertRule{OrgID: 1, Title: "other", UID: "other", NamespaceUID: "test_namespace_uid", IntervalSeconds: 10, ComputedLabels: true}
+		states := st.ProcessEvalResults(context.Background(), rule, eval.Results{
+			eval.Result{Instance: data.Labels{"instance": "a"}, State: eval.Normal, EvaluatedAt: evaluationTime},
+		}, "")
+		require.Len(t, states, 1)
+		for _, name := range models.ComputedLabelNames {
+			require.NotContains(t, states[0].Labels, name)
+		}
+	})
+}
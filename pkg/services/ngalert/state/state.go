@@ -10,11 +10,13 @@ import (
 )
 
 type State struct {
-	AlertRuleUID       string
-	OrgID              int64
-	CacheId            string
-	State              eval.State
-	Resolved           bool
+	AlertRuleUID string
+	OrgID        int64
+	CacheId      string
+	State        eval.State
+	Resolved     bool
+	// Paused is true if the alert rule of the state is paused.
+	Paused             bool
 	Results            []Evaluation
 	StartsAt           time.Time
 	EndsAt             time.Time
@@ -24,6 +26,17 @@ type State struct {
 	Annotations        map[string]string
 	Labels             data.Labels
 	Error              error
+	// ImageURL is the URL of the image of the panel of the alert rule captured when the alert instance started firing.
+	ImageURL string
+	// ComputedLabels are the names of the computed labels of the alert rule in Labels, they do not
+	// identify the state.
+	ComputedLabels []string
+}
+
+// InstanceLabels returns the labels that identify the alert instance of the state, i.e. its labels
+// without the computed labels of the alert rule, which can change for the same alert instance.
+func (a *State) InstanceLabels() data.Labels {
+	return withoutLabels(a.Labels, a.ComputedLabels)
 }
 
 type Evaluation struct {
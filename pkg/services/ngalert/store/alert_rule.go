@@ -18,6 +18,7 @@ import (
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/prometheus/common/model"
 )
 
 // AlertRuleMaxTitleLength is the maximum length of the alert rule title
@@ -30,11 +31,25 @@ type UpdateRuleGroupCmd struct {
 	OrgID           int64
 	NamespaceUID    string
 	RuleGroupConfig apimodels.PostableRuleGroupConfig
+	// UserID is the ID of the user updating the rule group.
+	UserID int64
+	// Provenance is the provenance of the rules of the group, if they are provisioned.
+	Provenance ngmodels.Provenance
+	// MovedRuleUIDs are the UIDs of the rules of other groups that are moved to the group. Rules keep their
+	// group when they are updated, so they are deleted in the same transaction before they are recreated.
+	MovedRuleUIDs []string
 }
 
 type UpsertRule struct {
 	Existing *ngmodels.AlertRule
 	New      ngmodels.AlertRule
+	// UserID is the ID of the user creating the new version of the rule.
+	UserID int64
+	// RestoredFrom is the version the new version of the rule is restored from, if any.
+	RestoredFrom int64
+	// Provenance is the provenance of the rule, if it is provisioned. Provisioned rules are created with
+	// the UID they are provisioned with.
+	Provenance ngmodels.Provenance
 }
 
 // Store is the interface for persisting alert rules and instances
@@ -50,9 +65,11 @@ type RuleStore interface {
 	GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRulesQuery) error
 	GetNamespaces(context.Context, int64, *models.SignedInUser) (map[string]*models.Folder, error)
 	GetNamespaceByTitle(context.Context, string, int64, *models.SignedInUser, bool) (*models.Folder, error)
+	GetNamespaceByUID(context.Context, string, int64, *models.SignedInUser) (*models.Folder, error)
 	GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error
 	UpsertAlertRules([]UpsertRule) error
 	UpdateRuleGroup(UpdateRuleGroupCmd) error
+	UpdateRuleGroups([]UpdateRuleGroupCmd) error
 }
 
 func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int64) (*ngmodels.AlertRule, error) {
@@ -71,23 +88,27 @@ func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int6
 // DeleteAlertRuleByUID is a handler for deleting an alert rule.
 func (st DBstore) DeleteAlertRuleByUID(orgID int64, ruleUID string) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
-		}
+		return deleteAlertRuleByUID(sess, orgID, ruleUID)
+	})
+}
 
-		_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
+func deleteAlertRuleByUID(sess *sqlstore.DBSession, orgID int64, ruleUID string) error {
+	_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return err
-		}
+	_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
 
-		_, err = sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+	if err != nil {
+		return err
+	}
+
+	_, err = sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
+	return setProvenance(sess, orgID, ngmodels.ResourceTypeAlertRule, ruleUID, ngmodels.ProvenanceNone)
 }
 
 // DeleteNamespaceAlertRules is a handler for deleting namespace alert rules. A list of deleted rule UIDs are returned.
@@ -187,134 +208,161 @@ func (st DBstore) GetAlertRuleByUID(query *ngmodels.GetAlertRuleByUIDQuery) erro
 // UpsertAlertRules is a handler for creating/updating alert rules.
 func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		newRules := make([]ngmodels.AlertRule, 0, len(rules))
-		ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
-		for _, r := range rules {
-			if r.Existing == nil && r.New.UID != "" {
-				// check by UID
-				existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
-				if err != nil {
-					if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-						return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
-					}
-					return err
-				}
+		return st.upsertAlertRules(sess, rules)
+	})
+}
+
+func (st DBstore) upsertAlertRules(sess *sqlstore.DBSession, rules []UpsertRule) error {
+	newRules := make([]ngmodels.AlertRule, 0, len(rules))
+	ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
+	for _, r := range rules {
+		if r.Existing == nil && r.New.UID != "" {
+			// check by UID
+			existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
+			switch {
+			case err == nil:
 				r.Existing = existingAlertRule
+			case !errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+				return err
+			case r.Provenance == ngmodels.ProvenanceNone:
+				return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
 			}
+		}
 
-			var parentVersion int64
-			switch r.Existing {
-			case nil: // new rule
+		var parentVersion int64
+		switch r.Existing {
+		case nil: // new rule
+			if r.New.UID == "" {
 				uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
 				if err != nil {
 					return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
 				}
 				r.New.UID = uid
+			}
 
-				if r.New.IntervalSeconds == 0 {
-					r.New.IntervalSeconds = int64(st.DefaultInterval.Seconds())
-				}
+			if r.New.IntervalSeconds == 0 {
+				r.New.IntervalSeconds = int64(st.DefaultInterval.Seconds())
+			}
 
-				r.New.Version = 1
+			if r.New.RuleGroupIndex == 0 {
+				r.New.RuleGroupIndex = 1
+			}
 
-				if r.New.NoDataState == "" {
-					// set default no data state
-					r.New.NoDataState = ngmodels.NoData
-				}
+			r.New.Version = 1
 
-				if r.New.ExecErrState == "" {
-					// set default error state
-					r.New.ExecErrState = ngmodels.AlertingErrState
-				}
+			if r.New.NoDataState == "" {
+				// set default no data state
+				r.New.NoDataState = ngmodels.NoData
+			}
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+			if r.New.ExecErrState == "" {
+				// set default error state
+				r.New.ExecErrState = ngmodels.AlertingErrState
+			}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+			if err := st.validateAlertRule(r.New); err != nil {
+				return err
+			}
 
-				newRules = append(newRules, r.New)
-			default:
-				// explicitly set the existing properties if missing
-				// do not rely on xorm
-				if r.New.Title == "" {
-					r.New.Title = r.Existing.Title
-				}
+			if err := (&r.New).PreSave(TimeNow); err != nil {
+				return err
+			}
 
-				if r.New.Condition == "" {
-					r.New.Condition = r.Existing.Condition
-				}
+			newRules = append(newRules, r.New)
+		default:
+			// explicitly set the existing properties if missing
+			// do not rely on xorm
+			if r.New.Title == "" {
+				r.New.Title = r.Existing.Title
+			}
 
-				if len(r.New.Data) == 0 {
-					r.New.Data = r.Existing.Data
-				}
+			if r.New.Condition == "" {
+				r.New.Condition = r.Existing.Condition
+			}
 
-				r.New.ID = r.Existing.ID
-				r.New.OrgID = r.Existing.OrgID
-				r.New.NamespaceUID = r.Existing.NamespaceUID
-				r.New.RuleGroup = r.Existing.RuleGroup
-				r.New.Version = r.Existing.Version + 1
+			if len(r.New.Data) == 0 {
+				r.New.Data = r.Existing.Data
+			}
 
-				if r.New.ExecErrState == "" {
-					r.New.ExecErrState = r.Existing.ExecErrState
-				}
+			r.New.ID = r.Existing.ID
+			r.New.OrgID = r.Existing.OrgID
+			r.New.NamespaceUID = r.Existing.NamespaceUID
+			r.New.RuleGroup = r.Existing.RuleGroup
+			r.New.Version = r.Existing.Version + 1
 
-				if r.New.NoDataState == "" {
-					r.New.NoDataState = r.Existing.NoDataState
-				}
+			if r.New.RuleGroupIndex == 0 {
+				r.New.RuleGroupIndex = r.Existing.RuleGroupIndex
+			}
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+			if r.New.ExecErrState == "" {
+				r.New.ExecErrState = r.Existing.ExecErrState
+			}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+			if r.New.NoDataState == "" {
+				r.New.NoDataState = r.Existing.NoDataState
+			}
 
-				// no way to update multiple rules at once
-				if _, err := sess.ID(r.Existing.ID).AllCols().Update(r.New); err != nil {
-					return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
-				}
+			if err := st.validateAlertRule(r.New); err != nil {
+				return err
+			}
 
-				parentVersion = r.Existing.Version
+			if err := (&r.New).PreSave(TimeNow); err != nil {
+				return err
 			}
 
-			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
-			})
-		}
-
-		if len(newRules) > 0 {
-			if _, err := sess.Insert(&newRules); err != nil {
-				return fmt.Errorf("failed to create new rules: %w", err)
+			// no way to update multiple rules at once
+			if _, err := sess.ID(r.Existing.ID).AllCols().Update(r.New); err != nil {
+				return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
 			}
-		}
 
-		if len(ruleVersions) > 0 {
-			if _, err := sess.Insert(&ruleVersions); err != nil {
-				return fmt.Errorf("failed to create new rule versions: %w", err)
+			parentVersion = r.Existing.Version
+		}
+
+		ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
+			RuleOrgID:        r.New.OrgID,
+			RuleUID:          r.New.UID,
+			RuleNamespaceUID: r.New.NamespaceUID,
+			RuleGroup:        r.New.RuleGroup,
+			RuleGroupIndex:   r.New.RuleGroupIndex,
+			ParentVersion:    parentVersion,
+			RestoredFrom:     r.RestoredFrom,
+			Version:          r.New.Version,
+			Created:          r.New.Updated,
+			Condition:        r.New.Condition,
+			Title:            r.New.Title,
+			Data:             r.New.Data,
+			IntervalSeconds:  r.New.IntervalSeconds,
+			NoDataState:      r.New.NoDataState,
+			ExecErrState:     r.New.ExecErrState,
+			For:              r.New.For,
+			Annotations:      r.New.Annotations,
+			Labels:           r.New.Labels,
+			Record:           r.New.Record,
+			IsPaused:         r.New.IsPaused,
+			ComputedLabels:   r.New.ComputedLabels,
+			CreatedBy:        r.UserID,
+		})
+
+		if r.Provenance != ngmodels.ProvenanceNone {
+			if err := setProvenance(sess, r.New.OrgID, ngmodels.ResourceTypeAlertRule, r.New.UID, r.Provenance); err != nil {
+				return err
 			}
 		}
+	}
 
-		return nil
-	})
+	if len(newRules) > 0 {
+		if _, err := sess.Insert(&newRules); err != nil {
+			return fmt.Errorf("failed to create new rules: %w", err)
+		}
+	}
+
+	if len(ruleVersions) > 0 {
+		if _, err := sess.Insert(&ruleVersions); err != nil {
+			return fmt.Errorf("failed to create new rule versions: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // GetOrgAlertRules is a handler for retrieving alert rules of specific organisation.
@@ -342,7 +390,7 @@ func (st DBstore) GetOrgAlertRules(query *ngmodels.ListAlertRulesQuery) error {
 			}
 		}
 
-		q = fmt.Sprintf("%s ORDER BY id ASC", q)
+		q = fmt.Sprintf("%s ORDER BY rule_group_idx ASC, id ASC", q)
 
 		if err := sess.SQL(q, params...).Find(&alertRules); err != nil {
 			return err
@@ -358,7 +406,7 @@ func (st DBstore) GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRules
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alertRules := make([]*ngmodels.AlertRule, 0)
 		// TODO rewrite using group by namespace_uid, rule_group
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ?"
+		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? ORDER BY rule_group_idx ASC, id ASC"
 		if err := sess.SQL(q, query.OrgID, query.NamespaceUID).Find(&alertRules); err != nil {
 			return err
 		}
@@ -371,26 +419,32 @@ func (st DBstore) GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRules
 // GetRuleGroupAlertRules is a handler for retrieving rule group alert rules of specific organisation.
 func (st DBstore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ?"
-		args := []interface{}{query.OrgID, query.NamespaceUID, query.RuleGroup}
+		return getRuleGroupAlertRules(sess, query)
+	})
+}
 
-		if query.DashboardUID != "" {
-			q = fmt.Sprintf("%s and dashboard_uid = ?", q)
-			args = append(args, query.DashboardUID)
-			if query.PanelID != 0 {
-				q = fmt.Sprintf("%s and panel_id = ?", q)
-				args = append(args, query.PanelID)
-			}
-		}
+func getRuleGroupAlertRules(sess *sqlstore.DBSession, query *ngmodels.ListRuleGroupAlertRulesQuery) error {
+	q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ?"
+	args := []interface{}{query.OrgID, query.NamespaceUID, query.RuleGroup}
 
-		alertRules := make([]*ngmodels.AlertRule, 0)
-		if err := sess.SQL(q, args...).Find(&alertRules); err != nil {
-			return err
+	if query.DashboardUID != "" {
+		q = fmt.Sprintf("%s and dashboard_uid = ?", q)
+		args = append(args, query.DashboardUID)
+		if query.PanelID != 0 {
+			q = fmt.Sprintf("%s and panel_id = ?", q)
+			args = append(args, query.PanelID)
 		}
+	}
 
-		query.Result = alertRules
-		return nil
-	})
+	q = fmt.Sprintf("%s ORDER BY rule_group_idx ASC, id ASC", q)
+
+	alertRules := make([]*ngmodels.AlertRule, 0)
+	if err := sess.SQL(q, args...).Find(&alertRules); err != nil {
+		return err
+	}
+
+	query.Result = alertRules
+	return nil
 }
 
 // GetNamespaces returns the folders that are visible to the user
@@ -438,12 +492,18 @@ func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, org
 	return folder, nil
 }
 
+// GetNamespaceByUID is a handler for retrieving a namespace by its UID.
+func (st DBstore) GetNamespaceByUID(ctx context.Context, uid string, orgID int64, user *models.SignedInUser) (*models.Folder, error) {
+	s := dashboards.NewFolderService(orgID, user, st.SQLStore)
+	return s.GetFolderByUID(ctx, uid)
+}
+
 // GetAlertRulesForScheduling returns alert rule info (identifier, interval, version state)
 // that is useful for it's scheduling.
 func (st DBstore) GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alerts := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT uid, org_id, interval_seconds, version FROM alert_rule"
+		q := "SELECT uid, org_id, namespace_uid, rule_group, rule_group_idx, interval_seconds, version, is_paused FROM alert_rule"
 		if len(query.ExcludeOrgs) > 0 {
 			q = fmt.Sprintf("%s WHERE org_id NOT IN (%s)", q, strings.Join(strings.Split(strings.Trim(fmt.Sprint(query.ExcludeOrgs), "[]"), " "), ","))
 		}
@@ -507,101 +567,133 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: cannot have Panel ID without a Dashboard UID", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.ComputedLabels {
+		for _, name := range ngmodels.ComputedLabelNames {
+			if _, ok := alertRule.Labels[name]; ok {
+				return fmt.Errorf("%w: label %q is reserved for the computed labels", ngmodels.ErrAlertRuleFailedValidation, name)
+			}
+		}
+	}
+
+	if alertRule.IsRecordingRule() && !model.IsValidMetricName(model.LabelValue(alertRule.Record)) {
+		return fmt.Errorf("%w: invalid metric name %q for recording rule", ngmodels.ErrAlertRuleFailedValidation, alertRule.Record)
+	}
+
 	return nil
 }
 
 // UpdateRuleGroup creates new rules and updates and/or deletes existing rules
 func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
-	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		ruleGroup := cmd.RuleGroupConfig.Name
-		q := &ngmodels.ListRuleGroupAlertRulesQuery{
-			OrgID:        cmd.OrgID,
-			NamespaceUID: cmd.NamespaceUID,
-			RuleGroup:    ruleGroup,
-		}
-		if err := st.GetRuleGroupAlertRules(q); err != nil {
-			return err
-		}
-		existingGroupRules := q.Result
-
-		existingGroupRulesUIDs := make(map[string]ngmodels.AlertRule, len(existingGroupRules))
-		for _, r := range existingGroupRules {
-			existingGroupRulesUIDs[r.UID] = *r
-		}
+	return st.UpdateRuleGroups([]UpdateRuleGroupCmd{cmd})
+}
 
-		upsertRules := make([]UpsertRule, 0)
-		for _, r := range cmd.RuleGroupConfig.Rules {
-			if r.GrafanaManagedAlert == nil {
-				continue
+// UpdateRuleGroups replaces the rules of several rule groups in a single transaction, so that either all
+// the rule groups are updated or none of them is.
+func (st DBstore) UpdateRuleGroups(cmds []UpdateRuleGroupCmd) error {
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, cmd := range cmds {
+			if err := st.updateRuleGroup(sess, cmd); err != nil {
+				return err
 			}
+		}
+		return nil
+	})
+	if err != nil && st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+		return ngmodels.ErrAlertRuleUniqueConstraintViolation
+	}
+	return err
+}
 
-			newAlertRule := ngmodels.AlertRule{
-				OrgID:           cmd.OrgID,
-				Title:           r.GrafanaManagedAlert.Title,
-				Condition:       r.GrafanaManagedAlert.Condition,
-				Data:            r.GrafanaManagedAlert.Data,
-				UID:             r.GrafanaManagedAlert.UID,
-				IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
-				NamespaceUID:    cmd.NamespaceUID,
-				RuleGroup:       ruleGroup,
-				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
-				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
-			}
+func (st DBstore) updateRuleGroup(sess *sqlstore.DBSession, cmd UpdateRuleGroupCmd) error {
+	for _, uid := range cmd.MovedRuleUIDs {
+		if err := deleteAlertRuleByUID(sess, cmd.OrgID, uid); err != nil {
+			return err
+		}
+	}
 
-			if r.ApiRuleNode != nil {
-				newAlertRule.For = time.Duration(r.ApiRuleNode.For)
-				newAlertRule.Annotations = r.ApiRuleNode.Annotations
-				newAlertRule.Labels = r.ApiRuleNode.Labels
-			}
+	ruleGroup := cmd.RuleGroupConfig.Name
+	q := &ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        cmd.OrgID,
+		NamespaceUID: cmd.NamespaceUID,
+		RuleGroup:    ruleGroup,
+	}
+	if err := getRuleGroupAlertRules(sess, q); err != nil {
+		return err
+	}
+	existingGroupRules := q.Result
 
-			if s := newAlertRule.Annotations[ngmodels.DashboardUIDAnnotation]; s != "" {
-				newAlertRule.DashboardUID = &s
-			}
+	existingGroupRulesUIDs := make(map[string]ngmodels.AlertRule, len(existingGroupRules))
+	for _, r := range existingGroupRules {
+		existingGroupRulesUIDs[r.UID] = *r
+	}
 
-			if s := newAlertRule.Annotations[ngmodels.PanelIDAnnotation]; s != "" {
-				panelID, err := strconv.ParseInt(s, 10, 64)
-				if err != nil {
-					return fmt.Errorf("the %s annotation does not contain a valid Panel ID: %w", ngmodels.PanelIDAnnotation, err)
-				}
-				newAlertRule.PanelID = &panelID
-			}
+	upsertRules := make([]UpsertRule, 0)
+	for _, r := range cmd.RuleGroupConfig.Rules {
+		if r.GrafanaManagedAlert == nil {
+			continue
+		}
 
-			upsertRule := UpsertRule{
-				New: newAlertRule,
-			}
+		newAlertRule := ngmodels.AlertRule{
+			OrgID:           cmd.OrgID,
+			Title:           r.GrafanaManagedAlert.Title,
+			Condition:       r.GrafanaManagedAlert.Condition,
+			Data:            r.GrafanaManagedAlert.Data,
+			UID:             r.GrafanaManagedAlert.UID,
+			IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
+			NamespaceUID:    cmd.NamespaceUID,
+			RuleGroup:       ruleGroup,
+			RuleGroupIndex:  len(upsertRules) + 1,
+			NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
+			ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+			IsPaused:        r.GrafanaManagedAlert.IsPaused,
+			ComputedLabels:  r.GrafanaManagedAlert.ComputedLabels,
+		}
 
-			if existingGroupRule, ok := existingGroupRulesUIDs[r.GrafanaManagedAlert.UID]; ok {
-				upsertRule.Existing = &existingGroupRule
-				// remove the rule from existingGroupRulesUIDs
-				delete(existingGroupRulesUIDs, r.GrafanaManagedAlert.UID)
-			}
-			upsertRules = append(upsertRules, upsertRule)
+		if r.ApiRuleNode != nil {
+			newAlertRule.For = time.Duration(r.ApiRuleNode.For)
+			newAlertRule.Annotations = r.ApiRuleNode.Annotations
+			newAlertRule.Labels = r.ApiRuleNode.Labels
+			newAlertRule.Record = r.ApiRuleNode.Record
 		}
 
-		if err := st.UpsertAlertRules(upsertRules); err != nil {
-			if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
-				return ngmodels.ErrAlertRuleUniqueConstraintViolation
-			}
+		if err := setDashboardAndPanelFromAnnotations(&newAlertRule); err != nil {
 			return err
 		}
 
-		// delete instances for rules that will not be removed
-		for _, rule := range existingGroupRules {
-			if _, ok := existingGroupRulesUIDs[rule.UID]; !ok {
-				if err := st.DeleteAlertInstancesByRuleUID(cmd.OrgID, rule.UID); err != nil {
-					return err
-				}
-			}
+		upsertRule := UpsertRule{
+			New:        newAlertRule,
+			UserID:     cmd.UserID,
+			Provenance: cmd.Provenance,
+		}
+
+		if existingGroupRule, ok := existingGroupRulesUIDs[r.GrafanaManagedAlert.UID]; ok {
+			upsertRule.Existing = &existingGroupRule
+			// remove the rule from existingGroupRulesUIDs
+			delete(existingGroupRulesUIDs, r.GrafanaManagedAlert.UID)
 		}
+		upsertRules = append(upsertRules, upsertRule)
+	}
 
-		// delete the remaining rules
-		for ruleUID := range existingGroupRulesUIDs {
-			if err := st.DeleteAlertRuleByUID(cmd.OrgID, ruleUID); err != nil {
+	if err := st.upsertAlertRules(sess, upsertRules); err != nil {
+		return err
+	}
+
+	// delete instances for rules that will not be removed
+	for _, rule := range existingGroupRules {
+		if _, ok := existingGroupRulesUIDs[rule.UID]; !ok {
+			if _, err := sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", cmd.OrgID, rule.UID); err != nil {
 				return err
 			}
 		}
-		return nil
-	})
+	}
+
+	// delete the remaining rules
+	for ruleUID := range existingGroupRulesUIDs {
+		if err := deleteAlertRuleByUID(sess, cmd.OrgID, ruleUID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (st DBstore) GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error {
@@ -650,3 +742,19 @@ WHERE org_id = ?`
 		return nil
 	})
 }
+
+// setDashboardAndPanelFromAnnotations sets the dashboard UID and the panel ID of the rule from its annotations.
+func setDashboardAndPanelFromAnnotations(rule *ngmodels.AlertRule) error {
+	if s := rule.Annotations[ngmodels.DashboardUIDAnnotation]; s != "" {
+		rule.DashboardUID = &s
+	}
+
+	if s := rule.Annotations[ngmodels.PanelIDAnnotation]; s != "" {
+		panelID, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("the %s annotation does not contain a valid Panel ID: %w", ngmodels.PanelIDAnnotation, err)
+		}
+		rule.PanelID = &panelID
+	}
+	return nil
+}
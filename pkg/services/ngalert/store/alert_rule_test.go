@@ -0,0 +1,141 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestUpdateRuleGroups(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	newCmd := func(group string, titles ...string) store.UpdateRuleGroupCmd {
+		cmd := store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     group,
+				Interval: model.Duration(time.Minute),
+			},
+		}
+		for _, title := range titles {
+			cmd.RuleGroupConfig.Rules = append(cmd.RuleGroupConfig.Rules, apimodels.PostableExtendedRuleNode{
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+					Title:     title,
+					Condition: "A",
+					Data: []models.AlertQuery{{
+						RefID:             "A",
+						RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Minute)},
+						Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+					}},
+				},
+			})
+		}
+		return cmd
+	}
+	groupTitles := func(t *testing.T, group string) []string {
+		t.Helper()
+		q := models.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: "namespace", RuleGroup: group}
+		require.NoError(t, dbstore.GetRuleGroupAlertRules(&q))
+		var titles []string
+		for _, r := range q.Result {
+			titles = append(titles, r.Title)
+		}
+		return titles
+	}
+
+	t.Run("it should update all the rule groups", func(t *testing.T) {
+		require.NoError(t, dbstore.UpdateRuleGroups([]store.UpdateRuleGroupCmd{
+			newCmd("a", "rule 1", "rule 2"),
+			newCmd("b", "rule 3"),
+		}))
+		require.Equal(t, []string{"rule 1", "rule 2"}, groupTitles(t, "a"))
+		require.Equal(t, []string{"rule 3"}, groupTitles(t, "b"))
+	})
+
+	t.Run("it should update none of the rule groups if one of them fails", func(t *testing.T) {
+		err := dbstore.UpdateRuleGroups([]store.UpdateRuleGroupCmd{
+			newCmd("c", "rule 4"),
+			newCmd("d", "rule 3"),
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleUniqueConstraintViolation)
+		require.Empty(t, groupTitles(t, "c"))
+		require.Empty(t, groupTitles(t, "d"))
+		require.Equal(t, []string{"rule 3"}, groupTitles(t, "b"))
+	})
+
+	t.Run("it should move rules from other groups", func(t *testing.T) {
+		q := models.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: "namespace", RuleGroup: "b"}
+		require.NoError(t, dbstore.GetRuleGroupAlertRules(&q))
+		require.Len(t, q.Result, 1)
+		moved := q.Result[0].UID
+		moveCmd := func(titles ...string) store.UpdateRuleGroupCmd {
+			cmd := newCmd("e", titles...)
+			cmd.RuleGroupConfig.Rules[0].GrafanaManagedAlert.UID = moved
+			cmd.MovedRuleUIDs = []string{moved}
+			cmd.Provenance = models.ProvenanceFile
+			return cmd
+		}
+
+		err := dbstore.UpdateRuleGroups([]store.UpdateRuleGroupCmd{moveCmd("rule 3", "rule 1")})
+		require.ErrorIs(t, err, models.ErrAlertRuleUniqueConstraintViolation)
+		require.Equal(t, []string{"rule 3"}, groupTitles(t, "b"), "the rule is not deleted if the group cannot be updated")
+		require.Empty(t, groupTitles(t, "e"))
+
+		require.NoError(t, dbstore.UpdateRuleGroups([]store.UpdateRuleGroupCmd{moveCmd("rule 3")}))
+		require.Empty(t, groupTitles(t, "b"))
+		require.Equal(t, []string{"rule 3"}, groupTitles(t, "e"))
+	})
+}
+
+func TestComputedLabelNames(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	newCmd := func(group string, computedLabels bool) store.UpdateRuleGroupCmd {
+		return store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     group,
+				Interval: model.Duration(time.Minute),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					ApiRuleNode: &apimodels.ApiRuleNode{Labels: map[string]string{models.FolderTitleLabel: "team-a"}},
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						Title:     group,
+						Condition: "A",
+						Data: []models.AlertQuery{{
+							RefID:             "A",
+							RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Minute)},
+							Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+						}},
+						ComputedLabels: computedLabels,
+					},
+				}},
+			},
+		}
+	}
+
+	t.Run("it should save rules with the labels if the computed labels are not enabled", func(t *testing.T) {
+		require.NoError(t, dbstore.UpdateRuleGroup(newCmd("disabled", false)))
+		q := models.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: "namespace", RuleGroup: "disabled"}
+		require.NoError(t, dbstore.GetRuleGroupAlertRules(&q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "team-a", q.Result[0].Labels[models.FolderTitleLabel])
+	})
+
+	t.Run("it should reject rules with the labels if the computed labels are enabled", func(t *testing.T) {
+		err := dbstore.UpdateRuleGroup(newCmd("enabled", true))
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
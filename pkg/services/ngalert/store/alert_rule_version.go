@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// RuleVersionStore is the interface for reading and restoring the versions of alert rules.
+type RuleVersionStore interface {
+	GetAlertRuleVersions(query *ngmodels.ListAlertRuleVersionsQuery) error
+	GetAlertRuleVersion(query *ngmodels.GetAlertRuleVersionQuery) error
+	RestoreAlertRuleVersion(cmd *ngmodels.RestoreAlertRuleVersionCommand) error
+}
+
+func (st DBstore) alertRuleVersionsSQL() string {
+	return fmt.Sprintf(`SELECT alert_rule_version.*, u.login AS created_by_login
+		FROM alert_rule_version
+		LEFT JOIN %s AS u ON u.id = alert_rule_version.created_by
+		WHERE alert_rule_version.rule_org_id = ? AND alert_rule_version.rule_uid = ?`, st.SQLStore.Dialect.Quote("user"))
+}
+
+// GetAlertRuleVersions is a handler for retrieving the versions of an alert rule, newest first.
+func (st DBstore) GetAlertRuleVersions(query *ngmodels.ListAlertRuleVersionsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		versions := make([]*ngmodels.AlertRuleVersionWithCreator, 0)
+		q := st.alertRuleVersionsSQL() + " ORDER BY alert_rule_version.version DESC"
+		if err := sess.SQL(q, query.OrgID, query.RuleUID).Find(&versions); err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			return ngmodels.ErrAlertRuleNotFound
+		}
+		query.Result = versions
+		return nil
+	})
+}
+
+// GetAlertRuleVersion is a handler for retrieving a single version of an alert rule.
+func (st DBstore) GetAlertRuleVersion(query *ngmodels.GetAlertRuleVersionQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		version, err := st.getAlertRuleVersion(sess, query.OrgID, query.RuleUID, query.Version)
+		if err != nil {
+			return err
+		}
+		query.Result = version
+		return nil
+	})
+}
+
+func (st DBstore) getAlertRuleVersion(sess *sqlstore.DBSession, orgID int64, ruleUID string, version int64) (*ngmodels.AlertRuleVersionWithCreator, error) {
+	versions := make([]*ngmodels.AlertRuleVersionWithCreator, 0, 1)
+	q := st.alertRuleVersionsSQL() + " AND alert_rule_version.version = ?"
+	if err := sess.SQL(q, orgID, ruleUID, version).Find(&versions); err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ngmodels.ErrAlertRuleVersionNotFound
+	}
+	return versions[0], nil
+}
+
+// RestoreAlertRuleVersion is a handler for restoring a version of an alert rule.
+// The content of the version becomes a new version of the alert rule, while the
+// rule keeps its current namespace, rule group and evaluation interval.
+func (st DBstore) RestoreAlertRuleVersion(cmd *ngmodels.RestoreAlertRuleVersionCommand) error {
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return st.restoreAlertRuleVersion(sess, cmd)
+	})
+	if err != nil && st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+		return ngmodels.ErrAlertRuleUniqueConstraintViolation
+	}
+	return err
+}
+
+func (st DBstore) restoreAlertRuleVersion(sess *sqlstore.DBSession, cmd *ngmodels.RestoreAlertRuleVersionCommand) error {
+	existing, err := getAlertRuleByUID(sess, cmd.RuleUID, cmd.OrgID)
+	if err != nil {
+		return err
+	}
+	version, err := st.getAlertRuleVersion(sess, cmd.OrgID, cmd.RuleUID, cmd.Version)
+	if err != nil {
+		return err
+	}
+
+	restored := ngmodels.AlertRule{
+		OrgID:           existing.OrgID,
+		UID:             existing.UID,
+		NamespaceUID:    existing.NamespaceUID,
+		RuleGroup:       existing.RuleGroup,
+		IntervalSeconds: existing.IntervalSeconds,
+		Title:           version.Title,
+		Condition:       version.Condition,
+		Data:            version.Data,
+		NoDataState:     version.NoDataState,
+		ExecErrState:    version.ExecErrState,
+		For:             version.For,
+		Annotations:     version.Annotations,
+		Labels:          version.Labels,
+		Record:          version.Record,
+		IsPaused:        version.IsPaused,
+		ComputedLabels:  version.ComputedLabels,
+	}
+	if err := setDashboardAndPanelFromAnnotations(&restored); err != nil {
+		return err
+	}
+
+	err = st.upsertAlertRules(sess, []UpsertRule{{
+		Existing:     existing,
+		New:          restored,
+		UserID:       cmd.UserID,
+		RestoredFrom: version.Version,
+	}})
+	if err != nil {
+		return err
+	}
+
+	// the states of the previous version do not apply to the restored one
+	if _, err := sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", cmd.OrgID, cmd.RuleUID); err != nil {
+		return err
+	}
+
+	cmd.Result, err = getAlertRuleByUID(sess, cmd.RuleUID, cmd.OrgID)
+	return err
+}
@@ -0,0 +1,74 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	models2 "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleVersionOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	user, err := dbstore.SQLStore.CreateUser(context.Background(), models2.CreateUserCommand{Login: "editor"})
+	require.NoError(t, err)
+
+	rule := tests.CreateTestAlertRule(t, dbstore, 60, 1)
+	originalTitle := rule.Title
+
+	updated := *rule
+	updated.Title = "updated title"
+	require.NoError(t, dbstore.UpsertAlertRules([]store.UpsertRule{{Existing: rule, New: updated, UserID: user.Id}}))
+
+	t.Run("it should list the versions newest first", func(t *testing.T) {
+		q := models.ListAlertRuleVersionsQuery{OrgID: rule.OrgID, RuleUID: rule.UID}
+		require.NoError(t, dbstore.GetAlertRuleVersions(&q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, int64(2), q.Result[0].Version)
+		require.Equal(t, int64(1), q.Result[0].ParentVersion)
+		require.Equal(t, "updated title", q.Result[0].Title)
+		require.Equal(t, "editor", q.Result[0].CreatedByLogin)
+		require.Equal(t, int64(1), q.Result[1].Version)
+		require.Equal(t, "", q.Result[1].CreatedByLogin)
+	})
+
+	t.Run("it should return an error for an unknown rule", func(t *testing.T) {
+		q := models.ListAlertRuleVersionsQuery{OrgID: rule.OrgID, RuleUID: "unknown"}
+		require.ErrorIs(t, dbstore.GetAlertRuleVersions(&q), models.ErrAlertRuleNotFound)
+	})
+
+	t.Run("it should return an error for an unknown version", func(t *testing.T) {
+		q := models.GetAlertRuleVersionQuery{OrgID: rule.OrgID, RuleUID: rule.UID, Version: 10}
+		require.ErrorIs(t, dbstore.GetAlertRuleVersion(&q), models.ErrAlertRuleVersionNotFound)
+	})
+
+	t.Run("it should restore a version as a new version", func(t *testing.T) {
+		cmd := models.RestoreAlertRuleVersionCommand{OrgID: rule.OrgID, RuleUID: rule.UID, Version: 1, UserID: user.Id}
+		require.NoError(t, dbstore.RestoreAlertRuleVersion(&cmd))
+		require.Equal(t, originalTitle, cmd.Result.Title)
+		require.Equal(t, int64(3), cmd.Result.Version)
+
+		q := models.GetAlertRuleVersionQuery{OrgID: rule.OrgID, RuleUID: rule.UID, Version: 3}
+		require.NoError(t, dbstore.GetAlertRuleVersion(&q))
+		require.Equal(t, int64(1), q.Result.RestoredFrom)
+		require.Equal(t, int64(2), q.Result.ParentVersion)
+		require.Equal(t, originalTitle, q.Result.Title)
+		require.Equal(t, "editor", q.Result.CreatedByLogin)
+	})
+	t.Run("it should not change the rule when the version cannot be restored", func(t *testing.T) {
+		cmd := models.RestoreAlertRuleVersionCommand{OrgID: rule.OrgID, RuleUID: rule.UID, Version: 10, UserID: user.Id}
+		require.ErrorIs(t, dbstore.RestoreAlertRuleVersion(&cmd), models.ErrAlertRuleVersionNotFound)
+
+		q := models.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(&q))
+		require.Equal(t, int64(3), q.Result.Version)
+	})
+}
@@ -67,6 +67,7 @@ func (st DBstore) SaveAlertmanagerConfigurationWithCallback(cmd *models.SaveAler
 			ConfigurationVersion:      cmd.ConfigurationVersion,
 			Default:                   cmd.Default,
 			OrgID:                     cmd.OrgID,
+			CreatedBy:                 cmd.CreatedBy,
 		}
 		if _, err := sess.Insert(config); err != nil {
 			return err
@@ -79,3 +80,87 @@ func (st DBstore) SaveAlertmanagerConfigurationWithCallback(cmd *models.SaveAler
 		return nil
 	})
 }
+
+// AlertmanagerConfigHistoryStore is the interface for reading and pruning the saved versions of the alertmanager configurations.
+type AlertmanagerConfigHistoryStore interface {
+	GetAlertmanagerConfigurationHistory(ctx context.Context, query *models.ListAlertmanagerConfigurationHistoryQuery) error
+	GetAlertmanagerConfiguration(ctx context.Context, query *models.GetAlertmanagerConfigurationQuery) error
+	DeleteOldAlertmanagerConfigurations(ctx context.Context, keep int) (int64, error)
+}
+
+func (st DBstore) alertmanagerConfigurationsSQL() string {
+	return fmt.Sprintf(`SELECT alert_configuration.*, u.login AS created_by_login
+		FROM alert_configuration
+		LEFT JOIN %s AS u ON u.id = alert_configuration.created_by
+		WHERE alert_configuration.org_id = ?`, st.SQLStore.Dialect.Quote("user"))
+}
+
+// GetAlertmanagerConfigurationHistory returns the saved alertmanager configurations of an organization, newest first.
+func (st DBstore) GetAlertmanagerConfigurationHistory(ctx context.Context, query *models.ListAlertmanagerConfigurationHistoryQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		configs := make([]*models.AlertConfigurationWithCreator, 0)
+		q := st.alertmanagerConfigurationsSQL() + " ORDER BY alert_configuration.id DESC"
+		if query.Limit > 0 {
+			q += " " + st.SQLStore.Dialect.Limit(int64(query.Limit))
+		}
+		if err := sess.SQL(q, query.OrgID).Find(&configs); err != nil {
+			return err
+		}
+		query.Result = configs
+		return nil
+	})
+}
+
+// GetAlertmanagerConfiguration returns a saved alertmanager configuration of an organization.
+// It returns ErrNoAlertmanagerConfiguration if the configuration is not found.
+func (st DBstore) GetAlertmanagerConfiguration(ctx context.Context, query *models.GetAlertmanagerConfigurationQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		configs := make([]*models.AlertConfigurationWithCreator, 0, 1)
+		q := st.alertmanagerConfigurationsSQL() + " AND alert_configuration.id = ?"
+		if err := sess.SQL(q, query.OrgID, query.ID).Find(&configs); err != nil {
+			return err
+		}
+		if len(configs) == 0 {
+			return ErrNoAlertmanagerConfiguration
+		}
+		query.Result = configs[0]
+		return nil
+	})
+}
+
+// DeleteOldAlertmanagerConfigurations deletes the saved alertmanager configurations of every organization
+// but the most recent ones, of which it keeps as many as requested, and returns the number of deleted configurations.
+// The latest configuration of an organization is never deleted.
+func (st DBstore) DeleteOldAlertmanagerConfigurations(ctx context.Context, keep int) (int64, error) {
+	if keep < 1 {
+		keep = 1
+	}
+	var affected int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var orgIDs []int64
+		if err := sess.Table("alert_configuration").Distinct("org_id").Find(&orgIDs); err != nil {
+			return err
+		}
+		for _, orgID := range orgIDs {
+			// the ID of the oldest configuration to keep
+			var ids []int64
+			if err := sess.Table("alert_configuration").Cols("id").Where("org_id = ?", orgID).Desc("id").Limit(1, keep-1).Find(&ids); err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			res, err := sess.Exec("DELETE FROM alert_configuration WHERE org_id = ? AND id < ?", orgID, ids[0])
+			if err != nil {
+				return err
+			}
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			affected += rows
+		}
+		return nil
+	})
+	return affected, err
+}
@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	models2 "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertmanagerConfigurationHistory(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	user, err := dbstore.SQLStore.CreateUser(context.Background(), models2.CreateUserCommand{Login: "editor"})
+	require.NoError(t, err)
+
+	const orgID = 1
+	for _, cfg := range []string{"first", "second", "third"} {
+		require.NoError(t, dbstore.SaveAlertmanagerConfiguration(&models.SaveAlertmanagerConfigurationCmd{
+			AlertmanagerConfiguration: cfg,
+			ConfigurationVersion:      "v1",
+			OrgID:                     orgID,
+			CreatedBy:                 user.Id,
+		}))
+	}
+	require.NoError(t, dbstore.SaveAlertmanagerConfiguration(&models.SaveAlertmanagerConfigurationCmd{
+		AlertmanagerConfiguration: "other org",
+		ConfigurationVersion:      "v1",
+		OrgID:                     2,
+	}))
+
+	t.Run("it should list the configurations of the org newest first", func(t *testing.T) {
+		q := models.ListAlertmanagerConfigurationHistoryQuery{OrgID: orgID}
+		require.NoError(t, dbstore.GetAlertmanagerConfigurationHistory(context.Background(), &q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, "third", q.Result[0].AlertmanagerConfiguration)
+		require.Equal(t, "first", q.Result[2].AlertmanagerConfiguration)
+		require.Equal(t, "editor", q.Result[0].CreatedByLogin)
+
+		q = models.ListAlertmanagerConfigurationHistoryQuery{OrgID: orgID, Limit: 2}
+		require.NoError(t, dbstore.GetAlertmanagerConfigurationHistory(context.Background(), &q))
+		require.Len(t, q.Result, 2)
+	})
+
+	t.Run("it should get a configuration of the org by ID", func(t *testing.T) {
+		list := models.ListAlertmanagerConfigurationHistoryQuery{OrgID: orgID}
+		require.NoError(t, dbstore.GetAlertmanagerConfigurationHistory(context.Background(), &list))
+
+		q := models.GetAlertmanagerConfigurationQuery{OrgID: orgID, ID: list.Result[1].ID}
+		require.NoError(t, dbstore.GetAlertmanagerConfiguration(context.Background(), &q))
+		require.Equal(t, "second", q.Result.AlertmanagerConfiguration)
+
+		q = models.GetAlertmanagerConfigurationQuery{OrgID: 2, ID: list.Result[1].ID}
+		require.ErrorIs(t, dbstore.GetAlertmanagerConfiguration(context.Background(), &q), store.ErrNoAlertmanagerConfiguration)
+	})
+
+	t.Run("it should keep the most recent configurations of every org", func(t *testing.T) {
+		affected, err := dbstore.DeleteOldAlertmanagerConfigurations(context.Background(), 2)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), affected)
+
+		q := models.ListAlertmanagerConfigurationHistoryQuery{OrgID: orgID}
+		require.NoError(t, dbstore.GetAlertmanagerConfigurationHistory(context.Background(), &q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, "second", q.Result[1].AlertmanagerConfiguration)
+
+		latest := models.GetLatestAlertmanagerConfigurationQuery{OrgID: 2}
+		require.NoError(t, dbstore.GetLatestAlertmanagerConfiguration(&latest))
+		require.Equal(t, "other org", latest.Result.AlertmanagerConfiguration)
+	})
+}
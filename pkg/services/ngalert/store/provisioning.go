@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// ProvisioningStore is the database interface for the provenance of the provisioned resources.
+type ProvisioningStore interface {
+	GetProvenances(ctx context.Context, orgID int64, recordType string) (map[string]models.Provenance, error)
+	SetProvenance(ctx context.Context, orgID int64, recordType string, recordKey string, provenance models.Provenance) error
+}
+
+// GetProvenances returns the provenance of the provisioned resources of a type by key.
+func (st DBstore) GetProvenances(ctx context.Context, orgID int64, recordType string) (map[string]models.Provenance, error) {
+	result := make(map[string]models.Provenance)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		records := make([]models.ProvenanceRecord, 0)
+		if err := sess.Table("alert_provenance").Where("org_id = ? AND record_type = ?", orgID, recordType).Find(&records); err != nil {
+			return err
+		}
+		for _, r := range records {
+			result[r.RecordKey] = r.Provenance
+		}
+		return nil
+	})
+	return result, err
+}
+
+// SetProvenance sets the provenance of a resource. The resource is no longer provisioned if the provenance is
+// models.ProvenanceNone.
+func (st DBstore) SetProvenance(ctx context.Context, orgID int64, recordType string, recordKey string, provenance models.Provenance) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return setProvenance(sess, orgID, recordType, recordKey, provenance)
+	})
+}
+
+func setProvenance(sess *sqlstore.DBSession, orgID int64, recordType string, recordKey string, provenance models.Provenance) error {
+	if _, err := sess.Exec("DELETE FROM alert_provenance WHERE org_id = ? AND record_type = ? AND record_key = ?", orgID, recordType, recordKey); err != nil {
+		return err
+	}
+	if provenance == models.ProvenanceNone {
+		return nil
+	}
+	_, err := sess.Table("alert_provenance").Insert(&models.ProvenanceRecord{
+		OrgID:      orgID,
+		RecordType: recordType,
+		RecordKey:  recordKey,
+		Provenance: provenance,
+	})
+	return err
+}
@@ -0,0 +1,80 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestProvenanceOperations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	t.Run("it should set, get and remove provenances", func(t *testing.T) {
+		require.NoError(t, dbstore.SetProvenance(ctx, 1, models.ResourceTypeContactPoint, "slack", models.ProvenanceFile))
+		require.NoError(t, dbstore.SetProvenance(ctx, 1, models.ResourceTypeContactPoint, "email", models.ProvenanceFile))
+		require.NoError(t, dbstore.SetProvenance(ctx, 2, models.ResourceTypeContactPoint, "pagerduty", models.ProvenanceFile))
+		require.NoError(t, dbstore.SetProvenance(ctx, 1, models.ResourceTypeTemplate, "slack", models.ProvenanceFile))
+		// setting a provenance twice is not an error
+		require.NoError(t, dbstore.SetProvenance(ctx, 1, models.ResourceTypeContactPoint, "slack", models.ProvenanceFile))
+
+		provenances, err := dbstore.GetProvenances(ctx, 1, models.ResourceTypeContactPoint)
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{"slack": models.ProvenanceFile, "email": models.ProvenanceFile}, provenances)
+
+		require.NoError(t, dbstore.SetProvenance(ctx, 1, models.ResourceTypeContactPoint, "slack", models.ProvenanceNone))
+		provenances, err = dbstore.GetProvenances(ctx, 1, models.ResourceTypeContactPoint)
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{"email": models.ProvenanceFile}, provenances)
+	})
+
+	t.Run("it should create provisioned rules with their UID and remove their provenance when they are deleted", func(t *testing.T) {
+		err := dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "provisioned",
+				Interval: model.Duration(time.Minute),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						UID:       "provisioned-rule",
+						Title:     "provisioned rule",
+						Condition: "A",
+						Data: []models.AlertQuery{{
+							RefID:             "A",
+							RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Minute)},
+							Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+						}},
+					},
+				}},
+			},
+			Provenance: models.ProvenanceFile,
+		})
+		require.NoError(t, err)
+
+		q := models.GetAlertRuleByUIDQuery{OrgID: 1, UID: "provisioned-rule"}
+		require.NoError(t, dbstore.GetAlertRuleByUID(&q))
+		require.Equal(t, "provisioned", q.Result.RuleGroup)
+
+		provenances, err := dbstore.GetProvenances(ctx, 1, models.ResourceTypeAlertRule)
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{"provisioned-rule": models.ProvenanceFile}, provenances)
+
+		require.NoError(t, dbstore.DeleteAlertRuleByUID(1, "provisioned-rule"))
+		provenances, err = dbstore.GetProvenances(ctx, 1, models.ResourceTypeAlertRule)
+		require.NoError(t, err)
+		require.Empty(t, provenances)
+	})
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// SchedulerMemberStore is the database interface used to discover the Grafana instances
+// taking part in the sharding of the alert rule evaluation.
+type SchedulerMemberStore interface {
+	HeartbeatSchedulerMember(ctx context.Context, nodeID string, now time.Time) error
+	GetActiveSchedulerMembers(ctx context.Context, since time.Time) ([]string, error)
+	DeleteSchedulerMember(ctx context.Context, nodeID string) error
+}
+
+// HeartbeatSchedulerMember announces the instance identified by nodeID as alive at the provided time.
+func (st DBstore) HeartbeatSchedulerMember(ctx context.Context, nodeID string, now time.Time) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		member := models.SchedulerMember{}
+		has, err := sess.Table("alert_scheduler_member").Where("node_id = ?", nodeID).Get(&member)
+		if err != nil {
+			return err
+		}
+
+		member.Heartbeat = now.Unix()
+		if has {
+			_, err = sess.Table("alert_scheduler_member").ID(member.ID).Cols("heartbeat").Update(&member)
+			return err
+		}
+
+		member.NodeID = nodeID
+		_, err = sess.Table("alert_scheduler_member").Insert(&member)
+		return err
+	})
+}
+
+// GetActiveSchedulerMembers returns the identifiers of the instances that announced themselves after the provided time.
+func (st DBstore) GetActiveSchedulerMembers(ctx context.Context, since time.Time) ([]string, error) {
+	members := make([]string, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("alert_scheduler_member").Where("heartbeat >= ?", since.Unix()).Asc("node_id").Cols("node_id").Find(&members)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// DeleteSchedulerMember removes the instance identified by nodeID from the members.
+func (st DBstore) DeleteSchedulerMember(ctx context.Context, nodeID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_scheduler_member WHERE node_id = ?", nodeID)
+		return err
+	})
+}
@@ -0,0 +1,43 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerMemberOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+
+	require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "node-b", now))
+	require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "node-a", now.Add(-2*time.Minute)))
+
+	t.Run("it should only return members with a recent heartbeat", func(t *testing.T) {
+		members, err := dbstore.GetActiveSchedulerMembers(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"node-b"}, members)
+	})
+
+	t.Run("it should update the heartbeat of an existing member", func(t *testing.T) {
+		require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "node-a", now))
+		members, err := dbstore.GetActiveSchedulerMembers(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"node-a", "node-b"}, members)
+	})
+
+	t.Run("it should delete a member", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteSchedulerMember(ctx, "node-b"))
+		members, err := dbstore.GetActiveSchedulerMembers(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"node-a"}, members)
+	})
+}
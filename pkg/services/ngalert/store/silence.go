@@ -0,0 +1,208 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SilenceStore is the database interface for the silence templates and the recurring silences.
+type SilenceStore interface {
+	ListSilenceTemplates(ctx context.Context, query *models.ListSilenceTemplatesQuery) error
+	GetSilenceTemplate(ctx context.Context, query *models.GetSilenceTemplateQuery) error
+	SaveSilenceTemplate(ctx context.Context, template *models.SilenceTemplate) error
+	DeleteSilenceTemplate(ctx context.Context, cmd *models.DeleteSilenceTemplateCommand) error
+
+	ListRecurringSilences(ctx context.Context, query *models.ListRecurringSilencesQuery) error
+	GetRecurringSilence(ctx context.Context, query *models.GetRecurringSilenceQuery) error
+	SaveRecurringSilence(ctx context.Context, silence *models.RecurringSilence) error
+	DeleteRecurringSilence(ctx context.Context, cmd *models.DeleteRecurringSilenceCommand) error
+	ClaimRecurringSilenceOccurrence(ctx context.Context, id int64, startsAt int64, silenceID string) (bool, error)
+	ResetRecurringSilenceOccurrence(ctx context.Context, id int64) error
+}
+
+// ListSilenceTemplates returns the silence templates of an organization sorted by name.
+func (st DBstore) ListSilenceTemplates(ctx context.Context, query *models.ListSilenceTemplatesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		templates := make([]*models.SilenceTemplate, 0)
+		if err := sess.Table("alert_silence_template").Where("org_id = ?", query.OrgID).Asc("name").Find(&templates); err != nil {
+			return err
+		}
+		query.Result = templates
+		return nil
+	})
+}
+
+// GetSilenceTemplate returns a silence template by UID. It returns models.ErrSilenceTemplateNotFound if
+// the template is not found.
+func (st DBstore) GetSilenceTemplate(ctx context.Context, query *models.GetSilenceTemplateQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		template := models.SilenceTemplate{}
+		ok, err := sess.Table("alert_silence_template").Where("org_id = ? AND uid = ?", query.OrgID, query.UID).Get(&template)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrSilenceTemplateNotFound
+		}
+		query.Result = &template
+		return nil
+	})
+}
+
+// SaveSilenceTemplate creates the silence template if it has no ID, and updates it otherwise.
+// It returns models.ErrSilenceTemplateNameExists if another template of the organization has the same name.
+func (st DBstore) SaveSilenceTemplate(ctx context.Context, template *models.SilenceTemplate) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Table("alert_silence_template").Where("org_id = ? AND name = ? AND id <> ?", template.OrgID, template.Name, template.ID).Exist()
+		if err != nil {
+			return err
+		}
+		if exists {
+			return models.ErrSilenceTemplateNameExists
+		}
+
+		template.Updated = TimeNow()
+		if template.ID == 0 {
+			if template.UID == "" {
+				template.UID = util.GenerateShortUID()
+			}
+			_, err := sess.Table("alert_silence_template").Insert(template)
+			return err
+		}
+		affected, err := sess.Table("alert_silence_template").ID(template.ID).AllCols().Update(template)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrSilenceTemplateNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteSilenceTemplate deletes a silence template by UID. It returns models.ErrSilenceTemplateNotFound if
+// the template is not found.
+func (st DBstore) DeleteSilenceTemplate(ctx context.Context, cmd *models.DeleteSilenceTemplateCommand) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_silence_template WHERE org_id = ? AND uid = ?", cmd.OrgID, cmd.UID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrSilenceTemplateNotFound
+		}
+		return nil
+	})
+}
+
+// ListRecurringSilences returns the recurring silences of an organization, or of every organization if
+// the organization ID of the query is zero.
+func (st DBstore) ListRecurringSilences(ctx context.Context, query *models.ListRecurringSilencesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		silences := make([]*models.RecurringSilence, 0)
+		q := sess.Table("alert_recurring_silence")
+		if query.OrgID > 0 {
+			q = q.Where("org_id = ?", query.OrgID)
+		}
+		if err := q.Asc("org_id", "id").Find(&silences); err != nil {
+			return err
+		}
+		query.Result = silences
+		return nil
+	})
+}
+
+// GetRecurringSilence returns a recurring silence by UID. It returns models.ErrRecurringSilenceNotFound if
+// the recurring silence is not found.
+func (st DBstore) GetRecurringSilence(ctx context.Context, query *models.GetRecurringSilenceQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		silence := models.RecurringSilence{}
+		ok, err := sess.Table("alert_recurring_silence").Where("org_id = ? AND uid = ?", query.OrgID, query.UID).Get(&silence)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrRecurringSilenceNotFound
+		}
+		query.Result = &silence
+		return nil
+	})
+}
+
+// SaveRecurringSilence creates the recurring silence if it has no ID, and updates it otherwise. The
+// materialized occurrence is not updated, see ClaimRecurringSilenceOccurrence.
+func (st DBstore) SaveRecurringSilence(ctx context.Context, silence *models.RecurringSilence) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		silence.Updated = TimeNow()
+		if silence.ID == 0 {
+			if silence.UID == "" {
+				silence.UID = util.GenerateShortUID()
+			}
+			_, err := sess.Table("alert_recurring_silence").Insert(silence)
+			return err
+		}
+		affected, err := sess.Table("alert_recurring_silence").ID(silence.ID).AllCols().Omit("last_starts_at", "silence_id").Update(silence)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrRecurringSilenceNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteRecurringSilence deletes a recurring silence by UID. It returns models.ErrRecurringSilenceNotFound if
+// the recurring silence is not found.
+func (st DBstore) DeleteRecurringSilence(ctx context.Context, cmd *models.DeleteRecurringSilenceCommand) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_recurring_silence WHERE org_id = ? AND uid = ?", cmd.OrgID, cmd.UID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrRecurringSilenceNotFound
+		}
+		return nil
+	})
+}
+
+// ClaimRecurringSilenceOccurrence records that the occurrence of a recurring silence that starts at startsAt,
+// in Unix seconds, is materialized into the silence with the given ID. It returns false if this occurrence or a
+// later one was already claimed, so that a single Grafana instance materializes each occurrence.
+func (st DBstore) ClaimRecurringSilenceOccurrence(ctx context.Context, id int64, startsAt int64, silenceID string) (bool, error) {
+	var claimed bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("UPDATE alert_recurring_silence SET last_starts_at = ?, silence_id = ? WHERE id = ? AND last_starts_at < ?", startsAt, silenceID, id, startsAt)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		claimed = affected > 0
+		return nil
+	})
+	return claimed, err
+}
+
+// ResetRecurringSilenceOccurrence forgets the materialized occurrence of a recurring silence, so that the
+// current or next occurrence is materialized again.
+func (st DBstore) ResetRecurringSilenceOccurrence(ctx context.Context, id int64) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("UPDATE alert_recurring_silence SET last_starts_at = 0, silence_id = '' WHERE id = ?", id)
+		return err
+	})
+}
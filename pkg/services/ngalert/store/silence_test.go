@@ -0,0 +1,137 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilenceTemplateOperations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	matchers := []models.SilenceMatcher{{Name: "team", Value: "db", IsEqual: true}}
+	template := &models.SilenceTemplate{OrgID: 1, Name: "db maintenance", Matchers: matchers, Duration: 2 * time.Hour}
+	require.NoError(t, dbstore.SaveSilenceTemplate(ctx, template))
+	require.NotEmpty(t, template.UID)
+	require.NoError(t, dbstore.SaveSilenceTemplate(ctx, &models.SilenceTemplate{OrgID: 2, Name: "db maintenance", Matchers: matchers}))
+
+	t.Run("it should get the templates of the organization", func(t *testing.T) {
+		q := models.GetSilenceTemplateQuery{OrgID: 1, UID: template.UID}
+		require.NoError(t, dbstore.GetSilenceTemplate(ctx, &q))
+		require.Equal(t, matchers, q.Result.Matchers)
+		require.Equal(t, 2*time.Hour, q.Result.Duration)
+
+		q = models.GetSilenceTemplateQuery{OrgID: 2, UID: template.UID}
+		require.ErrorIs(t, dbstore.GetSilenceTemplate(ctx, &q), models.ErrSilenceTemplateNotFound)
+
+		list := models.ListSilenceTemplatesQuery{OrgID: 1}
+		require.NoError(t, dbstore.ListSilenceTemplates(ctx, &list))
+		require.Len(t, list.Result, 1)
+	})
+
+	t.Run("it should reject a duplicate name", func(t *testing.T) {
+		err := dbstore.SaveSilenceTemplate(ctx, &models.SilenceTemplate{OrgID: 1, Name: "db maintenance", Matchers: matchers})
+		require.ErrorIs(t, err, models.ErrSilenceTemplateNameExists)
+	})
+
+	t.Run("it should update and delete a template", func(t *testing.T) {
+		template.Comment = "weekly maintenance"
+		require.NoError(t, dbstore.SaveSilenceTemplate(ctx, template))
+		q := models.GetSilenceTemplateQuery{OrgID: 1, UID: template.UID}
+		require.NoError(t, dbstore.GetSilenceTemplate(ctx, &q))
+		require.Equal(t, "weekly maintenance", q.Result.Comment)
+
+		require.NoError(t, dbstore.DeleteSilenceTemplate(ctx, &models.DeleteSilenceTemplateCommand{OrgID: 1, UID: template.UID}))
+		err := dbstore.DeleteSilenceTemplate(ctx, &models.DeleteSilenceTemplateCommand{OrgID: 1, UID: template.UID})
+		require.ErrorIs(t, err, models.ErrSilenceTemplateNotFound)
+	})
+}
+
+func TestRecurringSilenceOperations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	silence := &models.RecurringSilence{
+		OrgID:    1,
+		Matchers: []models.SilenceMatcher{{Name: "team", Value: "db", IsEqual: true}},
+		WeeklyWindows: []models.WeeklyWindow{
+			{Weekdays: []time.Weekday{time.Sunday}, StartTime: "02:00", EndTime: "04:00"},
+		},
+		Timezone: "Europe/Paris",
+	}
+	require.NoError(t, dbstore.SaveRecurringSilence(ctx, silence))
+	require.NotEmpty(t, silence.UID)
+	require.NoError(t, dbstore.SaveRecurringSilence(ctx, &models.RecurringSilence{OrgID: 2, Cron: "0 2 * * *", Duration: time.Hour}))
+
+	t.Run("it should get the recurring silences", func(t *testing.T) {
+		q := models.GetRecurringSilenceQuery{OrgID: 1, UID: silence.UID}
+		require.NoError(t, dbstore.GetRecurringSilence(ctx, &q))
+		require.Equal(t, silence.WeeklyWindows, q.Result.WeeklyWindows)
+		require.Equal(t, "Europe/Paris", q.Result.Timezone)
+
+		list := models.ListRecurringSilencesQuery{OrgID: 1}
+		require.NoError(t, dbstore.ListRecurringSilences(ctx, &list))
+		require.Len(t, list.Result, 1)
+
+		list = models.ListRecurringSilencesQuery{}
+		require.NoError(t, dbstore.ListRecurringSilences(ctx, &list))
+		require.Len(t, list.Result, 2)
+	})
+
+	t.Run("it should claim each occurrence once", func(t *testing.T) {
+		claimed, err := dbstore.ClaimRecurringSilenceOccurrence(ctx, silence.ID, 100, "first")
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = dbstore.ClaimRecurringSilenceOccurrence(ctx, silence.ID, 100, "duplicate")
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		claimed, err = dbstore.ClaimRecurringSilenceOccurrence(ctx, silence.ID, 200, "second")
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		q := models.GetRecurringSilenceQuery{OrgID: 1, UID: silence.UID}
+		require.NoError(t, dbstore.GetRecurringSilence(ctx, &q))
+		require.Equal(t, int64(200), q.Result.LastStartsAt)
+		require.Equal(t, "second", q.Result.SilenceID)
+	})
+
+	t.Run("it should not update the claimed occurrence when the recurring silence is saved", func(t *testing.T) {
+		silence.Comment = "maintenance"
+		require.NoError(t, dbstore.SaveRecurringSilence(ctx, silence))
+
+		q := models.GetRecurringSilenceQuery{OrgID: 1, UID: silence.UID}
+		require.NoError(t, dbstore.GetRecurringSilence(ctx, &q))
+		require.Equal(t, "maintenance", q.Result.Comment)
+		require.Equal(t, int64(200), q.Result.LastStartsAt)
+	})
+
+	t.Run("it should reset the claimed occurrence", func(t *testing.T) {
+		require.NoError(t, dbstore.ResetRecurringSilenceOccurrence(ctx, silence.ID))
+
+		q := models.GetRecurringSilenceQuery{OrgID: 1, UID: silence.UID}
+		require.NoError(t, dbstore.GetRecurringSilence(ctx, &q))
+		require.Zero(t, q.Result.LastStartsAt)
+		require.Empty(t, q.Result.SilenceID)
+
+		claimed, err := dbstore.ClaimRecurringSilenceOccurrence(ctx, silence.ID, 200, "again")
+		require.NoError(t, err)
+		require.True(t, claimed)
+	})
+
+	t.Run("it should delete a recurring silence", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteRecurringSilence(ctx, &models.DeleteRecurringSilenceCommand{OrgID: 1, UID: silence.UID}))
+		err := dbstore.DeleteRecurringSilence(ctx, &models.DeleteRecurringSilenceCommand{OrgID: 1, UID: silence.UID})
+		require.ErrorIs(t, err, models.ErrRecurringSilenceNotFound)
+	})
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// StateHistoryStore is the database interface used to persist the state transitions of alert instances.
+type StateHistoryStore interface {
+	SaveAlertStateHistory(ctx context.Context, entries []*models.AlertStateHistory) error
+	ListAlertStateHistory(ctx context.Context, query *models.ListAlertStateHistoryQuery) error
+	DeleteAlertStateHistoryOlderThan(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+// SaveAlertStateHistory inserts the provided state transitions.
+func (st DBstore) SaveAlertStateHistory(ctx context.Context, entries []*models.AlertStateHistory) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Table("alert_state_history").InsertMulti(&entries)
+		return err
+	})
+}
+
+// stateHistoryRuleUIDsBatchSize is the maximum number of rule UIDs in the IN clause of a query,
+// as databases limit the number of parameters of a statement.
+const stateHistoryRuleUIDsBatchSize = 500
+
+// ListAlertStateHistory returns the state transitions that match the query, from the most recent to the oldest.
+func (st DBstore) ListAlertStateHistory(ctx context.Context, query *models.ListAlertStateHistoryQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if len(query.RuleUIDs) <= stateHistoryRuleUIDsBatchSize {
+			result, err := listAlertStateHistory(sess, query, query.RuleUIDs)
+			if err != nil {
+				return err
+			}
+			query.Result = result
+			return nil
+		}
+
+		// the most recent transitions of all the rules are among the most recent transitions of each batch of rules
+		result := make([]*models.AlertStateHistory, 0)
+		for i := 0; i < len(query.RuleUIDs); i += stateHistoryRuleUIDsBatchSize {
+			end := i + stateHistoryRuleUIDsBatchSize
+			if end > len(query.RuleUIDs) {
+				end = len(query.RuleUIDs)
+			}
+			entries, err := listAlertStateHistory(sess, query, query.RuleUIDs[i:end])
+			if err != nil {
+				return err
+			}
+			result = append(result, entries...)
+		}
+		sort.Slice(result, func(i, j int) bool {
+			if !result[i].Created.Equal(result[j].Created) {
+				return result[i].Created.After(result[j].Created)
+			}
+			return result[i].ID > result[j].ID
+		})
+		if query.Limit > 0 && len(result) > query.Limit {
+			result = result[:query.Limit]
+		}
+		query.Result = result
+		return nil
+	})
+}
+
+// listAlertStateHistory returns the state transitions of the rules with the given UIDs that match the query,
+// from the most recent to the oldest. It returns the transitions of all the rules if ruleUIDs is empty.
+func listAlertStateHistory(sess *sqlstore.DBSession, query *models.ListAlertStateHistoryQuery, ruleUIDs []string) ([]*models.AlertStateHistory, error) {
+	result := make([]*models.AlertStateHistory, 0)
+	// labels are stored as JSON so they are matched once the rows are fetched,
+	// in batches so that the limit applies to the matching transitions only
+	for offset := 0; ; offset += query.Limit {
+		q := sess.Table("alert_state_history").Where("rule_org_id = ?", query.OrgID)
+		if len(ruleUIDs) > 0 {
+			q = q.In("rule_uid", ruleUIDs)
+		}
+		if !query.From.IsZero() {
+			q = q.And("created >= ?", query.From)
+		}
+		if !query.To.IsZero() {
+			q = q.And("created <= ?", query.To)
+		}
+		q = q.Desc("created", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit, offset)
+		}
+
+		entries := make([]*models.AlertStateHistory, 0)
+		if err := q.Find(&entries); err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !hasLabels(entry.Labels, query.Labels) {
+				continue
+			}
+			result = append(result, entry)
+			if query.Limit > 0 && len(result) == query.Limit {
+				break
+			}
+		}
+
+		if query.Limit <= 0 || len(result) == query.Limit || len(entries) < query.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// DeleteAlertStateHistoryOlderThan deletes the state transitions created before the provided time
+// and returns the number of deleted transitions.
+func (st DBstore) DeleteAlertStateHistoryOlderThan(ctx context.Context, olderThan time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE created < ?", olderThan)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
+
+func hasLabels(labels map[string]string, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertStateHistoryOperations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().Truncate(time.Second)
+	value := 1.0
+	entry := func(orgID int64, ruleUID string, labels map[string]string, state string, created time.Time) *models.AlertStateHistory {
+		return &models.AlertStateHistory{
+			RuleOrgID:        orgID,
+			RuleUID:          ruleUID,
+			Labels:           labels,
+			LabelsHash:       "hash",
+			PreviousState:    "Normal",
+			State:            state,
+			EvaluationValues: map[string]*float64{"A": &value},
+			Created:          created,
+		}
+	}
+
+	require.NoError(t, dbstore.SaveAlertStateHistory(ctx, []*models.AlertStateHistory{
+		entry(1, "rule-1", map[string]string{"instance": "a"}, "Alerting", now.Add(-3*time.Hour)),
+		entry(1, "rule-1", map[string]string{"instance": "b"}, "Alerting", now.Add(-2*time.Hour)),
+		entry(1, "rule-2", map[string]string{"instance": "a"}, "Error", now.Add(-time.Hour)),
+		entry(2, "rule-3", map[string]string{"instance": "a"}, "Alerting", now),
+	}))
+
+	t.Run("it should list the transitions of the organization newest first", func(t *testing.T) {
+		q := models.ListAlertStateHistoryQuery{OrgID: 1}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, &q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, "rule-2", q.Result[0].RuleUID)
+		require.Equal(t, "Error", q.Result[0].State)
+		require.Equal(t, map[string]string{"instance": "a"}, q.Result[0].Labels)
+		require.Equal(t, 1.0, *q.Result[0].EvaluationValues["A"])
+		require.Equal(t, "rule-1", q.Result[2].RuleUID)
+	})
+
+	t.Run("it should filter by rule, labels and time range", func(t *testing.T) {
+		q := models.ListAlertStateHistoryQuery{OrgID: 1, RuleUIDs: []string{"rule-1"}}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, &q))
+		require.Len(t, q.Result, 2)
+
+		q = models.ListAlertStateHistoryQuery{OrgID: 1, Labels: map[string]string{"instance": "a"}}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, &q))
+		require.Len(t, q.Result, 2)
+
+		q = models.ListAlertStateHistoryQuery{OrgID: 1, From: now.Add(-150 * time.Minute), To: now.Add(-90 * time.Minute)}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, &q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, map[string]string{"instance": "b"}, q.Result[0].Labels)
+	})
+
+	t.Run("it should apply the limit to the transitions that match the labels", func(t *testing.T) {
+		q := models.ListAlertStateHistoryQuery{OrgID: 1, Labels: map[string]string{"instance": "a"}, Limit: 1}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, &q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "rule-2", q.Result[0].RuleUID)
+
+		q = models.ListAlertStateHistoryQuery{OrgID: 1, Labels: map[string]string{"instance": "b"}, Limit: 1}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, &q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "rule-1", q.Result[0].RuleUID)
+	})
+
+	t.Run("it should filter by more rules than a statement can have parameters", func(t *testing.T) {
+		ruleUIDs := []string{"rule-2"}
+		for i := 0; i < 1500; i++ {
+			ruleUIDs = append(ruleUIDs, fmt.Sprintf("missing-%d", i))
+		}
+		ruleUIDs = append(ruleUIDs, "rule-1")
+
+		q := models.ListAlertStateHistoryQuery{OrgID: 1, RuleUIDs: ruleUIDs}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, &q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, "rule-2", q.Result[0].RuleUID)
+		require.Equal(t, "rule-1", q.Result[1].RuleUID)
+
+		q = models.ListAlertStateHistoryQuery{OrgID: 1, RuleUIDs: ruleUIDs, Limit: 2}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, &q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, "rule-2", q.Result[0].RuleUID)
+		require.Equal(t, map[string]string{"instance": "b"}, q.Result[1].Labels)
+	})
+
+	t.Run("it should delete the transitions older than the given time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteAlertStateHistoryOlderThan(ctx, now.Add(-90*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		q := models.ListAlertStateHistoryQuery{OrgID: 1}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, &q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "rule-2", q.Result[0].RuleUID)
+	})
+}
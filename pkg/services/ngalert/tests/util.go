@@ -42,7 +42,7 @@ func SetupTestEnv(t *testing.T, baseInterval time.Duration) (*ngalert.AlertNG, *
 	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
 	ng, err := ngalert.ProvideService(
 		cfg, nil, routing.NewRouteRegister(), sqlStore,
-		nil, nil, nil, nil, secretsService, m,
+		nil, nil, nil, nil, secretsService, nil, m,
 	)
 	require.NoError(t, err)
 	return ng, &store.DBstore{
@@ -22,6 +22,7 @@ type Message struct {
 	ReplyTo       []string
 	EmbeddedFiles []string
 	AttachedFiles []*AttachedFile
+	Relay         *models.SmtpRelay
 }
 
 func setDefaultTemplateData(cfg *setting.Cfg, data map[string]interface{}, u *models.User) {
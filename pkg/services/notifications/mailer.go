@@ -55,12 +55,12 @@ func (ns *NotificationService) Send(msg *Message) (int, error) {
 		}
 	}
 
-	return ns.dialAndSend(messages...)
+	return ns.dialAndSend(msg.Relay, messages...)
 }
 
-func (ns *NotificationService) dialAndSend(messages ...*Message) (int, error) {
+func (ns *NotificationService) dialAndSend(relay *models.SmtpRelay, messages ...*Message) (int, error) {
 	sentEmailsCount := 0
-	dialer, err := ns.createDialer()
+	dialer, err := ns.createDialer(relay)
 	if err != nil {
 		return sentEmailsCount, err
 	}
@@ -128,8 +128,21 @@ func (ns *NotificationService) setFiles(
 	}
 }
 
-func (ns *NotificationService) createDialer() (*gomail.Dialer, error) {
-	host, port, err := net.SplitHostPort(ns.Cfg.Smtp.Host)
+// createDialer creates a dialer to the SMTP server of the [smtp] settings, or to the relay when set.
+func (ns *NotificationService) createDialer(relay *models.SmtpRelay) (*gomail.Dialer, error) {
+	smtp := ns.Cfg.Smtp
+	if relay != nil {
+		smtp = setting.SmtpSettings{
+			Host:           relay.Host,
+			User:           relay.User,
+			Password:       relay.Password,
+			EhloIdentity:   ns.Cfg.Smtp.EhloIdentity,
+			StartTLSPolicy: relay.StartTLSPolicy,
+			SkipVerify:     relay.SkipVerify,
+		}
+	}
+
+	host, port, err := net.SplitHostPort(smtp.Host)
 	if err != nil {
 		return nil, err
 	}
@@ -139,24 +152,24 @@ func (ns *NotificationService) createDialer() (*gomail.Dialer, error) {
 	}
 
 	tlsconfig := &tls.Config{
-		InsecureSkipVerify: ns.Cfg.Smtp.SkipVerify,
+		InsecureSkipVerify: smtp.SkipVerify,
 		ServerName:         host,
 	}
 
-	if ns.Cfg.Smtp.CertFile != "" {
-		cert, err := tls.LoadX509KeyPair(ns.Cfg.Smtp.CertFile, ns.Cfg.Smtp.KeyFile)
+	if smtp.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(smtp.CertFile, smtp.KeyFile)
 		if err != nil {
 			return nil, fmt.Errorf("could not load cert or key file: %w", err)
 		}
 		tlsconfig.Certificates = []tls.Certificate{cert}
 	}
 
-	d := gomail.NewDialer(host, iPort, ns.Cfg.Smtp.User, ns.Cfg.Smtp.Password)
+	d := gomail.NewDialer(host, iPort, smtp.User, smtp.Password)
 	d.TLSConfig = tlsconfig
-	d.StartTLSPolicy = getStartTLSPolicy(ns.Cfg.Smtp.StartTLSPolicy)
+	d.StartTLSPolicy = getStartTLSPolicy(smtp.StartTLSPolicy)
 
-	if ns.Cfg.Smtp.EhloIdentity != "" {
-		d.LocalName = ns.Cfg.Smtp.EhloIdentity
+	if smtp.EhloIdentity != "" {
+		d.LocalName = smtp.EhloIdentity
 	} else {
 		d.LocalName = setting.InstanceName
 	}
@@ -175,7 +188,8 @@ func getStartTLSPolicy(policy string) gomail.StartTLSPolicy {
 }
 
 func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (*Message, error) {
-	if !ns.Cfg.Smtp.Enabled {
+	// a relay sends the email with its own SMTP server, even when the [smtp] settings are disabled
+	if !ns.Cfg.Smtp.Enabled && cmd.Relay == nil {
 		return nil, models.ErrSmtpNotEnabled
 	}
 
@@ -226,6 +240,9 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 	}
 
 	addr := mail.Address{Name: ns.Cfg.Smtp.FromName, Address: ns.Cfg.Smtp.FromAddress}
+	if cmd.Relay != nil {
+		addr = mail.Address{Name: cmd.Relay.FromName, Address: cmd.Relay.FromAddress}
+	}
 	return &Message{
 		To:            cmd.To,
 		SingleEmail:   cmd.SingleEmail,
@@ -235,6 +252,7 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 		EmbeddedFiles: cmd.EmbeddedFiles,
 		AttachedFiles: buildAttachedFiles(cmd.AttachedFiles),
 		ReplyTo:       cmd.ReplyTo,
+		Relay:         cmd.Relay,
 	}, nil
 }
 
@@ -2,9 +2,11 @@ package notifications
 
 import (
 	"bytes"
+	"html/template"
 	"strings"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -39,3 +41,59 @@ func TestBuildMail(t *testing.T) {
 		assert.Less(t, strings.Index(buf.String(), "Some plain text body"), strings.Index(buf.String(), "Some HTML body"))
 	})
 }
+
+func TestCreateDialer(t *testing.T) {
+	ns := &NotificationService{
+		Cfg: setting.NewCfg(),
+	}
+	ns.Cfg.Smtp.Host = "smtp.example.org:25"
+	ns.Cfg.Smtp.User = "grafana"
+
+	t.Run("should dial the SMTP server of the settings", func(t *testing.T) {
+		dialer, err := ns.createDialer(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "smtp.example.org", dialer.Host)
+		assert.Equal(t, 25, dialer.Port)
+		assert.Equal(t, "grafana", dialer.Username)
+	})
+
+	t.Run("should dial the relay when set", func(t *testing.T) {
+		dialer, err := ns.createDialer(&models.SmtpRelay{
+			Host:       "relay.example.org:587",
+			User:       "alerts",
+			Password:   "secret",
+			SkipVerify: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "relay.example.org", dialer.Host)
+		assert.Equal(t, 587, dialer.Port)
+		assert.Equal(t, "alerts", dialer.Username)
+		assert.Equal(t, "secret", dialer.Password)
+		assert.True(t, dialer.TLSConfig.InsecureSkipVerify)
+	})
+}
+
+func TestBuildEmailMessage_Relay(t *testing.T) {
+	ns := &NotificationService{
+		Cfg: setting.NewCfg(),
+	}
+	mailTemplates = template.Must(template.New("name").Funcs(template.FuncMap{
+		"Subject": subjectTemplateFunc,
+	}).Parse(`{{ define "test.html" }}Hello{{ end }}`))
+	ns.Cfg.Smtp.ContentTypes = []string{"text/html"}
+	ns.Cfg.Smtp.Enabled = false
+
+	cmd := &models.SendEmailCommand{
+		To:       []string{"to@example.org"},
+		Template: "test",
+		Subject:  "Subject",
+	}
+	_, err := ns.buildEmailMessage(cmd)
+	require.ErrorIs(t, err, models.ErrSmtpNotEnabled)
+
+	cmd.Relay = &models.SmtpRelay{Host: "relay.example.org:25", FromAddress: "alerts@example.org", FromName: "Alerts"}
+	msg, err := ns.buildEmailMessage(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, `"Alerts" <alerts@example.org>`, msg.From)
+	assert.Equal(t, cmd.Relay, msg.Relay)
+}
@@ -127,6 +127,7 @@ func (ns *NotificationService) sendEmailCommandHandlerSync(ctx context.Context,
 		EmbeddedFiles: cmd.EmbeddedFiles,
 		Subject:       cmd.Subject,
 		ReplyTo:       cmd.ReplyTo,
+		Relay:         cmd.Relay,
 	})
 
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 
 	"golang.org/x/net/context/ctxhttp"
 
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -76,6 +77,7 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 	if err != nil {
 		return err
 	}
+	models.SetWebhookResponseStatusCode(ctx, resp.StatusCode)
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			ns.log.Warn("Failed to close response body", "err", err)
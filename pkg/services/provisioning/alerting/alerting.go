@@ -0,0 +1,274 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// Provision alert rules, contact points, notification policies, templates and mute timings of the
+// Grafana managed alerting.
+func Provision(ctx context.Context, configDirectory string, ng *ngalert.AlertNG) error {
+	logger := log.New("provisioning.alerting")
+	if ng == nil || ng.IsDisabled() || ng.Store == nil {
+		logger.Debug("Unified alerting is disabled, skipping the provisioning of alerting resources")
+		return nil
+	}
+
+	ap := AlertingProvisioner{
+		log:                  logger,
+		cfgProvider:          newConfigReader(logger),
+		ruleStore:            ng.Store,
+		amStore:              ng.Store,
+		provenanceStore:      ng.Store,
+		alertmanagers:        ng.MultiOrgAlertmanager,
+		secrets:              ng.SecretsService,
+		defaultConfig:        ng.Cfg.UnifiedAlerting.DefaultConfiguration,
+		getOrCreateFolderUID: newFolderProvider(dashboards.NewProvisioningService(ng.SQLStore)),
+	}
+	return ap.applyChanges(ctx, configDirectory)
+}
+
+// alertmanagerProvider returns the Alertmanager of an organization.
+type alertmanagerProvider interface {
+	AlertmanagerFor(orgID int64) (*notifier.Alertmanager, error)
+}
+
+// AlertingProvisioner is responsible for provisioning the alerting resources.
+type AlertingProvisioner struct {
+	log                  log.Logger
+	cfgProvider          *configReader
+	ruleStore            store.RuleStore
+	amStore              store.AlertingStore
+	provenanceStore      store.ProvisioningStore
+	alertmanagers        alertmanagerProvider
+	secrets              secrets.Service
+	defaultConfig        string
+	getOrCreateFolderUID func(ctx context.Context, orgID int64, title string) (string, error)
+}
+
+func (ap *AlertingProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return err
+	}
+
+	// the files are applied together, so each Alertmanager configuration is saved once
+	cfg := &alertingAsConfig{}
+	for _, c := range configs {
+		cfg.Groups = append(cfg.Groups, c.Groups...)
+		cfg.DeleteRules = append(cfg.DeleteRules, c.DeleteRules...)
+		cfg.ContactPoints = append(cfg.ContactPoints, c.ContactPoints...)
+		cfg.DeleteContactPoints = append(cfg.DeleteContactPoints, c.DeleteContactPoints...)
+		cfg.Policies = append(cfg.Policies, c.Policies...)
+		cfg.ResetPolicies = append(cfg.ResetPolicies, c.ResetPolicies...)
+		cfg.Templates = append(cfg.Templates, c.Templates...)
+		cfg.DeleteTemplates = append(cfg.DeleteTemplates, c.DeleteTemplates...)
+		cfg.MuteTimes = append(cfg.MuteTimes, c.MuteTimes...)
+		cfg.DeleteMuteTimes = append(cfg.DeleteMuteTimes, c.DeleteMuteTimes...)
+	}
+
+	return ap.apply(ctx, cfg)
+}
+
+func (ap *AlertingProvisioner) apply(ctx context.Context, cfg *alertingAsConfig) error {
+	if err := ap.deleteRules(cfg.DeleteRules); err != nil {
+		return err
+	}
+
+	for _, group := range cfg.Groups {
+		if err := ap.provisionRuleGroup(ctx, group); err != nil {
+			return fmt.Errorf("failed to provision rule group %q of folder %q: %w", group.Name, group.Folder, err)
+		}
+	}
+
+	for _, orgCfg := range splitByOrg(cfg) {
+		if err := ap.provisionAlertmanagerConfig(ctx, orgCfg); err != nil {
+			return fmt.Errorf("failed to provision the Alertmanager configuration of organization %d: %w", orgCfg.orgID, err)
+		}
+	}
+
+	return nil
+}
+
+func (ap *AlertingProvisioner) deleteRules(rules []*deleteRuleConfig) error {
+	for _, rule := range rules {
+		ap.log.Info("Deleting alert rule", "org", rule.OrgID, "uid", rule.UID)
+		if err := ap.ruleStore.DeleteAlertRuleByUID(rule.OrgID, rule.UID); err != nil {
+			return fmt.Errorf("failed to delete alert rule %q: %w", rule.UID, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) provisionRuleGroup(ctx context.Context, group *ruleGroupFromConfig) error {
+	folderUID, err := ap.getOrCreateFolderUID(ctx, group.OrgID, group.Folder)
+	if err != nil {
+		return fmt.Errorf("failed to get or create folder: %w", err)
+	}
+
+	q := ngmodels.ListAlertRulesQuery{OrgID: group.OrgID}
+	if err := ap.ruleStore.GetOrgAlertRules(&q); err != nil {
+		return err
+	}
+	existing := make(map[string]*ngmodels.AlertRule, len(q.Result))
+	var current []*ngmodels.AlertRule
+	for _, r := range q.Result {
+		existing[r.UID] = r
+		if r.NamespaceUID == folderUID && r.RuleGroup == group.Name {
+			current = append(current, r)
+		}
+	}
+	sort.Slice(current, func(i, j int) bool {
+		return current[i].RuleGroupIndex < current[j].RuleGroupIndex
+	})
+
+	// Rules keep their group when they are updated, so the rules moved from another group are recreated.
+	var moved []string
+	for _, r := range group.Rules {
+		e, ok := existing[r.GrafanaManagedAlert.UID]
+		if !ok || (e.NamespaceUID == folderUID && e.RuleGroup == group.Name) {
+			continue
+		}
+		ap.log.Info("Moving alert rule to provisioned rule group", "org", group.OrgID, "uid", e.UID, "group", group.Name)
+		moved = append(moved, e.UID)
+	}
+
+	provenances, err := ap.provenanceStore.GetProvenances(ctx, group.OrgID, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return err
+	}
+
+	changed, err := ruleGroupChanged(current, group, provenances)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		ap.log.Debug("Provisioned rule group is up to date", "org", group.OrgID, "folder", group.Folder, "group", group.Name)
+		return nil
+	}
+
+	ap.log.Info("Provisioning rule group", "org", group.OrgID, "folder", group.Folder, "group", group.Name)
+	return ap.ruleStore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+		OrgID:        group.OrgID,
+		NamespaceUID: folderUID,
+		RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+			Name:     group.Name,
+			Interval: group.Interval,
+			Rules:    group.Rules,
+		},
+		Provenance:    ngmodels.ProvenanceFile,
+		MovedRuleUIDs: moved,
+	})
+}
+
+// ruleGroupChanged returns whether the provisioned rule group differs from the current rules of the group, in
+// their order, or whether some of them are not provisioned yet.
+func ruleGroupChanged(current []*ngmodels.AlertRule, group *ruleGroupFromConfig, provenances map[string]ngmodels.Provenance) (bool, error) {
+	if len(current) != len(group.Rules) {
+		return true, nil
+	}
+
+	for i, r := range group.Rules {
+		cur, rule := current[i], r.GrafanaManagedAlert
+		if cur.UID != rule.UID || provenances[cur.UID] != ngmodels.ProvenanceFile {
+			return true, nil
+		}
+
+		noDataState := ngmodels.NoDataState(rule.NoDataState)
+		if noDataState == "" {
+			noDataState = ngmodels.NoData
+		}
+		execErrState := ngmodels.ExecutionErrorState(rule.ExecErrState)
+		if execErrState == "" {
+			execErrState = ngmodels.AlertingErrState
+		}
+		var node apimodels.ApiRuleNode
+		if r.ApiRuleNode != nil {
+			node = *r.ApiRuleNode
+		}
+
+		if cur.Title != rule.Title ||
+			cur.Condition != rule.Condition ||
+			cur.IntervalSeconds != int64(time.Duration(group.Interval).Seconds()) ||
+			cur.NoDataState != noDataState ||
+			cur.ExecErrState != execErrState ||
+			cur.IsPaused != rule.IsPaused ||
+			cur.ComputedLabels != rule.ComputedLabels ||
+			cur.For != time.Duration(node.For) ||
+			cur.Record != node.Record ||
+			!stringMapsEqual(cur.Labels, node.Labels) ||
+			!stringMapsEqual(cur.Annotations, node.Annotations) {
+			return true, nil
+		}
+
+		curData, err := json.Marshal(cur.Data)
+		if err != nil {
+			return false, err
+		}
+		data, err := json.Marshal(rule.Data)
+		if err != nil {
+			return false, err
+		}
+		if string(curData) != string(data) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func stringMapsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// newFolderProvider returns a function that returns the UID of the folder of an organization with a title,
+// creating the folder if it doesn't exist.
+func newFolderProvider(service dashboards.DashboardProvisioningService) func(context.Context, int64, string) (string, error) {
+	return func(ctx context.Context, orgID int64, title string) (string, error) {
+		query := &models.GetDashboardQuery{Slug: models.SlugifyTitle(title), OrgId: orgID}
+		err := bus.DispatchCtx(ctx, query)
+		if err != nil && !errors.Is(err, models.ErrDashboardNotFound) {
+			return "", err
+		}
+
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			dash := &dashboards.SaveDashboardDTO{
+				Dashboard: models.NewDashboardFolder(title),
+				OrgId:     orgID,
+				Overwrite: true,
+			}
+			dbDash, err := service.SaveFolderForProvisionedDashboards(ctx, dash)
+			if err != nil {
+				return "", err
+			}
+			return dbDash.Uid, nil
+		}
+
+		if !query.Result.IsFolder {
+			return "", fmt.Errorf("%q is a dashboard, not a folder", title)
+		}
+		return query.Result.Uid, nil
+	}
+}
@@ -0,0 +1,123 @@
+package alerting
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRuleGroupChanged(t *testing.T) {
+	data := []ngmodels.AlertQuery{{
+		RefID:         "A",
+		DatasourceUID: "prometheus",
+		Model:         json.RawMessage(`{"expr":"up == 0","refId":"A"}`),
+	}}
+	group := func() *ruleGroupFromConfig {
+		return &ruleGroupFromConfig{
+			OrgID:    1,
+			Folder:   "Infrastructure",
+			Name:     "up",
+			Interval: model.Duration(time.Minute),
+			Rules: []apimodels.PostableExtendedRuleNode{{
+				ApiRuleNode: &apimodels.ApiRuleNode{
+					For:    model.Duration(5 * time.Minute),
+					Labels: map[string]string{"team": "infra"},
+				},
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+					UID:       "instance-down",
+					Title:     "Instance down",
+					Condition: "A",
+					Data:      data,
+				},
+			}},
+		}
+	}
+	current := func() []*ngmodels.AlertRule {
+		return []*ngmodels.AlertRule{{
+			UID:             "instance-down",
+			Title:           "Instance down",
+			Condition:       "A",
+			Data:            data,
+			IntervalSeconds: 60,
+			NoDataState:     ngmodels.NoData,
+			ExecErrState:    ngmodels.AlertingErrState,
+			For:             5 * time.Minute,
+			Labels:          map[string]string{"team": "infra"},
+			Annotations:     map[string]string{},
+		}}
+	}
+	provisioned := map[string]ngmodels.Provenance{"instance-down": ngmodels.ProvenanceFile}
+
+	t.Run("should not change when the rules are up to date", func(t *testing.T) {
+		changed, err := ruleGroupChanged(current(), group(), provisioned)
+		require.NoError(t, err)
+		require.False(t, changed)
+	})
+
+	t.Run("should change when the rules are not provisioned yet", func(t *testing.T) {
+		changed, err := ruleGroupChanged(current(), group(), map[string]ngmodels.Provenance{})
+		require.NoError(t, err)
+		require.True(t, changed)
+	})
+
+	t.Run("should change when the group is new", func(t *testing.T) {
+		changed, err := ruleGroupChanged(nil, group(), provisioned)
+		require.NoError(t, err)
+		require.True(t, changed)
+	})
+
+	testCases := []struct {
+		desc   string
+		update func(g *ruleGroupFromConfig)
+	}{
+		{
+			desc:   "interval",
+			update: func(g *ruleGroupFromConfig) { g.Interval = model.Duration(2 * time.Minute) },
+		},
+		{
+			desc:   "title",
+			update: func(g *ruleGroupFromConfig) { g.Rules[0].GrafanaManagedAlert.Title = "Instance is down" },
+		},
+		{
+			desc:   "no data state",
+			update: func(g *ruleGroupFromConfig) { g.Rules[0].GrafanaManagedAlert.NoDataState = apimodels.OK },
+		},
+		{
+			desc:   "labels",
+			update: func(g *ruleGroupFromConfig) { g.Rules[0].Labels = nil },
+		},
+		{
+			desc: "queries",
+			update: func(g *ruleGroupFromConfig) {
+				g.Rules[0].GrafanaManagedAlert.Data = []ngmodels.AlertQuery{{
+					RefID:         "A",
+					DatasourceUID: "prometheus",
+					Model:         json.RawMessage(`{"expr":"up == 1","refId":"A"}`),
+				}}
+			},
+		},
+		{
+			desc: "rules",
+			update: func(g *ruleGroupFromConfig) {
+				g.Rules = append(g.Rules, apimodels.PostableExtendedRuleNode{
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: "other", Title: "Other", Condition: "A", Data: data},
+				})
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("should change when the "+tc.desc+" change", func(t *testing.T) {
+			g := group()
+			tc.update(g)
+			changed, err := ruleGroupChanged(current(), g, provisioned)
+			require.NoError(t, err)
+			require.True(t, changed)
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package alerting
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/alertmanager/config"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// orgAlertmanagerConfig are the provisioned resources of the Alertmanager configuration of an organization.
+type orgAlertmanagerConfig struct {
+	orgID               int64
+	contactPoints       []*contactPointFromConfig
+	deleteContactPoints []string
+	policy              *apimodels.Route
+	resetPolicies       bool
+	templates           []*templateFromConfig
+	deleteTemplates     []string
+	muteTimes           []config.MuteTimeInterval
+	deleteMuteTimes     []string
+}
+
+// hasChanges returns whether the configuration provisions resources rather than only deleting them.
+func (c *orgAlertmanagerConfig) hasChanges() bool {
+	return len(c.contactPoints) > 0 || c.policy != nil || len(c.templates) > 0 || len(c.muteTimes) > 0
+}
+
+// splitByOrg returns the provisioned Alertmanager resources by organization, ordered by organization ID.
+func splitByOrg(cfg *alertingAsConfig) []*orgAlertmanagerConfig {
+	byOrg := make(map[int64]*orgAlertmanagerConfig)
+	get := func(orgID int64) *orgAlertmanagerConfig {
+		c, ok := byOrg[orgID]
+		if !ok {
+			c = &orgAlertmanagerConfig{orgID: orgID}
+			byOrg[orgID] = c
+		}
+		return c
+	}
+
+	for _, cp := range cfg.ContactPoints {
+		c := get(cp.OrgID)
+		c.contactPoints = append(c.contactPoints, cp)
+	}
+	for _, cp := range cfg.DeleteContactPoints {
+		c := get(cp.OrgID)
+		c.deleteContactPoints = append(c.deleteContactPoints, cp.Name)
+	}
+	for _, policy := range cfg.Policies {
+		route := policy.Route
+		get(policy.OrgID).policy = &route
+	}
+	for _, orgID := range cfg.ResetPolicies {
+		get(orgID).resetPolicies = true
+	}
+	for _, tmpl := range cfg.Templates {
+		c := get(tmpl.OrgID)
+		c.templates = append(c.templates, tmpl)
+	}
+	for _, tmpl := range cfg.DeleteTemplates {
+		c := get(tmpl.OrgID)
+		c.deleteTemplates = append(c.deleteTemplates, tmpl.Name)
+	}
+	for _, mt := range cfg.MuteTimes {
+		c := get(mt.OrgID)
+		c.muteTimes = append(c.muteTimes, mt.MuteTimeInterval)
+	}
+	for _, mt := range cfg.DeleteMuteTimes {
+		c := get(mt.OrgID)
+		c.deleteMuteTimes = append(c.deleteMuteTimes, mt.Name)
+	}
+
+	result := make([]*orgAlertmanagerConfig, 0, len(byOrg))
+	for _, c := range byOrg {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].orgID < result[j].orgID
+	})
+	return result
+}
+
+func (ap *AlertingProvisioner) provisionAlertmanagerConfig(ctx context.Context, orgCfg *orgAlertmanagerConfig) error {
+	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: orgCfg.orgID}
+	rawConfig := ap.defaultConfig
+	if err := ap.amStore.GetLatestAlertmanagerConfiguration(&query); err != nil {
+		if !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return fmt.Errorf("failed to get latest configuration: %w", err)
+		}
+		if !orgCfg.hasChanges() {
+			// there is nothing to delete
+			return nil
+		}
+	} else {
+		rawConfig = query.Result.AlertmanagerConfiguration
+	}
+
+	cfg, err := notifier.Load([]byte(rawConfig))
+	if err != nil {
+		return fmt.Errorf("failed to load latest configuration: %w", err)
+	}
+	before, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	defaultCfg, err := notifier.Load([]byte(ap.defaultConfig))
+	if err != nil {
+		return fmt.Errorf("failed to load default configuration: %w", err)
+	}
+
+	encryption := secureSettingsEncryption{
+		encrypt: func(value string) (string, error) {
+			encrypted, err := ap.secrets.Encrypt(ctx, []byte(value), secrets.WithoutScope())
+			if err != nil {
+				return "", err
+			}
+			return base64.StdEncoding.EncodeToString(encrypted), nil
+		},
+		decrypt: func(value string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return "", err
+			}
+			decrypted, err := ap.secrets.Decrypt(ctx, decoded)
+			if err != nil {
+				return "", err
+			}
+			return string(decrypted), nil
+		},
+	}
+	if err := mergeAlertmanagerConfig(cfg, defaultCfg.AlertmanagerConfig.Route, orgCfg, encryption); err != nil {
+		return err
+	}
+
+	after, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if string(before) != string(after) || query.Result == nil {
+		// the merged configuration is loaded again to be validated
+		merged, err := notifier.Load(after)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		if err := ap.saveAlertmanagerConfig(orgCfg.orgID, merged, after); err != nil {
+			return err
+		}
+	} else {
+		ap.log.Debug("Provisioned Alertmanager configuration is up to date", "org", orgCfg.orgID)
+	}
+
+	return ap.setAlertmanagerProvenances(ctx, orgCfg)
+}
+
+func (ap *AlertingProvisioner) saveAlertmanagerConfig(orgID int64, cfg *apimodels.PostableUserConfig, rawConfig []byte) error {
+	ap.log.Info("Provisioning Alertmanager configuration", "org", orgID)
+	am, err := ap.alertmanagers.AlertmanagerFor(orgID)
+	if err != nil {
+		if !errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			return err
+		}
+		// the Alertmanager of a new organization applies the latest configuration when it starts
+		return ap.amStore.SaveAlertmanagerConfiguration(&ngmodels.SaveAlertmanagerConfigurationCmd{
+			AlertmanagerConfiguration: string(rawConfig),
+			ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
+			OrgID:                     orgID,
+		})
+	}
+	return am.SaveAndApplyConfig(cfg, 0)
+}
+
+func (ap *AlertingProvisioner) setAlertmanagerProvenances(ctx context.Context, orgCfg *orgAlertmanagerConfig) error {
+	set := func(recordType, recordKey string, provenance ngmodels.Provenance) error {
+		if err := ap.provenanceStore.SetProvenance(ctx, orgCfg.orgID, recordType, recordKey, provenance); err != nil {
+			return fmt.Errorf("failed to set the provenance of %s %q: %w", recordType, recordKey, err)
+		}
+		return nil
+	}
+
+	for _, name := range orgCfg.deleteContactPoints {
+		if err := set(ngmodels.ResourceTypeContactPoint, name, ngmodels.ProvenanceNone); err != nil {
+			return err
+		}
+	}
+	for _, cp := range orgCfg.contactPoints {
+		if err := set(ngmodels.ResourceTypeContactPoint, cp.Name, ngmodels.ProvenanceFile); err != nil {
+			return err
+		}
+	}
+	if orgCfg.resetPolicies {
+		if err := set(ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyKey, ngmodels.ProvenanceNone); err != nil {
+			return err
+		}
+	}
+	if orgCfg.policy != nil {
+		if err := set(ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyKey, ngmodels.ProvenanceFile); err != nil {
+			return err
+		}
+	}
+	for _, name := range orgCfg.deleteTemplates {
+		if err := set(ngmodels.ResourceTypeTemplate, name, ngmodels.ProvenanceNone); err != nil {
+			return err
+		}
+	}
+	for _, tmpl := range orgCfg.templates {
+		if err := set(ngmodels.ResourceTypeTemplate, tmpl.Name, ngmodels.ProvenanceFile); err != nil {
+			return err
+		}
+	}
+	for _, name := range orgCfg.deleteMuteTimes {
+		if err := set(ngmodels.ResourceTypeMuteTiming, name, ngmodels.ProvenanceNone); err != nil {
+			return err
+		}
+	}
+	for _, mt := range orgCfg.muteTimes {
+		if err := set(ngmodels.ResourceTypeMuteTiming, mt.Name, ngmodels.ProvenanceFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secureSettingsEncryption encrypts and decrypts the base64 encoded secure settings of the receivers.
+type secureSettingsEncryption struct {
+	encrypt func(string) (string, error)
+	decrypt func(string) (string, error)
+}
+
+// mergeAlertmanagerConfig applies the provisioned resources of an organization to its Alertmanager configuration.
+// The deletions are applied first. The secure settings of the provisioned receivers are encrypted, unless they are
+// unchanged, in which case the stored values are kept so that an unchanged configuration remains the same.
+func mergeAlertmanagerConfig(cfg *apimodels.PostableUserConfig, defaultRoute *apimodels.Route, orgCfg *orgAlertmanagerConfig, encryption secureSettingsEncryption) error {
+	amCfg := &cfg.AlertmanagerConfig
+
+	for _, name := range orgCfg.deleteContactPoints {
+		for i, recv := range amCfg.Receivers {
+			if recv.Name == name {
+				amCfg.Receivers = append(amCfg.Receivers[:i], amCfg.Receivers[i+1:]...)
+				break
+			}
+		}
+	}
+	for _, cp := range orgCfg.contactPoints {
+		var current *apimodels.PostableApiReceiver
+		index := -1
+		for i, recv := range amCfg.Receivers {
+			if recv.Name == cp.Name {
+				current, index = recv, i
+				break
+			}
+		}
+		recv, err := toPostableApiReceiver(cp, current, encryption)
+		if err != nil {
+			return fmt.Errorf("failed to provision contact point %q: %w", cp.Name, err)
+		}
+		if index >= 0 {
+			amCfg.Receivers[index] = recv
+		} else {
+			amCfg.Receivers = append(amCfg.Receivers, recv)
+		}
+	}
+
+	if orgCfg.resetPolicies {
+		amCfg.Route = defaultRoute
+	}
+	if orgCfg.policy != nil {
+		amCfg.Route = orgCfg.policy
+	}
+
+	for _, name := range orgCfg.deleteTemplates {
+		delete(cfg.TemplateFiles, name)
+	}
+	for _, tmpl := range orgCfg.templates {
+		if cfg.TemplateFiles == nil {
+			cfg.TemplateFiles = make(map[string]string)
+		}
+		cfg.TemplateFiles[tmpl.Name] = tmpl.Template
+	}
+
+	for _, name := range orgCfg.deleteMuteTimes {
+		for i, mt := range amCfg.MuteTimeIntervals {
+			if mt.Name == name {
+				amCfg.MuteTimeIntervals = append(amCfg.MuteTimeIntervals[:i], amCfg.MuteTimeIntervals[i+1:]...)
+				break
+			}
+		}
+	}
+	for _, mt := range orgCfg.muteTimes {
+		found := false
+		for i := range amCfg.MuteTimeIntervals {
+			if amCfg.MuteTimeIntervals[i].Name == mt.Name {
+				amCfg.MuteTimeIntervals[i] = mt
+				found = true
+				break
+			}
+		}
+		if !found {
+			amCfg.MuteTimeIntervals = append(amCfg.MuteTimeIntervals, mt)
+		}
+	}
+
+	return nil
+}
+
+func toPostableApiReceiver(cp *contactPointFromConfig, current *apimodels.PostableApiReceiver, encryption secureSettingsEncryption) (*apimodels.PostableApiReceiver, error) {
+	currentReceivers := make(map[string]*apimodels.PostableGrafanaReceiver)
+	if current != nil {
+		for _, gr := range current.GrafanaManagedReceivers {
+			currentReceivers[gr.UID] = gr
+		}
+	}
+
+	recv := &apimodels.PostableApiReceiver{}
+	recv.Name = cp.Name
+	for _, r := range cp.Receivers {
+		gr := &apimodels.PostableGrafanaReceiver{
+			UID:                   r.UID,
+			Name:                  cp.Name,
+			Type:                  r.Type,
+			DisableResolveMessage: r.DisableResolveMessage,
+			Settings:              r.SettingsToJSON(),
+			SecureSettings:        make(map[string]string, len(r.SecureSettings)),
+		}
+		currentReceiver := currentReceivers[r.UID]
+		for k, v := range r.SecureSettings {
+			if currentReceiver != nil {
+				if stored, ok := currentReceiver.SecureSettings[k]; ok {
+					decrypted, err := encryption.decrypt(stored)
+					if err != nil {
+						return nil, fmt.Errorf("failed to decrypt stored secure setting %s: %w", k, err)
+					}
+					if decrypted == v {
+						gr.SecureSettings[k] = stored
+						continue
+					}
+				}
+			}
+			encrypted, err := encryption.encrypt(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt secure setting %s: %w", k, err)
+			}
+			gr.SecureSettings[k] = encrypted
+		}
+		recv.GrafanaManagedReceivers = append(recv.GrafanaManagedReceivers, gr)
+	}
+	return recv, nil
+}
@@ -0,0 +1,170 @@
+package alerting
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+const testConfig = `{
+	"template_files": {
+		"legacy.tmpl": "{{ define \"legacy\" }}{{ end }}"
+	},
+	"alertmanager_config": {
+		"route": {
+			"receiver": "legacy-email",
+			"routes": [{"receiver": "infra-slack"}]
+		},
+		"mute_time_intervals": [
+			{"name": "holidays", "time_intervals": [{"months": ["december"]}]}
+		],
+		"receivers": [
+			{
+				"name": "legacy-email",
+				"grafana_managed_receiver_configs": [
+					{"uid": "legacy-email", "name": "legacy-email", "type": "email", "settings": {"addresses": "legacy@example.com"}}
+				]
+			},
+			{
+				"name": "infra-slack",
+				"grafana_managed_receiver_configs": [
+					{"uid": "infra-slack", "name": "infra-slack", "type": "slack", "settings": {"recipient": "#old"}, "secureSettings": {"url": "enc:https://hooks.slack.com/secret"}}
+				]
+			}
+		]
+	}
+}`
+
+var testEncryption = secureSettingsEncryption{
+	encrypt: func(value string) (string, error) {
+		return "enc:" + value, nil
+	},
+	decrypt: func(value string) (string, error) {
+		if !strings.HasPrefix(value, "enc:") {
+			return "", errors.New("not encrypted")
+		}
+		return strings.TrimPrefix(value, "enc:"), nil
+	},
+}
+
+func TestMergeAlertmanagerConfig(t *testing.T) {
+	defaultRoute := &apimodels.Route{Receiver: "default-email"}
+	infraSlack := &contactPointFromConfig{
+		OrgID: 1,
+		Name:  "infra-slack",
+		Receivers: []*receiverFromConfig{{
+			UID:            "infra-slack",
+			Type:           "slack",
+			Settings:       map[string]interface{}{"recipient": "#infra"},
+			SecureSettings: map[string]string{"url": "https://hooks.slack.com/secret", "token": "xoxb"},
+		}},
+	}
+
+	t.Run("should apply the deletions and the provisioned resources", func(t *testing.T) {
+		cfg, err := notifier.Load([]byte(testConfig))
+		require.NoError(t, err)
+
+		orgCfg := &orgAlertmanagerConfig{
+			orgID:               1,
+			contactPoints:       []*contactPointFromConfig{infraSlack},
+			deleteContactPoints: []string{"legacy-email"},
+			policy:              &apimodels.Route{Receiver: "infra-slack", MuteTimeIntervals: []string{"weekends"}},
+			templates:           []*templateFromConfig{{OrgID: 1, Name: "infra.tmpl", Template: "{{ define \"infra\" }}{{ end }}"}},
+			deleteTemplates:     []string{"legacy.tmpl"},
+			muteTimes:           []config.MuteTimeInterval{{Name: "weekends"}},
+			deleteMuteTimes:     []string{"holidays"},
+		}
+		require.NoError(t, mergeAlertmanagerConfig(cfg, defaultRoute, orgCfg, testEncryption))
+
+		require.Equal(t, map[string]string{"infra.tmpl": "{{ define \"infra\" }}{{ end }}"}, cfg.TemplateFiles)
+		require.Equal(t, orgCfg.policy, cfg.AlertmanagerConfig.Route)
+		require.Equal(t, []config.MuteTimeInterval{{Name: "weekends"}}, cfg.AlertmanagerConfig.MuteTimeIntervals)
+		require.Len(t, cfg.AlertmanagerConfig.Receivers, 1)
+		recv := cfg.AlertmanagerConfig.Receivers[0]
+		require.Equal(t, "infra-slack", recv.Name)
+		require.Len(t, recv.GrafanaManagedReceivers, 1)
+		gr := recv.GrafanaManagedReceivers[0]
+		require.Equal(t, "infra-slack", gr.UID)
+		require.Equal(t, "infra-slack", gr.Name)
+		require.Equal(t, "slack", gr.Type)
+		require.Equal(t, "#infra", gr.Settings.Get("recipient").MustString())
+		require.Equal(t, map[string]string{"url": "enc:https://hooks.slack.com/secret", "token": "enc:xoxb"}, gr.SecureSettings)
+
+		// the merged configuration is valid
+		b, err := json.Marshal(cfg)
+		require.NoError(t, err)
+		_, err = notifier.Load(b)
+		require.NoError(t, err)
+	})
+
+	t.Run("should keep the stored secure settings when they are unchanged", func(t *testing.T) {
+		cfg, err := notifier.Load([]byte(testConfig))
+		require.NoError(t, err)
+
+		encryption := testEncryption
+		encryption.encrypt = func(value string) (string, error) {
+			// the encryption is not deterministic
+			return "enc:" + value + "#salt", nil
+		}
+		encryption.decrypt = func(value string) (string, error) {
+			return strings.TrimSuffix(strings.TrimPrefix(value, "enc:"), "#salt"), nil
+		}
+
+		orgCfg := &orgAlertmanagerConfig{orgID: 1, contactPoints: []*contactPointFromConfig{infraSlack}}
+		require.NoError(t, mergeAlertmanagerConfig(cfg, defaultRoute, orgCfg, encryption))
+		require.Equal(t, map[string]string{"url": "enc:https://hooks.slack.com/secret", "token": "enc:xoxb#salt"}, cfg.AlertmanagerConfig.Receivers[1].GrafanaManagedReceivers[0].SecureSettings)
+
+		// applying the same resources again does not change the configuration
+		before, err := json.Marshal(cfg)
+		require.NoError(t, err)
+		require.NoError(t, mergeAlertmanagerConfig(cfg, defaultRoute, orgCfg, encryption))
+		after, err := json.Marshal(cfg)
+		require.NoError(t, err)
+		require.JSONEq(t, string(before), string(after))
+	})
+
+	t.Run("should reset the notification policies", func(t *testing.T) {
+		cfg, err := notifier.Load([]byte(testConfig))
+		require.NoError(t, err)
+
+		require.NoError(t, mergeAlertmanagerConfig(cfg, defaultRoute, &orgAlertmanagerConfig{orgID: 1, resetPolicies: true}, testEncryption))
+		require.Equal(t, defaultRoute, cfg.AlertmanagerConfig.Route)
+	})
+
+	t.Run("should fail when a stored secure setting cannot be decrypted", func(t *testing.T) {
+		cfg, err := notifier.Load([]byte(testConfig))
+		require.NoError(t, err)
+		cfg.AlertmanagerConfig.Receivers[1].GrafanaManagedReceivers[0].SecureSettings["url"] = "plain"
+
+		err = mergeAlertmanagerConfig(cfg, defaultRoute, &orgAlertmanagerConfig{orgID: 1, contactPoints: []*contactPointFromConfig{infraSlack}}, testEncryption)
+		require.EqualError(t, err, `failed to provision contact point "infra-slack": failed to decrypt stored secure setting url: not encrypted`)
+	})
+}
+
+func TestSplitByOrg(t *testing.T) {
+	cfg := &alertingAsConfig{
+		ContactPoints:   []*contactPointFromConfig{{OrgID: 2, Name: "a"}, {OrgID: 1, Name: "b"}},
+		Policies:        []*policyFromConfig{{OrgID: 2, Route: apimodels.Route{Receiver: "a"}}},
+		ResetPolicies:   []int64{3},
+		DeleteTemplates: []*deleteConfig{{OrgID: 1, Name: "c"}},
+	}
+
+	orgs := splitByOrg(cfg)
+	require.Len(t, orgs, 3)
+	require.Equal(t, int64(1), orgs[0].orgID)
+	require.Equal(t, "b", orgs[0].contactPoints[0].Name)
+	require.Equal(t, []string{"c"}, orgs[0].deleteTemplates)
+	require.Nil(t, orgs[0].policy)
+	require.Equal(t, int64(2), orgs[1].orgID)
+	require.Equal(t, "a", orgs[1].policy.Receiver)
+	require.Equal(t, int64(3), orgs[2].orgID)
+	require.True(t, orgs[2].resetPolicies)
+	require.False(t, orgs[2].hasChanges())
+}
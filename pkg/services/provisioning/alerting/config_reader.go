@@ -0,0 +1,325 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+type configReader struct {
+	log log.Logger
+	// orgExists checks that an organization exists, it's replaced in tests.
+	orgExists func(ctx context.Context, orgID int64) error
+}
+
+func (cr *configReader) readConfig(ctx context.Context, path string) ([]*alertingAsConfig, error) {
+	var configs []*alertingAsConfig
+	cr.log.Debug("Looking for alerting provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read alerting provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing alerting provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseConfig(path, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating alerting provisioning files")
+	if err := cr.validateRequiredFields(configs); err != nil {
+		return nil, err
+	}
+
+	if err := cr.checkOrgIDs(ctx, configs); err != nil {
+		return nil, err
+	}
+
+	if err := validateDuplicates(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseConfig(path string, file os.FileInfo) (*alertingAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rules are decoded with yaml.v3, which supports inlining the Prometheus style properties of the rules.
+	var cfg *alertingAsConfigV1
+	dec := yaml.NewDecoder(bytes.NewReader(yamlFile))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, nil
+	}
+
+	if cfg.APIVersion.Value() != 1 {
+		return nil, fmt.Errorf("unsupported apiVersion %q, the supported version is 1", cfg.APIVersion.Raw)
+	}
+
+	return cfg.mapToAlertingAsConfig(), nil
+}
+
+func (cr *configReader) validateRequiredFields(configs []*alertingAsConfig) error {
+	for _, cfg := range configs {
+		var errStrings []string
+		for i, group := range cfg.Groups {
+			if group.Name == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Rule group %d in configuration doesn't contain required field name", i+1))
+			}
+			if group.Folder == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Rule group %d in configuration doesn't contain required field folder", i+1))
+			}
+			if group.Interval <= 0 {
+				errStrings = append(errStrings, fmt.Sprintf("Rule group %d in configuration doesn't contain required field interval", i+1))
+			}
+			for j, rule := range group.Rules {
+				if rule.GrafanaManagedAlert == nil {
+					errStrings = append(errStrings, fmt.Sprintf("Rule %d of rule group %d in configuration is not a Grafana managed rule", j+1, i+1))
+					continue
+				}
+				if rule.GrafanaManagedAlert.UID == "" {
+					errStrings = append(errStrings, fmt.Sprintf("Rule %d of rule group %d in configuration doesn't contain required field uid", j+1, i+1))
+				}
+				if rule.GrafanaManagedAlert.Title == "" {
+					errStrings = append(errStrings, fmt.Sprintf("Rule %d of rule group %d in configuration doesn't contain required field title", j+1, i+1))
+				}
+				if rule.ApiRuleNode != nil && (rule.ApiRuleNode.Alert != "" || rule.ApiRuleNode.Expr != "") {
+					errStrings = append(errStrings, fmt.Sprintf("Rule %d of rule group %d in configuration cannot have both Prometheus style rules and Grafana rules together", j+1, i+1))
+				}
+			}
+		}
+
+		for i, rule := range cfg.DeleteRules {
+			if rule.UID == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Deleted rule %d in configuration doesn't contain required field uid", i+1))
+			}
+		}
+
+		for i, cp := range cfg.ContactPoints {
+			if cp.Name == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Contact point %d in configuration doesn't contain required field name", i+1))
+			}
+			if len(cp.Receivers) == 0 {
+				errStrings = append(errStrings, fmt.Sprintf("Contact point %d in configuration doesn't contain required field receivers", i+1))
+			}
+			for j, recv := range cp.Receivers {
+				if recv.UID == "" {
+					errStrings = append(errStrings, fmt.Sprintf("Receiver %d of contact point %d in configuration doesn't contain required field uid", j+1, i+1))
+				}
+				if recv.Type == "" {
+					errStrings = append(errStrings, fmt.Sprintf("Receiver %d of contact point %d in configuration doesn't contain required field type", j+1, i+1))
+				}
+			}
+		}
+
+		for i, policy := range cfg.Policies {
+			if policy.Route.Receiver == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Policy %d in configuration doesn't contain required field receiver", i+1))
+			}
+		}
+
+		for i, tmpl := range cfg.Templates {
+			if tmpl.Name == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Template %d in configuration doesn't contain required field name", i+1))
+			}
+			if tmpl.Template == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Template %d in configuration doesn't contain required field template", i+1))
+			}
+		}
+
+		for i, mt := range cfg.MuteTimes {
+			if mt.MuteTimeInterval.Name == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Mute timing %d in configuration doesn't contain required field name", i+1))
+			}
+		}
+
+		for _, deleted := range []struct {
+			kind  string
+			items []*deleteConfig
+		}{
+			{"contact point", cfg.DeleteContactPoints},
+			{"template", cfg.DeleteTemplates},
+			{"mute timing", cfg.DeleteMuteTimes},
+		} {
+			for i, item := range deleted.items {
+				if item.Name == "" {
+					errStrings = append(errStrings, fmt.Sprintf("Deleted %s %d in configuration doesn't contain required field name", deleted.kind, i+1))
+				}
+			}
+		}
+
+		if len(errStrings) != 0 {
+			return errors.New(strings.Join(errStrings, "\n"))
+		}
+	}
+
+	return nil
+}
+
+// checkOrgIDs defaults the organization IDs to the main organization and checks that the organizations of the
+// provisioned resources exist.
+func (cr *configReader) checkOrgIDs(ctx context.Context, configs []*alertingAsConfig) error {
+	checked := make(map[int64]struct{})
+	check := func(orgID *int64, resource string) error {
+		if *orgID < 1 {
+			*orgID = 1
+		}
+		if _, ok := checked[*orgID]; ok {
+			return nil
+		}
+		if err := cr.orgExists(ctx, *orgID); err != nil {
+			return fmt.Errorf("failed to provision %s: %w", resource, err)
+		}
+		checked[*orgID] = struct{}{}
+		return nil
+	}
+
+	for _, cfg := range configs {
+		for _, group := range cfg.Groups {
+			if err := check(&group.OrgID, fmt.Sprintf("rule group %q", group.Name)); err != nil {
+				return err
+			}
+		}
+		for _, cp := range cfg.ContactPoints {
+			if err := check(&cp.OrgID, fmt.Sprintf("contact point %q", cp.Name)); err != nil {
+				return err
+			}
+		}
+		for _, policy := range cfg.Policies {
+			if err := check(&policy.OrgID, "notification policies"); err != nil {
+				return err
+			}
+		}
+		for _, tmpl := range cfg.Templates {
+			if err := check(&tmpl.OrgID, fmt.Sprintf("template %q", tmpl.Name)); err != nil {
+				return err
+			}
+		}
+		for _, mt := range cfg.MuteTimes {
+			if err := check(&mt.OrgID, fmt.Sprintf("mute timing %q", mt.MuteTimeInterval.Name)); err != nil {
+				return err
+			}
+		}
+
+		// deleted resources of organizations that don't exist are ignored
+		for _, rule := range cfg.DeleteRules {
+			if rule.OrgID < 1 {
+				rule.OrgID = 1
+			}
+		}
+		for _, items := range [][]*deleteConfig{cfg.DeleteContactPoints, cfg.DeleteTemplates, cfg.DeleteMuteTimes} {
+			for _, item := range items {
+				if item.OrgID < 1 {
+					item.OrgID = 1
+				}
+			}
+		}
+		for i, orgID := range cfg.ResetPolicies {
+			if orgID < 1 {
+				cfg.ResetPolicies[i] = 1
+			}
+		}
+	}
+	return nil
+}
+
+// validateDuplicates checks that each resource is provisioned once across all the provisioning files.
+func validateDuplicates(configs []*alertingAsConfig) error {
+	type key struct {
+		orgID int64
+		name  string
+	}
+	groups := make(map[key]struct{})
+	rules := make(map[key]struct{})
+	contactPoints := make(map[key]struct{})
+	policies := make(map[int64]struct{})
+	templates := make(map[key]struct{})
+	muteTimes := make(map[key]struct{})
+
+	for _, cfg := range configs {
+		for _, group := range cfg.Groups {
+			k := key{group.OrgID, group.Folder + "/" + group.Name}
+			if _, ok := groups[k]; ok {
+				return fmt.Errorf("rule group %q of folder %q is provisioned more than once", group.Name, group.Folder)
+			}
+			groups[k] = struct{}{}
+			for _, rule := range group.Rules {
+				k := key{group.OrgID, rule.GrafanaManagedAlert.UID}
+				if _, ok := rules[k]; ok {
+					return fmt.Errorf("rule %q is provisioned more than once", rule.GrafanaManagedAlert.UID)
+				}
+				rules[k] = struct{}{}
+			}
+		}
+		for _, cp := range cfg.ContactPoints {
+			k := key{cp.OrgID, cp.Name}
+			if _, ok := contactPoints[k]; ok {
+				return fmt.Errorf("contact point %q is provisioned more than once", cp.Name)
+			}
+			contactPoints[k] = struct{}{}
+		}
+		for _, policy := range cfg.Policies {
+			if _, ok := policies[policy.OrgID]; ok {
+				return fmt.Errorf("notification policies of organization %d are provisioned more than once", policy.OrgID)
+			}
+			policies[policy.OrgID] = struct{}{}
+		}
+		for _, tmpl := range cfg.Templates {
+			k := key{tmpl.OrgID, tmpl.Name}
+			if _, ok := templates[k]; ok {
+				return fmt.Errorf("template %q is provisioned more than once", tmpl.Name)
+			}
+			templates[k] = struct{}{}
+		}
+		for _, mt := range cfg.MuteTimes {
+			k := key{mt.OrgID, mt.MuteTimeInterval.Name}
+			if _, ok := muteTimes[k]; ok {
+				return fmt.Errorf("mute timing %q is provisioned more than once", mt.MuteTimeInterval.Name)
+			}
+			muteTimes[k] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func newConfigReader(log log.Logger) *configReader {
+	return &configReader{
+		log:       log,
+		orgExists: utils.CheckOrgExists,
+	}
+}
@@ -0,0 +1,160 @@
+package alerting
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	correctProperties  = "./testdata/test-configs/correct-properties"
+	noRequiredFields   = "./testdata/test-configs/no-required-fields"
+	duplicates         = "./testdata/test-configs/duplicates"
+	unsupportedVersion = "./testdata/test-configs/unsupported-version"
+	brokenYaml         = "./testdata/test-configs/broken-yaml"
+	emptyFile          = "./testdata/test-configs/empty"
+	missingFolder      = "./testdata/test-configs/missing"
+)
+
+func newTestConfigReader(orgs ...int64) *configReader {
+	return &configReader{
+		log: log.New("test logger"),
+		orgExists: func(_ context.Context, orgID int64) error {
+			for _, o := range orgs {
+				if o == orgID {
+					return nil
+				}
+			}
+			return models.ErrOrgNotFound
+		},
+	}
+}
+
+func TestAlertingAsConfig(t *testing.T) {
+	t.Run("Can read correct properties", func(t *testing.T) {
+		t.Setenv("FOLDER", "Infrastructure")
+		t.Setenv("USER", "oncall")
+		t.Setenv("WEBHOOK_URL", "https://hooks.slack.com/secret")
+
+		cfgs, err := newTestConfigReader(1, 2).readConfig(context.Background(), correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfgs, 1)
+		cfg := cfgs[0]
+
+		require.Len(t, cfg.Groups, 1)
+		group := cfg.Groups[0]
+		require.Equal(t, int64(2), group.OrgID)
+		require.Equal(t, "Infrastructure", group.Folder)
+		require.Equal(t, "cpu", group.Name)
+		require.Equal(t, model.Duration(time.Minute), group.Interval)
+		require.Len(t, group.Rules, 1)
+		rule := group.Rules[0]
+		require.Equal(t, "high-cpu", rule.GrafanaManagedAlert.UID)
+		require.Equal(t, "High CPU usage", rule.GrafanaManagedAlert.Title)
+		require.Equal(t, apimodels.OK, rule.GrafanaManagedAlert.NoDataState)
+		require.Len(t, rule.GrafanaManagedAlert.Data, 1)
+		require.Equal(t, "prometheus", rule.GrafanaManagedAlert.Data[0].DatasourceUID)
+		require.JSONEq(t, `{"expr":"avg(rate(node_cpu_seconds_total{mode!=\"idle\"}[5m])) > 0.9","refId":"A"}`, string(rule.GrafanaManagedAlert.Data[0].Model))
+		require.Equal(t, model.Duration(5*time.Minute), rule.For)
+		require.Equal(t, map[string]string{"team": "infra"}, rule.Labels)
+		// the rules are not interpolated
+		require.Equal(t, map[string]string{"summary": "{{ $labels.instance }} is busy"}, rule.Annotations)
+
+		require.Equal(t, []*deleteRuleConfig{{OrgID: 1, UID: "legacy-cpu"}}, cfg.DeleteRules)
+
+		require.Len(t, cfg.ContactPoints, 1)
+		cp := cfg.ContactPoints[0]
+		require.Equal(t, int64(1), cp.OrgID)
+		require.Equal(t, "infra-slack", cp.Name)
+		require.Len(t, cp.Receivers, 1)
+		recv := cp.Receivers[0]
+		require.Equal(t, "infra-slack", recv.UID)
+		require.Equal(t, "slack", recv.Type)
+		require.True(t, recv.DisableResolveMessage)
+		require.Equal(t, map[string]interface{}{
+			"recipient": "#infra",
+			"mentions":  map[string]interface{}{"users": []interface{}{"oncall"}},
+		}, recv.Settings)
+		require.Equal(t, map[string]string{"url": "https://hooks.slack.com/secret"}, recv.SecureSettings)
+
+		require.Equal(t, []*deleteConfig{{OrgID: 2, Name: "legacy-slack"}}, cfg.DeleteContactPoints)
+
+		require.Len(t, cfg.Policies, 1)
+		policy := cfg.Policies[0]
+		require.Equal(t, int64(1), policy.OrgID)
+		require.Equal(t, "infra-slack", policy.Route.Receiver)
+		require.Equal(t, []model.LabelName{"alertname"}, policy.Route.GroupBy)
+		require.Len(t, policy.Route.Routes, 1)
+		require.Len(t, policy.Route.Routes[0].ObjectMatchers, 1)
+		require.Equal(t, []string{"weekends"}, policy.Route.Routes[0].MuteTimeIntervals)
+
+		require.Equal(t, []int64{2}, cfg.ResetPolicies)
+
+		require.Equal(t, []*templateFromConfig{{OrgID: 1, Name: "infra.tmpl", Template: `{{ define "infra.title" }}{{ $labels }}{{ end }}`}}, cfg.Templates)
+		require.Equal(t, []*deleteConfig{{OrgID: 1, Name: "legacy.tmpl"}}, cfg.DeleteTemplates)
+
+		require.Len(t, cfg.MuteTimes, 1)
+		require.Equal(t, int64(1), cfg.MuteTimes[0].OrgID)
+		require.Equal(t, "weekends", cfg.MuteTimes[0].MuteTimeInterval.Name)
+		require.Len(t, cfg.MuteTimes[0].MuteTimeInterval.TimeIntervals, 1)
+		require.Equal(t, []*deleteConfig{{OrgID: 1, Name: "holidays"}}, cfg.DeleteMuteTimes)
+	})
+
+	t.Run("Should fail if an organization does not exist", func(t *testing.T) {
+		t.Setenv("FOLDER", "Infrastructure")
+		_, err := newTestConfigReader(1).readConfig(context.Background(), correctProperties)
+		require.ErrorIs(t, err, models.ErrOrgNotFound)
+	})
+
+	t.Run("Should fail on missing required fields", func(t *testing.T) {
+		_, err := newTestConfigReader(1).readConfig(context.Background(), noRequiredFields)
+		require.EqualError(t, err, "Rule group 1 in configuration doesn't contain required field folder\n"+
+			"Rule group 1 in configuration doesn't contain required field interval\n"+
+			"Rule 1 of rule group 1 in configuration doesn't contain required field uid\n"+
+			"Rule 2 of rule group 1 in configuration is not a Grafana managed rule\n"+
+			"Receiver 1 of contact point 1 in configuration doesn't contain required field uid\n"+
+			"Deleted template 1 in configuration doesn't contain required field name")
+	})
+
+	t.Run("Should fail on resources provisioned more than once", func(t *testing.T) {
+		_, err := newTestConfigReader(1).readConfig(context.Background(), duplicates)
+		require.EqualError(t, err, `contact point "infra-slack" is provisioned more than once`)
+	})
+
+	t.Run("Should fail on unsupported version", func(t *testing.T) {
+		_, err := newTestConfigReader(1).readConfig(context.Background(), unsupportedVersion)
+		require.EqualError(t, err, `failed to parse alerting.yaml: unsupported apiVersion "2", the supported version is 1`)
+	})
+
+	t.Run("Should fail on unknown fields", func(t *testing.T) {
+		_, err := newTestConfigReader(1).readConfig(context.Background(), brokenYaml)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field receiver not found")
+	})
+
+	t.Run("Empty file should not return error", func(t *testing.T) {
+		cfgs, err := newTestConfigReader(1).readConfig(context.Background(), emptyFile)
+		require.NoError(t, err)
+		require.Empty(t, cfgs)
+	})
+
+	t.Run("Missing folder should not return error", func(t *testing.T) {
+		cfgs, err := newTestConfigReader(1).readConfig(context.Background(), missingFolder)
+		require.NoError(t, err)
+		require.Empty(t, cfgs)
+	})
+
+	t.Run("Should fail when the environment variable of a required field is not set", func(t *testing.T) {
+		require.NoError(t, os.Unsetenv("FOLDER"))
+		_, err := newTestConfigReader(1, 2).readConfig(context.Background(), correctProperties)
+		require.EqualError(t, err, "Rule group 1 in configuration doesn't contain required field folder")
+	})
+}
@@ -0,0 +1,259 @@
+package alerting
+
+import (
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// alertingAsConfig is normalized data object for the alerting config data. Any config version should be mappable
+// to this type.
+type alertingAsConfig struct {
+	Groups              []*ruleGroupFromConfig
+	DeleteRules         []*deleteRuleConfig
+	ContactPoints       []*contactPointFromConfig
+	DeleteContactPoints []*deleteConfig
+	Policies            []*policyFromConfig
+	ResetPolicies       []int64
+	Templates           []*templateFromConfig
+	DeleteTemplates     []*deleteConfig
+	MuteTimes           []*muteTimeFromConfig
+	DeleteMuteTimes     []*deleteConfig
+}
+
+type ruleGroupFromConfig struct {
+	OrgID    int64
+	Folder   string
+	Name     string
+	Interval model.Duration
+	Rules    []apimodels.PostableExtendedRuleNode
+}
+
+type deleteRuleConfig struct {
+	OrgID int64
+	UID   string
+}
+
+type contactPointFromConfig struct {
+	OrgID     int64
+	Name      string
+	Receivers []*receiverFromConfig
+}
+
+type receiverFromConfig struct {
+	UID                   string
+	Type                  string
+	DisableResolveMessage bool
+	Settings              map[string]interface{}
+	SecureSettings        map[string]string
+}
+
+type policyFromConfig struct {
+	OrgID int64
+	Route apimodels.Route
+}
+
+type templateFromConfig struct {
+	OrgID    int64
+	Name     string
+	Template string
+}
+
+type muteTimeFromConfig struct {
+	OrgID            int64
+	MuteTimeInterval config.MuteTimeInterval
+}
+
+// deleteConfig is a contact point, template or mute timing to delete.
+type deleteConfig struct {
+	OrgID int64
+	Name  string
+}
+
+func (r receiverFromConfig) SettingsToJSON() *simplejson.Json {
+	settings := simplejson.New()
+	for k, v := range r.Settings {
+		settings.Set(k, v)
+	}
+	return settings
+}
+
+// alertingAsConfigV1 is mapping for the first version of the configs. This is mapped to its normalised version.
+// Environment variables are expanded in the organization IDs, names and contact point settings, but not in the
+// rules, notification policies, templates and mute timings, which commonly use $ in their own templating.
+type alertingAsConfigV1 struct {
+	APIVersion          values.Int64Value   `yaml:"apiVersion"`
+	Groups              []*ruleGroupV1      `yaml:"groups"`
+	DeleteRules         []*deleteRuleV1     `yaml:"deleteRules"`
+	ContactPoints       []*contactPointV1   `yaml:"contactPoints"`
+	DeleteContactPoints []*deleteV1         `yaml:"deleteContactPoints"`
+	Policies            []*policyV1         `yaml:"policies"`
+	ResetPolicies       []values.Int64Value `yaml:"resetPolicies"`
+	Templates           []*templateV1       `yaml:"templates"`
+	DeleteTemplates     []*deleteV1         `yaml:"deleteTemplates"`
+	MuteTimes           []*muteTimeV1       `yaml:"muteTimes"`
+	DeleteMuteTimes     []*deleteV1         `yaml:"deleteMuteTimes"`
+}
+
+type ruleGroupV1 struct {
+	OrgID    values.Int64Value                    `yaml:"orgId"`
+	Folder   values.StringValue                   `yaml:"folder"`
+	Name     values.StringValue                   `yaml:"name"`
+	Interval model.Duration                       `yaml:"interval"`
+	Rules    []apimodels.PostableExtendedRuleNode `yaml:"rules"`
+}
+
+type deleteRuleV1 struct {
+	OrgID values.Int64Value  `yaml:"orgId"`
+	UID   values.StringValue `yaml:"uid"`
+}
+
+type contactPointV1 struct {
+	OrgID     values.Int64Value  `yaml:"orgId"`
+	Name      values.StringValue `yaml:"name"`
+	Receivers []*receiverV1      `yaml:"receivers"`
+}
+
+type receiverV1 struct {
+	UID                   values.StringValue    `yaml:"uid"`
+	Type                  values.StringValue    `yaml:"type"`
+	DisableResolveMessage values.BoolValue      `yaml:"disableResolveMessage"`
+	Settings              values.JSONValue      `yaml:"settings"`
+	SecureSettings        values.StringMapValue `yaml:"secureSettings"`
+}
+
+// policyV1 is the notification policy tree of an organization. The root route is inlined next to the
+// organization ID.
+type policyV1 struct {
+	OrgID values.Int64Value
+	Route apimodels.Route
+}
+
+func (p *policyV1) UnmarshalYAML(value *yaml.Node) error {
+	var org orgV1
+	if err := value.Decode(&org); err != nil {
+		return err
+	}
+	p.OrgID = org.OrgID
+	return value.Decode(&p.Route)
+}
+
+type templateV1 struct {
+	OrgID    values.Int64Value  `yaml:"orgId"`
+	Name     values.StringValue `yaml:"name"`
+	Template string             `yaml:"template"`
+}
+
+// muteTimeV1 is a mute timing of an organization. The mute time interval is inlined next to the
+// organization ID.
+type muteTimeV1 struct {
+	OrgID            values.Int64Value
+	MuteTimeInterval config.MuteTimeInterval
+}
+
+func (m *muteTimeV1) UnmarshalYAML(value *yaml.Node) error {
+	var org orgV1
+	if err := value.Decode(&org); err != nil {
+		return err
+	}
+	m.OrgID = org.OrgID
+	return value.Decode(&m.MuteTimeInterval)
+}
+
+type orgV1 struct {
+	OrgID values.Int64Value `yaml:"orgId"`
+}
+
+type deleteV1 struct {
+	OrgID values.Int64Value  `yaml:"orgId"`
+	Name  values.StringValue `yaml:"name"`
+}
+
+// mapToAlertingAsConfig maps config syntax to normalized alertingAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *alertingAsConfigV1) mapToAlertingAsConfig() *alertingAsConfig {
+	r := &alertingAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, group := range cfg.Groups {
+		r.Groups = append(r.Groups, &ruleGroupFromConfig{
+			OrgID:    group.OrgID.Value(),
+			Folder:   group.Folder.Value(),
+			Name:     group.Name.Value(),
+			Interval: group.Interval,
+			Rules:    group.Rules,
+		})
+	}
+
+	for _, rule := range cfg.DeleteRules {
+		r.DeleteRules = append(r.DeleteRules, &deleteRuleConfig{
+			OrgID: rule.OrgID.Value(),
+			UID:   rule.UID.Value(),
+		})
+	}
+
+	for _, cp := range cfg.ContactPoints {
+		contactPoint := &contactPointFromConfig{
+			OrgID: cp.OrgID.Value(),
+			Name:  cp.Name.Value(),
+		}
+		for _, recv := range cp.Receivers {
+			contactPoint.Receivers = append(contactPoint.Receivers, &receiverFromConfig{
+				UID:                   recv.UID.Value(),
+				Type:                  recv.Type.Value(),
+				DisableResolveMessage: recv.DisableResolveMessage.Value(),
+				Settings:              recv.Settings.Value(),
+				SecureSettings:        recv.SecureSettings.Value(),
+			})
+		}
+		r.ContactPoints = append(r.ContactPoints, contactPoint)
+	}
+	r.DeleteContactPoints = mapToDeleteConfig(cfg.DeleteContactPoints)
+
+	for _, policy := range cfg.Policies {
+		r.Policies = append(r.Policies, &policyFromConfig{
+			OrgID: policy.OrgID.Value(),
+			Route: policy.Route,
+		})
+	}
+
+	for _, orgID := range cfg.ResetPolicies {
+		r.ResetPolicies = append(r.ResetPolicies, orgID.Value())
+	}
+
+	for _, tmpl := range cfg.Templates {
+		r.Templates = append(r.Templates, &templateFromConfig{
+			OrgID:    tmpl.OrgID.Value(),
+			Name:     tmpl.Name.Value(),
+			Template: tmpl.Template,
+		})
+	}
+	r.DeleteTemplates = mapToDeleteConfig(cfg.DeleteTemplates)
+
+	for _, mt := range cfg.MuteTimes {
+		r.MuteTimes = append(r.MuteTimes, &muteTimeFromConfig{
+			OrgID:            mt.OrgID.Value(),
+			MuteTimeInterval: mt.MuteTimeInterval,
+		})
+	}
+	r.DeleteMuteTimes = mapToDeleteConfig(cfg.DeleteMuteTimes)
+
+	return r
+}
+
+func mapToDeleteConfig(items []*deleteV1) []*deleteConfig {
+	var r []*deleteConfig
+	for _, item := range items {
+		r = append(r, &deleteConfig{
+			OrgID: item.OrgID.Value(),
+			Name:  item.Name.Value(),
+		})
+	}
+	return r
+}
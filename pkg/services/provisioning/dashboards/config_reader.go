@@ -103,6 +103,10 @@ func (cr *configReader) readConfig(ctx context.Context) ([]*config, error) {
 
 		if dashboard.UpdateIntervalSeconds == 0 {
 			dashboard.UpdateIntervalSeconds = 10
+			if dashboard.Type == "git" {
+				// don't fetch remote repositories as often as the local disk is walked
+				dashboard.UpdateIntervalSeconds = 60
+			}
 		}
 		if len(dashboard.FolderUID) > 0 {
 			uidUsage[dashboard.FolderUID]++
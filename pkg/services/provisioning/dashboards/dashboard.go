@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/dashboards"
@@ -20,10 +21,26 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	GetProvisionersStatus() []*ProvisionerStatus
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
-type DashboardProvisionerFactory func(context.Context, string, dashboards.Store) (DashboardProvisioner, error)
+type DashboardProvisionerFactory func(ctx context.Context, configDirectory string, dataPath string, store dashboards.Store) (DashboardProvisioner, error)
+
+// ProvisionerStatus is the status of a dashboard provider.
+type ProvisionerStatus struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	OrgID int64  `json:"orgId"`
+	Path  string `json:"path"`
+	// URL and Ref are the repository and the ref of providers of type git.
+	URL string `json:"url,omitempty"`
+	Ref string `json:"ref,omitempty"`
+	// Revision is the SHA of the commit applied by providers of type git.
+	Revision    string     `json:"revision,omitempty"`
+	LastApplied *time.Time `json:"lastApplied,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
 
 // Provisioner is responsible for syncing dashboard from disk to Grafana's database.
 type Provisioner struct {
@@ -33,8 +50,8 @@ type Provisioner struct {
 	duplicateValidator duplicateValidator
 }
 
-// New returns a new DashboardProvisioner
-func New(ctx context.Context, configDirectory string, store dashboards.Store) (DashboardProvisioner, error) {
+// New returns a new DashboardProvisioner. Providers of type git check out their repository in dataPath.
+func New(ctx context.Context, configDirectory string, dataPath string, store dashboards.Store) (DashboardProvisioner, error) {
 	logger := log.New("provisioning.dashboard")
 	cfgReader := &configReader{path: configDirectory, log: logger}
 	configs, err := cfgReader.readConfig(ctx)
@@ -42,7 +59,7 @@ func New(ctx context.Context, configDirectory string, store dashboards.Store) (D
 		return nil, errutil.Wrap("Failed to read dashboards config", err)
 	}
 
-	fileReaders, err := getFileReaders(configs, logger, store)
+	fileReaders, err := getFileReaders(configs, logger, store, dataPath)
 	if err != nil {
 		return nil, errutil.Wrap("Failed to initialize file readers", err)
 	}
@@ -120,7 +137,16 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
-func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store) ([]*FileReader, error) {
+// GetProvisionersStatus returns the status of every dashboard provider.
+func (provider *Provisioner) GetProvisionersStatus() []*ProvisionerStatus {
+	statuses := make([]*ProvisionerStatus, 0, len(provider.fileReaders))
+	for _, reader := range provider.fileReaders {
+		statuses = append(statuses, reader.status())
+	}
+	return statuses
+}
+
+func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store, dataPath string) ([]*FileReader, error) {
 	var readers []*FileReader
 
 	for _, config := range configs {
@@ -132,6 +158,13 @@ func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store
 				return nil, errutil.Wrapf(err, "Failed to create file reader for config %v", config.Name)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(config, logger.New("type", config.Type, "name", config.Name),
+				store, dataPath)
+			if err != nil {
+				return nil, errutil.Wrapf(err, "Failed to create git reader for config %v", config.Name)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
@@ -8,6 +8,7 @@ type calls struct {
 	PollChanges                 []interface{}
 	GetProvisionerResolvedPath  []interface{}
 	GetAllowUIUpdatesFromConfig []interface{}
+	GetProvisionersStatus       []interface{}
 }
 
 // ProvisionerMock is a mock implementation of `Provisioner`
@@ -17,6 +18,7 @@ type ProvisionerMock struct {
 	PollChangesFunc                 func(ctx context.Context)
 	GetProvisionerResolvedPathFunc  func(name string) string
 	GetAllowUIUpdatesFromConfigFunc func(name string) bool
+	GetProvisionersStatusFunc       func() []*ProvisionerStatus
 }
 
 // NewDashboardProvisionerMock returns a new dashboardprovisionermock
@@ -63,3 +65,12 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// GetProvisionersStatus is a mock implementation of `Provisioner.GetProvisionersStatus`
+func (dpm *ProvisionerMock) GetProvisionersStatus() []*ProvisionerStatus {
+	dpm.Calls.GetProvisionersStatus = append(dpm.Calls.GetProvisionersStatus, nil)
+	if dpm.GetProvisionersStatusFunc != nil {
+		return dpm.GetProvisionersStatusFunc()
+	}
+	return nil
+}
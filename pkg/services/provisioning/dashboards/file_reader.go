@@ -35,9 +35,15 @@ type FileReader struct {
 	dashboardProvisioningService dashboards.DashboardProvisioningService
 	FoldersFromFilesStructure    bool
 
+	// git is the repository the dashboards are checked out from, for readers of type git.
+	git *gitRepository
+
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+	revision                string
+	lastApplied             *time.Time
+	lastError               string
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -68,6 +74,50 @@ func NewDashboardFileReader(cfg *config, log log.Logger, store dboards.Store) (*
 	}, nil
 }
 
+// NewDashboardGitReader returns a new filereader that checks out the dashboards of the git repository
+// of `config` in a directory of dataPath.
+func NewDashboardGitReader(cfg *config, log log.Logger, store dboards.Store, dataPath string) (*FileReader, error) {
+	repositoryURL, _ := cfg.Options["url"].(string)
+	if repositoryURL == "" {
+		return nil, fmt.Errorf("failed to load dashboards, url param is not a string")
+	}
+	if strings.HasPrefix(repositoryURL, "-") {
+		return nil, fmt.Errorf("failed to load dashboards, invalid url %q", redactURL(repositoryURL))
+	}
+
+	ref, _ := cfg.Options["ref"].(string)
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("failed to load dashboards, invalid ref %q", ref)
+	}
+
+	// path is a directory of the repository
+	subPath, _ := cfg.Options["path"].(string)
+	if filepath.IsAbs(subPath) || strings.HasPrefix(filepath.Clean(subPath), "..") {
+		return nil, fmt.Errorf("failed to load dashboards, path %q is not a directory of the repository", subPath)
+	}
+
+	foldersFromFilesStructure, _ := cfg.Options["foldersFromFilesStructure"].(bool)
+	if foldersFromFilesStructure && cfg.Folder != "" && cfg.FolderUID != "" {
+		return nil, fmt.Errorf("'folder' and 'folderUID' should be empty using 'foldersFromFilesStructure' option")
+	}
+
+	checkSum, err := util.Md5SumString(cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+	checkoutPath := filepath.Join(dataPath, "provisioning", "dashboards", checkSum)
+
+	return &FileReader{
+		Cfg:                          cfg,
+		Path:                         filepath.Join(checkoutPath, subPath),
+		log:                          log,
+		dashboardProvisioningService: dashboards.NewProvisioningService(store),
+		FoldersFromFilesStructure:    foldersFromFilesStructure,
+		git:                          newGitRepository(repositoryURL, ref, checkoutPath, log),
+		usageTracker:                 newUsageTracker(),
+	}, nil
+}
+
 // pollChanges periodically runs walkDisk based on interval specified in the config.
 func (fr *FileReader) pollChanges(ctx context.Context) {
 	ticker := time.NewTicker(time.Duration(int64(time.Second) * fr.Cfg.UpdateIntervalSeconds))
@@ -84,8 +134,21 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 }
 
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
-// and applies any change to the database.
-func (fr *FileReader) walkDisk(ctx context.Context) error {
+// and applies any change to the database. Readers of type git first update their checkout of the repository.
+func (fr *FileReader) walkDisk(ctx context.Context) (err error) {
+	if fr.git != nil {
+		revision, err := fr.git.sync(ctx)
+		if err != nil {
+			// keep the dashboards of the last revision that was applied
+			fr.log.Error("failed to sync dashboards repository", "url", redactURL(fr.git.url), "ref", fr.git.ref, "error", err)
+			fr.setStatus(fr.getRevision(), err)
+			return nil
+		}
+		defer func() {
+			fr.setStatus(revision, err)
+		}()
+	}
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -440,6 +503,49 @@ func (fr *FileReader) resolvedPath() string {
 	return path
 }
 
+// setStatus records the revision applied by the last walk of the disk, or the error that prevented it.
+func (fr *FileReader) setStatus(revision string, err error) {
+	fr.mux.Lock()
+	defer fr.mux.Unlock()
+
+	if err != nil {
+		fr.lastError = err.Error()
+		return
+	}
+
+	now := time.Now()
+	fr.revision = revision
+	fr.lastApplied = &now
+	fr.lastError = ""
+}
+
+func (fr *FileReader) getRevision() string {
+	fr.mux.RLock()
+	defer fr.mux.RUnlock()
+
+	return fr.revision
+}
+
+func (fr *FileReader) status() *ProvisionerStatus {
+	fr.mux.RLock()
+	defer fr.mux.RUnlock()
+
+	status := &ProvisionerStatus{
+		Name:        fr.Cfg.Name,
+		Type:        fr.Cfg.Type,
+		OrgID:       fr.Cfg.OrgID,
+		Path:        fr.Path,
+		Revision:    fr.revision,
+		LastApplied: fr.lastApplied,
+		Error:       fr.lastError,
+	}
+	if fr.git != nil {
+		status.URL = redactURL(fr.git.url)
+		status.Ref = fr.git.ref
+	}
+	return status
+}
+
 func (fr *FileReader) getUsageTracker() *usageTracker {
 	fr.mux.RLock()
 	defer fr.mux.RUnlock()
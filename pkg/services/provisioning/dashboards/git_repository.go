@@ -0,0 +1,155 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// gitCommandTimeout is the maximum duration of a git command, so that an unreachable remote
+// doesn't block the provisioning.
+const gitCommandTimeout = 5 * time.Minute
+
+// gitRepository is a local checkout of a remote git repository, updated with the git command line.
+type gitRepository struct {
+	url string
+	ref string
+	dir string
+	log log.Logger
+}
+
+func newGitRepository(url, ref, dir string, log log.Logger) *gitRepository {
+	return &gitRepository{
+		url: url,
+		ref: ref,
+		dir: dir,
+		log: log,
+	}
+}
+
+// sync clones the repository, or fetches it when it is already cloned, and checks out the configured ref.
+// It returns the SHA of the checked out commit.
+func (r *gitRepository) sync(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if err := r.clone(ctx); err != nil {
+			return "", err
+		}
+	} else {
+		if _, err := r.git(ctx, "remote", "set-url", "origin", r.url); err != nil {
+			return "", err
+		}
+		if _, err := r.git(ctx, "fetch", "--quiet", "--prune", "--tags", "--force", "origin"); err != nil {
+			return "", err
+		}
+	}
+
+	sha, err := r.resolveRef(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := r.git(ctx, "checkout", "--quiet", "--force", "--detach", sha); err != nil {
+		return "", err
+	}
+	// remove the files that are not part of the commit, e.g. dashboards deleted from the repository
+	if _, err := r.git(ctx, "clean", "--quiet", "--force", "-d", "-x"); err != nil {
+		return "", err
+	}
+
+	return sha, nil
+}
+
+func (r *gitRepository) clone(ctx context.Context) error {
+	r.log.Info("cloning repository", "url", redactURL(r.url), "path", r.dir)
+	// a partial clone can't be recovered by a fetch, start from scratch
+	if err := os.RemoveAll(r.dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.dir), 0750); err != nil {
+		return err
+	}
+	_, err := runGit(ctx, "", "clone", "--quiet", "--no-checkout", "--", r.url, r.dir)
+	return r.redactError(err)
+}
+
+// resolveRef returns the commit of the ref, which can be a branch, a tag or a commit SHA.
+// An empty ref is the default branch of the remote.
+func (r *gitRepository) resolveRef(ctx context.Context) (string, error) {
+	var candidates []string
+	if r.ref == "" {
+		candidates = []string{"refs/remotes/origin/HEAD"}
+	} else {
+		candidates = []string{"refs/remotes/origin/" + r.ref, "refs/tags/" + r.ref, r.ref}
+	}
+
+	for _, candidate := range candidates {
+		sha, err := r.git(ctx, "rev-parse", "--verify", "--quiet", candidate+"^{commit}")
+		if err == nil {
+			return sha, nil
+		}
+	}
+
+	return "", fmt.Errorf("ref %q not found in repository %s", r.ref, redactURL(r.url))
+}
+
+func (r *gitRepository) git(ctx context.Context, args ...string) (string, error) {
+	out, err := runGit(ctx, r.dir, args...)
+	return out, r.redactError(err)
+}
+
+// redactError removes the credentials of the repository URL from the errors of the git commands.
+func (r *gitRepository) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	redacted := redactURL(r.url)
+	if redacted == r.url {
+		return err
+	}
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), r.url, redacted))
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitCommandTimeout)
+	defer cancel()
+
+	// nolint:gosec
+	// We can ignore the gosec G204 warning on this one because the arguments come from the provisioning configuration file.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// never wait for credentials on a terminal
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// redactURL removes the credentials of a repository URL, so that it can be logged. The user of HTTP URLs
+// is removed too, as it is commonly an access token.
+func redactURL(repositoryURL string) string {
+	u, err := url.Parse(repositoryURL)
+	if err != nil || u.User == nil {
+		return repositoryURL
+	}
+	if _, ok := u.User.Password(); !ok && (u.Scheme == "http" || u.Scheme == "https") {
+		u.User = url.User("xxxxx")
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,256 @@
+package orgs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+type configReader struct {
+	log       log.Logger
+	orgExists func(ctx context.Context, orgID int64) error
+}
+
+func newConfigReader(log log.Logger) *configReader {
+	return &configReader{
+		log:       log,
+		orgExists: utils.CheckOrgExists,
+	}
+}
+
+func (cr *configReader) readConfig(ctx context.Context, path string) ([]*orgsAsConfig, error) {
+	var configs []*orgsAsConfig
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("can't read organization provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cfg, err := cr.parseConfig(path, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	if err := cr.validateRequiredFields(configs); err != nil {
+		return nil, err
+	}
+
+	if err := cr.checkOrgIDs(ctx, configs); err != nil {
+		return nil, err
+	}
+
+	if err := validateDuplicates(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseConfig(path string, file os.FileInfo) (*orgsAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiVersion *configVersion
+	if err := yaml.Unmarshal(yamlFile, &apiVersion); err != nil {
+		return nil, err
+	}
+
+	if apiVersion == nil {
+		return nil, nil
+	}
+
+	if apiVersion.APIVersion != 1 {
+		return nil, fmt.Errorf("failed to parse %s: unsupported apiVersion %d, the supported version is 1", file.Name(), apiVersion.APIVersion)
+	}
+
+	var cfg *orgsAsConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToOrgsAsConfig(), nil
+}
+
+func (cr *configReader) validateRequiredFields(configs []*orgsAsConfig) error {
+	var errStrings []string
+	addErr := func(format string, args ...interface{}) {
+		errStrings = append(errStrings, fmt.Sprintf(format, args...))
+	}
+
+	for _, cfg := range configs {
+		for i, org := range cfg.Orgs {
+			if org.Name == "" {
+				addErr("Organization %d in configuration doesn't contain required field name", i+1)
+			}
+		}
+
+		for i, user := range cfg.Users {
+			if user.Login == "" {
+				addErr("User %d in configuration doesn't contain required field login", i+1)
+			}
+			for j, membership := range user.Orgs {
+				if !models.RoleType(membership.Role).IsValid() {
+					addErr("Organization %d of user %d in configuration has invalid role %q", j+1, i+1, membership.Role)
+				}
+			}
+		}
+
+		for i, team := range cfg.Teams {
+			if team.Name == "" {
+				addErr("Team %d in configuration doesn't contain required field name", i+1)
+			}
+			for j, member := range team.Members {
+				if member == "" {
+					addErr("Member %d of team %d in configuration is empty", j+1, i+1)
+				}
+			}
+		}
+
+		for i, folder := range cfg.Folders {
+			if folder.UID == "" {
+				addErr("Folder %d in configuration doesn't contain required field uid", i+1)
+			}
+			if folder.Title == "" {
+				addErr("Folder %d in configuration doesn't contain required field title", i+1)
+			}
+			for j, permission := range folder.Permissions {
+				if countNonEmpty(permission.Team, permission.User, permission.Role) != 1 {
+					addErr("Permission %d of folder %d in configuration must contain exactly one of the fields team, user or role", j+1, i+1)
+				}
+				if permission.Role != "" && !models.RoleType(permission.Role).IsValid() {
+					addErr("Permission %d of folder %d in configuration has invalid role %q", j+1, i+1, permission.Role)
+				}
+				if _, ok := permissionTypes[permission.Permission]; !ok {
+					addErr("Permission %d of folder %d in configuration has invalid permission %q", j+1, i+1, permission.Permission)
+				}
+			}
+		}
+	}
+
+	if len(errStrings) != 0 {
+		return fmt.Errorf(strings.Join(errStrings, "\n"))
+	}
+
+	return nil
+}
+
+// checkOrgIDs defaults the organization of the items that reference neither an organization ID nor a name
+// to the main organization, and checks that the referenced organization IDs exist. Organization names are
+// resolved when the configuration is applied, as they can reference organizations provisioned by it.
+func (cr *configReader) checkOrgIDs(ctx context.Context, configs []*orgsAsConfig) error {
+	var refs []*orgRef
+	for _, cfg := range configs {
+		for _, user := range cfg.Users {
+			for _, membership := range user.Orgs {
+				refs = append(refs, &membership.orgRef)
+			}
+		}
+		for _, team := range cfg.Teams {
+			refs = append(refs, &team.orgRef)
+		}
+		for _, folder := range cfg.Folders {
+			refs = append(refs, &folder.orgRef)
+		}
+	}
+
+	for _, ref := range refs {
+		if ref.OrgID < 1 {
+			if ref.OrgName == "" {
+				ref.OrgID = 1
+			} else {
+				ref.OrgID = 0
+			}
+			continue
+		}
+
+		if err := cr.orgExists(ctx, ref.OrgID); err != nil {
+			return fmt.Errorf("failed to provision organization %d: %w", ref.OrgID, err)
+		}
+	}
+
+	return nil
+}
+
+// validateDuplicates checks that no resource is declared more than once across all the configuration files.
+func validateDuplicates(configs []*orgsAsConfig) error {
+	seen := map[string]struct{}{}
+	check := func(kind string, key string) error {
+		k := kind + "/" + key
+		if _, ok := seen[k]; ok {
+			return fmt.Errorf("%s %s is provisioned more than once", kind, key)
+		}
+		seen[k] = struct{}{}
+		return nil
+	}
+
+	for _, cfg := range configs {
+		for _, org := range cfg.Orgs {
+			if err := check("organization", fmt.Sprintf("%q", org.Name)); err != nil {
+				return err
+			}
+		}
+		for _, user := range cfg.Users {
+			if err := check("user", fmt.Sprintf("%q", user.Login)); err != nil {
+				return err
+			}
+			for _, membership := range user.Orgs {
+				if err := check("membership", fmt.Sprintf("of user %q in organization %s", user.Login, membership.orgRef)); err != nil {
+					return err
+				}
+			}
+		}
+		for _, team := range cfg.Teams {
+			if err := check("team", fmt.Sprintf("%q in organization %s", team.Name, team.orgRef)); err != nil {
+				return err
+			}
+		}
+		for _, folder := range cfg.Folders {
+			if err := check("folder", fmt.Sprintf("%q in organization %s", folder.UID, folder.orgRef)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ref orgRef) String() string {
+	if ref.OrgID > 0 {
+		return fmt.Sprintf("%d", ref.OrgID)
+	}
+	return fmt.Sprintf("%q", ref.OrgName)
+}
+
+func countNonEmpty(values ...string) int {
+	count := 0
+	for _, v := range values {
+		if v != "" {
+			count++
+		}
+	}
+	return count
+}
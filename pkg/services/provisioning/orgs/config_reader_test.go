@@ -0,0 +1,142 @@
+package orgs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	correctProperties  = "./testdata/test-configs/correct-properties"
+	noRequiredFields   = "./testdata/test-configs/no-required-fields"
+	duplicates         = "./testdata/test-configs/duplicates"
+	unsupportedVersion = "./testdata/test-configs/unsupported-version"
+	brokenYaml         = "./testdata/test-configs/broken-yaml"
+	emptyFile          = "./testdata/test-configs/empty"
+	missingFolder      = "./testdata/test-configs/missing"
+)
+
+func newTestConfigReader(orgs ...int64) *configReader {
+	return &configReader{
+		log: log.New("test logger"),
+		orgExists: func(_ context.Context, orgID int64) error {
+			for _, o := range orgs {
+				if o == orgID {
+					return nil
+				}
+			}
+			return models.ErrOrgNotFound
+		},
+	}
+}
+
+func TestOrgsAsConfig(t *testing.T) {
+	t.Run("Can read correct properties", func(t *testing.T) {
+		t.Setenv("DEPLOY_BOT_PASSWORD", "s3cr3t")
+
+		cfgs, err := newTestConfigReader(1, 2).readConfig(context.Background(), correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfgs, 1)
+		cfg := cfgs[0]
+
+		require.Equal(t, []*orgFromConfig{{Name: "Platform"}}, cfg.Orgs)
+
+		require.Equal(t, []*userFromConfig{
+			{
+				Login:    "deploy-bot",
+				Email:    "deploy-bot@example.com",
+				Name:     "Deploy bot",
+				Password: "s3cr3t",
+				Orgs: []*orgMembershipFromConfig{
+					{orgRef: orgRef{OrgName: "Platform"}, Role: "Editor"},
+					{orgRef: orgRef{OrgID: 1}, Role: "Viewer"},
+				},
+			},
+			{
+				Login:          "platform-admin",
+				IsGrafanaAdmin: boolPtr(true),
+				Orgs: []*orgMembershipFromConfig{
+					{orgRef: orgRef{OrgID: 2}, Role: "Admin"},
+				},
+			},
+		}, cfg.Users)
+
+		require.Equal(t, []*teamFromConfig{{
+			orgRef:  orgRef{OrgName: "Platform"},
+			Name:    "SRE",
+			Email:   "sre@example.com",
+			Members: []string{"deploy-bot", "platform-admin"},
+		}}, cfg.Teams)
+
+		require.Equal(t, []*folderFromConfig{
+			{
+				orgRef: orgRef{OrgName: "Platform"},
+				UID:    "infra",
+				Title:  "Infrastructure",
+				Permissions: []*folderPermissionFromConfig{
+					{Team: "SRE", Permission: "Edit"},
+					{User: "deploy-bot", Permission: "Admin"},
+					{Role: "Viewer", Permission: "View"},
+				},
+			},
+			{
+				orgRef: orgRef{OrgID: 1},
+				UID:    "shared",
+				Title:  "Shared",
+			},
+		}, cfg.Folders)
+	})
+
+	t.Run("Should fail if an organization does not exist", func(t *testing.T) {
+		_, err := newTestConfigReader(1).readConfig(context.Background(), correctProperties)
+		require.ErrorIs(t, err, models.ErrOrgNotFound)
+	})
+
+	t.Run("Should fail on missing required fields", func(t *testing.T) {
+		_, err := newTestConfigReader(1).readConfig(context.Background(), noRequiredFields)
+		require.EqualError(t, err, "Organization 1 in configuration doesn't contain required field name\n"+
+			"User 1 in configuration doesn't contain required field login\n"+
+			"Organization 1 of user 1 in configuration has invalid role \"Owner\"\n"+
+			"Team 1 in configuration doesn't contain required field name\n"+
+			"Member 1 of team 1 in configuration is empty\n"+
+			"Folder 1 in configuration doesn't contain required field uid\n"+
+			"Permission 1 of folder 1 in configuration must contain exactly one of the fields team, user or role\n"+
+			"Permission 2 of folder 1 in configuration has invalid permission \"Read\"\n"+
+			"Folder 2 in configuration doesn't contain required field title")
+	})
+
+	t.Run("Should fail on resources provisioned more than once", func(t *testing.T) {
+		_, err := newTestConfigReader(1).readConfig(context.Background(), duplicates)
+		require.EqualError(t, err, `team "SRE" in organization "Platform" is provisioned more than once`)
+	})
+
+	t.Run("Should fail on unsupported version", func(t *testing.T) {
+		_, err := newTestConfigReader(1).readConfig(context.Background(), unsupportedVersion)
+		require.EqualError(t, err, "failed to parse orgs.yaml: unsupported apiVersion 2, the supported version is 1")
+	})
+
+	t.Run("Should fail on broken yaml", func(t *testing.T) {
+		_, err := newTestConfigReader(1).readConfig(context.Background(), brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Empty file should not return error", func(t *testing.T) {
+		cfgs, err := newTestConfigReader(1).readConfig(context.Background(), emptyFile)
+		require.NoError(t, err)
+		require.Empty(t, cfgs)
+	})
+
+	t.Run("Missing folder should not return error", func(t *testing.T) {
+		cfgs, err := newTestConfigReader(1).readConfig(context.Background(), missingFolder)
+		require.NoError(t, err)
+		require.Empty(t, cfgs)
+	})
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,470 @@
+package orgs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var permissionTypes = map[string]models.PermissionType{
+	models.PERMISSION_VIEW.String():  models.PERMISSION_VIEW,
+	models.PERMISSION_EDIT.String():  models.PERMISSION_EDIT,
+	models.PERMISSION_ADMIN.String(): models.PERMISSION_ADMIN,
+}
+
+// Provision scans a directory for provisioning config files
+// and provisions the organizations, users, teams and folders in those files.
+// The resources provisioned by a previous run that are no longer in the files are removed.
+func Provision(ctx context.Context, configDirectory string, sqlStore *sqlstore.SQLStore) error {
+	op := newOrgsProvisioner(log.New("provisioning.orgs"), sqlStore)
+	return op.applyChanges(ctx, configDirectory)
+}
+
+// OrgsProvisioner is responsible for provisioning organizations, users, teams and folders
+// based on configuration read by the `configReader`.
+type OrgsProvisioner struct {
+	log         log.Logger
+	cfgProvider *configReader
+	store       *sqlstore.SQLStore
+	state       *kvstore.NamespacedKVStore
+}
+
+func newOrgsProvisioner(log log.Logger, sqlStore *sqlstore.SQLStore) OrgsProvisioner {
+	return OrgsProvisioner{
+		log:         log,
+		cfgProvider: newConfigReader(log),
+		store:       sqlStore,
+		state:       kvstore.WithNamespace(kvstore.ProvideService(sqlStore), 0, stateNamespace),
+	}
+}
+
+func (op *OrgsProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	// Without a configuration directory nothing is provisioned, and nothing that was provisioned
+	// before is removed: an unmounted volume should not delete users and organizations.
+	if _, err := os.Stat(configPath); err != nil {
+		op.log.Debug("skipping organization provisioning, the configuration directory cannot be read", "path", configPath, "error", err)
+		return nil
+	}
+
+	configs, err := op.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return err
+	}
+
+	cfg := &orgsAsConfig{}
+	for _, c := range configs {
+		cfg.Orgs = append(cfg.Orgs, c.Orgs...)
+		cfg.Users = append(cfg.Users, c.Users...)
+		cfg.Teams = append(cfg.Teams, c.Teams...)
+		cfg.Folders = append(cfg.Folders, c.Folders...)
+	}
+
+	previous, err := op.getState(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := provisionedState{}
+	if err := op.apply(ctx, cfg, runState{previous: previous, current: current}); err != nil {
+		// keep track of everything provisioned so far so that it can still be removed later
+		for r := range previous {
+			current[r] = struct{}{}
+		}
+		if stateErr := op.setState(ctx, current); stateErr != nil {
+			op.log.Error("failed to save the provisioned resources", "error", stateErr)
+		}
+		return err
+	}
+
+	// resources that could not be removed are kept in the state to be removed on the next run
+	for _, r := range previous.missingFrom(current).sorted() {
+		if err := op.prune(ctx, r); err != nil {
+			op.log.Error("failed to remove resource no longer provisioned", "kind", r.Kind, "org", r.OrgID, "name", r.Name, "login", r.Login, "error", err)
+			current[r] = struct{}{}
+		}
+	}
+
+	return op.setState(ctx, current)
+}
+
+func (op *OrgsProvisioner) apply(ctx context.Context, cfg *orgsAsConfig, provisioned runState) error {
+	for _, org := range cfg.Orgs {
+		created, err := op.provisionOrg(ctx, org)
+		if err != nil {
+			return fmt.Errorf("failed to provision organization %q: %w", org.Name, err)
+		}
+		provisioned.own(provisionedResource{Kind: kindOrg, Name: org.Name}, created)
+	}
+
+	for _, user := range cfg.Users {
+		if err := op.provisionUser(ctx, user, provisioned); err != nil {
+			return fmt.Errorf("failed to provision user %q: %w", user.Login, err)
+		}
+	}
+
+	for _, team := range cfg.Teams {
+		if err := op.provisionTeam(ctx, team, provisioned); err != nil {
+			return fmt.Errorf("failed to provision team %q: %w", team.Name, err)
+		}
+	}
+
+	for _, folder := range cfg.Folders {
+		created, err := op.provisionFolder(ctx, folder)
+		if err != nil {
+			return fmt.Errorf("failed to provision folder %q: %w", folder.UID, err)
+		}
+		provisioned.own(provisionedResource{Kind: kindFolder, OrgID: folder.OrgID, Name: folder.UID}, created)
+	}
+
+	return nil
+}
+
+// provisionOrg creates the organization if it does not exist, it returns true if it was created.
+func (op *OrgsProvisioner) provisionOrg(ctx context.Context, org *orgFromConfig) (bool, error) {
+	query := &models.GetOrgByNameQuery{Name: org.Name}
+	err := bus.DispatchCtx(ctx, query)
+	if err == nil || !errors.Is(err, models.ErrOrgNotFound) {
+		return false, err
+	}
+
+	op.log.Info("creating organization from configuration", "name", org.Name)
+	return true, bus.DispatchCtx(ctx, &models.CreateOrgCommand{Name: org.Name})
+}
+
+// resolveOrg sets the organization ID of references by organization name.
+func (op *OrgsProvisioner) resolveOrg(ctx context.Context, ref *orgRef) error {
+	if ref.OrgID > 0 {
+		return nil
+	}
+
+	query := &models.GetOrgByNameQuery{Name: ref.OrgName}
+	if err := bus.DispatchCtx(ctx, query); err != nil {
+		return fmt.Errorf("organization %q: %w", ref.OrgName, err)
+	}
+	ref.OrgID = query.Result.Id
+	return nil
+}
+
+func (op *OrgsProvisioner) getUser(ctx context.Context, login string) (*models.User, error) {
+	query := &models.GetUserByLoginQuery{LoginOrEmail: login}
+	if err := bus.DispatchCtx(ctx, query); err != nil {
+		return nil, fmt.Errorf("user %q: %w", login, err)
+	}
+	return query.Result, nil
+}
+
+func (op *OrgsProvisioner) provisionUser(ctx context.Context, cfg *userFromConfig, provisioned runState) error {
+	query := &models.GetUserByLoginQuery{LoginOrEmail: cfg.Login}
+	err := bus.DispatchCtx(ctx, query)
+	if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+		return err
+	}
+
+	var user *models.User
+	created := errors.Is(err, models.ErrUserNotFound)
+	if created {
+		password := cfg.Password
+		if password == "" {
+			// users without a password are service users that can't log in with a password
+			if password, err = util.GetRandomString(32); err != nil {
+				return err
+			}
+		}
+
+		op.log.Info("creating user from configuration", "login", cfg.Login)
+		user, err = op.store.CreateUser(ctx, models.CreateUserCommand{
+			Login:        cfg.Login,
+			Email:        cfg.Email,
+			Name:         cfg.Name,
+			Password:     password,
+			IsAdmin:      cfg.IsGrafanaAdmin != nil && *cfg.IsGrafanaAdmin,
+			SkipOrgSetup: true,
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		user = query.Result
+		if err := op.updateUser(ctx, user, cfg); err != nil {
+			return err
+		}
+	}
+	provisioned.own(provisionedResource{Kind: kindUser, Name: cfg.Login}, created)
+
+	orgsQuery := &models.GetUserOrgListQuery{UserId: user.Id}
+	if err := bus.DispatchCtx(ctx, orgsQuery); err != nil {
+		return err
+	}
+	roles := make(map[int64]models.RoleType, len(orgsQuery.Result))
+	for _, org := range orgsQuery.Result {
+		roles[org.OrgId] = org.Role
+	}
+
+	for _, membership := range cfg.Orgs {
+		if err := op.resolveOrg(ctx, &membership.orgRef); err != nil {
+			return err
+		}
+
+		role := models.RoleType(membership.Role)
+		current, ok := roles[membership.OrgID]
+		switch {
+		case !ok:
+			op.log.Info("adding user to organization from configuration", "login", cfg.Login, "org", membership.OrgID, "role", role)
+			err = bus.DispatchCtx(ctx, &models.AddOrgUserCommand{OrgId: membership.OrgID, UserId: user.Id, Role: role})
+		case current != role:
+			op.log.Info("updating user role in organization from configuration", "login", cfg.Login, "org", membership.OrgID, "role", role)
+			err = bus.DispatchCtx(ctx, &models.UpdateOrgUserCommand{OrgId: membership.OrgID, UserId: user.Id, Role: role})
+		}
+		if err != nil {
+			return err
+		}
+		provisioned.own(provisionedResource{Kind: kindOrgMember, OrgID: membership.OrgID, Login: cfg.Login}, !ok)
+	}
+
+	return nil
+}
+
+// updateUser updates the existing user with the information of its configuration. Empty and omitted fields
+// keep their current value.
+func (op *OrgsProvisioner) updateUser(ctx context.Context, user *models.User, cfg *userFromConfig) error {
+	update := &models.UpdateUserCommand{
+		UserId: user.Id,
+		Login:  user.Login,
+		Email:  user.Email,
+		Name:   user.Name,
+		Theme:  user.Theme,
+	}
+	if cfg.Email != "" {
+		update.Email = cfg.Email
+	}
+	if cfg.Name != "" {
+		update.Name = cfg.Name
+	}
+	if update.Email != user.Email || update.Name != user.Name {
+		op.log.Debug("updating user from configuration", "login", cfg.Login)
+		if err := bus.DispatchCtx(ctx, update); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Password != "" {
+		password, err := util.EncodePassword(cfg.Password, user.Salt)
+		if err != nil {
+			return err
+		}
+		if password != user.Password {
+			op.log.Debug("updating user password from configuration", "login", cfg.Login)
+			if err := bus.DispatchCtx(ctx, &models.ChangeUserPasswordCommand{UserId: user.Id, NewPassword: password}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.IsGrafanaAdmin != nil && *cfg.IsGrafanaAdmin != user.IsAdmin {
+		if err := op.store.UpdateUserPermissions(user.Id, *cfg.IsGrafanaAdmin); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (op *OrgsProvisioner) provisionTeam(ctx context.Context, cfg *teamFromConfig, provisioned runState) error {
+	if err := op.resolveOrg(ctx, &cfg.orgRef); err != nil {
+		return err
+	}
+
+	team, err := op.getTeam(ctx, cfg.OrgID, cfg.Name)
+	if err != nil && !errors.Is(err, models.ErrTeamNotFound) {
+		return err
+	}
+
+	var teamID int64
+	created := errors.Is(err, models.ErrTeamNotFound)
+	if created {
+		op.log.Info("creating team from configuration", "name", cfg.Name, "org", cfg.OrgID)
+		created, err := op.store.CreateTeam(cfg.Name, cfg.Email, cfg.OrgID)
+		if err != nil {
+			return err
+		}
+		teamID = created.Id
+	} else {
+		teamID = team.Id
+		if team.Email != cfg.Email {
+			op.log.Debug("updating team from configuration", "name", cfg.Name, "org", cfg.OrgID)
+			if err := bus.DispatchCtx(ctx, &models.UpdateTeamCommand{Id: team.Id, OrgId: cfg.OrgID, Name: team.Name, Email: cfg.Email}); err != nil {
+				return err
+			}
+		}
+	}
+	provisioned.own(provisionedResource{Kind: kindTeam, OrgID: cfg.OrgID, Name: cfg.Name}, created)
+
+	membersQuery := &models.GetTeamMembersQuery{OrgId: cfg.OrgID, TeamId: teamID}
+	if err := bus.DispatchCtx(ctx, membersQuery); err != nil {
+		return err
+	}
+	members := make(map[int64]struct{}, len(membersQuery.Result))
+	for _, member := range membersQuery.Result {
+		members[member.UserId] = struct{}{}
+	}
+
+	for _, login := range cfg.Members {
+		user, err := op.getUser(ctx, login)
+		if err != nil {
+			return err
+		}
+		_, ok := members[user.Id]
+		if !ok {
+			op.log.Info("adding user to team from configuration", "login", login, "team", cfg.Name, "org", cfg.OrgID)
+			if err := op.store.AddTeamMember(user.Id, cfg.OrgID, teamID, false, 0); err != nil {
+				return err
+			}
+		}
+		provisioned.own(provisionedResource{Kind: kindTeamMember, OrgID: cfg.OrgID, Name: cfg.Name, Login: login}, !ok)
+	}
+
+	return nil
+}
+
+func (op *OrgsProvisioner) getTeam(ctx context.Context, orgID int64, name string) (*models.TeamDTO, error) {
+	query := &models.SearchTeamsQuery{OrgId: orgID, Name: name, Limit: 1, Page: 1}
+	if err := bus.DispatchCtx(ctx, query); err != nil {
+		return nil, err
+	}
+	if len(query.Result.Teams) == 0 {
+		return nil, models.ErrTeamNotFound
+	}
+	return query.Result.Teams[0], nil
+}
+
+func (op *OrgsProvisioner) folderService(orgID int64) dashboards.FolderService {
+	return dashboards.NewFolderService(orgID, &models.SignedInUser{OrgId: orgID, OrgRole: models.ROLE_ADMIN}, op.store)
+}
+
+// provisionFolder creates or updates the folder, it returns true if it was created.
+func (op *OrgsProvisioner) provisionFolder(ctx context.Context, cfg *folderFromConfig) (bool, error) {
+	if err := op.resolveOrg(ctx, &cfg.orgRef); err != nil {
+		return false, err
+	}
+
+	folderService := op.folderService(cfg.OrgID)
+	folder, err := folderService.GetFolderByUID(ctx, cfg.UID)
+	if err != nil && !errors.Is(err, models.ErrFolderNotFound) {
+		return false, err
+	}
+
+	created := errors.Is(err, models.ErrFolderNotFound)
+	if created {
+		op.log.Info("creating folder from configuration", "uid", cfg.UID, "title", cfg.Title, "org", cfg.OrgID)
+		if folder, err = folderService.CreateFolder(ctx, cfg.Title, cfg.UID); err != nil {
+			return false, err
+		}
+	} else if folder.Title != cfg.Title {
+		op.log.Debug("updating folder from configuration", "uid", cfg.UID, "title", cfg.Title, "org", cfg.OrgID)
+		cmd := &models.UpdateFolderCommand{Uid: cfg.UID, Title: cfg.Title, Overwrite: true}
+		if err := folderService.UpdateFolder(ctx, cfg.UID, cmd); err != nil {
+			return false, err
+		}
+	}
+
+	if cfg.Permissions == nil {
+		return created, nil
+	}
+	return created, op.provisionFolderPermissions(ctx, folder, cfg)
+}
+
+// provisionFolderPermissions replaces the permissions of the folder with the permissions of its configuration.
+func (op *OrgsProvisioner) provisionFolderPermissions(ctx context.Context, folder *models.Folder, cfg *folderFromConfig) error {
+	items := make([]*models.DashboardAcl, 0, len(cfg.Permissions))
+	for _, permission := range cfg.Permissions {
+		item := &models.DashboardAcl{
+			OrgID:       cfg.OrgID,
+			DashboardID: folder.Id,
+			Permission:  permissionTypes[permission.Permission],
+		}
+
+		switch {
+		case permission.Team != "":
+			team, err := op.getTeam(ctx, cfg.OrgID, permission.Team)
+			if err != nil {
+				return fmt.Errorf("team %q: %w", permission.Team, err)
+			}
+			item.TeamID = team.Id
+		case permission.User != "":
+			user, err := op.getUser(ctx, permission.User)
+			if err != nil {
+				return err
+			}
+			item.UserID = user.Id
+		default:
+			role := models.RoleType(permission.Role)
+			item.Role = &role
+		}
+		items = append(items, item)
+	}
+
+	query := &models.GetDashboardAclInfoListQuery{OrgID: cfg.OrgID, DashboardID: folder.Id}
+	if err := op.store.GetDashboardAclInfoList(ctx, query); err != nil {
+		return err
+	}
+	if folder.HasAcl && aclEqual(items, query.Result, folder.Id) {
+		return nil
+	}
+
+	op.log.Info("updating folder permissions from configuration", "uid", cfg.UID, "org", cfg.OrgID)
+	now := time.Now()
+	for _, item := range items {
+		item.Created = now
+		item.Updated = now
+	}
+	return op.store.UpdateDashboardACLCtx(ctx, folder.Id, items)
+}
+
+// aclEqual returns whether the permissions set on the folder are the same as the given ones.
+// Inherited and default permissions are ignored.
+func aclEqual(items []*models.DashboardAcl, current []*models.DashboardAclInfoDTO, folderID int64) bool {
+	type aclKey struct {
+		userID     int64
+		teamID     int64
+		role       models.RoleType
+		permission models.PermissionType
+	}
+	toKey := func(userID, teamID int64, role *models.RoleType, permission models.PermissionType) aclKey {
+		k := aclKey{userID: userID, teamID: teamID, permission: permission}
+		if role != nil {
+			k.role = *role
+		}
+		return k
+	}
+
+	want := make(map[aclKey]int, len(items))
+	for _, item := range items {
+		want[toKey(item.UserID, item.TeamID, item.Role, item.Permission)]++
+	}
+	for _, item := range current {
+		if item.DashboardId != folderID {
+			continue
+		}
+		k := toKey(item.UserId, item.TeamId, item.Role, item.Permission)
+		if want[k] == 0 {
+			return false
+		}
+		want[k]--
+	}
+	for _, n := range want {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
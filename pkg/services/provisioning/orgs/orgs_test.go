@@ -0,0 +1,218 @@
+package orgs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const fullConfig = `apiVersion: 1
+
+orgs:
+  - name: Platform
+
+users:
+  - login: deploy-bot
+    email: deploy-bot@example.com
+    password: s3cr3t
+    orgs:
+      - orgName: Platform
+        role: Editor
+  - login: platform-admin
+    orgs:
+      - orgName: Platform
+        role: Admin
+
+teams:
+  - orgName: Platform
+    name: SRE
+    members:
+      - deploy-bot
+      - platform-admin
+
+folders:
+  - orgName: Platform
+    uid: infra
+    title: Infrastructure
+    permissions:
+      - team: SRE
+        permission: Edit
+      - role: Viewer
+        permission: View
+`
+
+const prunedConfig = `apiVersion: 1
+
+orgs:
+  - name: Platform
+
+users:
+  - login: platform-admin
+    orgs:
+      - orgName: Platform
+        role: Admin
+
+teams:
+  - orgName: Platform
+    name: SRE
+    members:
+      - platform-admin
+`
+
+const existingUserConfig = `apiVersion: 1
+
+orgs:
+  - name: Platform
+
+users:
+  - login: admin
+    name: Administrator
+    orgs:
+      - orgId: 1
+        role: Admin
+      - orgName: Platform
+        role: Admin
+`
+
+func TestOrgsProvisioner(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+	require.NoError(t, sqlstore.CreateOrg(ctx, &models.CreateOrgCommand{Name: "Main Org."}))
+
+	dir := t.TempDir()
+	writeConfig := func(t *testing.T, config string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "orgs.yaml"), []byte(config), 0600))
+	}
+	provision := func(t *testing.T, path string) {
+		t.Helper()
+		op := newOrgsProvisioner(log.New("test logger"), sqlStore)
+		require.NoError(t, op.applyChanges(ctx, path))
+	}
+
+	getOrgID := func(t *testing.T) int64 {
+		t.Helper()
+		query := &models.GetOrgByNameQuery{Name: "Platform"}
+		require.NoError(t, bus.DispatchCtx(ctx, query))
+		return query.Result.Id
+	}
+	getUser := func(t *testing.T, login string) *models.User {
+		t.Helper()
+		query := &models.GetUserByLoginQuery{LoginOrEmail: login}
+		err := bus.DispatchCtx(ctx, query)
+		if err != nil {
+			require.ErrorIs(t, err, models.ErrUserNotFound)
+			return nil
+		}
+		return query.Result
+	}
+	getTeamMembers := func(t *testing.T, orgID int64) []string {
+		t.Helper()
+		op := newOrgsProvisioner(log.New("test logger"), sqlStore)
+		team, err := op.getTeam(ctx, orgID, "SRE")
+		require.NoError(t, err)
+		query := &models.GetTeamMembersQuery{OrgId: orgID, TeamId: team.Id}
+		require.NoError(t, bus.DispatchCtx(ctx, query))
+		var logins []string
+		for _, m := range query.Result {
+			logins = append(logins, m.Login)
+		}
+		return logins
+	}
+	getFolder := func(t *testing.T, orgID int64) *models.Dashboard {
+		t.Helper()
+		query := &models.GetDashboardQuery{OrgId: orgID, Uid: "infra"}
+		err := bus.DispatchCtx(ctx, query)
+		if err != nil {
+			require.ErrorIs(t, err, models.ErrDashboardNotFound)
+			return nil
+		}
+		return query.Result
+	}
+
+	t.Run("should provision the organizations, users, teams and folders", func(t *testing.T) {
+		writeConfig(t, fullConfig)
+		provision(t, dir)
+		// provisioning the same configuration again is a no-op
+		provision(t, dir)
+
+		orgID := getOrgID(t)
+
+		bot := getUser(t, "deploy-bot")
+		require.NotNil(t, bot)
+		require.Equal(t, "deploy-bot@example.com", bot.Email)
+		orgsQuery := &models.GetUserOrgListQuery{UserId: bot.Id}
+		require.NoError(t, bus.DispatchCtx(ctx, orgsQuery))
+		require.Len(t, orgsQuery.Result, 1)
+		require.Equal(t, orgID, orgsQuery.Result[0].OrgId)
+		require.Equal(t, models.ROLE_EDITOR, orgsQuery.Result[0].Role)
+
+		require.ElementsMatch(t, []string{"deploy-bot", "platform-admin"}, getTeamMembers(t, orgID))
+
+		folder := getFolder(t, orgID)
+		require.NotNil(t, folder)
+		require.Equal(t, "Infrastructure", folder.Title)
+		aclQuery := &models.GetDashboardAclInfoListQuery{OrgID: orgID, DashboardID: folder.Id}
+		require.NoError(t, sqlStore.GetDashboardAclInfoList(ctx, aclQuery))
+		require.Len(t, aclQuery.Result, 2)
+	})
+
+	t.Run("should remove the resources that are no longer provisioned", func(t *testing.T) {
+		writeConfig(t, prunedConfig)
+		provision(t, dir)
+
+		orgID := getOrgID(t)
+		require.Nil(t, getUser(t, "deploy-bot"))
+		require.NotNil(t, getUser(t, "platform-admin"))
+		require.Equal(t, []string{"platform-admin"}, getTeamMembers(t, orgID))
+		require.Nil(t, getFolder(t, orgID))
+	})
+
+	t.Run("should not remove anything when the configuration directory is missing", func(t *testing.T) {
+		provision(t, filepath.Join(dir, "missing"))
+
+		require.NotNil(t, getUser(t, "platform-admin"))
+	})
+
+	t.Run("should remove everything when the configuration is empty", func(t *testing.T) {
+		writeConfig(t, "")
+		provision(t, dir)
+
+		require.Nil(t, getUser(t, "platform-admin"))
+		query := &models.GetOrgByNameQuery{Name: "Platform"}
+		require.ErrorIs(t, bus.DispatchCtx(ctx, query), models.ErrOrgNotFound)
+	})
+	t.Run("should update but not remove the resources that existed before they were provisioned", func(t *testing.T) {
+		user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "admin", Email: "admin@localhost", SkipOrgSetup: true, IsAdmin: true})
+		require.NoError(t, err)
+		require.NoError(t, bus.DispatchCtx(ctx, &models.AddOrgUserCommand{OrgId: 1, UserId: user.Id, Role: models.ROLE_VIEWER}))
+
+		writeConfig(t, existingUserConfig)
+		provision(t, dir)
+
+		admin := getUser(t, "admin")
+		require.NotNil(t, admin)
+		require.Equal(t, "Administrator", admin.Name)
+		// isGrafanaAdmin is not set, so the permissions are kept
+		require.True(t, admin.IsAdmin)
+
+		writeConfig(t, "")
+		provision(t, dir)
+
+		admin = getUser(t, "admin")
+		require.NotNil(t, admin)
+		orgsQuery := &models.GetUserOrgListQuery{UserId: admin.Id}
+		require.NoError(t, bus.DispatchCtx(ctx, orgsQuery))
+		require.Len(t, orgsQuery.Result, 1)
+		require.Equal(t, int64(1), orgsQuery.Result[0].OrgId)
+		require.Equal(t, models.ROLE_ADMIN, orgsQuery.Result[0].Role)
+	})
+}
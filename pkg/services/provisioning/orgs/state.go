@@ -0,0 +1,203 @@
+package orgs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	stateNamespace = "provisioning.orgs"
+	stateKey       = "provisioned"
+)
+
+const (
+	kindOrg        = "org"
+	kindUser       = "user"
+	kindOrgMember  = "org_member"
+	kindTeam       = "team"
+	kindTeamMember = "team_member"
+	kindFolder     = "folder"
+)
+
+// pruneOrder is the order in which resources no longer provisioned are removed, so that
+// the members of a team are removed before the team and the organizations are removed last.
+var pruneOrder = map[string]int{
+	kindTeamMember: 0,
+	kindTeam:       1,
+	kindFolder:     2,
+	kindOrgMember:  3,
+	kindUser:       4,
+	kindOrg:        5,
+}
+
+// provisionedResource identifies a resource created by the provisioner.
+// Name is the name of an organization or a team, the login of a user or the UID of a folder,
+// and Login is the login of the member of an organization or a team.
+type provisionedResource struct {
+	Kind  string `json:"kind"`
+	OrgID int64  `json:"orgId,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Login string `json:"login,omitempty"`
+}
+
+// provisionedState is the set of resources provisioned by the last run of the provisioner.
+// It is stored to remove the resources that are removed from the configuration files.
+type provisionedState map[provisionedResource]struct{}
+
+func (s provisionedState) add(r provisionedResource) {
+	s[r] = struct{}{}
+}
+
+// runState is the state of a run of the provisioner.
+type runState struct {
+	previous provisionedState
+	current  provisionedState
+}
+
+// own adds the resource to the current state if it was created by the provisioner, in this run or in a
+// previous one. Resources that existed before they were provisioned, such as the admin user, are updated
+// from the configuration but are not owned by the provisioner, so they are not removed with it.
+func (s runState) own(r provisionedResource, created bool) {
+	if _, ok := s.previous[r]; created || ok {
+		s.current.add(r)
+	}
+}
+
+// missingFrom returns the resources of s that are not in other.
+func (s provisionedState) missingFrom(other provisionedState) provisionedState {
+	missing := provisionedState{}
+	for r := range s {
+		if _, ok := other[r]; !ok {
+			missing.add(r)
+		}
+	}
+	return missing
+}
+
+func (s provisionedState) sorted() []provisionedResource {
+	resources := make([]provisionedResource, 0, len(s))
+	for r := range s {
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		if a.Kind != b.Kind {
+			return pruneOrder[a.Kind] < pruneOrder[b.Kind]
+		}
+		if a.OrgID != b.OrgID {
+			return a.OrgID < b.OrgID
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Login < b.Login
+	})
+	return resources
+}
+
+func (op *OrgsProvisioner) getState(ctx context.Context) (provisionedState, error) {
+	state := provisionedState{}
+	value, ok, err := op.state.Get(ctx, stateKey)
+	if err != nil || !ok {
+		return state, err
+	}
+
+	var resources []provisionedResource
+	if err := json.Unmarshal([]byte(value), &resources); err != nil {
+		return nil, err
+	}
+	for _, r := range resources {
+		state.add(r)
+	}
+	return state, nil
+}
+
+func (op *OrgsProvisioner) setState(ctx context.Context, state provisionedState) error {
+	value, err := json.Marshal(state.sorted())
+	if err != nil {
+		return err
+	}
+	return op.state.Set(ctx, stateKey, string(value))
+}
+
+// prune removes a resource that is no longer provisioned. Resources that no longer exist are ignored.
+func (op *OrgsProvisioner) prune(ctx context.Context, r provisionedResource) error {
+	err := op.remove(ctx, r)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (op *OrgsProvisioner) remove(ctx context.Context, r provisionedResource) error {
+	switch r.Kind {
+	case kindTeamMember:
+		team, err := op.getTeam(ctx, r.OrgID, r.Name)
+		if err != nil {
+			return err
+		}
+		user, err := op.getUser(ctx, r.Login)
+		if err != nil {
+			return err
+		}
+		op.log.Info("removing user from team no longer provisioned", "login", r.Login, "team", r.Name, "org", r.OrgID)
+		return bus.DispatchCtx(ctx, &models.RemoveTeamMemberCommand{OrgId: r.OrgID, TeamId: team.Id, UserId: user.Id})
+
+	case kindTeam:
+		team, err := op.getTeam(ctx, r.OrgID, r.Name)
+		if err != nil {
+			return err
+		}
+		op.log.Info("deleting team no longer provisioned", "name", r.Name, "org", r.OrgID)
+		return bus.DispatchCtx(ctx, &models.DeleteTeamCommand{OrgId: r.OrgID, Id: team.Id})
+
+	case kindFolder:
+		op.log.Info("deleting folder no longer provisioned", "uid", r.Name, "org", r.OrgID)
+		_, err := op.folderService(r.OrgID).DeleteFolder(ctx, r.Name, false)
+		return err
+
+	case kindOrgMember:
+		user, err := op.getUser(ctx, r.Login)
+		if err != nil {
+			return err
+		}
+		op.log.Info("removing user from organization no longer provisioned", "login", r.Login, "org", r.OrgID)
+		return bus.DispatchCtx(ctx, &models.RemoveOrgUserCommand{OrgId: r.OrgID, UserId: user.Id})
+
+	case kindUser:
+		user, err := op.getUser(ctx, r.Name)
+		if err != nil {
+			return err
+		}
+		op.log.Info("deleting user no longer provisioned", "login", r.Name)
+		return bus.DispatchCtx(ctx, &models.DeleteUserCommand{UserId: user.Id})
+
+	case kindOrg:
+		query := &models.GetOrgByNameQuery{Name: r.Name}
+		if err := bus.DispatchCtx(ctx, query); err != nil {
+			return err
+		}
+		if query.Result.Id == 1 {
+			op.log.Warn("not deleting the main organization although it is no longer provisioned", "name", r.Name)
+			return nil
+		}
+		op.log.Info("deleting organization no longer provisioned", "name", r.Name)
+		return bus.DispatchCtx(ctx, &models.DeleteOrgCommand{Id: query.Result.Id})
+	}
+
+	return nil
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, models.ErrOrgNotFound) ||
+		errors.Is(err, models.ErrUserNotFound) ||
+		errors.Is(err, models.ErrOrgUserNotFound) ||
+		errors.Is(err, models.ErrTeamNotFound) ||
+		errors.Is(err, models.ErrTeamMemberNotFound) ||
+		errors.Is(err, models.ErrFolderNotFound)
+}
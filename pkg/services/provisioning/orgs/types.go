@@ -0,0 +1,193 @@
+package orgs
+
+import (
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// configVersion is used to figure out which API version a config uses.
+type configVersion struct {
+	APIVersion int64 `json:"apiVersion" yaml:"apiVersion"`
+}
+
+// orgsAsConfig is normalized data object for the organizations config data. Any config version should be mappable
+// to this type.
+type orgsAsConfig struct {
+	Orgs    []*orgFromConfig
+	Users   []*userFromConfig
+	Teams   []*teamFromConfig
+	Folders []*folderFromConfig
+}
+
+type orgFromConfig struct {
+	Name string
+}
+
+// orgRef references an organization either by its ID or by its name.
+type orgRef struct {
+	OrgID   int64
+	OrgName string
+}
+
+// userFromConfig is a user of the configuration. IsGrafanaAdmin is nil if the configuration does not set it,
+// in which case existing users keep their permissions.
+type userFromConfig struct {
+	Login          string
+	Email          string
+	Name           string
+	Password       string
+	IsGrafanaAdmin *bool
+	Orgs           []*orgMembershipFromConfig
+}
+
+type orgMembershipFromConfig struct {
+	orgRef
+	Role string
+}
+
+type teamFromConfig struct {
+	orgRef
+	Name    string
+	Email   string
+	Members []string
+}
+
+type folderFromConfig struct {
+	orgRef
+	UID         string
+	Title       string
+	Permissions []*folderPermissionFromConfig
+}
+
+type folderPermissionFromConfig struct {
+	Team       string
+	User       string
+	Role       string
+	Permission string
+}
+
+// orgsAsConfigV1 is mapping for the first version of the configs. This is mapped to its normalised version.
+type orgsAsConfigV1 struct {
+	Orgs    []*orgV1    `json:"orgs" yaml:"orgs"`
+	Users   []*userV1   `json:"users" yaml:"users"`
+	Teams   []*teamV1   `json:"teams" yaml:"teams"`
+	Folders []*folderV1 `json:"folders" yaml:"folders"`
+}
+
+type orgV1 struct {
+	Name values.StringValue `json:"name" yaml:"name"`
+}
+
+type orgRefV1 struct {
+	OrgID   values.Int64Value  `json:"orgId" yaml:"orgId"`
+	OrgName values.StringValue `json:"orgName" yaml:"orgName"`
+}
+
+type userV1 struct {
+	Login          values.StringValue `json:"login" yaml:"login"`
+	Email          values.StringValue `json:"email" yaml:"email"`
+	Name           values.StringValue `json:"name" yaml:"name"`
+	Password       values.StringValue `json:"password" yaml:"password"`
+	IsGrafanaAdmin *values.BoolValue  `json:"isGrafanaAdmin" yaml:"isGrafanaAdmin"`
+	Orgs           []*orgMembershipV1 `json:"orgs" yaml:"orgs"`
+}
+
+type orgMembershipV1 struct {
+	orgRefV1 `yaml:",inline"`
+	Role     values.StringValue `json:"role" yaml:"role"`
+}
+
+type teamV1 struct {
+	orgRefV1 `yaml:",inline"`
+	Name     values.StringValue   `json:"name" yaml:"name"`
+	Email    values.StringValue   `json:"email" yaml:"email"`
+	Members  []values.StringValue `json:"members" yaml:"members"`
+}
+
+type folderV1 struct {
+	orgRefV1    `yaml:",inline"`
+	UID         values.StringValue    `json:"uid" yaml:"uid"`
+	Title       values.StringValue    `json:"title" yaml:"title"`
+	Permissions []*folderPermissionV1 `json:"permissions" yaml:"permissions"`
+}
+
+type folderPermissionV1 struct {
+	Team       values.StringValue `json:"team" yaml:"team"`
+	User       values.StringValue `json:"user" yaml:"user"`
+	Role       values.StringValue `json:"role" yaml:"role"`
+	Permission values.StringValue `json:"permission" yaml:"permission"`
+}
+
+func (ref orgRefV1) mapToOrgRef() orgRef {
+	return orgRef{
+		OrgID:   ref.OrgID.Value(),
+		OrgName: ref.OrgName.Value(),
+	}
+}
+
+// mapToOrgsAsConfig maps config syntax to normalized orgsAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *orgsAsConfigV1) mapToOrgsAsConfig() *orgsAsConfig {
+	r := &orgsAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, org := range cfg.Orgs {
+		r.Orgs = append(r.Orgs, &orgFromConfig{Name: org.Name.Value()})
+	}
+
+	for _, user := range cfg.Users {
+		u := &userFromConfig{
+			Login:    user.Login.Value(),
+			Email:    user.Email.Value(),
+			Name:     user.Name.Value(),
+			Password: user.Password.Value(),
+		}
+		if user.IsGrafanaAdmin != nil {
+			isGrafanaAdmin := user.IsGrafanaAdmin.Value()
+			u.IsGrafanaAdmin = &isGrafanaAdmin
+		}
+		for _, membership := range user.Orgs {
+			u.Orgs = append(u.Orgs, &orgMembershipFromConfig{
+				orgRef: membership.mapToOrgRef(),
+				Role:   membership.Role.Value(),
+			})
+		}
+		r.Users = append(r.Users, u)
+	}
+
+	for _, team := range cfg.Teams {
+		t := &teamFromConfig{
+			orgRef: team.mapToOrgRef(),
+			Name:   team.Name.Value(),
+			Email:  team.Email.Value(),
+		}
+		for _, member := range team.Members {
+			t.Members = append(t.Members, member.Value())
+		}
+		r.Teams = append(r.Teams, t)
+	}
+
+	for _, folder := range cfg.Folders {
+		f := &folderFromConfig{
+			orgRef: folder.mapToOrgRef(),
+			UID:    folder.UID.Value(),
+			Title:  folder.Title.Value(),
+		}
+		// folders without a permissions key keep their permissions, an empty list removes them
+		if folder.Permissions != nil {
+			f.Permissions = make([]*folderPermissionFromConfig, 0, len(folder.Permissions))
+		}
+		for _, permission := range folder.Permissions {
+			f.Permissions = append(f.Permissions, &folderPermissionFromConfig{
+				Team:       permission.Team.Value(),
+				User:       permission.User.Value(),
+				Role:       permission.Role.Value(),
+				Permission: permission.Permission.Value(),
+			})
+		}
+		r.Folders = append(r.Folders, f)
+	}
+
+	return r
+}
@@ -9,9 +9,12 @@ import (
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
+	"github.com/grafana/grafana/pkg/services/provisioning/orgs"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -19,17 +22,20 @@ import (
 )
 
 func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginStore plugifaces.Store,
-	encryptionService encryption.Service) (*ProvisioningServiceImpl, error) {
+	encryptionService encryption.Service, alertNG *ngalert.AlertNG) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                     cfg,
 		SQLStore:                sqlStore,
 		pluginStore:             pluginStore,
 		EncryptionService:       encryptionService,
+		AlertNG:                 alertNG,
 		log:                     log.New("provisioning"),
 		newDashboardProvisioner: dashboards.New,
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alerting.Provision,
+		provisionOrgs:           orgs.Provision,
 	}
 	return s, nil
 }
@@ -37,12 +43,15 @@ func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginStore p
 type ProvisioningService interface {
 	registry.BackgroundService
 	RunInitProvisioners(ctx context.Context) error
+	ProvisionOrgs(ctx context.Context) error
 	ProvisionDatasources(ctx context.Context) error
 	ProvisionPlugins() error
 	ProvisionNotifications(ctx context.Context) error
+	ProvisionAlerting(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	GetDashboardProvisionersStatus() []*dashboards.ProvisionerStatus
 }
 
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
@@ -53,6 +62,8 @@ func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alerting.Provision,
+		provisionOrgs:           orgs.Provision,
 	}
 }
 
@@ -62,6 +73,8 @@ func newProvisioningServiceImpl(
 	provisionNotifiers func(context.Context, string, encryption.Service) error,
 	provisionDatasources func(context.Context, string) error,
 	provisionPlugins func(string, plugifaces.Store) error,
+	provisionAlerting func(context.Context, string, *ngalert.AlertNG) error,
+	provisionOrgs func(context.Context, string, *sqlstore.SQLStore) error,
 ) *ProvisioningServiceImpl {
 	return &ProvisioningServiceImpl{
 		log:                     log.New("provisioning"),
@@ -69,6 +82,8 @@ func newProvisioningServiceImpl(
 		provisionNotifiers:      provisionNotifiers,
 		provisionDatasources:    provisionDatasources,
 		provisionPlugins:        provisionPlugins,
+		provisionAlerting:       provisionAlerting,
+		provisionOrgs:           provisionOrgs,
 	}
 }
 
@@ -77,6 +92,7 @@ type ProvisioningServiceImpl struct {
 	SQLStore                *sqlstore.SQLStore
 	pluginStore             plugifaces.Store
 	EncryptionService       encryption.Service
+	AlertNG                 *ngalert.AlertNG
 	log                     log.Logger
 	pollingCtxCancel        context.CancelFunc
 	newDashboardProvisioner dashboards.DashboardProvisionerFactory
@@ -84,11 +100,18 @@ type ProvisioningServiceImpl struct {
 	provisionNotifiers      func(context.Context, string, encryption.Service) error
 	provisionDatasources    func(context.Context, string) error
 	provisionPlugins        func(string, plugifaces.Store) error
+	provisionAlerting       func(context.Context, string, *ngalert.AlertNG) error
+	provisionOrgs           func(context.Context, string, *sqlstore.SQLStore) error
 	mutex                   sync.Mutex
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
-	err := ps.ProvisionDatasources(ctx)
+	err := ps.ProvisionOrgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = ps.ProvisionDatasources(ctx)
 	if err != nil {
 		return err
 	}
@@ -103,6 +126,11 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	err = ps.ProvisionAlerting(ctx)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -135,6 +163,12 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 	}
 }
 
+func (ps *ProvisioningServiceImpl) ProvisionOrgs(ctx context.Context) error {
+	orgsPath := filepath.Join(ps.Cfg.ProvisioningPath, "orgs")
+	err := ps.provisionOrgs(ctx, orgsPath, ps.SQLStore)
+	return errutil.Wrap("Organization provisioning error", err)
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) error {
 	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
 	err := ps.provisionDatasources(ctx, datasourcePath)
@@ -153,9 +187,15 @@ func (ps *ProvisioningServiceImpl) ProvisionNotifications(ctx context.Context) e
 	return errutil.Wrap("Alert notification provisioning error", err)
 }
 
+func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
+	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	err := ps.provisionAlerting(ctx, alertingPath, ps.AlertNG)
+	return errutil.Wrap("Alerting provisioning error", err)
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
-	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, ps.SQLStore)
+	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, ps.Cfg.DataPath, ps.SQLStore)
 	if err != nil {
 		return errutil.Wrap("Failed to create provisioner", err)
 	}
@@ -184,6 +224,13 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+func (ps *ProvisioningServiceImpl) GetDashboardProvisionersStatus() []*dashboards.ProvisionerStatus {
+	if ps.dashboardProvisioner == nil {
+		return []*dashboards.ProvisionerStatus{}
+	}
+	return ps.dashboardProvisioner.GetProvisionersStatus()
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
@@ -1,27 +1,37 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []interface{}
+	ProvisionOrgs                       []interface{}
 	ProvisionDatasources                []interface{}
 	ProvisionPlugins                    []interface{}
 	ProvisionNotifications              []interface{}
+	ProvisionAlerting                   []interface{}
 	ProvisionDashboards                 []interface{}
 	GetDashboardProvisionerResolvedPath []interface{}
 	GetAllowUIUpdatesFromConfig         []interface{}
+	GetDashboardProvisionersStatus      []interface{}
 	Run                                 []interface{}
 }
 
 type ProvisioningServiceMock struct {
 	Calls                                   *Calls
 	RunInitProvisionersFunc                 func(ctx context.Context) error
+	ProvisionOrgsFunc                       func() error
 	ProvisionDatasourcesFunc                func(ctx context.Context) error
 	ProvisionPluginsFunc                    func() error
 	ProvisionNotificationsFunc              func() error
+	ProvisionAlertingFunc                   func() error
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	GetDashboardProvisionersStatusFunc      func() []*dashboards.ProvisionerStatus
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -39,6 +49,14 @@ func (mock *ProvisioningServiceMock) RunInitProvisioners(ctx context.Context) er
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionOrgs(ctx context.Context) error {
+	mock.Calls.ProvisionOrgs = append(mock.Calls.ProvisionOrgs, nil)
+	if mock.ProvisionOrgsFunc != nil {
+		return mock.ProvisionOrgsFunc()
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) ProvisionDatasources(ctx context.Context) error {
 	mock.Calls.ProvisionDatasources = append(mock.Calls.ProvisionDatasources, nil)
 	if mock.ProvisionDatasourcesFunc != nil {
@@ -63,6 +81,14 @@ func (mock *ProvisioningServiceMock) ProvisionNotifications(ctx context.Context)
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionAlerting(ctx context.Context) error {
+	mock.Calls.ProvisionAlerting = append(mock.Calls.ProvisionAlerting, nil)
+	if mock.ProvisionAlertingFunc != nil {
+		return mock.ProvisionAlertingFunc()
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) ProvisionDashboards(ctx context.Context) error {
 	mock.Calls.ProvisionDashboards = append(mock.Calls.ProvisionDashboards, nil)
 	if mock.ProvisionDashboardsFunc != nil {
@@ -87,6 +113,14 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) GetDashboardProvisionersStatus() []*dashboards.ProvisionerStatus {
+	mock.Calls.GetDashboardProvisionersStatus = append(mock.Calls.GetDashboardProvisionersStatus, nil)
+	if mock.GetDashboardProvisionersStatusFunc != nil {
+		return mock.GetDashboardProvisionersStatusFunc()
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
@@ -92,12 +92,14 @@ func setup() *serviceTestStruct {
 	}
 
 	serviceTest.service = newProvisioningServiceImpl(
-		func(context.Context, string, dboards.Store) (dashboards.DashboardProvisioner, error) {
+		func(context.Context, string, string, dboards.Store) (dashboards.DashboardProvisioner, error) {
 			return serviceTest.mock, nil
 		},
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 	)
 	serviceTest.service.Cfg = setting.NewCfg()
 
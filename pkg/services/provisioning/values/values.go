@@ -202,6 +202,14 @@ func transformInterface(i interface{}) (interface{}, interface{}, error) {
 	case reflect.Slice:
 		return transformSlice(i.([]interface{}))
 	case reflect.Map:
+		// yaml.v3 decodes mappings with string keys
+		if m, ok := i.(map[string]interface{}); ok {
+			converted := make(map[interface{}]interface{}, len(m))
+			for k, v := range m {
+				converted[k] = v
+			}
+			return transformMap(converted)
+		}
 		return transformMap(i.(map[interface{}]interface{}))
 	case reflect.String:
 		return interpolateValue(i.(string))
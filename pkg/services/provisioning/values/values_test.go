@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/ini.v1"
 	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 func TestValues(t *testing.T) {
@@ -218,6 +219,39 @@ func TestValues(t *testing.T) {
 					"anchored":  "$INT",
 				})
 			})
+
+			t.Run("Should unmarshal variable nesting with yaml.v3", func(t *testing.T) {
+				doc := `
+                 val:
+                   one: 1
+                   two:
+                     - three:
+                         inside: $STRING
+                   four:
+                     nested:
+                       onemore: $INT
+               `
+				d := &Data{}
+				err := yamlv3.Unmarshal([]byte(doc), d)
+				require.NoError(t, err)
+
+				type stringMap = map[string]interface{}
+				require.Equal(t, stringMap{
+					"one": 1,
+					"two": []interface{}{
+						stringMap{
+							"three": stringMap{
+								"inside": "test",
+							},
+						},
+					},
+					"four": stringMap{
+						"nested": stringMap{
+							"onemore": "1",
+						},
+					},
+				}, d.Val.Value())
+			})
 		})
 
 		t.Run("StringMapValue", func(t *testing.T) {
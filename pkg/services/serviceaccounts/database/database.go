@@ -0,0 +1,254 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type ServiceAccountsStoreImpl struct {
+	sqlStore *sqlstore.SQLStore
+}
+
+func ProvideServiceAccountsStore(sqlStore *sqlstore.SQLStore) *ServiceAccountsStoreImpl {
+	return &ServiceAccountsStoreImpl{
+		sqlStore: sqlStore,
+	}
+}
+
+// CreateServiceAccount creates the user of the service account and adds it to the organization orgID.
+func (s *ServiceAccountsStoreImpl) CreateServiceAccount(ctx context.Context, orgID int64, form serviceaccounts.CreateServiceAccountForm) (*serviceaccounts.ServiceAccountDTO, error) {
+	if form.Name == "" {
+		return nil, serviceaccounts.ErrServiceAccountNameMissing
+	}
+	if form.Role == "" {
+		form.Role = models.ROLE_VIEWER
+	}
+
+	// the login of the service account is unique across organizations, the name within the organization
+	login := fmt.Sprintf("sa-%d-%s", orgID, models.SlugifyTitle(form.Name))
+	var id int64
+	err := s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("login = ? OR email = ?", login, login).Get(&models.User{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return serviceaccounts.ErrServiceAccountAlreadyExists
+		}
+
+		now := time.Now()
+		user := models.User{
+			Email:            login,
+			Name:             form.Name,
+			Login:            login,
+			IsDisabled:       form.IsDisabled,
+			IsServiceAccount: true,
+			OrgId:            orgID,
+			Created:          now,
+			Updated:          now,
+			LastSeenAt:       now.AddDate(-10, 0, 0),
+		}
+		if user.Salt, err = util.GetRandomString(10); err != nil {
+			return err
+		}
+		if user.Rands, err = util.GetRandomString(10); err != nil {
+			return err
+		}
+
+		sess.UseBool("is_admin", "is_disabled", "is_service_account")
+		if _, err := sess.Insert(&user); err != nil {
+			return err
+		}
+
+		orgUser := models.OrgUser{
+			OrgId:   orgID,
+			UserId:  user.Id,
+			Role:    form.Role,
+			Created: now,
+			Updated: now,
+		}
+		if _, err := sess.Insert(&orgUser); err != nil {
+			return err
+		}
+
+		id = user.Id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetServiceAccount(ctx, orgID, id)
+}
+
+func (s *ServiceAccountsStoreImpl) ListServiceAccounts(ctx context.Context, orgID int64) ([]*serviceaccounts.ServiceAccountDTO, error) {
+	result := make([]*serviceaccounts.ServiceAccountDTO, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL(s.serviceAccountsSQL()+" ORDER BY u.name", orgID, s.sqlStore.Dialect.BooleanStr(true)).Find(&result)
+	})
+	return result, err
+}
+
+func (s *ServiceAccountsStoreImpl) GetServiceAccount(ctx context.Context, orgID, serviceAccountID int64) (*serviceaccounts.ServiceAccountDTO, error) {
+	var result serviceaccounts.ServiceAccountDTO
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.SQL(s.serviceAccountsSQL()+" AND u.id = ?", orgID, s.sqlStore.Dialect.BooleanStr(true), serviceAccountID).Get(&result)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return serviceaccounts.ErrServiceAccountNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *ServiceAccountsStoreImpl) UpdateServiceAccount(ctx context.Context, orgID, serviceAccountID int64, form serviceaccounts.UpdateServiceAccountForm) (*serviceaccounts.ServiceAccountDTO, error) {
+	if form.Name != nil && *form.Name == "" {
+		return nil, serviceaccounts.ErrServiceAccountNameMissing
+	}
+
+	serviceAccount, err := s.GetServiceAccount(ctx, orgID, serviceAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		now := time.Now()
+		user := models.User{
+			Name:       serviceAccount.Name,
+			IsDisabled: serviceAccount.IsDisabled,
+			Updated:    now,
+		}
+		if form.Name != nil {
+			user.Name = *form.Name
+		}
+		if form.IsDisabled != nil {
+			user.IsDisabled = *form.IsDisabled
+		}
+		if _, err := sess.ID(serviceAccountID).Cols("name", "is_disabled", "updated").Update(&user); err != nil {
+			return err
+		}
+
+		if form.Role != nil {
+			orgUser := models.OrgUser{Role: *form.Role, Updated: now}
+			if _, err := sess.Where("org_id = ? AND user_id = ?", orgID, serviceAccountID).Cols("role", "updated").Update(&orgUser); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetServiceAccount(ctx, orgID, serviceAccountID)
+}
+
+// DeleteServiceAccount deletes the service account and its tokens.
+func (s *ServiceAccountsStoreImpl) DeleteServiceAccount(ctx context.Context, orgID, serviceAccountID int64) error {
+	if _, err := s.GetServiceAccount(ctx, orgID, serviceAccountID); err != nil {
+		return err
+	}
+	return sqlstore.DeleteUser(ctx, &models.DeleteUserCommand{UserId: serviceAccountID})
+}
+
+func (s *ServiceAccountsStoreImpl) ListTokens(ctx context.Context, orgID, serviceAccountID int64) ([]*models.ApiKey, error) {
+	result := make([]*models.ApiKey, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ? AND service_account_id = ?", orgID, serviceAccountID).
+			Asc("name").
+			Find(&result)
+	})
+	return result, err
+}
+
+// AddServiceAccountToken adds an API key, with the role of the service account, linked to the service account.
+func (s *ServiceAccountsStoreImpl) AddServiceAccountToken(ctx context.Context, cmd *serviceaccounts.AddServiceAccountTokenCommand) error {
+	serviceAccount, err := s.GetServiceAccount(ctx, cmd.OrgId, cmd.ServiceAccountId)
+	if err != nil {
+		return err
+	}
+
+	addKeyCmd := &models.AddApiKeyCommand{
+		Name:             cmd.Name,
+		Role:             serviceAccount.Role,
+		OrgId:            cmd.OrgId,
+		Key:              cmd.Key,
+		SecondsToLive:    cmd.SecondsToLive,
+		ServiceAccountId: serviceAccount.Id,
+	}
+	if err := sqlstore.AddAPIKey(ctx, addKeyCmd); err != nil {
+		return err
+	}
+
+	cmd.Result = addKeyCmd.Result
+	return nil
+}
+
+func (s *ServiceAccountsStoreImpl) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error {
+	return s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		result, err := sess.Exec("DELETE FROM api_key WHERE id = ? AND org_id = ? AND service_account_id = ?", tokenID, orgID, serviceAccountID)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return serviceaccounts.ErrServiceAccountTokenNotFound
+		}
+		return nil
+	})
+}
+
+// LinkAPIKeyToServiceAccount makes the API key authenticate requests as the service account.
+func (s *ServiceAccountsStoreImpl) LinkAPIKeyToServiceAccount(ctx context.Context, orgID, apiKeyID, serviceAccountID int64) error {
+	if _, err := s.GetServiceAccount(ctx, orgID, serviceAccountID); err != nil {
+		return err
+	}
+
+	return s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		result, err := sess.Exec("UPDATE api_key SET service_account_id = ? WHERE id = ? AND org_id = ?", serviceAccountID, apiKeyID, orgID)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrApiKeyNotFound
+		}
+		return nil
+	})
+}
+
+// serviceAccountsSQL selects the service accounts of an organization, its parameters are the organization id
+// and the true value of the dialect.
+func (s *ServiceAccountsStoreImpl) serviceAccountsSQL() string {
+	return `SELECT
+		u.id,
+		org_user.org_id,
+		u.name,
+		u.login,
+		org_user.role,
+		u.is_disabled,
+		u.created,
+		u.updated,
+		(SELECT COUNT(*) FROM api_key WHERE api_key.service_account_id = u.id) AS tokens
+		FROM ` + s.sqlStore.Dialect.Quote("user") + ` AS u
+		INNER JOIN org_user ON org_user.user_id = u.id
+		WHERE org_user.org_id = ? AND u.is_service_account = ?`
+}
@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestEnv(t testing.TB) (*ServiceAccountsStoreImpl, *sqlstore.SQLStore) {
+	t.Helper()
+
+	sqlStore := sqlstore.InitTestDB(t)
+	return ProvideServiceAccountsStore(sqlStore), sqlStore
+}
+
+func createServiceAccount(t *testing.T, store *ServiceAccountsStoreImpl, orgID int64, name string, role models.RoleType) *serviceaccounts.ServiceAccountDTO {
+	t.Helper()
+
+	serviceAccount, err := store.CreateServiceAccount(context.Background(), orgID, serviceaccounts.CreateServiceAccountForm{
+		Name: name,
+		Role: role,
+	})
+	require.NoError(t, err)
+	return serviceAccount
+}
+
+func TestServiceAccountsStore_ServiceAccounts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create, update and delete a service account", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+
+		created := createServiceAccount(t, store, 1, "Grafana Agent", models.ROLE_EDITOR)
+		assert.Equal(t, "Grafana Agent", created.Name)
+		assert.Equal(t, "sa-1-grafana-agent", created.Login)
+		assert.Equal(t, int64(1), created.OrgId)
+		assert.Equal(t, models.ROLE_EDITOR, created.Role)
+		assert.False(t, created.IsDisabled)
+
+		name := "Terraform"
+		role := models.ROLE_ADMIN
+		disabled := true
+		updated, err := store.UpdateServiceAccount(ctx, 1, created.Id, serviceaccounts.UpdateServiceAccountForm{
+			Name:       &name,
+			Role:       &role,
+			IsDisabled: &disabled,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Terraform", updated.Name)
+		assert.Equal(t, models.ROLE_ADMIN, updated.Role)
+		assert.True(t, updated.IsDisabled)
+
+		require.NoError(t, store.DeleteServiceAccount(ctx, 1, created.Id))
+		_, err = store.GetServiceAccount(ctx, 1, created.Id)
+		assert.ErrorIs(t, err, serviceaccounts.ErrServiceAccountNotFound)
+	})
+
+	t.Run("should default the role to viewer", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+
+		created := createServiceAccount(t, store, 1, "Grafana Agent", "")
+		assert.Equal(t, models.ROLE_VIEWER, created.Role)
+	})
+
+	t.Run("should not create two service accounts with the same name in an organization", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+
+		createServiceAccount(t, store, 1, "Grafana Agent", models.ROLE_VIEWER)
+		_, err := store.CreateServiceAccount(ctx, 1, serviceaccounts.CreateServiceAccountForm{Name: "Grafana Agent"})
+		assert.ErrorIs(t, err, serviceaccounts.ErrServiceAccountAlreadyExists)
+
+		createServiceAccount(t, store, 2, "Grafana Agent", models.ROLE_VIEWER)
+	})
+
+	t.Run("should require a name", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+
+		_, err := store.CreateServiceAccount(ctx, 1, serviceaccounts.CreateServiceAccountForm{})
+		assert.ErrorIs(t, err, serviceaccounts.ErrServiceAccountNameMissing)
+	})
+
+	t.Run("should list the service accounts of an organization only", func(t *testing.T) {
+		store, sqlStore := setupTestEnv(t)
+
+		_, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "user", Email: "user@example.org"})
+		require.NoError(t, err)
+		createServiceAccount(t, store, 1, "Terraform", models.ROLE_ADMIN)
+		createServiceAccount(t, store, 1, "Grafana Agent", models.ROLE_VIEWER)
+		other := createServiceAccount(t, store, 2, "Other", models.ROLE_VIEWER)
+
+		result, err := store.ListServiceAccounts(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "Grafana Agent", result[0].Name)
+		assert.Equal(t, "Terraform", result[1].Name)
+
+		_, err = store.GetServiceAccount(ctx, 1, other.Id)
+		assert.ErrorIs(t, err, serviceaccounts.ErrServiceAccountNotFound)
+	})
+
+	t.Run("should not list service accounts as users", func(t *testing.T) {
+		store, sqlStore := setupTestEnv(t)
+
+		createServiceAccount(t, store, 1, "Grafana Agent", models.ROLE_VIEWER)
+
+		query := &models.SearchUsersQuery{Page: 1, Limit: 10}
+		require.NoError(t, sqlstore.SearchUsers(ctx, query))
+		assert.Empty(t, query.Result.Users)
+
+		orgUsers := &models.GetOrgUsersQuery{OrgId: 1}
+		require.NoError(t, sqlStore.GetOrgUsers(ctx, orgUsers))
+		assert.Empty(t, orgUsers.Result)
+	})
+}
+
+func TestServiceAccountsStore_Tokens(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should add, list and delete the tokens of a service account", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+
+		serviceAccount := createServiceAccount(t, store, 1, "Grafana Agent", models.ROLE_EDITOR)
+		for _, name := range []string{"rotated", "current"} {
+			cmd := &serviceaccounts.AddServiceAccountTokenCommand{
+				Name:             name,
+				SecondsToLive:    3600,
+				OrgId:            1,
+				ServiceAccountId: serviceAccount.Id,
+				Key:              "key-" + name,
+			}
+			require.NoError(t, store.AddServiceAccountToken(ctx, cmd))
+			assert.Equal(t, models.ROLE_EDITOR, cmd.Result.Role)
+			assert.Equal(t, serviceAccount.Id, cmd.Result.ServiceAccountId)
+			assert.NotNil(t, cmd.Result.Expires)
+		}
+
+		tokens, err := store.ListTokens(ctx, 1, serviceAccount.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		assert.Equal(t, "current", tokens[0].Name)
+
+		serviceAccount, err = store.GetServiceAccount(ctx, 1, serviceAccount.Id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), serviceAccount.Tokens)
+
+		keys := &models.GetApiKeysQuery{OrgId: 1}
+		require.NoError(t, sqlstore.GetAPIKeys(ctx, keys))
+		assert.Empty(t, keys.Result)
+
+		require.NoError(t, store.DeleteServiceAccountToken(ctx, 1, serviceAccount.Id, tokens[0].Id))
+		err = store.DeleteServiceAccountToken(ctx, 1, serviceAccount.Id, tokens[0].Id)
+		assert.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+
+		require.NoError(t, store.DeleteServiceAccount(ctx, 1, serviceAccount.Id))
+		key := &models.GetApiKeyByIdQuery{ApiKeyId: tokens[1].Id}
+		assert.ErrorIs(t, sqlstore.GetApiKeyById(key), models.ErrInvalidApiKey)
+	})
+
+	t.Run("should not add tokens to the service accounts of another organization", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+
+		serviceAccount := createServiceAccount(t, store, 2, "Grafana Agent", models.ROLE_EDITOR)
+		err := store.AddServiceAccountToken(ctx, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:             "token",
+			OrgId:            1,
+			ServiceAccountId: serviceAccount.Id,
+			Key:              "key",
+		})
+		assert.ErrorIs(t, err, serviceaccounts.ErrServiceAccountNotFound)
+	})
+
+	t.Run("should link an API key to a service account", func(t *testing.T) {
+		store, _ := setupTestEnv(t)
+
+		serviceAccount := createServiceAccount(t, store, 1, "Grafana Agent", models.ROLE_EDITOR)
+		cmd := &models.AddApiKeyCommand{Name: "key", Role: models.ROLE_EDITOR, OrgId: 1, Key: "key"}
+		require.NoError(t, sqlstore.AddAPIKey(ctx, cmd))
+
+		require.NoError(t, store.LinkAPIKeyToServiceAccount(ctx, 1, cmd.Result.Id, serviceAccount.Id))
+		tokens, err := store.ListTokens(ctx, 1, serviceAccount.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, cmd.Result.Id, tokens[0].Id)
+
+		err = store.LinkAPIKeyToServiceAccount(ctx, 2, cmd.Result.Id, serviceAccount.Id)
+		assert.ErrorIs(t, err, serviceaccounts.ErrServiceAccountNotFound)
+	})
+}
@@ -0,0 +1,243 @@
+package manager
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-macaron/binding"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmiddleware "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *ServiceAccountsService) registerAPIEndpoints() {
+	if s.RouteRegister == nil {
+		return
+	}
+
+	authorize := acmiddleware.Middleware(s.AccessControl)
+	s.RouteRegister.Group("/api/serviceaccounts", func(r routing.RouteRegister) {
+		r.Get("/", authorize(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeAll)), routing.Wrap(s.listServiceAccountsHandler))
+		r.Post("/", authorize(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(serviceaccounts.ActionCreate, serviceaccounts.ScopeAll)), binding.Bind(serviceaccounts.CreateServiceAccountForm{}), routing.Wrap(s.createServiceAccountHandler))
+		r.Get("/:serviceAccountId", authorize(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(s.getServiceAccountHandler))
+		r.Patch("/:serviceAccountId", authorize(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), binding.Bind(serviceaccounts.UpdateServiceAccountForm{}), routing.Wrap(s.updateServiceAccountHandler))
+		r.Delete("/:serviceAccountId", authorize(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(serviceaccounts.ActionDelete, serviceaccounts.ScopeID)), routing.Wrap(s.deleteServiceAccountHandler))
+
+		r.Get("/:serviceAccountId/tokens", authorize(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(s.listTokensHandler))
+		r.Post("/:serviceAccountId/tokens", authorize(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), binding.Bind(serviceaccounts.AddServiceAccountTokenCommand{}), routing.Wrap(s.addTokenHandler))
+		r.Delete("/:serviceAccountId/tokens/:tokenId", authorize(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(s.deleteTokenHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// listServiceAccountsHandler handles GET /api/serviceaccounts.
+func (s *ServiceAccountsService) listServiceAccountsHandler(c *models.ReqContext) response.Response {
+	result, err := s.store.ListServiceAccounts(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return toServiceAccountError(err, "Failed to list service accounts")
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// createServiceAccountHandler handles POST /api/serviceaccounts.
+func (s *ServiceAccountsService) createServiceAccountHandler(c *models.ReqContext, form serviceaccounts.CreateServiceAccountForm) response.Response {
+	if form.Role == "" {
+		form.Role = models.ROLE_VIEWER
+	}
+	if !form.Role.IsValid() {
+		return response.Error(http.StatusBadRequest, "Invalid role specified", nil)
+	}
+	if resp := checkRole(c, form.Role); resp != nil {
+		return resp
+	}
+
+	serviceAccount, err := s.store.CreateServiceAccount(c.Req.Context(), c.OrgId, form)
+	if err != nil {
+		return toServiceAccountError(err, "Failed to create service account")
+	}
+	return response.JSON(http.StatusCreated, serviceAccount)
+}
+
+// getServiceAccountHandler handles GET /api/serviceaccounts/:serviceAccountId.
+func (s *ServiceAccountsService) getServiceAccountHandler(c *models.ReqContext) response.Response {
+	serviceAccount, resp := s.getServiceAccount(c)
+	if resp != nil {
+		return resp
+	}
+	return response.JSON(http.StatusOK, serviceAccount)
+}
+
+// updateServiceAccountHandler handles PATCH /api/serviceaccounts/:serviceAccountId.
+func (s *ServiceAccountsService) updateServiceAccountHandler(c *models.ReqContext, form serviceaccounts.UpdateServiceAccountForm) response.Response {
+	serviceAccount, resp := s.getServiceAccount(c)
+	if resp != nil {
+		return resp
+	}
+	if form.Role != nil {
+		if !form.Role.IsValid() {
+			return response.Error(http.StatusBadRequest, "Invalid role specified", nil)
+		}
+		if resp := checkRole(c, *form.Role); resp != nil {
+			return resp
+		}
+	}
+
+	serviceAccount, err := s.store.UpdateServiceAccount(c.Req.Context(), c.OrgId, serviceAccount.Id, form)
+	if err != nil {
+		return toServiceAccountError(err, "Failed to update service account")
+	}
+	return response.JSON(http.StatusOK, serviceAccount)
+}
+
+// deleteServiceAccountHandler handles DELETE /api/serviceaccounts/:serviceAccountId.
+func (s *ServiceAccountsService) deleteServiceAccountHandler(c *models.ReqContext) response.Response {
+	id, resp := getServiceAccountID(c)
+	if resp != nil {
+		return resp
+	}
+
+	if err := s.store.DeleteServiceAccount(c.Req.Context(), c.OrgId, id); err != nil {
+		return toServiceAccountError(err, "Failed to delete service account")
+	}
+	return response.Success("Service account deleted")
+}
+
+// listTokensHandler handles GET /api/serviceaccounts/:serviceAccountId/tokens.
+func (s *ServiceAccountsService) listTokensHandler(c *models.ReqContext) response.Response {
+	serviceAccount, resp := s.getServiceAccount(c)
+	if resp != nil {
+		return resp
+	}
+
+	tokens, err := s.store.ListTokens(c.Req.Context(), c.OrgId, serviceAccount.Id)
+	if err != nil {
+		return toServiceAccountError(err, "Failed to list service account tokens")
+	}
+
+	now := time.Now()
+	result := make([]*serviceaccounts.TokenDTO, len(tokens))
+	for i, t := range tokens {
+		token := &serviceaccounts.TokenDTO{
+			Id:      t.Id,
+			Name:    t.Name,
+			Created: t.Created,
+		}
+		if t.Expires != nil {
+			expiration := time.Unix(*t.Expires, 0)
+			token.Expiration = &expiration
+			token.HasExpired = expiration.Before(now)
+		}
+		result[i] = token
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// addTokenHandler handles POST /api/serviceaccounts/:serviceAccountId/tokens.
+func (s *ServiceAccountsService) addTokenHandler(c *models.ReqContext, cmd serviceaccounts.AddServiceAccountTokenCommand) response.Response {
+	serviceAccount, resp := s.getServiceAccount(c)
+	if resp != nil {
+		return resp
+	}
+	// a token acts with the role of its service account
+	if resp := checkRole(c, serviceAccount.Role); resp != nil {
+		return resp
+	}
+
+	if s.Cfg.ApiKeyMaxSecondsToLive != -1 {
+		if cmd.SecondsToLive == 0 {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
+		}
+		if cmd.SecondsToLive > s.Cfg.ApiKeyMaxSecondsToLive {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
+		}
+	}
+
+	newKeyInfo, err := apikeygen.New(c.OrgId, cmd.Name)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Generating service account token failed", err)
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.ServiceAccountId = serviceAccount.Id
+	cmd.Key = newKeyInfo.HashedKey
+	if err := s.store.AddServiceAccountToken(c.Req.Context(), &cmd); err != nil {
+		return toServiceAccountError(err, "Failed to add service account token")
+	}
+
+	return response.JSON(http.StatusOK, &dtos.NewApiKeyResult{
+		ID:   cmd.Result.Id,
+		Name: cmd.Result.Name,
+		Key:  newKeyInfo.ClientSecret,
+	})
+}
+
+// deleteTokenHandler handles DELETE /api/serviceaccounts/:serviceAccountId/tokens/:tokenId.
+func (s *ServiceAccountsService) deleteTokenHandler(c *models.ReqContext) response.Response {
+	id, resp := getServiceAccountID(c)
+	if resp != nil {
+		return resp
+	}
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "tokenId is invalid", err)
+	}
+
+	if err := s.store.DeleteServiceAccountToken(c.Req.Context(), c.OrgId, id, tokenID); err != nil {
+		return toServiceAccountError(err, "Failed to delete service account token")
+	}
+	return response.Success("Service account token deleted")
+}
+
+// getServiceAccount returns the :serviceAccountId service account of the organization of the signed in user.
+func (s *ServiceAccountsService) getServiceAccount(c *models.ReqContext) (*serviceaccounts.ServiceAccountDTO, response.Response) {
+	id, resp := getServiceAccountID(c)
+	if resp != nil {
+		return nil, resp
+	}
+
+	serviceAccount, err := s.store.GetServiceAccount(c.Req.Context(), c.OrgId, id)
+	if err != nil {
+		return nil, toServiceAccountError(err, "Failed to get service account")
+	}
+	return serviceAccount, nil
+}
+
+func getServiceAccountID(c *models.ReqContext) (int64, response.Response) {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return 0, response.Error(http.StatusBadRequest, "serviceAccountId is invalid", err)
+	}
+	return id, nil
+}
+
+// checkRole prevents the signed in user from creating a service account, or a token, with a role
+// higher than its own.
+func checkRole(c *models.ReqContext, role models.RoleType) response.Response {
+	if c.IsGrafanaAdmin || c.OrgRole.Includes(role) {
+		return nil
+	}
+	return response.Error(http.StatusForbidden, "Cannot assign a role higher than your own to a service account", nil)
+}
+
+func toServiceAccountError(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, serviceaccounts.ErrServiceAccountNotFound),
+		errors.Is(err, serviceaccounts.ErrServiceAccountTokenNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, serviceaccounts.ErrServiceAccountNameMissing),
+		errors.Is(err, models.ErrInvalidApiKeyExpiration):
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, serviceaccounts.ErrServiceAccountAlreadyExists),
+		errors.Is(err, models.ErrDuplicateApiKey):
+		return response.Error(http.StatusConflict, err.Error(), nil)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
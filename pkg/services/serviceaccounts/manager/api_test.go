@@ -0,0 +1,203 @@
+package manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/models"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/database"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	orgAdmin  = &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+	orgEditor = &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_EDITOR}
+)
+
+func setupTestEnv(t *testing.T) (*ServiceAccountsService, *accesscontrolmock.Mock) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.FeatureToggles = map[string]bool{"service-accounts": true}
+	cfg.ApiKeyMaxSecondsToLive = -1
+	ac := accesscontrolmock.New()
+
+	store := database.ProvideServiceAccountsStore(sqlstore.InitTestDB(t))
+	s, err := ProvideServiceAccountsService(cfg, store, ac, routing.NewRouteRegister())
+	require.NoError(t, err)
+	return s, ac
+}
+
+func newRequestContext(user *models.SignedInUser, params map[string]string) *models.ReqContext {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	return &models.ReqContext{
+		Context:      &web.Context{Req: web.SetURLParams(req, params)},
+		SignedInUser: user,
+	}
+}
+
+func createServiceAccount(t *testing.T, s *ServiceAccountsService, name string, role models.RoleType) *serviceaccounts.ServiceAccountDTO {
+	t.Helper()
+
+	resp := s.createServiceAccountHandler(newRequestContext(orgAdmin, nil), serviceaccounts.CreateServiceAccountForm{Name: name, Role: role})
+	require.Equal(t, http.StatusCreated, resp.Status())
+	var serviceAccount serviceaccounts.ServiceAccountDTO
+	require.NoError(t, json.Unmarshal(resp.Body(), &serviceAccount))
+	return &serviceAccount
+}
+
+func TestServiceAccountsService_DeclareFixedRoles(t *testing.T) {
+	_, ac := setupTestEnv(t)
+	require.Len(t, ac.Calls.DeclareFixedRoles, 1)
+
+	cfg := setting.NewCfg()
+	ac = accesscontrolmock.New()
+	_, err := ProvideServiceAccountsService(cfg, nil, ac, routing.NewRouteRegister())
+	require.NoError(t, err)
+	assert.Empty(t, ac.Calls.DeclareFixedRoles, "the fixed roles are only declared when service accounts are enabled")
+}
+
+func TestServiceAccountsService_ServiceAccountsAPI(t *testing.T) {
+	t.Run("should create, list, disable and delete a service account", func(t *testing.T) {
+		s, _ := setupTestEnv(t)
+
+		created := createServiceAccount(t, s, "Grafana Agent", models.ROLE_EDITOR)
+		assert.Equal(t, "Grafana Agent", created.Name)
+		assert.Equal(t, models.ROLE_EDITOR, created.Role)
+
+		resp := s.listServiceAccountsHandler(newRequestContext(orgAdmin, nil))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var list []*serviceaccounts.ServiceAccountDTO
+		require.NoError(t, json.Unmarshal(resp.Body(), &list))
+		require.Len(t, list, 1)
+		assert.Equal(t, created.Id, list[0].Id)
+
+		params := map[string]string{":serviceAccountId": strconv.FormatInt(created.Id, 10)}
+		disabled := true
+		resp = s.updateServiceAccountHandler(newRequestContext(orgAdmin, params), serviceaccounts.UpdateServiceAccountForm{IsDisabled: &disabled})
+		require.Equal(t, http.StatusOK, resp.Status())
+		var updated serviceaccounts.ServiceAccountDTO
+		require.NoError(t, json.Unmarshal(resp.Body(), &updated))
+		assert.True(t, updated.IsDisabled)
+		assert.Equal(t, "Grafana Agent", updated.Name)
+
+		resp = s.deleteServiceAccountHandler(newRequestContext(orgAdmin, params))
+		require.Equal(t, http.StatusOK, resp.Status())
+		resp = s.getServiceAccountHandler(newRequestContext(orgAdmin, params))
+		assert.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("should reject duplicated names and invalid roles", func(t *testing.T) {
+		s, _ := setupTestEnv(t)
+
+		createServiceAccount(t, s, "Grafana Agent", models.ROLE_VIEWER)
+		resp := s.createServiceAccountHandler(newRequestContext(orgAdmin, nil), serviceaccounts.CreateServiceAccountForm{Name: "Grafana Agent"})
+		assert.Equal(t, http.StatusConflict, resp.Status())
+
+		resp = s.createServiceAccountHandler(newRequestContext(orgAdmin, nil), serviceaccounts.CreateServiceAccountForm{Name: "Terraform", Role: "Owner"})
+		assert.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("should not give a service account a role higher than the role of the signed in user", func(t *testing.T) {
+		s, _ := setupTestEnv(t)
+
+		resp := s.createServiceAccountHandler(newRequestContext(orgEditor, nil), serviceaccounts.CreateServiceAccountForm{Name: "Terraform", Role: models.ROLE_ADMIN})
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+
+		created := createServiceAccount(t, s, "Grafana Agent", models.ROLE_VIEWER)
+		params := map[string]string{":serviceAccountId": strconv.FormatInt(created.Id, 10)}
+		role := models.ROLE_ADMIN
+		resp = s.updateServiceAccountHandler(newRequestContext(orgEditor, params), serviceaccounts.UpdateServiceAccountForm{Role: &role})
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+
+		role = models.ROLE_EDITOR
+		resp = s.updateServiceAccountHandler(newRequestContext(orgEditor, params), serviceaccounts.UpdateServiceAccountForm{Role: &role})
+		assert.Equal(t, http.StatusOK, resp.Status())
+	})
+
+	t.Run("should not manage the service accounts of another organization", func(t *testing.T) {
+		s, _ := setupTestEnv(t)
+
+		created := createServiceAccount(t, s, "Grafana Agent", models.ROLE_VIEWER)
+		otherOrgAdmin := &models.SignedInUser{UserId: 3, OrgId: 2, OrgRole: models.ROLE_ADMIN}
+		params := map[string]string{":serviceAccountId": strconv.FormatInt(created.Id, 10)}
+
+		resp := s.getServiceAccountHandler(newRequestContext(otherOrgAdmin, params))
+		assert.Equal(t, http.StatusNotFound, resp.Status())
+		resp = s.deleteServiceAccountHandler(newRequestContext(otherOrgAdmin, params))
+		assert.Equal(t, http.StatusNotFound, resp.Status())
+		resp = s.addTokenHandler(newRequestContext(otherOrgAdmin, params), serviceaccounts.AddServiceAccountTokenCommand{Name: "token"})
+		assert.Equal(t, http.StatusNotFound, resp.Status())
+	})
+}
+
+func TestServiceAccountsService_TokensAPI(t *testing.T) {
+	t.Run("should add, list and delete tokens", func(t *testing.T) {
+		s, _ := setupTestEnv(t)
+
+		created := createServiceAccount(t, s, "Grafana Agent", models.ROLE_EDITOR)
+		params := map[string]string{":serviceAccountId": strconv.FormatInt(created.Id, 10)}
+
+		resp := s.addTokenHandler(newRequestContext(orgAdmin, params), serviceaccounts.AddServiceAccountTokenCommand{Name: "expiring", SecondsToLive: 3600})
+		require.Equal(t, http.StatusOK, resp.Status())
+		var token dtos.NewApiKeyResult
+		require.NoError(t, json.Unmarshal(resp.Body(), &token))
+		assert.Equal(t, "expiring", token.Name)
+		assert.NotEmpty(t, token.Key)
+
+		resp = s.addTokenHandler(newRequestContext(orgAdmin, params), serviceaccounts.AddServiceAccountTokenCommand{Name: "expiring"})
+		assert.Equal(t, http.StatusConflict, resp.Status())
+		resp = s.addTokenHandler(newRequestContext(orgAdmin, params), serviceaccounts.AddServiceAccountTokenCommand{Name: "permanent"})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		resp = s.listTokensHandler(newRequestContext(orgAdmin, params))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var tokens []*serviceaccounts.TokenDTO
+		require.NoError(t, json.Unmarshal(resp.Body(), &tokens))
+		require.Len(t, tokens, 2)
+		assert.Equal(t, "expiring", tokens[0].Name)
+		assert.NotNil(t, tokens[0].Expiration)
+		assert.False(t, tokens[0].HasExpired)
+		assert.Nil(t, tokens[1].Expiration)
+
+		params[":tokenId"] = strconv.FormatInt(token.ID, 10)
+		resp = s.deleteTokenHandler(newRequestContext(orgAdmin, params))
+		require.Equal(t, http.StatusOK, resp.Status())
+		resp = s.deleteTokenHandler(newRequestContext(orgAdmin, params))
+		assert.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("should enforce the maximum lifetime of the tokens", func(t *testing.T) {
+		s, _ := setupTestEnv(t)
+		s.Cfg.ApiKeyMaxSecondsToLive = 3600
+
+		created := createServiceAccount(t, s, "Grafana Agent", models.ROLE_VIEWER)
+		params := map[string]string{":serviceAccountId": strconv.FormatInt(created.Id, 10)}
+
+		resp := s.addTokenHandler(newRequestContext(orgAdmin, params), serviceaccounts.AddServiceAccountTokenCommand{Name: "permanent"})
+		assert.Equal(t, http.StatusBadRequest, resp.Status())
+		resp = s.addTokenHandler(newRequestContext(orgAdmin, params), serviceaccounts.AddServiceAccountTokenCommand{Name: "long", SecondsToLive: 7200})
+		assert.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("should not add tokens to a service account with a role higher than the role of the signed in user", func(t *testing.T) {
+		s, _ := setupTestEnv(t)
+
+		created := createServiceAccount(t, s, "Terraform", models.ROLE_ADMIN)
+		params := map[string]string{":serviceAccountId": strconv.FormatInt(created.Id, 10)}
+
+		resp := s.addTokenHandler(newRequestContext(orgEditor, params), serviceaccounts.AddServiceAccountTokenCommand{Name: "token"})
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+	})
+}
@@ -0,0 +1,94 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func ProvideServiceAccountsService(cfg *setting.Cfg, store serviceaccounts.Store, ac accesscontrol.AccessControl, routeRegister routing.RouteRegister) (*ServiceAccountsService, error) {
+	s := &ServiceAccountsService{
+		Cfg:           cfg,
+		RouteRegister: routeRegister,
+		AccessControl: ac,
+		store:         store,
+		log:           log.New("serviceaccounts"),
+	}
+
+	if !s.IsDisabled() {
+		if err := s.declareFixedRoles(); err != nil {
+			return nil, err
+		}
+		s.registerAPIEndpoints()
+	}
+	return s, nil
+}
+
+// ServiceAccountsService is the service managing the service accounts of the organizations.
+type ServiceAccountsService struct {
+	Cfg           *setting.Cfg
+	RouteRegister routing.RouteRegister
+	AccessControl accesscontrol.AccessControl
+	store         serviceaccounts.Store
+	log           log.Logger
+}
+
+// IsDisabled returns true when the service-accounts feature toggle is off.
+func (s *ServiceAccountsService) IsDisabled() bool {
+	if s.Cfg == nil {
+		return true
+	}
+	return !s.Cfg.FeatureToggles["service-accounts"]
+}
+
+// CreateServiceAccount creates a service account in the organization orgID.
+func (s *ServiceAccountsService) CreateServiceAccount(ctx context.Context, orgID int64, form serviceaccounts.CreateServiceAccountForm) (*serviceaccounts.ServiceAccountDTO, error) {
+	return s.store.CreateServiceAccount(ctx, orgID, form)
+}
+
+// GetServiceAccount gets a service account of the organization orgID.
+func (s *ServiceAccountsService) GetServiceAccount(ctx context.Context, orgID, serviceAccountID int64) (*serviceaccounts.ServiceAccountDTO, error) {
+	return s.store.GetServiceAccount(ctx, orgID, serviceAccountID)
+}
+
+// DeleteServiceAccount deletes a service account of the organization orgID and its tokens.
+func (s *ServiceAccountsService) DeleteServiceAccount(ctx context.Context, orgID, serviceAccountID int64) error {
+	return s.store.DeleteServiceAccount(ctx, orgID, serviceAccountID)
+}
+
+// declareFixedRoles declares to the AccessControl service the fixed roles of the service accounts,
+// granted to the organization administrators
+func (s *ServiceAccountsService) declareFixedRoles() error {
+	readPermissions := []accesscontrol.Permission{
+		{Action: serviceaccounts.ActionRead, Scope: serviceaccounts.ScopeAll},
+	}
+	return s.AccessControl.DeclareFixedRoles(
+		accesscontrol.RoleRegistration{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:serviceaccounts:admin:read",
+				Description: "Read the service accounts of the organization and their tokens",
+				Permissions: readPermissions,
+			},
+			Grants: []string{string(models.ROLE_ADMIN)},
+		},
+		accesscontrol.RoleRegistration{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:serviceaccounts:admin:edit",
+				Description: "Create, read, update and delete the service accounts of the organization and their tokens",
+				Permissions: accesscontrol.ConcatPermissions(readPermissions, []accesscontrol.Permission{
+					{Action: serviceaccounts.ActionCreate, Scope: serviceaccounts.ScopeAll},
+					{Action: serviceaccounts.ActionWrite, Scope: serviceaccounts.ScopeAll},
+					{Action: serviceaccounts.ActionDelete, Scope: serviceaccounts.ScopeAll},
+				}),
+			},
+			Grants: []string{string(models.ROLE_ADMIN)},
+		},
+	)
+}
@@ -0,0 +1,74 @@
+package serviceaccounts
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+var (
+	ErrServiceAccountNotFound      = errors.New("service account not found")
+	ErrServiceAccountAlreadyExists = errors.New("a service account with the same name already exists")
+	ErrServiceAccountNameMissing   = errors.New("service account name is missing")
+	ErrServiceAccountTokenNotFound = errors.New("service account token not found")
+)
+
+// Service accounts actions
+const (
+	ActionRead   = "serviceaccounts:read"
+	ActionCreate = "serviceaccounts:create"
+	ActionWrite  = "serviceaccounts:write"
+	ActionDelete = "serviceaccounts:delete"
+)
+
+// Service accounts scopes
+var (
+	ScopeAll = accesscontrol.Scope("serviceaccounts", "*")
+	ScopeID  = accesscontrol.Scope("serviceaccounts", "id", accesscontrol.Parameter(":serviceAccountId"))
+)
+
+type ServiceAccountDTO struct {
+	Id         int64           `json:"id"`
+	OrgId      int64           `json:"orgId"`
+	Name       string          `json:"name"`
+	Login      string          `json:"login"`
+	Role       models.RoleType `json:"role"`
+	IsDisabled bool            `json:"isDisabled"`
+	Tokens     int64           `json:"tokens"`
+	Created    time.Time       `json:"created"`
+	Updated    time.Time       `json:"updated"`
+}
+
+type CreateServiceAccountForm struct {
+	Name       string          `json:"name"`
+	Role       models.RoleType `json:"role"`
+	IsDisabled bool            `json:"isDisabled"`
+}
+
+// UpdateServiceAccountForm changes the fields of a service account that are set.
+type UpdateServiceAccountForm struct {
+	Name       *string          `json:"name"`
+	Role       *models.RoleType `json:"role"`
+	IsDisabled *bool            `json:"isDisabled"`
+}
+
+type AddServiceAccountTokenCommand struct {
+	Name          string `json:"name" binding:"Required"`
+	SecondsToLive int64  `json:"secondsToLive"`
+
+	OrgId            int64  `json:"-"`
+	ServiceAccountId int64  `json:"-"`
+	Key              string `json:"-"`
+
+	Result *models.ApiKey `json:"-"`
+}
+
+type TokenDTO struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Created    time.Time  `json:"created"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+	HasExpired bool       `json:"hasExpired"`
+}
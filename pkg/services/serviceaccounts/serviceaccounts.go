@@ -0,0 +1,28 @@
+package serviceaccounts
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Service manages the service accounts, the identities of automations, of the organizations.
+type Service interface {
+	CreateServiceAccount(ctx context.Context, orgID int64, form CreateServiceAccountForm) (*ServiceAccountDTO, error)
+	GetServiceAccount(ctx context.Context, orgID, serviceAccountID int64) (*ServiceAccountDTO, error)
+	DeleteServiceAccount(ctx context.Context, orgID, serviceAccountID int64) error
+}
+
+// Store defines methods to interact with the service accounts storage. Service accounts are stored as users
+// of a single organization, and their tokens as API keys linked to them.
+type Store interface {
+	CreateServiceAccount(ctx context.Context, orgID int64, form CreateServiceAccountForm) (*ServiceAccountDTO, error)
+	ListServiceAccounts(ctx context.Context, orgID int64) ([]*ServiceAccountDTO, error)
+	GetServiceAccount(ctx context.Context, orgID, serviceAccountID int64) (*ServiceAccountDTO, error)
+	UpdateServiceAccount(ctx context.Context, orgID, serviceAccountID int64, form UpdateServiceAccountForm) (*ServiceAccountDTO, error)
+	DeleteServiceAccount(ctx context.Context, orgID, serviceAccountID int64) error
+	ListTokens(ctx context.Context, orgID, serviceAccountID int64) ([]*models.ApiKey, error)
+	AddServiceAccountToken(ctx context.Context, cmd *AddServiceAccountTokenCommand) error
+	DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
+	LinkAPIKeyToServiceAccount(ctx context.Context, orgID, apiKeyID, serviceAccountID int64) error
+}
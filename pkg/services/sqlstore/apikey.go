@@ -32,6 +32,8 @@ func GetAPIKeys(ctx context.Context, query *models.GetApiKeysQuery) error {
 				Where("org_id=? and ( expires IS NULL or expires >= ?)", query.OrgId, timeNow().Unix()).
 				Asc("name")
 		}
+		// the tokens of the service accounts are listed with their service account
+		sess = sess.Where("(service_account_id IS NULL OR service_account_id = 0)")
 
 		query.Result = make([]*models.ApiKey, 0)
 		return sess.Find(&query.Result)
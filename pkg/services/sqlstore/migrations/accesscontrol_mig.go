@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAccessControlMigrations(mg *Migrator) {
+	roleV1 := Table{
+		Name: "role",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "version", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "display_name", Type: DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "description", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "name"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create role table", NewAddTableMigration(roleV1))
+	mg.AddMigration("add unique index role.uid", NewAddIndexMigration(roleV1, roleV1.Indices[0]))
+	mg.AddMigration("add unique index role.org_id-name", NewAddIndexMigration(roleV1, roleV1.Indices[1]))
+
+	permissionV1 := Table{
+		Name: "permission",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "role_id", Type: DB_BigInt, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "scope", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"role_id"}},
+		},
+	}
+
+	mg.AddMigration("create permission table", NewAddTableMigration(permissionV1))
+	mg.AddMigration("add index permission.role_id", NewAddIndexMigration(permissionV1, permissionV1.Indices[0]))
+
+	builtinRoleV1 := Table{
+		Name: "builtin_role",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "role", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "role_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "role", "role_id"}, Type: UniqueIndex},
+			{Cols: []string{"role_id"}},
+		},
+	}
+
+	mg.AddMigration("create builtin_role table", NewAddTableMigration(builtinRoleV1))
+	mg.AddMigration("add unique index builtin_role.org_id-role-role_id", NewAddIndexMigration(builtinRoleV1, builtinRoleV1.Indices[0]))
+	mg.AddMigration("add index builtin_role.role_id", NewAddIndexMigration(builtinRoleV1, builtinRoleV1.Indices[1]))
+
+	userRoleV1 := Table{
+		Name: "user_role",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "role_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "user_id", "role_id"}, Type: UniqueIndex},
+			{Cols: []string{"role_id"}},
+		},
+	}
+
+	mg.AddMigration("create user_role table", NewAddTableMigration(userRoleV1))
+	mg.AddMigration("add unique index user_role.org_id-user_id-role_id", NewAddIndexMigration(userRoleV1, userRoleV1.Indices[0]))
+	mg.AddMigration("add index user_role.role_id", NewAddIndexMigration(userRoleV1, userRoleV1.Indices[1]))
+
+	teamRoleV1 := Table{
+		Name: "team_role",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "team_id", Type: DB_BigInt, Nullable: false},
+			{Name: "role_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "team_id", "role_id"}, Type: UniqueIndex},
+			{Cols: []string{"role_id"}},
+		},
+	}
+
+	mg.AddMigration("create team_role table", NewAddTableMigration(teamRoleV1))
+	mg.AddMigration("add unique index team_role.org_id-team_id-role_id", NewAddIndexMigration(teamRoleV1, teamRoleV1.Indices[0]))
+	mg.AddMigration("add index team_role.role_id", NewAddIndexMigration(teamRoleV1, teamRoleV1.Indices[1]))
+}
@@ -22,6 +22,18 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create Admin Configuration
 	AddAlertAdminConfigMigrations(mg)
+
+	// Create the members of the alert rule evaluation sharding
+	AddAlertSchedulerMemberMigrations(mg)
+
+	// Create the history of the alert instance state transitions
+	AddAlertStateHistoryMigrations(mg)
+
+	// Create the silence templates and the recurring silences
+	AddAlertSilenceMigrations(mg)
+
+	// Create the provenance of the provisioned alerting resources
+	AddProvenanceMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -231,6 +243,18 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 			Cols: []string{"org_id", "dashboard_uid", "panel_id"},
 		},
 	))
+
+	// add record column, it holds the metric name of recording rules
+	mg.AddMigration("add column record to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"}))
+
+	// add is_paused column
+	mg.AddMigration("add is_paused column to alert_rule table", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	// add rule_group_idx column, it holds the position of the rule in its rule group
+	mg.AddMigration("add rule_group_idx column to alert_rule table", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "rule_group_idx", Type: migrator.DB_Int, Nullable: false, Default: "1"}))
+
+	// add computed_labels column, the computed labels are opt-in so that existing alerts keep their labels
+	mg.AddMigration("add computed_labels column to alert_rule table", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "computed_labels", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -274,6 +298,21 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add record column
+	mg.AddMigration("add column record to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"}))
+
+	// add is_paused column
+	mg.AddMigration("add is_paused column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	// add created_by column, it holds the ID of the user who created the version
+	mg.AddMigration("add created_by column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+
+	// add rule_group_idx column
+	mg.AddMigration("add rule_group_idx column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "rule_group_idx", Type: migrator.DB_Int, Nullable: false, Default: "1"}))
+
+	// add computed_labels column
+	mg.AddMigration("add computed_labels column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "computed_labels", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -303,6 +342,11 @@ func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("add index in alert_configuration table on org_id column", migrator.NewAddIndexMigration(alertConfiguration, &migrator.Index{
 		Cols: []string{"org_id"},
 	}))
+
+	// add created_by column, it holds the ID of the user who saved the configuration
+	mg.AddMigration("add column created_by in alert_configuration", migrator.NewAddColumnMigration(alertConfiguration, &migrator.Column{
+		Name: "created_by", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
 
 func AddAlertAdminConfigMigrations(mg *migrator.Migrator) {
@@ -324,3 +368,122 @@ func AddAlertAdminConfigMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create_ngalert_configuration_table", migrator.NewAddTableMigration(adminConfiguration))
 	mg.AddMigration("add index in ngalert_configuration on org_id column", migrator.NewAddIndexMigration(adminConfiguration, adminConfiguration.Indices[0]))
 }
+
+func AddAlertSchedulerMemberMigrations(mg *migrator.Migrator) {
+	schedulerMember := migrator.Table{
+		Name: "alert_scheduler_member",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "node_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "heartbeat", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"node_id"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"heartbeat"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_scheduler_member table", migrator.NewAddTableMigration(schedulerMember))
+	mg.AddMigration("add unique index in alert_scheduler_member on node_id column", migrator.NewAddIndexMigration(schedulerMember, schedulerMember.Indices[0]))
+	mg.AddMigration("add index in alert_scheduler_member on heartbeat column", migrator.NewAddIndexMigration(schedulerMember, schedulerMember.Indices[1]))
+}
+
+func AddAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "rule_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "evaluation_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"rule_org_id", "rule_uid", "created"}, Type: migrator.IndexType},
+			{Cols: []string{"rule_org_id", "created"}, Type: migrator.IndexType},
+			{Cols: []string{"created"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history on rule_org_id, rule_uid and created columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on rule_org_id and created columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history on created column", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[2]))
+}
+
+func AddAlertSilenceMigrations(mg *migrator.Migrator) {
+	silenceTemplate := migrator.Table{
+		Name: "alert_silence_template",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "name"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_silence_template table", migrator.NewAddTableMigration(silenceTemplate))
+	mg.AddMigration("add unique index in alert_silence_template on org_id and uid columns", migrator.NewAddIndexMigration(silenceTemplate, silenceTemplate.Indices[0]))
+	mg.AddMigration("add unique index in alert_silence_template on org_id and name columns", migrator.NewAddIndexMigration(silenceTemplate, silenceTemplate.Indices[1]))
+
+	recurringSilence := migrator.Table{
+		Name: "alert_recurring_silence",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "cron", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "weekly_windows", Type: migrator.DB_Text, Nullable: true},
+			{Name: "timezone", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "last_starts_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_recurring_silence table", migrator.NewAddTableMigration(recurringSilence))
+	mg.AddMigration("add unique index in alert_recurring_silence on org_id and uid columns", migrator.NewAddIndexMigration(recurringSilence, recurringSilence.Indices[0]))
+
+	mg.AddMigration("add silence_id column to alert_recurring_silence table", migrator.NewAddColumnMigration(recurringSilence, &migrator.Column{
+		Name: "silence_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: true,
+	}))
+}
+
+func AddProvenanceMigrations(mg *migrator.Migrator) {
+	provenance := migrator.Table{
+		Name: "alert_provenance",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "record_type", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "record_key", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "provenance", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "record_type", "record_key"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_provenance table", migrator.NewAddTableMigration(provenance))
+	mg.AddMigration("add unique index in alert_provenance on org_id, record_type and record_key columns", migrator.NewAddIndexMigration(provenance, provenance.Indices[0]))
+}
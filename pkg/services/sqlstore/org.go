@@ -252,6 +252,11 @@ func DeleteOrg(ctx context.Context, cmd *models.DeleteOrgCommand) error {
 			"DELETE FROM alert WHERE org_id = ?",
 			"DELETE FROM annotation WHERE org_id = ?",
 			"DELETE FROM kv_store WHERE org_id = ?",
+			"DELETE FROM permission WHERE EXISTS (SELECT 1 FROM role WHERE role.org_id = ? AND permission.role_id = role.id)",
+			"DELETE FROM role WHERE org_id = ?",
+			"DELETE FROM builtin_role WHERE org_id = ?",
+			"DELETE FROM user_role WHERE org_id = ?",
+			"DELETE FROM team_role WHERE org_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -205,17 +205,18 @@ func (ss *SQLStore) CreateUser(ctx context.Context, cmd models.CreateUserCommand
 
 		// create user
 		user = &models.User{
-			Email:         cmd.Email,
-			Name:          cmd.Name,
-			Login:         cmd.Login,
-			Company:       cmd.Company,
-			IsAdmin:       cmd.IsAdmin,
-			IsDisabled:    cmd.IsDisabled,
-			OrgId:         orgId,
-			EmailVerified: cmd.EmailVerified,
-			Created:       time.Now(),
-			Updated:       time.Now(),
-			LastSeenAt:    time.Now().AddDate(-10, 0, 0),
+			Email:            cmd.Email,
+			Name:             cmd.Name,
+			Login:            cmd.Login,
+			Company:          cmd.Company,
+			IsAdmin:          cmd.IsAdmin,
+			IsDisabled:       cmd.IsDisabled,
+			IsServiceAccount: cmd.IsServiceAccount,
+			OrgId:            orgId,
+			EmailVerified:    cmd.EmailVerified,
+			Created:          time.Now(),
+			Updated:          time.Now(),
+			LastSeenAt:       time.Now().AddDate(-10, 0, 0),
 		}
 
 		salt, err := util.GetRandomString(10)
@@ -524,6 +525,8 @@ func GetSignedInUser(ctx context.Context, query *models.GetSignedInUserQuery) er
 	var rawSQL = `SELECT
 		u.id             as user_id,
 		u.is_admin       as is_grafana_admin,
+		u.is_disabled    as is_disabled,
+		u.is_service_account as is_service_account,
 		u.email          as email,
 		u.login          as login,
 		u.name           as name,
@@ -609,6 +612,10 @@ func SearchUsers(ctx context.Context, query *models.SearchUsersQuery) error {
 		whereParams = append(whereParams, query.IsDisabled)
 	}
 
+	// service accounts are managed through their own API
+	whereConditions = append(whereConditions, "is_service_account = ?")
+	whereParams = append(whereParams, false)
+
 	if query.AuthModule != "" {
 		whereConditions = append(whereConditions, `auth_module=?`)
 		whereParams = append(whereParams, query.AuthModule)
@@ -745,6 +752,8 @@ func deleteUserInTransaction(sess *DBSession, cmd *models.DeleteUserCommand) err
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_role WHERE user_id = ?",
+		"DELETE FROM api_key WHERE service_account_id = ?",
 	}
 
 	for _, sql := range deletes {
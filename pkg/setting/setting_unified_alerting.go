@@ -2,6 +2,7 @@ package setting
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -47,6 +48,15 @@ const (
 	schedulerDefaultMaxAttempts             = 3
 	schedulerDefaultLegacyMinInterval       = 1
 	schedulerDefaultMinInterval             = 10 * time.Second
+	recordingRulesDefaultTimeout            = 10 * time.Second
+	shardingDefaultMembership               = ShardingMembershipDatabase
+	shardingDefaultHeartbeatInterval        = 15 * time.Second
+	shardingDefaultMemberTimeout            = time.Minute
+	stateHistoryDefaultMaxAge               = 30 * 24 * time.Hour
+	screenshotsDefaultCaptureTimeout        = 10 * time.Second
+	screenshotsDefaultMaxConcurrent         = 5
+	configHistoryDefaultMaxVersions         = 100
+	backtestingDefaultMaxEvaluations        = 24 * 60
 )
 
 type UnifiedAlertingSettings struct {
@@ -65,6 +75,61 @@ type UnifiedAlertingSettings struct {
 	DefaultConfiguration           string
 	Enabled                        bool
 	DisabledOrgs                   map[int64]struct{}
+	RecordingRules                 RecordingRuleSettings
+	Sharding                       ShardingSettings
+	StateHistory                   StateHistorySettings
+	Screenshots                    ScreenshotsSettings
+	ConfigHistory                  ConfigHistorySettings
+	Backtesting                    BacktestingSettings
+}
+
+const (
+	// ShardingMembershipDatabase discovers the instances taking part in the sharding through the database.
+	ShardingMembershipDatabase = "database"
+	// ShardingMembershipPeers discovers the instances taking part in the sharding through the Alertmanager peer mesh.
+	ShardingMembershipPeers = "peers"
+)
+
+// ShardingSettings holds the settings for sharding the alert rule evaluation across the instances of a high availability cluster.
+type ShardingSettings struct {
+	Enabled           bool
+	Membership        string
+	HeartbeatInterval time.Duration
+	MemberTimeout     time.Duration
+}
+
+// StateHistorySettings holds the settings of the history of the state transitions of alert instances.
+type StateHistorySettings struct {
+	Enabled bool
+	// MaxAge is how long state transitions are kept, they are kept forever if zero.
+	MaxAge time.Duration
+}
+
+// ScreenshotsSettings holds the settings of the screenshots of the panels of the alert rules attached to notifications.
+type ScreenshotsSettings struct {
+	Capture        bool
+	CaptureTimeout time.Duration
+	MaxConcurrent  int
+}
+
+// ConfigHistorySettings holds the settings of the history of the Alertmanager configurations.
+type ConfigHistorySettings struct {
+	// MaxVersions is the number of configurations kept per organization, all of them are kept if zero.
+	MaxVersions int
+}
+
+// BacktestingSettings holds the settings of the backtesting of alert rules.
+type BacktestingSettings struct {
+	// MaxEvaluations is the maximum number of evaluations of a backtest.
+	MaxEvaluations int
+}
+
+// RecordingRuleSettings holds the settings of the remote write endpoint recording rules write to.
+type RecordingRuleSettings struct {
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
 }
 
 // ReadUnifiedAlertingSettings reads both the `unified_alerting` and `alerting` sections of the configuration while preferring configuration the `alerting` section.
@@ -174,6 +239,59 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.MinInterval = uaMinInterval
 
+	rr := iniFile.Section("unified_alerting.recording_rules")
+	uaCfg.RecordingRules.URL = valueAsString(rr, "url", "")
+	uaCfg.RecordingRules.BasicAuthUsername = valueAsString(rr, "basic_auth_username", "")
+	uaCfg.RecordingRules.BasicAuthPassword = valueAsString(rr, "basic_auth_password", "")
+	uaCfg.RecordingRules.Timeout, err = gtime.ParseDuration(valueAsString(rr, "timeout", recordingRulesDefaultTimeout.String()))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.Sharding.Enabled = ua.Key("sharding_enabled").MustBool(false)
+	uaCfg.Sharding.Membership = valueAsString(ua, "sharding_membership", shardingDefaultMembership)
+	if uaCfg.Sharding.Membership != ShardingMembershipDatabase && uaCfg.Sharding.Membership != ShardingMembershipPeers {
+		return fmt.Errorf("invalid sharding_membership %q: must be %q or %q", uaCfg.Sharding.Membership, ShardingMembershipDatabase, ShardingMembershipPeers)
+	}
+	uaCfg.Sharding.HeartbeatInterval, err = gtime.ParseDuration(valueAsString(ua, "sharding_heartbeat_interval", shardingDefaultHeartbeatInterval.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.Sharding.MemberTimeout, err = gtime.ParseDuration(valueAsString(ua, "sharding_member_timeout", shardingDefaultMemberTimeout.String()))
+	if err != nil {
+		return err
+	}
+
+	sh := iniFile.Section("unified_alerting.state_history")
+	uaCfg.StateHistory.Enabled = sh.Key("enabled").MustBool(true)
+	uaCfg.StateHistory.MaxAge, err = gtime.ParseDuration(valueAsString(sh, "max_age", stateHistoryDefaultMaxAge.String()))
+	if err != nil {
+		return err
+	}
+
+	ss := iniFile.Section("unified_alerting.screenshots")
+	uaCfg.Screenshots.Capture = ss.Key("capture").MustBool(false)
+	uaCfg.Screenshots.CaptureTimeout, err = gtime.ParseDuration(valueAsString(ss, "capture_timeout", screenshotsDefaultCaptureTimeout.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.Screenshots.MaxConcurrent = ss.Key("max_concurrent_screenshots").MustInt(screenshotsDefaultMaxConcurrent)
+	if uaCfg.Screenshots.MaxConcurrent <= 0 {
+		return fmt.Errorf("invalid max_concurrent_screenshots %d: it must be positive", uaCfg.Screenshots.MaxConcurrent)
+	}
+
+	ch := iniFile.Section("unified_alerting.config_history")
+	uaCfg.ConfigHistory.MaxVersions = ch.Key("max_versions").MustInt(configHistoryDefaultMaxVersions)
+	if uaCfg.ConfigHistory.MaxVersions < 0 {
+		return fmt.Errorf("invalid max_versions %d: it must not be negative", uaCfg.ConfigHistory.MaxVersions)
+	}
+
+	bt := iniFile.Section("unified_alerting.backtesting")
+	uaCfg.Backtesting.MaxEvaluations = bt.Key("max_evaluations").MustInt(backtestingDefaultMaxEvaluations)
+	if uaCfg.Backtesting.MaxEvaluations <= 0 {
+		return fmt.Errorf("invalid max_evaluations %d: it must be positive", uaCfg.Backtesting.MaxEvaluations)
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
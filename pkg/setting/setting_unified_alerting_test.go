@@ -24,6 +24,18 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 		require.Len(t, cfg.UnifiedAlerting.HAPeers, 0)
 		require.Equal(t, 200*time.Millisecond, cfg.UnifiedAlerting.HAGossipInterval)
 		require.Equal(t, 60*time.Second, cfg.UnifiedAlerting.HAPushPullInterval)
+		require.Equal(t, "", cfg.UnifiedAlerting.RecordingRules.URL)
+		require.Equal(t, 10*time.Second, cfg.UnifiedAlerting.RecordingRules.Timeout)
+		require.False(t, cfg.UnifiedAlerting.Sharding.Enabled)
+		require.Equal(t, ShardingMembershipDatabase, cfg.UnifiedAlerting.Sharding.Membership)
+		require.Equal(t, 15*time.Second, cfg.UnifiedAlerting.Sharding.HeartbeatInterval)
+		require.Equal(t, time.Minute, cfg.UnifiedAlerting.Sharding.MemberTimeout)
+		require.True(t, cfg.UnifiedAlerting.StateHistory.Enabled)
+		require.Equal(t, 30*24*time.Hour, cfg.UnifiedAlerting.StateHistory.MaxAge)
+		require.False(t, cfg.UnifiedAlerting.Screenshots.Capture)
+		require.Equal(t, 10*time.Second, cfg.UnifiedAlerting.Screenshots.CaptureTimeout)
+		require.Equal(t, 5, cfg.UnifiedAlerting.Screenshots.MaxConcurrent)
+		require.Equal(t, 100, cfg.UnifiedAlerting.ConfigHistory.MaxVersions)
 	}
 
 	// With peers set, it correctly parses them.
@@ -1632,6 +1632,353 @@ var expAvailableChannelJsonOutput = `
         "secure": false
       }
     ]
+  },
+  {
+    "type": "webex",
+    "name": "Webex Teams",
+    "heading": "Webex Teams settings",
+    "description": "Sends notifications to a Webex Teams room",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Bot Token",
+        "description": "",
+        "placeholder": "Webex bot access token",
+        "propertyName": "bot_token",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Room ID",
+        "description": "The ID of the room the bot posts to",
+        "placeholder": "",
+        "propertyName": "room_id",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "API URL",
+        "description": "",
+        "placeholder": "https://webexapis.com/v1/messages",
+        "propertyName": "api_url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "sns",
+    "name": "AWS SNS",
+    "heading": "AWS SNS settings",
+    "description": "Publishes notifications to an AWS SNS topic, endpoint or phone number",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Region",
+        "description": "",
+        "placeholder": "us-east-1",
+        "propertyName": "region",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Topic ARN",
+        "description": "One of Topic ARN, Target ARN or Phone number is required",
+        "placeholder": "",
+        "propertyName": "topic_arn",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Target ARN",
+        "description": "A mobile platform endpoint ARN",
+        "placeholder": "",
+        "propertyName": "target_arn",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Phone number",
+        "description": "A phone number in E.164 format to send SMS messages to",
+        "placeholder": "",
+        "propertyName": "phone_number",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Access Key",
+        "description": "Leave empty to use the default AWS credentials chain, if the default auth provider is allowed",
+        "placeholder": "",
+        "propertyName": "access_key",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "password",
+        "label": "Secret Key",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "secret_key",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Assume Role ARN",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "assume_role_arn",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Subject",
+        "description": "",
+        "placeholder": "{{ template \"default.title\" . }}",
+        "propertyName": "subject",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "API URL",
+        "description": "Overrides the SNS endpoint with an HTTPS AWS endpoint, e.g. a VPC endpoint",
+        "placeholder": "",
+        "propertyName": "api_url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "mattermost",
+    "name": "Mattermost",
+    "heading": "Mattermost settings",
+    "description": "Sends notifications to Mattermost via incoming webhooks",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Webhook URL",
+        "description": "",
+        "placeholder": "Mattermost incoming webhook URL",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Channel",
+        "description": "Overrides the channel of the incoming webhook",
+        "placeholder": "",
+        "propertyName": "channel",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Username",
+        "description": "",
+        "placeholder": "Grafana",
+        "propertyName": "username",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Icon URL",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "icon_url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Title",
+        "description": "",
+        "placeholder": "{{ template \"default.title\" . }}",
+        "propertyName": "title",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Text Body",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "text",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
   }
 ]
 `
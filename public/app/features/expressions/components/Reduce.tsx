@@ -1,7 +1,7 @@
 import React, { FC } from 'react';
 import { SelectableValue } from '@grafana/data';
-import { InlineField, InlineFieldRow, Select } from '@grafana/ui';
-import { ExpressionQuery, reducerTypes } from '../types';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { ExpressionQuery, ReducerMode, reducerModes, reducerTypes } from '../types';
 
 interface Props {
   labelWidth: number;
@@ -11,7 +11,8 @@ interface Props {
 }
 
 export const Reduce: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
-  const reducer = reducerTypes.find((o) => o.value === query.reducer);
+  const reducer = reducerTypes.find((o) => o.value === query.reducer) ?? { value: query.reducer, label: query.reducer };
+  const mode = query.settings?.mode ?? ReducerMode.Strict;
 
   const onRefIdChange = (value: SelectableValue<string>) => {
     onChange({ ...query, expression: value.value });
@@ -21,14 +22,46 @@ export const Reduce: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
     onChange({ ...query, reducer: value.value });
   };
 
+  const onSelectMode = (value: SelectableValue<ReducerMode>) => {
+    onChange({ ...query, settings: { mode: value.value ?? ReducerMode.Strict } });
+  };
+
+  const onReplaceWithValueChange = (event: React.FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, settings: { mode, replaceWithValue: parseFloat(event.currentTarget.value) } });
+  };
+
   return (
-    <InlineFieldRow>
-      <InlineField label="Function" labelWidth={labelWidth}>
-        <Select menuShouldPortal options={reducerTypes} value={reducer} onChange={onSelectReducer} width={25} />
-      </InlineField>
-      <InlineField label="Input" labelWidth={labelWidth}>
-        <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
-      </InlineField>
-    </InlineFieldRow>
+    <>
+      <InlineFieldRow>
+        <InlineField label="Function" labelWidth={labelWidth}>
+          <Select
+            menuShouldPortal
+            allowCustomValue
+            options={reducerTypes}
+            value={reducer}
+            onChange={onSelectReducer}
+            width={25}
+          />
+        </InlineField>
+        <InlineField label="Input" labelWidth={labelWidth}>
+          <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField label="Mode" labelWidth={labelWidth}>
+          <Select menuShouldPortal options={reducerModes} value={mode} onChange={onSelectMode} width={25} />
+        </InlineField>
+        {mode === ReducerMode.ReplaceNonNumbers && (
+          <InlineField label="Replace With" labelWidth={labelWidth}>
+            <Input
+              type="number"
+              width={20}
+              defaultValue={query.settings?.replaceWithValue}
+              onBlur={onReplaceWithValueChange}
+            />
+          </InlineField>
+        )}
+      </InlineFieldRow>
+    </>
   );
 };
@@ -21,8 +21,44 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.mean, label: 'Mean', description: 'Get the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of non-null values' },
+  { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'percentile(95)', label: 'Percentile (95)', description: 'Get the 95th percentile, e.g. percentile(99)' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of the values' },
+  { value: 'diff', label: 'Difference', description: 'Get the difference between the last and first values' },
 ];
 
+export enum ReducerMode {
+  Strict = 'strict',
+  DropNonNumbers = 'dropNN',
+  ReplaceNonNumbers = 'replaceNN',
+}
+
+export const reducerModes: Array<SelectableValue<ReducerMode>> = [
+  {
+    value: ReducerMode.Strict,
+    label: 'Strict',
+    description: 'Result can be NaN if series contains null or NaN values',
+  },
+  {
+    value: ReducerMode.DropNonNumbers,
+    label: 'Drop Non-numeric Values',
+    description: 'Drop null, NaN and infinite values from input series before reducing',
+  },
+  {
+    value: ReducerMode.ReplaceNonNumbers,
+    label: 'Replace Non-numeric Values',
+    description: 'Replace null, NaN and infinite values with a constant value before reducing',
+  },
+];
+
+export interface ReduceSettings {
+  mode: ReducerMode;
+  replaceWithValue?: number;
+}
+
 export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.min, label: 'Min', description: 'Fill with the minimum value' },
   { value: ReducerID.max, label: 'Max', description: 'Fill with the maximum value' },
@@ -48,6 +84,7 @@ export interface ExpressionQuery extends DataQuery {
   downsampler?: string;
   upsampler?: string;
   conditions?: ClassicCondition[];
+  settings?: ReduceSettings;
 }
 export interface ClassicCondition {
   evaluator: {
@@ -63,6 +100,7 @@ export interface ClassicCondition {
   reducer: {
     params: [];
     type: ReducerType;
+    settings?: ReduceSettings;
   };
   type: 'query';
 }
@@ -74,7 +112,9 @@ export type ReducerType =
   | 'sum'
   | 'count'
   | 'last'
+  | 'first'
   | 'median'
+  | 'stddev'
   | 'diff'
   | 'diff_abs'
   | 'percent_diff'